@@ -6,9 +6,11 @@ CONFIG_PATH=/etc/cms
 ROOT_CA_DIR=${CONFIG_PATH}/root-ca
 INTERMEDIATE_CA_DIR=${CONFIG_PATH}/intermediate-ca
 CERTDIR_TRUSTEDJWTCERTS=${CONFIG_PATH}/jwt
+ISSUED_CERTS_DIR=${CONFIG_PATH}/issued-certs
+CRL_DIR=${CONFIG_PATH}/crl
 
 if [ ! -f $CONFIG_PATH/.setup_done ]; then
-  for directory in $LOG_PATH $CONFIG_PATH $CERTDIR_TRUSTEDJWTCERTS $ROOT_CA_DIR $INTERMEDIATE_CA_DIR; do
+  for directory in $LOG_PATH $CONFIG_PATH $CERTDIR_TRUSTEDJWTCERTS $ROOT_CA_DIR $INTERMEDIATE_CA_DIR $ISSUED_CERTS_DIR $CRL_DIR; do
     mkdir -p $directory
     if [ $? -ne 0 ]; then
       echo "Cannot create directory: $directory"
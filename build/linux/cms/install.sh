@@ -82,6 +82,12 @@ chmod 700 $CONFIG_PATH/root-ca
 mkdir -p $CONFIG_PATH/intermediate-ca && chown cms:cms $CONFIG_PATH/intermediate-ca
 chmod 700 $CONFIG_PATH/intermediate-ca
 
+mkdir -p $CONFIG_PATH/issued-certs && chown cms:cms $CONFIG_PATH/issued-certs
+chmod 700 $CONFIG_PATH/issued-certs
+
+mkdir -p $CONFIG_PATH/crl && chown cms:cms $CONFIG_PATH/crl
+chmod 700 $CONFIG_PATH/crl
+
 # Create logging dir in /var/log
 mkdir -p $LOG_PATH && chown cms:cms $LOG_PATH
 chmod 740 $LOG_PATH
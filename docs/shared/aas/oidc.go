@@ -0,0 +1,93 @@
+/*
+ *  Copyright (C) 2021 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+package aas
+
+import "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
+
+// OidcTokenRequestInfo request payload
+// swagger:parameters OidcTokenRequestInfo
+type OidcTokenRequest struct {
+	// in:body
+	Body aas.OidcTokenRequest
+}
+
+// swagger:operation POST /oidc/token Token getJwtTokenFromIdToken
+// ---
+// description: |
+//   Exchanges an ID token of the configured OpenID Connect identity provider for a bearer token. The signature,
+//   issuer, audience and validity of the ID token are verified. The roles of the bearer token are mapped from
+//   the groups of the user by the configured role mappings. The subject of the bearer token is the issuer and the
+//   subject of the ID token separated by "|", so that it never matches the name of a local user. The API is only
+//   available when OIDC is enabled.
+//
+// consumes:
+// - application/json
+// produces:
+// - application/jwt
+// parameters:
+// - name: request body
+//   required: true
+//   in: body
+//   schema:
+//     "$ref": "#/definitions/OidcTokenRequest"
+// responses:
+//   '200':
+//     description: Successfully created the bearer token.
+//     schema:
+//       type: string
+//   '400':
+//     description: Invalid request body
+//   '401':
+//     description: Invalid ID token or no role mapped to the user
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/v1/oidc/token
+// x-sample-call-input: |
+//    {
+//       "id_token" : "eyJhbGciOiJSUzI1NiIsImtpZCI6InJzYSIsInR5cCI6IkpXVCJ9..."
+//    }
+// ---
+
+// swagger:operation GET /oidc/login Token oidcLogin
+// ---
+// description: |
+//   Starts the OpenID Connect authorization code flow by redirecting to the authorization endpoint of the
+//   identity provider. The API is only available when OIDC is enabled.
+//
+// responses:
+//   '302':
+//     description: Redirect to the authorization endpoint of the identity provider.
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/v1/oidc/login
+// ---
+
+// swagger:operation GET /oidc/callback Token oidcCallback
+// ---
+// description: |
+//   Completes the OpenID Connect authorization code flow. The authorization code is redeemed at the identity
+//   provider and a bearer token is created for the user with the roles mapped from its groups.
+//
+// produces:
+// - application/jwt
+// parameters:
+// - name: code
+//   description: Authorization code issued by the identity provider.
+//   in: query
+//   type: string
+// - name: state
+//   description: State of the login started by /oidc/login.
+//   in: query
+//   type: string
+// responses:
+//   '200':
+//     description: Successfully created the bearer token.
+//     schema:
+//       type: string
+//   '400':
+//     description: Authorization code is missing
+//   '401':
+//     description: Authentication failed or no role mapped to the user
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/v1/oidc/callback?code=SplxlOBeZQQYbYS6WxSbIA&state=af0ifjsldkj
+// ---
@@ -86,7 +86,8 @@ type CustomClaims struct {
 // description: |
 //   Creates a new bearer token that can be used in the Authorization header for other API
 //   requests. Bearer token Authorization is required when requesting custom claims token
-//   from Authservice.
+//   from Authservice. The validity_seconds cannot exceed the configured jwt
+//   custom-claims-max-duration-mins, 30 days by default.
 //
 // security:
 //  - bearerAuth: []
@@ -0,0 +1,180 @@
+/*
+ *  Copyright (C) 2021 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+package aas
+
+import "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
+
+// TokenResponse response payload
+// swagger:response TokenResponse
+type TokenResponse struct {
+	// in:body
+	Body aas.TokenResponse
+}
+
+// RefreshTokenRequestInfo request payload
+// swagger:parameters RefreshTokenRequestInfo
+type RefreshTokenRequest struct {
+	// in:body
+	Body aas.RefreshTokenRequest
+}
+
+// RevokeTokenRequestInfo request payload
+// swagger:parameters RevokeTokenRequestInfo
+type RevokeTokenRequest struct {
+	// in:body
+	Body aas.RevokeTokenRequest
+}
+
+// TokenRevocationList response payload
+// swagger:response TokenRevocationList
+type TokenRevocationList struct {
+	// in:body
+	Body aas.TokenRevocationList
+}
+
+// swagger:operation POST /token Token getJwtTokenPair
+// ---
+// description: |
+//   Creates a short lived bearer token along with a refresh token when the request accepts application/json.
+//   The refresh token can be exchanged once for a new token pair at /token/refresh. The validities of the tokens
+//   are configured by jwt access-token-duration-mins and refresh-token-duration-mins.
+//
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// parameters:
+// - name: request body
+//   required: true
+//   in: body
+//   schema:
+//     "$ref": "#/definitions/UserCred"
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully created the bearer token and the refresh token.
+//     schema:
+//       "$ref": "#/definitions/TokenResponse"
+//   '401':
+//     description: Invalid credentials
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/v1/token
+// x-sample-call-input: |
+//    {
+//       "username" : "admin@aas",
+//       "password" : "aasAdminPass"
+//    }
+// x-sample-call-output: |
+//    {
+//       "access_token": "eyJhbGciOiJSUzM4NCIsImtpZCI6ImYwY2UyNzhhMGM0OGI5NjE3YzQxNzViYmMz...",
+//       "token_type": "Bearer",
+//       "expires_in": 900,
+//       "refresh_token": "sNvA829emyTEQSAz9xBLtRKgOcVqcnnEMvzHO7SNmtA"
+//    }
+// ---
+
+// swagger:operation POST /token/refresh Token refreshJwtToken
+// ---
+// description: |
+//   Exchanges a refresh token for a new bearer token and refresh token. The bearer token has the current roles
+//   and permissions of the user. A refresh token can only be used once, presenting a refresh token that has
+//   already been used revokes all the refresh tokens of the user.
+//
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// parameters:
+// - name: request body
+//   required: true
+//   in: body
+//   schema:
+//     "$ref": "#/definitions/RefreshTokenRequest"
+// responses:
+//   '200':
+//     description: Successfully created the bearer token and the refresh token.
+//     schema:
+//       "$ref": "#/definitions/TokenResponse"
+//   '400':
+//     description: Invalid request body
+//   '401':
+//     description: Invalid, expired or reused refresh token
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/v1/token/refresh
+// x-sample-call-input: |
+//    {
+//       "refresh_token" : "sNvA829emyTEQSAz9xBLtRKgOcVqcnnEMvzHO7SNmtA"
+//    }
+// ---
+
+// swagger:operation POST /token/revoke Token revokeJwtToken
+// ---
+// description: |
+//   Revokes the bearer token with the jti or all the bearer tokens and refresh tokens issued to the user so far.
+//   Exactly one of jti and username must be provided. The services reject the revoked tokens once they have
+//   retrieved the token revocation list. The tokens of a user are also revoked when the user is deleted, a role
+//   of the user is removed or the password of the user is changed.
+//
+// security:
+//  - bearerAuth: []
+// consumes:
+// - application/json
+// parameters:
+// - name: request body
+//   required: true
+//   in: body
+//   schema:
+//     "$ref": "#/definitions/RevokeTokenRequest"
+// responses:
+//   '204':
+//     description: Successfully revoked the tokens.
+//   '400':
+//     description: Invalid request body
+//   '404':
+//     description: User not found
+//
+// x-permissions: tokens:revoke
+// x-sample-call-endpoint: https://authservice.com:8444/aas/v1/token/revoke
+// x-sample-call-input: |
+//    {
+//       "username" : "hvsuser@hvs"
+//    }
+// ---
+
+// swagger:operation GET /token/revocations Token getTokenRevocationList
+// ---
+// description: |
+//   Retrieves the revocations of the tokens that are not expired yet. A token is revoked when its jti is listed
+//   or when it was issued to a listed subject before issued_before. The services cache the list and reject the
+//   revoked tokens.
+//
+// produces:
+// - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the token revocation list.
+//     schema:
+//       "$ref": "#/definitions/TokenRevocationList"
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/v1/token/revocations
+// x-sample-call-output: |
+//    {
+//       "jtis": [
+//          "5cefd45e-1fa5-42d9-bb82-15b2b2a82c9c"
+//       ],
+//       "subjects": [
+//          {
+//             "subject": "hvsuser@hvs",
+//             "issued_before": "2021-06-10T08:12:41.162339Z"
+//          }
+//       ]
+//    }
+// ---
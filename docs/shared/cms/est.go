@@ -0,0 +1,77 @@
+/*
+ *  Copyright (C) 2021 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cms
+
+// The EST endpoints of RFC 7030 are served under /.well-known/est, outside of the /cms/v1 base path. The optional
+// label path segment, as in /.well-known/est/{label}/simpleenroll, selects the certificate type.
+
+// swagger:operation GET /.well-known/est/cacerts EST EstCaCerts
+// ---
+// description: |
+//   Retrieves the root and intermediate CA certificates of CMS as a base64 encoded certs-only PKCS#7.
+//
+// produces:
+// - application/pkcs7-mime
+// responses:
+//   "200":
+//     description: Successfully retrieved the CA certificates.
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://cms.com:8445/.well-known/est/cacerts
+// x-sample-call-output: |
+//   MIIYDAYJKoZIhvcNAQcCoIIX/TCCF/kCAQExADALBgkqhkiG9w0BBwGgghffMIIGDTCCBHWgAwIBAgIBAD
+// ---
+
+// swagger:operation POST /.well-known/est/simpleenroll EST EstSimpleEnroll
+// ---
+// description: |
+//   Issues a certificate for a base64 encoded PKCS#10 CSR. The CSR is validated the same way as the CSRs posted
+//   to /certificates, the label selects the certificate type and defaults to TLS. A valid bearer token with the
+//   CMS CertApprover role matching the CSR is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// consumes:
+// - application/pkcs10
+// produces:
+// - application/pkcs7-mime
+// responses:
+//   "200":
+//     description: Successfully issued the certificate, the certs-only PKCS#7 contains the issued and issuing CA certificates.
+//   '400':
+//     description: Invalid CSR provided
+//   '401':
+//     description: Unauthorized
+//   '415':
+//     description: Content type not supported
+//
+// x-sample-call-endpoint: https://cms.com:8445/.well-known/est/TLS/simpleenroll
+// ---
+
+// swagger:operation POST /.well-known/est/simplereenroll EST EstSimpleReenroll
+// ---
+// description: |
+//   Renews a certificate issued by CMS. The request is authenticated by the TLS client certificate being renewed,
+//   which must neither be revoked nor expired. The base64 encoded PKCS#10 CSR has to keep the subject and the
+//   subject alternative names of the certificate, the certificate type of the renewed certificate is kept.
+//
+// consumes:
+// - application/pkcs10
+// produces:
+// - application/pkcs7-mime
+// responses:
+//   "200":
+//     description: Successfully renewed the certificate, the certs-only PKCS#7 contains the issued and issuing CA certificates.
+//   '400':
+//     description: Invalid CSR provided
+//   '401':
+//     description: Unauthorized
+//   '415':
+//     description: Content type not supported
+//
+// x-sample-call-endpoint: https://cms.com:8445/.well-known/est/simplereenroll
+// ---
@@ -0,0 +1,201 @@
+/*
+ *  Copyright (C) 2021 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package cms
+
+import "github.com/intel-secl/intel-secl/v4/pkg/model/cms"
+
+type IssuedCertificate struct {
+	cms.IssuedCertificate
+}
+
+// IssuedCertificateCollection response payload
+// swagger:response IssuedCertificateCollection
+type IssuedCertificateCollection struct {
+	// in:body
+	Body []IssuedCertificate
+}
+
+// IssuedCertificateResponse response payload
+// swagger:response IssuedCertificateResponse
+type IssuedCertificateResponse struct {
+	// in:body
+	Body IssuedCertificate
+}
+
+// swagger:parameters RevokeCertificateRequest
+type RevokeCertificateRequest struct {
+	// in:body
+	Body cms.RevokeCertificateRequest
+}
+
+// swagger:operation GET /certificates Certificate SearchIssuedCertificates
+// ---
+// description: |
+//   Retrieves the inventory of the certificates issued by CMS. The search can be narrowed down by the
+//   issuing CA, the certificate type and the revocation status. A valid bearer token with the CMS
+//   CertManager role is required to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// parameters:
+// - name: issuingCa
+//   description: Issuing CA of the certificates such as TLS, TLS-Client and Signing.
+//   in: query
+//   type: string
+// - name: certType
+//   description: Certificate type such as TLS, Flavor-Signing, JWT-Signing, Signing and TLS-Client.
+//   in: query
+//   type: string
+// - name: revoked
+//   description: Revocation status of the certificates.
+//   in: query
+//   type: boolean
+// responses:
+//   "200":
+//     description: Successfully retrieved the issued certificates.
+//     schema:
+//       $ref: "#/definitions/IssuedCertificateCollection"
+//   '400':
+//     description: Invalid query parameter provided
+//   '401':
+//     description: Unauthorized
+//
+// x-sample-call-endpoint: https://cms.com:8445/cms/v1/certificates?revoked=false&issuingCa=TLS
+// x-sample-call-output: |
+//   [
+//     {
+//       "serial_number": "1f",
+//       "subject": "HVS TLS Certificate",
+//       "cert_type": "TLS",
+//       "issuing_ca": "TLS",
+//       "not_before": "2021-03-02T10:15:30Z",
+//       "not_after": "2022-03-02T10:15:30Z",
+//       "revoked": false
+//     }
+//   ]
+// ---
+
+// swagger:operation POST /certificates/{serialNumber}/revoke Certificate RevokeCertificate
+// ---
+// description: |
+//   Revokes a certificate issued by CMS. The CRL of the issuing CA is regenerated and the OCSP responder
+//   reports the certificate as revoked. The revocation reason is one of unspecified, key_compromise,
+//   ca_compromise, affiliation_changed, superseded and cessation_of_operation, it defaults to unspecified
+//   when the request body is omitted. A valid bearer token with the CMS CertManager role is required to
+//   authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// parameters:
+// - name: serialNumber
+//   description: Hexadecimal serial number of the certificate.
+//   in: path
+//   type: string
+//   required: true
+// - name: request body
+//   in: body
+//   required: false
+//   schema:
+//     "$ref": "#/definitions/RevokeCertificateRequest"
+// responses:
+//   "200":
+//     description: Successfully revoked the certificate.
+//     schema:
+//       $ref: "#/definitions/IssuedCertificate"
+//   '400':
+//     description: Invalid serial number or revocation reason provided
+//   '401':
+//     description: Unauthorized
+//   '404':
+//     description: Certificate with specified serial number was not issued by CMS
+//
+// x-sample-call-endpoint: https://cms.com:8445/cms/v1/certificates/1f/revoke
+// x-sample-call-input: |
+//   {
+//     "reason": "key_compromise"
+//   }
+// x-sample-call-output: |
+//   {
+//     "serial_number": "1f",
+//     "subject": "HVS TLS Certificate",
+//     "cert_type": "TLS",
+//     "issuing_ca": "TLS",
+//     "not_before": "2021-03-02T10:15:30Z",
+//     "not_after": "2022-03-02T10:15:30Z",
+//     "revoked": true,
+//     "revoked_at": "2021-05-11T08:42:10Z",
+//     "revocation_reason": "key_compromise"
+//   }
+// ---
+
+// swagger:operation GET /crl CRL GetCrl
+// ---
+// description: |
+//   Retrieves the DER encoded certificate revocation list of an issuing CA. The CRL is regenerated
+//   periodically and on every revocation. This URL is embedded as CRL distribution point in the
+//   certificates issued by CMS when the revocation base URL is configured.
+//
+// produces:
+// - application/pkix-crl
+// parameters:
+// - name: issuingCa
+//   description: Issuing CA of the CRL such as TLS, TLS-Client and Signing.
+//   in: query
+//   type: string
+//   required: true
+// responses:
+//   "200":
+//     description: Successfully retrieved the CRL.
+//   '400':
+//     description: Invalid Query parameter issuingCa provided
+//   '404':
+//     description: CRL is not available
+//
+// x-sample-call-endpoint: https://cms.com:8445/cms/v1/crl?issuingCa=TLS
+// ---
+
+// swagger:operation POST /ocsp OCSP OcspRequest
+// ---
+// description: |
+//   OCSP responder of the CMS intermediate CAs as defined by RFC 6960. The responses are signed by the
+//   issuing CA of the certificate. This URL is embedded as OCSP server in the certificates issued by
+//   CMS when the revocation base URL is configured.
+//
+// consumes:
+// - application/ocsp-request
+// produces:
+// - application/ocsp-response
+// responses:
+//   "200":
+//     description: OCSP response, its response status reports malformed or unauthorized requests.
+//
+// x-sample-call-endpoint: https://cms.com:8445/cms/v1/ocsp
+// ---
+
+// swagger:operation GET /ocsp/{request} OCSP OcspGetRequest
+// ---
+// description: |
+//   OCSP responder of the CMS intermediate CAs accepting the base64 and URL encoded DER OCSP request
+//   as path parameter, as defined by RFC 6960 Appendix A.
+//
+// produces:
+// - application/ocsp-response
+// parameters:
+// - name: request
+//   description: Base64 and URL encoded DER OCSP request.
+//   in: path
+//   type: string
+//   required: true
+// responses:
+//   "200":
+//     description: OCSP response, its response status reports malformed or unauthorized requests.
+// ---
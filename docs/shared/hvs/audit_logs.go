@@ -0,0 +1,285 @@
+/*
+ *  Copyright (C) 2021 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package hvs
+
+import "github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+
+// AuditLogEntryCollection response payload
+// swagger:parameters AuditLogEntryCollection
+type AuditLogEntryCollection struct {
+	// in:body
+	Body hvs.AuditLogEntryCollection
+}
+
+// AuditLogChainVerification response payload
+// swagger:parameters AuditLogChainVerification
+type AuditLogChainVerification struct {
+	// in:body
+	Body hvs.AuditLogChainVerification
+}
+
+// AuditLogExportTrailer last line of the audit log export
+// swagger:parameters AuditLogExportTrailer
+type AuditLogExportTrailer struct {
+	// in:body
+	Body hvs.AuditLogExportTrailer
+}
+
+// ---
+
+// swagger:operation GET /audit-logs AuditLogs Search-AuditLogs
+// ---
+// description: |
+//   Searches the audit log entries recorded by HVS when a report or a host status is created, updated or deleted.
+//   The columns of an entry hold the values of the audited record, is_updated is set for the columns changed
+//   by an update.
+//
+//   Each entry is chained to the previous one: its hash is the hex encoded SHA-384 of its canonical JSON encoding,
+//   with the hash field omitted, the object keys sorted and the creation time in UTC, and prev_hash holds the hash
+//   of the entry preceding it in the sequence. The entries written before the chaining was introduced have a
+//   sequence of 0 and no hash.
+//
+//   The search is paginated when any of limit, after or sortBy is provided, the next field of the response then
+//   links to the following page.
+//
+// x-permissions: audit_logs:search
+// security:
+//   - bearerAuth: []
+// produces:
+//   - application/json
+// parameters:
+//   - name: entityType
+//     description: Type of the audited record.
+//     in: query
+//     type: string
+//     enum: [host_status, report, flavor]
+//     required: false
+//   - name: entityId
+//     description: ID of the audited record.
+//     in: query
+//     type: string
+//     format: uuid
+//     required: false
+//   - name: action
+//     description: Action recorded by the entry.
+//     in: query
+//     type: string
+//     enum: [create, update, delete]
+//     required: false
+//   - name: fromDate
+//     description: Returns the entries created on or after the date, in ISO 8601 format.
+//     in: query
+//     type: string
+//     format: date-time
+//     required: false
+//   - name: toDate
+//     description: Returns the entries created on or before the date, in ISO 8601 format.
+//     in: query
+//     type: string
+//     format: date-time
+//     required: false
+//   - name: limit
+//     description: Maximum number of entries returned.
+//     in: query
+//     type: integer
+//     required: false
+//   - name: after
+//     description: Opaque cursor of the last entry of the previous page.
+//     in: query
+//     type: string
+//     required: false
+//   - name: sortBy
+//     description: Field the entries are sorted on.
+//     in: query
+//     type: string
+//     enum: [created, id]
+//     required: false
+//   - name: orderBy
+//     description: Sort order.
+//     in: query
+//     type: string
+//     enum: [asc, desc]
+//     required: false
+//   - name: Accept
+//     description: Accept header
+//     in: header
+//     type: string
+//     required: true
+//     enum:
+//       - application/json
+// responses:
+//   '200':
+//     description: Successfully searched the audit log entries.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/AuditLogEntryCollection"
+//   '400':
+//     description: Invalid search criteria provided
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/audit-logs?entityType=host_status&limit=1
+// x-sample-call-output: |
+//   {
+//       "audit_logs": [
+//           {
+//               "id": "0c4f4a6e-8d1d-4b32-9b8e-55c9f1a0e7d2",
+//               "entity_id": "ee37c360-7eae-4250-a677-6ee12adce8e2",
+//               "entity_type": "host_status",
+//               "created": "2021-03-02T10:15:02.118731Z",
+//               "action": "update",
+//               "columns": [
+//                   {"name": "id", "value": "ee37c360-7eae-4250-a677-6ee12adce8e2", "is_updated": false},
+//                   {"name": "host_id", "value": "4dd5d9b6-34e5-4d8e-9b76-3d2cb0cc3e4e", "is_updated": false},
+//                   {"name": "status", "value": {"host_state": "CONNECTED", "last_time_connected": "2021-03-02T10:15:02.101423Z"}, "is_updated": true},
+//                   {"name": "host_report", "value": null, "is_updated": false},
+//                   {"name": "created", "value": "2021-03-01T08:01:11.219003Z", "is_updated": false}
+//               ],
+//               "sequence": 42,
+//               "prev_hash": "8c1f0d0b5b5d1b0e6a2ff4d2c58d0cbd0f8e4b5a8a7d93a1a0c3d6a9f0e2b7c1d4e6f8a0b2c4d6e8f0a1b3c5d7e9f1a2b3",
+//               "hash": "3a7bd3e2360a3d29eea436fcfb7e44c735d117c42d1c1835420b6b9942dd4f1b0e9a7f3c5d2b1a0f9e8d7c6b5a4f3e2d1c"
+//           }
+//       ],
+//       "next": "/hvs/v2/audit-logs?after=eyJzIjoiY3JlYXRlZCJ9&entityType=host_status&limit=1"
+//   }
+
+// ---
+
+// swagger:operation GET /audit-logs/verify AuditLogs Verify-AuditLogs
+// ---
+// description: |
+//   Verifies the hash chain of the audit log. The entries whose content does not match their hash are reported
+//   as modified, the entries whose prev_hash does not match the hash of their predecessor are reported as broken
+//   links and the gaps in the sequence are reported as missing entries.
+//
+//   The oldest entries are removed when the audit log is rotated, so the chain is allowed to start after the
+//   first sequence. To detect the deletion of the oldest or of the newest entries, an anchor previously recorded,
+//   such as the last sequence and hash from the signature of an export, can be checked against the chain.
+//
+// x-permissions: audit_logs:verify
+// security:
+//   - bearerAuth: []
+// produces:
+//   - application/json
+// parameters:
+//   - name: anchorSequence
+//     description: Sequence of the anchor entry. Required with anchorHash.
+//     in: query
+//     type: integer
+//     required: false
+//   - name: anchorHash
+//     description: Hash of the anchor entry. Required with anchorSequence.
+//     in: query
+//     type: string
+//     required: false
+//   - name: Accept
+//     description: Accept header
+//     in: header
+//     type: string
+//     required: true
+//     enum:
+//       - application/json
+// responses:
+//   '200':
+//     description: Successfully verified the audit log, the verified field of the response tells the outcome.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/AuditLogChainVerification"
+//   '400':
+//     description: Invalid anchor provided
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/audit-logs/verify
+// x-sample-call-output: |
+//   {
+//       "verified": false,
+//       "entry_count": 1203,
+//       "first_sequence": 1,
+//       "last_sequence": 1205,
+//       "last_hash": "5e0b9d8a3c1f2e4d6b8a0c2e4f6a8b0d1c3e5f7a9b1d3f5a7c9e1b3d5f7a9c1e3b5d7f9a1c3e5b7d9f1a3c5e7b9d1f3a",
+//       "missing_entries": [{"from": 311, "to": 312}],
+//       "broken_links": [313]
+//   }
+
+// ---
+
+// swagger:operation GET /audit-logs/export AuditLogs Export-AuditLogs
+// ---
+// description: |
+//   Exports the audit log entries matching the filter criteria as JSON Lines, in sequence order, the entries that
+//   are not chained coming first. Each line holds an entry in the format returned by the search.
+//
+//   The last line holds the signature of the export. The digest is the hex encoded SHA-384 of all the preceding
+//   lines, newlines included, and is signed with the SAML signing key of HVS, whose certificate is provided. An
+//   export without the signature line is incomplete.
+//
+// x-permissions: audit_logs:export
+// security:
+//   - bearerAuth: []
+// produces:
+//   - application/x-ndjson
+// parameters:
+//   - name: entityType
+//     description: Type of the audited record.
+//     in: query
+//     type: string
+//     enum: [host_status, report, flavor]
+//     required: false
+//   - name: entityId
+//     description: ID of the audited record.
+//     in: query
+//     type: string
+//     format: uuid
+//     required: false
+//   - name: action
+//     description: Action recorded by the entry.
+//     in: query
+//     type: string
+//     enum: [create, update, delete]
+//     required: false
+//   - name: fromDate
+//     description: Exports the entries created on or after the date, in ISO 8601 format.
+//     in: query
+//     type: string
+//     format: date-time
+//     required: false
+//   - name: toDate
+//     description: Exports the entries created on or before the date, in ISO 8601 format.
+//     in: query
+//     type: string
+//     format: date-time
+//     required: false
+//   - name: Accept
+//     description: Accept header
+//     in: header
+//     type: string
+//     required: true
+//     enum:
+//       - application/x-ndjson
+// responses:
+//   '200':
+//     description: Successfully exported the audit log.
+//     content: application/x-ndjson
+//     schema:
+//       $ref: "#/definitions/AuditLogExportTrailer"
+//   '400':
+//     description: Invalid filter criteria provided
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/audit-logs/export?fromDate=2021-03-01T00:00:00Z
+// x-sample-call-output: |
+//   {"id":"0c4f4a6e-8d1d-4b32-9b8e-55c9f1a0e7d2","entity_id":"ee37c360-7eae-4250-a677-6ee12adce8e2","entity_type":"host_status","created":"2021-03-02T10:15:02.118731Z","action":"update","columns":[...],"sequence":42,"prev_hash":"8c1f...b3","hash":"3a7b...1c"}
+//   {"signature":{"entry_count":1,"first_sequence":42,"last_sequence":42,"last_hash":"3a7b...1c","digest":"9f2c...e4","algorithm":"RS384","signature":"MEUCIQ...","certificate":"MIID..."}}
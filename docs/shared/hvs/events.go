@@ -0,0 +1,338 @@
+/*
+ *  Copyright (C) 2021 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package hvs
+
+import "github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+
+// EventSubscription request/response payload
+// swagger:parameters EventSubscription
+type EventSubscription struct {
+	// in:body
+	Body hvs.EventSubscription
+}
+
+// EventSubscriptionCollection response payload
+// swagger:parameters EventSubscriptionCollection
+type EventSubscriptionCollection struct {
+	// in:body
+	Body hvs.EventSubscriptionCollection
+}
+
+// TrustEvent payload delivered to the webhooks and to the event stream
+// swagger:parameters TrustEvent
+type TrustEvent struct {
+	// in:body
+	Body hvs.TrustEvent
+}
+
+// ---
+
+// swagger:operation POST /event-subscriptions EventSubscriptions Create-EventSubscription
+// ---
+// description: |
+//   Registers a webhook to which HVS delivers the trust events. A host-trust-changed event is emitted when a new
+//   report changes the trust status of a host, including the first report of a host. A host-state-changed event
+//   is emitted when the connection state of a host changes.
+//
+//   The events are POSTed as JSON to the webhook. The X-HVS-Event header holds the event type and the X-HVS-Signature
+//   header holds "sha256=" followed by the hex encoded HMAC-SHA256 of the request body, keyed with the subscription
+//   secret. The delivery is retried with an exponential backoff when the webhook cannot be reached or returns a 5xx,
+//   408 or 429 status, up to events-webhook-max-attempts times.
+//
+//    | Attribute   | Description |
+//    |-------------|-------------|
+//    | url         | The https URL of the webhook. The CA of the webhook must be trusted by the system or be one of the HVS trusted root CAs. |
+//    | secret      | The key used to sign the payloads. It is stored encrypted and is never returned. |
+//    | event_types | The event types delivered to the webhook, either host-trust-changed or host-state-changed. All the events are delivered when empty. (Optional) |
+//
+// x-permissions: event_subscriptions:create
+// security:
+//   - bearerAuth: []
+// produces:
+//   - application/json
+// consumes:
+//   - application/json
+// parameters:
+//   - name: request body
+//     required: true
+//     in: body
+//     schema:
+//       "$ref": "#/definitions/EventSubscription"
+//   - name: Content-Type
+//     description: Content-Type header
+//     in: header
+//     type: string
+//     required: true
+//     enum:
+//       - application/json
+//   - name: Accept
+//     description: Accept header
+//     in: header
+//     type: string
+//     required: true
+//     enum:
+//       - application/json
+// responses:
+//   '201':
+//     description: Successfully created the event subscription.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/EventSubscription"
+//   '400':
+//     description: Invalid request body provided
+//   '415':
+//     description: Invalid Content-Type/Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/event-subscriptions
+// x-sample-call-input: |
+//   {
+//       "url": "https://siem.example.com/hvs/events",
+//       "secret": "3f9c1a6e0b7d4c2a",
+//       "event_types": ["host-trust-changed"]
+//   }
+// x-sample-call-output: |
+//   {
+//       "id": "1b3b8c3d-5c7a-4e6f-8b2d-3a2f4e5d6c7b",
+//       "url": "https://siem.example.com/hvs/events",
+//       "event_types": ["host-trust-changed"],
+//       "created": "2021-03-02T10:12:45.581431Z"
+//   }
+
+// ---
+
+// swagger:operation GET /event-subscriptions EventSubscriptions Search-EventSubscriptions
+// ---
+// description: |
+//   Searches the event subscriptions.
+//
+// x-permissions: event_subscriptions:search
+// security:
+//   - bearerAuth: []
+// produces:
+//   - application/json
+// parameters:
+//   - name: id
+//     description: Event subscription ID
+//     in: query
+//     type: string
+//     format: uuid
+//     required: false
+//   - name: eventType
+//     description: Returns the subscriptions receiving the given event type, including the subscriptions to all the events.
+//     in: query
+//     type: string
+//     enum: [host-trust-changed, host-state-changed]
+//     required: false
+//   - name: Accept
+//     description: Accept header
+//     in: header
+//     type: string
+//     required: true
+//     enum:
+//       - application/json
+// responses:
+//   '200':
+//     description: Successfully searched the event subscriptions.
+//     content: application/json
+//     schema:
+//       $ref: "#/definitions/EventSubscriptionCollection"
+//   '400':
+//     description: Invalid search criteria provided
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/event-subscriptions?eventType=host-trust-changed
+// x-sample-call-output: |
+//   {
+//       "event_subscriptions": [
+//           {
+//               "id": "1b3b8c3d-5c7a-4e6f-8b2d-3a2f4e5d6c7b",
+//               "url": "https://siem.example.com/hvs/events",
+//               "event_types": ["host-trust-changed"],
+//               "created": "2021-03-02T10:12:45.581431Z"
+//           }
+//       ]
+//   }
+
+// ---
+
+// swagger:operation GET /event-subscriptions/{event_subscription_id} EventSubscriptions Retrieve-EventSubscription
+// ---
+// description: |
+//   Retrieves an event subscription.
+//
+// x-permissions: event_subscriptions:retrieve
+// security:
+//   - bearerAuth: []
+// produces:
+//   - application/json
+// parameters:
+//   - name: event_subscription_id
+//     description: Unique ID of the event subscription.
+//     in: path
+//     required: true
+//     type: string
+//     format: uuid
+//   - name: Accept
+//     description: Accept header
+//     in: header
+//     type: string
+//     required: true
+//     enum:
+//       - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the event subscription.
+//     content: application/json
+//     schema:
+//       $ref: "#/definitions/EventSubscription"
+//   '404':
+//     description: No relevant event subscription found
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/event-subscriptions/1b3b8c3d-5c7a-4e6f-8b2d-3a2f4e5d6c7b
+
+// ---
+
+// swagger:operation PUT /event-subscriptions/{event_subscription_id} EventSubscriptions Update-EventSubscription
+// ---
+// description: |
+//   Updates an event subscription. The attributes that are not provided are left unchanged, in particular the secret
+//   is only replaced when a new one is provided.
+//
+// x-permissions: event_subscriptions:store
+// security:
+//   - bearerAuth: []
+// produces:
+//   - application/json
+// consumes:
+//   - application/json
+// parameters:
+//   - name: event_subscription_id
+//     description: Unique ID of the event subscription.
+//     in: path
+//     required: true
+//     type: string
+//     format: uuid
+//   - name: request body
+//     required: true
+//     in: body
+//     schema:
+//       "$ref": "#/definitions/EventSubscription"
+//   - name: Content-Type
+//     description: Content-Type header
+//     in: header
+//     type: string
+//     required: true
+//     enum:
+//       - application/json
+//   - name: Accept
+//     description: Accept header
+//     in: header
+//     type: string
+//     required: true
+//     enum:
+//       - application/json
+// responses:
+//   '200':
+//     description: Successfully updated the event subscription.
+//     content: application/json
+//     schema:
+//       $ref: "#/definitions/EventSubscription"
+//   '400':
+//     description: Invalid request body provided
+//   '404':
+//     description: No relevant event subscription found
+//   '415':
+//     description: Invalid Content-Type/Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/event-subscriptions/1b3b8c3d-5c7a-4e6f-8b2d-3a2f4e5d6c7b
+// x-sample-call-input: |
+//   {
+//       "event_types": ["host-trust-changed", "host-state-changed"]
+//   }
+
+// ---
+
+// swagger:operation DELETE /event-subscriptions/{event_subscription_id} EventSubscriptions Delete-EventSubscription
+// ---
+// description: |
+//   Deletes an event subscription.
+//
+// x-permissions: event_subscriptions:delete
+// security:
+//   - bearerAuth: []
+// parameters:
+//   - name: event_subscription_id
+//     description: Unique ID of the event subscription.
+//     in: path
+//     required: true
+//     type: string
+//     format: uuid
+// responses:
+//   '204':
+//     description: Successfully deleted the event subscription.
+//   '404':
+//     description: No relevant event subscription found
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/event-subscriptions/1b3b8c3d-5c7a-4e6f-8b2d-3a2f4e5d6c7b
+
+// ---
+
+// swagger:operation GET /events/stream Events Stream-Events
+// ---
+// description: |
+//   Streams the trust events as server-sent events until the client disconnects. Each event is sent with its ID,
+//   its type and its JSON payload. Events are dropped for the clients that do not keep up with the stream.
+//   The stream is closed by HVS after the configured server write timeout, clients are expected to reconnect.
+//
+// x-permissions: events:stream
+// security:
+//   - bearerAuth: []
+// produces:
+//   - text/event-stream
+// parameters:
+//   - name: eventType
+//     description: Only streams the events of the given type.
+//     in: query
+//     type: string
+//     enum: [host-trust-changed, host-state-changed]
+//     required: false
+//   - name: Accept
+//     description: Accept header
+//     in: header
+//     type: string
+//     required: true
+//     enum:
+//       - text/event-stream
+// responses:
+//   '200':
+//     description: Successfully opened the event stream.
+//     content: text/event-stream
+//     schema:
+//       $ref: "#/definitions/TrustEvent"
+//   '400':
+//     description: Invalid event type provided
+//   '415':
+//     description: Invalid Accept Header in Request
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/events/stream?eventType=host-trust-changed
+// x-sample-call-output: |
+//   id: 5a6b7c8d-1e2f-4a3b-9c4d-5e6f7a8b9c0d
+//   event: host-trust-changed
+//   data: {"id":"5a6b7c8d-1e2f-4a3b-9c4d-5e6f7a8b9c0d","type":"host-trust-changed","created":"2021-03-02T10:15:02.118731Z","host_id":"ee37c360-7eae-4250-a677-6ee12adce8e2","report_id":"0b4c2e1a-4a43-4f86-a7b8-2cd2bd3b8d25","trusted":false,"previous_trusted":true}
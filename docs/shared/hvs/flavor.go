@@ -31,6 +31,55 @@ type SignedFlavorCollection struct {
 	Body hvs.SignedFlavorCollection
 }
 
+// Flavor lifecycle API request payload
+// swagger:parameters FlavorLifecycleRequest
+type FlavorLifecycleRequest struct {
+	// in:body
+	Body hvs.FlavorLifecycleRequest
+}
+
+// Flavor lifecycle API response payload
+// swagger:parameters FlavorLifecycle
+type FlavorLifecycle struct {
+	// in:body
+	Body hvs.FlavorLifecycle
+}
+
+// Flavors dry run API request payload
+// swagger:parameters FlavorDryRunRequest
+type FlavorDryRunRequest struct {
+	// in:body
+	Body hvs.FlavorDryRunRequest
+}
+
+// Flavors dry run API response payload
+// swagger:parameters FlavorDryRunResponse
+type FlavorDryRunResponse struct {
+	// in:body
+	Body hvs.FlavorDryRunResponse
+}
+
+// Flavors export and import API payload
+// swagger:parameters FlavorBundle
+type FlavorBundle struct {
+	// in:body
+	Body hvs.FlavorBundle
+}
+
+// Flavors import API response payload
+// swagger:parameters FlavorBundleImportResult
+type FlavorBundleImportResult struct {
+	// in:body
+	Body hvs.FlavorBundleImportResult
+}
+
+// Flavor diff API response payload
+// swagger:parameters FlavorDiff
+type FlavorDiff struct {
+	// in:body
+	Body hvs.FlavorDiff
+}
+
 // ---
 //
 // swagger:operation GET /flavors Flavors Search-Flavors
@@ -79,6 +128,34 @@ type SignedFlavorCollection struct {
 //   in: query
 //   type: string
 //   required: false
+// - name: limit
+//   description: Maximum number of records in the page. Enables paging of the results, the response carries a "next" link when more records are available.
+//   in: query
+//   type: integer
+//   minimum: 1
+//   default: 10000
+//   required: false
+// - name: after
+//   description: Opaque cursor identifying the last record of the previous page. It is obtained from the "next" link of the previous response and must be used with the same sortBy.
+//   in: query
+//   type: string
+//   required: false
+// - name: sortBy
+//   description: Field the paged records are ordered on, the record ID being used as a tie-breaker. Defaults to "label".
+//   in: query
+//   type: string
+//   enum:
+//      - label
+//      - id
+//   required: false
+// - name: orderBy
+//   description: Orders the paged records in ascending/descending order. Accepted values are "asc"/"desc" the default being "asc".
+//   in: query
+//   type: string
+//   enum:
+//      - asc
+//      - desc
+//   required: false
 // - name: Accept
 //   description: Accept header
 //   in: header
@@ -164,6 +241,7 @@ type SignedFlavorCollection struct {
 //    | signed_flavors                 | (Optional) This is collection of signed flavors consisting of flavor and signature provided by user. |
 //    | flavorgroup_names              | (Optional) Flavor group names that the created flavor(s) will be associated with. If not provided, created flavor will be associated with automatic flavor group. |
 //    | partial_flavor_types           | (Optional) List array input of flavor types to be imported from a host. Partial flavor type can be any of the following: PLATFORM, OS, ASSET_TAG, HOST_UNIQUE, SOFTWARE. Can be provided with the host connection string. See the product guide for more details on how flavor types are broken down for each host type. |
+//    | lifecycle                      | (Optional) Lifecycle state of the created flavor(s), either staged or active, along with the optional not_before and not_after dates. Flavors are created active by default. |
 //
 // x-permissions: flavors:create
 // security:
@@ -564,3 +642,598 @@ type SignedFlavorCollection struct {
 // x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/flavors/f66ac31d-124d-418e-8200-2abf414a9adf
 
 // ---
+
+// swagger:operation GET /flavors/{flavor_id}/lifecycle Flavors Retrieve-Flavor-Lifecycle
+// ---
+//
+// description: |
+//   Retrieves the lifecycle state of a flavor.
+//
+//    | State      | Description                                     |
+//    |------------|-------------------------------------------------|
+//    | staged     | The flavor is evaluated in shadow. Its results are reported in the shadow_results of the trust report and do not affect the trust status. |
+//    | active     | The flavor is verified as usual. |
+//    | deprecated | The flavor is still verified, its use is reported in the warnings of the trust report. When it does not match the host, its faults are reported as warnings instead of affecting the trust status. |
+//    | revoked    | The flavor is no longer verified. A revoked flavor cannot be moved to another state. |
+//
+//   An active or deprecated flavor is evaluated in shadow until its not_before date and any flavor is handled as
+//   revoked from its not_after date.
+// x-permissions: flavors:retrieve
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// parameters:
+// - name: flavor_id
+//   description: Unique UUID of the flavor.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the flavor lifecycle.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/FlavorLifecycle"
+//   '404':
+//     description: No flavor with the provided flavor ID found.
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/flavors/f66ac31d-124d-418e-8200-2abf414a9adf/lifecycle
+// x-sample-call-output: |
+//      {
+//          "flavor_id": "f66ac31d-124d-418e-8200-2abf414a9adf",
+//          "state": "deprecated",
+//          "not_after": "2021-12-31T00:00:00Z"
+//      }
+
+// ---
+
+// swagger:operation PUT /flavors/{flavor_id}/lifecycle Flavors Update-Flavor-Lifecycle
+// ---
+//
+// description: |
+//   Moves a flavor to a lifecycle state. The dates that are not provided are cleared. A staged flavor can be moved to
+//   the active or revoked state, an active or deprecated flavor can be moved to any state and a revoked flavor
+//   cannot be moved. The transition is recorded in the audit log and the hosts linked to the flavor are added to the
+//   flavor verification queue to re-evaluate their trust status.
+//
+//    | Attribute                      | Description                                     |
+//    |--------------------------------|-------------------------------------------------|
+//    | state                          | Lifecycle state: staged, active, deprecated or revoked. |
+//    | not_before                     | (Optional) Date from which the flavor is in effect, it is evaluated in shadow before. |
+//    | not_after                      | (Optional) Date from which the flavor is handled as revoked. |
+//
+// x-permissions: flavors:lifecycle_update
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// consumes:
+// - application/json
+// parameters:
+// - name: flavor_id
+//   description: Unique UUID of the flavor.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: request body
+//   required: true
+//   in: body
+//   schema:
+//    "$ref": "#/definitions/FlavorLifecycleRequest"
+// - name: Content-Type
+//   description: Content-Type header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully updated the flavor lifecycle.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/FlavorLifecycle"
+//   '400':
+//     description: Invalid request body provided or transition not allowed
+//   '404':
+//     description: No flavor with the provided flavor ID found.
+//   '415':
+//     description: Invalid Content-Type/Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/flavors/f66ac31d-124d-418e-8200-2abf414a9adf/lifecycle
+// x-sample-call-input: |
+//      {
+//          "state": "deprecated",
+//          "not_after": "2021-12-31T00:00:00Z"
+//      }
+// x-sample-call-output: |
+//      {
+//          "flavor_id": "f66ac31d-124d-418e-8200-2abf414a9adf",
+//          "state": "deprecated",
+//          "not_after": "2021-12-31T00:00:00Z"
+//      }
+
+// ---
+
+// swagger:operation POST /flavors/dry-run Flavors Dry-Run-Flavors
+// ---
+//
+// description: |
+//   Evaluates the trust of hosts against a flavorgroup as if candidate flavors were added to it, without creating
+//   the flavors, updating the flavor trust cache or storing reports. The latest stored manifest of each host is
+//   verified, no connection is made to the hosts.
+//
+//   The candidate flavors are verified along with the flavors of the flavorgroup. A candidate replaces the stored
+//   flavors of its flavor part when the flavor part has a LATEST match policy. The signature of the candidate flavors
+//   is not verified.
+//
+//    | Attribute                      | Description                                     |
+//    |--------------------------------|-------------------------------------------------|
+//    | flavorgroup_name               | Name of the flavorgroup to evaluate. |
+//    | flavor_collection              | (Optional) Candidate flavors. |
+//    | signed_flavor_collection       | (Optional) Candidate signed flavors. |
+//    | flavor_match_policies          | (Optional) Match policies replacing those of the flavorgroup. Each candidate flavor part must have a match policy. |
+//    | host_ids                       | (Optional) Hosts to evaluate. |
+//    | host_names                     | (Optional) Hosts to evaluate. All the hosts linked to the flavorgroup are evaluated when host_ids and host_names are not provided. |
+//
+// x-permissions: flavors:dry_run
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// consumes:
+// - application/json
+// parameters:
+// - name: request body
+//   required: true
+//   in: body
+//   schema:
+//    "$ref": "#/definitions/FlavorDryRunRequest"
+// - name: Content-Type
+//   description: Content-Type header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully evaluated the hosts.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/FlavorDryRunResponse"
+//   '400':
+//     description: Invalid request body provided, unknown flavorgroup or host
+//   '415':
+//     description: Invalid Content-Type/Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/flavors/dry-run
+// x-sample-call-input: |
+//      {
+//          "flavorgroup_name": "automatic",
+//          "flavor_match_policies": [
+//              {
+//                  "flavor_part": "PLATFORM",
+//                  "match_policy": {
+//                      "match_type": "LATEST",
+//                      "required": "REQUIRED"
+//                  }
+//              }
+//          ],
+//          "host_names": ["computepurley1"]
+//      }
+// x-sample-call-output: |
+//      {
+//          "flavorgroup_name": "automatic",
+//          "flavor_match_policies": [
+//              {
+//                  "flavor_part": "PLATFORM",
+//                  "match_policy": {
+//                      "match_type": "LATEST",
+//                      "required": "REQUIRED"
+//                  }
+//              }
+//          ],
+//          "trusted_count": 0,
+//          "untrusted_count": 1,
+//          "error_count": 0,
+//          "host_results": [
+//              {
+//                  "host_id": "ee37c360-7eae-4250-a677-6ee12adce8e2",
+//                  "host_name": "computepurley1",
+//                  "trusted": false,
+//                  "failed_results": [
+//                      {
+//                          "rule": {
+//                              "rule_name": "PcrMatchesConstant",
+//                              "markers": ["PLATFORM"],
+//                              "expected_pcr": {
+//                                  "pcr": {"index": 0, "bank": "SHA256"},
+//                                  "measurement": "8b2f1d4a9e0f4c1a7f7d4e3b2a1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c",
+//                                  "pcr_matches": true
+//                              }
+//                          },
+//                          "flavor_id": "f66ac31d-124d-418e-8200-2abf414a9adf",
+//                          "faults": [
+//                              {
+//                                  "fault_name": "PcrValueMismatchSHA256",
+//                                  "description": "Host PCR 0 with value '3f95ecbb0bb8e66e54d3f9e4dbae8fe57fed96f0' does not match expected value '8b2f1d4a9e0f4c1a7f7d4e3b2a1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c'"
+//                              }
+//                          ],
+//                          "trusted": false
+//                      }
+//                  ]
+//              }
+//          ]
+//      }
+
+// ---
+
+// swagger:operation GET /flavors/export Flavors Export-Flavors
+// ---
+//
+// description: |
+//   Exports flavorgroups along with their match policies, their flavor templates and their flavors as a bundle
+//   signed with the flavor signing key of the HVS, so that they can be imported in another HVS. Only the flavors in
+//   effect, that is active or deprecated, are exported.
+//
+//   The manifest of the bundle lists every flavorgroup, flavor template and flavor of the bundle with the SHA384
+//   digest of its content. The signature is computed over the manifest and the certificate chain of the signing key
+//   is included in the bundle.
+//
+// x-permissions: flavors:export
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// parameters:
+// - name: flavorgroupName
+//   description: Name of a flavorgroup to export, can be repeated. All the flavorgroups are exported when not provided.
+//   in: query
+//   type: string
+//   required: false
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully exported the flavors.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/FlavorBundle"
+//   '400':
+//     description: Invalid query parameter provided or unknown flavorgroup
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/flavors/export?flavorgroupName=automatic
+// x-sample-call-output: |
+//      {
+//          "manifest": {
+//              "version": "1.0",
+//              "created_at": "2021-06-01T10:00:00.000000Z",
+//              "entries": [
+//                  {
+//                      "type": "flavorgroup",
+//                      "id": "ee37c360-7eae-4250-a677-6ee12adce8e2",
+//                      "name": "automatic",
+//                      "digest": "6c4ad1fbb2a0bd9f0c3a7cb1e0f6d6f1d7a5e1f5a2c77e8b0e3d1cc6c5e8a3b9f4b2a1e0d9c8b7a6f5e4d3c2b1a0f9e8"
+//                  },
+//                  {
+//                      "type": "flavor_template",
+//                      "id": "426912bd-39b0-4daa-ad21-0c6933230b50",
+//                      "name": "default-pfr",
+//                      "digest": "0a9f4e3c1b2d8e7f6a5b4c3d2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b8"
+//                  },
+//                  {
+//                      "type": "flavor",
+//                      "id": "f66ac31d-124d-418e-8200-2abf414a9adf",
+//                      "name": "INTEL_IntelCorporation_SE5C620.86B.00.01.0014.070920180847_TXT_TPM_06-16-2020",
+//                      "digest": "e1b4f8d0c9a7b6e5d4c3b2a1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a1f0e9d8c7"
+//                  }
+//              ]
+//          },
+//          "flavorgroups": [
+//              {
+//                  "id": "ee37c360-7eae-4250-a677-6ee12adce8e2",
+//                  "name": "automatic",
+//                  "flavorIds": ["f66ac31d-124d-418e-8200-2abf414a9adf"],
+//                  "flavorTemplateIds": ["426912bd-39b0-4daa-ad21-0c6933230b50"],
+//                  "flavor_match_policies": [
+//                      {
+//                          "flavor_part": "PLATFORM",
+//                          "match_policy": {
+//                              "match_type": "ANY_OF",
+//                              "required": "REQUIRED"
+//                          }
+//                      }
+//                  ]
+//              }
+//          ],
+//          "flavor_templates": [
+//              {
+//                  "id": "426912bd-39b0-4daa-ad21-0c6933230b50",
+//                  "label": "default-pfr",
+//                  "condition": ["//host_info/tpm_version//*[text()='2.0']"],
+//                  "flavor_parts": {}
+//              }
+//          ],
+//          "signed_flavors": [
+//              {
+//                  "flavor": {
+//                      "meta": {
+//                          "id": "f66ac31d-124d-418e-8200-2abf414a9adf",
+//                          "description": {
+//                              "flavor_part": "PLATFORM",
+//                              "label": "INTEL_IntelCorporation_SE5C620.86B.00.01.0014.070920180847_TXT_TPM_06-16-2020"
+//                          }
+//                      },
+//                      "pcrs": []
+//                  },
+//                  "signature": "EyuFK0QJu0WkRY5JSmDVhkELl6qqOfMrJdVGG1yTRzk8V9J0Jg5AbSaPsEJ1XZrHdtp4F2d0kM3cJzi6Ku7XGdFQAe+Vc4XdI43I+Z6ncOkaTjKrQH6Cd47FmyVZx9ye/fVzqSWkMBAmyEI9Oafhw=="
+//              }
+//          ],
+//          "signature": "Hl6qRZK3fYLg2X8nqD0qCfAy7zN8bZ9K+3wZ4LhGk8o5d0KwNjI2xY1mEhPz4VtQ0aCwXnLqGb7dYf3uS9eJrTmB1kA6oH2vIcW5yDpF==",
+//          "signing_certificates": "-----BEGIN CERTIFICATE-----\nMIIEoDCCAwigAwIBAgIBATANBgkqhkiG9w0BAQwFADBQ...\n-----END CERTIFICATE-----\n"
+//      }
+
+// ---
+
+// swagger:operation POST /flavors/import Flavors Import-Flavors
+// ---
+//
+// description: |
+//   Imports a flavor bundle exported by another HVS. The bundle is rejected unless its signing certificate is issued
+//   by a flavor CA trusted by this HVS, its signature is valid, its manifest lists its content with matching
+//   digests and each flavor signature is valid.
+//
+//   Flavor templates and flavors are matched by id, flavorgroups by name. A record that already exists is skipped,
+//   a record with the same id but a different digest, or a flavor with the label of another flavor, is skipped as a
+//   conflict. Existing flavorgroups keep their match policies. The added flavors are active and signed with the
+//   flavor signing key of this HVS, the hosts of their flavorgroups are added to the flavor verification queue.
+//
+// x-permissions: flavors:import
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// consumes:
+// - application/json
+// parameters:
+// - name: request body
+//   required: true
+//   in: body
+//   schema:
+//    "$ref": "#/definitions/FlavorBundle"
+// - name: Content-Type
+//   description: Content-Type header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully imported the flavor bundle.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/FlavorBundleImportResult"
+//   '400':
+//     description: Invalid request body provided or flavor bundle verification failed
+//   '415':
+//     description: Invalid Content-Type/Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/flavors/import
+// x-sample-call-input: |
+//      Flavor bundle returned by GET /flavors/export
+// x-sample-call-output: |
+//      {
+//          "added": [
+//              {
+//                  "type": "flavor",
+//                  "id": "f66ac31d-124d-418e-8200-2abf414a9adf",
+//                  "name": "INTEL_IntelCorporation_SE5C620.86B.00.01.0014.070920180847_TXT_TPM_06-16-2020"
+//              }
+//          ],
+//          "skipped": [
+//              {
+//                  "type": "flavorgroup",
+//                  "id": "d3c85e02-0c1c-4a1b-9b8f-c1a2e8a0b8a4",
+//                  "name": "automatic",
+//                  "reason": "Flavorgroup already exists"
+//              },
+//              {
+//                  "type": "flavor_template",
+//                  "id": "426912bd-39b0-4daa-ad21-0c6933230b50",
+//                  "name": "default-pfr",
+//                  "reason": "Flavor template already exists"
+//              }
+//          ]
+//      }
+
+// ---
+
+// swagger:operation GET /flavors/{flavor_id}/diff Flavors Diff-Flavor
+// ---
+//
+// description: |
+//   Compares the PCRs of a flavor with the ones of another flavor or of the latest manifest stored for a host. Only
+//   the PCRs that differ are listed, with their expected and actual measurements and the events that were added,
+//   removed or changed. Events are matched on their measurement and type_id, an event is changed when its type_name
+//   or tags differ.
+//
+//    | Status  | Description                                                          |
+//    |---------|----------------------------------------------------------------------|
+//    | added   | The PCR is not in the flavor.                                        |
+//    | removed | The PCR of the flavor is missing from the other flavor or host manifest. |
+//    | changed | The measurement or the events of the PCR differ.                     |
+//
+//   When compared with a host manifest, the host events carrying the exclude_tags of the flavor are ignored and the
+//   host events that are not in the flavor are only reported for the PCRs expecting an equal event log.
+//
+//   The candidate flavor is the flavor updated with the differences, so that it matches the other flavor or the
+//   host. It has no id and is not signed: it is meant to be reviewed and then created with POST /flavors.
+//
+// x-permissions: flavors:diff
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// parameters:
+// - name: flavor_id
+//   description: Unique UUID of the flavor.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: otherFlavorId
+//   description: Unique UUID of the flavor to compare with. Either otherFlavorId or hostId must be provided.
+//   in: query
+//   type: string
+//   format: uuid
+//   required: false
+// - name: hostId
+//   description: Unique UUID of the host whose latest manifest is compared with. Either otherFlavorId or hostId must be provided.
+//   in: query
+//   type: string
+//   format: uuid
+//   required: false
+// - name: includeCandidateFlavor
+//   description: Returns the candidate updated flavor when true.
+//   in: query
+//   type: boolean
+//   required: false
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully compared the flavor.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/FlavorDiff"
+//   '400':
+//     description: Invalid query parameter provided, unknown other flavor or host, or no manifest stored for the host
+//   '404':
+//     description: No flavor with the provided flavor ID found.
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/flavors/f66ac31d-124d-418e-8200-2abf414a9adf/diff?hostId=ee37c360-7eae-4250-a677-6ee12adce8e2
+// x-sample-call-output: |
+//      {
+//          "flavor_id": "f66ac31d-124d-418e-8200-2abf414a9adf",
+//          "host_id": "ee37c360-7eae-4250-a677-6ee12adce8e2",
+//          "pcrs": [
+//              {
+//                  "pcr": {
+//                      "index": 0,
+//                      "bank": "SHA256"
+//                  },
+//                  "added_events": [
+//                      {
+//                          "type_id": "0x1",
+//                          "type_name": "EV_POST_CODE",
+//                          "tags": [
+//                              "Microcode"
+//                          ],
+//                          "measurement": "b08f1b4b4c1d8e0f4fc9a1cd1ec9b4f5e8e36a1ef05ba4fa92d6a8d2c7c5e9f1"
+//                      }
+//                  ],
+//                  "removed_events": [
+//                      {
+//                          "type_id": "0x1",
+//                          "type_name": "EV_POST_CODE",
+//                          "tags": [
+//                              "Microcode"
+//                          ],
+//                          "measurement": "3a8a5f1c1b0f3c5e9d5e7b0d1a4fb8a2e7c1d3f5a9b8c7d6e5f4a3b2c1d0e9f8"
+//                      }
+//                  ],
+//                  "changed_events": [
+//                      {
+//                          "expected": {
+//                              "type_id": "0x8",
+//                              "type_name": "EV_S_CRTM_VERSION",
+//                              "tags": [
+//                                  "BootGuard"
+//                              ],
+//                              "measurement": "96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7"
+//                          },
+//                          "actual": {
+//                              "type_id": "0x8",
+//                              "type_name": "EV_S_CRTM_VERSION",
+//                              "tags": [
+//                                  "BootGuardV2"
+//                              ],
+//                              "measurement": "96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7"
+//                          }
+//                      }
+//                  ],
+//                  "status": "changed",
+//                  "expected_measurement": "9c2a5b36a3b7a6e1b1a6c0f4f4e5e2d3c9b8a7f6e5d4c3b2a1f0e9d8c7b6a5f4",
+//                  "actual_measurement": "2f1e0d9c8b7a6f5e4d3c2b1a0f9e8d7c6b5a4f3e2d1c0b9a8f7e6d5c4b3a2f1e"
+//              }
+//          ]
+//      }
+
+// ---
@@ -122,6 +122,34 @@ type FlavorgroupFlavorLinkCollection struct {
 //   in: query
 //   type: boolean
 //   required: false
+// - name: limit
+//   description: Maximum number of records in the page. Enables paging of the results, the response carries a "next" link when more records are available.
+//   in: query
+//   type: integer
+//   minimum: 1
+//   default: 10000
+//   required: false
+// - name: after
+//   description: Opaque cursor identifying the last record of the previous page. It is obtained from the "next" link of the previous response and must be used with the same sortBy.
+//   in: query
+//   type: string
+//   required: false
+// - name: sortBy
+//   description: Field the paged records are ordered on, the record ID being used as a tie-breaker. Defaults to "name".
+//   in: query
+//   type: string
+//   enum:
+//      - name
+//      - id
+//   required: false
+// - name: orderBy
+//   description: Orders the paged records in ascending/descending order. Accepted values are "asc"/"desc" the default being "asc".
+//   in: query
+//   type: string
+//   enum:
+//      - asc
+//      - desc
+//   required: false
 // - name: Accept
 //   required: true
 //   in: header
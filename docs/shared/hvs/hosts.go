@@ -27,6 +27,13 @@ type HostCreateRequest struct {
 	Body hvs.HostCreateRequest
 }
 
+// HostLabelTrustSummary response payload
+// swagger:parameters HostLabelTrustSummary
+type HostLabelTrustSummary struct {
+	// in:body
+	Body hvs.HostLabelTrustSummary
+}
+
 // HostFlavorgroup response payload
 // swagger:parameters HostFlavorgroup
 type HostFlavorgroup struct {
@@ -48,6 +55,20 @@ type HostFlavorgroupCreateRequest struct {
 	Body hvs.HostFlavorgroupCreateRequest
 }
 
+// HostImportRequest request payload
+// swagger:parameters HostImportRequest
+type HostImportRequest struct {
+	// in:body
+	Body hvs.HostImportRequest
+}
+
+// HostImportJob response payload
+// swagger:parameters HostImportJob
+type HostImportJob struct {
+	// in:body
+	Body hvs.HostImportJob
+}
+
 // ---
 
 // swagger:operation POST /hosts Hosts CreateHost
@@ -77,6 +98,7 @@ type HostFlavorgroupCreateRequest struct {
 //    | connection_string | The host connection string. |
 //    | flavorgroup_names | List of flavor group names that the created host will be associated. |
 //    | description       | Host description. |
+//    | labels            | User defined labels of the host, e.g. {"env": "prod", "rack": "12"}. Keys and values are made of at most 63 alphanumeric characters, "-", "_" and ".", keys also allowing "/". |
 //
 // x-permissions: hosts:create
 // security:
@@ -218,6 +240,7 @@ type HostFlavorgroupCreateRequest struct {
 //    | connection_string | The host connection string. |
 //    | flavorgroup_names | List of flavor group names that the created host will be associated. |
 //    | description       | Host description. |
+//    | labels            | User defined labels of the host, replacing the existing ones when provided. |
 //
 //
 //
@@ -379,6 +402,13 @@ type HostFlavorgroupCreateRequest struct {
 //   in: query
 //   type: boolean
 //   required: false
+// - name: labelSelector
+//   description: |
+//     Comma separated label requirements the hosts must all meet, e.g. "env=prod,rack in (12,13),cluster!=a". The supported operators are "=", "!=", "in" and "notin", the "!=" and "notin" requirements also selecting the hosts that do not have the label.
+//     It can be specified along with the identifying parameter.
+//   in: query
+//   type: string
+//   required: false
 // - name: getTrustStatus
 //   description: Get trust status for host.
 //   in: query
@@ -397,6 +427,26 @@ type HostFlavorgroupCreateRequest struct {
 //      - asc
 //      - desc
 //   required: false
+// - name: limit
+//   description: Maximum number of records in the page. Enables paging of the results, the response carries a "next" link when more records are available.
+//   in: query
+//   type: integer
+//   minimum: 1
+//   default: 10000
+//   required: false
+// - name: after
+//   description: Opaque cursor identifying the last record of the previous page. It is obtained from the "next" link of the previous response and must be used with the same sortBy.
+//   in: query
+//   type: string
+//   required: false
+// - name: sortBy
+//   description: Field the paged records are ordered on, the record ID being used as a tie-breaker. Defaults to "name".
+//   in: query
+//   type: string
+//   enum:
+//      - name
+//      - id
+//   required: false
 // - name: Accept
 //   description: Accept header
 //   in: header
@@ -437,6 +487,79 @@ type HostFlavorgroupCreateRequest struct {
 //
 // ---
 
+// swagger:operation GET /hosts/trust-summary Hosts SummarizeHostTrust
+// ---
+//
+// description: |
+//   <b>Summarizes the trust status of the hosts by label.</b>
+//   <pre>
+//   Counts the trusted, untrusted and unknown hosts for each value of the given label. The hosts that do not have
+//   the label are left out and the hosts without a trust report are counted as unknown.</br>
+//   </pre>
+//
+//   Returns - The serialized HostLabelTrustSummary Go struct object.
+//
+// x-permissions: hosts:search
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: labelKey
+//   description: Key of the label by which the hosts are counted.
+//   in: query
+//   type: string
+//   required: true
+// - name: labelSelector
+//   description: Comma separated label requirements the counted hosts must all meet, e.g. "env=prod,rack in (12,13)".
+//   in: query
+//   type: string
+//   required: false
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully summarized the trust status of the hosts.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/HostLabelTrustSummary"
+//   '400':
+//     description: Invalid values for request params
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/hosts/trust-summary?labelKey=rack&labelSelector=env%3Dprod
+// x-sample-call-output: |
+//    {
+//        "label_key": "rack",
+//        "values": [
+//            {
+//                "value": "12",
+//                "total": 3,
+//                "trusted": 2,
+//                "untrusted": 1,
+//                "unknown": 0
+//            },
+//            {
+//                "value": "13",
+//                "total": 2,
+//                "trusted": 1,
+//                "untrusted": 0,
+//                "unknown": 1
+//            }
+//        ]
+//    }
+
+// ---
+
 // swagger:operation POST /hosts/{host_id}/flavorgroups HostFlavorgroupLinks CreateHostFlavorgroupLink
 // ---
 //
@@ -649,3 +772,266 @@ type HostFlavorgroupCreateRequest struct {
 //            }
 //        ]
 //    }
+// ---
+
+// swagger:operation POST /hosts/bulk Hosts CreateHostImportJob
+// ---
+//
+// description: |
+//   <b>Registers hosts in bulk.</b>
+//   <pre>
+//   The hosts are registered in the background, the same way as with the POST /hosts API, with at most
+//   host-import.concurrency hosts being registered at the same time. The returned job ID is used to retrieve the
+//   result of the registration of each host and to retry the hosts that failed to register.</br>
+//   The username and password of a host are added to its connection string when it does not hold credentials already.
+//   They are stored encrypted for the job to be retried and are never returned.</br>
+//   A host whose name was already provided in a previous row is failed without being registered.</br>
+//   At most 10000 hosts can be imported at once.</br>
+//   </pre>
+//
+//   The request body is either a serialized HostImportRequest Go struct object or CSV records, the first record
+//   naming the columns. The host_name and connection_string columns are required, the flavor group names of the CSV
+//   records are separated with ';'.
+//
+//    | Attribute         | Description |
+//    |-------------------|-------------|
+//    | host_name         | HVS name for the host. |
+//    | connection_string | The host connection string. |
+//    | flavorgroup_names | List of flavor group names that the created host will be associated. |
+//    | description       | Host description. |
+//    | username          | Username added to the connection string. |
+//    | password          | Password added to the connection string. |
+//
+// x-permissions: hosts:create
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// consumes:
+// - application/json
+// - text/csv
+// parameters:
+// - name: request body
+//   required: true
+//   in: body
+//   schema:
+//    "$ref": "#/definitions/HostImportRequest"
+// - name: Content-Type
+//   description: Content-Type header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+//     - text/csv
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '202':
+//     description: Successfully submitted the host import job.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/HostImportJob"
+//   '400':
+//     description: Invalid request body provided
+//   '415':
+//     description: Invalid Content-Type or Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/hosts/bulk
+// x-sample-call-input: |
+//    {
+//        "hosts": [
+//            {
+//                "host_name": "Purley host1",
+//                "connection_string": "intel:https://trustagent1.server.com:1443",
+//                "flavorgroup_names": ["automatic"],
+//                "username": "tagentadmin",
+//                "password": "password"
+//            },
+//            {
+//                "host_name": "Purley host2",
+//                "connection_string": "intel:https://trustagent2.server.com:1443"
+//            }
+//        ]
+//    }
+// x-sample-call-output: |
+//    {
+//        "id": "6b2c9f8e-1c1e-4a43-9e3a-0a5d8c2c7f10",
+//        "status": "running",
+//        "created": "2021-06-01T10:00:00.000000Z",
+//        "updated": "2021-06-01T10:00:00.000000Z",
+//        "total": 2,
+//        "pending": 2,
+//        "succeeded": 0,
+//        "failed": 0,
+//        "rows": [
+//            {
+//                "row": 1,
+//                "host_name": "Purley host1",
+//                "connection_string": "intel:https://trustagent1.server.com:1443",
+//                "status": "pending",
+//                "attempts": 0
+//            },
+//            {
+//                "row": 2,
+//                "host_name": "Purley host2",
+//                "connection_string": "intel:https://trustagent2.server.com:1443",
+//                "status": "pending",
+//                "attempts": 0
+//            }
+//        ]
+//    }
+// ---
+
+// swagger:operation GET /hosts/bulk/{job_id} Hosts RetrieveHostImportJob
+// ---
+//
+// description: |
+//   Retrieves a host import job along with the result of the registration of each of its hosts.
+//   Returns - The serialized HostImportJob Go struct object that was retrieved.
+// x-permissions: hosts:retrieve
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// parameters:
+// - name: job_id
+//   description: Unique ID of the host import job.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the host import job.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/HostImportJob"
+//   '404':
+//     description: Host import job record not found
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/hosts/bulk/6b2c9f8e-1c1e-4a43-9e3a-0a5d8c2c7f10
+// x-sample-call-output: |
+//    {
+//        "id": "6b2c9f8e-1c1e-4a43-9e3a-0a5d8c2c7f10",
+//        "status": "completed",
+//        "created": "2021-06-01T10:00:00.000000Z",
+//        "updated": "2021-06-01T10:00:05.000000Z",
+//        "total": 2,
+//        "pending": 0,
+//        "succeeded": 1,
+//        "failed": 1,
+//        "rows": [
+//            {
+//                "row": 1,
+//                "host_name": "Purley host1",
+//                "connection_string": "intel:https://trustagent1.server.com:1443",
+//                "status": "succeeded",
+//                "host_id": "fc0cc779-22b6-4741-b0d9-e2e69635ad1e",
+//                "attempts": 1
+//            },
+//            {
+//                "row": 2,
+//                "host_name": "Purley host2",
+//                "connection_string": "intel:https://trustagent2.server.com:1443",
+//                "status": "failed",
+//                "error": "Host with this name already exist",
+//                "attempts": 1
+//            }
+//        ]
+//    }
+// ---
+
+// swagger:operation POST /hosts/bulk/{job_id}/retry Hosts RetryHostImportJob
+// ---
+//
+// description: |
+//   Registers again the hosts of a completed host import job that failed to register. The hosts registered
+//   successfully are left as is.
+//   Returns - The serialized HostImportJob Go struct object of the restarted job.
+// x-permissions: hosts:create
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// parameters:
+// - name: job_id
+//   description: Unique ID of the host import job.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '202':
+//     description: Successfully restarted the host import job.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/HostImportJob"
+//   '400':
+//     description: The host import job has no failed host
+//   '404':
+//     description: Host import job record not found
+//   '409':
+//     description: The host import job is still running
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/hosts/bulk/6b2c9f8e-1c1e-4a43-9e3a-0a5d8c2c7f10/retry
+// x-sample-call-output: |
+//    {
+//        "id": "6b2c9f8e-1c1e-4a43-9e3a-0a5d8c2c7f10",
+//        "status": "running",
+//        "created": "2021-06-01T10:00:00.000000Z",
+//        "updated": "2021-06-01T10:10:00.000000Z",
+//        "total": 2,
+//        "pending": 1,
+//        "succeeded": 1,
+//        "failed": 0,
+//        "rows": [
+//            {
+//                "row": 1,
+//                "host_name": "Purley host1",
+//                "connection_string": "intel:https://trustagent1.server.com:1443",
+//                "status": "succeeded",
+//                "host_id": "fc0cc779-22b6-4741-b0d9-e2e69635ad1e",
+//                "attempts": 1
+//            },
+//            {
+//                "row": 2,
+//                "host_name": "Purley host2",
+//                "connection_string": "intel:https://trustagent2.server.com:1443",
+//                "status": "pending",
+//                "attempts": 1
+//            }
+//        ]
+//    }
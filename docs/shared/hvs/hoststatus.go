@@ -60,6 +60,11 @@ type HostStatusCollection struct {
 //      type: string
 //      format: uuid
 //      required: false
+//    - name: labelSelector
+//      description: Comma separated label requirements the hosts must all meet, e.g. "env=prod,rack in (12,13)".
+//      in: query
+//      type: string
+//      required: false
 //    - name: hostStatus
 //      description: Host connection state.
 //      in: query
@@ -114,12 +119,33 @@ type HostStatusCollection struct {
 //      minimum: 1
 //      required: false
 //    - name: limit
-//      description: Limits the number of HostStatus records in the response.
+//      description: Limits the number of HostStatus records in the response, or in each page when paging is enabled by sortBy.
 //      in: query
 //      type: integer
 //      minimum: 1
 //      default: 10000
 //      required: false
+//    - name: after
+//      description: Opaque cursor identifying the last record of the previous page. It is obtained from the "next" link of the previous response and must be used with the same sortBy.
+//      in: query
+//      type: string
+//      required: false
+//    - name: sortBy
+//      description: Enables paging of the results, the response carries a "next" link when more records are available. Field the paged records are ordered on, the record ID being used as a tie-breaker.
+//      in: query
+//      type: string
+//      enum:
+//         - created
+//         - id
+//      required: false
+//    - name: orderBy
+//      description: Orders the paged records in ascending/descending order. Accepted values are "asc"/"desc" the default being "asc".
+//      in: query
+//      type: string
+//      enum:
+//         - asc
+//         - desc
+//      required: false
 //    - name: Accept
 //      description: Accept header
 //      in: header
@@ -28,6 +28,20 @@ type ReportCreateRequest struct {
 	Body hvs.ReportCreateRequest
 }
 
+// ReportSummary response payload
+// swagger:parameters ReportSummary
+type ReportSummary struct {
+	// in:body
+	Body hvs.ReportSummary
+}
+
+// ReportBulkCreateResponse response payload
+// swagger:parameters ReportBulkCreateResponse
+type ReportBulkCreateResponse struct {
+	// in:body
+	Body hvs.ReportBulkCreateResponse
+}
+
 // ---
 
 // swagger:operation GET /reports Reports Search-Reports
@@ -75,6 +89,11 @@ type ReportCreateRequest struct {
 //   type: string
 //   format: uuid
 //   required: false
+// - name: labelSelector
+//   description: Comma separated label requirements the hosts must all meet, e.g. "env=prod,rack in (12,13)". It can be specified along with the other parameters.
+//   in: query
+//   type: string
+//   required: false
 // - name: hostStatus
 //   description: Current state of an active host.  A list of host states is defined in the description section of the HostStatus.
 //   in: query
@@ -118,11 +137,32 @@ type ReportCreateRequest struct {
 //   required: false
 //   default: true
 // - name: limit
-//   description: This limits the overall number of results (all hosts included).
+//   description: This limits the overall number of results (all hosts included), or the number of results in each page when paging is enabled by sortBy.
 //   in: query
 //   type: integer
 //   required: false
 //   default: 2000
+// - name: after
+//   description: Opaque cursor identifying the last record of the previous page. It is obtained from the "next" link of the previous response and must be used with the same sortBy.
+//   in: query
+//   type: string
+//   required: false
+// - name: sortBy
+//   description: Enables paging of the results, the response carries a "next" link when more records are available. Field the paged records are ordered on, the record ID being used as a tie-breaker.
+//   in: query
+//   type: string
+//   enum:
+//      - created
+//      - id
+//   required: false
+// - name: orderBy
+//   description: Orders the paged records in ascending/descending order. Accepted values are "asc"/"desc" the default being "asc".
+//   in: query
+//   type: string
+//   enum:
+//      - asc
+//      - desc
+//   required: false
 // - name: Accept
 //   description: Accept header
 //   in: header
@@ -217,7 +257,10 @@ type ReportCreateRequest struct {
 //    | host_id                        | ID of host |
 //    | host_name                      | hostname of host |
 //    | hardware_uuid                  | Hardware UUID of host |
+//    | label_selector                 | Label selector of the hosts, e.g. "env=prod,rack in (12,13)". It cannot be specified along with the other attributes. |
 //
+//   When a label selector is provided, the matching hosts are queued for verification and their IDs are returned with
+//   a 202 status, the reports being created asynchronously.
 //
 // x-permissions: reports:create
 // security:
@@ -253,6 +296,12 @@ type ReportCreateRequest struct {
 //       application/json
 //     schema:
 //       $ref: "#/definitions/Report"
+//   '202':
+//     description: Successfully queued the hosts matching the label selector for verification.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/ReportBulkCreateResponse"
 //   '400':
 //     description: Invalid search criteria provided
 //   '415':
@@ -324,6 +373,126 @@ type ReportCreateRequest struct {
 
 // ---
 
+// swagger:operation GET /reports/summary Reports Summarize-Reports
+// ---
+//
+// description: |
+//   <b>Summarizes the trust status of the hosts</b>
+//
+//   Counts the hosts that are trusted, untrusted, unknown and failing to connect. Each host is counted once: the hosts whose
+//   latest connection attempt failed are counted as connection failures whatever their last report, and the hosts without a
+//   report are unknown.
+//
+//   The counts can be broken down by flavor part and failing rule of the latest reports, and by host connection state. When a
+//   date range is given, the hosts that got trusted reports, untrusted reports or connection failures are also counted by time
+//   bucket from the audit log, a host being counted in each bucket it went through.
+//
+// x-permissions: reports:search
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: labelSelector
+//   description: Comma separated label requirements the counted hosts must all meet, e.g. "env=prod,rack in (12,13)".
+//   in: query
+//   type: string
+//   required: false
+// - name: groupBy
+//   description: Comma separated breakdowns of the counts, among flavorPart, rule and hostState.
+//   in: query
+//   type: string
+//   required: false
+// - name: numberOfDays
+//   description: |
+//      Counts the hosts by time bucket between the current date and number of days prior. This option will override other date options.
+//      min: 1
+//      max: 365
+//   in: query
+//   type: integer
+//   required: false
+// - name: fromDate
+//   description: Counts the hosts by time bucket from this date, in any of the date formats supported by the report search.
+//   in: query
+//   type: string
+//   required: false
+// - name: toDate
+//   description: Counts the hosts by time bucket until this date, defaulting to the current date. It requires fromDate.
+//   in: query
+//   type: string
+//   required: false
+// - name: bucketSize
+//   description: Size of the time buckets, defaulting to day.
+//   in: query
+//   type: string
+//   enum:
+//     - hour
+//     - day
+//     - week
+//   required: false
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully summarized the trust status of the hosts.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/ReportSummary"
+//   '400':
+//     description: Invalid values for request params
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/reports/summary?groupBy=rule,hostState&numberOfDays=2
+// x-sample-call-output: |
+//    {
+//        "total": 10,
+//        "trusted": 7,
+//        "untrusted": 1,
+//        "unknown": 1,
+//        "connection_failure": 1,
+//        "failing_rules": [
+//            {
+//                "rule_name": "com.intel.mtwilson.core.verifier.policy.rule.PcrMatchesConstant",
+//                "hosts": 1
+//            }
+//        ],
+//        "host_states": [
+//            {
+//                "host_state": "CONNECTED",
+//                "hosts": 9
+//            },
+//            {
+//                "host_state": "CONNECTION_FAILURE",
+//                "hosts": 1
+//            }
+//        ],
+//        "time_buckets": [
+//            {
+//                "start": "2021-03-01T00:00:00Z",
+//                "trusted": 8,
+//                "untrusted": 1,
+//                "connection_failure": 0
+//            },
+//            {
+//                "start": "2021-03-02T00:00:00Z",
+//                "trusted": 7,
+//                "untrusted": 1,
+//                "connection_failure": 1
+//            }
+//        ]
+//    }
+
+// ---
+
 // swagger:operation GET /reports/{report_id} Reports Retrieve-Report
 // ---
 //
@@ -22,6 +22,13 @@ type KeyResponse struct {
 	Body kbs.KeyResponse
 }
 
+// Key rotate request payload
+// swagger:parameters KeyRotateRequest
+type KeyRotateRequest struct {
+	// in:body
+	Body kbs.KeyRotateRequest
+}
+
 // KeyCollection response payload
 // swagger:parameters KeyCollection
 type KeyCollection struct {
@@ -52,6 +59,21 @@ type KeyTransferAttributes struct {
 //    | transfer_policy_id | Unique identifier of the transfer policy to apply to this key. |
 //    | label              | String to attach optionally a text description to the key, e.g. "US Nginx key". |
 //    | usage              | String to attach optionally a usage criteria for the key, e.g. "Country:US,State:CA". |
+//    | usage_policy       | Optional structured usage policy the SAML report of a host must satisfy for the key transfer, cannot be combined with usage. |
+//    | rotation_interval  | Optional interval of the automatic rotation of the key as a duration of at least 1h, e.g. "720h". |
+//
+//   The serialized KeyUsagePolicy Go struct object represents the content of the usage_policy field, all the attributes are optional
+//   and all the attributes provided must be satisfied.
+//
+//    | Attribute                     | Description |
+//    |-------------------------------|-------------|
+//    | asset_tags                    | Json object with all_of, any_of and none_of lists of asset tags given as key and value, matched case-insensitively against the TAG_ attributes of the report. |
+//    | required_trusted_flavor_parts | Flavor parts for which the host must be trusted, e.g. ["PLATFORM", "OS"]. |
+//    | max_report_age                | Maximum age of the SAML report as a duration, e.g. "10m". |
+//    | allowed_hardware_features     | Allowed values of the FEATURE_ attributes of the report keyed by feature name, e.g. {"TXT": ["true"]}. |
+//    | allowed_aik_issuers           | Common names or distinguished names of the CAs allowed to issue the AIK certificate of the host. |
+//
+//   A key transfer denied by the usage policy reports the clause of the policy which was not satisfied.
 //
 //   The serialized KeyInformation Go struct object represents the content of the key_information field.
 //
@@ -184,7 +206,8 @@ type KeyTransferAttributes struct {
 // ---
 //
 // description: |
-//   Transfers a key.
+//   Transfers a key. The active version of the key is transferred unless a prior version is requested, the
+//   version field of the response holds the transferred version.
 //   Returns - The serialized KeyTransferAttributes Go struct object that was retrieved.
 // x-permissions: keys:transfer
 // security:
@@ -200,6 +223,11 @@ type KeyTransferAttributes struct {
 //   required: true
 //   type: string
 //   format: uuid
+// - name: version
+//   description: Version of the key to transfer, the active version by default.
+//   in: query
+//   type: integer
+//   required: false
 // - name: Content-Type
 //   description: Content-Type header
 //   in: header
@@ -221,8 +249,10 @@ type KeyTransferAttributes struct {
 //       application/json
 //     schema:
 //       $ref: "#/definitions/KeyTransferAttributes"
+//   '400':
+//     description: Invalid version query parameter
 //   '404':
-//     description: Key record not found
+//     description: Key record or key version not found
 //   '415':
 //     description: Invalid Content-Type/Accept Header in Request
 //   '500':
@@ -244,7 +274,95 @@ type KeyTransferAttributes struct {
 // x-sample-call-output: |
 //    {
 //        "id": "fc0cc779-22b6-4741-b0d9-e2e69635ad1e",
-//        "payload": "F+nUVyejh2Cp0wkLFvqNkhBydtnKY8v5eJ5zbl9gHoPbqvjwuSafx4LwnHOT6DJDqa8LO5ufVyLqqXVfyAdf88s1VnKLCE0Udbn8Zjnq4CHnR2KqDPWTauYLnuYJH2lVGf4Ke4mTcvOfBO9YRTop0WzfTBSuEFKrAsE67ERogtCvD7hf5LhJ2sxv0ej48uZ5KLHRVAzbWMttRZXbL10xTC+dZM9SIAWg2s0aq7Mb49h2rcaI307e3GQgsXhbopwSTC7L7Sy1RYUf4XvHl+/XMmVmvKWjOFIfOXTg8cA+COTBjzOQXVJiXF/xv5/idny0sOeyebFfnxfj7ZXJhqT8pYtiyRm0kzU35jtFTpJR8+aMkOjI/4KdbM6zoY+7JiRD2A0VNEAvQzEoKnY2H9/fIRlkYLtjCI/n5CSPg5Ap0wghqZAmmCeaOH48D0NgjpVQPhc/OQHq/k0HRUXvmUgQe/D4T3WIUdJCctSBGsjIn3WrusH+cb5eaof5Aqq7NT4W"
+//        "payload": "F+nUVyejh2Cp0wkLFvqNkhBydtnKY8v5eJ5zbl9gHoPbqvjwuSafx4LwnHOT6DJDqa8LO5ufVyLqqXVfyAdf88s1VnKLCE0Udbn8Zjnq4CHnR2KqDPWTauYLnuYJH2lVGf4Ke4mTcvOfBO9YRTop0WzfTBSuEFKrAsE67ERogtCvD7hf5LhJ2sxv0ej48uZ5KLHRVAzbWMttRZXbL10xTC+dZM9SIAWg2s0aq7Mb49h2rcaI307e3GQgsXhbopwSTC7L7Sy1RYUf4XvHl+/XMmVmvKWjOFIfOXTg8cA+COTBjzOQXVJiXF/xv5/idny0sOeyebFfnxfj7ZXJhqT8pYtiyRm0kzU35jtFTpJR8+aMkOjI/4KdbM6zoY+7JiRD2A0VNEAvQzEoKnY2H9/fIRlkYLtjCI/n5CSPg5Ap0wghqZAmmCeaOH48D0NgjpVQPhc/OQHq/k0HRUXvmUgQe/D4T3WIUdJCctSBGsjIn3WrusH+cb5eaof5Aqq7NT4W",
+//        "version": 1
+//    }
+
+// ---
+
+// swagger:operation POST /keys/{id}/rotate Keys RotateKey
+// ---
+//
+// description: |
+//   Rotates a key. A new version of the key is created behind the same key ID and becomes the active version,
+//   the prior versions remain transferable through the version query parameter of the key transfer.
+//   The request body is optional, the serialized KeyRotateRequest Go struct object represents its content.
+//
+//    | Attribute          | Description |
+//    |--------------------|-------------|
+//    | rotation_interval  | Replaces the interval of the automatic rotation of the key, an empty interval disables the automatic rotation. |
+//
+//   Returns - The serialized KeyResponse Go struct object of the rotated key.
+// x-permissions: keys:rotate
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// consumes:
+// - application/json
+// parameters:
+// - name: id
+//   description: Unique ID of the key.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: request body
+//   required: false
+//   in: body
+//   schema:
+//    "$ref": "#/definitions/KeyRotateRequest"
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully rotated the key.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/KeyResponse"
+//   '400':
+//     description: Invalid request body
+//   '404':
+//     description: Key record not found
+//   '415':
+//     description: Invalid Content-Type/Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://kbs.com:9443/kbs/v1/keys/fc0cc779-22b6-4741-b0d9-e2e69635ad1e/rotate
+// x-sample-call-input: |
+//    {
+//        "rotation_interval": "720h"
+//    }
+// x-sample-call-output: |
+//    {
+//        "key_information": {
+//            "id": "fc0cc779-22b6-4741-b0d9-e2e69635ad1e",
+//            "algorithm": "AES",
+//            "key_length": 256
+//        },
+//        "transfer_policy_id": "3ce27bbd-3c5f-4b15-8c0a-44310f7cbbd4",
+//        "transfer_link": "https://kbs.com:9443/kbs/v1/keys/fc0cc779-22b6-4741-b0d9-e2e69635ad1e/transfer",
+//        "created_at": "2021-03-18T06:31:38.618416913Z",
+//        "version": 2,
+//        "versions": [
+//            {
+//                "version": 1,
+//                "created_at": "2021-03-18T06:31:38.618416913Z"
+//            },
+//            {
+//                "version": 2,
+//                "created_at": "2021-04-17T06:31:40.113287554Z"
+//            }
+//        ],
+//        "rotation_interval": "720h",
+//        "next_rotation": "2021-05-17T06:31:40.113287554Z"
 //    }
 
 // ---
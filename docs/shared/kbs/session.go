@@ -6,6 +6,8 @@ package kbs
 
 import "github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
 
+type Sessions []kbs.SessionInfo
+
 // Session request payload
 // swagger:parameters SessionManagementAttributes
 type SessionManagementAttributes struct {
@@ -20,6 +22,13 @@ type SessionResponseAttributes struct {
 	Body kbs.SessionResponseAttributes
 }
 
+// SessionCollection response payload
+// swagger:parameters SessionCollection
+type SessionCollection struct {
+	// in:body
+	Body Sessions
+}
+
 // ---
 
 // swagger:operation POST /session Session CreateSession
@@ -96,3 +105,101 @@ type SessionResponseAttributes struct {
 //      "operation": "establish session key",
 //      "status": "success"
 //  }
+
+// ---
+
+// swagger:operation GET /sessions Session SearchSessions
+// ---
+//
+// description: |
+//   Searches for the active SKC sessions. The sessions are kept in the database when KBS uses the postgres store type,
+//   they are then shared by the KBS instances and survive restarts. The session wrapping keys are never returned.
+//   Returns - The collection of serialized SessionInfo Go struct objects.
+//
+//    | Attribute          | Description |
+//    |--------------------|-------------|
+//    | id                 | Session ID, as sent to the SKC Library in the Session-Id header. |
+//    | stm_label          | Security Technology Module(STM) label of the session. |
+//    | client_cert_hash   | Hash of the SKC client certificate. |
+//    | attested           | Whether the enclave quote has been verified for the session. |
+//    | tcb_level          | TCB level reported by the quote verification. |
+//    | created            | Creation time of the session. |
+//    | expires            | Expiry time of the session. |
+// x-permissions: sessions:search
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: stmLabel
+//   description: STM label of the sessions.
+//   in: query
+//   type: string
+//   required: false
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the sessions.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/Sessions"
+//   '400':
+//     description: Invalid values for request params
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://kbs.com:9443/kbs/v1/sessions?stmLabel=SGX
+// x-sample-call-output: |
+//    [
+//        {
+//            "id": "14cfced1-03ee-4a68-8b50-6d456423b078",
+//            "stm_label": "SGX",
+//            "client_cert_hash": "",
+//            "attested": true,
+//            "tcb_level": "UpToDate",
+//            "created": "2021-06-09T08:05:47.418Z",
+//            "expires": "2021-06-09T09:05:47.418Z"
+//        }
+//    ]
+
+// ---
+
+// swagger:operation DELETE /sessions/{id} Session DeleteSession
+// ---
+//
+// description: |
+//   Revokes an SKC session. The enclave has to be attested again before the next key transfer.
+// x-permissions: sessions:delete
+// security:
+//  - bearerAuth: []
+// parameters:
+// - name: id
+//   description: Session ID.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '204':
+//     description: Successfully revoked the session.
+//   '404':
+//     description: Session record not found
+//   '500':
+//     description: Internal server error
+// x-sample-call-endpoint: https://kbs.com:9443/kbs/v1/sessions/14cfced1-03ee-4a68-8b50-6d456423b078
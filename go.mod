@@ -16,6 +16,7 @@ require (
 	github.com/joho/godotenv v1.3.0
 	github.com/lib/pq v1.3.0
 	github.com/mattermost/xml-roundtrip-validator v0.0.0-20201213122252-bcd7e1b9601e
+	github.com/miekg/pkcs11 v1.1.1
 	github.com/nats-io/jwt/v2 v2.0.2
 	github.com/nats-io/nats.go v1.11.0
 	github.com/nats-io/nkeys v0.3.0
@@ -7,6 +7,7 @@ package config
 import (
 	"github.com/intel-secl/intel-secl/v4/pkg/authservice/constants"
 	commConfig "github.com/intel-secl/intel-secl/v4/pkg/lib/common/config"
+	aasModel "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -28,6 +29,7 @@ type Configuration struct {
 	TLS              commConfig.TLSCertConfig `yaml:"tls" mapstructure:"tls"`
 	Server           commConfig.ServerConfig  `yaml:"server" mapstructure:"server"`
 	Nats             NatsConfig               `yaml:"nats" mapstructure:"nats"`
+	OIDC             OIDCConfig               `yaml:"oidc" mapstructure:"oidc"`
 }
 
 type AASConfig struct {
@@ -36,9 +38,12 @@ type AASConfig struct {
 }
 
 type JWT struct {
-	IncludeKid        bool   `yaml:"include-kid" mapstructure:"include-kid"`
-	TokenDurationMins int    `yaml:"token-duration-mins" mapstructure:"token-duration-mins"`
-	CertCommonName    string `yaml:"cert-common-name" mapstructure:"cert-common-name"`
+	IncludeKid                  bool   `yaml:"include-kid" mapstructure:"include-kid"`
+	TokenDurationMins           int    `yaml:"token-duration-mins" mapstructure:"token-duration-mins"`
+	AccessTokenDurationMins     int    `yaml:"access-token-duration-mins" mapstructure:"access-token-duration-mins"`
+	RefreshTokenDurationMins    int    `yaml:"refresh-token-duration-mins" mapstructure:"refresh-token-duration-mins"`
+	CustomClaimsMaxDurationMins int    `yaml:"custom-claims-max-duration-mins" mapstructure:"custom-claims-max-duration-mins"`
+	CertCommonName              string `yaml:"cert-common-name" mapstructure:"cert-common-name"`
 }
 
 type AuthDefender struct {
@@ -53,6 +58,27 @@ type NatsConfig struct {
 	UserCredentialValidity time.Duration  `yaml:"user-credential-validity" mapstructure:"user-credential-validity"`
 }
 
+// OIDCConfig configures the federation with an external OpenID Connect identity provider. The users authenticated
+// by the identity provider get the AAS roles mapped from their groups.
+type OIDCConfig struct {
+	Enabled       bool              `yaml:"enabled" mapstructure:"enabled"`
+	Issuer        string            `yaml:"issuer" mapstructure:"issuer"`
+	ClientID      string            `yaml:"client-id" mapstructure:"client-id"`
+	ClientSecret  string            `yaml:"client-secret" mapstructure:"client-secret"`
+	RedirectURL   string            `yaml:"redirect-url" mapstructure:"redirect-url"`
+	Scopes        string            `yaml:"scopes" mapstructure:"scopes"`
+	UsernameClaim string            `yaml:"username-claim" mapstructure:"username-claim"`
+	GroupsClaim   string            `yaml:"groups-claim" mapstructure:"groups-claim"`
+	RoleMappings  []OIDCRoleMapping `yaml:"role-mappings" mapstructure:"role-mappings"`
+}
+
+// OIDCRoleMapping grants the roles to the members of the identity provider group, the group "*" matches every
+// authenticated user
+type OIDCRoleMapping struct {
+	Group string              `yaml:"group" mapstructure:"group" json:"group"`
+	Roles []aasModel.RoleInfo `yaml:"roles" mapstructure:"roles" json:"roles"`
+}
+
 type NatsEntityInfo struct {
 	Name               string        `yaml:"name" mapstructure:"name"`
 	CredentialValidity time.Duration `yaml:"credential-validity" mapstructure:"credential-validity"`
@@ -75,6 +75,24 @@ const (
 	DefaultTLSKeyFile  = ConfigDir + "tls.key"
 )
 
+const (
+	DefaultAccessTokenDurationMins     = 15
+	DefaultRefreshTokenDurationMins    = 1440
+	DefaultCustomClaimsMaxDurationMins = 43200
+	RefreshTokenLength                 = 32
+)
+
+const (
+	DefaultOidcScopes        = "openid profile email"
+	DefaultOidcUsernameClaim = "preferred_username"
+	DefaultOidcGroupsClaim   = "groups"
+	OidcLoginStateValidity   = 10 * time.Minute
+	OidcGroupWildcard        = "*"
+
+	// minimum time between two refreshes of the identity provider signing keys
+	OidcJwksMinRefreshInterval = 30 * time.Second
+)
+
 const (
 	DefaultAuthDefendMaxAttempts  = 5
 	DefaultAuthDefendIntervalMins = 5
@@ -140,6 +158,7 @@ func GetDefaultAdministratorRoles() []ct.RoleCreate {
 			},
 			Permissions: []string{
 				UserCreate + ":*", UserRetrieve + ":*", UserStore + ":*", UserSearch + ":*", UserDelete + ":*",
+				TokenRevoke + ":*",
 			},
 		},
 		{
@@ -24,6 +24,8 @@ const (
 
 	CustomClaimsCreate = "custom_claims:create"
 
+	TokenRevoke = "tokens:revoke"
+
 	CredentialCreate = "credential:create"
 
 	CredentialCreatorRoleName = "CredentialCreator"
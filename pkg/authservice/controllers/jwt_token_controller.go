@@ -5,12 +5,19 @@
 package controllers
 
 import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	consts "github.com/intel-secl/intel-secl/v4/pkg/authservice/constants"
 	"github.com/intel-secl/intel-secl/v4/pkg/authservice/domain"
 	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
 	jwtauth "github.com/intel-secl/intel-secl/v4/pkg/lib/common/jwt"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
 	aasModel "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
+	"strings"
 	"time"
 
 	authcommon "github.com/intel-secl/intel-secl/v4/pkg/authservice/common"
@@ -28,6 +35,13 @@ type roleClaims struct {
 type JwtTokenController struct {
 	Database     domain.AASDatabase
 	TokenFactory *jwtauth.JwtFactory
+	// AccessTokenValidity and RefreshTokenValidity are the validities of the tokens issued in pairs
+	AccessTokenValidity  time.Duration
+	RefreshTokenValidity time.Duration
+	// RevocationValidity is how long a revocation is kept, the longest validity of the issued tokens
+	RevocationValidity time.Duration
+	// CustomClaimsMaxValidity is the longest validity of the custom claims tokens
+	CustomClaimsMaxValidity time.Duration
 }
 
 func (controller JwtTokenController) CreateJwtToken(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
@@ -35,52 +49,319 @@ func (controller JwtTokenController) CreateJwtToken(w http.ResponseWriter, r *ht
 	defaultLog.Trace("call to createJwtToken")
 	defer defaultLog.Trace("createJwtToken return")
 
+	user, roles, perms, httpStatus, err := controller.authenticateUser(r)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	jwt, err := controller.TokenFactory.Create(&roleClaims{Roles: roles, Permissions: perms}, user.Name, 0)
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "could not generate token"}
+	}
+
+	secLog.Infof("%s: Return JWT token of user [%s] to: %s", commLogMsg.TokenIssued, user.Name, r.RemoteAddr)
+	return jwt, http.StatusOK, nil
+}
+
+// CreateJwtTokenPair returns a short lived access token along with a refresh token that can be exchanged for a
+// new token pair at /token/refresh
+func (controller JwtTokenController) CreateJwtTokenPair(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to createJwtTokenPair")
+	defer defaultLog.Trace("createJwtTokenPair return")
+
+	user, roles, perms, httpStatus, err := controller.authenticateUser(r)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+	return controller.createTokenPair(user, roles, perms, r)
+}
+
+// RefreshJwtToken exchanges a refresh token for a new token pair, a refresh token can only be used once. Presenting
+// a refresh token that has already been used revokes all the refresh tokens of the user.
+func (controller JwtTokenController) RefreshJwtToken(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to refreshJwtToken")
+	defer defaultLog.Trace("refreshJwtToken return")
+
 	if r.ContentLength == 0 {
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body was not provided"}
 	}
 
+	var rt aasModel.RefreshTokenRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&rt)
+	if err != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+	if rt.RefreshToken == "" {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "refresh_token is required"}
+	}
+
+	refreshTokenStore := controller.Database.RefreshTokenStore()
+	stored, err := refreshTokenStore.RetrieveByHash(hashRefreshToken(rt.RefreshToken))
+	if err != nil {
+		if strings.Contains(err.Error(), commErr.RecordNotFound) {
+			secLog.Warningf("%s: Unknown refresh token, requested from %s: ", commLogMsg.AuthenticationFailed, r.RemoteAddr)
+			return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "Invalid refresh token"}
+		}
+		defaultLog.WithError(err).Error("failed to retrieve refresh token")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Database error: unable to retrieve refresh token"}
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		secLog.Warningf("%s: Expired refresh token, requested from %s: ", commLogMsg.AuthenticationFailed, r.RemoteAddr)
+		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "Refresh token expired"}
+	}
+
+	// revoking the presented token only succeeds once, so that concurrent requests cannot both get a new pair
+	rotated := false
+	if !stored.Revoked {
+		rotated, err = refreshTokenStore.Revoke(stored.ID)
+		if err != nil {
+			defaultLog.WithError(err).Error("failed to revoke refresh token")
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Database error: unable to revoke refresh token"}
+		}
+	}
+	if !rotated {
+		secLog.Warningf("%s: Reuse of refresh token of user %s, revoking all refresh tokens of the user, requested from %s: ", commLogMsg.AuthenticationFailed, stored.UserID, r.RemoteAddr)
+		if err = refreshTokenStore.RevokeByUser(stored.UserID); err != nil {
+			defaultLog.WithError(err).Error("failed to revoke refresh tokens of user")
+		}
+		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "Invalid refresh token"}
+	}
+
+	u := controller.Database.UserStore()
+	user, err := u.Retrieve(types.User{ID: stored.UserID})
+	if err != nil {
+		secLog.WithError(err).Warningf("%s: User %s of refresh token not found, requested from %s: ", commLogMsg.AuthenticationFailed, stored.UserID, r.RemoteAddr)
+		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "Invalid refresh token"}
+	}
+	roles, err := u.GetRoles(types.User{Name: user.Name}, nil, false)
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Database error: unable to retrieve roles"}
+	}
+	perms, err := u.GetPermissions(types.User{Name: user.Name}, nil)
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Database error: unable to retrieve permissions"}
+	}
+	return controller.createTokenPair(user, roles, perms, r)
+}
+
+// RevokeJwtToken revokes the token with the jti or all the tokens and refresh tokens issued to the user
+func (controller JwtTokenController) RevokeJwtToken(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to revokeJwtToken")
+	defer defaultLog.Trace("revokeJwtToken return")
+
+	if r.ContentLength == 0 {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body was not provided"}
+	}
+
+	var rt aasModel.RevokeTokenRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&rt)
+	if err != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+	if (rt.Jti == "") == (rt.UserName == "") {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Either jti or username must be provided"}
+	}
+
+	if rt.Jti != "" {
+		if validationErr := validation.ValidateUUIDv4(rt.Jti); validationErr != nil {
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid jti"}
+		}
+		_, err = controller.Database.TokenRevocationStore().Create(types.TokenRevocation{
+			Jti:       rt.Jti,
+			ExpiresAt: time.Now().Add(controller.RevocationValidity),
+		})
+		if err != nil {
+			defaultLog.WithError(err).Error("failed to create token revocation")
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Database error: unable to revoke token"}
+		}
+		secLog.Infof("%s: Token %s revoked by: %s", commLogMsg.PrivilegeModified, rt.Jti, r.RemoteAddr)
+		return nil, http.StatusNoContent, nil
+	}
+
+	if validationErr := validation.ValidateUserNameString(rt.UserName); validationErr != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: validationErr.Error()}
+	}
+	user, err := controller.Database.UserStore().Retrieve(types.User{Name: rt.UserName})
+	if err != nil {
+		if strings.Contains(err.Error(), commErr.RecordNotFound) {
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "User not found"}
+		}
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "failed to retrieve user"}
+	}
+	if err = revokeUserTokens(controller.Database, *user, controller.RevocationValidity); err != nil {
+		defaultLog.WithError(err).Error("failed to revoke tokens of user")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Database error: unable to revoke tokens"}
+	}
+	secLog.Infof("%s: Tokens of user %s revoked by: %s", commLogMsg.PrivilegeModified, user.Name, r.RemoteAddr)
+	return nil, http.StatusNoContent, nil
+}
+
+// GetTokenRevocationList returns the revocations of the tokens that are not expired yet, the services reject the
+// tokens matching the list
+func (controller JwtTokenController) GetTokenRevocationList(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to getTokenRevocationList")
+	defer defaultLog.Trace("getTokenRevocationList return")
+
+	revocationList, err := RetrieveTokenRevocationList(controller.Database)
+	if err != nil {
+		defaultLog.WithError(err).Error("failed to retrieve token revocations")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Database error: unable to retrieve token revocations"}
+	}
+	revocationListBytes, err := json.Marshal(revocationList)
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: err.Error()}
+	}
+	return string(revocationListBytes), http.StatusOK, nil
+}
+
+// RetrieveTokenRevocationList returns the list of the revoked tokens that are not expired yet
+func RetrieveTokenRevocationList(db domain.AASDatabase) (*aasModel.TokenRevocationList, error) {
+	revocations, err := db.TokenRevocationStore().RetrieveActive(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	revocationList := &aasModel.TokenRevocationList{}
+	for _, revocation := range revocations {
+		if revocation.Jti != "" {
+			revocationList.Jtis = append(revocationList.Jtis, revocation.Jti)
+		} else {
+			revocationList.Subjects = append(revocationList.Subjects, aasModel.SubjectRevocation{
+				Subject:      revocation.Subject,
+				IssuedBefore: revocation.IssuedBefore,
+			})
+		}
+	}
+	return revocationList, nil
+}
+
+// authenticateUser validates the user credentials of the request and returns the user with its roles and permissions
+func (controller JwtTokenController) authenticateUser(r *http.Request) (*types.User, types.Roles, []aasModel.PermissionInfo, int, error) {
+
+	if r.ContentLength == 0 {
+		return nil, nil, nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body was not provided"}
+	}
+
 	var uc aasModel.UserCred
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
 	err := dec.Decode(&uc)
 	if err != nil {
-		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+		return nil, nil, nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
 	}
 
 	validationErr := validation.ValidateUserNameString(uc.UserName)
 	if validationErr != nil {
-		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: validationErr.Error()}
+		return nil, nil, nil, http.StatusUnauthorized, &commErr.ResourceError{Message: validationErr.Error()}
 	}
 
 	validationErr = validation.ValidatePasswordString(uc.Password)
 	if validationErr != nil {
-		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: validationErr.Error()}
+		return nil, nil, nil, http.StatusUnauthorized, &commErr.ResourceError{Message: validationErr.Error()}
 	}
 
 	u := controller.Database.UserStore()
 
 	if httpStatus, err := authcommon.HttpHandleUserAuth(u, uc.UserName, uc.Password); err != nil {
 		secLog.Warningf("%s: User [%s] authentication failed, requested from %s: ", commLogMsg.AuthenticationFailed, uc.UserName, r.RemoteAddr)
-		return nil, httpStatus, &commErr.ResourceError{Message: err.Error()}
+		return nil, nil, nil, httpStatus, &commErr.ResourceError{Message: err.Error()}
 	}
 	secLog.Infof("%s: User [%s] authenticated, requested from %s: ", commLogMsg.AuthenticationSuccess, uc.UserName, r.RemoteAddr)
 
+	user, err := u.Retrieve(types.User{Name: uc.UserName})
+	if err != nil {
+		return nil, nil, nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Database error: unable to retrieve user"}
+	}
 	roles, err := u.GetRoles(types.User{Name: uc.UserName}, nil, false)
 	if err != nil {
-		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Database error: unable to retrieve roles"}
+		return nil, nil, nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Database error: unable to retrieve roles"}
 	}
 	perms, err := u.GetPermissions(types.User{Name: uc.UserName}, nil)
 	if err != nil {
-		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Database error: unable to retrieve permissions"}
+		return nil, nil, nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Database error: unable to retrieve permissions"}
 	}
+	return user, roles, perms, http.StatusOK, nil
+}
+
+func (controller JwtTokenController) createTokenPair(user *types.User, roles types.Roles, perms []aasModel.PermissionInfo, r *http.Request) (interface{}, int, error) {
 
-	jwt, err := controller.TokenFactory.Create(&roleClaims{Roles: roles, Permissions: perms}, uc.UserName, 0)
+	jwt, err := controller.TokenFactory.Create(&roleClaims{Roles: roles, Permissions: perms}, user.Name, controller.AccessTokenValidity)
 	if err != nil {
 		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "could not generate token"}
 	}
 
-	secLog.Infof("%s: Return JWT token of user [%s] to: %s", commLogMsg.TokenIssued, uc.UserName, r.RemoteAddr)
-	return jwt, http.StatusOK, nil
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		defaultLog.WithError(err).Error("failed to generate refresh token")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "could not generate refresh token"}
+	}
+	now := time.Now()
+	refreshTokenStore := controller.Database.RefreshTokenStore()
+	if err = refreshTokenStore.DeleteExpired(now); err != nil {
+		defaultLog.WithError(err).Warn("failed to delete expired refresh tokens")
+	}
+	_, err = refreshTokenStore.Create(types.RefreshToken{
+		TokenHash: hashRefreshToken(refreshToken),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(controller.RefreshTokenValidity),
+	})
+	if err != nil {
+		defaultLog.WithError(err).Error("failed to store refresh token")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Database error: unable to store refresh token"}
+	}
+
+	tokenBytes, err := json.Marshal(aasModel.TokenResponse{
+		AccessToken:  jwt,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(controller.AccessTokenValidity / time.Second),
+		RefreshToken: refreshToken,
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	secLog.Infof("%s: Return JWT token and refresh token of user [%s] to: %s", commLogMsg.TokenIssued, user.Name, r.RemoteAddr)
+	return string(tokenBytes), http.StatusOK, nil
+}
+
+// revokeUserTokens revokes the tokens issued to the user so far along with its refresh tokens
+func revokeUserTokens(db domain.AASDatabase, user types.User, revocationValidity time.Duration) error {
+	now := time.Now()
+	revocationStore := db.TokenRevocationStore()
+	if err := revocationStore.DeleteExpired(now); err != nil {
+		defaultLog.WithError(err).Warn("failed to delete expired token revocations")
+	}
+	_, err := revocationStore.Create(types.TokenRevocation{
+		Subject:      user.Name,
+		IssuedBefore: jwtauth.IssuedAtCutoff(now),
+		ExpiresAt:    now.Add(revocationValidity),
+	})
+	if err != nil {
+		return err
+	}
+	return db.RefreshTokenStore().RevokeByUser(user.ID)
+}
+
+// newRefreshToken returns an opaque refresh token, only its hash is stored
+func newRefreshToken() (string, error) {
+	b := make([]byte, consts.RefreshTokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashRefreshToken(refreshToken string) string {
+	hash := sha512.Sum384([]byte(refreshToken))
+	return hex.EncodeToString(hash[:])
 }
 
 func (controller JwtTokenController) CreateCustomClaimsJwtToken(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
@@ -105,6 +386,13 @@ func (controller JwtTokenController) CreateCustomClaimsJwtToken(w http.ResponseW
 		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: validationErr.Error()}
 	}
 
+	// the revocations are kept for the longest validity, a longer lived token could outlive its revocation
+	maxValiditySecs := int64(controller.CustomClaimsMaxValidity / time.Second)
+	if cc.ValiditySecs < 0 || int64(cc.ValiditySecs) > maxValiditySecs {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: fmt.Sprintf("validity_seconds must be between 0 and %d",
+			maxValiditySecs)}
+	}
+
 	jwt, err := controller.TokenFactory.Create(&cc.Claims, cc.Subject, time.Duration(cc.ValiditySecs)*time.Second)
 	if err != nil {
 		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "could not generate token"}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/postgres"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/postgres/mock"
+	aasModel "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTokenRevocationList(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	columns := []string{"id", "created_at", "jti", "subject", "issued_before", "expires_at"}
+
+	tests := []struct {
+		name           string
+		rows           *sqlmock.Rows
+		queryErr       error
+		expectedStatus int
+		expectedList   aasModel.TokenRevocationList
+	}{
+		{
+			name: "revoked tokens",
+			rows: sqlmock.NewRows(columns).
+				AddRow("0b8a7f0e-6d9b-4e8c-a6a5-2b3c4d5e6f70", now, "6c6a9d8e-8f0a-4c4b-9b6e-0a4c1f5f2d3e", "", time.Time{}, now.Add(time.Hour)).
+				AddRow("1c9b8a1f-7e0c-4f9d-b7b6-3c4d5e6f7081", now, "", "admin", now, now.Add(time.Hour)),
+			expectedStatus: http.StatusOK,
+			expectedList: aasModel.TokenRevocationList{
+				Jtis:     []string{"6c6a9d8e-8f0a-4c4b-9b6e-0a4c1f5f2d3e"},
+				Subjects: []aasModel.SubjectRevocation{{Subject: "admin", IssuedBefore: now}},
+			},
+		},
+		{
+			name:           "no revoked tokens",
+			rows:           sqlmock.NewRows(columns),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "database error",
+			queryErr:       errors.New("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			gdb, err := gorm.Open("postgres", db)
+			assert.NoError(t, err)
+			query := sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "token_revocations" WHERE (expires_at >= $1) ORDER BY created_at`))
+			if tt.queryErr != nil {
+				query.WillReturnError(tt.queryErr)
+			} else {
+				query.WillReturnRows(tt.rows)
+			}
+
+			controller := JwtTokenController{Database: &mock.MockDatabase{
+				TokenRevocations: (&postgres.PostgresDatabase{Db: gdb}).TokenRevocationStore(),
+			}}
+			req := httptest.NewRequest(http.MethodGet, "/aas/v1/token/revocations", nil)
+			body, status, err := controller.GetTokenRevocationList(httptest.NewRecorder(), req)
+			assert.Equal(t, tt.expectedStatus, status)
+			if tt.expectedStatus == http.StatusOK {
+				assert.NoError(t, err)
+				var revocationList aasModel.TokenRevocationList
+				assert.NoError(t, json.Unmarshal([]byte(body.(string)), &revocationList))
+				assert.Equal(t, tt.expectedList, revocationList)
+			} else {
+				assert.Error(t, err)
+			}
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/oidc"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/types"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	jwtauth "github.com/intel-secl/intel-secl/v4/pkg/lib/common/jwt"
+	commLogMsg "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/message"
+	aasModel "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
+)
+
+// OidcController issues AAS tokens to the users authenticated by the federated OpenID Connect identity provider.
+// The roles of the token are mapped from the groups of the user.
+type OidcController struct {
+	Database     domain.AASDatabase
+	TokenFactory *jwtauth.JwtFactory
+	Provider     *oidc.Provider
+	LoginStates  *oidc.LoginStates
+}
+
+// CreateJwtTokenFromIdToken exchanges an ID token of the identity provider for an AAS token
+func (controller OidcController) CreateJwtTokenFromIdToken(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to createJwtTokenFromIdToken")
+	defer defaultLog.Trace("createJwtTokenFromIdToken return")
+
+	if r.ContentLength == 0 {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body was not provided"}
+	}
+
+	var tokenRequest aasModel.OidcTokenRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&tokenRequest)
+	if err != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+	if tokenRequest.IdToken == "" {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "id_token is required"}
+	}
+
+	identity, err := controller.Provider.VerifyIDToken(tokenRequest.IdToken)
+	if err != nil {
+		secLog.WithError(err).Warningf("%s: ID token verification failed, requested from %s: ", commLogMsg.AuthenticationFailed, r.RemoteAddr)
+		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "Invalid ID token"}
+	}
+	return controller.createJwtToken(identity, r)
+}
+
+// Login starts the authorization code flow by redirecting to the authorization endpoint of the identity provider
+func (controller OidcController) Login(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to oidcLogin")
+	defer defaultLog.Trace("oidcLogin return")
+
+	state, nonce, err := controller.LoginStates.Start()
+	if err != nil {
+		defaultLog.WithError(err).Error("Failed to start OIDC login")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to start OIDC login"}
+	}
+	authURL, err := controller.Provider.AuthCodeURL(state, nonce)
+	if err != nil {
+		defaultLog.WithError(err).Error("Failed to build OIDC authorization request")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to start OIDC login"}
+	}
+	w.Header().Set("Location", authURL)
+	return nil, http.StatusFound, nil
+}
+
+// Callback completes the authorization code flow and returns an AAS token for the authenticated user
+func (controller OidcController) Callback(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to oidcCallback")
+	defer defaultLog.Trace("oidcCallback return")
+
+	query := r.URL.Query()
+	if idpErr := query.Get("error"); idpErr != "" {
+		secLog.Warningf("%s: Identity provider returned error %s, requested from %s: ", commLogMsg.AuthenticationFailed, idpErr, r.RemoteAddr)
+		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "Authentication failed at the identity provider"}
+	}
+	nonce, err := controller.LoginStates.Complete(query.Get("state"))
+	if err != nil {
+		secLog.WithError(err).Warningf("%s: Invalid OIDC login state, requested from %s: ", commLogMsg.AuthenticationFailed, r.RemoteAddr)
+		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "Invalid login state"}
+	}
+	code := query.Get("code")
+	if code == "" {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Authorization code is required"}
+	}
+
+	identity, err := controller.Provider.Exchange(code, nonce)
+	if err != nil {
+		secLog.WithError(err).Warningf("%s: Authorization code exchange failed, requested from %s: ", commLogMsg.AuthenticationFailed, r.RemoteAddr)
+		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "Authentication failed"}
+	}
+	return controller.createJwtToken(identity, r)
+}
+
+func (controller OidcController) createJwtToken(identity *oidc.Identity, r *http.Request) (interface{}, int, error) {
+	roles, perms, err := controller.mappedRolesAndPermissions(identity)
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Database error: unable to retrieve roles"}
+	}
+	if len(roles) == 0 {
+		secLog.Warningf("%s: No AAS role is mapped to the groups of federated user [%s], requested from %s: ", commLogMsg.UnauthorizedAccess, identity.Username, r.RemoteAddr)
+		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "No role is mapped to the user"}
+	}
+	secLog.Infof("%s: Federated user [%s] with subject [%s] authenticated, requested from %s: ", commLogMsg.AuthenticationSuccess, identity.Username, identity.QualifiedSubject(), r.RemoteAddr)
+
+	// the subject of the token is qualified by the issuer, the user name of the identity provider may be the one of a
+	// local user
+	jwt, err := controller.TokenFactory.Create(&roleClaims{Roles: roles, Permissions: perms}, identity.QualifiedSubject(), 0)
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "could not generate token"}
+	}
+
+	secLog.Infof("%s: Return JWT token of federated user [%s] to: %s", commLogMsg.TokenIssued, identity.Username, r.RemoteAddr)
+	return jwt, http.StatusOK, nil
+}
+
+// mappedRolesAndPermissions returns the existing AAS roles mapped from the groups of the user and their permissions,
+// grouped by service and context as for the local users
+func (controller OidcController) mappedRolesAndPermissions(identity *oidc.Identity) (types.Roles, []aasModel.PermissionInfo, error) {
+	var roles types.Roles
+	var perms []aasModel.PermissionInfo
+	type permKey struct{ service, context string }
+	permIndex := map[permKey]int{}
+	for _, roleInfo := range oidc.MapGroupsToRoles(identity.Groups, controller.Provider.RoleMappings()) {
+		found, err := controller.Database.RoleStore().RetrieveAll(&types.RoleSearch{RoleInfo: roleInfo})
+		if err != nil {
+			defaultLog.WithError(err).Errorf("Failed to retrieve role %s:%s", roleInfo.Service, roleInfo.Name)
+			return nil, nil, err
+		}
+		if len(found) == 0 {
+			defaultLog.Warnf("Mapped role %s:%s with context %q does not exist", roleInfo.Service, roleInfo.Name, roleInfo.Context)
+			continue
+		}
+		role := found[0]
+		roles = append(roles, types.Role{ID: role.ID, RoleInfo: role.RoleInfo})
+
+		key := permKey{service: role.Service, context: role.Context}
+		for _, perm := range role.Permissions {
+			i, ok := permIndex[key]
+			if !ok {
+				i = len(perms)
+				permIndex[key] = i
+				perms = append(perms, aasModel.PermissionInfo{Service: role.Service, Context: role.Context})
+			}
+			if !containsString(perms[i].Rules, perm.Rule) {
+				perms[i].Rules = append(perms[i].Rules, perm.Rule)
+			}
+		}
+	}
+	return roles, perms, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
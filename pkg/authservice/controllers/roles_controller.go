@@ -15,6 +15,7 @@ import (
 	aasModel "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -23,6 +24,8 @@ import (
 
 type RolesController struct {
 	Database domain.AASDatabase
+	// RevocationValidity is how long the revocation of the tokens of the users of a deleted role is kept
+	RevocationValidity time.Duration
 }
 
 func (controller RolesController) CreateRole(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
@@ -219,12 +222,26 @@ func (controller RolesController) DeleteRole(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
+	// the users of the role are retrieved before the user-role mapping is cleared, their tokens carry the role
+	roleUsers, err := controller.Database.RoleStore().GetUsers(*delRl)
+	if err != nil {
+		defaultLog.WithError(err).WithField("id", id).Error("failed to retrieve users of role")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to delete role"}
+	}
+
 	if err := controller.Database.RoleStore().Delete(*delRl); err != nil {
 		defaultLog.WithError(err).WithField("id", id).Info("failed to delete role")
 		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to delete role"}
 	}
 	secLog.WithField("role", delRl).Infof("%s: Role deleted by: %s", commLogMsg.PrivilegeModified, r.RemoteAddr)
 
+	for _, user := range roleUsers {
+		if err = revokeUserTokens(controller.Database, user, controller.RevocationValidity); err != nil {
+			defaultLog.WithError(err).WithField("id", id).Error("failed to revoke tokens of users of role")
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "failed to revoke tokens of users of role"}
+		}
+	}
+
 	return nil, http.StatusNoContent, nil
 }
 
@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -28,6 +29,8 @@ import (
 
 type UsersController struct {
 	Database domain.AASDatabase
+	// RevocationValidity is how long the revocation of the tokens of a user is kept
+	RevocationValidity time.Duration
 }
 
 func (controller UsersController) CreateUser(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
@@ -180,6 +183,14 @@ func (controller UsersController) UpdateUser(w http.ResponseWriter, r *http.Requ
 	}
 	secLog.Infof("%s: User %s changed by: %s", commLogMsg.PrivilegeModified, id, r.RemoteAddr)
 
+	// the tokens issued with the former name or password are no longer valid
+	if uc.Password != "" || updatedUser.Name != u.Name {
+		if err = revokeUserTokens(controller.Database, *u, controller.RevocationValidity); err != nil {
+			defaultLog.WithError(err).Error("failed to revoke tokens of user:", id)
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "cannot complete request"}
+		}
+	}
+
 	return nil, http.StatusOK, nil
 
 }
@@ -207,6 +218,10 @@ func (controller UsersController) DeleteUser(w http.ResponseWriter, r *http.Requ
 	}
 	secLog.WithField("user", delUsr).Infof("%s: User deleted by: %s", commLogMsg.UserDeleted, r.RemoteAddr)
 
+	if err := revokeUserTokens(controller.Database, *delUsr, controller.RevocationValidity); err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: err.Error()}
+	}
+
 	return nil, http.StatusNoContent, nil
 }
 
@@ -561,6 +576,11 @@ func (controller UsersController) DeleteUserRole(w http.ResponseWriter, r *http.
 		}
 	}
 	secLog.WithField("user", *u).Infof("%s: User roles deleted by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+
+	if err = revokeUserTokens(controller.Database, *u, controller.RevocationValidity); err != nil {
+		defaultLog.WithError(err).WithField("id", id).Error("failed to revoke tokens of user")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "failed to revoke tokens of user"}
+	}
 	return nil, http.StatusNoContent, nil
 }
 
@@ -627,6 +647,11 @@ func (controller UsersController) ChangePassword(w http.ResponseWriter, r *http.
 	}
 	secLog.WithField("user", existingUser.ID).Infof("%s: User %s password changed by: %s", commLogMsg.PrivilegeModified, existingUser.ID, r.RemoteAddr)
 
+	if err = revokeUserTokens(controller.Database, *existingUser, controller.RevocationValidity); err != nil {
+		defaultLog.WithError(err).Error("failed to revoke tokens after password change")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "cannot complete request"}
+	}
+
 	return nil, http.StatusOK, nil
 }
 
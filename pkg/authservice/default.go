@@ -48,6 +48,9 @@ func init() {
 	viper.SetDefault("jwt-include-kid", true)
 	viper.SetDefault("jwt-cert-common-name", constants.DefaultAasJwtCn)
 	viper.SetDefault("jwt-token-duration-mins", constants.DefaultAasJwtDurationMins)
+	viper.SetDefault("jwt-access-token-duration-mins", constants.DefaultAccessTokenDurationMins)
+	viper.SetDefault("jwt-refresh-token-duration-mins", constants.DefaultRefreshTokenDurationMins)
+	viper.SetDefault("jwt-custom-claims-max-duration-mins", constants.DefaultCustomClaimsMaxDurationMins)
 
 	viper.SetDefault("auth-defender-max-attempts", constants.DefaultAuthDefendMaxAttempts)
 	viper.SetDefault("auth-defender-interval-mins", constants.DefaultAuthDefendIntervalMins)
@@ -60,6 +63,11 @@ func init() {
 	viper.SetDefault("nats-account-credential-validity", time.Hour*43800)
 	viper.SetDefault("nats-user-credential-validity", time.Hour*8760)
 
+	viper.SetDefault("oidc-enabled", false)
+	viper.SetDefault("oidc-scopes", constants.DefaultOidcScopes)
+	viper.SetDefault("oidc-username-claim", constants.DefaultOidcUsernameClaim)
+	viper.SetDefault("oidc-groups-claim", constants.DefaultOidcGroupsClaim)
+
 }
 
 func defaultConfig() *config.Configuration {
@@ -80,9 +88,12 @@ func defaultConfig() *config.Configuration {
 			Level:        viper.GetString("log-level"),
 		},
 		JWT: config.JWT{
-			IncludeKid:        viper.GetBool("jwt-include-kid"),
-			TokenDurationMins: viper.GetInt("jwt-token-duration-mins"),
-			CertCommonName:    viper.GetString("jwt-cert-common-name"),
+			IncludeKid:                  viper.GetBool("jwt-include-kid"),
+			TokenDurationMins:           viper.GetInt("jwt-token-duration-mins"),
+			AccessTokenDurationMins:     viper.GetInt("jwt-access-token-duration-mins"),
+			RefreshTokenDurationMins:    viper.GetInt("jwt-refresh-token-duration-mins"),
+			CustomClaimsMaxDurationMins: viper.GetInt("jwt-custom-claims-max-duration-mins"),
+			CertCommonName:              viper.GetString("jwt-cert-common-name"),
 		},
 		AuthDefender: config.AuthDefender{
 			MaxAttempts:         viper.GetInt("auth-defender-max-attempts"),
@@ -100,6 +111,12 @@ func defaultConfig() *config.Configuration {
 			},
 			UserCredentialValidity: viper.GetDuration("nats-user-credential-vaildity"),
 		},
+		OIDC: config.OIDCConfig{
+			Enabled:       viper.GetBool("oidc-enabled"),
+			Scopes:        viper.GetString("oidc-scopes"),
+			UsernameClaim: viper.GetString("oidc-username-claim"),
+			GroupsClaim:   viper.GetString("oidc-groups-claim"),
+		},
 	}
 }
 
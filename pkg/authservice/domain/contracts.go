@@ -6,6 +6,8 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/intel-secl/intel-secl/v4/pkg/authservice/types"
 	ct "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
 )
@@ -16,6 +18,8 @@ type (
 		UserStore() UserStore
 		RoleStore() RoleStore
 		PermissionStore() PermissionStore
+		RefreshTokenStore() RefreshTokenStore
+		TokenRevocationStore() TokenRevocationStore
 		Close()
 	}
 
@@ -33,6 +37,7 @@ type (
 		RetrieveAll(*types.RoleSearch) (types.Roles, error)
 		Update(types.Role) error
 		Delete(types.Role) error
+		GetUsers(types.Role) (types.Users, error)
 	}
 
 	UserStore interface {
@@ -47,4 +52,19 @@ type (
 		GetUserRoleByID(types.User, string) (types.Role, error)
 		DeleteRole(types.User, string, []string) error
 	}
+
+	RefreshTokenStore interface {
+		Create(types.RefreshToken) (*types.RefreshToken, error)
+		RetrieveByHash(string) (*types.RefreshToken, error)
+		// Revoke returns false when the refresh token was already revoked
+		Revoke(string) (bool, error)
+		RevokeByUser(string) error
+		DeleteExpired(time.Time) error
+	}
+
+	TokenRevocationStore interface {
+		Create(types.TokenRevocation) (*types.TokenRevocation, error)
+		RetrieveActive(time.Time) ([]types.TokenRevocation, error)
+		DeleteExpired(time.Time) error
+	}
 )
@@ -0,0 +1,37 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package oidc
+
+import (
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/config"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/constants"
+	aasModel "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
+)
+
+// MapGroupsToRoles returns the AAS roles granted to the groups by the mapping rules, without duplicates
+func MapGroupsToRoles(groups []string, mappings []config.OIDCRoleMapping) []aasModel.RoleInfo {
+	defaultLog.Trace("oidc/mapping:MapGroupsToRoles() Entering")
+	defer defaultLog.Trace("oidc/mapping:MapGroupsToRoles() Leaving")
+
+	memberOf := map[string]bool{constants.OidcGroupWildcard: true}
+	for _, group := range groups {
+		memberOf[group] = true
+	}
+
+	var roles []aasModel.RoleInfo
+	granted := map[aasModel.RoleInfo]bool{}
+	for _, mapping := range mappings {
+		if !memberOf[mapping.Group] {
+			continue
+		}
+		for _, role := range mapping.Roles {
+			if !granted[role] {
+				granted[role] = true
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
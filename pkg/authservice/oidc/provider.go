@@ -0,0 +1,344 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package oidc
+
+import (
+	"crypto"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/Waterdrips/jwt-go"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/config"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
+	"github.com/pkg/errors"
+)
+
+var defaultLog = log.GetDefaultLogger()
+
+const (
+	discoveryPath         = "/.well-known/openid-configuration"
+	defaultRequestTimeout = 10 * time.Second
+	maxResponseBytes      = 1024 * 1024
+	// allowed clock skew between AAS and the identity provider
+	clockSkew = time.Minute
+	// separates the issuer and the subject in the qualified subject of a federated user
+	subjectSeparator = "|"
+)
+
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// Identity is the user authenticated by the identity provider
+type Identity struct {
+	Issuer   string
+	Subject  string
+	Username string
+	Groups   []string
+}
+
+// QualifiedSubject returns the subject qualified by the issuer, it is the subject of the AAS tokens of the user. The
+// separator is not allowed in the AAS user names so that a federated user cannot pass for a local user.
+func (i *Identity) QualifiedSubject() string {
+	return i.Issuer + subjectSeparator + i.Subject
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type tokenResponse struct {
+	IdToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Provider authenticates users against an OpenID Connect identity provider. The discovery document and the signing
+// keys of the provider are fetched on first use, the keys are fetched again when an ID token is signed by an
+// unknown key, at most once every constants.OidcJwksMinRefreshInterval.
+type Provider struct {
+	cfg        config.OIDCConfig
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      map[string]crypto.PublicKey
+	// time of the last attempt to fetch the keys
+	keysRefreshed time.Time
+}
+
+// NewProvider returns the provider configured by cfg, the HTTP client is used to reach the identity provider
+func NewProvider(cfg config.OIDCConfig, httpClient *http.Client) (*Provider, error) {
+	defaultLog.Trace("oidc/provider:NewProvider() Entering")
+	defer defaultLog.Trace("oidc/provider:NewProvider() Leaving")
+
+	if cfg.Issuer == "" || cfg.ClientID == "" {
+		return nil, errors.New("oidc/provider:NewProvider() OIDC issuer and client id are required")
+	}
+	client := http.Client{}
+	if httpClient != nil {
+		client = *httpClient
+	}
+	if client.Timeout == 0 {
+		client.Timeout = defaultRequestTimeout
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = constants.DefaultOidcUsernameClaim
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = constants.DefaultOidcGroupsClaim
+	}
+	if cfg.Scopes == "" {
+		cfg.Scopes = constants.DefaultOidcScopes
+	}
+	return &Provider{cfg: cfg, httpClient: &client}, nil
+}
+
+// RoleMappings returns the configured mappings of the identity provider groups to AAS roles
+func (p *Provider) RoleMappings() []config.OIDCRoleMapping {
+	return p.cfg.RoleMappings
+}
+
+// AuthCodeURL returns the URL of the authorization endpoint starting the authorization code flow
+func (p *Provider) AuthCodeURL(state, nonce string) (string, error) {
+	defaultLog.Trace("oidc/provider:AuthCodeURL() Entering")
+	defer defaultLog.Trace("oidc/provider:AuthCodeURL() Leaving")
+
+	if p.cfg.RedirectURL == "" {
+		return "", errors.New("oidc/provider:AuthCodeURL() OIDC redirect url is not configured")
+	}
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+	authURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil || discovery.AuthorizationEndpoint == "" {
+		return "", errors.Errorf("oidc/provider:AuthCodeURL() Invalid authorization endpoint %s", discovery.AuthorizationEndpoint)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", p.cfg.RedirectURL)
+	query.Set("scope", p.cfg.Scopes)
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	authURL.RawQuery = query.Encode()
+	return authURL.String(), nil
+}
+
+// Exchange redeems the authorization code at the token endpoint and returns the verified identity of the ID token
+func (p *Provider) Exchange(code, nonce string) (*Identity, error) {
+	defaultLog.Trace("oidc/provider:Exchange() Entering")
+	defer defaultLog.Trace("oidc/provider:Exchange() Leaving")
+
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	req, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Wrap(err, "oidc/provider:Exchange() Failed to create token request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+
+	var tokens tokenResponse
+	status, err := p.doJSON(req, &tokens)
+	if err != nil {
+		return nil, errors.Wrap(err, "oidc/provider:Exchange() Failed to redeem authorization code")
+	}
+	if status != http.StatusOK || tokens.IdToken == "" {
+		return nil, errors.Errorf("oidc/provider:Exchange() Token endpoint returned status %d: %s %s", status,
+			tokens.Error, tokens.ErrorDescription)
+	}
+	return p.verify(tokens.IdToken, nonce)
+}
+
+// VerifyIDToken verifies the signature, issuer, audience and validity of the ID token and returns its identity
+func (p *Provider) VerifyIDToken(rawIdToken string) (*Identity, error) {
+	defaultLog.Trace("oidc/provider:VerifyIDToken() Entering")
+	defer defaultLog.Trace("oidc/provider:VerifyIDToken() Leaving")
+
+	return p.verify(rawIdToken, "")
+}
+
+func (p *Provider) verify(rawIdToken, nonce string) (*Identity, error) {
+	parser := jwt.Parser{ValidMethods: signingMethods, SkipClaimsValidation: true}
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(rawIdToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.getKey(kid)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "oidc/provider:verify() Invalid ID token")
+	}
+
+	now := time.Now()
+	if !claims.VerifyIssuer(p.cfg.Issuer, true) {
+		return nil, errors.New("oidc/provider:verify() ID token is not issued by the configured issuer")
+	}
+	if !hasAudience(claims["aud"], p.cfg.ClientID) {
+		return nil, errors.New("oidc/provider:verify() ID token is not issued for the configured client")
+	}
+	if !claims.VerifyExpiresAt(now.Add(-clockSkew).Unix(), true) {
+		return nil, errors.New("oidc/provider:verify() ID token is expired")
+	}
+	if !claims.VerifyNotBefore(now.Add(clockSkew).Unix(), false) || !claims.VerifyIssuedAt(now.Add(clockSkew).Unix(), false) {
+		return nil, errors.New("oidc/provider:verify() ID token is not valid yet")
+	}
+	if tokenNonce, _ := claims["nonce"].(string); nonce != "" && tokenNonce != nonce {
+		return nil, errors.New("oidc/provider:verify() ID token nonce does not match")
+	}
+
+	identity := &Identity{Issuer: p.cfg.Issuer}
+	identity.Subject, _ = claims["sub"].(string)
+	if identity.Subject == "" {
+		return nil, errors.New("oidc/provider:verify() ID token has no subject")
+	}
+	identity.Username, _ = claims[p.cfg.UsernameClaim].(string)
+	if identity.Username == "" {
+		identity.Username = identity.Subject
+	}
+	switch groups := claims[p.cfg.GroupsClaim].(type) {
+	case string:
+		identity.Groups = []string{groups}
+	case []interface{}:
+		for _, group := range groups {
+			if g, ok := group.(string); ok {
+				identity.Groups = append(identity.Groups, g)
+			}
+		}
+	}
+	return identity, nil
+}
+
+func hasAudience(aud interface{}, clientID string) bool {
+	switch audience := aud.(type) {
+	case string:
+		return audience == clientID
+	case []interface{}:
+		for _, a := range audience {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getKey returns the signing key of the identity provider, the key set is refreshed once for an unknown key id. The
+// ID tokens are sent by unauthenticated users, the unknown key ids fail without a refresh until the minimum refresh
+// interval has elapsed so that they cannot make AAS flood the identity provider.
+func (p *Provider) getKey(kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	keys := p.keys
+	if key, err := selectKey(keys, kid); err == nil {
+		p.mu.Unlock()
+		return key, nil
+	}
+	if !p.keysRefreshed.IsZero() && time.Since(p.keysRefreshed) < constants.OidcJwksMinRefreshInterval {
+		p.mu.Unlock()
+		return nil, errors.Errorf("oidc/provider:getKey() No signing key found for key id %q, the key set was refreshed less than %s ago", kid, constants.OidcJwksMinRefreshInterval)
+	}
+	p.keysRefreshed = time.Now()
+	p.mu.Unlock()
+
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, discovery.JwksURI, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "oidc/provider:getKey() Failed to create JWKS request")
+	}
+	var jwks jsonWebKeySet
+	status, err := p.doJSON(req, &jwks)
+	if err != nil || status != http.StatusOK {
+		return nil, errors.Errorf("oidc/provider:getKey() Failed to retrieve JWKS from %s: status %d %v", discovery.JwksURI, status, err)
+	}
+	keys = jwks.publicKeys()
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+	return selectKey(keys, kid)
+}
+
+func selectKey(keys map[string]crypto.PublicKey, kid string) (crypto.PublicKey, error) {
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	// a token without key id can only be matched to a key set holding a single key
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	return nil, errors.Errorf("oidc/provider:selectKey() No signing key found for key id %q", kid)
+}
+
+func (p *Provider) getDiscovery() (*discoveryDocument, error) {
+	p.mu.Lock()
+	discovery := p.discovery
+	p.mu.Unlock()
+	if discovery != nil {
+		return discovery, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(p.cfg.Issuer, "/")+discoveryPath, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "oidc/provider:getDiscovery() Failed to create discovery request")
+	}
+	discovery = &discoveryDocument{}
+	status, err := p.doJSON(req, discovery)
+	if err != nil || status != http.StatusOK {
+		return nil, errors.Errorf("oidc/provider:getDiscovery() Failed to retrieve discovery document of %s: status %d %v",
+			p.cfg.Issuer, status, err)
+	}
+	// OpenID Connect Discovery 1.0 section 4.3
+	if discovery.Issuer != p.cfg.Issuer {
+		return nil, errors.Errorf("oidc/provider:getDiscovery() Discovery document issuer %s does not match %s",
+			discovery.Issuer, p.cfg.Issuer)
+	}
+	p.mu.Lock()
+	p.discovery = discovery
+	p.mu.Unlock()
+	return discovery, nil
+}
+
+func (p *Provider) doJSON(req *http.Request, v interface{}) (int, error) {
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		derr := resp.Body.Close()
+		if derr != nil {
+			defaultLog.WithError(derr).Error("Error closing response body")
+		}
+	}()
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, maxResponseBytes))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if len(body) == 0 {
+		return resp.StatusCode, nil
+	}
+	if err = json.Unmarshal(body, v); err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, err
+	}
+	return resp.StatusCode, nil
+}
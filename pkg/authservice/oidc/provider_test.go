@@ -0,0 +1,243 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	jwt "github.com/Waterdrips/jwt-go"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/config"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/constants"
+	aasModel "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testClientID     = "aas"
+	testClientSecret = "aas-secret"
+	testCode         = "test-authorization-code"
+)
+
+// mockProvider is a minimal OpenID Connect identity provider serving the discovery document, the key set and the
+// token endpoint
+type mockProvider struct {
+	server    *httptest.Server
+	rsaKey    *rsa.PrivateKey
+	ecKey     *ecdsa.PrivateKey
+	nonce     string
+	jwksCalls int
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	mp := &mockProvider{rsaKey: rsaKey, ecKey: ecKey}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(discoveryDocument{
+			Issuer:                mp.server.URL,
+			AuthorizationEndpoint: mp.server.URL + "/authorize",
+			TokenEndpoint:         mp.server.URL + "/token",
+			JwksURI:               mp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		mp.jwksCalls++
+		encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+		_ = json.NewEncoder(w).Encode(jsonWebKeySet{Keys: []jsonWebKey{
+			{Kty: "RSA", Kid: "rsa", Use: "sig", N: encode(rsaKey.N.Bytes()), E: encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{Kty: "EC", Kid: "ec", Crv: "P-256", X: encode(ecKey.X.Bytes()), Y: encode(ecKey.Y.Bytes())},
+		}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, _ := r.BasicAuth()
+		if r.Method != http.MethodPost || r.FormValue("code") != testCode || clientID != testClientID || clientSecret != testClientSecret {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_grant"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(tokenResponse{IdToken: mp.idToken(t, "rsa", jwt.MapClaims{"nonce": mp.nonce})})
+	})
+	mp.server = httptest.NewServer(mux)
+	return mp
+}
+
+func (mp *mockProvider) config() config.OIDCConfig {
+	return config.OIDCConfig{
+		Enabled:      true,
+		Issuer:       mp.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  "https://aas.example.com:8444/aas/v1/oidc/callback",
+	}
+}
+
+// idToken returns an ID token of alice signed by the key, the claims override the default claims
+func (mp *mockProvider) idToken(t *testing.T, kid string, overrides jwt.MapClaims) string {
+	claims := jwt.MapClaims{
+		"iss":                mp.server.URL,
+		"sub":                "0f6b2b4e",
+		"aud":                []string{testClientID},
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"iat":                time.Now().Unix(),
+		"preferred_username": "alice",
+		"groups":             []string{"secl-admins", "staff"},
+	}
+	for k, v := range overrides {
+		claims[k] = v
+	}
+	var token *jwt.Token
+	var key interface{}
+	if kid == "ec" {
+		token, key = jwt.NewWithClaims(jwt.SigningMethodES256, claims), mp.ecKey
+	} else {
+		token, key = jwt.NewWithClaims(jwt.SigningMethodRS256, claims), mp.rsaKey
+	}
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	assert.NoError(t, err)
+	return signed
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	mp := newMockProvider(t)
+	defer mp.server.Close()
+	provider, err := NewProvider(mp.config(), mp.server.Client())
+	assert.NoError(t, err)
+
+	identity, err := provider.VerifyIDToken(mp.idToken(t, "rsa", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", identity.Username)
+	assert.Equal(t, "0f6b2b4e", identity.Subject)
+	assert.Equal(t, mp.server.URL+"|0f6b2b4e", identity.QualifiedSubject())
+	assert.Equal(t, []string{"secl-admins", "staff"}, identity.Groups)
+
+	identity, err = provider.VerifyIDToken(mp.idToken(t, "ec", jwt.MapClaims{"aud": testClientID, "preferred_username": nil}))
+	assert.NoError(t, err)
+	assert.Equal(t, "0f6b2b4e", identity.Username)
+	// the key set is cached
+	assert.Equal(t, 1, mp.jwksCalls)
+}
+
+func TestVerifyIDTokenInvalid(t *testing.T) {
+	mp := newMockProvider(t)
+	defer mp.server.Close()
+	provider, err := NewProvider(mp.config(), mp.server.Client())
+	assert.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"iss": mp.server.URL, "sub": "mallory",
+		"aud": testClientID, "exp": time.Now().Add(time.Hour).Unix()})
+	forged.Header["kid"] = "rsa"
+	forgedToken, err := forged.SignedString(otherKey)
+	assert.NoError(t, err)
+
+	for name, token := range map[string]string{
+		"expired":        mp.idToken(t, "rsa", jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}),
+		"wrong issuer":   mp.idToken(t, "rsa", jwt.MapClaims{"iss": "https://idp.example.com"}),
+		"wrong audience": mp.idToken(t, "rsa", jwt.MapClaims{"aud": "hvs"}),
+		"unknown key":    mp.idToken(t, "unknown", nil),
+		"forged":         forgedToken,
+		"hmac":           signHmac(t, mp),
+	} {
+		_, err := provider.VerifyIDToken(token)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestVerifyIDTokenUnknownKeyRefreshInterval(t *testing.T) {
+	mp := newMockProvider(t)
+	defer mp.server.Close()
+	provider, err := NewProvider(mp.config(), mp.server.Client())
+	assert.NoError(t, err)
+
+	// the key set is fetched for the first unknown key id only
+	for i := 0; i < 3; i++ {
+		_, err = provider.VerifyIDToken(mp.idToken(t, "unknown", nil))
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 1, mp.jwksCalls)
+	_, err = provider.VerifyIDToken(mp.idToken(t, "rsa", nil))
+	assert.NoError(t, err)
+
+	// the key set is fetched again once the refresh interval has elapsed
+	provider.keysRefreshed = time.Now().Add(-constants.OidcJwksMinRefreshInterval)
+	_, err = provider.VerifyIDToken(mp.idToken(t, "unknown", nil))
+	assert.Error(t, err)
+	assert.Equal(t, 2, mp.jwksCalls)
+}
+
+func signHmac(t *testing.T, mp *mockProvider) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iss": mp.server.URL, "sub": "mallory",
+		"aud": testClientID, "exp": time.Now().Add(time.Hour).Unix()}).SignedString([]byte(testClientSecret))
+	assert.NoError(t, err)
+	return token
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	mp := newMockProvider(t)
+	defer mp.server.Close()
+	provider, err := NewProvider(mp.config(), mp.server.Client())
+	assert.NoError(t, err)
+	loginStates := NewLoginStates(time.Minute)
+
+	state, nonce, err := loginStates.Start()
+	assert.NoError(t, err)
+	authURL, err := provider.AuthCodeURL(state, nonce)
+	assert.NoError(t, err)
+	parsed, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, "/authorize", parsed.Path)
+	assert.Equal(t, testClientID, parsed.Query().Get("client_id"))
+	assert.Equal(t, "code", parsed.Query().Get("response_type"))
+	assert.Equal(t, state, parsed.Query().Get("state"))
+
+	// the identity provider redirects back with the state and puts the nonce in the ID token
+	mp.nonce = parsed.Query().Get("nonce")
+	completedNonce, err := loginStates.Complete(state)
+	assert.NoError(t, err)
+	identity, err := provider.Exchange(testCode, completedNonce)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", identity.Username)
+
+	// a state can only be used once
+	_, err = loginStates.Complete(state)
+	assert.Error(t, err)
+
+	_, err = provider.Exchange("invalid-code", completedNonce)
+	assert.Error(t, err)
+	_, err = provider.Exchange(testCode, "another-nonce")
+	assert.Error(t, err)
+}
+
+func TestMapGroupsToRoles(t *testing.T) {
+	hvsAdmin := aasModel.RoleInfo{Service: "HVS", Name: "Administrator"}
+	kbsAdmin := aasModel.RoleInfo{Service: "KBS", Name: "Administrator"}
+	reader := aasModel.RoleInfo{Service: "HVS", Name: "ReportSearcher"}
+	mappings := []config.OIDCRoleMapping{
+		{Group: "secl-admins", Roles: []aasModel.RoleInfo{hvsAdmin, kbsAdmin}},
+		{Group: "hvs-admins", Roles: []aasModel.RoleInfo{hvsAdmin}},
+		{Group: "*", Roles: []aasModel.RoleInfo{reader}},
+	}
+
+	assert.Equal(t, []aasModel.RoleInfo{hvsAdmin, kbsAdmin, reader}, MapGroupsToRoles([]string{"hvs-admins", "secl-admins"}, mappings))
+	assert.Equal(t, []aasModel.RoleInfo{reader}, MapGroupsToRoles(nil, mappings))
+	assert.Empty(t, MapGroupsToRoles([]string{"secl-admins"}, nil))
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package oidc
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type loginState struct {
+	nonce     string
+	expiresAt time.Time
+}
+
+// LoginStates keeps the state and nonce of the pending authorization code flows, a state can only be used once
+type LoginStates struct {
+	validity time.Duration
+
+	mu     sync.Mutex
+	states map[string]loginState
+}
+
+func NewLoginStates(validity time.Duration) *LoginStates {
+	return &LoginStates{validity: validity, states: map[string]loginState{}}
+}
+
+// Start returns the state and the nonce of a new authorization code flow
+func (ls *LoginStates) Start() (string, string, error) {
+	state, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	for s, pending := range ls.states {
+		if now.After(pending.expiresAt) {
+			delete(ls.states, s)
+		}
+	}
+	ls.states[state] = loginState{nonce: nonce, expiresAt: now.Add(ls.validity)}
+	return state, nonce, nil
+}
+
+// Complete consumes the state and returns the nonce of its authorization code flow
+func (ls *LoginStates) Complete(state string) (string, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	pending, ok := ls.states[state]
+	if !ok {
+		return "", errors.New("oidc/state:Complete() Unknown login state")
+	}
+	delete(ls.states, state)
+	if time.Now().After(pending.expiresAt) {
+		return "", errors.New("oidc/state:Complete() Login state expired")
+	}
+	return pending.nonce, nil
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "oidc/state:randomString() Failed to generate random bytes")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
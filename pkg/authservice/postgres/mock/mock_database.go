@@ -12,6 +12,9 @@ type MockDatabase struct {
 	MockUserStore       MockUserStore
 	MockRoleStore       MockRoleStore
 	MockPermissionStore MockPermissionStore
+	// the token stores are not mocked, the tests set the stores they use
+	RefreshTokens    domain.RefreshTokenStore
+	TokenRevocations domain.TokenRevocationStore
 }
 
 func (m *MockDatabase) Migrate() error {
@@ -30,6 +33,14 @@ func (m *MockDatabase) PermissionStore() domain.PermissionStore {
 	return &m.MockPermissionStore
 }
 
+func (m *MockDatabase) RefreshTokenStore() domain.RefreshTokenStore {
+	return m.RefreshTokens
+}
+
+func (m *MockDatabase) TokenRevocationStore() domain.TokenRevocationStore {
+	return m.TokenRevocations
+}
+
 func (m *MockDatabase) Close() {
 
 }
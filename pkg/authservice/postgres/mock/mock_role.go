@@ -14,6 +14,7 @@ type MockRoleStore struct {
 	RetrieveAllFunc func(*types.RoleSearch) (types.Roles, error)
 	UpdateFunc      func(types.Role) error
 	DeleteFunc      func(types.Role) error
+	GetUsersFunc    func(types.Role) (types.Users, error)
 }
 
 func (m *MockRoleStore) Create(role types.Role) (*types.Role, error) {
@@ -50,3 +51,10 @@ func (m *MockRoleStore) Delete(role types.Role) error {
 	}
 	return nil
 }
+
+func (m *MockRoleStore) GetUsers(role types.Role) (types.Users, error) {
+	if m.GetUsersFunc != nil {
+		return m.GetUsersFunc(role)
+	}
+	return nil, nil
+}
@@ -175,7 +175,7 @@ func (pd *PostgresDatabase) Migrate() error {
 	defaultLog.Trace("Migrate")
 	defer defaultLog.Trace("Migrate done")
 
-	pd.Db.AutoMigrate(types.User{}, types.Role{}, types.Permission{})
+	pd.Db.AutoMigrate(types.User{}, types.Role{}, types.Permission{}, types.RefreshToken{}, types.TokenRevocation{})
 	return nil
 }
 
@@ -191,6 +191,14 @@ func (pd *PostgresDatabase) PermissionStore() domain.PermissionStore {
 	return &PostgresPermissionStore{db: pd.Db}
 }
 
+func (pd *PostgresDatabase) RefreshTokenStore() domain.RefreshTokenStore {
+	return &PostgresRefreshTokenStore{db: pd.Db}
+}
+
+func (pd *PostgresDatabase) TokenRevocationStore() domain.TokenRevocationStore {
+	return &PostgresTokenRevocationStore{db: pd.Db}
+}
+
 func (pd *PostgresDatabase) Close() {
 	if pd.Db != nil {
 		err := pd.Db.Close()
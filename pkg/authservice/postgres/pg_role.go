@@ -159,3 +159,14 @@ func (r *PostgresPermissionStore) DeletePermission(role types.Role, permissionID
 	}
 	return nil
 }
+
+func (r *PostgresRoleStore) GetUsers(role types.Role) (types.Users, error) {
+	defaultLog.Trace("role GetUsers")
+	defer defaultLog.Trace("role GetUsers done")
+
+	var users types.Users
+	if err := r.db.Model(&role).Association("Users").Find(&users).Error; err != nil {
+		return nil, errors.Wrap(err, "role get users: failed")
+	}
+	return users, nil
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"time"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/types"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+type PostgresRefreshTokenStore struct {
+	db *gorm.DB
+}
+
+func (r *PostgresRefreshTokenStore) Create(token types.RefreshToken) (*types.RefreshToken, error) {
+	defaultLog.Trace("refresh token Create")
+	defer defaultLog.Trace("refresh token Create done")
+
+	uuid, err := UUID()
+	if err != nil {
+		return &token, errors.Wrap(err, "refresh token create: failed to get UUID")
+	}
+	token.ID = uuid
+	if err := r.db.Create(&token).Error; err != nil {
+		return &token, errors.Wrap(err, "refresh token create: failed")
+	}
+	return &token, nil
+}
+
+func (r *PostgresRefreshTokenStore) RetrieveByHash(tokenHash string) (*types.RefreshToken, error) {
+	defaultLog.Trace("refresh token RetrieveByHash")
+	defer defaultLog.Trace("refresh token RetrieveByHash done")
+
+	token := &types.RefreshToken{}
+	if err := r.db.Where(&types.RefreshToken{TokenHash: tokenHash}).First(token).Error; err != nil {
+		return nil, errors.Wrap(err, "refresh token retrieve: failed")
+	}
+	return token, nil
+}
+
+func (r *PostgresRefreshTokenStore) Revoke(id string) (bool, error) {
+	defaultLog.Trace("refresh token Revoke")
+	defer defaultLog.Trace("refresh token Revoke done")
+
+	// the update only matches an active token so that a refresh token can be exchanged once
+	tx := r.db.Model(&types.RefreshToken{}).Where("id = ? AND revoked = ?", id, false).Update("revoked", true)
+	if tx.Error != nil {
+		return false, errors.Wrap(tx.Error, "refresh token revoke: failed")
+	}
+	return tx.RowsAffected == 1, nil
+}
+
+func (r *PostgresRefreshTokenStore) RevokeByUser(userID string) error {
+	defaultLog.Trace("refresh token RevokeByUser")
+	defer defaultLog.Trace("refresh token RevokeByUser done")
+
+	if err := r.db.Model(&types.RefreshToken{}).Where("user_id = ?", userID).Update("revoked", true).Error; err != nil {
+		return errors.Wrap(err, "refresh token revoke by user: failed")
+	}
+	return nil
+}
+
+func (r *PostgresRefreshTokenStore) DeleteExpired(now time.Time) error {
+	defaultLog.Trace("refresh token DeleteExpired")
+	defer defaultLog.Trace("refresh token DeleteExpired done")
+
+	if err := r.db.Where("expires_at < ?", now).Delete(&types.RefreshToken{}).Error; err != nil {
+		return errors.Wrap(err, "refresh token delete expired: failed")
+	}
+	return nil
+}
+
+type PostgresTokenRevocationStore struct {
+	db *gorm.DB
+}
+
+func (r *PostgresTokenRevocationStore) Create(revocation types.TokenRevocation) (*types.TokenRevocation, error) {
+	defaultLog.Trace("token revocation Create")
+	defer defaultLog.Trace("token revocation Create done")
+
+	uuid, err := UUID()
+	if err != nil {
+		return &revocation, errors.Wrap(err, "token revocation create: failed to get UUID")
+	}
+	revocation.ID = uuid
+	if err := r.db.Create(&revocation).Error; err != nil {
+		return &revocation, errors.Wrap(err, "token revocation create: failed")
+	}
+	return &revocation, nil
+}
+
+func (r *PostgresTokenRevocationStore) RetrieveActive(now time.Time) ([]types.TokenRevocation, error) {
+	defaultLog.Trace("token revocation RetrieveActive")
+	defer defaultLog.Trace("token revocation RetrieveActive done")
+
+	var revocations []types.TokenRevocation
+	if err := r.db.Where("expires_at >= ?", now).Order("created_at").Find(&revocations).Error; err != nil {
+		return nil, errors.Wrap(err, "token revocation retrieve active: failed")
+	}
+	return revocations, nil
+}
+
+func (r *PostgresTokenRevocationStore) DeleteExpired(now time.Time) error {
+	defaultLog.Trace("token revocation DeleteExpired")
+	defer defaultLog.Trace("token revocation DeleteExpired done")
+
+	if err := r.db.Where("expires_at < ?", now).Delete(&types.TokenRevocation{}).Error; err != nil {
+		return errors.Wrap(err, "token revocation delete expired: failed")
+	}
+	return nil
+}
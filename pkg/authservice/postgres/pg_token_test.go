@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/types"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSQLMockDatabase returns a PostgresDatabase with a mock database connection
+func newSQLMockDatabase(t *testing.T) (*PostgresDatabase, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	gdb, err := gorm.Open("postgres", db)
+	assert.NoError(t, err)
+	return &PostgresDatabase{Db: gdb}, mock
+}
+
+var tokenRevocationColumns = []string{"id", "created_at", "jti", "subject", "issued_before", "expires_at"}
+
+func TestTokenRevocationStoreCreate(t *testing.T) {
+	pd, mock := newSQLMockDatabase(t)
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "token_revocations" ("id","created_at","jti","subject","issued_before","expires_at") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "token_revocations"."id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "6c6a9d8e-8f0a-4c4b-9b6e-0a4c1f5f2d3e", "", sqlmock.AnyArg(), expiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("0b8a7f0e-6d9b-4e8c-a6a5-2b3c4d5e6f70"))
+	mock.ExpectCommit()
+
+	revocation, err := pd.TokenRevocationStore().Create(types.TokenRevocation{
+		Jti:       "6c6a9d8e-8f0a-4c4b-9b6e-0a4c1f5f2d3e",
+		ExpiresAt: expiresAt,
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, revocation.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRevocationStoreRetrieveActive(t *testing.T) {
+	now := time.Now()
+	issuedBefore := now.Add(-time.Minute)
+
+	tests := []struct {
+		name        string
+		rows        *sqlmock.Rows
+		queryErr    error
+		expected    []types.TokenRevocation
+		expectError bool
+	}{
+		{
+			name: "revoked jti and subject",
+			rows: sqlmock.NewRows(tokenRevocationColumns).
+				AddRow("0b8a7f0e-6d9b-4e8c-a6a5-2b3c4d5e6f70", now, "6c6a9d8e-8f0a-4c4b-9b6e-0a4c1f5f2d3e", "", time.Time{}, now.Add(time.Hour)).
+				AddRow("1c9b8a1f-7e0c-4f9d-b7b6-3c4d5e6f7081", now, "", "admin", issuedBefore, now.Add(time.Hour)),
+			expected: []types.TokenRevocation{
+				{ID: "0b8a7f0e-6d9b-4e8c-a6a5-2b3c4d5e6f70", CreatedAt: now, Jti: "6c6a9d8e-8f0a-4c4b-9b6e-0a4c1f5f2d3e", ExpiresAt: now.Add(time.Hour)},
+				{ID: "1c9b8a1f-7e0c-4f9d-b7b6-3c4d5e6f7081", CreatedAt: now, Subject: "admin", IssuedBefore: issuedBefore, ExpiresAt: now.Add(time.Hour)},
+			},
+		},
+		{
+			name:     "nothing revoked",
+			rows:     sqlmock.NewRows(tokenRevocationColumns),
+			expected: []types.TokenRevocation{},
+		},
+		{
+			name:        "database error",
+			queryErr:    errors.New("connection refused"),
+			expectError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pd, mock := newSQLMockDatabase(t)
+			query := mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "token_revocations" WHERE (expires_at >= $1) ORDER BY created_at`)).
+				WithArgs(now)
+			if tt.queryErr != nil {
+				query.WillReturnError(tt.queryErr)
+			} else {
+				query.WillReturnRows(tt.rows)
+			}
+
+			revocations, err := pd.TokenRevocationStore().RetrieveActive(now)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, revocations)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTokenRevocationStoreDeleteExpired(t *testing.T) {
+	pd, mock := newSQLMockDatabase(t)
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "token_revocations" WHERE (expires_at < $1)`)).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	assert.NoError(t, pd.TokenRevocationStore().DeleteExpired(now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshTokenStoreRevoke(t *testing.T) {
+	tests := []struct {
+		name         string
+		rowsAffected int64
+		expected     bool
+	}{
+		{
+			name:         "active refresh token",
+			rowsAffected: 1,
+			expected:     true,
+		},
+		{
+			name:         "refresh token already revoked",
+			rowsAffected: 0,
+			expected:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pd, mock := newSQLMockDatabase(t)
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE "refresh_tokens" SET "revoked" = $1 WHERE (id = $2 AND revoked = $3)`)).
+				WithArgs(true, "2d0c9b2a-8f1d-4a0e-c8c7-4d5e6f708192", false).
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+			mock.ExpectCommit()
+
+			rotated, err := pd.RefreshTokenStore().Revoke("2d0c9b2a-8f1d-4a0e-c8c7-4d5e6f708192")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, rotated)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -6,35 +6,71 @@
 package router
 
 import (
+	"time"
+
 	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/config"
 	consts "github.com/intel-secl/intel-secl/v4/pkg/authservice/constants"
 	"github.com/intel-secl/intel-secl/v4/pkg/authservice/controllers"
 	"github.com/intel-secl/intel-secl/v4/pkg/authservice/domain"
 	jwtauth "github.com/intel-secl/intel-secl/v4/pkg/lib/common/jwt"
 )
 
-func SetJwtTokenRoutes(r *mux.Router, db domain.AASDatabase, tokFactory *jwtauth.JwtFactory) *mux.Router {
+func SetJwtTokenRoutes(r *mux.Router, db domain.AASDatabase, tokFactory *jwtauth.JwtFactory, jwtCfg config.JWT) *mux.Router {
 	defaultLog.Trace("router/jwt_certificate:SetJwtTokenRoutes() Entering")
 	defer defaultLog.Trace("router/jwt_certificate:SetJwtTokenRoutes() Leaving")
 
-	controller := controllers.JwtTokenController{
-		Database:     db,
-		TokenFactory: tokFactory,
-	}
+	controller := newJwtTokenController(db, tokFactory, jwtCfg)
+	// clients accepting json get a short lived token along with a refresh token
+	r.Handle("/token", ErrorHandler(ResponseHandler(controller.CreateJwtTokenPair, "application/json"))).
+		Methods("POST").HeadersRegexp("Accept", "application/json")
 	r.Handle("/token", ErrorHandler(ResponseHandler(controller.CreateJwtToken, "application/jwt"))).Methods("POST")
+	r.Handle("/token/refresh", ErrorHandler(ResponseHandler(controller.RefreshJwtToken, "application/json"))).Methods("POST")
+	r.Handle("/token/revocations", ErrorHandler(ResponseHandler(controller.GetTokenRevocationList, "application/json"))).Methods("GET")
 	return r
 }
 
-func SetAuthJwtTokenRoutes(r *mux.Router, db domain.AASDatabase, tokFactory *jwtauth.JwtFactory) *mux.Router {
+func SetAuthJwtTokenRoutes(r *mux.Router, db domain.AASDatabase, tokFactory *jwtauth.JwtFactory, jwtCfg config.JWT) *mux.Router {
 	defaultLog.Trace("router/jwt_certificate:SetAuthJwtTokenRoutes() Entering")
 	defer defaultLog.Trace("router/jwt_certificate:SetAuthJwtTokenRoutes() Leaving")
 
-	controller := controllers.JwtTokenController{
-		Database:     db,
-		TokenFactory: tokFactory,
-	}
+	controller := newJwtTokenController(db, tokFactory, jwtCfg)
 	r.Handle("/custom-claims-token", ErrorHandler(permissionsHandler(ResponseHandler(controller.CreateCustomClaimsJwtToken,
 		"application/jwt"), []string{consts.CustomClaimsCreate}))).Methods("POST")
+	r.Handle("/token/revoke", ErrorHandler(permissionsHandler(ResponseHandler(controller.RevokeJwtToken,
+		""), []string{consts.TokenRevoke}))).Methods("POST")
 
 	return r
 }
+
+func newJwtTokenController(db domain.AASDatabase, tokFactory *jwtauth.JwtFactory, jwtCfg config.JWT) controllers.JwtTokenController {
+	return controllers.JwtTokenController{
+		Database:                db,
+		TokenFactory:            tokFactory,
+		AccessTokenValidity:     time.Duration(jwtCfg.AccessTokenDurationMins) * time.Minute,
+		RefreshTokenValidity:    time.Duration(jwtCfg.RefreshTokenDurationMins) * time.Minute,
+		RevocationValidity:      tokenRevocationValidity(jwtCfg),
+		CustomClaimsMaxValidity: time.Duration(customClaimsMaxDurationMins(jwtCfg)) * time.Minute,
+	}
+}
+
+// tokenRevocationValidity returns the longest validity of the tokens that can be issued, including the custom claims
+// tokens, a revocation has to be kept until the revoked tokens are expired
+func tokenRevocationValidity(jwtCfg config.JWT) time.Duration {
+	validityMins := jwtCfg.TokenDurationMins
+	for _, mins := range []int{jwtCfg.AccessTokenDurationMins, customClaimsMaxDurationMins(jwtCfg)} {
+		if mins > validityMins {
+			validityMins = mins
+		}
+	}
+	return time.Duration(validityMins) * time.Minute
+}
+
+// customClaimsMaxDurationMins returns the longest validity of the custom claims tokens, the configurations predating
+// the setting use the default
+func customClaimsMaxDurationMins(jwtCfg config.JWT) int {
+	if jwtCfg.CustomClaimsMaxDurationMins <= 0 {
+		return consts.DefaultCustomClaimsMaxDurationMins
+	}
+	return jwtCfg.CustomClaimsMaxDurationMins
+}
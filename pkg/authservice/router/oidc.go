@@ -0,0 +1,51 @@
+/*
+ *  Copyright (C) 2021 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package router
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/config"
+	consts "github.com/intel-secl/intel-secl/v4/pkg/authservice/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/oidc"
+	"github.com/intel-secl/intel-secl/v4/pkg/clients"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
+	jwtauth "github.com/intel-secl/intel-secl/v4/pkg/lib/common/jwt"
+)
+
+// SetOidcRoutes sets the public endpoints exchanging the identities of the federated identity provider for AAS tokens
+func SetOidcRoutes(r *mux.Router, oidcConfig config.OIDCConfig, db domain.AASDatabase, tokFactory *jwtauth.JwtFactory) *mux.Router {
+	defaultLog.Trace("router/oidc:SetOidcRoutes() Entering")
+	defer defaultLog.Trace("router/oidc:SetOidcRoutes() Leaving")
+
+	// the identity provider is trusted through the system CAs or the CAs trusted by AAS
+	trustedCAs, err := crypt.GetCertsFromDir(consts.TrustedCAsStoreDir)
+	if err != nil {
+		defaultLog.WithError(err).Warn("router/oidc:SetOidcRoutes() Could not read trusted CA certificates")
+	}
+	httpClient, err := clients.HTTPClientWithCA(trustedCAs)
+	if err != nil {
+		defaultLog.WithError(err).Error("router/oidc:SetOidcRoutes() Could not create OIDC http client, OIDC login is disabled")
+		return r
+	}
+	provider, err := oidc.NewProvider(oidcConfig, httpClient)
+	if err != nil {
+		defaultLog.WithError(err).Error("router/oidc:SetOidcRoutes() Invalid OIDC configuration, OIDC login is disabled")
+		return r
+	}
+
+	controller := controllers.OidcController{
+		Database:     db,
+		TokenFactory: tokFactory,
+		Provider:     provider,
+		LoginStates:  oidc.NewLoginStates(consts.OidcLoginStateValidity),
+	}
+	r.Handle("/oidc/token", ErrorHandler(ResponseHandler(controller.CreateJwtTokenFromIdToken, "application/jwt"))).Methods("POST")
+	r.Handle("/oidc/login", ErrorHandler(ResponseHandler(controller.Login, ""))).Methods("GET")
+	r.Handle("/oidc/callback", ErrorHandler(ResponseHandler(controller.Callback, "application/jwt"))).Methods("GET")
+	return r
+}
@@ -7,15 +7,16 @@ package router
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/config"
 	"github.com/intel-secl/intel-secl/v4/pkg/authservice/controllers"
 	"github.com/intel-secl/intel-secl/v4/pkg/authservice/domain"
 )
 
-func SetRolesRoutes(r *mux.Router, db domain.AASDatabase) *mux.Router {
+func SetRolesRoutes(r *mux.Router, db domain.AASDatabase, jwtCfg config.JWT) *mux.Router {
 	defaultLog.Trace("router/roles:SetRolesRoutes() Entering")
 	defer defaultLog.Trace("router/roles:SetRolesRoutes() Leaving")
 
-	controller := controllers.RolesController{Database: db}
+	controller := controllers.RolesController{Database: db, RevocationValidity: tokenRevocationValidity(jwtCfg)}
 
 	r.Handle("/roles", ErrorHandler(ResponseHandler(controller.CreateRole, "application/json"))).Methods("POST")
 	r.Handle("/roles", ErrorHandler(ResponseHandler(controller.QueryRoles, "application/json"))).Methods("GET")
@@ -12,9 +12,11 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/intel-secl/intel-secl/v4/pkg/authservice/config"
 	"github.com/intel-secl/intel-secl/v4/pkg/authservice/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/controllers"
 	"github.com/intel-secl/intel-secl/v4/pkg/authservice/postgres"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
 	cmw "github.com/intel-secl/intel-secl/v4/pkg/lib/common/middleware"
+	aasModel "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
 )
 
 var defaultLog = log.GetDefaultLogger()
@@ -48,17 +50,22 @@ func defineSubRoutes(router *mux.Router, service string, cfg *config.Configurati
 	subRouter := router.PathPrefix(serviceApi).Subrouter()
 	subRouter = SetVersionRoutes(subRouter)
 	subRouter = SetJwtCertificateRoutes(subRouter)
-	subRouter = SetJwtTokenRoutes(subRouter, dataStore, tokenFactory)
-	subRouter = SetUsersNoAuthRoutes(subRouter, dataStore)
+	subRouter = SetJwtTokenRoutes(subRouter, dataStore, tokenFactory, cfg.JWT)
+	subRouter = SetUsersNoAuthRoutes(subRouter, dataStore, cfg.JWT)
+	if cfg.OIDC.Enabled {
+		subRouter = SetOidcRoutes(subRouter, cfg.OIDC, dataStore, tokenFactory)
+	}
 
 	subRouter = router.PathPrefix(serviceApi).Subrouter()
 	cfgRouter := Router{cfg: cfg}
-	subRouter.Use(cmw.NewTokenAuth(constants.TokenSignKeysAndCertDir,
+	subRouter.Use(cmw.NewTokenAuthWithRevocationList(constants.TokenSignKeysAndCertDir,
 		constants.TrustedCAsStoreDir, cfgRouter.retrieveJWTSigningCerts,
-		time.Minute*constants.DefaultJwtValidateCacheKeyMins))
-	subRouter = SetRolesRoutes(subRouter, dataStore)
-	subRouter = SetUsersRoutes(subRouter, dataStore)
-	subRouter = SetAuthJwtTokenRoutes(subRouter, dataStore, tokenFactory)
+		time.Minute*constants.DefaultJwtValidateCacheKeyMins, func() (*aasModel.TokenRevocationList, error) {
+			return controllers.RetrieveTokenRevocationList(dataStore)
+		}, cmw.DefaultRevocationListCacheTime))
+	subRouter = SetRolesRoutes(subRouter, dataStore, cfg.JWT)
+	subRouter = SetUsersRoutes(subRouter, dataStore, cfg.JWT)
+	subRouter = SetAuthJwtTokenRoutes(subRouter, dataStore, tokenFactory, cfg.JWT)
 	subRouter = SetCredentialsRoutes(subRouter, cfg.Nats.UserCredentialValidity)
 
 }
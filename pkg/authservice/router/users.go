@@ -7,16 +7,17 @@ package router
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/authservice/config"
 	consts "github.com/intel-secl/intel-secl/v4/pkg/authservice/constants"
 	"github.com/intel-secl/intel-secl/v4/pkg/authservice/controllers"
 	"github.com/intel-secl/intel-secl/v4/pkg/authservice/domain"
 )
 
-func SetUsersRoutes(r *mux.Router, db domain.AASDatabase) *mux.Router {
+func SetUsersRoutes(r *mux.Router, db domain.AASDatabase, jwtCfg config.JWT) *mux.Router {
 	defaultLog.Trace("router/users:SetUsersRoutes() Entering")
 	defer defaultLog.Trace("router/users:SetUsersRoutes() Leaving")
 
-	controller := controllers.UsersController{Database: db}
+	controller := controllers.UsersController{Database: db, RevocationValidity: tokenRevocationValidity(jwtCfg)}
 
 	r.Handle("/users", ErrorHandler(permissionsHandler(ResponseHandler(controller.CreateUser,
 		"application/json"), []string{consts.UserCreate}))).Methods("POST")
@@ -42,11 +43,11 @@ func SetUsersRoutes(r *mux.Router, db domain.AASDatabase) *mux.Router {
 	return r
 }
 
-func SetUsersNoAuthRoutes(r *mux.Router, db domain.AASDatabase) *mux.Router {
+func SetUsersNoAuthRoutes(r *mux.Router, db domain.AASDatabase, jwtCfg config.JWT) *mux.Router {
 	defaultLog.Trace("router/users:SetUsersNoAuthRoutes() Entering")
 	defer defaultLog.Trace("router/users:SetUsersNoAuthRoutes() Leaving")
 
-	controller := controllers.UsersController{Database: db}
+	controller := controllers.UsersController{Database: db, RevocationValidity: tokenRevocationValidity(jwtCfg)}
 	r.Handle("/users/changepassword", ErrorHandler(ResponseHandler(controller.ChangePassword,
 		""))).Methods("PATCH")
 
@@ -6,6 +6,7 @@
 package tasks
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/intel-secl/intel-secl/v4/pkg/authservice/config"
 	commConfig "github.com/intel-secl/intel-secl/v4/pkg/lib/common/config"
@@ -30,6 +31,9 @@ var envHelp = map[string]string{
 	"LOG_ENABLE_STDOUT":                   "Enable console log",
 	"JWT_INCLUDE_KID":                     "Includes JWT Key Id for token validation",
 	"JWT_TOKEN_DURATION_MINS":             "Validity of token duration",
+	"JWT_ACCESS_TOKEN_DURATION_MINS":      "Validity of access token duration when issued with a refresh token",
+	"JWT_REFRESH_TOKEN_DURATION_MINS":     "Validity of refresh token duration",
+	"JWT_CUSTOM_CLAIMS_MAX_DURATION_MINS": "Maximum validity of custom claims token duration",
 	"JWT_CERT_COMMON_NAME":                "Common Name for JWT Certificate",
 	"AUTH_DEFENDER_MAX_ATTEMPTS":          "Auth defender maximum attempts",
 	"AUTH_DEFENDER_INTERVAL_MINS":         "Auth defender interval in minutes",
@@ -45,6 +49,15 @@ var envHelp = map[string]string{
 	"NATS_ACCOUNT_NAME":                   "Set the NATS account name, default is \"ISecL-account\"",
 	"NATS_ACCOUNT_CREDENTIAL_VALIDITY":    "Set the NATS account credential validity, default is 5 years",
 	"NATS_USER_CREDENTIAL_VALIDITY":       "Set the NATS user credential validity, default is 1 year",
+	"OIDC_ENABLED":                        "Enable the login with the OpenID Connect identity provider, default is false",
+	"OIDC_ISSUER":                         "Issuer URL of the OpenID Connect identity provider",
+	"OIDC_CLIENT_ID":                      "Client id of AAS registered with the identity provider",
+	"OIDC_CLIENT_SECRET":                  "Client secret of AAS registered with the identity provider",
+	"OIDC_REDIRECT_URL":                   "Redirect URL of the authorization code flow, https://<AAS>:<PORT>/aas/v1/oidc/callback",
+	"OIDC_SCOPES":                         "Scopes requested by the authorization code flow, default is \"openid profile email\"",
+	"OIDC_USERNAME_CLAIM":                 "ID token claim holding the user name logged by AAS, default is \"preferred_username\"",
+	"OIDC_GROUPS_CLAIM":                   "ID token claim holding the groups of the user, default is \"groups\"",
+	"OIDC_ROLE_MAPPINGS":                  "JSON list of group to role mappings, e.g. [{\"group\":\"admins\",\"roles\":[{\"service\":\"HVS\",\"name\":\"Administrator\"}]}]",
 }
 
 func (uc UpdateServiceConfig) Run() error {
@@ -57,9 +70,12 @@ func (uc UpdateServiceConfig) Run() error {
 	}
 
 	(*uc.AppConfig).JWT = config.JWT{
-		IncludeKid:        viper.GetBool("jwt-include-kid"),
-		TokenDurationMins: viper.GetInt("jwt-token-duration-mins"),
-		CertCommonName:    viper.GetString("jwt-cert-common-name"),
+		IncludeKid:                  viper.GetBool("jwt-include-kid"),
+		TokenDurationMins:           viper.GetInt("jwt-token-duration-mins"),
+		AccessTokenDurationMins:     viper.GetInt("jwt-access-token-duration-mins"),
+		RefreshTokenDurationMins:    viper.GetInt("jwt-refresh-token-duration-mins"),
+		CustomClaimsMaxDurationMins: viper.GetInt("jwt-custom-claims-max-duration-mins"),
+		CertCommonName:              viper.GetString("jwt-cert-common-name"),
 	}
 
 	(*uc.AppConfig).AuthDefender = config.AuthDefender{
@@ -80,6 +96,25 @@ func (uc UpdateServiceConfig) Run() error {
 		UserCredentialValidity: viper.GetDuration("nats-user-credential-validity"),
 	}
 
+	roleMappings := (*uc.AppConfig).OIDC.RoleMappings
+	if mappings := viper.GetString("oidc-role-mappings"); mappings != "" {
+		roleMappings = nil
+		if err := json.Unmarshal([]byte(mappings), &roleMappings); err != nil {
+			return errors.Wrap(err, "tasks/update_service_config:Run() Invalid OIDC role mappings")
+		}
+	}
+	(*uc.AppConfig).OIDC = config.OIDCConfig{
+		Enabled:       viper.GetBool("oidc-enabled"),
+		Issuer:        viper.GetString("oidc-issuer"),
+		ClientID:      viper.GetString("oidc-client-id"),
+		ClientSecret:  viper.GetString("oidc-client-secret"),
+		RedirectURL:   viper.GetString("oidc-redirect-url"),
+		Scopes:        viper.GetString("oidc-scopes"),
+		UsernameClaim: viper.GetString("oidc-username-claim"),
+		GroupsClaim:   viper.GetString("oidc-groups-claim"),
+		RoleMappings:  roleMappings,
+	}
+
 	if uc.ServerConfig.Port < 1024 ||
 		uc.ServerConfig.Port > 65535 {
 		uc.ServerConfig.Port = uc.DefaultPort
@@ -95,6 +130,9 @@ func (uc UpdateServiceConfig) Validate() error {
 		(*uc.AppConfig).Server.Port > 65535 {
 		return errors.New("Configured port is not valid")
 	}
+	if (*uc.AppConfig).OIDC.Enabled && ((*uc.AppConfig).OIDC.Issuer == "" || (*uc.AppConfig).OIDC.ClientID == "") {
+		return errors.New("OIDC issuer and client id are required when OIDC is enabled")
+	}
 
 	return nil
 }
@@ -0,0 +1,32 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package types
+
+import (
+	"time"
+)
+
+// RefreshToken struct is the database schema of the refresh_tokens table. Only the hash of the refresh token is
+// stored, a refresh token is revoked once it has been exchanged for a new token pair.
+type RefreshToken struct {
+	ID        string    `gorm:"primary_key;type:uuid"`
+	CreatedAt time.Time `json:"-"`
+	TokenHash string    `gorm:"unique_index;not null"`
+	UserID    string    `gorm:"type:uuid;index"`
+	ExpiresAt time.Time `gorm:"index"`
+	Revoked   bool
+}
+
+// TokenRevocation struct is the database schema of the token_revocations table. It either revokes the token with
+// the jti or all the tokens issued to the subject before the revocation. The revocation is kept until the revoked
+// tokens are expired.
+type TokenRevocation struct {
+	ID           string    `gorm:"primary_key;type:uuid"`
+	CreatedAt    time.Time `json:"-"`
+	Jti          string    `gorm:"index"`
+	Subject      string    `gorm:"index"`
+	IssuedBefore time.Time
+	ExpiresAt    time.Time `gorm:"index"`
+}
@@ -48,6 +48,9 @@ var (
 	ErrHTTPGetRolesForUser = &clients.HTTPClientErr{
 		ErrMessage: "Failed to get roles for user",
 	}
+	ErrHTTPGetTokenRevocationList = &clients.HTTPClientErr{
+		ErrMessage: "Failed to get token revocation list",
+	}
 )
 
 func (c *Client) prepReqHeader(req *http.Request) {
@@ -365,3 +368,41 @@ func (c *Client) GetCredentials(createCredentailsReq types.CreateCredentialsReq)
 	}
 	return creds, nil
 }
+
+// GetTokenRevocationList returns the revoked tokens that are not expired yet, the list is public and the request is
+// sent without a bearer token
+func (c *Client) GetTokenRevocationList() (*types.TokenRevocationList, error) {
+
+	revocationsURL := clients.ResolvePath(c.BaseURL, "token/revocations")
+
+	req, err := http.NewRequest(http.MethodGet, revocationsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if c.HTTPClient == nil {
+		return nil, errors.New("aasClient.GetTokenRevocationList: HTTPClient should not be null")
+	}
+	rsp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		derr := rsp.Body.Close()
+		if derr != nil {
+			log.WithError(derr).Error("Error closing response body")
+		}
+	}()
+	if rsp.StatusCode != http.StatusOK {
+		ErrHTTPGetTokenRevocationList.RetCode = rsp.StatusCode
+		return nil, ErrHTTPGetTokenRevocationList
+	}
+
+	var revocationList types.TokenRevocationList
+	err = json.NewDecoder(rsp.Body).Decode(&revocationList)
+	if err != nil {
+		return nil, err
+	}
+	return &revocationList, nil
+}
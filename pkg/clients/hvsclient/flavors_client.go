@@ -25,6 +25,8 @@ import (
 
 type FlavorsClient interface {
 	CreateFlavor(flavorCreateRequest *models.FlavorCreateRequest) (hvs.FlavorCollection, error)
+	ExportFlavors(flavorgroupNames []string) (*hvs.FlavorBundle, error)
+	ImportFlavors(bundle *hvs.FlavorBundle) (*hvs.FlavorBundleImportResult, error)
 }
 
 //-------------------------------------------------------------------------------------------------
@@ -90,3 +92,109 @@ func (client *flavorsClientImpl) CreateFlavor(flavorCreateRequest *models.Flavor
 	}
 	return flavors, nil
 }
+
+// ExportFlavors retrieves the given flavorgroups, all of them when none is given, as a signed flavor bundle
+func (client *flavorsClientImpl) ExportFlavors(flavorgroupNames []string) (*hvs.FlavorBundle, error) {
+	log.Trace("hvsclient/flavors_client:ExportFlavors() Entering")
+	defer log.Trace("hvsclient/flavors_client:ExportFlavors() Leaving")
+
+	parsedUrl, err := url.Parse(client.cfg.BaseURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "hvsclient/flavors_client:ExportFlavors() error parsing base url")
+	}
+
+	parsedUrl.Path = path.Join(parsedUrl.Path, "flavors", "export")
+	query := parsedUrl.Query()
+	for _, flavorgroupName := range flavorgroupNames {
+		query.Add("flavorgroupName", flavorgroupName)
+	}
+	parsedUrl.RawQuery = query.Encode()
+	request, err := http.NewRequest("GET", parsedUrl.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "hvsclient/flavors_client:ExportFlavors() error creating request")
+	}
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+client.cfg.BearerToken)
+
+	response, err := client.httpClient.Do(request)
+	if err != nil {
+		secLog.Warn(message.BadConnection)
+		return nil, errors.Wrapf(err, "hvsclient/flavors_client:ExportFlavors() Error while making request to %s", parsedUrl)
+	}
+
+	defer func() {
+		derr := response.Body.Close()
+		if derr != nil {
+			log.WithError(derr).Error("Error closing response body")
+		}
+	}()
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("hvsclient/flavors_client:ExportFlavors() request made to %s returned status %d", parsedUrl, response.StatusCode)
+	}
+
+	jsonData, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.Errorf("hvsclient/flavors_client:ExportFlavors() Error reading response")
+	}
+
+	var bundle hvs.FlavorBundle
+	err = json.Unmarshal(jsonData, &bundle)
+	if err != nil {
+		return nil, errors.Wrap(err, "hvsclient/flavors_client:ExportFlavors() Error unmarshalling json data to flavor bundle")
+	}
+	return &bundle, nil
+}
+
+// ImportFlavors adds the content of a flavor bundle exported by another HVS
+func (client *flavorsClientImpl) ImportFlavors(bundle *hvs.FlavorBundle) (*hvs.FlavorBundleImportResult, error) {
+	log.Trace("hvsclient/flavors_client:ImportFlavors() Entering")
+	defer log.Trace("hvsclient/flavors_client:ImportFlavors() Leaving")
+
+	jsonData, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, err
+	}
+	parsedUrl, err := url.Parse(client.cfg.BaseURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "hvsclient/flavors_client:ImportFlavors() error parsing base url")
+	}
+
+	parsedUrl.Path = path.Join(parsedUrl.Path, "flavors", "import")
+	request, err := http.NewRequest("POST", parsedUrl.String(), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, errors.Wrap(err, "hvsclient/flavors_client:ImportFlavors() error creating request")
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+client.cfg.BearerToken)
+
+	response, err := client.httpClient.Do(request)
+	if err != nil {
+		secLog.Warn(message.BadConnection)
+		return nil, errors.Wrapf(err, "hvsclient/flavors_client:ImportFlavors() Error while making request to %s", parsedUrl)
+	}
+
+	defer func() {
+		derr := response.Body.Close()
+		if derr != nil {
+			log.WithError(derr).Error("Error closing response body")
+		}
+	}()
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("hvsclient/flavors_client:ImportFlavors() request made to %s returned status %d", parsedUrl, response.StatusCode)
+	}
+
+	jsonData, err = ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.Errorf("hvsclient/flavors_client:ImportFlavors() Error reading response")
+	}
+
+	log.Debugf("hvsclient/flavors_client:ImportFlavors() Json response body returned: %s", string(jsonData))
+
+	var result hvs.FlavorBundleImportResult
+	err = json.Unmarshal(jsonData, &result)
+	if err != nil {
+		return nil, errors.Wrap(err, "hvsclient/flavors_client:ImportFlavors() Error unmarshalling json data to import result")
+	}
+	return &result, nil
+}
@@ -32,6 +32,10 @@ type HostsClient interface {
 
 	//  Updates the host with the specified attributes. Except for the host name, all other attributes can be updated.
 	UpdateHost(host *hvs.Host) (*hvs.Host, error)
+
+	// Calls fn for each host matching the specified criteria, fetching the hosts one page at a time.
+	// The iteration stops at the first error returned by fn.
+	ForEachHost(criteria *models.HostFilterCriteria, fn func(*hvs.Host) error) error
 }
 
 //-------------------------------------------------------------------------------------------------
@@ -86,6 +90,10 @@ func (client *hostsClientImpl) SearchHosts(hostFilterCriteria *models.HostFilter
 		query.Add("value", hostFilterCriteria.Value)
 	}
 
+	if hostFilterCriteria.Page != nil {
+		addPageQueryParams(query, hostFilterCriteria.Page)
+	}
+
 	request.URL.RawQuery = query.Encode()
 
 	log.Debugf("SearchHosts: %s", request.URL.RawQuery)
@@ -240,3 +248,26 @@ func (client *hostsClientImpl) UpdateHost(host *hvs.Host) (*hvs.Host, error) {
 
 	return &updatedHost, nil
 }
+
+func (client *hostsClientImpl) ForEachHost(criteria *models.HostFilterCriteria, fn func(*hvs.Host) error) error {
+	log.Trace("hvsclient/hosts_client:ForEachHost() Entering")
+	defer log.Trace("hvsclient/hosts_client:ForEachHost() Leaving")
+
+	pageCriteria := *criteria
+	pageCriteria.Page = newPageCriteria(criteria.Page, models.SortByName)
+
+	for {
+		hosts, err := client.SearchHosts(&pageCriteria)
+		if err != nil {
+			return err
+		}
+		for _, host := range hosts.Hosts {
+			if err := fn(host); err != nil {
+				return err
+			}
+		}
+		if pageCriteria.Page.After, err = nextPageCursor(hosts.Next); err != nil || pageCriteria.Page.After == nil {
+			return err
+		}
+	}
+}
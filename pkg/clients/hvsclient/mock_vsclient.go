@@ -80,6 +80,11 @@ func (mock MockedHostsClient) UpdateHost(host *hvs.Host) (*hvs.Host, error) {
 	return args.Get(0).(*hvs.Host), args.Error(1)
 }
 
+func (mock MockedHostsClient) ForEachHost(hostFilterCriteria *models.HostFilterCriteria, fn func(*hvs.Host) error) error {
+	args := mock.Called(hostFilterCriteria, fn)
+	return args.Error(0)
+}
+
 //-------------------------------------------------------------------------------------------------
 // Mocked Flavors interface
 //-------------------------------------------------------------------------------------------------
@@ -92,6 +97,16 @@ func (mock MockedFlavorsClient) CreateFlavor(flavorCreateRequest *models.FlavorC
 	return args.Get(0).(hvs.FlavorCollection), args.Error(0)
 }
 
+func (mock MockedFlavorsClient) ExportFlavors(flavorgroupNames []string) (*hvs.FlavorBundle, error) {
+	args := mock.Called(flavorgroupNames)
+	return args.Get(0).(*hvs.FlavorBundle), args.Error(1)
+}
+
+func (mock MockedFlavorsClient) ImportFlavors(bundle *hvs.FlavorBundle) (*hvs.FlavorBundleImportResult, error) {
+	args := mock.Called(bundle)
+	return args.Get(0).(*hvs.FlavorBundleImportResult), args.Error(1)
+}
+
 //-------------------------------------------------------------------------------------------------
 // Mocked Manifests interface
 //-------------------------------------------------------------------------------------------------
@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package hvsclient
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/pkg/errors"
+)
+
+// defaultPageSize is the number of records fetched per request by the iterator helpers
+const defaultPageSize = 1000
+
+func addPageQueryParams(query url.Values, page *models.PageCriteria) {
+	if page.Limit > 0 {
+		query.Add("limit", strconv.Itoa(page.Limit))
+	}
+	if page.SortBy != "" {
+		query.Add("sortBy", page.SortBy)
+	}
+	if page.OrderBy != "" {
+		query.Add("orderBy", string(page.OrderBy))
+	}
+	if page.After != nil {
+		query.Add("after", page.After.Encode())
+	}
+}
+
+// newPageCriteria returns a copy of page, defaulting the page size and sort field when they are not set
+func newPageCriteria(page *models.PageCriteria, defaultSortBy string) *models.PageCriteria {
+	pc := models.PageCriteria{}
+	if page != nil {
+		pc = *page
+	}
+	if pc.Limit <= 0 {
+		pc.Limit = defaultPageSize
+	}
+	if pc.SortBy == "" {
+		pc.SortBy = defaultSortBy
+	}
+	return &pc
+}
+
+// nextPageCursor extracts the cursor from the next page link of a search response, nil is
+// returned when there are no more pages
+func nextPageCursor(next string) (*models.PageCursor, error) {
+	if next == "" {
+		return nil, nil
+	}
+	nextUrl, err := url.Parse(next)
+	if err != nil {
+		return nil, errors.Wrap(err, "hvsclient/pagination:nextPageCursor() Error parsing next page link")
+	}
+	cursor, err := models.DecodePageCursor(nextUrl.Query().Get("after"))
+	if err != nil {
+		return nil, errors.Wrap(err, "hvsclient/pagination:nextPageCursor() Invalid next page link")
+	}
+	return cursor, nil
+}
@@ -7,7 +7,11 @@ package hvsclient
 import (
 	"bytes"
 	"encoding/json"
+	"strconv"
+
+	"github.com/google/uuid"
 	"github.com/intel-secl/intel-secl/v4/pkg/clients/util"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
 	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
@@ -15,10 +19,18 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 type ReportsClient interface {
 	CreateSAMLReport(hvs.ReportCreateRequest) ([]byte, error)
+
+	// Searches for the reports with the specified criteria.
+	SearchReports(*models.ReportFilterCriteria) (*hvs.ReportCollection, error)
+
+	// Calls fn for each report matching the specified criteria, fetching the reports one page at a time.
+	// The iteration stops at the first error returned by fn.
+	ForEachReport(criteria *models.ReportFilterCriteria, fn func(*hvs.Report) error) error
 }
 
 type reportsClientImpl struct {
@@ -83,3 +95,114 @@ func (client reportsClientImpl) CreateSAMLReport(reportCreateRequest hvs.ReportC
 
 	return samlReport, nil
 }
+
+func (client reportsClientImpl) SearchReports(criteria *models.ReportFilterCriteria) (*hvs.ReportCollection, error) {
+	log.Trace("hvsclient/reports_client:SearchReports() Entering")
+	defer log.Trace("hvsclient/reports_client:SearchReports() Leaving")
+
+	parsedUrl, err := url.Parse(client.cfg.BaseURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "hvsclient/reports_client:SearchReports() Configured HVS URL is malformed")
+	}
+	reports, _ := parsedUrl.Parse("reports")
+	endpoint := parsedUrl.ResolveReference(reports)
+
+	query := endpoint.Query()
+	if criteria.ID != uuid.Nil {
+		query.Add("id", criteria.ID.String())
+	}
+	if criteria.HostID != uuid.Nil {
+		query.Add("hostId", criteria.HostID.String())
+	}
+	if criteria.HostHardwareID != uuid.Nil {
+		query.Add("hostHardwareId", criteria.HostHardwareID.String())
+	}
+	if criteria.HostName != "" {
+		query.Add("hostName", criteria.HostName)
+	}
+	if criteria.HostStatus != "" {
+		query.Add("hostStatus", criteria.HostStatus)
+	}
+	if criteria.NumberOfDays != 0 {
+		query.Add("numberOfDays", strconv.Itoa(criteria.NumberOfDays))
+	}
+	if !criteria.FromDate.IsZero() {
+		query.Add("fromDate", criteria.FromDate.Format(time.RFC3339Nano))
+	}
+	if !criteria.ToDate.IsZero() {
+		query.Add("toDate", criteria.ToDate.Format(time.RFC3339Nano))
+	}
+	query.Add("latestPerHost", strconv.FormatBool(criteria.LatestPerHost))
+	if criteria.Page != nil {
+		addPageQueryParams(query, criteria.Page)
+	} else if criteria.Limit > 0 {
+		query.Add("limit", strconv.Itoa(criteria.Limit))
+	}
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", endpoint.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "hvsclient/reports_client:SearchReports() Failed to instantiate http request to HVS")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	var data []byte
+	if client.cfg.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+client.cfg.BearerToken)
+		rsp, err := client.httpClient.Do(req)
+		if err != nil {
+			return nil, errors.Wrapf(err, "hvsclient/reports_client:SearchReports() Error making request to %s", endpoint)
+		}
+		defer func() {
+			derr := rsp.Body.Close()
+			if derr != nil {
+				log.WithError(derr).Error("Error closing response body")
+			}
+		}()
+		if rsp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("hvsclient/reports_client:SearchReports() Request made to %s returned status %d", endpoint, rsp.StatusCode)
+		}
+		data, err = ioutil.ReadAll(rsp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "hvsclient/reports_client:SearchReports() Error reading response")
+		}
+	} else {
+		certs, err := crypt.GetCertsFromDir(client.cfg.CaCertsDir)
+		if err != nil {
+			return nil, errors.Wrap(err, "hvsclient/reports_client:SearchReports() Error while retrieving ca certs from dir")
+		}
+		data, err = util.SendRequest(req, client.cfg.AasAPIUrl, client.cfg.UserName, client.cfg.Password, certs)
+		if err != nil {
+			return nil, errors.Wrap(err, "hvsclient/reports_client:SearchReports() Error while sending request")
+		}
+	}
+
+	var reportCollection hvs.ReportCollection
+	if err := json.Unmarshal(data, &reportCollection); err != nil {
+		return nil, errors.Wrap(err, "hvsclient/reports_client:SearchReports() Error while unmarshalling the response")
+	}
+	return &reportCollection, nil
+}
+
+func (client reportsClientImpl) ForEachReport(criteria *models.ReportFilterCriteria, fn func(*hvs.Report) error) error {
+	log.Trace("hvsclient/reports_client:ForEachReport() Entering")
+	defer log.Trace("hvsclient/reports_client:ForEachReport() Leaving")
+
+	pageCriteria := *criteria
+	pageCriteria.Page = newPageCriteria(criteria.Page, models.SortByCreated)
+
+	for {
+		reports, err := client.SearchReports(&pageCriteria)
+		if err != nil {
+			return err
+		}
+		for _, report := range reports.Reports {
+			if err := fn(report); err != nil {
+				return err
+			}
+		}
+		if pageCriteria.Page.After, err = nextPageCursor(reports.Next); err != nil || pageCriteria.Page.After == nil {
+			return err
+		}
+	}
+}
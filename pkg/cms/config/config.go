@@ -12,6 +12,7 @@ import (
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v2"
 	"os"
+	"time"
 )
 
 // Configuration is the global configuration struct that is marshalled/unmarshalled to a persisted yaml file
@@ -26,6 +27,7 @@ type Configuration struct {
 	AasJwtCn          string                  `yaml:"aas-jwt-cn" mapstructure:"aas-jwt-cn"`
 	AasTlsCn          string                  `yaml:"aas-tls-cn" mapstructure:"aas-tls-cn"`
 	AasTlsSan         string                  `yaml:"aas-tls-san" mapstructure:"aas-tls-san"`
+	Revocation        RevocationConfig        `yaml:"revocation" mapstructure:"revocation"`
 }
 
 type CACertConfig struct {
@@ -36,6 +38,14 @@ type CACertConfig struct {
 	Country      string `yaml:"country" mapstructure:"country"`
 }
 
+// RevocationConfig holds the settings of the CRL and OCSP services. The CRL distribution point and OCSP URLs are
+// embedded in the issued certificates only when BaseUrl is set.
+type RevocationConfig struct {
+	BaseUrl            string        `yaml:"base-url" mapstructure:"base-url"`
+	CrlValidity        time.Duration `yaml:"crl-validity" mapstructure:"crl-validity"`
+	CrlRefreshInterval time.Duration `yaml:"crl-refresh-interval" mapstructure:"crl-refresh-interval"`
+}
+
 // this function sets the configuration file name and type
 func init() {
 	viper.SetConfigName(constants.ConfigFile)
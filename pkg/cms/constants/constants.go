@@ -32,6 +32,9 @@ const (
 	TLSCertPath                    = ConfigDir + "tls-cert.pem"
 	TLSKeyPath                     = ConfigDir + "tls.key"
 	SerialNumberPath               = ConfigDir + "serial-number"
+	IssuedCertsDirPath             = ConfigDir + "issued-certs/"
+	CrlDirPath                     = ConfigDir + "crl/"
+	EstPathPrefix                  = "/.well-known/est"
 	ServiceRemoveCmd               = "systemctl disable cms"
 	DefaultRootCACommonName        = "CMSCA"
 	DefaultPort                    = 8445
@@ -43,6 +46,7 @@ const (
 	DefaultKeyAlgorithm            = "rsa"
 	DefaultKeyAlgorithmLength      = 3072
 	CertApproverGroupName          = "CertApprover"
+	CertManagerGroupName           = "CertManager"
 	DefaultAasJwtCn                = "AAS JWT Signing Certificate"
 	DefaultAasTlsCn                = "AAS TLS Certificate"
 	DefaultTlsSan                  = "127.0.0.1,localhost"
@@ -54,12 +58,16 @@ const (
 	DefaultIdleTimeout             = 10 * time.Second
 	DefaultMaxHeaderBytes          = 1 << 20
 	DefaultLogEntryMaxlength       = 300
+	DefaultCrlValidity             = 24 * time.Hour
+	DefaultCrlRefreshInterval      = time.Hour
+	DefaultOcspResponseValidity    = time.Hour
 )
 
 type CaAttrib struct {
 	CommonName string
 	CertPath   string
 	KeyPath    string
+	CrlPath    string
 }
 
 const (
@@ -70,10 +78,10 @@ const (
 )
 
 var mp = map[string]CaAttrib{
-	Root:      {"CMSCA", RootCACertPath, RootCAKeyPath},
-	Tls:       {"CMS TLS CA", IntermediateCADirPath + "tls-ca.pem", IntermediateCADirPath + "tls-ca.key"},
-	TlsClient: {"CMS TLS Client CA", IntermediateCADirPath + "tls-client-ca.pem", IntermediateCADirPath + "tls-client-ca.key"},
-	Signing:   {"CMS Signing CA", IntermediateCADirPath + "signing-ca.pem", IntermediateCADirPath + "signing-ca.key"},
+	Root:      {"CMSCA", RootCACertPath, RootCAKeyPath, ""},
+	Tls:       {"CMS TLS CA", IntermediateCADirPath + "tls-ca.pem", IntermediateCADirPath + "tls-ca.key", CrlDirPath + "tls-ca.crl"},
+	TlsClient: {"CMS TLS Client CA", IntermediateCADirPath + "tls-client-ca.pem", IntermediateCADirPath + "tls-client-ca.key", CrlDirPath + "tls-client-ca.crl"},
+	Signing:   {"CMS Signing CA", IntermediateCADirPath + "signing-ca.pem", IntermediateCADirPath + "signing-ca.key", CrlDirPath + "signing-ca.crl"},
 }
 
 func GetIntermediateCAs() []string {
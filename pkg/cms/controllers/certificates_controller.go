@@ -11,6 +11,7 @@ import (
 	"encoding/pem"
 	"github.com/intel-secl/intel-secl/v4/pkg/cms/config"
 	"github.com/intel-secl/intel-secl/v4/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/directory"
 	"github.com/intel-secl/intel-secl/v4/pkg/cms/utils"
 	"github.com/intel-secl/intel-secl/v4/pkg/cms/validation"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/auth"
@@ -19,14 +20,23 @@ import (
 	commLogMsg "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/message"
 	v "github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
 	ct "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/cms"
 	"io/ioutil"
 	"net/http"
 	"strings"
 	"time"
 )
 
+// the issuing CAs and the serial numbers of the issued certificates are looked up through these, which the tests
+// replace to issue certificates from their own CAs
+var (
+	getCaAttribs        = constants.GetCaAttribs
+	getNextSerialNumber = utils.GetNextSerialNumber
+)
+
 type CertificatesController struct {
 	Config *config.Configuration
+	Store  *directory.IssuedCertificateStore
 }
 
 //GetCertificates is used to get the JWT Signing/TLS certificate upon JWT validation
@@ -157,15 +167,52 @@ func (controller CertificatesController) GetCertificates(httpWriter http.Respons
 	}
 	log.Debug("resource/certificates:GetCertificates() Received valid CSR")
 
-	serialNumber, err := utils.GetNextSerialNumber()
+	certificate, caCert, issueErr := controller.issueCertificate(clientCSR, certType)
+	if issueErr != nil {
+		httpWriter.WriteHeader(issueErr.StatusCode)
+		_, err = httpWriter.Write([]byte(issueErr.Message))
+		if err != nil {
+			log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to write response")
+		}
+		return
+	}
+
+	httpWriter.Header().Add("Content-Type", "application/x-pem-file")
+	httpWriter.WriteHeader(http.StatusOK)
+	// encode the certificate first
+	err = pem.Encode(httpWriter, &pem.Block{Type: "CERTIFICATE", Bytes: certificate})
 	if err != nil {
-		log.WithError(err).Error("resource/certificates:GetCertificates() Failed to read next Serial Number")
+		log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to encode certificate")
 		httpWriter.WriteHeader(http.StatusInternalServerError)
-		_, err = httpWriter.Write([]byte("Failed to read next Serial Number"))
+		_, err = httpWriter.Write([]byte("Cannot encode issued certificate"))
+		if err != nil {
+			log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to write response")
+		}
+	}
+	// include the issuing CA as well since clients would need the entire chain minus the root.
+	err = pem.Encode(httpWriter, &pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})
+	if err != nil {
+		log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to encode certificate")
+		httpWriter.WriteHeader(http.StatusInternalServerError)
+		_, err = httpWriter.Write([]byte("Cannot encode Issuing CA"))
 		if err != nil {
 			log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to write response")
 		}
-		return
+	}
+	log.Infof("resource/certificates:GetCertificates() Issued certificate for requested CSR with CN - %v", clientCSR.Subject.String())
+	return
+}
+
+// issueCertificate signs the validated CSR with the issuing CA of the certificate type and records the issued
+// certificate in the inventory
+func (controller CertificatesController) issueCertificate(clientCSR *x509.CertificateRequest, certType string) ([]byte, *x509.Certificate, *resourceError) {
+	log.Trace("resource/certificates:issueCertificate() Entering")
+	defer log.Trace("resource/certificates:issueCertificate() Leaving")
+
+	serialNumber, err := getNextSerialNumber()
+	if err != nil {
+		log.WithError(err).Error("resource/certificates:issueCertificate() Failed to read next Serial Number")
+		return nil, nil, &resourceError{Message: "Failed to read next Serial Number", StatusCode: http.StatusInternalServerError}
 	}
 
 	clientCRTTemplate := x509.Certificate{
@@ -187,7 +234,7 @@ func (controller CertificatesController) GetCertificates(httpWriter http.Respons
 	// in the CSR and that the the CN is not in the form of a domain name/ IP address
 
 	var issuingCa string
-	log.Debugf("resource/certificates:GetCertificates() Processing CSR with cert type - %v", certType)
+	log.Debugf("resource/certificates:issueCertificate() Processing CSR with cert type - %v", certType)
 	if strings.EqualFold(certType, "TLS") {
 		issuingCa = constants.Tls
 		clientCRTTemplate.DNSNames = clientCSR.DNSNames
@@ -205,57 +252,38 @@ func (controller CertificatesController) GetCertificates(httpWriter http.Respons
 
 	} else {
 		log.Errorf("Invalid certType provided")
-		httpWriter.WriteHeader(http.StatusBadRequest)
-		_, err = httpWriter.Write([]byte("Invalid certType provided"))
-		if err != nil {
-			log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to write response")
-		}
-		return
+		return nil, nil, &resourceError{Message: "Invalid certType provided", StatusCode: http.StatusBadRequest}
 	}
-	caAttr := constants.GetCaAttribs(issuingCa)
+	if controller.Config.Revocation.BaseUrl != "" {
+		clientCRTTemplate.CRLDistributionPoints = []string{utils.CrlDistributionPoint(controller.Config.Revocation.BaseUrl, issuingCa)}
+		clientCRTTemplate.OCSPServer = []string{utils.OcspServer(controller.Config.Revocation.BaseUrl)}
+	}
+	caAttr := getCaAttribs(issuingCa)
 
 	caCert, caPrivKey, err := crypt.LoadX509CertAndPrivateKey(caAttr.CertPath, caAttr.KeyPath)
 	if err != nil {
-		log.WithError(err).Error("resource/certificates:GetCertificates() Could not load Issuing CA")
-		httpWriter.WriteHeader(http.StatusInternalServerError)
-		_, err = httpWriter.Write([]byte("Cannot load Issuing CA"))
-		if err != nil {
-			log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to write response")
-		}
+		log.WithError(err).Error("resource/certificates:issueCertificate() Could not load Issuing CA")
+		return nil, nil, &resourceError{Message: "Cannot load Issuing CA", StatusCode: http.StatusInternalServerError}
 	}
 
 	certificate, err := x509.CreateCertificate(rand.Reader, &clientCRTTemplate, caCert, clientCSR.PublicKey, caPrivKey)
 	if err != nil {
-		log.WithError(err).Error("resource/certificates:GetCertificates() Cannot create certificate from CSR")
-		httpWriter.WriteHeader(http.StatusInternalServerError)
-		_, err = httpWriter.Write([]byte("Cannot create certificate"))
-		if err != nil {
-			log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to write response")
-		}
+		log.WithError(err).Error("resource/certificates:issueCertificate() Cannot create certificate from CSR")
+		return nil, nil, &resourceError{Message: "Cannot create certificate", StatusCode: http.StatusInternalServerError}
 	}
 
-	httpWriter.Header().Add("Content-Type", "application/x-pem-file")
-	httpWriter.WriteHeader(http.StatusOK)
-	// encode the certificate first
-	err = pem.Encode(httpWriter, &pem.Block{Type: "CERTIFICATE", Bytes: certificate})
-	if err != nil {
-		log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to encode certificate")
-		httpWriter.WriteHeader(http.StatusInternalServerError)
-		_, err = httpWriter.Write([]byte("Cannot encode issued certificate"))
-		if err != nil {
-			log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to write response")
-		}
-	}
-	// include the issuing CA as well since clients would need the entire chain minus the root.
-	err = pem.Encode(httpWriter, &pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})
+	// a certificate that is not part of the inventory could not be revoked, so it is not handed out
+	_, err = controller.Store.Create(&cms.IssuedCertificate{
+		SerialNumber: directory.SerialNumberString(serialNumber),
+		Subject:      clientCSR.Subject.CommonName,
+		CertType:     certType,
+		IssuingCa:    issuingCa,
+		NotBefore:    clientCRTTemplate.NotBefore.UTC(),
+		NotAfter:     clientCRTTemplate.NotAfter.UTC(),
+	})
 	if err != nil {
-		log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to encode certificate")
-		httpWriter.WriteHeader(http.StatusInternalServerError)
-		_, err = httpWriter.Write([]byte("Cannot encode Issuing CA"))
-		if err != nil {
-			log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to write response")
-		}
+		log.WithError(err).Error("resource/certificates:issueCertificate() Cannot record issued certificate")
+		return nil, nil, &resourceError{Message: "Cannot record issued certificate", StatusCode: http.StatusInternalServerError}
 	}
-	log.Infof("resource/certificates:GetCertificates() Issued certificate for requested CSR with CN - %v", clientCSR.Subject.String())
-	return
+	return certificate, caCert, nil
 }
@@ -0,0 +1,293 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/config"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/directory"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/utils"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/validation"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/auth"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/context"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	commLogMsg "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/message"
+	v "github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
+	ct "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
+	"github.com/pkg/errors"
+)
+
+const maxEstRequestBytes = 64 * 1024
+
+// EstController implements the simple enrollment of RFC 7030 (Enrollment over Secure Transport) on top of the
+// certificate issuance of CMS. The optional EST label selects the certificate type.
+type EstController struct {
+	Config *config.Configuration
+	Store  *directory.IssuedCertificateStore
+}
+
+// CaCerts is used to get the root and intermediate CA certificates of CMS as a certs-only PKCS#7
+func (controller EstController) CaCerts() http.Handler {
+	log.Trace("resource/est:CaCerts() Entering")
+	defer log.Trace("resource/est:CaCerts() Leaving")
+
+	return errorHandlerFunc(func(httpWriter http.ResponseWriter, httpRequest *http.Request) error {
+		caCerts, err := loadCaCertificates()
+		if err != nil {
+			log.WithError(err).Error("resource/est:CaCerts() Cannot load CA certificates")
+			return resourceError{Message: "Cannot load CA certificates", StatusCode: http.StatusInternalServerError}
+		}
+		return writeCertsOnly(httpWriter, caCerts)
+	})
+}
+
+// SimpleEnroll is used to issue a certificate for a CSR, the request is authorized the same way as the CSRs posted
+// to /certificates
+func (controller EstController) SimpleEnroll() http.Handler {
+	log.Trace("resource/est:SimpleEnroll() Entering")
+	defer log.Trace("resource/est:SimpleEnroll() Leaving")
+
+	return errorHandlerFunc(func(httpWriter http.ResponseWriter, httpRequest *http.Request) error {
+		privileges, err := context.GetUserRoles(httpRequest)
+		if err != nil {
+			slog.WithError(err).Warn("resource/est:SimpleEnroll() Failed to read roles and permissions")
+			return resourceError{Message: "Could not get user roles from http context", StatusCode: http.StatusInternalServerError}
+		}
+		ctxMap, foundRole := auth.ValidatePermissionAndGetRoleContext(privileges,
+			[]ct.RoleInfo{{Service: constants.ServiceName, Name: constants.CertApproverGroupName}},
+			true)
+		if !foundRole {
+			slog.Warning(commLogMsg.UnauthorizedAccess)
+			return privilegeError{Message: "Unauthorized", StatusCode: http.StatusUnauthorized}
+		}
+
+		certType, err := estCertType(httpRequest)
+		if err != nil {
+			return err
+		}
+		clientCSR, err := readEstCertificateRequest(httpWriter, httpRequest)
+		if err != nil {
+			return err
+		}
+
+		if err = validation.ValidateCertificateRequest(controller.Config, clientCSR, certType, ctxMap); err != nil {
+			slog.Warning(commLogMsg.InvalidInputBadParam)
+			log.WithError(err).Error("resource/est:SimpleEnroll() Invalid CSR provided")
+			return resourceError{Message: "Invalid CSR provided", StatusCode: http.StatusBadRequest}
+		}
+		return controller.enroll(httpWriter, clientCSR, certType)
+	})
+}
+
+// SimpleReenroll is used to renew a certificate issued by CMS. The request is authenticated by the TLS client
+// certificate being renewed, the CSR has to keep its subject and subject alternative names.
+func (controller EstController) SimpleReenroll() http.Handler {
+	log.Trace("resource/est:SimpleReenroll() Entering")
+	defer log.Trace("resource/est:SimpleReenroll() Leaving")
+
+	return errorHandlerFunc(func(httpWriter http.ResponseWriter, httpRequest *http.Request) error {
+		if httpRequest.TLS == nil || len(httpRequest.TLS.PeerCertificates) == 0 {
+			slog.Warning(commLogMsg.UnauthorizedAccess)
+			return privilegeError{Message: "Re-enrollment requires a TLS client certificate", StatusCode: http.StatusUnauthorized}
+		}
+		clientCert := httpRequest.TLS.PeerCertificates[0]
+		if err := verifyIssuedByCms(clientCert, httpRequest.TLS.PeerCertificates[1:]); err != nil {
+			slog.WithError(err).Warn(commLogMsg.UnauthorizedAccess)
+			return privilegeError{Message: "TLS client certificate is not trusted", StatusCode: http.StatusUnauthorized}
+		}
+
+		issuedCert, err := controller.Store.Retrieve(directory.SerialNumberString(clientCert.SerialNumber))
+		if err != nil {
+			if err.Error() == commErr.RecordNotFound {
+				slog.Warning(commLogMsg.UnauthorizedAccess)
+				return privilegeError{Message: "TLS client certificate was not issued by CMS", StatusCode: http.StatusUnauthorized}
+			}
+			log.WithError(err).Error("resource/est:SimpleReenroll() Failed to retrieve issued certificate")
+			return resourceError{Message: "Failed to retrieve issued certificate", StatusCode: http.StatusInternalServerError}
+		}
+		if issuedCert.Revoked {
+			slog.Warningf("resource/est:SimpleReenroll() %s Certificate %s is revoked", commLogMsg.UnauthorizedAccess, issuedCert.SerialNumber)
+			return privilegeError{Message: "TLS client certificate is revoked", StatusCode: http.StatusUnauthorized}
+		}
+
+		certType := issuedCert.CertType
+		if label := mux.Vars(httpRequest)["label"]; label != "" && !strings.EqualFold(label, certType) {
+			slog.Warning(commLogMsg.InvalidInputBadParam)
+			return resourceError{Message: "Certificate type of the label does not match the certificate being renewed", StatusCode: http.StatusBadRequest}
+		}
+		clientCSR, err := readEstCertificateRequest(httpWriter, httpRequest)
+		if err != nil {
+			return err
+		}
+
+		// RFC 7030 4.2.2 the subject and subject alternative names of the renewed certificate do not change
+		if !bytes.Equal(clientCSR.RawSubject, clientCert.RawSubject) ||
+			!sameStrings(clientCSR.DNSNames, clientCert.DNSNames) || !sameIPs(clientCSR.IPAddresses, clientCert.IPAddresses) {
+			slog.Warning(commLogMsg.InvalidInputBadParam)
+			return resourceError{Message: "CSR subject does not match the certificate being renewed", StatusCode: http.StatusBadRequest}
+		}
+
+		// the certificate being renewed grants the same role context as a token of the CertApprover role
+		ctxMap := map[string]ct.RoleInfo{reenrollRoleContext(clientCert, certType): {Service: constants.ServiceName, Name: constants.CertApproverGroupName}}
+		if err = validation.ValidateCertificateRequest(controller.Config, clientCSR, certType, &ctxMap); err != nil {
+			slog.Warning(commLogMsg.InvalidInputBadParam)
+			log.WithError(err).Error("resource/est:SimpleReenroll() Invalid CSR provided")
+			return resourceError{Message: "Invalid CSR provided", StatusCode: http.StatusBadRequest}
+		}
+		return controller.enroll(httpWriter, clientCSR, certType)
+	})
+}
+
+func (controller EstController) enroll(httpWriter http.ResponseWriter, clientCSR *x509.CertificateRequest, certType string) error {
+	certController := CertificatesController{Config: controller.Config, Store: controller.Store}
+	certificate, caCert, issueErr := certController.issueCertificate(clientCSR, certType)
+	if issueErr != nil {
+		return issueErr
+	}
+	issuedCert, err := x509.ParseCertificate(certificate)
+	if err != nil {
+		return errors.Wrap(err, "Failed to parse issued certificate")
+	}
+	log.Infof("resource/est:enroll() Issued certificate for requested CSR with CN - %v", clientCSR.Subject.String())
+	return writeCertsOnly(httpWriter, []*x509.Certificate{issuedCert, caCert})
+}
+
+// estCertType returns the certificate type selected by the EST label, TLS certificates are issued without label
+func estCertType(httpRequest *http.Request) (string, error) {
+	certType := mux.Vars(httpRequest)["label"]
+	if certType == "" {
+		return constants.Tls, nil
+	}
+	if err := v.ValidateStrings([]string{certType}); err != nil {
+		slog.Warning(commLogMsg.InvalidInputBadParam)
+		return "", resourceError{Message: "EST label is in invalid format", StatusCode: http.StatusBadRequest}
+	}
+	return certType, nil
+}
+
+// readEstCertificateRequest reads the base64 encoded DER CSR of the EST requests and checks its signature
+func readEstCertificateRequest(httpWriter http.ResponseWriter, httpRequest *http.Request) (*x509.CertificateRequest, error) {
+	if !strings.HasPrefix(httpRequest.Header.Get("Content-Type"), "application/pkcs10") {
+		return nil, resourceError{Message: "Content type not supported", StatusCode: http.StatusUnsupportedMediaType}
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(httpWriter, httpRequest.Body, maxEstRequestBytes))
+	if err != nil {
+		slog.Warning(commLogMsg.InvalidInputBadParam)
+		return nil, resourceError{Message: "Cannot read http request body", StatusCode: http.StatusBadRequest}
+	}
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(body)), ""))
+	if err != nil {
+		slog.Warning(commLogMsg.InvalidInputBadEncoding)
+		return nil, resourceError{Message: "CSR is not base64 encoded", StatusCode: http.StatusBadRequest}
+	}
+	clientCSR, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		slog.Warning(commLogMsg.InvalidInputBadParam)
+		log.WithError(err).Error("resource/est:readEstCertificateRequest() Invalid CSR provided")
+		return nil, resourceError{Message: "Invalid CSR provided", StatusCode: http.StatusBadRequest}
+	}
+	if err = clientCSR.CheckSignature(); err != nil {
+		slog.Warning(commLogMsg.InvalidInputBadParam)
+		log.WithError(err).Error("resource/est:readEstCertificateRequest() CSR signature does not match")
+		return nil, resourceError{Message: "Invalid CSR provided", StatusCode: http.StatusBadRequest}
+	}
+	return clientCSR, nil
+}
+
+// loadCaCertificates returns the root CA certificate followed by the intermediate CA certificates
+func loadCaCertificates() ([]*x509.Certificate, error) {
+	var caCerts []*x509.Certificate
+	for _, ca := range append([]string{constants.Root}, constants.GetIntermediateCAs()...) {
+		caCert, err := crypt.GetCertFromPemFile(getCaAttribs(ca).CertPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not load %s CA certificate", ca)
+		}
+		caCerts = append(caCerts, caCert)
+	}
+	return caCerts, nil
+}
+
+// verifyIssuedByCms verifies that the certificate chains up to the root CA of CMS
+func verifyIssuedByCms(cert *x509.Certificate, peerIntermediates []*x509.Certificate) error {
+	caCerts, err := loadCaCertificates()
+	if err != nil {
+		return err
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(caCerts[0])
+	intermediates := x509.NewCertPool()
+	for _, caCert := range append(caCerts[1:], peerIntermediates...) {
+		intermediates.AddCert(caCert)
+	}
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// reenrollRoleContext returns the role context, in the format of the CertApprover role contexts, matching the
+// subject and subject alternative names of the certificate
+func reenrollRoleContext(cert *x509.Certificate, certType string) string {
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return "CN=" + cert.Subject.CommonName + ";SAN=" + strings.Join(sans, ",") + ";CERTTYPE=" + certType
+}
+
+func writeCertsOnly(httpWriter http.ResponseWriter, certs []*x509.Certificate) error {
+	pkcs7, err := utils.EncodeCertsOnlyPkcs7(certs)
+	if err != nil {
+		return errors.Wrap(err, "Failed to encode certificates")
+	}
+	httpWriter.Header().Set("Content-Type", "application/pkcs7-mime; smime-type=certs-only")
+	httpWriter.Header().Set("Content-Transfer-Encoding", "base64")
+	httpWriter.WriteHeader(http.StatusOK)
+	_, err = httpWriter.Write([]byte(base64.StdEncoding.EncodeToString(pkcs7)))
+	if err != nil {
+		log.WithError(err).Error("Failed to write response")
+	}
+	return nil
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameIPs(a, b []net.IP) bool {
+	toStrings := func(ips []net.IP) []string {
+		var s []string
+		for _, ip := range ips {
+			s = append(s, ip.String())
+		}
+		return s
+	}
+	return sameStrings(toStrings(a), toStrings(b))
+}
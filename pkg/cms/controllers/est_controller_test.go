@@ -0,0 +1,339 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/config"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/directory"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/utils"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/context"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
+	ct "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/cms"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testEstCommonName  = "HVS TLS Certificate"
+	testEstRoleContext = "CN=" + testEstCommonName + ";SAN=hvs.com,127.0.0.1;CERTTYPE=TLS"
+)
+
+// testEstCas holds the root CA and the intermediate CAs the EST controller issues the certificates from
+type testEstCas struct {
+	root          *x509.Certificate
+	intermediates []*x509.Certificate
+}
+
+func newTestRsaCa(t *testing.T, dir, ca string, parent *x509.Certificate, parentKey *rsa.PrivateKey) (*x509.Certificate, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: constants.GetCaAttribs(ca).CommonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	assert.NoError(t, crypt.SavePemCert(der, filepath.Join(dir, ca+".pem")))
+	assert.NoError(t, crypt.SavePrivateKeyAsPKCS8(keyDer, filepath.Join(dir, ca+".key")))
+	return cert, key
+}
+
+// newTestEstController issues the certificates from CAs created for the test
+func newTestEstController(t *testing.T) (*EstController, *testEstCas) {
+	dir := t.TempDir()
+	cas := &testEstCas{}
+	var rootKey *rsa.PrivateKey
+	cas.root, rootKey = newTestRsaCa(t, dir, constants.Root, nil, nil)
+	for _, ca := range constants.GetIntermediateCAs() {
+		caCert, _ := newTestRsaCa(t, dir, ca, cas.root, rootKey)
+		cas.intermediates = append(cas.intermediates, caCert)
+	}
+
+	serialNumber := big.NewInt(100)
+	getCaAttribs = func(ca string) constants.CaAttrib {
+		attr := constants.GetCaAttribs(ca)
+		if attr.CommonName == "" {
+			return attr
+		}
+		return constants.CaAttrib{CommonName: attr.CommonName, CertPath: filepath.Join(dir, ca+".pem"),
+			KeyPath: filepath.Join(dir, ca+".key")}
+	}
+	getNextSerialNumber = func() (*big.Int, error) {
+		serialNumber = new(big.Int).Add(serialNumber, big.NewInt(1))
+		return serialNumber, nil
+	}
+	t.Cleanup(func() {
+		getCaAttribs = constants.GetCaAttribs
+		getNextSerialNumber = utils.GetNextSerialNumber
+	})
+
+	return &EstController{
+		Config: &config.Configuration{},
+		Store:  directory.NewIssuedCertificateStore(t.TempDir()),
+	}, cas
+}
+
+func newTestCsr(t *testing.T, key *rsa.PrivateKey, commonName string, dnsNames []string, ips []net.IP) string {
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:            pkix.Name{CommonName: commonName},
+		DNSNames:           dnsNames,
+		IPAddresses:        ips,
+		SignatureAlgorithm: x509.SHA384WithRSA,
+	}, key)
+	assert.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+// decodeCertsOnly returns the certificates of a base64 encoded certs-only PKCS#7 response
+func decodeCertsOnly(t *testing.T, body string) []*x509.Certificate {
+	der, err := base64.StdEncoding.DecodeString(body)
+	assert.NoError(t, err)
+	var contentInfo struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue `asn1:"optional"`
+	}
+	_, err = asn1.Unmarshal(der, &contentInfo)
+	assert.NoError(t, err)
+	var signedData struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		ContentInfo      asn1.RawValue
+		Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+		SignerInfos      asn1.RawValue
+	}
+	_, err = asn1.Unmarshal(contentInfo.Content.Bytes, &signedData)
+	assert.NoError(t, err)
+	certs, err := x509.ParseCertificates(signedData.Certificates.Bytes)
+	assert.NoError(t, err)
+	return certs
+}
+
+func newTestEstRequest(path, csr string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, constants.EstPathPrefix+path, strings.NewReader(csr))
+	req.Header.Set("Content-Type", "application/pkcs10")
+	return req
+}
+
+// enrollTestCertificate issues a TLS certificate through simple enrollment
+func enrollTestCertificate(t *testing.T, controller *EstController, key *rsa.PrivateKey) *x509.Certificate {
+	req := newTestEstRequest("/simpleenroll",
+		newTestCsr(t, key, testEstCommonName, []string{"hvs.com"}, []net.IP{net.ParseIP("127.0.0.1")}))
+	req = context.SetUserRoles(req, []ct.RoleInfo{{Service: constants.ServiceName,
+		Name: constants.CertApproverGroupName, Context: testEstRoleContext}})
+	rr := httptest.NewRecorder()
+	controller.SimpleEnroll().ServeHTTP(rr, req)
+	if !assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String()) {
+		t.FailNow()
+	}
+	return decodeCertsOnly(t, rr.Body.String())[0]
+}
+
+func TestEstCaCerts(t *testing.T) {
+	controller, cas := newTestEstController(t)
+
+	rr := httptest.NewRecorder()
+	controller.CaCerts().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, constants.EstPathPrefix+"/cacerts", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/pkcs7-mime; smime-type=certs-only", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "base64", rr.Header().Get("Content-Transfer-Encoding"))
+
+	certs := decodeCertsOnly(t, rr.Body.String())
+	if assert.Len(t, certs, 1+len(cas.intermediates)) {
+		assert.True(t, certs[0].Equal(cas.root))
+		for i, intermediate := range cas.intermediates {
+			assert.True(t, certs[i+1].Equal(intermediate))
+		}
+	}
+}
+
+func TestEstSimpleEnroll(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	validCsr := newTestCsr(t, key, testEstCommonName, []string{"hvs.com"}, []net.IP{net.ParseIP("127.0.0.1")})
+	certApprover := []ct.RoleInfo{{Service: constants.ServiceName, Name: constants.CertApproverGroupName,
+		Context: testEstRoleContext}}
+
+	tests := []struct {
+		name           string
+		csr            string
+		contentType    string
+		roles          []ct.RoleInfo
+		expectedStatus int
+	}{
+		{name: "enroll", csr: validCsr, roles: certApprover, expectedStatus: http.StatusOK},
+		{name: "CSR split over lines", csr: validCsr[:64] + "\r\n" + validCsr[64:], roles: certApprover,
+			expectedStatus: http.StatusOK},
+		{
+			name:           "not a certificate approver",
+			csr:            validCsr,
+			roles:          []ct.RoleInfo{{Service: constants.ServiceName, Name: constants.CertManagerGroupName}},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "unsupported content type",
+			csr:            validCsr,
+			contentType:    "application/x-pem-file",
+			roles:          certApprover,
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{name: "CSR not base64 encoded", csr: "not a CSR!", roles: certApprover, expectedStatus: http.StatusBadRequest},
+		{
+			name:           "common name without role",
+			csr:            newTestCsr(t, key, "AAS TLS Certificate", []string{"hvs.com"}, []net.IP{net.ParseIP("127.0.0.1")}),
+			roles:          certApprover,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, cas := newTestEstController(t)
+			req := newTestEstRequest("/simpleenroll", tt.csr)
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			req = context.SetUserRoles(req, tt.roles)
+			rr := httptest.NewRecorder()
+			controller.SimpleEnroll().ServeHTTP(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			// the issued certificate is followed by its issuing CA
+			certs := decodeCertsOnly(t, rr.Body.String())
+			if !assert.Len(t, certs, 2) {
+				return
+			}
+			assert.True(t, certs[1].Equal(cas.intermediates[0]))
+			assert.Equal(t, testEstCommonName, certs[0].Subject.CommonName)
+			assert.Equal(t, []string{"hvs.com"}, certs[0].DNSNames)
+			assert.NoError(t, certs[0].CheckSignatureFrom(cas.intermediates[0]))
+
+			issuedCert, err := controller.Store.Retrieve(directory.SerialNumberString(certs[0].SerialNumber))
+			assert.NoError(t, err)
+			assert.Equal(t, constants.Tls, issuedCert.CertType)
+		})
+	}
+}
+
+func TestEstSimpleReenroll(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	otherCaCert, otherCaKey := newTestCertificate(t, "Other CA", 1, nil, nil)
+	notIssuedByCms, _ := newTestCertificate(t, testEstCommonName, 2, otherCaCert, otherCaKey)
+	sans := []net.IP{net.ParseIP("127.0.0.1")}
+
+	tests := []struct {
+		name           string
+		csr            string
+		label          string
+		noClientCert   bool
+		revoked        bool
+		clientCert     *x509.Certificate
+		expectedStatus int
+	}{
+		{name: "reenroll", csr: newTestCsr(t, key, testEstCommonName, []string{"hvs.com"}, sans), expectedStatus: http.StatusOK},
+		{
+			name:           "subject mismatch",
+			csr:            newTestCsr(t, key, "AAS TLS Certificate", []string{"hvs.com"}, sans),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "subject alternative names mismatch",
+			csr:            newTestCsr(t, key, testEstCommonName, []string{"aas.com"}, sans),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "label of another certificate type",
+			csr:            newTestCsr(t, key, testEstCommonName, []string{"hvs.com"}, sans),
+			label:          constants.Signing,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "no client certificate",
+			csr:            newTestCsr(t, key, testEstCommonName, []string{"hvs.com"}, sans),
+			noClientCert:   true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "revoked client certificate",
+			csr:            newTestCsr(t, key, testEstCommonName, []string{"hvs.com"}, sans),
+			revoked:        true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "client certificate not issued by CMS",
+			csr:            newTestCsr(t, key, testEstCommonName, []string{"hvs.com"}, sans),
+			clientCert:     notIssuedByCms,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, _ := newTestEstController(t)
+			clientCert := enrollTestCertificate(t, controller, key)
+			if tt.revoked {
+				_, err := controller.Store.Revoke(directory.SerialNumberString(clientCert.SerialNumber),
+					cms.RevocationReasonKeyCompromise)
+				assert.NoError(t, err)
+			}
+			if tt.clientCert != nil {
+				clientCert = tt.clientCert
+			}
+
+			req := newTestEstRequest("/simplereenroll", tt.csr)
+			if tt.label != "" {
+				req = mux.SetURLVars(req, map[string]string{"label": tt.label})
+			}
+			if !tt.noClientCert {
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{clientCert}}
+			}
+			rr := httptest.NewRecorder()
+			controller.SimpleReenroll().ServeHTTP(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code, rr.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			// the renewed certificate keeps the subject under a new serial number
+			renewedCert := decodeCertsOnly(t, rr.Body.String())[0]
+			assert.Equal(t, clientCert.RawSubject, renewedCert.RawSubject)
+			assert.NotEqual(t, 0, clientCert.SerialNumber.Cmp(renewedCert.SerialNumber))
+			_, err := controller.Store.Retrieve(directory.SerialNumberString(renewedCert.SerialNumber))
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,355 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/config"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/directory"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/utils"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/auth"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/context"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	commLogMsg "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/message"
+	v "github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
+	ct "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/cms"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ocsp"
+)
+
+const maxOcspRequestBytes = 10 * 1024
+
+// RevocationController serves the inventory of the issued certificates, their revocation and the CRL and OCSP
+// services used by the relying parties to check the revocation status
+type RevocationController struct {
+	Config      *config.Configuration
+	Store       *directory.IssuedCertificateStore
+	ocspIssuers []ocspIssuer
+}
+
+// ocspIssuer is an intermediate CA whose certificates are checked by the OCSP responder
+type ocspIssuer struct {
+	issuingCa string
+	cert      *x509.Certificate
+	key       crypto.Signer
+	publicKey []byte
+}
+
+// NewRevocationController loads the intermediate CAs signing the OCSP responses
+func NewRevocationController(config *config.Configuration, store *directory.IssuedCertificateStore) (*RevocationController, error) {
+	log.Trace("resource/revocation:NewRevocationController() Entering")
+	defer log.Trace("resource/revocation:NewRevocationController() Leaving")
+
+	controller := &RevocationController{Config: config, Store: store}
+	for _, issuingCa := range constants.GetIntermediateCAs() {
+		caAttr := constants.GetCaAttribs(issuingCa)
+		caCert, caKey, err := crypt.LoadX509CertAndPrivateKey(caAttr.CertPath, caAttr.KeyPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resource/revocation:NewRevocationController() Could not load %s CA", issuingCa)
+		}
+		issuer, err := newOcspIssuer(issuingCa, caCert, caKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "resource/revocation:NewRevocationController() Could not load OCSP issuer")
+		}
+		controller.ocspIssuers = append(controller.ocspIssuers, *issuer)
+	}
+	return controller, nil
+}
+
+func newOcspIssuer(issuingCa string, caCert *x509.Certificate, caKey interface{}) (*ocspIssuer, error) {
+	var publicKeyInfo struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(caCert.RawSubjectPublicKeyInfo, &publicKeyInfo); err != nil {
+		return nil, errors.Wrapf(err, "Could not parse public key of %s CA", issuingCa)
+	}
+	signer, ok := caKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.Errorf("Key of %s CA does not support signing", issuingCa)
+	}
+	return &ocspIssuer{
+		issuingCa: issuingCa,
+		cert:      caCert,
+		key:       signer,
+		publicKey: publicKeyInfo.PublicKey.RightAlign(),
+	}, nil
+}
+
+// SearchCertificates is used to list the certificates issued by CMS
+func (controller RevocationController) SearchCertificates() http.Handler {
+	log.Trace("resource/revocation:SearchCertificates() Entering")
+	defer log.Trace("resource/revocation:SearchCertificates() Leaving")
+
+	return errorHandlerFunc(func(httpWriter http.ResponseWriter, httpRequest *http.Request) error {
+		if err := authorizeCertManager(httpRequest); err != nil {
+			return err
+		}
+
+		query := httpRequest.URL.Query()
+		criteria := directory.IssuedCertificateFilterCriteria{
+			IssuingCa: query.Get("issuingCa"),
+			CertType:  query.Get("certType"),
+		}
+		if err := v.ValidateStrings([]string{criteria.IssuingCa, criteria.CertType}); err != nil {
+			slog.Warning(commLogMsg.InvalidInputBadParam)
+			return resourceError{Message: "Invalid issuingCa or certType query parameter provided", StatusCode: http.StatusBadRequest}
+		}
+		if revokedParam := query.Get("revoked"); revokedParam != "" {
+			revoked, err := strconv.ParseBool(revokedParam)
+			if err != nil {
+				slog.Warning(commLogMsg.InvalidInputBadParam)
+				return resourceError{Message: "Invalid revoked query parameter provided", StatusCode: http.StatusBadRequest}
+			}
+			criteria.Revoked = &revoked
+		}
+
+		certs, err := controller.Store.Search(&criteria)
+		if err != nil {
+			log.WithError(err).Error("resource/revocation:SearchCertificates() Failed to search issued certificates")
+			return resourceError{Message: "Failed to search issued certificates", StatusCode: http.StatusInternalServerError}
+		}
+		return writeJson(httpWriter, http.StatusOK, certs)
+	})
+}
+
+// RevokeCertificate is used to revoke a certificate issued by CMS, the CRL of the issuing CA is regenerated right away
+func (controller RevocationController) RevokeCertificate() http.Handler {
+	log.Trace("resource/revocation:RevokeCertificate() Entering")
+	defer log.Trace("resource/revocation:RevokeCertificate() Leaving")
+
+	return errorHandlerFunc(func(httpWriter http.ResponseWriter, httpRequest *http.Request) error {
+		if err := authorizeCertManager(httpRequest); err != nil {
+			return err
+		}
+
+		serialNumber := mux.Vars(httpRequest)["serialNumber"]
+		if _, ok := new(big.Int).SetString(serialNumber, 16); !ok {
+			slog.Warning(commLogMsg.InvalidInputBadParam)
+			return resourceError{Message: "Invalid serial number provided", StatusCode: http.StatusBadRequest}
+		}
+
+		var revokeRequest cms.RevokeCertificateRequest
+		if httpRequest.ContentLength != 0 {
+			if httpRequest.Header.Get("Content-Type") != "application/json" {
+				return resourceError{Message: "Content type not supported", StatusCode: http.StatusUnsupportedMediaType}
+			}
+			dec := json.NewDecoder(httpRequest.Body)
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&revokeRequest); err != nil {
+				slog.Warning(commLogMsg.InvalidInputBadParam)
+				log.WithError(err).Error("resource/revocation:RevokeCertificate() Unable to decode request body")
+				return resourceError{Message: "Unable to decode JSON request body", StatusCode: http.StatusBadRequest}
+			}
+		}
+		reason := strings.ToLower(revokeRequest.Reason)
+		if reason == "" {
+			reason = cms.RevocationReasonUnspecified
+		}
+		if _, ok := utils.RevocationReasonCode(reason); !ok {
+			slog.Warning(commLogMsg.InvalidInputBadParam)
+			return resourceError{Message: "Invalid revocation reason provided", StatusCode: http.StatusBadRequest}
+		}
+
+		cert, err := controller.Store.Revoke(serialNumber, reason)
+		if err != nil {
+			if err.Error() == commErr.RecordNotFound {
+				return resourceError{Message: "Certificate with specified serial number was not issued by CMS", StatusCode: http.StatusNotFound}
+			}
+			log.WithError(err).Error("resource/revocation:RevokeCertificate() Failed to revoke certificate")
+			return resourceError{Message: "Failed to revoke certificate", StatusCode: http.StatusInternalServerError}
+		}
+		slog.Infof("resource/revocation:RevokeCertificate() Certificate %s with subject %s revoked, reason - %s",
+			cert.SerialNumber, cert.Subject, cert.RevocationReason)
+
+		// the revocation is already visible to the OCSP responder, a failure here is caught up by the next refresh
+		if err := utils.GenerateCrl(controller.Store, cert.IssuingCa, controller.Config.Revocation.CrlValidity); err != nil {
+			log.WithError(err).Errorf("resource/revocation:RevokeCertificate() Failed to regenerate CRL of %s CA", cert.IssuingCa)
+		}
+		return writeJson(httpWriter, http.StatusOK, cert)
+	})
+}
+
+// GetCrl is used to get the DER encoded CRL of an issuing CA
+func (controller RevocationController) GetCrl() http.Handler {
+	log.Trace("resource/revocation:GetCrl() Entering")
+	defer log.Trace("resource/revocation:GetCrl() Leaving")
+
+	return errorHandlerFunc(func(httpWriter http.ResponseWriter, httpRequest *http.Request) error {
+		issuingCa := httpRequest.URL.Query().Get("issuingCa")
+		caAttr := constants.GetCaAttribs(issuingCa)
+		if caAttr.CrlPath == "" {
+			slog.Warning(commLogMsg.InvalidInputBadParam)
+			return resourceError{Message: "Invalid Query parameter issuingCa provided", StatusCode: http.StatusBadRequest}
+		}
+
+		crl, err := ioutil.ReadFile(caAttr.CrlPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return resourceError{Message: "CRL is not available", StatusCode: http.StatusNotFound}
+			}
+			log.WithError(err).Errorf("resource/revocation:GetCrl() Cannot read CRL of %s CA", issuingCa)
+			return resourceError{Message: "Cannot read CRL", StatusCode: http.StatusInternalServerError}
+		}
+		httpWriter.Header().Set("Content-Type", "application/pkix-crl")
+		httpWriter.WriteHeader(http.StatusOK)
+		_, err = httpWriter.Write(crl)
+		if err != nil {
+			log.WithError(err).Errorf("resource/revocation:GetCrl() Failed to write response")
+		}
+		return nil
+	})
+}
+
+// Ocsp is the OCSP responder of the intermediate CAs, requests are accepted either as POST body or as GET path
+// parameter as defined by RFC 6960 Appendix A
+func (controller RevocationController) Ocsp() http.Handler {
+	log.Trace("resource/revocation:Ocsp() Entering")
+	defer log.Trace("resource/revocation:Ocsp() Leaving")
+
+	return errorHandlerFunc(func(httpWriter http.ResponseWriter, httpRequest *http.Request) error {
+		var requestBytes []byte
+		var err error
+		if httpRequest.Method == http.MethodGet {
+			var encoded string
+			encoded, err = url.PathUnescape(mux.Vars(httpRequest)["request"])
+			if err == nil {
+				requestBytes, err = base64.StdEncoding.DecodeString(encoded)
+			}
+		} else {
+			if httpRequest.Header.Get("Content-Type") != "application/ocsp-request" {
+				return resourceError{Message: "Content type not supported", StatusCode: http.StatusUnsupportedMediaType}
+			}
+			requestBytes, err = ioutil.ReadAll(http.MaxBytesReader(httpWriter, httpRequest.Body, maxOcspRequestBytes))
+		}
+		if err != nil {
+			slog.Warning(commLogMsg.InvalidInputBadEncoding)
+			return writeOcspResponse(httpWriter, ocsp.MalformedRequestErrorResponse)
+		}
+
+		ocspRequest, err := ocsp.ParseRequest(requestBytes)
+		if err != nil {
+			slog.Warning(commLogMsg.InvalidInputBadEncoding)
+			log.WithError(err).Debug("resource/revocation:Ocsp() Invalid OCSP request")
+			return writeOcspResponse(httpWriter, ocsp.MalformedRequestErrorResponse)
+		}
+
+		issuer := controller.findOcspIssuer(ocspRequest)
+		if issuer == nil {
+			// the request is about a certificate that was not issued by any of the intermediate CAs of CMS
+			return writeOcspResponse(httpWriter, ocsp.UnauthorizedErrorResponse)
+		}
+
+		now := time.Now().UTC()
+		template := ocsp.Response{
+			Status:       ocsp.Unknown,
+			SerialNumber: ocspRequest.SerialNumber,
+			ThisUpdate:   now,
+			NextUpdate:   now.Add(constants.DefaultOcspResponseValidity),
+		}
+		cert, err := controller.Store.Retrieve(directory.SerialNumberString(ocspRequest.SerialNumber))
+		if err != nil && err.Error() != commErr.RecordNotFound {
+			log.WithError(err).Error("resource/revocation:Ocsp() Failed to retrieve issued certificate")
+			return writeOcspResponse(httpWriter, ocsp.InternalErrorErrorResponse)
+		}
+		if cert != nil && strings.EqualFold(cert.IssuingCa, issuer.issuingCa) {
+			if cert.Revoked {
+				template.Status = ocsp.Revoked
+				template.RevocationReason, _ = utils.RevocationReasonCode(cert.RevocationReason)
+				if cert.RevokedAt != nil {
+					template.RevokedAt = *cert.RevokedAt
+				}
+			} else {
+				template.Status = ocsp.Good
+			}
+		}
+
+		response, err := ocsp.CreateResponse(issuer.cert, issuer.cert, template, issuer.key)
+		if err != nil {
+			log.WithError(err).Error("resource/revocation:Ocsp() Failed to create OCSP response")
+			return writeOcspResponse(httpWriter, ocsp.InternalErrorErrorResponse)
+		}
+		return writeOcspResponse(httpWriter, response)
+	})
+}
+
+// findOcspIssuer returns the intermediate CA matching the issuer name and key hashes of the OCSP request, nil is
+// returned when none of them matches
+func (controller RevocationController) findOcspIssuer(ocspRequest *ocsp.Request) *ocspIssuer {
+	if !ocspRequest.HashAlgorithm.Available() {
+		return nil
+	}
+	for i := range controller.ocspIssuers {
+		issuer := &controller.ocspIssuers[i]
+		nameHash := ocspRequest.HashAlgorithm.New()
+		nameHash.Write(issuer.cert.RawSubject)
+		keyHash := ocspRequest.HashAlgorithm.New()
+		keyHash.Write(issuer.publicKey)
+		if bytes.Equal(nameHash.Sum(nil), ocspRequest.IssuerNameHash) &&
+			bytes.Equal(keyHash.Sum(nil), ocspRequest.IssuerKeyHash) {
+			return issuer
+		}
+	}
+	return nil
+}
+
+func authorizeCertManager(httpRequest *http.Request) error {
+	privileges, err := context.GetUserRoles(httpRequest)
+	if err != nil {
+		slog.WithError(err).Warn("resource/revocation:authorizeCertManager() Failed to read roles and permissions")
+		return resourceError{Message: "Could not get user roles from http context", StatusCode: http.StatusInternalServerError}
+	}
+	_, foundRole := auth.ValidatePermissionAndGetRoleContext(privileges,
+		[]ct.RoleInfo{{Service: constants.ServiceName, Name: constants.CertManagerGroupName}},
+		true)
+	if !foundRole {
+		slog.Warning(commLogMsg.UnauthorizedAccess)
+		return privilegeError{Message: "Unauthorized", StatusCode: http.StatusUnauthorized}
+	}
+	return nil
+}
+
+func writeJson(httpWriter http.ResponseWriter, status int, body interface{}) error {
+	response, err := json.Marshal(body)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal response body")
+	}
+	httpWriter.Header().Set("Content-Type", "application/json")
+	httpWriter.WriteHeader(status)
+	_, err = httpWriter.Write(response)
+	if err != nil {
+		log.WithError(err).Error("Failed to write response")
+	}
+	return nil
+}
+
+func writeOcspResponse(httpWriter http.ResponseWriter, response []byte) error {
+	httpWriter.Header().Set("Content-Type", "application/ocsp-response")
+	httpWriter.WriteHeader(http.StatusOK)
+	_, err := httpWriter.Write(response)
+	if err != nil {
+		log.WithError(err).Error("Failed to write OCSP response")
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/config"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/directory"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/context"
+	ct "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/cms"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ocsp"
+)
+
+// newTestCertificate returns a certificate signed by the parent, or a self signed CA certificate when parent is nil
+func newTestCertificate(t *testing.T, commonName string, serialNumber int64, parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serialNumber),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	if parent == nil {
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+		template.BasicConstraintsValid = true
+		template.IsCA = true
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return cert, key
+}
+
+func newTestRevocationController(t *testing.T) *RevocationController {
+	store := directory.NewIssuedCertificateStore(t.TempDir())
+	now := time.Now().UTC()
+	for _, serialNumber := range []string{"1a", "2b"} {
+		_, err := store.Create(&cms.IssuedCertificate{
+			SerialNumber: serialNumber,
+			Subject:      "CN=HVS TLS Certificate",
+			CertType:     constants.Tls,
+			IssuingCa:    constants.Tls,
+			NotBefore:    now.Add(-time.Hour),
+			NotAfter:     now.Add(time.Hour),
+		})
+		assert.NoError(t, err)
+	}
+	return &RevocationController{
+		Config: &config.Configuration{Revocation: config.RevocationConfig{
+			CrlValidity:        constants.DefaultCrlValidity,
+			CrlRefreshInterval: constants.DefaultCrlRefreshInterval,
+		}},
+		Store: store,
+	}
+}
+
+func TestRevokeCertificate(t *testing.T) {
+	certManager := []ct.RoleInfo{{Service: constants.ServiceName, Name: constants.CertManagerGroupName}}
+
+	tests := []struct {
+		name           string
+		serialNumber   string
+		body           string
+		roles          []ct.RoleInfo
+		expectedStatus int
+		expectedReason string
+	}{
+		{
+			name:           "revoke without reason",
+			serialNumber:   "1a",
+			roles:          certManager,
+			expectedStatus: http.StatusOK,
+			expectedReason: cms.RevocationReasonUnspecified,
+		},
+		{
+			name:           "revoke with reason",
+			serialNumber:   "2b",
+			body:           `{"reason":"key_compromise"}`,
+			roles:          certManager,
+			expectedStatus: http.StatusOK,
+			expectedReason: cms.RevocationReasonKeyCompromise,
+		},
+		{
+			name:           "unknown serial number",
+			serialNumber:   "3c",
+			roles:          certManager,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid serial number",
+			serialNumber:   "not-a-serial",
+			roles:          certManager,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid reason",
+			serialNumber:   "1a",
+			body:           `{"reason":"remove_from_crl"}`,
+			roles:          certManager,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "not a certificate manager",
+			serialNumber:   "1a",
+			roles:          []ct.RoleInfo{{Service: constants.ServiceName, Name: constants.CertApproverGroupName}},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := newTestRevocationController(t)
+			req := httptest.NewRequest(http.MethodPost, "/cms/v1/certificates/"+tt.serialNumber+"/revoke", strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			req = mux.SetURLVars(req, map[string]string{"serialNumber": tt.serialNumber})
+			req = context.SetUserRoles(req, tt.roles)
+			rr := httptest.NewRecorder()
+			controller.RevokeCertificate().ServeHTTP(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var cert cms.IssuedCertificate
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &cert))
+			assert.True(t, cert.Revoked)
+			assert.Equal(t, tt.expectedReason, cert.RevocationReason)
+			stored, err := controller.Store.Retrieve(tt.serialNumber)
+			assert.NoError(t, err)
+			assert.True(t, stored.Revoked)
+		})
+	}
+}
+
+func TestOcsp(t *testing.T) {
+	caCert, caKey := newTestCertificate(t, "CMS TLS CA", 1, nil, nil)
+	otherCaCert, otherCaKey := newTestCertificate(t, "Other CA", 1, nil, nil)
+
+	controller := newTestRevocationController(t)
+	issuer, err := newOcspIssuer(constants.Tls, caCert, caKey)
+	assert.NoError(t, err)
+	controller.ocspIssuers = []ocspIssuer{*issuer}
+	_, err = controller.Store.Revoke("2b", cms.RevocationReasonKeyCompromise)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		serialNumber     int64
+		issuerCert       *x509.Certificate
+		issuerKey        *ecdsa.PrivateKey
+		expectedStatus   int
+		unauthorizedCert bool
+	}{
+		{name: "good", serialNumber: 0x1a, issuerCert: caCert, issuerKey: caKey, expectedStatus: ocsp.Good},
+		{name: "revoked", serialNumber: 0x2b, issuerCert: caCert, issuerKey: caKey, expectedStatus: ocsp.Revoked},
+		{name: "unknown serial number", serialNumber: 0x3c, issuerCert: caCert, issuerKey: caKey, expectedStatus: ocsp.Unknown},
+		{name: "not issued by CMS", serialNumber: 0x1a, issuerCert: otherCaCert, issuerKey: otherCaKey, unauthorizedCert: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert, _ := newTestCertificate(t, "HVS TLS Certificate", tt.serialNumber, tt.issuerCert, tt.issuerKey)
+			ocspRequest, err := ocsp.CreateRequest(cert, tt.issuerCert, nil)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/cms/v1/ocsp", bytes.NewReader(ocspRequest))
+			req.Header.Set("Content-Type", "application/ocsp-request")
+			rr := httptest.NewRecorder()
+			controller.Ocsp().ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusOK, rr.Code)
+
+			response, err := ocsp.ParseResponseForCert(rr.Body.Bytes(), cert, caCert)
+			if tt.unauthorizedCert {
+				assert.Equal(t, ocsp.ResponseError{Status: ocsp.Unauthorized}, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, response.Status)
+			if tt.expectedStatus == ocsp.Revoked {
+				assert.Equal(t, ocsp.KeyCompromise, response.RevocationReason)
+			}
+		})
+	}
+
+	malformed := httptest.NewRequest(http.MethodPost, "/cms/v1/ocsp", strings.NewReader("not an OCSP request"))
+	malformed.Header.Set("Content-Type", "application/ocsp-request")
+	rr := httptest.NewRecorder()
+	controller.Ocsp().ServeHTTP(rr, malformed)
+	_, err = ocsp.ParseResponse(rr.Body.Bytes(), caCert)
+	assert.Equal(t, ocsp.ResponseError{Status: ocsp.Malformed}, err)
+}
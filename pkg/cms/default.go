@@ -44,6 +44,9 @@ func init() {
 	viper.SetDefault("aas-tls-san", constants.DefaultTlsSan)
 
 	viper.SetDefault("token-duration-mins", constants.DefaultTokenDurationMins)
+
+	viper.SetDefault("revocation-crl-validity", constants.DefaultCrlValidity)
+	viper.SetDefault("revocation-crl-refresh-interval", constants.DefaultCrlRefreshInterval)
 }
 
 func defaultConfig() *config.Configuration {
@@ -67,6 +70,11 @@ func defaultConfig() *config.Configuration {
 		AasTlsSan:         viper.GetString("aas-tls-san"),
 		TlsSanList:        viper.GetString("san-list"),
 		TokenDurationMins: viper.GetInt("token-duration-mins"),
+		Revocation: config.RevocationConfig{
+			BaseUrl:            viper.GetString("revocation-base-url"),
+			CrlValidity:        viper.GetDuration("revocation-crl-validity"),
+			CrlRefreshInterval: viper.GetDuration("revocation-crl-refresh-interval"),
+		},
 	}
 }
 
@@ -0,0 +1,170 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package directory
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/cms"
+	"github.com/pkg/errors"
+)
+
+var defaultLog = log.GetDefaultLogger()
+
+// IssuedCertificateFilterCriteria narrows down the issued certificates returned by Search
+type IssuedCertificateFilterCriteria struct {
+	IssuingCa string
+	CertType  string
+	Revoked   *bool
+}
+
+// IssuedCertificateStore keeps one file per issued certificate, named after its hexadecimal serial number
+type IssuedCertificateStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewIssuedCertificateStore(dir string) *IssuedCertificateStore {
+	return &IssuedCertificateStore{dir: dir}
+}
+
+// SerialNumberString returns the representation of a serial number used to identify the issued certificates
+func SerialNumberString(serialNumber *big.Int) string {
+	return serialNumber.Text(16)
+}
+
+func (ics *IssuedCertificateStore) Create(cert *cms.IssuedCertificate) (*cms.IssuedCertificate, error) {
+	defaultLog.Trace("directory/issued_certificate_store:Create() Entering")
+	defer defaultLog.Trace("directory/issued_certificate_store:Create() Leaving")
+
+	ics.mu.Lock()
+	defer ics.mu.Unlock()
+
+	if err := ics.write(cert); err != nil {
+		return nil, errors.Wrap(err, "directory/issued_certificate_store:Create() Failed to store issued certificate")
+	}
+	return cert, nil
+}
+
+func (ics *IssuedCertificateStore) Retrieve(serialNumber string) (*cms.IssuedCertificate, error) {
+	defaultLog.Trace("directory/issued_certificate_store:Retrieve() Entering")
+	defer defaultLog.Trace("directory/issued_certificate_store:Retrieve() Leaving")
+
+	path, err := ics.path(serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	return readIssuedCertificate(path)
+}
+
+// Revoke marks the issued certificate as revoked, the record is returned unchanged when it is already revoked
+func (ics *IssuedCertificateStore) Revoke(serialNumber, reason string) (*cms.IssuedCertificate, error) {
+	defaultLog.Trace("directory/issued_certificate_store:Revoke() Entering")
+	defer defaultLog.Trace("directory/issued_certificate_store:Revoke() Leaving")
+
+	ics.mu.Lock()
+	defer ics.mu.Unlock()
+
+	cert, err := ics.Retrieve(serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	if cert.Revoked {
+		return cert, nil
+	}
+
+	now := time.Now().UTC()
+	cert.Revoked = true
+	cert.RevokedAt = &now
+	cert.RevocationReason = reason
+	if err := ics.write(cert); err != nil {
+		return nil, errors.Wrapf(err, "directory/issued_certificate_store:Revoke() Failed to revoke certificate : %s", serialNumber)
+	}
+	return cert, nil
+}
+
+func (ics *IssuedCertificateStore) Search(criteria *IssuedCertificateFilterCriteria) ([]cms.IssuedCertificate, error) {
+	defaultLog.Trace("directory/issued_certificate_store:Search() Entering")
+	defer defaultLog.Trace("directory/issued_certificate_store:Search() Leaving")
+
+	files, err := ioutil.ReadDir(ics.dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/issued_certificate_store:Search() Unable to read the issued certificates directory")
+	}
+
+	certs := []cms.IssuedCertificate{}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		cert, err := readIssuedCertificate(filepath.Join(ics.dir, file.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "directory/issued_certificate_store:Search() Failed to read issued certificate : %s", file.Name())
+		}
+		if criteria != nil {
+			if criteria.IssuingCa != "" && !strings.EqualFold(cert.IssuingCa, criteria.IssuingCa) {
+				continue
+			}
+			if criteria.CertType != "" && !strings.EqualFold(cert.CertType, criteria.CertType) {
+				continue
+			}
+			if criteria.Revoked != nil && cert.Revoked != *criteria.Revoked {
+				continue
+			}
+		}
+		certs = append(certs, *cert)
+	}
+
+	sort.Slice(certs, func(i, j int) bool {
+		return certs[i].NotBefore.Before(certs[j].NotBefore)
+	})
+	return certs, nil
+}
+
+func (ics *IssuedCertificateStore) write(cert *cms.IssuedCertificate) error {
+	path, err := ics.path(cert.SerialNumber)
+	if err != nil {
+		return err
+	}
+	bytes, err := json.Marshal(cert)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal issued certificate")
+	}
+	return ioutil.WriteFile(path, bytes, 0600)
+}
+
+// path validates the serial number before using it as a file name
+func (ics *IssuedCertificateStore) path(serialNumber string) (string, error) {
+	serial, ok := new(big.Int).SetString(serialNumber, 16)
+	if !ok || serial.Sign() < 0 {
+		return "", errors.New(commErr.RecordNotFound)
+	}
+	return filepath.Join(ics.dir, SerialNumberString(serial)), nil
+}
+
+func readIssuedCertificate(path string) (*cms.IssuedCertificate, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New(commErr.RecordNotFound)
+		}
+		return nil, errors.Wrap(err, "directory/issued_certificate_store:readIssuedCertificate() Unable to read issued certificate file")
+	}
+	var cert cms.IssuedCertificate
+	if err := json.Unmarshal(bytes, &cert); err != nil {
+		return nil, errors.Wrap(err, "directory/issued_certificate_store:readIssuedCertificate() Failed to unmarshal issued certificate")
+	}
+	return &cert, nil
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package directory
+
+import (
+	"testing"
+	"time"
+
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/cms"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestIssuedCertificateStore(t *testing.T) *IssuedCertificateStore {
+	store := NewIssuedCertificateStore(t.TempDir())
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, cert := range []cms.IssuedCertificate{
+		{SerialNumber: "1a", Subject: "CN=HVS TLS Certificate", CertType: "TLS", IssuingCa: "TLS", NotBefore: now.Add(-2 * time.Hour), NotAfter: now.Add(time.Hour)},
+		{SerialNumber: "2b", Subject: "CN=Flavor Signing Certificate", CertType: "Signing", IssuingCa: "Signing", NotBefore: now.Add(-time.Hour), NotAfter: now.Add(time.Hour)},
+		{SerialNumber: "3c", Subject: "CN=WLA TLS Client Certificate", CertType: "TLS-Client", IssuingCa: "TLS-Client", NotBefore: now, NotAfter: now.Add(time.Hour)},
+	} {
+		cert := cert
+		_, err := store.Create(&cert)
+		assert.NoError(t, err)
+	}
+	return store
+}
+
+func TestIssuedCertificateStoreRetrieve(t *testing.T) {
+	store := newTestIssuedCertificateStore(t)
+
+	tests := []struct {
+		name         string
+		serialNumber string
+		expectError  bool
+	}{
+		{name: "issued certificate", serialNumber: "1a"},
+		{name: "upper case serial number", serialNumber: "1A"},
+		{name: "unknown serial number", serialNumber: "4d", expectError: true},
+		{name: "invalid serial number", serialNumber: "../tls-cert.pem", expectError: true},
+		{name: "negative serial number", serialNumber: "-1a", expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert, err := store.Retrieve(tt.serialNumber)
+			if tt.expectError {
+				assert.EqualError(t, err, commErr.RecordNotFound)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "1a", cert.SerialNumber)
+			assert.False(t, cert.Revoked)
+		})
+	}
+}
+
+func TestIssuedCertificateStoreRevoke(t *testing.T) {
+	store := newTestIssuedCertificateStore(t)
+
+	cert, err := store.Revoke("2b", cms.RevocationReasonKeyCompromise)
+	assert.NoError(t, err)
+	assert.True(t, cert.Revoked)
+	assert.NotNil(t, cert.RevokedAt)
+	assert.Equal(t, cms.RevocationReasonKeyCompromise, cert.RevocationReason)
+
+	stored, err := store.Retrieve("2b")
+	assert.NoError(t, err)
+	assert.True(t, stored.Revoked)
+	assert.True(t, cert.RevokedAt.Equal(*stored.RevokedAt))
+
+	// revoking again keeps the original revocation
+	revokedAgain, err := store.Revoke("2b", cms.RevocationReasonSuperseded)
+	assert.NoError(t, err)
+	assert.True(t, cert.RevokedAt.Equal(*revokedAgain.RevokedAt))
+	assert.Equal(t, cms.RevocationReasonKeyCompromise, revokedAgain.RevocationReason)
+
+	_, err = store.Revoke("4d", cms.RevocationReasonUnspecified)
+	assert.EqualError(t, err, commErr.RecordNotFound)
+}
+
+func TestIssuedCertificateStoreSearch(t *testing.T) {
+	store := newTestIssuedCertificateStore(t)
+	_, err := store.Revoke("3c", cms.RevocationReasonUnspecified)
+	assert.NoError(t, err)
+
+	revoked, notRevoked := true, false
+	tests := []struct {
+		name     string
+		criteria *IssuedCertificateFilterCriteria
+		expected []string
+	}{
+		{name: "all certificates", expected: []string{"1a", "2b", "3c"}},
+		{name: "issuing CA", criteria: &IssuedCertificateFilterCriteria{IssuingCa: "signing"}, expected: []string{"2b"}},
+		{name: "certificate type", criteria: &IssuedCertificateFilterCriteria{CertType: "TLS"}, expected: []string{"1a"}},
+		{name: "revoked", criteria: &IssuedCertificateFilterCriteria{Revoked: &revoked}, expected: []string{"3c"}},
+		{name: "not revoked", criteria: &IssuedCertificateFilterCriteria{Revoked: &notRevoked}, expected: []string{"1a", "2b"}},
+		{name: "no match", criteria: &IssuedCertificateFilterCriteria{IssuingCa: "TLS", Revoked: &revoked}, expected: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certs, err := store.Search(tt.criteria)
+			assert.NoError(t, err)
+			serialNumbers := []string{}
+			for _, cert := range certs {
+				serialNumbers = append(serialNumbers, cert.SerialNumber)
+			}
+			assert.Equal(t, tt.expected, serialNumbers)
+		})
+	}
+}
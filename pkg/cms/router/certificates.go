@@ -9,15 +9,19 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/intel-secl/intel-secl/v4/pkg/cms/config"
 	"github.com/intel-secl/intel-secl/v4/pkg/cms/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/directory"
 	log "github.com/sirupsen/logrus"
 )
 
 // SetCertificatesRoutes is used to set the endpoints for certificate handling APIs
-func SetCertificatesRoutes(router *mux.Router, config *config.Configuration) *mux.Router {
+func SetCertificatesRoutes(router *mux.Router, config *config.Configuration, store *directory.IssuedCertificateStore,
+	revocationController *controllers.RevocationController) *mux.Router {
 	log.Trace("router/certificates:SetCertificatesRoutes() Entering")
 	defer log.Trace("router/certificates:SetCertificatesRoutes() Leaving")
 
-	certController := controllers.CertificatesController{Config: config}
+	certController := controllers.CertificatesController{Config: config, Store: store}
 	router.HandleFunc("/certificates", certController.GetCertificates).Methods("POST")
+	router.Handle("/certificates", revocationController.SearchCertificates()).Methods("GET")
+	router.Handle("/certificates/{serialNumber}/revoke", revocationController.RevokeCertificate()).Methods("POST")
 	return router
 }
@@ -0,0 +1,38 @@
+/*
+ *  Copyright (C) 2021 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package router
+
+import (
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/config"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/directory"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/middleware"
+)
+
+// SetEstRoutes is used to set the RFC 7030 endpoints, the optional label selects the certificate type. Simple
+// enrollment is authorized by a token, re-enrollment by the TLS client certificate being renewed.
+func SetEstRoutes(router *mux.Router, config *config.Configuration, store *directory.IssuedCertificateStore) *mux.Router {
+	defaultLog.Trace("router/est:SetEstRoutes() Entering")
+	defer defaultLog.Trace("router/est:SetEstRoutes() Leaving")
+
+	estController := controllers.EstController{Config: config, Store: store}
+	for _, prefix := range []string{constants.EstPathPrefix, constants.EstPathPrefix + "/{label}"} {
+		publicRouter := router.PathPrefix(prefix).Subrouter()
+		publicRouter.Handle("/cacerts", estController.CaCerts()).Methods("GET")
+		publicRouter.Handle("/simplereenroll", estController.SimpleReenroll()).Methods("POST")
+
+		tokenRouter := router.PathPrefix(prefix).Subrouter()
+		cfgRouter := Router{cfg: config}
+		tokenRouter.Use(middleware.NewTokenAuthWithRevocationList(constants.TrustedJWTSigningCertsDir, constants.ConfigDir, cfgRouter.fnGetJwtCerts,
+			time.Minute*constants.DefaultJwtValidateCacheKeyMins, cfgRouter.fnGetTokenRevocationList, middleware.DefaultRevocationListCacheTime))
+		tokenRouter.Handle("/simpleenroll", estController.SimpleEnroll()).Methods("POST")
+	}
+	return router
+}
@@ -0,0 +1,22 @@
+/*
+ *  Copyright (C) 2021 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package router
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/controllers"
+)
+
+// SetRevocationRoutes is used to set the public endpoints of the CRL and OCSP services
+func SetRevocationRoutes(router *mux.Router, revocationController *controllers.RevocationController) *mux.Router {
+	defaultLog.Trace("router/revocation:SetRevocationRoutes() Entering")
+	defer defaultLog.Trace("router/revocation:SetRevocationRoutes() Leaving")
+
+	router.Handle("/crl", revocationController.GetCrl()).Methods("GET")
+	router.Handle("/ocsp", revocationController.Ocsp()).Methods("POST")
+	router.Handle("/ocsp/{request:.+}", revocationController.Ocsp()).Methods("GET")
+	return router
+}
@@ -8,12 +8,17 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/clients"
+	"github.com/intel-secl/intel-secl/v4/pkg/clients/aas"
 	"github.com/intel-secl/intel-secl/v4/pkg/cms/config"
 	"github.com/intel-secl/intel-secl/v4/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/directory"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/middleware"
 	cos "github.com/intel-secl/intel-secl/v4/pkg/lib/common/os"
+	aasModel "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
 	"github.com/pkg/errors"
 	"io/ioutil"
 	"net/http"
@@ -28,7 +33,7 @@ type Router struct {
 }
 
 // InitRoutes registers all routes for the application.
-func InitRoutes(cfg *config.Configuration) *mux.Router {
+func InitRoutes(cfg *config.Configuration, store *directory.IssuedCertificateStore, revocationController *controllers.RevocationController) *mux.Router {
 	defaultLog.Trace("router/router:InitRoutes() Entering")
 	defer defaultLog.Trace("router/router:InitRoutes() Leaving")
 
@@ -36,11 +41,13 @@ func InitRoutes(cfg *config.Configuration) *mux.Router {
 	router := mux.NewRouter()
 
 	router.SkipClean(true)
-	defineSubRoutes(router, strings.ToLower(constants.ServiceName), cfg)
+	defineSubRoutes(router, strings.ToLower(constants.ServiceName), cfg, store, revocationController)
+	SetEstRoutes(router, cfg, store)
 	return router
 }
 
-func defineSubRoutes(router *mux.Router, service string, cfg *config.Configuration) {
+func defineSubRoutes(router *mux.Router, service string, cfg *config.Configuration, store *directory.IssuedCertificateStore,
+	revocationController *controllers.RevocationController) {
 	defaultLog.Trace("router/router:defineSubRoutes() Entering")
 	defer defaultLog.Trace("router/router:defineSubRoutes() Leaving")
 
@@ -48,12 +55,13 @@ func defineSubRoutes(router *mux.Router, service string, cfg *config.Configurati
 	subRouter := router.PathPrefix(serviceApi).Subrouter()
 	subRouter = SetVersionRoutes(subRouter)
 	subRouter = SetCACertificatesRoutes(subRouter)
+	subRouter = SetRevocationRoutes(subRouter, revocationController)
 
 	subRouter = router.PathPrefix(serviceApi).Subrouter()
 	cfgRouter := Router{cfg: cfg}
-	subRouter.Use(middleware.NewTokenAuth(constants.TrustedJWTSigningCertsDir, constants.ConfigDir, cfgRouter.fnGetJwtCerts,
-		time.Minute*constants.DefaultJwtValidateCacheKeyMins))
-	subRouter = SetCertificatesRoutes(subRouter, cfg)
+	subRouter.Use(middleware.NewTokenAuthWithRevocationList(constants.TrustedJWTSigningCertsDir, constants.ConfigDir, cfgRouter.fnGetJwtCerts,
+		time.Minute*constants.DefaultJwtValidateCacheKeyMins, cfgRouter.fnGetTokenRevocationList, middleware.DefaultRevocationListCacheTime))
+	subRouter = SetCertificatesRoutes(subRouter, cfg, store, revocationController)
 }
 
 // Fetch JWT certificate from AAS
@@ -121,3 +129,29 @@ func (r *Router) fnGetJwtCerts() error {
 	}
 	return nil
 }
+
+// Fetch token revocation list from AAS
+func (r *Router) fnGetTokenRevocationList() (*aasModel.TokenRevocationList, error) {
+	defaultLog.Trace("router/router:fnGetTokenRevocationList() Entering")
+	defer defaultLog.Trace("router/router:fnGetTokenRevocationList() Leaving")
+
+	caCerts, err := crypt.GetCertsFromDir(constants.RootCADirPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "router/router:fnGetTokenRevocationList() Could not read root CA certificates")
+	}
+	httpClient, err := clients.HTTPClientWithCA(caCerts)
+	if err != nil {
+		return nil, errors.Wrap(err, "router/router:fnGetTokenRevocationList() Could not create http client")
+	}
+	// a hung AAS must not hold up the refresh of the revocation list
+	httpClient.Timeout = middleware.DefaultRevocationListRetrieveTimeout
+	aasClient := aas.Client{
+		BaseURL:    r.cfg.AASApiUrl,
+		HTTPClient: httpClient,
+	}
+	revocationList, err := aasClient.GetTokenRevocationList()
+	if err != nil {
+		return nil, errors.Wrap(err, "router/router:fnGetTokenRevocationList() Could not retrieve token revocation list")
+	}
+	return revocationList, nil
+}
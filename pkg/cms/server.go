@@ -8,7 +8,11 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/config"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/directory"
 	"github.com/intel-secl/intel-secl/v4/pkg/cms/router"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/utils"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/message"
 	"net/http"
@@ -42,11 +46,27 @@ func (a *App) startServer() error {
 		return err
 	}
 
+	// the revocation directories and settings are missing on installations upgraded from an earlier release
+	for _, dir := range []string{constants.IssuedCertsDirPath, constants.CrlDirPath} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return errors.Wrapf(err, "app:startServer() Failed to create directory %s", dir)
+		}
+	}
+	setRevocationDefaults(&c.Revocation)
+
+	issuedCertStore := directory.NewIssuedCertificateStore(constants.IssuedCertsDirPath)
+	revocationController, err := controllers.NewRevocationController(c, issuedCertStore)
+	if err != nil {
+		return errors.Wrap(err, "app:startServer() Failed to initialize revocation controller")
+	}
+
 	// Initialize routes
-	routes := router.InitRoutes(c)
+	routes := router.InitRoutes(c, issuedCertStore, revocationController)
 
 	tlsconfig := &tls.Config{
 		MinVersion: tls.VersionTLS13,
+		// the client certificate is only used by EST re-enrollment, which verifies it against the CMS CA
+		ClientAuth: tls.RequestClientCert,
 		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
 			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
 			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
@@ -80,6 +100,11 @@ func (a *App) startServer() error {
 		}
 	}()
 
+	// the CRLs are generated before serving them and then refreshed ahead of their expiry
+	done := make(chan struct{})
+	defer close(done)
+	go refreshCrls(issuedCertStore, c.Revocation, done)
+
 	slog.Info(message.ServiceStart)
 	<-stop
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -91,6 +116,33 @@ func (a *App) startServer() error {
 	return nil
 }
 
+func setRevocationDefaults(revocationConfig *config.RevocationConfig) {
+	if revocationConfig.CrlRefreshInterval <= 0 {
+		revocationConfig.CrlRefreshInterval = constants.DefaultCrlRefreshInterval
+	}
+	if revocationConfig.CrlValidity < revocationConfig.CrlRefreshInterval {
+		revocationConfig.CrlValidity = constants.DefaultCrlValidity
+	}
+}
+
+func refreshCrls(store *directory.IssuedCertificateStore, revocationConfig config.RevocationConfig, done <-chan struct{}) {
+	defaultLog.Trace("app:refreshCrls() Entering")
+	defer defaultLog.Trace("app:refreshCrls() Leaving")
+
+	ticker := time.NewTicker(revocationConfig.CrlRefreshInterval)
+	defer ticker.Stop()
+	for {
+		if err := utils.GenerateCrls(store, revocationConfig.CrlValidity); err != nil {
+			defaultLog.WithError(err).Error("app:refreshCrls() Failed to generate CRLs")
+		}
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func (a *App) loadCertPathStore() *models.CertificatesPathStore {
 	return &models.CertificatesPathStore{
 		models.CaCertTypesRootCa.String(): models.CertLocation{
@@ -10,9 +10,11 @@ import (
 	"github.com/intel-secl/intel-secl/v4/pkg/cms/config"
 	commConfig "github.com/intel-secl/intel-secl/v4/pkg/lib/common/config"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/setup"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"io"
+	"strings"
 )
 
 type UpdateServiceConfig struct {
@@ -26,17 +28,20 @@ type UpdateServiceConfig struct {
 const envHelpPrompt = "Following environment variables are required for update-service-config setup:"
 
 var envHelp = map[string]string{
-	"LOG_LEVEL":                  "Log level",
-	"LOG_MAX_LENGTH":             "Max length of log statement",
-	"LOG_ENABLE_STDOUT":          "Enable console log",
-	"AAS_BASE_URL":               "AAS Base URL",
-	"TOKEN_DURATION_MINS":        "Validity of token duration",
-	"SERVER_PORT":                "The Port on which Server Listens to",
-	"SERVER_READ_TIMEOUT":        "Request Read Timeout Duration in Seconds",
-	"SERVER_READ_HEADER_TIMEOUT": "Request Read Header Timeout Duration in Seconds",
-	"SERVER_WRITE_TIMEOUT":       "Request Write Timeout Duration in Seconds",
-	"SERVER_IDLE_TIMEOUT":        "Request Idle Timeout in Seconds",
-	"SERVER_MAX_HEADER_BYTES":    "Max Length Of Request Header in Bytes",
+	"LOG_LEVEL":                       "Log level",
+	"LOG_MAX_LENGTH":                  "Max length of log statement",
+	"LOG_ENABLE_STDOUT":               "Enable console log",
+	"AAS_BASE_URL":                    "AAS Base URL",
+	"TOKEN_DURATION_MINS":             "Validity of token duration",
+	"SERVER_PORT":                     "The Port on which Server Listens to",
+	"SERVER_READ_TIMEOUT":             "Request Read Timeout Duration in Seconds",
+	"SERVER_READ_HEADER_TIMEOUT":      "Request Read Header Timeout Duration in Seconds",
+	"SERVER_WRITE_TIMEOUT":            "Request Write Timeout Duration in Seconds",
+	"SERVER_IDLE_TIMEOUT":             "Request Idle Timeout in Seconds",
+	"SERVER_MAX_HEADER_BYTES":         "Max Length Of Request Header in Bytes",
+	"REVOCATION_BASE_URL":             "CMS Base URL embedded in the issued certificates as CRL distribution point and OCSP server, e.g. https://<CMS IP>:<PORT>/cms/v1",
+	"REVOCATION_CRL_VALIDITY":         "Validity of the generated CRLs",
+	"REVOCATION_CRL_REFRESH_INTERVAL": "Interval at which the CRLs are regenerated",
 }
 
 func (uc UpdateServiceConfig) Run() error {
@@ -52,6 +57,24 @@ func (uc UpdateServiceConfig) Run() error {
 	(*uc.AppConfig).AASApiUrl = viper.GetString("aas-base-url")
 
 	(*uc.AppConfig).TokenDurationMins = viper.GetInt("token-duration-mins")
+
+	(*uc.AppConfig).Revocation = config.RevocationConfig{
+		BaseUrl:            strings.TrimSuffix(viper.GetString("revocation-base-url"), "/"),
+		CrlValidity:        viper.GetDuration("revocation-crl-validity"),
+		CrlRefreshInterval: viper.GetDuration("revocation-crl-refresh-interval"),
+	}
+	if (*uc.AppConfig).Revocation.BaseUrl != "" {
+		if err := validation.ValidateURL((*uc.AppConfig).Revocation.BaseUrl, map[string]byte{"https": 0, "http": 0}, "/cms/v1"); err != nil {
+			return errors.Wrap(err, "REVOCATION_BASE_URL is not a valid CMS base URL")
+		}
+	}
+	if (*uc.AppConfig).Revocation.CrlValidity <= 0 {
+		return errors.New("REVOCATION_CRL_VALIDITY must be greater than 0")
+	}
+	if (*uc.AppConfig).Revocation.CrlRefreshInterval <= 0 ||
+		(*uc.AppConfig).Revocation.CrlValidity < (*uc.AppConfig).Revocation.CrlRefreshInterval {
+		return errors.New("REVOCATION_CRL_VALIDITY must not be shorter than REVOCATION_CRL_REFRESH_INTERVAL")
+	}
 	if uc.ServerConfig.Port < 1024 ||
 		uc.ServerConfig.Port > 65535 {
 		uc.ServerConfig.Port = uc.DefaultPort
@@ -65,6 +88,12 @@ func (uc UpdateServiceConfig) Validate() error {
 		(*uc.AppConfig).Server.Port > 65535 {
 		return errors.New("Configured port is not valid")
 	}
+	if (*uc.AppConfig).Revocation.CrlValidity <= 0 {
+		return errors.New("CRL validity is not configured")
+	}
+	if (*uc.AppConfig).Revocation.CrlRefreshInterval <= 0 {
+		return errors.New("CRL refresh interval is not configured")
+	}
 	return nil
 }
 
@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package utils
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	oidPkcs7Data       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidPkcs7SignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+)
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"optional"`
+}
+
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	ContentInfo      pkcs7ContentInfo
+	Certificates     asn1.RawValue   `asn1:"optional,tag:0"`
+	SignerInfos      []asn1.RawValue `asn1:"set"`
+}
+
+// EncodeCertsOnlyPkcs7 returns the DER encoded degenerate PKCS#7 SignedData, without any signer, carrying the
+// certificates as defined by RFC 2315 and used by the EST certs-only responses of RFC 7030
+func EncodeCertsOnlyPkcs7(certs []*x509.Certificate) ([]byte, error) {
+	var rawCerts []byte
+	for _, cert := range certs {
+		rawCerts = append(rawCerts, cert.Raw...)
+	}
+
+	signedData, err := asn1.Marshal(pkcs7SignedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{},
+		ContentInfo:      pkcs7ContentInfo{ContentType: oidPkcs7Data},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: rawCerts},
+		SignerInfos:      []asn1.RawValue{},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "utils/pkcs7:EncodeCertsOnlyPkcs7() Failed to marshal signed data")
+	}
+
+	contentInfo, err := asn1.Marshal(pkcs7ContentInfo{
+		ContentType: oidPkcs7SignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedData},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "utils/pkcs7:EncodeCertsOnlyPkcs7() Failed to marshal content info")
+	}
+	return contentInfo, nil
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package utils
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeCertsOnlyPkcs7(t *testing.T) {
+	rootCert, _ := newTestCa(t)
+	tlsCaCert, _ := newTestCa(t)
+
+	der, err := EncodeCertsOnlyPkcs7([]*x509.Certificate{rootCert, tlsCaCert})
+	assert.NoError(t, err)
+
+	var contentInfo pkcs7ContentInfo
+	rest, err := asn1.Unmarshal(der, &contentInfo)
+	assert.NoError(t, err)
+	assert.Empty(t, rest)
+	assert.True(t, contentInfo.ContentType.Equal(oidPkcs7SignedData))
+	assert.Equal(t, asn1.ClassContextSpecific, contentInfo.Content.Class)
+	assert.Equal(t, 0, contentInfo.Content.Tag)
+
+	var signedData pkcs7SignedData
+	rest, err = asn1.Unmarshal(contentInfo.Content.Bytes, &signedData)
+	assert.NoError(t, err)
+	assert.Empty(t, rest)
+	assert.Equal(t, 1, signedData.Version)
+	assert.True(t, signedData.ContentInfo.ContentType.Equal(oidPkcs7Data))
+	// a certs-only response is degenerate, it is not signed
+	assert.Empty(t, signedData.DigestAlgorithms)
+	assert.Empty(t, signedData.SignerInfos)
+
+	certs, err := x509.ParseCertificates(signedData.Certificates.Bytes)
+	assert.NoError(t, err)
+	if assert.Len(t, certs, 2) {
+		assert.True(t, certs[0].Equal(rootCert))
+		assert.True(t, certs[1].Equal(tlsCaCert))
+	}
+}
+
+func TestEncodeCertsOnlyPkcs7NoCertificates(t *testing.T) {
+	der, err := EncodeCertsOnlyPkcs7(nil)
+	assert.NoError(t, err)
+
+	var contentInfo pkcs7ContentInfo
+	_, err = asn1.Unmarshal(der, &contentInfo)
+	assert.NoError(t, err)
+	var signedData pkcs7SignedData
+	_, err = asn1.Unmarshal(contentInfo.Content.Bytes, &signedData)
+	assert.NoError(t, err)
+	assert.Empty(t, signedData.Certificates.Bytes)
+}
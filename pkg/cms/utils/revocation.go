@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package utils
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"io/ioutil"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/directory"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/cms"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ocsp"
+)
+
+var revocationReasonCodes = map[string]int{
+	cms.RevocationReasonUnspecified:          ocsp.Unspecified,
+	cms.RevocationReasonKeyCompromise:        ocsp.KeyCompromise,
+	cms.RevocationReasonCACompromise:         ocsp.CACompromise,
+	cms.RevocationReasonAffiliationChanged:   ocsp.AffiliationChanged,
+	cms.RevocationReasonSuperseded:           ocsp.Superseded,
+	cms.RevocationReasonCessationOfOperation: ocsp.CessationOfOperation,
+}
+
+// RevocationReasonCode maps the revocation reason of the revoke request to its RFC 5280 reason code
+func RevocationReasonCode(reason string) (int, bool) {
+	if reason == "" {
+		return ocsp.Unspecified, true
+	}
+	code, ok := revocationReasonCodes[strings.ToLower(reason)]
+	return code, ok
+}
+
+// CrlDistributionPoint returns the URL of the CRL of the issuing CA published under the CMS base URL
+func CrlDistributionPoint(baseUrl, issuingCa string) string {
+	return strings.TrimSuffix(baseUrl, "/") + "/crl?issuingCa=" + url.QueryEscape(issuingCa)
+}
+
+// OcspServer returns the URL of the OCSP responder published under the CMS base URL
+func OcspServer(baseUrl string) string {
+	return strings.TrimSuffix(baseUrl, "/") + "/ocsp"
+}
+
+// GenerateCrl signs the list of the revoked certificates of the issuing CA and stores it in the CRL file of the CA
+func GenerateCrl(store *directory.IssuedCertificateStore, issuingCa string, validity time.Duration) error {
+	caAttr := constants.GetCaAttribs(issuingCa)
+	if caAttr.CrlPath == "" {
+		return errors.Errorf("utils/revocation:GenerateCrl() CRL is not supported for %s CA", issuingCa)
+	}
+
+	caCert, caPrivKey, err := crypt.LoadX509CertAndPrivateKey(caAttr.CertPath, caAttr.KeyPath)
+	if err != nil {
+		return errors.Wrap(err, "utils/revocation:GenerateCrl() Could not load Issuing CA")
+	}
+	signer, ok := caPrivKey.(crypto.Signer)
+	if !ok {
+		return errors.New("utils/revocation:GenerateCrl() Issuing CA key does not support signing")
+	}
+
+	crl, err := CreateCrl(store, issuingCa, caCert, signer, validity)
+	if err != nil {
+		return errors.Wrap(err, "utils/revocation:GenerateCrl() Failed to create CRL")
+	}
+	err = ioutil.WriteFile(caAttr.CrlPath, crl, 0644)
+	if err != nil {
+		return errors.Wrap(err, "utils/revocation:GenerateCrl() Failed to write CRL to file")
+	}
+	return nil
+}
+
+// CreateCrl returns the DER encoded CRL listing the unexpired revoked certificates of the issuing CA
+func CreateCrl(store *directory.IssuedCertificateStore, issuingCa string, caCert *x509.Certificate, signer crypto.Signer,
+	validity time.Duration) ([]byte, error) {
+	revoked := true
+	certs, err := store.Search(&directory.IssuedCertificateFilterCriteria{IssuingCa: issuingCa, Revoked: &revoked})
+	if err != nil {
+		return nil, errors.Wrap(err, "utils/revocation:CreateCrl() Failed to search revoked certificates")
+	}
+
+	now := time.Now().UTC()
+	var entries []x509.RevocationListEntry
+	for _, cert := range certs {
+		// expired certificates are no longer listed as they can not be validated anyway
+		if cert.NotAfter.Before(now) {
+			continue
+		}
+		serialNumber, ok := new(big.Int).SetString(cert.SerialNumber, 16)
+		if !ok {
+			return nil, errors.Errorf("utils/revocation:CreateCrl() Invalid serial number %s", cert.SerialNumber)
+		}
+		reasonCode, _ := RevocationReasonCode(cert.RevocationReason)
+		entry := x509.RevocationListEntry{
+			SerialNumber: serialNumber,
+			ReasonCode:   reasonCode,
+		}
+		if cert.RevokedAt != nil {
+			entry.RevocationTime = *cert.RevokedAt
+		}
+		entries = append(entries, entry)
+	}
+
+	template := &x509.RevocationList{
+		RevokedCertificateEntries: entries,
+		// the CRL number has to increase with every CRL issued by the CA
+		Number:     big.NewInt(now.UnixNano()),
+		ThisUpdate: now,
+		NextUpdate: now.Add(validity),
+	}
+	crl, err := x509.CreateRevocationList(rand.Reader, template, caCert, signer)
+	if err != nil {
+		return nil, errors.Wrap(err, "utils/revocation:CreateCrl() Failed to sign CRL")
+	}
+	return crl, nil
+}
+
+// GenerateCrls regenerates the CRL of every intermediate CA
+func GenerateCrls(store *directory.IssuedCertificateStore, validity time.Duration) error {
+	for _, issuingCa := range constants.GetIntermediateCAs() {
+		if err := GenerateCrl(store, issuingCa, validity); err != nil {
+			return errors.Wrapf(err, "utils/revocation:GenerateCrls() Failed to generate CRL of %s CA", issuingCa)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/cms/directory"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/cms"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ocsp"
+)
+
+func newTestCa(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "CMS TLS CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return cert, key
+}
+
+func TestCreateCrl(t *testing.T) {
+	caCert, caKey := newTestCa(t)
+	store := directory.NewIssuedCertificateStore(t.TempDir())
+	now := time.Now().UTC()
+	for _, cert := range []cms.IssuedCertificate{
+		{SerialNumber: "1a", IssuingCa: constants.Tls, NotBefore: now.Add(-time.Hour), NotAfter: now.Add(time.Hour)},
+		{SerialNumber: "2b", IssuingCa: constants.Tls, NotBefore: now.Add(-time.Hour), NotAfter: now.Add(time.Hour)},
+		{SerialNumber: "3c", IssuingCa: constants.Tls, NotBefore: now.Add(-2 * time.Hour), NotAfter: now.Add(-time.Hour)},
+		{SerialNumber: "4d", IssuingCa: constants.Signing, NotBefore: now.Add(-time.Hour), NotAfter: now.Add(time.Hour)},
+	} {
+		cert := cert
+		_, err := store.Create(&cert)
+		assert.NoError(t, err)
+	}
+	for _, serialNumber := range []string{"2b", "3c", "4d"} {
+		_, err := store.Revoke(serialNumber, cms.RevocationReasonKeyCompromise)
+		assert.NoError(t, err)
+	}
+
+	der, err := CreateCrl(store, constants.Tls, caCert, caKey, 24*time.Hour)
+	assert.NoError(t, err)
+	crl, err := x509.ParseRevocationList(der)
+	assert.NoError(t, err)
+	assert.NoError(t, crl.CheckSignatureFrom(caCert))
+	assert.WithinDuration(t, crl.ThisUpdate.Add(24*time.Hour), crl.NextUpdate, time.Second)
+
+	// only the unexpired certificates revoked by the issuing CA are listed
+	assert.Len(t, crl.RevokedCertificateEntries, 1)
+	assert.Equal(t, big.NewInt(0x2b), crl.RevokedCertificateEntries[0].SerialNumber)
+	assert.Equal(t, ocsp.KeyCompromise, crl.RevokedCertificateEntries[0].ReasonCode)
+
+	// the CRL number increases with every CRL
+	next, err := CreateCrl(store, constants.Tls, caCert, caKey, 24*time.Hour)
+	assert.NoError(t, err)
+	nextCrl, err := x509.ParseRevocationList(next)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, nextCrl.Number.Cmp(crl.Number))
+}
+
+func TestRevocationReasonCode(t *testing.T) {
+	tests := []struct {
+		reason       string
+		expectedCode int
+		expectedOk   bool
+	}{
+		{reason: "", expectedCode: ocsp.Unspecified, expectedOk: true},
+		{reason: cms.RevocationReasonKeyCompromise, expectedCode: ocsp.KeyCompromise, expectedOk: true},
+		{reason: "Superseded", expectedCode: ocsp.Superseded, expectedOk: true},
+		{reason: "remove_from_crl", expectedOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			code, ok := RevocationReasonCode(tt.reason)
+			assert.Equal(t, tt.expectedOk, ok)
+			if ok {
+				assert.Equal(t, tt.expectedCode, code)
+			}
+		})
+	}
+}
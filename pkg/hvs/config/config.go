@@ -43,6 +43,11 @@ type Configuration struct {
 	FVS    FVSConfig               `yaml:"fvs" mapstructure:"fvs"`
 	VCSS   VCSSConfig              `yaml:"vcss" mapstructure:"vcss"`
 	NATS   NatsConfig              `yaml:"nats" mapstructure:"nats"`
+	Events EventsConfig            `yaml:"events" mapstructure:"events"`
+
+	HostImport HostImportConfig `yaml:"host-import" mapstructure:"host-import"`
+
+	RevocationCheck commConfig.RevocationCheckConfig `yaml:"revocation-check" mapstructure:"revocation-check"`
 }
 
 type FVSConfig struct {
@@ -69,6 +74,20 @@ type VCSSConfig struct {
 	RefreshPeriod time.Duration `yaml:"refresh-period" mapstructure:"refresh-period"`
 }
 
+type EventsConfig struct {
+	BufferSize int `yaml:"buffer-size" mapstructure:"buffer-size"`
+	// WebhookMaxAttempts is the maximum number of delivery attempts of an event to a webhook subscription
+	WebhookMaxAttempts int `yaml:"webhook-max-attempts" mapstructure:"webhook-max-attempts"`
+	// WebhookRetryDelay is the delay before the first retry of a failed delivery, doubled on each following retry
+	WebhookRetryDelay time.Duration `yaml:"webhook-retry-delay" mapstructure:"webhook-retry-delay"`
+	WebhookTimeout    time.Duration `yaml:"webhook-timeout" mapstructure:"webhook-timeout"`
+}
+
+type HostImportConfig struct {
+	// Concurrency is the maximum number of hosts of a bulk host import job registered at the same time
+	Concurrency int `yaml:"concurrency" mapstructure:"concurrency"`
+}
+
 type NatsConfig struct {
 	Servers []string `yaml:"servers" mapstructure:"servers"`
 }
@@ -166,6 +166,38 @@ const (
 	DefaultVcssRefreshPeriod = time.Duration(2) * time.Minute
 )
 
+// flavor lifecycle constants
+const (
+	// FlavorLifecycleSchedulerPeriod bounds the time between two searches of the upcoming flavor lifecycle dates
+	FlavorLifecycleSchedulerPeriod = time.Duration(5) * time.Minute
+)
+
+// trust event constants
+const (
+	DefaultEventsBufferSize         = 5000
+	DefaultEventsWebhookMaxAttempts = 5
+	DefaultEventsWebhookRetryDelay  = time.Duration(5) * time.Second
+	DefaultEventsWebhookTimeout     = time.Duration(10) * time.Second
+	// EventStreamKeepAliveInterval is the interval of the comments sent on an idle event stream to keep the
+	// connection open through proxies
+	EventStreamKeepAliveInterval = time.Duration(30) * time.Second
+	// EventSignatureHeader carries the hex encoded HMAC-SHA256 of the payload delivered to a webhook
+	EventSignatureHeader = "X-HVS-Signature"
+	EventTypeHeader      = "X-HVS-Event"
+)
+
+// bulk host import constants
+const (
+	DefaultHostImportConcurrency = 10
+	// MaxHostImportRows is the maximum number of hosts of a bulk host import job
+	MaxHostImportRows = 10000
+)
+
+// certificate revocation check constants
+const (
+	DefaultRevocationCheckCrlCacheDuration = time.Duration(1) * time.Hour
+)
+
 // audit log constants
 const (
 	DefaultMaxRowCount       = 10000
@@ -194,4 +226,15 @@ const (
 	FvsHostTrustCacheThreshold         = "fvs-host-trust-cache-threshold"
 	HrrsRefreshPeriod                  = "hrrs-refresh-period"
 	VcssRefreshPeriod                  = "vcss-refresh-period"
+	EventsBufferSize                   = "events-buffer-size"
+	EventsWebhookMaxAttempts           = "events-webhook-max-attempts"
+	EventsWebhookRetryDelay            = "events-webhook-retry-delay"
+	EventsWebhookTimeout               = "events-webhook-timeout"
+	HostImportConcurrency              = "host-import-concurrency"
+	RevocationCheckEnabled             = "revocation-check-enabled"
+	RevocationCheckCrlFile             = "revocation-check-crl-file"
+	RevocationCheckFetchCrl            = "revocation-check-fetch-crl"
+	RevocationCheckOcsp                = "revocation-check-ocsp"
+	RevocationCheckCrlCacheDuration    = "revocation-check-crl-cache-duration"
+	RevocationCheckFailOpen            = "revocation-check-fail-open"
 )
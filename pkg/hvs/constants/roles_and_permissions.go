@@ -35,10 +35,15 @@ const (
 	FlavorTemplateSearch   = "flavor-template:search"
 	FlavorTemplateDelete   = "flavor-template:delete"
 
-	FlavorCreate   = "flavors:create"
-	FlavorRetrieve = "flavors:retrieve"
-	FlavorSearch   = "flavors:search"
-	FlavorDelete   = "flavors:delete"
+	FlavorCreate          = "flavors:create"
+	FlavorRetrieve        = "flavors:retrieve"
+	FlavorSearch          = "flavors:search"
+	FlavorDelete          = "flavors:delete"
+	FlavorDryRun          = "flavors:dry_run"
+	FlavorLifecycleUpdate = "flavors:lifecycle_update"
+	FlavorExport          = "flavors:export"
+	FlavorImport          = "flavors:import"
+	FlavorDiff            = "flavors:diff"
 
 	TagFlavorCreate        = "tag_flavors:create"
 	HostUniqueFlavorCreate = "host_unique_flavors:create"
@@ -61,6 +66,18 @@ const (
 	ReportRetrieve = "reports:retrieve"
 	ReportSearch   = "reports:search"
 
+	EventSubscriptionCreate   = "event_subscriptions:create"
+	EventSubscriptionRetrieve = "event_subscriptions:retrieve"
+	EventSubscriptionStore    = "event_subscriptions:store"
+	EventSubscriptionSearch   = "event_subscriptions:search"
+	EventSubscriptionDelete   = "event_subscriptions:delete"
+
+	EventStream = "events:stream"
+
+	AuditLogSearch = "audit_logs:search"
+	AuditLogVerify = "audit_logs:verify"
+	AuditLogExport = "audit_logs:export"
+
 	// AssetTagAPI
 	TagCertificateCreate = "tag_certificates:create"
 	TagCertificateDelete = "tag_certificates:delete"
@@ -29,6 +29,7 @@ const (
 	FaultAikCertificateMissing                      = FaultPrefix + "AikCertificateMissing"
 	FaultAikCertificateNotTrusted                   = FaultPrefix + "AikCertificateNotTrusted"
 	FaultAikCertificateNotYetValid                  = FaultPrefix + "AikCertificateNotYetValid"
+	FaultAikCertificateRevoked                      = FaultPrefix + "AikCertificateRevoked"
 	FaultAllofFlavorsMissing                        = FaultPrefix + "AllOfFlavorsMissing"
 	FaultAssetTagMismatch                           = FaultPrefix + "AssetTagMismatch"
 	FaultAssetTagMissing                            = FaultPrefix + "AssetTagMissing"
@@ -37,6 +38,7 @@ const (
 	FaultRequiredFlavorTypeMissing                  = FaultPrefix + "RequiredFlavorTypeMissing"
 	FaultFlavorSignatureNotTrusted                  = FaultPrefix + "FlavorSignatureNotTrusted"
 	FaultFlavorSignatureVerificationFailed          = FaultPrefix + "FlavorSignatureVerificationFailed"
+	FaultFlavorSigningCertificateRevoked            = FaultPrefix + "FlavorSigningCertificateRevoked"
 	FaultPcrEventLogContainsUnexpectedEntries       = FaultPrefix + "PcrEventLogContainsUnexpectedEntries"
 	FaultPcrEventLogInvalid                         = FaultPrefix + "PcrEventLogInvalid"
 	FaultPcrEventLogMissing                         = FaultPrefix + "PcrEventLogMissing"
@@ -50,6 +52,7 @@ const (
 	FaultTagCertificateMissing                      = FaultPrefix + "TagCertificateMissing"
 	FaultTagCertificateNotTrusted                   = FaultPrefix + "TagCertificateNotTrusted"
 	FaultTagCertificateNotYetValid                  = FaultPrefix + "TagCertificateNotYetValid"
+	FaultTagCertificateRevoked                      = FaultPrefix + "TagCertificateRevoked"
 	FaultXmlMeasurementLogContainsUnexpectedEntries = FaultPrefix + "XmlMeasurementLogContainsUnexpectedEntries"
 	FaultXmlMeasurementLogInvalid                   = FaultPrefix + "XmlMeasurementLogInvalid"
 	FaultXmlMeasurementLogMissing                   = FaultPrefix + "XmlMeasurementLogMissing"
@@ -61,6 +64,13 @@ const (
 	PcrEventLogMissingFields                        = "PcrEventLogMissingFields"
 )
 
+// Verifier Warnings
+const (
+	WarningPrefix                = "warning."
+	WarningFlavorDeprecated      = WarningPrefix + "FlavorDeprecated"
+	WarningDeprecatedFlavorFault = WarningPrefix + "DeprecatedFlavorFault"
+)
+
 //Builder names
 const (
 	IntelBuilder  = "Intel Host Trust Policy"
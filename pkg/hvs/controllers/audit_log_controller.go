@@ -0,0 +1,316 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/services/auditlog"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/utils"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	commLogMsg "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/message"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+// HTTPMediaTypeJsonLines is the media type of the audit log export, one JSON document per line
+const HTTPMediaTypeJsonLines = "application/x-ndjson"
+
+// AuditLogController exposes the audit log entries recorded for the reports and the host statuses
+type AuditLogController struct {
+	Store     domain.AuditLogEntryStore
+	CertStore *models.CertificatesStore
+}
+
+func NewAuditLogController(store domain.AuditLogEntryStore, certStore *models.CertificatesStore) *AuditLogController {
+	return &AuditLogController{
+		Store:     store,
+		CertStore: certStore,
+	}
+}
+
+var auditLogFilterParams = map[string]bool{"entityType": true, "entityId": true, "action": true, "fromDate": true, "toDate": true}
+var auditLogSearchParams = utils.WithPageQueryParams(auditLogFilterParams)
+var auditLogVerifyParams = map[string]bool{"anchorSequence": true, "anchorHash": true}
+var auditLogSortFields = []string{models.SortByCreated, models.SortByID}
+
+// Search returns the audit log entries matching the filter criteria
+func (controller AuditLogController) Search(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/audit_log_controller:Search() Entering")
+	defer defaultLog.Trace("controllers/audit_log_controller:Search() Leaving")
+
+	if err := utils.ValidateQueryParams(r.URL.Query(), auditLogSearchParams); err != nil {
+		secLog.Errorf("controllers/audit_log_controller:Search() %s", err.Error())
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	criteria, err := getAuditLogFilterCriteria(r.URL.Query())
+	if err != nil {
+		secLog.WithError(err).Warnf("controllers/audit_log_controller:Search() %s", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+	criteria.Page, err = utils.ParsePageCriteria(r.URL.Query(), auditLogSortFields)
+	if err != nil {
+		secLog.WithError(err).Warnf("controllers/audit_log_controller:Search() %s", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	entries, err := controller.Store.Search(criteria)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/audit_log_controller:Search() Audit log search operation failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Audit log search operation failed"}
+	}
+
+	collection := hvs.AuditLogEntryCollection{AuditLogEntries: []hvs.AuditLogEntry{}}
+	for i := range entries {
+		collection.AuditLogEntries = append(collection.AuditLogEntries, entries[i].ToAuditLog())
+	}
+	if criteria.Page != nil && len(entries) > 0 {
+		last := entries[len(entries)-1]
+		cursor := models.PageCursor{SortBy: criteria.Page.SortBy, ID: last.ID}
+		if criteria.Page.SortBy == models.SortByCreated {
+			cursor.Value = models.PageCursorTime(last.CreatedAt)
+		}
+		collection.Next = utils.NextPageLink(r, criteria.Page, len(entries), cursor)
+	}
+
+	secLog.Infof("%s: Return audit log search query to: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return collection, http.StatusOK, nil
+}
+
+// Verify walks the hash chain of the audit log and reports the entries that were modified or deleted. The entries
+// written before the chaining was introduced are not verified.
+func (controller AuditLogController) Verify(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/audit_log_controller:Verify() Entering")
+	defer defaultLog.Trace("controllers/audit_log_controller:Verify() Leaving")
+
+	if err := utils.ValidateQueryParams(r.URL.Query(), auditLogVerifyParams); err != nil {
+		secLog.Errorf("controllers/audit_log_controller:Verify() %s", err.Error())
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+	anchor, err := getAuditLogChainAnchor(r.URL.Query())
+	if err != nil {
+		secLog.WithError(err).Warnf("controllers/audit_log_controller:Verify() %s", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	verifier := auditlog.NewChainVerifier(anchor)
+	err = controller.walkChain(&models.AuditLogFilterCriteria{}, func(entry *models.AuditLogEntry) error {
+		if entry.Sequence > 0 {
+			verifier.Add(entry)
+		}
+		return nil
+	})
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/audit_log_controller:Verify() Failed to retrieve the audit log entries")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Audit log verification failed"}
+	}
+
+	result := verifier.Result()
+	if !result.Verified {
+		secLog.Warnf("controllers/audit_log_controller:Verify() Audit log hash chain verification failed: %d modified, %d broken links, %d missing ranges",
+			len(result.ModifiedEntries), len(result.BrokenLinks), len(result.MissingEntries))
+	}
+	secLog.Infof("%s: Return audit log verification to: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return result, http.StatusOK, nil
+}
+
+// Export streams the audit log entries matching the filter criteria as JSON Lines, in sequence order. The last
+// line holds the signature of the export by the SAML signing key of HVS.
+func (controller AuditLogController) Export(w http.ResponseWriter, r *http.Request) error {
+	defaultLog.Trace("controllers/audit_log_controller:Export() Entering")
+	defer defaultLog.Trace("controllers/audit_log_controller:Export() Leaving")
+
+	if r.Header.Get("Accept") != HTTPMediaTypeJsonLines {
+		return &commErr.HandledError{StatusCode: http.StatusUnsupportedMediaType, Message: "Invalid Accept type"}
+	}
+	if err := utils.ValidateQueryParams(r.URL.Query(), auditLogFilterParams); err != nil {
+		secLog.Errorf("controllers/audit_log_controller:Export() %s", err.Error())
+		return &commErr.HandledError{StatusCode: http.StatusBadRequest, Message: err.Error()}
+	}
+	criteria, err := getAuditLogFilterCriteria(r.URL.Query())
+	if err != nil {
+		secLog.WithError(err).Warnf("controllers/audit_log_controller:Export() %s", commLogMsg.InvalidInputBadParam)
+		return &commErr.HandledError{StatusCode: http.StatusBadRequest, Message: err.Error()}
+	}
+
+	signer, algorithm, certificate, err := controller.exportSigner()
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/audit_log_controller:Export() Audit log export signing key is not available")
+		return &commErr.HandledError{StatusCode: http.StatusInternalServerError, Message: "Audit log export failed"}
+	}
+
+	digest := sha512.New384()
+	encoder := json.NewEncoder(io.MultiWriter(w, digest))
+	signature := hvs.AuditLogExportSignature{Algorithm: algorithm, Certificate: base64.StdEncoding.EncodeToString(certificate)}
+	headerWritten := false
+	err = controller.walkChain(criteria, func(entry *models.AuditLogEntry) error {
+		if !headerWritten {
+			w.Header().Set("Content-Type", HTTPMediaTypeJsonLines)
+			w.WriteHeader(http.StatusOK)
+			headerWritten = true
+		}
+		if signature.EntryCount == 0 {
+			signature.FirstSequence = entry.Sequence
+		}
+		signature.EntryCount++
+		signature.LastSequence = entry.Sequence
+		if entry.Hash != "" {
+			signature.LastHash = entry.Hash
+		}
+		return encoder.Encode(entry.ToAuditLog())
+	})
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/audit_log_controller:Export() Failed to export the audit log entries")
+		if !headerWritten {
+			return &commErr.HandledError{StatusCode: http.StatusInternalServerError, Message: "Audit log export failed"}
+		}
+		// the status was already sent, the missing signature tells the client the export is incomplete
+		return nil
+	}
+	if !headerWritten {
+		w.Header().Set("Content-Type", HTTPMediaTypeJsonLines)
+		w.WriteHeader(http.StatusOK)
+	}
+
+	sum := digest.Sum(nil)
+	sig, err := signer.Sign(rand.Reader, sum, crypto.SHA384)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/audit_log_controller:Export() Failed to sign the audit log export")
+		return nil
+	}
+	signature.Digest = hex.EncodeToString(sum)
+	signature.Signature = base64.StdEncoding.EncodeToString(sig)
+	if err := json.NewEncoder(w).Encode(hvs.AuditLogExportTrailer{Signature: signature}); err != nil {
+		defaultLog.WithError(err).Error("controllers/audit_log_controller:Export() Failed to write the audit log export signature")
+		return nil
+	}
+
+	secLog.Infof("%s: Return audit log export of %d entries to: %s", commLogMsg.AuthorizedAccess, signature.EntryCount, r.RemoteAddr)
+	return nil
+}
+
+// walkChain calls fn for each entry matching the criteria in sequence order, the entries that are not chained
+// coming first
+func (controller AuditLogController) walkChain(criteria *models.AuditLogFilterCriteria, fn func(*models.AuditLogEntry) error) error {
+	criteria.ChainAfter = &models.AuditLogChainCursor{}
+	criteria.Limit = constants.DefaultSearchResultRowLimit
+	for {
+		entries, err := controller.Store.Search(criteria)
+		if err != nil {
+			return err
+		}
+		for i := range entries {
+			if err := fn(&entries[i]); err != nil {
+				return err
+			}
+		}
+		if len(entries) < criteria.Limit {
+			return nil
+		}
+		last := entries[len(entries)-1]
+		criteria.ChainAfter = &models.AuditLogChainCursor{Sequence: last.Sequence, ID: last.ID}
+	}
+}
+
+// exportSigner returns the SAML signing key with the name of its signature algorithm and its certificate
+func (controller AuditLogController) exportSigner() (crypto.Signer, string, []byte, error) {
+	key, certificates, err := controller.CertStore.GetKeyAndCertificates(models.CertTypesSaml.String())
+	if err != nil {
+		return nil, "", nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok || len(certificates) == 0 {
+		return nil, "", nil, errors.New("SAML signing key or certificate is missing")
+	}
+	switch signer.Public().(type) {
+	case *rsa.PublicKey:
+		return signer, "RS384", certificates[0].Raw, nil
+	case *ecdsa.PublicKey:
+		return signer, "ES384", certificates[0].Raw, nil
+	default:
+		return nil, "", nil, errors.New("Unsupported SAML signing key type")
+	}
+}
+
+func getAuditLogFilterCriteria(params url.Values) (*models.AuditLogFilterCriteria, error) {
+	defaultLog.Trace("controllers/audit_log_controller:getAuditLogFilterCriteria() Entering")
+	defer defaultLog.Trace("controllers/audit_log_controller:getAuditLogFilterCriteria() Leaving")
+
+	criteria := models.AuditLogFilterCriteria{}
+
+	if entityType := strings.TrimSpace(params.Get("entityType")); entityType != "" {
+		if err := validation.ValidateNameString(entityType); err != nil {
+			return nil, errors.Wrap(err, "Valid contents for entityType must be specified")
+		}
+		criteria.EntityType = entityType
+	}
+
+	if entityID := strings.TrimSpace(params.Get("entityId")); entityID != "" {
+		id, err := uuid.Parse(entityID)
+		if err != nil {
+			return nil, errors.New("Invalid UUID format of the entityId")
+		}
+		criteria.EntityID = id
+	}
+
+	if action := strings.TrimSpace(params.Get("action")); action != "" {
+		if err := validation.ValidateNameString(action); err != nil {
+			return nil, errors.Wrap(err, "Valid contents for action must be specified")
+		}
+		criteria.Action = action
+	}
+
+	if fromDate := strings.TrimSpace(params.Get("fromDate")); fromDate != "" {
+		pTime, err := utils.ParseDateQueryParam(fromDate)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid fromDate specified")
+		}
+		criteria.FromDate = pTime
+	}
+
+	if toDate := strings.TrimSpace(params.Get("toDate")); toDate != "" {
+		pTime, err := utils.ParseDateQueryParam(toDate)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid toDate specified")
+		}
+		criteria.ToDate = pTime
+	}
+
+	return &criteria, nil
+}
+
+func getAuditLogChainAnchor(params url.Values) (*auditlog.ChainAnchor, error) {
+	anchorSequence := strings.TrimSpace(params.Get("anchorSequence"))
+	anchorHash := strings.TrimSpace(params.Get("anchorHash"))
+	if anchorSequence == "" && anchorHash == "" {
+		return nil, nil
+	}
+	sequence, err := strconv.ParseInt(anchorSequence, 10, 64)
+	if err != nil || sequence <= 0 {
+		return nil, errors.New("anchorSequence must be an integer > 0")
+	}
+	if len(anchorHash) != sha512.Size384*2 || validation.ValidateHexString(anchorHash) != nil {
+		return nil, errors.New("anchorHash must be a hex encoded SHA-384 hash")
+	}
+	return &auditlog.ChainAnchor{Sequence: sequence, Hash: strings.ToLower(anchorHash)}, nil
+}
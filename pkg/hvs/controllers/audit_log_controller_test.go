@@ -0,0 +1,252 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package controllers_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/mocks"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	hvsRoutes "github.com/intel-secl/intel-secl/v4/pkg/hvs/router"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/services/auditlog"
+	consts "github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuditLogController", func() {
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+	var auditLogEntryStore *mocks.MockAuditLogEntryStore
+	var auditLogController *controllers.AuditLogController
+	var samlKey *rsa.PrivateKey
+	hostStatusID := uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2")
+
+	BeforeEach(func() {
+		router = mux.NewRouter()
+		auditLogEntryStore = mocks.NewMockAuditLogEntryStore()
+
+		var err error
+		samlKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		template := x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "HVS SAML Certificate"},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		certDer, err := x509.CreateCertificate(rand.Reader, &template, &template, &samlKey.PublicKey, samlKey)
+		Expect(err).NotTo(HaveOccurred())
+		samlCert, err := x509.ParseCertificate(certDer)
+		Expect(err).NotTo(HaveOccurred())
+		certStore := mocks.NewFakeCertificatesStore()
+		(*certStore)[models.CertTypesSaml.String()].Key = samlKey
+		(*certStore)[models.CertTypesSaml.String()].Certificates = []x509.Certificate{*samlCert}
+		auditLogController = controllers.NewAuditLogController(auditLogEntryStore, certStore)
+
+		// two host status entries and a report entry chained by the audit log writer
+		writer, err := auditlog.NewAuditLogDBWriter(auditLogEntryStore, 3)
+		Expect(err).NotTo(HaveOccurred())
+		hostStatus := &hvs.HostStatus{ID: hostStatusID, HostID: uuid.New(), Created: time.Now()}
+		entry, err := writer.CreateEntry("create", hostStatus)
+		Expect(err).NotTo(HaveOccurred())
+		writer.Log(entry)
+		entry, err = writer.CreateEntry("update", hostStatus, &hvs.HostStatus{ID: hostStatusID, HostID: hostStatus.HostID,
+			HostStatusInformation: hvs.HostStatusInformation{HostState: hvs.HostStateConnected}})
+		Expect(err).NotTo(HaveOccurred())
+		writer.Log(entry)
+		entry, err = writer.CreateEntry("create", &models.HVSReport{ID: uuid.New(), HostID: hostStatus.HostID})
+		Expect(err).NotTo(HaveOccurred())
+		writer.Log(entry)
+		writer.Stop()
+	})
+
+	// Specs for HTTP Get to "/audit-logs"
+	Describe("Search audit log entries", func() {
+		Context("Search the entries of a host status", func() {
+			It("Should get the entries of the host status", func() {
+				router.Handle("/audit-logs", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(auditLogController.Search))).Methods("GET")
+				req, err := http.NewRequest("GET", "/audit-logs?entityType=host_status&entityId="+hostStatusID.String(), nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var collection hvs.AuditLogEntryCollection
+				err = json.Unmarshal(w.Body.Bytes(), &collection)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(collection.AuditLogEntries).To(HaveLen(2))
+				Expect(collection.AuditLogEntries[0].EntityID).To(Equal(hostStatusID))
+				Expect(collection.AuditLogEntries[0].Hash).NotTo(BeEmpty())
+				Expect(collection.Next).To(BeEmpty())
+			})
+		})
+
+		Context("Search the entries by page", func() {
+			It("Should get a link to the next page", func() {
+				router.Handle("/audit-logs", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(auditLogController.Search))).Methods("GET")
+				req, err := http.NewRequest("GET", "/audit-logs?limit=2", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var collection hvs.AuditLogEntryCollection
+				err = json.Unmarshal(w.Body.Bytes(), &collection)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(collection.AuditLogEntries).To(HaveLen(2))
+				Expect(collection.Next).To(ContainSubstring("after="))
+			})
+		})
+
+		Context("Search with an invalid entity ID", func() {
+			It("Should get HTTP Status: 400", func() {
+				router.Handle("/audit-logs", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(auditLogController.Search))).Methods("GET")
+				req, err := http.NewRequest("GET", "/audit-logs?entityId=e57e5ea0", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	// Specs for HTTP Get to "/audit-logs/verify"
+	Describe("Verify the audit log", func() {
+		Context("Verify an intact audit log", func() {
+			It("Should report the chain as verified", func() {
+				router.Handle("/audit-logs/verify", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(auditLogController.Verify))).Methods("GET")
+				latest, err := auditLogEntryStore.RetrieveLatest()
+				Expect(err).NotTo(HaveOccurred())
+				req, err := http.NewRequest("GET", "/audit-logs/verify?anchorSequence=3&anchorHash="+latest.Hash, nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var result hvs.AuditLogChainVerification
+				err = json.Unmarshal(w.Body.Bytes(), &result)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Verified).To(BeTrue())
+				Expect(result.EntryCount).To(Equal(int64(3)))
+				Expect(result.LastHash).To(Equal(latest.Hash))
+				Expect(result.Anchor).To(Equal(hvs.AuditLogAnchorMatched))
+			})
+		})
+
+		Context("Verify an audit log with a modified and a deleted entry", func() {
+			It("Should report the modified and the missing entries", func() {
+				router.Handle("/audit-logs/verify", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(auditLogController.Verify))).Methods("GET")
+				for id, entry := range auditLogEntryStore.Entries {
+					switch entry.Sequence {
+					case 1:
+						entry.Action = "delete"
+					case 2:
+						delete(auditLogEntryStore.Entries, id)
+					}
+				}
+				req, err := http.NewRequest("GET", "/audit-logs/verify", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var result hvs.AuditLogChainVerification
+				err = json.Unmarshal(w.Body.Bytes(), &result)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Verified).To(BeFalse())
+				Expect(result.ModifiedEntries).To(Equal([]int64{1}))
+				Expect(result.MissingEntries).To(Equal([]hvs.AuditLogSequenceRange{{From: 2, To: 2}}))
+			})
+		})
+
+		Context("Verify with an invalid anchor hash", func() {
+			It("Should get HTTP Status: 400", func() {
+				router.Handle("/audit-logs/verify", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(auditLogController.Verify))).Methods("GET")
+				req, err := http.NewRequest("GET", "/audit-logs/verify?anchorSequence=3&anchorHash=abcd", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	// Specs for HTTP Get to "/audit-logs/export"
+	Describe("Export the audit log", func() {
+		Context("Export the audit log as JSON Lines", func() {
+			It("Should get the entries followed by a valid signature", func() {
+				router.Handle("/audit-logs/export", hvsRoutes.ErrorHandler(auditLogController.Export)).Methods("GET")
+				req, err := http.NewRequest("GET", "/audit-logs/export", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", controllers.HTTPMediaTypeJsonLines)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(w.Header().Get("Content-Type")).To(Equal(controllers.HTTPMediaTypeJsonLines))
+
+				body := w.Body.Bytes()
+				lines := bytes.SplitAfter(bytes.TrimSuffix(body, []byte("\n")), []byte("\n"))
+				Expect(lines).To(HaveLen(4))
+				for i, line := range lines[:3] {
+					var entry hvs.AuditLogEntry
+					err = json.Unmarshal(line, &entry)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(entry.Sequence).To(Equal(int64(i + 1)))
+					Expect(entry.ComputeHash()).To(Equal(entry.Hash))
+				}
+
+				var trailer hvs.AuditLogExportTrailer
+				err = json.Unmarshal(lines[3], &trailer)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(trailer.Signature.EntryCount).To(Equal(3))
+				Expect(trailer.Signature.LastSequence).To(Equal(int64(3)))
+				Expect(trailer.Signature.Algorithm).To(Equal("RS384"))
+
+				// the signature covers the entry lines
+				digest := sha512.Sum384(bytes.Join(lines[:3], nil))
+				signature, err := base64.StdEncoding.DecodeString(trailer.Signature.Signature)
+				Expect(err).NotTo(HaveOccurred())
+				err = rsa.VerifyPKCS1v15(&samlKey.PublicKey, crypto.SHA384, digest[:], signature)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("Export the audit log with a JSON Accept type", func() {
+			It("Should get HTTP Status: 415", func() {
+				router.Handle("/audit-logs/export", hvsRoutes.ErrorHandler(auditLogController.Export)).Methods("GET")
+				req, err := http.NewRequest("GET", "/audit-logs/export", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusUnsupportedMediaType))
+			})
+		})
+	})
+})
@@ -9,7 +9,6 @@ import (
 	"bytes"
 	"crypto"
 	"crypto/rand"
-	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
@@ -92,6 +91,20 @@ func (certifyHostAiksController *CertifyHostAiksController) StoreEkCerts(identit
 		return err
 	}
 
+	// the public area is only sent for the ECC AIKs, which have no modulus
+	if len(identityChallengePayload.IdentityRequest.AikPublicArea) > 0 {
+		publicAreaFileName := idReqFileName + ".pub"
+		// add validation to check if the file exists with permission 0400
+		fInfoAikPub, err := os.Stat(certifyHostAiksController.AikRequestsDirPath + publicAreaFileName)
+		if fInfoAikPub != nil && fInfoAikPub.Mode().Perm() != 0400 {
+			return errors.Errorf("Invalid file permission on %s", certifyHostAiksController.AikRequestsDirPath+publicAreaFileName)
+		}
+		err = ioutil.WriteFile(certifyHostAiksController.AikRequestsDirPath+publicAreaFileName, identityChallengePayload.IdentityRequest.AikPublicArea, 0400)
+		if err != nil {
+			return err
+		}
+	}
+
 	ekcertFilename := idReqFileName + ".ekcert"
 	// add validation to check if the file exists with permission 0400
 	fInfoEkCert, err := os.Stat(certifyHostAiksController.AikRequestsDirPath + ekcertFilename)
@@ -105,30 +118,30 @@ func (certifyHostAiksController *CertifyHostAiksController) StoreEkCerts(identit
 	return nil
 }
 
-func (certifyHostAiksController *CertifyHostAiksController) GetEkCerts(decryptedIdentityRequestChallenge []byte) (*x509.Certificate, []byte, []byte, error) {
+func (certifyHostAiksController *CertifyHostAiksController) GetEkCerts(decryptedIdentityRequestChallenge []byte) (*x509.Certificate, taModel.IdentityRequest, error) {
 	defaultLog.Trace("controllers/certify_host_aiks_controller:GetEkCerts() Entering")
 	defer defaultLog.Trace("controllers/certify_host_aiks_controller:GetEkCerts() Leaving")
 
 	fileName := hex.EncodeToString(decryptedIdentityRequestChallenge)
 	if _, err := os.Stat(certifyHostAiksController.AikRequestsDirPath + fileName); os.IsNotExist(err) {
-		return nil, nil, nil, errors.New("controllers/certify_host_aiks_controller:GetEkCerts() Invalid Challenge response")
+		return nil, taModel.IdentityRequest{}, errors.New("controllers/certify_host_aiks_controller:GetEkCerts() Invalid Challenge response")
 	}
 	defaultLog.Debugf("ek cert fileName: %s", fileName)
 	ekcertFile := certifyHostAiksController.AikRequestsDirPath + fileName + ".ekcert"
 	ekCert, err := ioutil.ReadFile(ekcertFile)
 	if err != nil {
-		return nil, nil, nil, errors.Wrapf(err, "controllers/certify_host_aiks_controller:GetEkCerts() Unable to read file %s", ekcertFile)
+		return nil, taModel.IdentityRequest{}, errors.Wrapf(err, "controllers/certify_host_aiks_controller:GetEkCerts() Unable to read file %s", ekcertFile)
 	}
 
 	ekx509Certs, err := x509.ParseCertificates(ekCert)
 	if err != nil {
-		return nil, nil, nil, errors.Wrap(err, "controllers/certify_host_aiks_controller:GetEkCerts() Unable to parse certificate")
+		return nil, taModel.IdentityRequest{}, errors.Wrap(err, "controllers/certify_host_aiks_controller:GetEkCerts() Unable to parse certificate")
 	}
 	var ekx509Cert *x509.Certificate
 	// since the EK certificate may have multiple levels, we need to extract the leaf
 	ekx509Cert = crypt.GetLeafCert(ekx509Certs)
 	if ekx509Cert == nil {
-		return nil, nil, nil, errors.New("controllers/certify_host_aiks_controller:GetEkCerts() EK leaf cert missing from chain")
+		return nil, taModel.IdentityRequest{}, errors.New("controllers/certify_host_aiks_controller:GetEkCerts() EK leaf cert missing from chain")
 	}
 
 	optionsFile := certifyHostAiksController.AikRequestsDirPath + fileName + ".opt"
@@ -136,15 +149,24 @@ func (certifyHostAiksController *CertifyHostAiksController) GetEkCerts(decrypted
 
 	modulus, err := ioutil.ReadFile(challengeFile)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, taModel.IdentityRequest{}, err
 	}
 
 	aikName, err := ioutil.ReadFile(optionsFile)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, taModel.IdentityRequest{}, err
 	}
 
-	return ekx509Cert, modulus, aikName, nil
+	var aikPublicArea []byte
+	publicAreaFile := certifyHostAiksController.AikRequestsDirPath + fileName + ".pub"
+	if _, err := os.Stat(publicAreaFile); err == nil {
+		aikPublicArea, err = ioutil.ReadFile(publicAreaFile)
+		if err != nil {
+			return nil, taModel.IdentityRequest{}, err
+		}
+	}
+
+	return ekx509Cert, taModel.IdentityRequest{AikModulus: modulus, AikName: aikName, AikPublicArea: aikPublicArea}, nil
 }
 
 func (certifyHostAiksController *CertifyHostAiksController) IdentityRequestGetChallenge(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
@@ -289,7 +311,7 @@ func (certifyHostAiksController *CertifyHostAiksController) getIdentityProofRequ
 		return taModel.IdentityProofRequest{}, http.StatusInternalServerError, errors.Wrapf(err, "controllers/certify_host_aiks_controller:getIdentityProofRequestResponse() directory %s doesnot exist", certifyHostAiksController.AikRequestsDirPath)
 	}
 
-	ekx509Cert, modulus, aikName, err := certifyHostAiksController.GetEkCerts(decryptedIdentityRequestChallenge)
+	ekx509Cert, aikRequest, err := certifyHostAiksController.GetEkCerts(decryptedIdentityRequestChallenge)
 	if err != nil {
 		return taModel.IdentityProofRequest{}, http.StatusBadRequest, err
 	}
@@ -299,18 +321,21 @@ func (certifyHostAiksController *CertifyHostAiksController) getIdentityProofRequ
 		return taModel.IdentityProofRequest{}, http.StatusBadRequest, err
 	}
 
-	n := new(big.Int)
-	n.SetBytes(modulus)
-
-	aikPubKey := rsa.PublicKey{N: n, E: 65537}
-	pcaKey := (*certifyHostAiksController.CertStore)[models.CaCertTypesPrivacyCa.String()].Key
+	aikPubKey, err := libPrivacyca.GetAikPublicKey(aikRequest)
+	if err != nil {
+		return taModel.IdentityProofRequest{}, http.StatusBadRequest, errors.Wrap(err, "controllers/certify_host_aiks_controller:getIdentityProofRequestResponse() Invalid AIK public key")
+	}
+	pcaKey, ok := (*certifyHostAiksController.CertStore)[models.CaCertTypesPrivacyCa.String()].Key.(crypto.Signer)
+	if !ok {
+		return taModel.IdentityProofRequest{}, http.StatusInternalServerError, errors.New("controllers/certify_host_aiks_controller:getIdentityProofRequestResponse() Privacy CA key is not a signing key")
+	}
 	pcaCert := (*certifyHostAiksController.CertStore)[models.CaCertTypesPrivacyCa.String()].Certificates
-	aikCert, err := certifyHostAiksController.CertifyAik(&aikPubKey, aikName, pcaKey.(*rsa.PrivateKey), &pcaCert[0], certifyHostAiksController.AikCertValidity)
+	aikCert, err := certifyHostAiksController.CertifyAik(aikPubKey, aikRequest.AikName, pcaKey, &pcaCert[0], certifyHostAiksController.AikCertValidity)
 	if err != nil {
 		return taModel.IdentityProofRequest{}, http.StatusInternalServerError, errors.Wrap(err, "controllers/certify_host_aiks_controller:getIdentityProofRequestResponse() Unable to Certify Aik")
 	}
 
-	proofReq, err := privacycaTpm2.ProcessIdentityRequest(identityChallengePayload.IdentityRequest, ekx509Cert.PublicKey.(*rsa.PublicKey), aikCert)
+	proofReq, err := privacycaTpm2.ProcessIdentityRequest(identityChallengePayload.IdentityRequest, ekx509Cert.PublicKey, aikCert)
 	if err != nil {
 		defaultLog.WithError(err).Error("")
 		return taModel.IdentityProofRequest{}, http.StatusInternalServerError, errors.Wrap(err, "controllers/certify_host_aiks_controller:getIdentityProofRequestResponse() Error while generating identityProofRequest")
@@ -319,7 +344,7 @@ func (certifyHostAiksController *CertifyHostAiksController) getIdentityProofRequ
 	return proofReq, http.StatusOK, nil
 }
 
-func (certifyHostAiksController *CertifyHostAiksController) CertifyAik(aikPubKey *rsa.PublicKey, aikName []byte, privacycaKey *rsa.PrivateKey, privacycaCert *x509.Certificate, validity int) ([]byte, error) {
+func (certifyHostAiksController *CertifyHostAiksController) CertifyAik(aikPubKey crypto.PublicKey, aikName []byte, privacycaKey crypto.Signer, privacycaCert *x509.Certificate, validity int) ([]byte, error) {
 	defaultLog.Trace("controllers/certify_host_aiks_controller:CertifyAik() Entering")
 	defer defaultLog.Trace("controllers/certify_host_aiks_controller:CertifyAik() Leaving")
 
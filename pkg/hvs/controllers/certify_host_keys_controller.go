@@ -7,8 +7,6 @@ package controllers
 
 import (
 	"crypto"
-	"crypto/rsa"
-	"crypto/sha256"
 	"crypto/x509"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
@@ -21,7 +19,6 @@ import (
 
 	"encoding/json"
 	consts "github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
-	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	"net/http"
 )
 
@@ -130,9 +127,9 @@ func (certifyHostKeysController *CertifyHostKeysController) generateCertificate(
 		return nil, errors.New("controllers/certify_host_keys_controller:generateCertificate() Error verifying the AIK signature against the Privacy CA"), http.StatusBadRequest
 	}
 
-	rsaPubKey, err := certifyKey20.GetPublicKeyFromModulus()
+	pubKey, err := certifyKey20.GetPublicKey()
 	if err != nil {
-		return nil, errors.Wrap(err, "controllers/certify_host_keys_controller:generateCertificate() Error while retrieving public key modulus"), http.StatusBadRequest
+		return nil, errors.Wrap(err, "controllers/certify_host_keys_controller:generateCertificate() Error while retrieving public key"), http.StatusBadRequest
 	}
 
 	status, err := certifyKey20.IsCertifiedKeySignatureValid(aikCert)
@@ -153,9 +150,12 @@ func (certifyHostKeysController *CertifyHostKeysController) generateCertificate(
 		return nil, errors.Wrap(err, "TPM Key Name specified does not match name digest in the TCG binding certificate"), http.StatusBadRequest
 	}
 	defaultLog.Info("controllers/certify_host_keys_controller:generateCertificate() TpmNameDigest validated successfully")
-	pcaKey := (*certifyHostKeysController.CertStore)[models.CaCertTypesPrivacyCa.String()].Key
+	pcaKey, ok := (*certifyHostKeysController.CertStore)[models.CaCertTypesPrivacyCa.String()].Key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("controllers/certify_host_keys_controller:generateCertificate() Privacy CA key is not a signing key"), http.StatusInternalServerError
+	}
 	pcaCert := (*certifyHostKeysController.CertStore)[models.CaCertTypesPrivacyCa.String()].Certificates
-	certificate, err := certifyKey20.CertifyKey(&pcaCert[0], rsaPubKey, pcaKey.(*rsa.PrivateKey), commName)
+	certificate, err := certifyKey20.CertifyKey(&pcaCert[0], pubKey, pcaKey, commName)
 	if err != nil {
 		return nil, errors.Wrapf(err, "controllers/certify_host_keys_controller:generateCertificate() Error while Certifying key"), http.StatusInternalServerError
 	}
@@ -168,21 +168,7 @@ func (certifyHostKeysController *CertifyHostKeysController) isAikCertifiedByPriv
 	defer defaultLog.Trace("controllers/certify_host_keys_controller:isAikCertifiedByPrivacyCA() Leaving")
 
 	pcaCert := (*certifyHostKeysController.CertStore)[models.CaCertTypesPrivacyCa.String()].Certificates
-	pubKey, err := crypt.GetPublicKeyFromCert(&pcaCert[0])
-	if err != nil {
-		defaultLog.WithError(err).Errorf("controllers/certify_host_keys_controller:isAikCertifiedByPrivacyCA() Could not get Public key from privacy ca certificate")
-		return false
-	}
-	rsaPublicKey := pubKey.(*rsa.PublicKey)
-
-	h := sha256.New()
-	_, err = h.Write(aikCert.RawTBSCertificate)
-	if err != nil {
-		defaultLog.WithError(err).Errorf("controllers/certify_host_keys_controller:isAikCertifiedByPrivacyCA() Could not write certificate")
-		return false
-	}
-	digest := h.Sum(nil)
-	err = rsa.VerifyPKCS1v15(rsaPublicKey, crypto.SHA256, digest, aikCert.Signature)
+	err := pcaCert[0].CheckSignature(aikCert.SignatureAlgorithm, aikCert.RawTBSCertificate, aikCert.Signature)
 	if err != nil {
 		defaultLog.WithError(err).Error("controllers/certify_host_keys_controller:isAikCertifiedByPrivacyCA() Error while verifying the AIK signature against the Privacy CA")
 		return false
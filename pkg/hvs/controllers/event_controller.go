@@ -0,0 +1,338 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/utils"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	commLogMsg "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/message"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+// HTTPMediaTypeEventStream is the media type of the server-sent events stream
+const HTTPMediaTypeEventStream = "text/event-stream"
+
+type EventController struct {
+	Store     domain.EventSubscriptionStore
+	Publisher domain.EventPublisher
+	// KeepAliveInterval is the interval of the comments sent on an idle event stream
+	KeepAliveInterval time.Duration
+}
+
+func NewEventController(store domain.EventSubscriptionStore, publisher domain.EventPublisher) *EventController {
+	return &EventController{
+		Store:             store,
+		Publisher:         publisher,
+		KeepAliveInterval: consts.EventStreamKeepAliveInterval,
+	}
+}
+
+var eventSubscriptionSearchParams = map[string]bool{"id": true, "eventType": true}
+var eventStreamParams = map[string]bool{"eventType": true}
+
+func (controller EventController) CreateSubscription(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/event_controller:CreateSubscription() Entering")
+	defer defaultLog.Trace("controllers/event_controller:CreateSubscription() Leaving")
+
+	reqSubscription, status, err := decodeEventSubscription(r)
+	if err != nil {
+		return nil, status, err
+	}
+
+	if reqSubscription.URL == "" || reqSubscription.Secret == "" {
+		secLog.Errorf("controllers/event_controller:CreateSubscription() %s : url and secret must be specified", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "url and secret must be specified"}
+	}
+
+	if err := validateEventSubscription(reqSubscription); err != nil {
+		secLog.WithError(err).Errorf("controllers/event_controller:CreateSubscription() %s", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	createdSubscription, err := controller.Store.Create(reqSubscription)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/event_controller:CreateSubscription() Event subscription create failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to create event subscription"}
+	}
+	createdSubscription.Secret = ""
+
+	secLog.WithField("url", createdSubscription.URL).Infof("%s: Event subscription %s created by: %s", commLogMsg.PrivilegeModified, createdSubscription.ID, r.RemoteAddr)
+	return createdSubscription, http.StatusCreated, nil
+}
+
+func (controller EventController) RetrieveSubscription(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/event_controller:RetrieveSubscription() Entering")
+	defer defaultLog.Trace("controllers/event_controller:RetrieveSubscription() Leaving")
+
+	id := uuid.MustParse(mux.Vars(r)["id"])
+	subscription, status, err := controller.retrieveSubscription(id)
+	if err != nil {
+		return nil, status, err
+	}
+	subscription.Secret = ""
+
+	secLog.Infof("%s: Event subscription %s retrieved by: %s", commLogMsg.AuthorizedAccess, id, r.RemoteAddr)
+	return subscription, http.StatusOK, nil
+}
+
+func (controller EventController) UpdateSubscription(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/event_controller:UpdateSubscription() Entering")
+	defer defaultLog.Trace("controllers/event_controller:UpdateSubscription() Leaving")
+
+	id := uuid.MustParse(mux.Vars(r)["id"])
+	reqSubscription, status, err := decodeEventSubscription(r)
+	if err != nil {
+		return nil, status, err
+	}
+
+	if reqSubscription.ID != uuid.Nil && reqSubscription.ID != id {
+		secLog.Errorf("controllers/event_controller:UpdateSubscription() %s : Subscription ID in body does not match the one in the path", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Event subscription ID in request body does not match the one in the path"}
+	}
+
+	subscription, status, err := controller.retrieveSubscription(id)
+	if err != nil {
+		return nil, status, err
+	}
+
+	// the secret is left unchanged unless a new one is provided
+	subscription.Secret = reqSubscription.Secret
+	if reqSubscription.URL != "" {
+		subscription.URL = reqSubscription.URL
+	}
+	if reqSubscription.EventTypes != nil {
+		subscription.EventTypes = reqSubscription.EventTypes
+	}
+
+	if err := validateEventSubscription(subscription); err != nil {
+		secLog.WithError(err).Errorf("controllers/event_controller:UpdateSubscription() %s", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	updatedSubscription, err := controller.Store.Update(subscription)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/event_controller:UpdateSubscription() Event subscription update failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to update event subscription"}
+	}
+	updatedSubscription.Secret = ""
+
+	secLog.WithField("url", updatedSubscription.URL).Infof("%s: Event subscription %s updated by: %s", commLogMsg.PrivilegeModified, id, r.RemoteAddr)
+	return updatedSubscription, http.StatusOK, nil
+}
+
+func (controller EventController) DeleteSubscription(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/event_controller:DeleteSubscription() Entering")
+	defer defaultLog.Trace("controllers/event_controller:DeleteSubscription() Leaving")
+
+	id := uuid.MustParse(mux.Vars(r)["id"])
+	if _, status, err := controller.retrieveSubscription(id); err != nil {
+		return nil, status, err
+	}
+
+	if err := controller.Store.Delete(id); err != nil {
+		defaultLog.WithError(err).WithField("id", id).Error("controllers/event_controller:DeleteSubscription() Event subscription delete failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to delete event subscription"}
+	}
+
+	secLog.WithField("id", id).Infof("%s: Event subscription deleted by: %s", commLogMsg.PrivilegeModified, r.RemoteAddr)
+	return nil, http.StatusNoContent, nil
+}
+
+func (controller EventController) SearchSubscriptions(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/event_controller:SearchSubscriptions() Entering")
+	defer defaultLog.Trace("controllers/event_controller:SearchSubscriptions() Leaving")
+
+	if err := utils.ValidateQueryParams(r.URL.Query(), eventSubscriptionSearchParams); err != nil {
+		secLog.Errorf("controllers/event_controller:SearchSubscriptions() %s", err.Error())
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	criteria := &models.EventSubscriptionFilterCriteria{}
+	if id := strings.TrimSpace(r.URL.Query().Get("id")); id != "" {
+		parsedId, err := uuid.Parse(id)
+		if err != nil {
+			secLog.WithError(err).Errorf("controllers/event_controller:SearchSubscriptions() %s : Invalid id query param value", commLogMsg.InvalidInputBadParam)
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid id query param value, must be UUID"}
+		}
+		criteria.Id = parsedId
+	}
+	if eventType := strings.TrimSpace(r.URL.Query().Get("eventType")); eventType != "" {
+		if err := validateEventTypes([]string{eventType}); err != nil {
+			secLog.WithError(err).Errorf("controllers/event_controller:SearchSubscriptions() %s", commLogMsg.InvalidInputBadParam)
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+		}
+		criteria.EventType = eventType
+	}
+
+	subscriptions, err := controller.Store.Search(criteria)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/event_controller:SearchSubscriptions() Event subscription search failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to search event subscriptions"}
+	}
+
+	collection := hvs.EventSubscriptionCollection{EventSubscriptions: []*hvs.EventSubscription{}}
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+		collection.EventSubscriptions = append(collection.EventSubscriptions, &subscriptions[i])
+	}
+
+	secLog.Infof("%s: Event subscriptions searched by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return collection, http.StatusOK, nil
+}
+
+// Stream serves the trust events as server-sent events until the client disconnects
+func (controller EventController) Stream(w http.ResponseWriter, r *http.Request) error {
+	defaultLog.Trace("controllers/event_controller:Stream() Entering")
+	defer defaultLog.Trace("controllers/event_controller:Stream() Leaving")
+
+	if r.Header.Get("Accept") != HTTPMediaTypeEventStream {
+		return &commErr.HandledError{StatusCode: http.StatusUnsupportedMediaType, Message: "Invalid Accept type"}
+	}
+
+	if err := utils.ValidateQueryParams(r.URL.Query(), eventStreamParams); err != nil {
+		secLog.Errorf("controllers/event_controller:Stream() %s", err.Error())
+		return &commErr.HandledError{StatusCode: http.StatusBadRequest, Message: err.Error()}
+	}
+
+	var eventTypes []string
+	if eventType := strings.TrimSpace(r.URL.Query().Get("eventType")); eventType != "" {
+		eventTypes = []string{eventType}
+		if err := validateEventTypes(eventTypes); err != nil {
+			secLog.WithError(err).Errorf("controllers/event_controller:Stream() %s", commLogMsg.InvalidInputBadParam)
+			return &commErr.HandledError{StatusCode: http.StatusBadRequest, Message: err.Error()}
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		defaultLog.Error("controllers/event_controller:Stream() Response writer does not support streaming")
+		return &commErr.HandledError{StatusCode: http.StatusInternalServerError, Message: "Streaming is not supported"}
+	}
+
+	// the event stream outlives the write timeout of the server
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		defaultLog.WithError(err).Error("controllers/event_controller:Stream() Failed to clear the write deadline")
+		return &commErr.HandledError{StatusCode: http.StatusInternalServerError, Message: "Streaming is not supported"}
+	}
+
+	keepAliveInterval := controller.KeepAliveInterval
+	if keepAliveInterval <= 0 {
+		keepAliveInterval = consts.EventStreamKeepAliveInterval
+	}
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	events, unregister := controller.Publisher.Listen(eventTypes)
+	defer unregister()
+
+	w.Header().Set("Content-Type", HTTPMediaTypeEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	secLog.Infof("%s: Event stream opened by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	for {
+		select {
+		case <-r.Context().Done():
+			defaultLog.Debugf("controllers/event_controller:Stream() Event stream closed by: %s", r.RemoteAddr)
+			return nil
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				defaultLog.WithError(err).Debugf("controllers/event_controller:Stream() Failed to write to event stream of: %s", r.RemoteAddr)
+				return nil
+			}
+			flusher.Flush()
+		case e, ok := <-events:
+			if !ok {
+				// the publisher is shutting down
+				return nil
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				defaultLog.WithError(err).Errorf("controllers/event_controller:Stream() Failed to marshal event %s", e.ID)
+				continue
+			}
+			if _, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data); err != nil {
+				defaultLog.WithError(err).Debugf("controllers/event_controller:Stream() Failed to write to event stream of: %s", r.RemoteAddr)
+				return nil
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (controller EventController) retrieveSubscription(id uuid.UUID) (*hvs.EventSubscription, int, error) {
+	subscription, err := controller.Store.Retrieve(id)
+	if err != nil {
+		if strings.Contains(err.Error(), commErr.RowsNotFound) {
+			secLog.WithError(err).WithField("id", id).Error(
+				"controllers/event_controller:retrieveSubscription() Event subscription with given ID does not exist")
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Event subscription with given ID does not exist"}
+		}
+		defaultLog.WithError(err).WithField("id", id).Error(
+			"controllers/event_controller:retrieveSubscription() Failed to retrieve event subscription")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve event subscription"}
+	}
+	return subscription, http.StatusOK, nil
+}
+
+func decodeEventSubscription(r *http.Request) (*hvs.EventSubscription, int, error) {
+	if r.Header.Get("Content-Type") != constants.HTTPMediaTypeJson {
+		return nil, http.StatusUnsupportedMediaType, &commErr.ResourceError{Message: "Invalid Content-Type"}
+	}
+
+	if r.ContentLength == 0 {
+		secLog.Error("controllers/event_controller:decodeEventSubscription() The request body is not provided")
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body is not provided"}
+	}
+
+	var subscription hvs.EventSubscription
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&subscription); err != nil {
+		secLog.WithError(err).Errorf("controllers/event_controller:decodeEventSubscription() %s : Failed to decode request body as EventSubscription", commLogMsg.InvalidInputBadEncoding)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Unable to decode JSON request body"}
+	}
+	return &subscription, http.StatusOK, nil
+}
+
+func validateEventSubscription(subscription *hvs.EventSubscription) error {
+	webhookURL, err := url.Parse(subscription.URL)
+	if err != nil || webhookURL.Scheme != "https" || webhookURL.Host == "" || webhookURL.User != nil {
+		return errors.New("url must be a valid https URL")
+	}
+	return validateEventTypes(subscription.EventTypes)
+}
+
+func validateEventTypes(eventTypes []string) error {
+	for _, eventType := range eventTypes {
+		valid := false
+		for _, t := range hvs.TrustEventTypes {
+			if eventType == t {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return errors.Errorf("event type must be one of %s", strings.Join(hvs.TrustEventTypes, ", "))
+		}
+	}
+	return nil
+}
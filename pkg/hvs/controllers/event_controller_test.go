@@ -0,0 +1,312 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package controllers_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/mocks"
+	hvsRoutes "github.com/intel-secl/intel-secl/v4/pkg/hvs/router"
+	consts "github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EventController", func() {
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+	var eventSubscriptionStore *mocks.MockEventSubscriptionStore
+	var eventPublisher *mocks.MockEventPublisher
+	var eventController *controllers.EventController
+
+	BeforeEach(func() {
+		router = mux.NewRouter()
+		eventSubscriptionStore = mocks.NewMockEventSubscriptionStore()
+		eventPublisher = mocks.NewMockEventPublisher()
+		eventController = controllers.NewEventController(eventSubscriptionStore, eventPublisher)
+	})
+
+	// Specs for HTTP Post to "/event-subscriptions"
+	Describe("Create a new event subscription", func() {
+		Context("Provide a valid event subscription", func() {
+			It("Should create the subscription without returning the secret", func() {
+				router.Handle("/event-subscriptions", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(eventController.CreateSubscription))).Methods("POST")
+				subscriptionJson := `{
+					"url": "https://ticketing.example.com/webhooks/hvs",
+					"secret": "s3cr3t",
+					"event_types": ["host-state-changed"]
+				}`
+				req, err := http.NewRequest("POST", "/event-subscriptions", strings.NewReader(subscriptionJson))
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusCreated))
+
+				var subscription hvs.EventSubscription
+				err = json.Unmarshal(w.Body.Bytes(), &subscription)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(subscription.ID).NotTo(Equal(uuid.Nil))
+				Expect(subscription.Secret).To(BeEmpty())
+
+				stored, err := eventSubscriptionStore.Retrieve(subscription.ID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stored.Secret).To(Equal("s3cr3t"))
+			})
+		})
+
+		Context("Provide an event subscription with a plain http URL", func() {
+			It("Should get HTTP Status: 400", func() {
+				router.Handle("/event-subscriptions", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(eventController.CreateSubscription))).Methods("POST")
+				subscriptionJson := `{"url": "http://ticketing.example.com/webhooks/hvs", "secret": "s3cr3t"}`
+				req, err := http.NewRequest("POST", "/event-subscriptions", strings.NewReader(subscriptionJson))
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("Provide an event subscription without secret", func() {
+			It("Should get HTTP Status: 400", func() {
+				router.Handle("/event-subscriptions", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(eventController.CreateSubscription))).Methods("POST")
+				subscriptionJson := `{"url": "https://ticketing.example.com/webhooks/hvs"}`
+				req, err := http.NewRequest("POST", "/event-subscriptions", strings.NewReader(subscriptionJson))
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("Provide an event subscription with an unknown event type", func() {
+			It("Should get HTTP Status: 400", func() {
+				router.Handle("/event-subscriptions", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(eventController.CreateSubscription))).Methods("POST")
+				subscriptionJson := `{"url": "https://ticketing.example.com/webhooks/hvs", "secret": "s3cr3t", "event_types": ["flavor-created"]}`
+				req, err := http.NewRequest("POST", "/event-subscriptions", strings.NewReader(subscriptionJson))
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	// Specs for HTTP Get to "/event-subscriptions"
+	Describe("Search event subscriptions", func() {
+		Context("Search the subscriptions receiving the host-state-changed events", func() {
+			It("Should only return the subscription to all the events", func() {
+				router.Handle("/event-subscriptions", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(eventController.SearchSubscriptions))).Methods("GET")
+				req, err := http.NewRequest("GET", "/event-subscriptions?eventType=host-state-changed", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var collection hvs.EventSubscriptionCollection
+				err = json.Unmarshal(w.Body.Bytes(), &collection)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(collection.EventSubscriptions)).To(Equal(1))
+				Expect(collection.EventSubscriptions[0].EventTypes).To(BeEmpty())
+				Expect(collection.EventSubscriptions[0].Secret).To(BeEmpty())
+			})
+		})
+
+		Context("Search with an unknown query parameter", func() {
+			It("Should get HTTP Status: 400", func() {
+				router.Handle("/event-subscriptions", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(eventController.SearchSubscriptions))).Methods("GET")
+				req, err := http.NewRequest("GET", "/event-subscriptions?urlContains=example", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	// Specs for HTTP Get to "/event-subscriptions/{id}"
+	Describe("Retrieve an event subscription", func() {
+		Context("Retrieve an existing subscription", func() {
+			It("Should return the subscription without the secret", func() {
+				router.Handle("/event-subscriptions/{id}", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(eventController.RetrieveSubscription))).Methods("GET")
+				req, err := http.NewRequest("GET", "/event-subscriptions/1b3b8c3d-5c7a-4e6f-8b2d-3a2f4e5d6c7b", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(w.Body.String()).NotTo(ContainSubstring("trust-events-secret"))
+			})
+		})
+
+		Context("Retrieve a subscription that does not exist", func() {
+			It("Should get HTTP Status: 404", func() {
+				router.Handle("/event-subscriptions/{id}", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(eventController.RetrieveSubscription))).Methods("GET")
+				req, err := http.NewRequest("GET", "/event-subscriptions/73755fda-c910-46be-821f-e8ddeab189e9", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	// Specs for HTTP Put to "/event-subscriptions/{id}"
+	Describe("Update an event subscription", func() {
+		Context("Update the event types of an existing subscription", func() {
+			It("Should keep the secret and get HTTP Status: 200", func() {
+				router.Handle("/event-subscriptions/{id}", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(eventController.UpdateSubscription))).Methods("PUT")
+				subscriptionJson := `{"event_types": ["host-trust-changed", "host-state-changed"]}`
+				req, err := http.NewRequest("PUT", "/event-subscriptions/1b3b8c3d-5c7a-4e6f-8b2d-3a2f4e5d6c7b", strings.NewReader(subscriptionJson))
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				stored, err := eventSubscriptionStore.Retrieve(uuid.MustParse("1b3b8c3d-5c7a-4e6f-8b2d-3a2f4e5d6c7b"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stored.EventTypes).To(HaveLen(2))
+				Expect(stored.Secret).To(Equal("trust-events-secret"))
+			})
+		})
+
+		Context("Update a subscription that does not exist", func() {
+			It("Should get HTTP Status: 404", func() {
+				router.Handle("/event-subscriptions/{id}", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(eventController.UpdateSubscription))).Methods("PUT")
+				subscriptionJson := `{"url": "https://ticketing.example.com/webhooks/hvs"}`
+				req, err := http.NewRequest("PUT", "/event-subscriptions/73755fda-c910-46be-821f-e8ddeab189e9", strings.NewReader(subscriptionJson))
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	// Specs for HTTP Delete to "/event-subscriptions/{id}"
+	Describe("Delete an event subscription", func() {
+		Context("Delete an existing subscription", func() {
+			It("Should get HTTP Status: 204", func() {
+				router.Handle("/event-subscriptions/{id}", hvsRoutes.ErrorHandler(hvsRoutes.ResponseHandler(eventController.DeleteSubscription))).Methods("DELETE")
+				req, err := http.NewRequest("DELETE", "/event-subscriptions/0a2a7b2c-4b6f-4d5e-9a1c-2f1e3d4c5b6a", nil)
+				Expect(err).NotTo(HaveOccurred())
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNoContent))
+			})
+		})
+
+		Context("Delete a subscription that does not exist", func() {
+			It("Should get HTTP Status: 404", func() {
+				router.Handle("/event-subscriptions/{id}", hvsRoutes.ErrorHandler(hvsRoutes.ResponseHandler(eventController.DeleteSubscription))).Methods("DELETE")
+				req, err := http.NewRequest("DELETE", "/event-subscriptions/73755fda-c910-46be-821f-e8ddeab189e9", nil)
+				Expect(err).NotTo(HaveOccurred())
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	// Specs for HTTP Get to "/events/stream"
+	Describe("Stream the trust events", func() {
+		Context("Listen to the host-trust-changed events", func() {
+			It("Should stream the matching events as server-sent events", func() {
+				trusted := false
+				eventPublisher.Publish(&hvs.TrustEvent{
+					ID:      uuid.MustParse("5a6b7c8d-1e2f-4a3b-9c4d-5e6f7a8b9c0d"),
+					Type:    hvs.TrustEventHostTrustChanged,
+					HostID:  uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2"),
+					Trusted: &trusted,
+				})
+				eventPublisher.Publish(&hvs.TrustEvent{
+					ID:        uuid.MustParse("6b7c8d9e-2f3a-4b4c-8d5e-6f7a8b9c0d1e"),
+					Type:      hvs.TrustEventHostStateChanged,
+					HostID:    uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2"),
+					HostState: hvs.HostStateConnectionFailure.String(),
+				})
+				eventPublisher.Stop()
+
+				router.Handle("/events/stream", hvsRoutes.ErrorHandler(eventController.Stream)).Methods("GET")
+				req, err := http.NewRequest("GET", "/events/stream?eventType=host-trust-changed", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", controllers.HTTPMediaTypeEventStream)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(w.Header().Get("Content-Type")).To(Equal(controllers.HTTPMediaTypeEventStream))
+				Expect(w.Body.String()).To(HavePrefix("id: 5a6b7c8d-1e2f-4a3b-9c4d-5e6f7a8b9c0d\nevent: host-trust-changed\ndata: {"))
+				Expect(w.Body.String()).NotTo(ContainSubstring(hvs.TrustEventHostStateChanged))
+			})
+		})
+
+		Context("Keep an idle event stream open", func() {
+			It("Should send keepalive comments past the write timeout of the server", func() {
+				eventController.KeepAliveInterval = 10 * time.Millisecond
+				router.Handle("/events/stream", hvsRoutes.ErrorHandler(eventController.Stream)).Methods("GET")
+				server := httptest.NewUnstartedServer(router)
+				server.Config.WriteTimeout = 50 * time.Millisecond
+				server.Start()
+				defer server.Close()
+
+				req, err := http.NewRequest("GET", server.URL+"/events/stream", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", controllers.HTTPMediaTypeEventStream)
+				client := &http.Client{Timeout: 5 * time.Second}
+				rsp, err := client.Do(req)
+				Expect(err).NotTo(HaveOccurred())
+				defer rsp.Body.Close()
+				Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+
+				// 20 keepalives take twice the write timeout of the server
+				scanner := bufio.NewScanner(rsp.Body)
+				keepAlives := 0
+				for keepAlives < 20 && scanner.Scan() {
+					if scanner.Text() == ": keepalive" {
+						keepAlives++
+					}
+				}
+				Expect(scanner.Err()).NotTo(HaveOccurred())
+				Expect(keepAlives).To(Equal(20))
+			})
+		})
+
+		Context("Request the event stream with a JSON Accept type", func() {
+			It("Should get HTTP Status: 415", func() {
+				router.Handle("/events/stream", hvsRoutes.ErrorHandler(eventController.Stream)).Methods("GET")
+				req, err := http.NewRequest("GET", "/events/stream", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusUnsupportedMediaType))
+			})
+		})
+	})
+})
@@ -0,0 +1,605 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package controllers
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	dm "github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/utils"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	commLogMsg "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/message"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
+	fc "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/common"
+	fm "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/model"
+	fu "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/util"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+// FlavorBundleController copies flavorgroups, flavor templates and flavors between HVS instances as signed bundles
+type FlavorBundleController struct {
+	FlavorController FlavorController
+	// FlavorCACertificates are the CAs the signing certificate of an imported bundle must be issued by
+	FlavorCACertificates *x509.CertPool
+}
+
+var flavorBundleExportParams = map[string]bool{"flavorgroupName": true}
+
+func NewFlavorBundleController(fc FlavorController, flavorCACertificates *x509.CertPool) *FlavorBundleController {
+	return &FlavorBundleController{
+		FlavorController:     fc,
+		FlavorCACertificates: flavorCACertificates,
+	}
+}
+
+// Export returns the given flavorgroups, all of them by default, along with their flavor templates and the flavors
+// in effect as a bundle signed with the flavor signing key. The staged and revoked flavors are not exported.
+func (controller FlavorBundleController) Export(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/flavor_bundle_controller:Export() Entering")
+	defer defaultLog.Trace("controllers/flavor_bundle_controller:Export() Leaving")
+
+	if err := utils.ValidateQueryParams(r.URL.Query(), flavorBundleExportParams); err != nil {
+		secLog.Errorf("controllers/flavor_bundle_controller:Export() %s", err.Error())
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+	flavorgroupNames := r.URL.Query()["flavorgroupName"]
+	if err := validation.ValidateStrings(flavorgroupNames); err != nil {
+		secLog.WithError(err).Errorf("controllers/flavor_bundle_controller:Export() %s : Invalid flavorgroup name", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Valid flavorgroup names must be specified"}
+	}
+
+	flavorgroups, status, err := controller.getFlavorgroupsToExport(flavorgroupNames)
+	if err != nil {
+		return nil, status, err
+	}
+
+	bundle, err := controller.buildFlavorBundle(flavorgroups)
+	if err != nil {
+		defaultLog.WithError(err).Errorf("controllers/flavor_bundle_controller:Export() %s : Error building the flavor bundle", commLogMsg.AppRuntimeErr)
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Error building the flavor bundle"}
+	}
+
+	signingKey, signingCertificates, err := (*controller.FlavorController.CertStore).GetKeyAndCertificates(dm.CertTypesFlavorSigning.String())
+	if err != nil {
+		defaultLog.WithError(err).Errorf("controllers/flavor_bundle_controller:Export() %s : Flavor signing key not found", commLogMsg.AppRuntimeErr)
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Error signing the flavor bundle"}
+	}
+	rsaKey, _ := signingKey.(*rsa.PrivateKey)
+	if err = bundle.Sign(rsaKey, signingCertificates, time.Now().UTC()); err != nil {
+		defaultLog.WithError(err).Errorf("controllers/flavor_bundle_controller:Export() %s : Error signing the flavor bundle", commLogMsg.AppRuntimeErr)
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Error signing the flavor bundle"}
+	}
+
+	secLog.Infof("%s: Flavor bundle with %d flavors exported by: %s", commLogMsg.PrivilegeModified, len(bundle.SignedFlavors), r.RemoteAddr)
+	return bundle, http.StatusOK, nil
+}
+
+func (controller FlavorBundleController) getFlavorgroupsToExport(names []string) ([]hvs.FlavorGroup, int, error) {
+	defaultLog.Trace("controllers/flavor_bundle_controller:getFlavorgroupsToExport() Entering")
+	defer defaultLog.Trace("controllers/flavor_bundle_controller:getFlavorgroupsToExport() Leaving")
+
+	fgStore := controller.FlavorController.FGStore
+	if len(names) == 0 {
+		flavorgroups, err := fgStore.Search(nil)
+		if err != nil {
+			defaultLog.WithError(err).Errorf("controllers/flavor_bundle_controller:getFlavorgroupsToExport() %s : Error searching flavorgroups", commLogMsg.AppRuntimeErr)
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Error searching flavorgroups"}
+		}
+		return flavorgroups, http.StatusOK, nil
+	}
+
+	var flavorgroups []hvs.FlavorGroup
+	exported := make(map[string]bool)
+	for _, name := range names {
+		if exported[name] {
+			continue
+		}
+		found, err := fgStore.Search(&dm.FlavorGroupFilterCriteria{NameEqualTo: name})
+		if err != nil {
+			defaultLog.WithError(err).Errorf("controllers/flavor_bundle_controller:getFlavorgroupsToExport() %s : Error searching flavorgroup %s", commLogMsg.AppRuntimeErr, name)
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Error searching flavorgroups"}
+		}
+		if len(found) == 0 {
+			secLog.Errorf("controllers/flavor_bundle_controller:getFlavorgroupsToExport() %s : Flavorgroup %s does not exist", commLogMsg.InvalidInputBadParam, name)
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Flavorgroup " + name + " does not exist"}
+		}
+		flavorgroups = append(flavorgroups, found[0])
+		exported[name] = true
+	}
+	return flavorgroups, http.StatusOK, nil
+}
+
+// buildFlavorBundle gathers the flavor templates and the flavors in effect linked to the flavorgroups
+func (controller FlavorBundleController) buildFlavorBundle(flavorgroups []hvs.FlavorGroup) (*hvs.FlavorBundle, error) {
+	defaultLog.Trace("controllers/flavor_bundle_controller:buildFlavorBundle() Entering")
+	defer defaultLog.Trace("controllers/flavor_bundle_controller:buildFlavorBundle() Leaving")
+
+	fcon := controller.FlavorController
+	sort.Slice(flavorgroups, func(i, j int) bool { return flavorgroups[i].Name < flavorgroups[j].Name })
+
+	var flavorIds, templateIds []uuid.UUID
+	flavorgroupFlavorIds := make(map[uuid.UUID][]uuid.UUID)
+	flavorgroupTemplateIds := make(map[uuid.UUID][]uuid.UUID)
+	seenFlavors := make(map[uuid.UUID]bool)
+	seenTemplates := make(map[uuid.UUID]bool)
+	for _, flavorgroup := range flavorgroups {
+		ids, err := fcon.FGStore.SearchFlavors(flavorgroup.ID)
+		if err != nil && !strings.Contains(err.Error(), commErr.RowsNotFound) {
+			return nil, errors.Wrapf(err, "Error retrieving the flavors linked to flavorgroup %s", flavorgroup.Name)
+		}
+		flavorgroupFlavorIds[flavorgroup.ID] = ids
+		for _, id := range ids {
+			if !seenFlavors[id] {
+				seenFlavors[id] = true
+				flavorIds = append(flavorIds, id)
+			}
+		}
+		ids, err = fcon.FGStore.SearchFlavorTemplatesByFlavorGroup(flavorgroup.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error retrieving the flavor templates linked to flavorgroup %s", flavorgroup.Name)
+		}
+		flavorgroupTemplateIds[flavorgroup.ID] = ids
+		for _, id := range ids {
+			if !seenTemplates[id] {
+				seenTemplates[id] = true
+				templateIds = append(templateIds, id)
+			}
+		}
+	}
+
+	bundle := &hvs.FlavorBundle{}
+
+	// only the flavors in effect are exported, the importing HVS creates active flavors
+	var exportedFlavorIds []uuid.UUID
+	if len(flavorIds) > 0 {
+		lifecycles, err := fcon.FStore.SearchLifecycles(flavorIds)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error retrieving the lifecycle of the flavors to export")
+		}
+		now := time.Now()
+		for _, lifecycle := range lifecycles {
+			if state := lifecycle.EffectiveState(now); state == hvs.FlavorStateActive || state == hvs.FlavorStateDeprecated {
+				exportedFlavorIds = append(exportedFlavorIds, lifecycle.FlavorId)
+			}
+		}
+	}
+	exportedFlavors := make(map[uuid.UUID]bool)
+	if len(exportedFlavorIds) > 0 {
+		signedFlavors, err := fcon.FStore.Search(&dm.FlavorVerificationFC{
+			FlavorFC: dm.FlavorFilterCriteria{Ids: exportedFlavorIds},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "Error retrieving the flavors to export")
+		}
+		sort.Slice(signedFlavors, func(i, j int) bool {
+			return signedFlavors[i].Flavor.Meta.ID.String() < signedFlavors[j].Flavor.Meta.ID.String()
+		})
+		for _, signedFlavor := range signedFlavors {
+			exportedFlavors[signedFlavor.Flavor.Meta.ID] = true
+		}
+		bundle.SignedFlavors = signedFlavors
+	}
+
+	exportedTemplates := make(map[uuid.UUID]bool)
+	for _, id := range templateIds {
+		template, err := fcon.FTStore.Retrieve(id, false)
+		if err != nil {
+			if _, ok := err.(*commErr.StatusNotFoundError); ok {
+				continue
+			}
+			return nil, errors.Wrapf(err, "Error retrieving flavor template %s", id)
+		}
+		exportedTemplates[id] = true
+		bundle.FlavorTemplates = append(bundle.FlavorTemplates, *template)
+	}
+
+	for _, flavorgroup := range flavorgroups {
+		exportedFlavorgroup := hvs.FlavorGroup{
+			ID:            flavorgroup.ID,
+			Name:          flavorgroup.Name,
+			MatchPolicies: flavorgroup.MatchPolicies,
+		}
+		for _, id := range flavorgroupFlavorIds[flavorgroup.ID] {
+			if exportedFlavors[id] {
+				exportedFlavorgroup.FlavorIds = append(exportedFlavorgroup.FlavorIds, id)
+			}
+		}
+		for _, id := range flavorgroupTemplateIds[flavorgroup.ID] {
+			if exportedTemplates[id] {
+				exportedFlavorgroup.FlavorTemplateIds = append(exportedFlavorgroup.FlavorTemplateIds, id)
+			}
+		}
+		bundle.FlavorGroups = append(bundle.FlavorGroups, exportedFlavorgroup)
+	}
+	return bundle, nil
+}
+
+// Import verifies a flavor bundle exported by another HVS and adds the flavorgroups, flavor templates and flavors
+// that do not exist yet. The imported flavors are signed again with the flavor signing key of this HVS.
+func (controller FlavorBundleController) Import(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/flavor_bundle_controller:Import() Entering")
+	defer defaultLog.Trace("controllers/flavor_bundle_controller:Import() Leaving")
+
+	if r.Header.Get("Content-Type") != constants.HTTPMediaTypeJson {
+		return nil, http.StatusUnsupportedMediaType, &commErr.ResourceError{Message: "Invalid Content-Type"}
+	}
+
+	if r.ContentLength == 0 {
+		secLog.Errorf("controllers/flavor_bundle_controller:Import() %s : The request body is not provided", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body is not provided"}
+	}
+
+	var bundle hvs.FlavorBundle
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&bundle); err != nil {
+		secLog.WithError(err).Errorf("controllers/flavor_bundle_controller:Import() %s : Failed to decode request body as flavor bundle", commLogMsg.InvalidInputBadEncoding)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Unable to decode JSON request body"}
+	}
+
+	signingCertificate, err := bundle.Verify(controller.FlavorCACertificates)
+	if err != nil {
+		secLog.WithError(err).Errorf("controllers/flavor_bundle_controller:Import() %s : Flavor bundle verification failed", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Flavor bundle verification failed"}
+	}
+	if err = validateFlavorBundle(&bundle, signingCertificate.PublicKey.(*rsa.PublicKey)); err != nil {
+		secLog.WithError(err).Errorf("controllers/flavor_bundle_controller:Import() %s : Invalid flavor bundle", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	result, err := controller.importFlavorBundle(&bundle)
+	if err != nil {
+		defaultLog.WithError(err).Errorf("controllers/flavor_bundle_controller:Import() %s : Error importing the flavor bundle", commLogMsg.AppRuntimeErr)
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Error importing the flavor bundle"}
+	}
+
+	secLog.Infof("%s: Flavor bundle signed by %s imported by: %s", commLogMsg.PrivilegeModified, signingCertificate.Subject.CommonName, r.RemoteAddr)
+	return result, http.StatusOK, nil
+}
+
+// validateFlavorBundle checks the content of a bundle whose manifest has been verified
+func validateFlavorBundle(bundle *hvs.FlavorBundle, publicKey *rsa.PublicKey) error {
+	defaultLog.Trace("controllers/flavor_bundle_controller:validateFlavorBundle() Entering")
+	defer defaultLog.Trace("controllers/flavor_bundle_controller:validateFlavorBundle() Leaving")
+
+	for _, flavorgroup := range bundle.FlavorGroups {
+		if flavorgroup.Name == "" {
+			return errors.New("Flavorgroup name must be specified")
+		}
+		if err := validation.ValidateStrings([]string{flavorgroup.Name}); err != nil {
+			return errors.New("Valid flavorgroup names must be specified")
+		}
+	}
+	for i := range bundle.FlavorTemplates {
+		if bundle.FlavorTemplates[i].ID == uuid.Nil {
+			return errors.New("Flavor template id must be specified")
+		}
+	}
+	for i := range bundle.SignedFlavors {
+		signedFlavor := &bundle.SignedFlavors[i]
+		if signedFlavor.Flavor.Meta.ID == uuid.Nil {
+			return errors.New("Flavor id must be specified")
+		}
+		if err := validateFlavorMetaContent(&signedFlavor.Flavor.Meta); err != nil {
+			return errors.Errorf("Invalid flavor content for flavor %s", signedFlavor.Flavor.Meta.ID)
+		}
+		if err := signedFlavor.Verify(publicKey); err != nil {
+			return errors.Errorf("Invalid signature for flavor %s", signedFlavor.Flavor.Meta.ID)
+		}
+	}
+	return nil
+}
+
+// importFlavorBundle adds the content of the bundle that does not exist yet. The flavor templates are matched by id
+// and skipped when they exist, a record with the same id and a different digest is reported as a conflict. The
+// flavors are matched by id and by content digest. The flavorgroups are matched by name, the existing ones keep
+// their match policies.
+func (controller FlavorBundleController) importFlavorBundle(bundle *hvs.FlavorBundle) (*hvs.FlavorBundleImportResult, error) {
+	defaultLog.Trace("controllers/flavor_bundle_controller:importFlavorBundle() Entering")
+	defer defaultLog.Trace("controllers/flavor_bundle_controller:importFlavorBundle() Leaving")
+
+	fcon := controller.FlavorController
+	result := &hvs.FlavorBundleImportResult{
+		Added:   []hvs.FlavorBundleImportEntry{},
+		Skipped: []hvs.FlavorBundleImportEntry{},
+	}
+
+	// flavor templates that can be linked to the flavorgroups, along with the ones that were just added
+	availableTemplates := make(map[uuid.UUID]bool)
+	addedTemplates := make(map[uuid.UUID]bool)
+	for i := range bundle.FlavorTemplates {
+		template := bundle.FlavorTemplates[i]
+		entry := hvs.FlavorBundleImportEntry{Type: hvs.FlavorBundleEntryFlavorTemplate, Id: template.ID, Name: template.Label}
+		existing, err := fcon.FTStore.Retrieve(template.ID, false)
+		if err != nil {
+			if _, ok := err.(*commErr.StatusNotFoundError); !ok {
+				return nil, errors.Wrapf(err, "Error retrieving flavor template %s", template.ID)
+			}
+			if _, err = fcon.FTStore.Create(&template); err != nil {
+				return nil, errors.Wrapf(err, "Error creating flavor template %s", template.ID)
+			}
+			availableTemplates[template.ID] = true
+			addedTemplates[template.ID] = true
+			result.Added = append(result.Added, entry)
+			continue
+		}
+		same, err := sameFlavorTemplateDigest(existing, &template)
+		if err != nil {
+			return nil, err
+		}
+		if same {
+			availableTemplates[template.ID] = true
+			entry.Reason = "Flavor template already exists"
+		} else {
+			entry.Reason = "A different flavor template exists with the same id"
+		}
+		result.Skipped = append(result.Skipped, entry)
+	}
+
+	// flavorgroups of this HVS by flavor id, along with the ones that were just added
+	flavorFlavorgroups := make(map[uuid.UUID][]hvs.FlavorGroup)
+	addedFlavorgroups := make(map[uuid.UUID]bool)
+	for _, flavorgroup := range bundle.FlavorGroups {
+		entry := hvs.FlavorBundleImportEntry{Type: hvs.FlavorBundleEntryFlavorgroup, Name: flavorgroup.Name}
+		existing, err := fcon.FGStore.Search(&dm.FlavorGroupFilterCriteria{NameEqualTo: flavorgroup.Name})
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error searching flavorgroup %s", flavorgroup.Name)
+		}
+		var local *hvs.FlavorGroup
+		var templateIds []uuid.UUID
+		if len(existing) > 0 {
+			local = &existing[0]
+			entry.Reason = "Flavorgroup already exists"
+			if !sameMatchPolicies(local.MatchPolicies, flavorgroup.MatchPolicies) {
+				entry.Reason = "Flavorgroup already exists with different match policies"
+			}
+			for _, id := range flavorgroup.FlavorTemplateIds {
+				if addedTemplates[id] {
+					templateIds = append(templateIds, id)
+				}
+			}
+		} else {
+			local, err = fcon.FGStore.Create(&hvs.FlavorGroup{
+				Name:          flavorgroup.Name,
+				MatchPolicies: flavorgroup.MatchPolicies,
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "Error creating flavorgroup %s", flavorgroup.Name)
+			}
+			addedFlavorgroups[local.ID] = true
+			for _, id := range flavorgroup.FlavorTemplateIds {
+				if availableTemplates[id] {
+					templateIds = append(templateIds, id)
+				}
+			}
+		}
+		if len(templateIds) > 0 {
+			if err = fcon.FGStore.AddFlavorTemplates(local.ID, templateIds); err != nil {
+				return nil, errors.Wrapf(err, "Error linking flavor templates to flavorgroup %s", flavorgroup.Name)
+			}
+		}
+		entry.Id = local.ID
+		if entry.Reason == "" {
+			result.Added = append(result.Added, entry)
+		} else {
+			result.Skipped = append(result.Skipped, entry)
+		}
+		for _, id := range flavorgroup.FlavorIds {
+			flavorFlavorgroups[id] = append(flavorFlavorgroups[id], *local)
+		}
+	}
+
+	signingKey, _, err := (*fcon.CertStore).GetKeyAndCertificates(dm.CertTypesFlavorSigning.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "Error retrieving the flavor signing key")
+	}
+	rsaKey, _ := signingKey.(*rsa.PrivateKey)
+
+	// the flavors linked to the same flavorgroups are added together
+	flavorsToAdd := make(map[string]map[fc.FlavorPart][]hvs.SignedFlavor)
+	flavorgroupsToLink := make(map[string][]hvs.FlavorGroup)
+	digests := make(flavorDigestIndex)
+	for i := range bundle.SignedFlavors {
+		flavor := bundle.SignedFlavors[i].Flavor
+		label, _ := flavor.Meta.Description[fm.Label].(string)
+		entry := hvs.FlavorBundleImportEntry{Type: hvs.FlavorBundleEntryFlavor, Id: flavor.Meta.ID, Name: label}
+		flavorgroups := flavorFlavorgroups[flavor.Meta.ID]
+
+		skipped, err := controller.reconcileFlavor(&flavor, label, flavorgroups, digests, &entry)
+		if err != nil {
+			return nil, err
+		}
+		if skipped {
+			result.Skipped = append(result.Skipped, entry)
+			continue
+		}
+
+		signedFlavor, err := fu.PlatformFlavorUtil{}.GetSignedFlavor(&flavor, rsaKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error signing flavor %s", flavor.Meta.ID)
+		}
+		var flavorPart fc.FlavorPart
+		_ = (&flavorPart).Parse(flavor.Meta.Description[fm.FlavorPart].(string))
+		groupKey := flavorgroupsKey(flavorgroups)
+		if _, ok := flavorsToAdd[groupKey]; !ok {
+			flavorsToAdd[groupKey] = make(map[fc.FlavorPart][]hvs.SignedFlavor)
+			flavorgroupsToLink[groupKey] = flavorgroups
+		}
+		flavorsToAdd[groupKey][flavorPart] = append(flavorsToAdd[groupKey][flavorPart], *signedFlavor)
+	}
+
+	for groupKey, flavorFlavorPartMap := range flavorsToAdd {
+		signedFlavors, err := fcon.addFlavorToFlavorgroup(flavorFlavorPartMap, flavorgroupsToLink[groupKey], nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error adding flavors")
+		}
+		for _, signedFlavor := range signedFlavors {
+			label, _ := signedFlavor.Flavor.Meta.Description[fm.Label].(string)
+			result.Added = append(result.Added, hvs.FlavorBundleImportEntry{
+				Type: hvs.FlavorBundleEntryFlavor,
+				Id:   signedFlavor.Flavor.Meta.ID,
+				Name: label,
+			})
+		}
+	}
+	return result, nil
+}
+
+// flavorDigestIndex maps the content digests of the flavors linked to the flavorgroups of this HVS onto their id,
+// by flavorgroup id
+type flavorDigestIndex map[uuid.UUID]map[string]uuid.UUID
+
+// reconcileFlavor tells if an imported flavor must be skipped because the same flavor exists, or a different flavor
+// exists with the same id or label. The flavors of the flavorgroups of the bundle are matched on their content
+// digest whatever their id and label. An existing flavor with the same content is linked to the flavorgroups of the
+// bundle it is missing from.
+func (controller FlavorBundleController) reconcileFlavor(flavor *hvs.Flavor, label string, flavorgroups []hvs.FlavorGroup, digests flavorDigestIndex, entry *hvs.FlavorBundleImportEntry) (bool, error) {
+	defaultLog.Trace("controllers/flavor_bundle_controller:reconcileFlavor() Entering")
+	defer defaultLog.Trace("controllers/flavor_bundle_controller:reconcileFlavor() Leaving")
+
+	fcon := controller.FlavorController
+	existingId := uuid.Nil
+	existing, err := fcon.FStore.Retrieve(flavor.Meta.ID)
+	if err != nil && !strings.Contains(err.Error(), commErr.RowsNotFound) {
+		return false, errors.Wrapf(err, "Error retrieving flavor %s", flavor.Meta.ID)
+	}
+	if existing != nil {
+		importedDigest, err := hvs.FlavorDigest(flavor)
+		if err != nil {
+			return false, errors.Wrapf(err, "Error computing the digest of flavor %s", flavor.Meta.ID)
+		}
+		existingDigest, err := hvs.FlavorDigest(&existing.Flavor)
+		if err != nil {
+			return false, errors.Wrapf(err, "Error computing the digest of flavor %s", flavor.Meta.ID)
+		}
+		if importedDigest != existingDigest {
+			entry.Reason = "A different flavor exists with the same id"
+			return true, nil
+		}
+		existingId = flavor.Meta.ID
+	} else {
+		contentDigest, err := flavorContentDigest(flavor)
+		if err != nil {
+			return false, errors.Wrapf(err, "Error computing the digest of flavor %s", flavor.Meta.ID)
+		}
+		for _, flavorgroup := range flavorgroups {
+			existingId, err = controller.lookupFlavorDigest(digests, flavorgroup, contentDigest)
+			if err != nil {
+				return false, err
+			}
+			if existingId != uuid.Nil {
+				break
+			}
+		}
+	}
+
+	if existingId == uuid.Nil {
+		sameLabel, err := fcon.FStore.Search(&dm.FlavorVerificationFC{
+			FlavorFC: dm.FlavorFilterCriteria{Key: fm.Label, Value: label},
+		})
+		if err != nil {
+			return false, errors.Wrapf(err, "Error searching flavors with label %s", label)
+		}
+		if len(sameLabel) > 0 {
+			entry.Reason = "A different flavor exists with the same label"
+			return true, nil
+		}
+		return false, nil
+	}
+
+	entry.Id = existingId
+	entry.Reason = "Flavor already exists"
+	for _, flavorgroup := range flavorgroups {
+		_, err := fcon.FGStore.RetrieveFlavor(flavorgroup.ID, existingId)
+		if err == nil {
+			continue
+		}
+		if !strings.Contains(err.Error(), commErr.RowsNotFound) {
+			return false, errors.Wrapf(err, "Error retrieving the link of flavor %s to flavorgroup %s", existingId, flavorgroup.Name)
+		}
+		if _, err = fcon.FGStore.AddFlavors(flavorgroup.ID, []uuid.UUID{existingId}); err != nil {
+			return false, errors.Wrapf(err, "Error linking flavor %s to flavorgroup %s", existingId, flavorgroup.Name)
+		}
+	}
+	return true, nil
+}
+
+// lookupFlavorDigest returns the id of the flavor of the flavorgroup with the given content digest, uuid.Nil when
+// there is none. The flavors of a flavorgroup are indexed the first time it is looked up.
+func (controller FlavorBundleController) lookupFlavorDigest(index flavorDigestIndex, flavorgroup hvs.FlavorGroup, digest string) (uuid.UUID, error) {
+	fcon := controller.FlavorController
+	flavorgroupDigests, ok := index[flavorgroup.ID]
+	if !ok {
+		flavorgroupDigests = make(map[string]uuid.UUID)
+		ids, err := fcon.FGStore.SearchFlavors(flavorgroup.ID)
+		if err != nil && !strings.Contains(err.Error(), commErr.RowsNotFound) {
+			return uuid.Nil, errors.Wrapf(err, "Error retrieving the flavors linked to flavorgroup %s", flavorgroup.Name)
+		}
+		if len(ids) > 0 {
+			flavors, err := fcon.FStore.Search(&dm.FlavorVerificationFC{FlavorFC: dm.FlavorFilterCriteria{Ids: ids}})
+			if err != nil {
+				return uuid.Nil, errors.Wrapf(err, "Error retrieving the flavors linked to flavorgroup %s", flavorgroup.Name)
+			}
+			for i := range flavors {
+				flavorDigest, err := flavorContentDigest(&flavors[i].Flavor)
+				if err != nil {
+					return uuid.Nil, errors.Wrapf(err, "Error computing the digest of flavor %s", flavors[i].Flavor.Meta.ID)
+				}
+				flavorgroupDigests[flavorDigest] = flavors[i].Flavor.Meta.ID
+			}
+		}
+		index[flavorgroup.ID] = flavorgroupDigests
+	}
+	return flavorgroupDigests[digest], nil
+}
+
+// flavorContentDigest returns the digest of the flavor content, it depends neither on the flavor id nor on its label
+func flavorContentDigest(flavor *hvs.Flavor) (string, error) {
+	content := *flavor
+	content.Meta.Description = make(map[string]interface{}, len(flavor.Meta.Description))
+	for key, value := range flavor.Meta.Description {
+		if key != fm.Label {
+			content.Meta.Description[key] = value
+		}
+	}
+	return hvs.FlavorDigest(&content)
+}
+
+func sameFlavorTemplateDigest(existing, imported *hvs.FlavorTemplate) (bool, error) {
+	existingDigest, err := hvs.FlavorTemplateDigest(existing)
+	if err != nil {
+		return false, errors.Wrapf(err, "Error computing the digest of flavor template %s", existing.ID)
+	}
+	importedDigest, err := hvs.FlavorTemplateDigest(imported)
+	if err != nil {
+		return false, errors.Wrapf(err, "Error computing the digest of flavor template %s", imported.ID)
+	}
+	return existingDigest == importedDigest, nil
+}
+
+func sameMatchPolicies(existing, imported hvs.FlavorMatchPolicies) bool {
+	existingJson, _ := json.Marshal(existing)
+	importedJson, _ := json.Marshal(imported)
+	return string(existingJson) == string(importedJson)
+}
+
+func flavorgroupsKey(flavorgroups []hvs.FlavorGroup) string {
+	ids := make([]string, 0, len(flavorgroups))
+	for _, flavorgroup := range flavorgroups {
+		ids = append(ids, flavorgroup.ID.String())
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
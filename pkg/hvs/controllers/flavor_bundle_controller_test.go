@@ -0,0 +1,310 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/mocks"
+	dm "github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	hvsRoutes "github.com/intel-secl/intel-secl/v4/pkg/hvs/router"
+	smocks "github.com/intel-secl/intel-secl/v4/pkg/hvs/services/hosttrust/mocks"
+	consts "github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
+	fu "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/util"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// newFlavorSigningCertificate returns a key along with a self-signed certificate the flavors and the flavor bundles
+// can be signed with
+func newFlavorSigningCertificate(commonName string) (*rsa.PrivateKey, *x509.Certificate) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	certificate, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	return key, certificate
+}
+
+var _ = Describe("FlavorBundleController", func() {
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+	var flavorStore *mocks.MockFlavorStore
+	var flavorGroupStore *mocks.MockFlavorgroupStore
+	var flavorTemplateStore *mocks.MockFlavorTemplateStore
+	var flavorBundleController *controllers.FlavorBundleController
+	var signingKey *rsa.PrivateKey
+	var flavorCAs *x509.CertPool
+
+	flavorId := uuid.MustParse("c36b5412-8c02-4e08-8a74-8bfa40425cf3")
+	flavorgroupId := uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2")
+
+	newController := func(fs *mocks.MockFlavorStore, fgs *mocks.MockFlavorgroupStore, fts *mocks.MockFlavorTemplateStore) *controllers.FlavorBundleController {
+		certStore := mocks.NewFakeCertificatesStore()
+		var signingCertificate *x509.Certificate
+		signingKey, signingCertificate = newFlavorSigningCertificate("HVS Flavor Signing Certificate")
+		(*certStore)[dm.CertTypesFlavorSigning.String()].Key = signingKey
+		(*certStore)[dm.CertTypesFlavorSigning.String()].Certificates = []x509.Certificate{*signingCertificate}
+		flavorCAs = x509.NewCertPool()
+		flavorCAs.AddCert(signingCertificate)
+
+		flavorController := controllers.FlavorController{
+			FStore:    fs,
+			FGStore:   fgs,
+			FTStore:   fts,
+			HStore:    mocks.NewMockHostStore(),
+			CertStore: certStore,
+			HTManager: &smocks.MockHostTrustManager{},
+		}
+		return controllers.NewFlavorBundleController(flavorController, flavorCAs)
+	}
+
+	exportFlavors := func(query string) *hvs.FlavorBundle {
+		router.Handle("/flavors/export", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(flavorBundleController.Export))).Methods("GET")
+		req, err := http.NewRequest("GET", "/flavors/export"+query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			return nil
+		}
+		var bundle hvs.FlavorBundle
+		Expect(json.Unmarshal(w.Body.Bytes(), &bundle)).To(Succeed())
+		return &bundle
+	}
+
+	importFlavors := func(controller *controllers.FlavorBundleController, bundle *hvs.FlavorBundle) *hvs.FlavorBundleImportResult {
+		router = mux.NewRouter()
+		router.Handle("/flavors/import", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(controller.Import))).Methods("POST")
+		body, err := json.Marshal(bundle)
+		Expect(err).NotTo(HaveOccurred())
+		req, err := http.NewRequest("POST", "/flavors/import", bytes.NewBuffer(body))
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+		req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			return nil
+		}
+		var result hvs.FlavorBundleImportResult
+		Expect(json.Unmarshal(w.Body.Bytes(), &result)).To(Succeed())
+		return &result
+	}
+
+	reasons := func(entries []hvs.FlavorBundleImportEntry) map[hvs.FlavorBundleEntryType]string {
+		byType := make(map[hvs.FlavorBundleEntryType]string)
+		for _, entry := range entries {
+			byType[entry.Type] = entry.Reason
+		}
+		return byType
+	}
+
+	BeforeEach(func() {
+		router = mux.NewRouter()
+		flavorStore = &mocks.MockFlavorStore{}
+		flavorGroupStore = mocks.NewFakeFlavorgroupStore()
+		flavorTemplateStore = mocks.NewFakeFlavorTemplateStore()
+		flavorBundleController = newController(flavorStore, flavorGroupStore, flavorTemplateStore)
+
+		// the stored flavors are signed with the flavor signing key
+		signedFlavor, err := mocks.NewMockFlavorStore().Retrieve(flavorId)
+		Expect(err).NotTo(HaveOccurred())
+		signedFlavor, err = fu.PlatformFlavorUtil{}.GetSignedFlavor(&signedFlavor.Flavor, signingKey)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = flavorStore.Create(signedFlavor)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = flavorGroupStore.AddFlavors(flavorgroupId, []uuid.UUID{flavorId})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("Export flavors", func() {
+		Context("Export a flavorgroup by name", func() {
+			It("Should return a signed flavor bundle", func() {
+				bundle := exportFlavors("?flavorgroupName=hvs_flavorgroup_test1")
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(bundle.FlavorGroups).To(HaveLen(1))
+				Expect(bundle.FlavorGroups[0].FlavorIds).To(Equal([]uuid.UUID{flavorId}))
+				Expect(bundle.SignedFlavors).To(HaveLen(1))
+				Expect(bundle.Manifest.Entries).To(HaveLen(2))
+
+				signingCertificate, err := bundle.Verify(flavorCAs)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(signingCertificate.Subject.CommonName).To(Equal("HVS Flavor Signing Certificate"))
+			})
+		})
+
+		Context("Export all the flavorgroups", func() {
+			It("Should return every flavorgroup", func() {
+				bundle := exportFlavors("")
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(bundle.FlavorGroups).To(HaveLen(2))
+			})
+		})
+
+		Context("Export a revoked flavor", func() {
+			It("Should leave the flavor out of the bundle", func() {
+				_, err := flavorStore.UpdateLifecycle(&hvs.FlavorLifecycle{FlavorId: flavorId, State: hvs.FlavorStateRevoked})
+				Expect(err).NotTo(HaveOccurred())
+				bundle := exportFlavors("?flavorgroupName=hvs_flavorgroup_test1")
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(bundle.SignedFlavors).To(BeEmpty())
+				Expect(bundle.FlavorGroups[0].FlavorIds).To(BeEmpty())
+			})
+		})
+
+		Context("Export a non-existent flavorgroup or with an invalid query", func() {
+			It("Should return 400 response code", func() {
+				exportFlavors("?flavorgroupName=unknown")
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+				exportFlavors("?nameEqualTo=hvs_flavorgroup_test1")
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	Describe("Import flavors", func() {
+		Context("Import a bundle in an HVS trusting the flavor CA", func() {
+			It("Should add the flavorgroup and the flavor", func() {
+				bundle := exportFlavors("?flavorgroupName=hvs_flavorgroup_test1")
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				targetFlavorStore := &mocks.MockFlavorStore{}
+				targetFlavorGroupStore := &mocks.MockFlavorgroupStore{
+					FlavorgroupStore:       make(map[uuid.UUID]*hvs.FlavorGroup),
+					FlavorgroupFlavorStore: make(map[uuid.UUID][]uuid.UUID),
+				}
+				target := newController(targetFlavorStore, targetFlavorGroupStore, &mocks.MockFlavorTemplateStore{})
+				target.FlavorCACertificates.AddCert(&(*flavorBundleController.FlavorController.CertStore)[dm.CertTypesFlavorSigning.String()].Certificates[0])
+
+				result := importFlavors(target, bundle)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(result.Added).To(HaveLen(2))
+				Expect(result.Skipped).To(BeEmpty())
+
+				imported, err := targetFlavorStore.Retrieve(flavorId)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(imported.Verify(&signingKey.PublicKey)).To(Succeed())
+				flavorgroups, err := targetFlavorGroupStore.Search(&dm.FlavorGroupFilterCriteria{NameEqualTo: "hvs_flavorgroup_test1"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(flavorgroups).To(HaveLen(1))
+				Expect(targetFlavorGroupStore.FlavorgroupFlavorStore[flavorgroups[0].ID]).To(Equal([]uuid.UUID{flavorId}))
+			})
+		})
+
+		Context("Import a bundle whose content already exists", func() {
+			It("Should skip the duplicates", func() {
+				bundle := exportFlavors("?flavorgroupName=hvs_flavorgroup_test1")
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				result := importFlavors(flavorBundleController, bundle)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(result.Added).To(BeEmpty())
+				Expect(reasons(result.Skipped)).To(Equal(map[hvs.FlavorBundleEntryType]string{
+					hvs.FlavorBundleEntryFlavorgroup: "Flavorgroup already exists",
+					hvs.FlavorBundleEntryFlavor:      "Flavor already exists",
+				}))
+			})
+		})
+
+		Context("Import a bundle with a flavor that differs from the stored one", func() {
+			It("Should skip the flavor as a conflict", func() {
+				signedFlavor, err := mocks.NewMockFlavorStore().Retrieve(flavorId)
+				Expect(err).NotTo(HaveOccurred())
+				signedFlavor.Flavor.Meta.Description["label"] = "imported_label"
+				signedFlavor, err = fu.PlatformFlavorUtil{}.GetSignedFlavor(&signedFlavor.Flavor, signingKey)
+				Expect(err).NotTo(HaveOccurred())
+				bundle := &hvs.FlavorBundle{
+					FlavorGroups:  []hvs.FlavorGroup{{ID: flavorgroupId, Name: "hvs_flavorgroup_test1", FlavorIds: []uuid.UUID{flavorId}}},
+					SignedFlavors: []hvs.SignedFlavor{*signedFlavor},
+				}
+				certificates := (*flavorBundleController.FlavorController.CertStore)[dm.CertTypesFlavorSigning.String()].Certificates
+				Expect(bundle.Sign(signingKey, certificates, time.Now())).To(Succeed())
+
+				result := importFlavors(flavorBundleController, bundle)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(result.Added).To(BeEmpty())
+				Expect(reasons(result.Skipped)[hvs.FlavorBundleEntryFlavor]).To(Equal("A different flavor exists with the same id"))
+			})
+		})
+
+		Context("Import a bundle with a stored flavor under a new id and label", func() {
+			It("Should skip the flavor as a duplicate of the stored one", func() {
+				signedFlavor, err := mocks.NewMockFlavorStore().Retrieve(flavorId)
+				Expect(err).NotTo(HaveOccurred())
+				copiedFlavorId := uuid.New()
+				signedFlavor.Flavor.Meta.ID = copiedFlavorId
+				signedFlavor.Flavor.Meta.Description["label"] = "copied_label"
+				signedFlavor, err = fu.PlatformFlavorUtil{}.GetSignedFlavor(&signedFlavor.Flavor, signingKey)
+				Expect(err).NotTo(HaveOccurred())
+				bundle := &hvs.FlavorBundle{
+					FlavorGroups:  []hvs.FlavorGroup{{ID: uuid.New(), Name: "hvs_flavorgroup_test1", FlavorIds: []uuid.UUID{copiedFlavorId}}},
+					SignedFlavors: []hvs.SignedFlavor{*signedFlavor},
+				}
+				certificates := (*flavorBundleController.FlavorController.CertStore)[dm.CertTypesFlavorSigning.String()].Certificates
+				Expect(bundle.Sign(signingKey, certificates, time.Now())).To(Succeed())
+
+				result := importFlavors(flavorBundleController, bundle)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(result.Added).To(BeEmpty())
+				Expect(reasons(result.Skipped)[hvs.FlavorBundleEntryFlavor]).To(Equal("Flavor already exists"))
+				for _, entry := range result.Skipped {
+					if entry.Type == hvs.FlavorBundleEntryFlavor {
+						Expect(entry.Id).To(Equal(flavorId))
+					}
+				}
+				_, err = flavorStore.Retrieve(copiedFlavorId)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("Import a bundle that was modified after it was signed", func() {
+			It("Should return 400 response code", func() {
+				bundle := exportFlavors("?flavorgroupName=hvs_flavorgroup_test1")
+				Expect(w.Code).To(Equal(http.StatusOK))
+				bundle.FlavorGroups[0].Name = "hvs_flavorgroup_test3"
+
+				importFlavors(flavorBundleController, bundle)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("Import a bundle signed by an untrusted certificate", func() {
+			It("Should return 400 response code", func() {
+				bundle := exportFlavors("?flavorgroupName=hvs_flavorgroup_test1")
+				Expect(w.Code).To(Equal(http.StatusOK))
+				untrustedKey, untrustedCertificate := newFlavorSigningCertificate("Untrusted Flavor Signing Certificate")
+				Expect(bundle.Sign(untrustedKey, []x509.Certificate{*untrustedCertificate}, time.Now())).To(Succeed())
+
+				importFlavors(flavorBundleController, bundle)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+})
@@ -21,6 +21,7 @@ import (
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
 	dm "github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/services/hosttrust"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/utils"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/auth"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
@@ -52,7 +53,10 @@ type FlavorController struct {
 	IsExsi    bool
 }
 
-var flavorSearchParams = map[string]bool{"id": true, "key": true, "value": true, "flavorgroupId": true, "flavorParts": true}
+var flavorSearchParams = utils.WithPageQueryParams(map[string]bool{"id": true, "key": true, "value": true,
+	"flavorgroupId": true, "flavorParts": true})
+
+var flavorSortFields = []string{dm.SortByLabel, dm.SortByID}
 
 func NewFlavorController(fs domain.FlavorStore, fgs domain.FlavorGroupStore, hs domain.HostStore, tcs domain.TagCertificateStore, htm domain.HostTrustManager, certStore *dm.CertificatesStore, hcConfig domain.HostControllerConfig, fts domain.FlavorTemplateStore) *FlavorController {
 	// certStore should have an entry for Flavor Signing CA
@@ -318,7 +322,7 @@ func (fcon *FlavorController) createFlavors(flavorReq dm.FlavorCreateRequest) ([
 		defaultLog.Error("controllers/flavor_controller:createFlavors() Cannot create flavors")
 		return nil, errors.New("Unable to create Flavors")
 	}
-	return fcon.addFlavorToFlavorgroup(flavorFlavorPartMap, flavorgroups)
+	return fcon.addFlavorToFlavorgroup(flavorFlavorPartMap, flavorgroups, flavorReq.Lifecycle)
 }
 
 func getFlavorCreateReq(r *http.Request) (dm.FlavorCreateRequest, error) {
@@ -429,7 +433,7 @@ func (fcon *FlavorController) findTemplatesToApply(flavorgroups []hvs.FlavorGrou
 	return filteredTemplates, nil
 }
 
-func (fcon *FlavorController) addFlavorToFlavorgroup(flavorFlavorPartMap map[fc.FlavorPart][]hvs.SignedFlavor, fgs []hvs.FlavorGroup) ([]hvs.SignedFlavor, error) {
+func (fcon *FlavorController) addFlavorToFlavorgroup(flavorFlavorPartMap map[fc.FlavorPart][]hvs.SignedFlavor, fgs []hvs.FlavorGroup, lifecycle *hvs.FlavorLifecycleRequest) ([]hvs.SignedFlavor, error) {
 	defaultLog.Trace("controllers/flavor_controller:addFlavorToFlavorgroup() Entering")
 	defer defaultLog.Trace("controllers/flavor_controller:addFlavorToFlavorgroup() Leaving")
 
@@ -440,12 +444,21 @@ func (fcon *FlavorController) addFlavorToFlavorgroup(flavorFlavorPartMap map[fc.
 	var flavorgroupsForQueue []hvs.FlavorGroup
 	fetchHostData := false
 	var fgHostIds []uuid.UUID
+	// flavors are created active unless another lifecycle state is requested
+	var flavorLifecycle *hvs.FlavorLifecycle
+	if lifecycle != nil {
+		flavorLifecycle = &hvs.FlavorLifecycle{
+			State:     lifecycle.State,
+			NotBefore: lifecycle.NotBefore,
+			NotAfter:  lifecycle.NotAfter,
+		}
+	}
 
 	for flavorPart, signedFlavors := range flavorFlavorPartMap {
 		defaultLog.Debugf("Creating flavors for fp %s", flavorPart.String())
 		for _, signedFlavor := range signedFlavors {
 			flavorgroups := []hvs.FlavorGroup{}
-			signedFlavorCreated, err := fcon.FStore.Create(&signedFlavor)
+			signedFlavorCreated, err := fcon.FStore.CreateWithLifecycle(&signedFlavor, flavorLifecycle)
 			if err != nil {
 				defaultLog.WithError(err).Errorf("controllers/flavor_controller: addFlavorToFlavorgroup() : "+
 					"Unable to create flavors of %s flavorPart", flavorPart.String())
@@ -682,6 +695,12 @@ func (fcon *FlavorController) Search(w http.ResponseWriter, r *http.Request) (in
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
 	}
 
+	filterCriteria.Page, err = utils.ParsePageCriteria(r.URL.Query(), flavorSortFields)
+	if err != nil {
+		secLog.Errorf("controllers/flavor_controller:Search()  %s", err.Error())
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
 	signedFlavors, err := fcon.FStore.Search(&dm.FlavorVerificationFC{
 		FlavorFC: *filterCriteria,
 	})
@@ -690,8 +709,18 @@ func (fcon *FlavorController) Search(w http.ResponseWriter, r *http.Request) (in
 		return nil, http.StatusInternalServerError, errors.Errorf("Unable to search Flavors")
 	}
 
+	signedFlavorCollection := hvs.SignedFlavorCollection{SignedFlavors: signedFlavors}
+	if page := filterCriteria.Page; page != nil && len(signedFlavors) > 0 {
+		last := signedFlavors[len(signedFlavors)-1].Flavor.Meta
+		cursor := dm.PageCursor{SortBy: page.SortBy, ID: last.ID}
+		if page.SortBy == dm.SortByLabel {
+			cursor.Value, _ = last.Description[fm.Label].(string)
+		}
+		signedFlavorCollection.Next = utils.NextPageLink(r, page, len(signedFlavors), cursor)
+	}
+
 	secLog.Infof("%s: Return flavor query to: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
-	return hvs.SignedFlavorCollection{SignedFlavors: signedFlavors}, http.StatusOK, nil
+	return signedFlavorCollection, http.StatusOK, nil
 }
 
 func (fcon *FlavorController) Delete(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
@@ -712,7 +741,7 @@ func (fcon *FlavorController) Delete(w http.ResponseWriter, r *http.Request) (in
 		}
 	}
 
-	hostIdsForQueue, err := getHostsAssociatedWithFlavor(fcon.HStore, fcon.FGStore, signedFlavor)
+	hostIdsForQueue, err := hosttrust.GetHostsAssociatedWithFlavor(fcon.HStore, fcon.FGStore, signedFlavor)
 	if err != nil {
 		defaultLog.WithError(err).Error("controllers/flavor_controller:Delete() Failed to retrieve hosts " +
 			"associated with flavor")
@@ -739,47 +768,6 @@ func (fcon *FlavorController) Delete(w http.ResponseWriter, r *http.Request) (in
 	return nil, http.StatusNoContent, nil
 }
 
-func getHostsAssociatedWithFlavor(hStore domain.HostStore, fgStore domain.FlavorGroupStore, flavor *hvs.SignedFlavor) ([]uuid.UUID, error) {
-	defaultLog.Trace("controllers/flavor_controller:getHostsAssociatedWithFlavor() Entering")
-	defer defaultLog.Trace("controllers/flavor_controller:getHostsAssociatedWithFlavor() Leaving")
-
-	id := flavor.Flavor.Meta.ID
-	flavorGroups, err := fgStore.Search(&dm.FlavorGroupFilterCriteria{FlavorId: &id})
-	if err != nil {
-		return nil, errors.Wrapf(err, "controllers/flavor_controller:getHostsAssociatedWithFlavor() Failed to retrieve flavorgroups "+
-			"associated with flavor %v for trust re-verification", id)
-	}
-
-	var hostIdsForQueue []uuid.UUID
-	for _, flavorGroup := range flavorGroups {
-		//Host unique flavors are associated with only host_unique flavorgroup and associated with only one host uniquely
-		if flavorGroup.Name == dm.FlavorGroupsHostUnique.String() {
-			hardwareUUID, err := uuid.Parse(flavor.Flavor.Meta.Description[fm.HardwareUUID].(string))
-			if err != nil {
-				return nil, errors.Wrap(err, "controllers/flavor_controller:getHostsAssociatedWithFlavor() Failed to parse hardwareUUID")
-			}
-			hosts, err := hStore.Search(&dm.HostFilterCriteria{
-				HostHardwareId: hardwareUUID,
-			}, nil)
-			if err != nil {
-				return nil, errors.Wrapf(err, "controllers/flavor_controller:getHostsAssociatedWithFlavor() Failed to retrieve hosts "+
-					"associated with flavor %v for trust re-verification", id)
-			}
-			if len(hosts) > 0 {
-				hostIdsForQueue = append(hostIdsForQueue, hosts[0].Id)
-				break
-			}
-		}
-		hostIds, err := fgStore.SearchHostsByFlavorGroup(flavorGroup.ID)
-		if err != nil {
-			return nil, errors.Wrapf(err, "controllers/flavor_controller:getHostsAssociatedWithFlavor() Failed to retrieve hosts "+
-				"associated with flavorgroup %v for trust re-verification", flavorGroup.ID)
-		}
-		hostIdsForQueue = append(hostIdsForQueue, hostIds...)
-	}
-	return hostIdsForQueue, nil
-}
-
 func (fcon *FlavorController) Retrieve(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
 	defaultLog.Trace("controllers/flavor_controller:Retrieve() Entering")
 	defer defaultLog.Trace("controllers/flavor_controller:Retrieve() Leaving")
@@ -801,6 +789,112 @@ func (fcon *FlavorController) Retrieve(w http.ResponseWriter, r *http.Request) (
 
 }
 
+func (fcon *FlavorController) RetrieveLifecycle(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/flavor_controller:RetrieveLifecycle() Entering")
+	defer defaultLog.Trace("controllers/flavor_controller:RetrieveLifecycle() Leaving")
+
+	id := uuid.MustParse(mux.Vars(r)["id"])
+	lifecycle, err := fcon.FStore.RetrieveLifecycle(id)
+	if err != nil {
+		if strings.Contains(err.Error(), commErr.RowsNotFound) {
+			secLog.WithError(err).WithField("id", id).Info(
+				"controllers/flavor_controller:RetrieveLifecycle() Flavor with given ID does not exist")
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Flavor with given ID does not exist"}
+		} else {
+			secLog.WithError(err).WithField("id", id).Info(
+				"controllers/flavor_controller:RetrieveLifecycle() failed to retrieve Flavor lifecycle")
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve Flavor lifecycle with the given ID"}
+		}
+	}
+	return lifecycle, http.StatusOK, nil
+}
+
+// UpdateLifecycle moves a flavor to another lifecycle state and queues the hosts linked to the flavor for
+// re-verification, since the flavors in effect for them may have changed
+func (fcon *FlavorController) UpdateLifecycle(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/flavor_controller:UpdateLifecycle() Entering")
+	defer defaultLog.Trace("controllers/flavor_controller:UpdateLifecycle() Leaving")
+
+	id := uuid.MustParse(mux.Vars(r)["id"])
+	if r.Header.Get("Content-Type") != constants.HTTPMediaTypeJson {
+		return nil, http.StatusUnsupportedMediaType, &commErr.ResourceError{Message: "Invalid Content-Type"}
+	}
+	if r.ContentLength == 0 {
+		secLog.Error("controllers/flavor_controller:UpdateLifecycle() The request body is not provided")
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body is not provided"}
+	}
+
+	var lifecycleReq hvs.FlavorLifecycleRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&lifecycleReq); err != nil {
+		secLog.WithError(err).Errorf("controllers/flavor_controller:UpdateLifecycle() %s :  Failed to decode request body as Flavor lifecycle", commLogMsg.InvalidInputBadEncoding)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Unable to decode JSON request body"}
+	}
+	if err := validateFlavorLifecycleRequest(lifecycleReq); err != nil {
+		secLog.WithError(err).Errorf("controllers/flavor_controller:UpdateLifecycle() %s Invalid flavor lifecycle", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	signedFlavor, err := fcon.FStore.Retrieve(id)
+	if err != nil {
+		if strings.Contains(err.Error(), commErr.RowsNotFound) {
+			secLog.WithError(err).WithField("id", id).Info(
+				"controllers/flavor_controller:UpdateLifecycle() Flavor with given ID does not exist")
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Flavor with given ID does not exist"}
+		}
+		secLog.WithError(err).WithField("id", id).Info(
+			"controllers/flavor_controller:UpdateLifecycle() failed to retrieve Flavor")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to update Flavor lifecycle"}
+	}
+	current, err := fcon.FStore.RetrieveLifecycle(id)
+	if err != nil {
+		defaultLog.WithError(err).WithField("id", id).Error(
+			"controllers/flavor_controller:UpdateLifecycle() failed to retrieve Flavor lifecycle")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to update Flavor lifecycle"}
+	}
+	if !current.State.CanTransitionTo(lifecycleReq.State) {
+		secLog.WithField("id", id).Errorf("controllers/flavor_controller:UpdateLifecycle() %s Invalid flavor lifecycle transition from %s to %s",
+			commLogMsg.InvalidInputBadParam, current.State, lifecycleReq.State)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Flavor cannot be moved from " +
+			current.State.String() + " to " + lifecycleReq.State.String() + " state"}
+	}
+
+	hostIdsForQueue, err := hosttrust.GetHostsAssociatedWithFlavor(fcon.HStore, fcon.FGStore, signedFlavor)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/flavor_controller:UpdateLifecycle() Failed to retrieve hosts " +
+			"associated with flavor")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve hosts " +
+			"associated with flavor for trust re-verification"}
+	}
+
+	lifecycle, err := fcon.FStore.UpdateLifecycle(&hvs.FlavorLifecycle{
+		FlavorId:  id,
+		State:     lifecycleReq.State,
+		NotBefore: lifecycleReq.NotBefore,
+		NotAfter:  lifecycleReq.NotAfter,
+	})
+	if err != nil {
+		defaultLog.WithError(err).WithField("id", id).Error(
+			"controllers/flavor_controller:UpdateLifecycle() failed to update Flavor lifecycle")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to update Flavor lifecycle"}
+	}
+
+	defaultLog.Debugf("Found %v hosts to be added to flavor-verify queue", len(hostIdsForQueue))
+	// adding all the host linked to flavor to flavor-verify queue
+	if len(hostIdsForQueue) >= 1 {
+		err := fcon.HTManager.VerifyHostsAsync(hostIdsForQueue, false, false)
+		if err != nil {
+			defaultLog.Error("controllers/flavor_controller:UpdateLifecycle() Host to Flavor Verify Queue addition failed")
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to re-verify hosts " +
+				"associated with Flavor"}
+		}
+	}
+	secLog.WithField("id", id).Infof("%s: Flavor moved from %s to %s lifecycle state by: %s", commLogMsg.PrivilegeModified,
+		current.State, lifecycle.State, r.RemoteAddr)
+	return lifecycle, http.StatusOK, nil
+}
+
 func validateFlavorFilterCriteria(key, value, flavorgroupId string, ids, flavorParts []string) (*dm.FlavorFilterCriteria, error) {
 	defaultLog.Trace("controllers/flavor_controller:validateFlavorFilterCriteria() Entering")
 	defer defaultLog.Trace("controllers/flavor_controller:validateFlavorFilterCriteria() Leaving")
@@ -878,10 +972,32 @@ func validateFlavorCreateRequest(criteria dm.FlavorCreateRequest) error {
 			return errors.New("Valid flavor parts must be given as a flavor create criteria")
 		}
 	}
+	if criteria.Lifecycle != nil {
+		if err := validateFlavorLifecycleRequest(*criteria.Lifecycle); err != nil {
+			return err
+		}
+		if criteria.Lifecycle.State != hvs.FlavorStateStaged && criteria.Lifecycle.State != hvs.FlavorStateActive {
+			return errors.New("Flavors can only be created in staged or active lifecycle state")
+		}
+	}
 
 	return nil
 }
 
+func validateFlavorLifecycleRequest(lifecycle hvs.FlavorLifecycleRequest) error {
+	defaultLog.Trace("controllers/flavor_controller:validateFlavorLifecycleRequest() Entering")
+	defer defaultLog.Trace("controllers/flavor_controller:validateFlavorLifecycleRequest() Leaving")
+
+	var state hvs.FlavorLifecycleState
+	if err := (&state).Parse(lifecycle.State.String()); err != nil {
+		return errors.New("Valid flavor lifecycle state must be given")
+	}
+	if lifecycle.NotBefore != nil && lifecycle.NotAfter != nil && !lifecycle.NotBefore.Before(*lifecycle.NotAfter) {
+		return errors.New("Flavor lifecycle not_before date must be before the not_after date")
+	}
+	return nil
+}
+
 func validateFlavorMetaContent(meta *fm.Meta) error {
 	defaultLog.Trace("controllers/flavor_controller:validateFlavorMetaContent() Entering")
 	defer defaultLog.Trace("controllers/flavor_controller:validateFlavorMetaContent() Leaving")
@@ -5,20 +5,28 @@
 package controllers_test
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
 	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	hvsConsts "github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/controllers"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/mocks"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
 	hvsRoutes "github.com/intel-secl/intel-secl/v4/pkg/hvs/router"
 	smocks "github.com/intel-secl/intel-secl/v4/pkg/hvs/services/hosttrust/mocks"
 	consts "github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/context"
 	mocks2 "github.com/intel-secl/intel-secl/v4/pkg/lib/host-connector/mocks"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/aas"
 	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -201,6 +209,115 @@ var _ = Describe("FlavorController", func() {
 		})
 	})
 
+	// Specs for HTTP Get and Put to "/flavors/{flavorId}/lifecycle"
+	Describe("Flavor lifecycle", func() {
+		updateLifecycle := func(id, body string) {
+			router.Handle("/flavors/{id}/lifecycle", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(flavorController.UpdateLifecycle))).Methods("PUT")
+			req, err := http.NewRequest("PUT", "/flavors/"+id+"/lifecycle", strings.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+			req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+		}
+
+		Context("Retrieve the lifecycle of a Flavor by valid ID from data store", func() {
+			It("Should retrieve an active Flavor lifecycle", func() {
+				router.Handle("/flavors/{id}/lifecycle", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(flavorController.RetrieveLifecycle))).Methods("GET")
+				req, err := http.NewRequest("GET", "/flavors/c36b5412-8c02-4e08-8a74-8bfa40425cf3/lifecycle", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var lifecycle hvs.FlavorLifecycle
+				err = json.Unmarshal(w.Body.Bytes(), &lifecycle)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(lifecycle.State).To(Equal(hvs.FlavorStateActive))
+			})
+		})
+
+		Context("Deprecate an active Flavor with a not_after date", func() {
+			It("Should update the Flavor lifecycle", func() {
+				updateLifecycle("c36b5412-8c02-4e08-8a74-8bfa40425cf3", `{"state": "deprecated", "not_after": "2030-01-01T00:00:00Z"}`)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				lifecycle, err := flavorStore.RetrieveLifecycle(uuid.MustParse("c36b5412-8c02-4e08-8a74-8bfa40425cf3"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(lifecycle.State).To(Equal(hvs.FlavorStateDeprecated))
+				Expect(lifecycle.NotAfter).NotTo(BeNil())
+			})
+		})
+
+		Context("Move a revoked Flavor back to the active state", func() {
+			It("Should return 400 response code", func() {
+				updateLifecycle("c36b5412-8c02-4e08-8a74-8bfa40425cf3", `{"state": "revoked"}`)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				updateLifecycle("c36b5412-8c02-4e08-8a74-8bfa40425cf3", `{"state": "active"}`)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("Provide invalid Flavor lifecycle requests", func() {
+			It("Should return 400 response code", func() {
+				for _, body := range []string{
+					`{"state": "retired"}`,
+					`{"state": "active", "not_before": "2030-01-01T00:00:00Z", "not_after": "2029-01-01T00:00:00Z"}`,
+					`{"state": "active", "reason": "unknown field"}`,
+				} {
+					updateLifecycle("c36b5412-8c02-4e08-8a74-8bfa40425cf3", body)
+					Expect(w.Code).To(Equal(http.StatusBadRequest), body)
+				}
+			})
+		})
+
+		Context("Update the lifecycle of a Flavor by non-existent ID from data store", func() {
+			It("Should return 404 response code", func() {
+				updateLifecycle("73755fda-c910-46be-821f-e8ddeab189e9", `{"state": "deprecated"}`)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+		Context("Create a Flavor in the staged lifecycle state", func() {
+			It("Should create the Flavor in its final state without updating its lifecycle", func() {
+				store := &lifecycleUpdateCounter{MockFlavorStore: flavorStore}
+				flavorController.FStore = store
+				signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+				Expect(err).NotTo(HaveOccurred())
+				certStore := mocks.NewFakeCertificatesStore()
+				(*certStore)[models.CertTypesFlavorSigning.String()] = &models.CertificateStore{Key: signingKey}
+				flavorController.CertStore = certStore
+				router.Handle("/flavors", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(flavorController.Create))).Methods("POST")
+
+				var createRequest map[string]interface{}
+				Expect(json.Unmarshal([]byte(manualFlavorCreateRequest), &createRequest)).To(Succeed())
+				notBefore := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+				createRequest["lifecycle"] = map[string]interface{}{"state": "staged", "not_before": notBefore}
+				body, err := json.Marshal(createRequest)
+				Expect(err).NotTo(HaveOccurred())
+
+				req, err := http.NewRequest("POST", "/flavors", strings.NewReader(string(body)))
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				req = context.SetUserPermissions(req, []aas.PermissionInfo{{Service: hvsConsts.ServiceName,
+					Rules: []string{hvsConsts.FlavorCreate}}})
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusCreated))
+
+				var signedFlavors hvs.SignedFlavorCollection
+				Expect(json.Unmarshal(w.Body.Bytes(), &signedFlavors)).To(Succeed())
+				Expect(signedFlavors.SignedFlavors).To(HaveLen(1))
+				lifecycle, err := flavorStore.RetrieveLifecycle(signedFlavors.SignedFlavors[0].Flavor.Meta.ID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(lifecycle.State).To(Equal(hvs.FlavorStateStaged))
+				Expect(lifecycle.NotBefore.Equal(notBefore)).To(BeTrue())
+				Expect(store.updates).To(Equal(0))
+			})
+		})
+	})
+
 	// Specs for HTTP Post to "/flavor"
 	Describe("Create a new flavor", func() {
 		Context("Provide a invalid Create request with XSS Attack Strings", func() {
@@ -299,6 +416,22 @@ var _ = Describe("FlavorController", func() {
 
 		Context("Provide a valid manually crafted Flavor request", func() {
 			It("Should return 201 Response code and a signed flavor", func() {
+				router.Handle("/flavors", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(flavorController.Create))).Methods("POST")
+				flavorJson := manualFlavorCreateRequest
+				req, err := http.NewRequest(
+					"POST",
+					"/flavors",
+					strings.NewReader(flavorJson),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+			})
+		})
+		Context("Provide a manually crafted Flavor request with an invalid field name", func() {
+			It("Should return 400 Error code", func() {
 				router.Handle("/flavors", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(flavorController.Create))).Methods("POST")
 				flavorJson := `{
 					"connection_string":"",
@@ -307,7 +440,7 @@ var _ = Describe("FlavorController", func() {
 						  {
 							 "flavor":{
 								"meta":{
-								   "id":"0fcb8e8d-6fe6-46ba-9526-32b53bf3df75",
+								   "id":"0fcb8e8d-6fe6-46ba-9526-32b53bf3df7b",
 								   "description":{
 									  "bios_name":"Intel Corporation",
 									  "bios_version":"SE5C610.86B.01.01.0016.033120161139",
@@ -539,7 +672,7 @@ var _ = Describe("FlavorController", func() {
 						  }
 					   ]
 					},
-					"flavorgroup_names":[
+					"invalid_field_names":[
 					   "Test"
 					],
 					"partial_flavor_types":[
@@ -556,19 +689,31 @@ var _ = Describe("FlavorController", func() {
 				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
 			})
 		})
-		Context("Provide a manually crafted Flavor request with an invalid field name", func() {
-			It("Should return 400 Error code", func() {
-				router.Handle("/flavors", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(flavorController.Create))).Methods("POST")
-				flavorJson := `{
+	})
+})
+
+// lifecycleUpdateCounter counts the updates of the flavor lifecycles
+type lifecycleUpdateCounter struct {
+	*mocks.MockFlavorStore
+	updates int
+}
+
+func (store *lifecycleUpdateCounter) UpdateLifecycle(lifecycle *hvs.FlavorLifecycle) (*hvs.FlavorLifecycle, error) {
+	store.updates++
+	return store.MockFlavorStore.UpdateLifecycle(lifecycle)
+}
+
+var manualFlavorCreateRequest = `{
 					"connection_string":"",
 					"flavor_collection":{
 					   "flavors":[
 						  {
 							 "flavor":{
 								"meta":{
-								   "id":"0fcb8e8d-6fe6-46ba-9526-32b53bf3df7b",
+								   "id":"0fcb8e8d-6fe6-46ba-9526-32b53bf3df75",
 								   "description":{
 									  "bios_name":"Intel Corporation",
 									  "bios_version":"SE5C610.86B.01.01.0016.033120161139",
@@ -800,25 +945,10 @@ var _ = Describe("FlavorController", func() {
 						  }
 					   ]
 					},
-					"invalid_field_names":[
+					"flavorgroup_names":[
 					   "Test"
 					],
 					"partial_flavor_types":[
 					   "PLATFORM"
 					]
 				 }`
-				req, err := http.NewRequest(
-					"POST",
-					"/flavors",
-					strings.NewReader(flavorJson),
-				)
-				Expect(err).NotTo(HaveOccurred())
-				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
-				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
-				w = httptest.NewRecorder()
-				router.ServeHTTP(w, req)
-				Expect(w.Code).To(Equal(http.StatusBadRequest))
-			})
-		})
-	})
-})
@@ -0,0 +1,159 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/utils"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	commLogMsg "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/message"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/host-connector/types"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+)
+
+// FlavorDiffController compares the PCRs of a flavor with the ones of another flavor or of the latest stored
+// manifest of a host, so that the flavor can be updated after a firmware or software update of the host
+type FlavorDiffController struct {
+	FStore  domain.FlavorStore
+	HStore  domain.HostStore
+	HSStore domain.HostStatusStore
+}
+
+var flavorDiffParams = map[string]bool{"otherFlavorId": true, "hostId": true, "includeCandidateFlavor": true}
+
+func NewFlavorDiffController(fs domain.FlavorStore, hs domain.HostStore, hss domain.HostStatusStore) *FlavorDiffController {
+	return &FlavorDiffController{
+		FStore:  fs,
+		HStore:  hs,
+		HSStore: hss,
+	}
+}
+
+// Diff lists the PCRs, and their events, that differ between the flavor and another flavor or a host manifest
+func (controller FlavorDiffController) Diff(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/flavor_diff_controller:Diff() Entering")
+	defer defaultLog.Trace("controllers/flavor_diff_controller:Diff() Leaving")
+
+	id := uuid.MustParse(mux.Vars(r)["id"])
+	if err := utils.ValidateQueryParams(r.URL.Query(), flavorDiffParams); err != nil {
+		secLog.Errorf("controllers/flavor_diff_controller:Diff() %s", err.Error())
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	otherFlavorIdParam := r.URL.Query().Get("otherFlavorId")
+	hostIdParam := r.URL.Query().Get("hostId")
+	if (otherFlavorIdParam == "") == (hostIdParam == "") {
+		secLog.Errorf("controllers/flavor_diff_controller:Diff() %s : Either otherFlavorId or hostId must be specified", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Either otherFlavorId or hostId must be specified"}
+	}
+
+	includeCandidateFlavor := false
+	if param := r.URL.Query().Get("includeCandidateFlavor"); param != "" {
+		var err error
+		includeCandidateFlavor, err = strconv.ParseBool(param)
+		if err != nil {
+			secLog.WithError(err).Errorf("controllers/flavor_diff_controller:Diff() %s : Invalid includeCandidateFlavor query param value, must be true or false", commLogMsg.InvalidInputBadParam)
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid includeCandidateFlavor query param value, must be true or false"}
+		}
+	}
+
+	signedFlavor, err := controller.FStore.Retrieve(id)
+	if err != nil {
+		if strings.Contains(err.Error(), commErr.RowsNotFound) {
+			secLog.WithError(err).WithField("id", id).Info("controllers/flavor_diff_controller:Diff() Flavor with given ID does not exist")
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Flavor with given ID does not exist"}
+		}
+		defaultLog.WithError(err).WithField("id", id).Error("controllers/flavor_diff_controller:Diff() Failed to retrieve flavor")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve Flavor with the given ID"}
+	}
+
+	flavorDiff := hvs.FlavorDiff{FlavorId: id}
+	if otherFlavorIdParam != "" {
+		otherFlavorId, err := uuid.Parse(otherFlavorIdParam)
+		if err != nil {
+			secLog.WithError(err).Errorf("controllers/flavor_diff_controller:Diff() %s : Invalid otherFlavorId query param value", commLogMsg.InvalidInputBadParam)
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid otherFlavorId query param value, must be UUID"}
+		}
+		otherFlavor, status, err := controller.retrieveOtherFlavor(otherFlavorId)
+		if err != nil {
+			return nil, status, err
+		}
+		flavorDiff.OtherFlavorId = &otherFlavorId
+		flavorDiff.Pcrs, err = hvs.DiffFlavors(&signedFlavor.Flavor, &otherFlavor.Flavor)
+		if err != nil {
+			defaultLog.WithError(err).Errorf("controllers/flavor_diff_controller:Diff() %s : Failed to compare the flavors", commLogMsg.AppRuntimeErr)
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to compare the flavors"}
+		}
+	} else {
+		hostId, err := uuid.Parse(hostIdParam)
+		if err != nil {
+			secLog.WithError(err).Errorf("controllers/flavor_diff_controller:Diff() %s : Invalid hostId query param value", commLogMsg.InvalidInputBadParam)
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid hostId query param value, must be UUID"}
+		}
+		hostManifest, status, err := controller.retrieveHostManifest(hostId)
+		if err != nil {
+			return nil, status, err
+		}
+		flavorDiff.HostId = &hostId
+		flavorDiff.Pcrs, err = hvs.DiffFlavorWithHostManifest(&signedFlavor.Flavor, hostManifest)
+		if err != nil {
+			defaultLog.WithError(err).Errorf("controllers/flavor_diff_controller:Diff() %s : Failed to compare the flavor with the host manifest", commLogMsg.AppRuntimeErr)
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to compare the flavor with the host manifest"}
+		}
+	}
+
+	if includeCandidateFlavor {
+		flavorDiff.CandidateFlavor = hvs.CandidateFlavor(&signedFlavor.Flavor, flavorDiff.Pcrs)
+	}
+	return flavorDiff, http.StatusOK, nil
+}
+
+func (controller FlavorDiffController) retrieveOtherFlavor(otherFlavorId uuid.UUID) (*hvs.SignedFlavor, int, error) {
+	defaultLog.Trace("controllers/flavor_diff_controller:retrieveOtherFlavor() Entering")
+	defer defaultLog.Trace("controllers/flavor_diff_controller:retrieveOtherFlavor() Leaving")
+
+	otherFlavor, err := controller.FStore.Retrieve(otherFlavorId)
+	if err != nil {
+		if strings.Contains(err.Error(), commErr.RowsNotFound) {
+			secLog.WithError(err).WithField("id", otherFlavorId).Info("controllers/flavor_diff_controller:retrieveOtherFlavor() Flavor with given otherFlavorId does not exist")
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Flavor with given otherFlavorId does not exist"}
+		}
+		defaultLog.WithError(err).WithField("id", otherFlavorId).Error("controllers/flavor_diff_controller:retrieveOtherFlavor() Failed to retrieve flavor")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve Flavor with the given otherFlavorId"}
+	}
+	return otherFlavor, http.StatusOK, nil
+}
+
+// retrieveHostManifest returns the latest manifest stored for the host, the host is not contacted
+func (controller FlavorDiffController) retrieveHostManifest(hostId uuid.UUID) (*types.HostManifest, int, error) {
+	defaultLog.Trace("controllers/flavor_diff_controller:retrieveHostManifest() Entering")
+	defer defaultLog.Trace("controllers/flavor_diff_controller:retrieveHostManifest() Leaving")
+
+	if _, err := controller.HStore.Retrieve(hostId, nil); err != nil {
+		if strings.Contains(err.Error(), commErr.RowsNotFound) {
+			secLog.WithError(err).WithField("id", hostId).Info("controllers/flavor_diff_controller:retrieveHostManifest() Host with given hostId does not exist")
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Host with given hostId does not exist"}
+		}
+		defaultLog.WithError(err).WithField("id", hostId).Error("controllers/flavor_diff_controller:retrieveHostManifest() Failed to retrieve host")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve Host with the given hostId"}
+	}
+
+	hostStatuses, err := controller.HSStore.Search(&models.HostStatusFilterCriteria{HostId: hostId, LatestPerHost: true})
+	if err != nil {
+		defaultLog.WithError(err).Errorf("controllers/flavor_diff_controller:retrieveHostManifest() Failed to retrieve host status of host %s", hostId)
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve host manifest"}
+	}
+	if len(hostStatuses) == 0 || hostStatuses[0].HostManifest.HostInfo.HardwareUUID == "" {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "No host manifest is stored for the host"}
+	}
+	return &hostStatuses[0].HostManifest, http.StatusOK, nil
+}
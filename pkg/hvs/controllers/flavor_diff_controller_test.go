@@ -0,0 +1,162 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/mocks"
+	hvsRoutes "github.com/intel-secl/intel-secl/v4/pkg/hvs/router"
+	consts "github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
+	flavormodel "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/model"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/host-connector/types"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FlavorDiffController", func() {
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+	flavorId := uuid.MustParse("4e4b8a4f-3f5c-4d5e-9a6b-7c8d9e0f1a2b")
+	otherFlavorId := uuid.MustParse("5f5c9b50-4a6d-4e6f-8b7c-8d9e0f1a2b3c")
+	hostWithManifest := uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2")
+	pcr0 := types.Pcr{Index: 0, Bank: "SHA256"}
+	microcode := types.EventLog{TypeID: "0x1", TypeName: "EV_POST_CODE", Tags: []string{"Microcode"}, Measurement: "bb"}
+
+	BeforeEach(func() {
+		router = mux.NewRouter()
+		flavorStore := mocks.NewMockFlavorStore()
+		updatedMicrocode := microcode
+		updatedMicrocode.Measurement = "bc"
+		for id, event := range map[uuid.UUID]types.EventLog{flavorId: microcode, otherFlavorId: updatedMicrocode} {
+			_, err := flavorStore.Create(&hvs.SignedFlavor{Flavor: flavormodel.Flavor{
+				Meta: flavormodel.Meta{ID: id, Description: map[string]interface{}{flavormodel.Label: id.String()}},
+				Pcrs: []types.FlavorPcrs{{
+					Pcr:           pcr0,
+					Measurement:   event.Measurement,
+					PCRMatches:    true,
+					EventlogEqual: &types.EventLogEqual{Events: []types.EventLog{event}},
+				}},
+			}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		flavorDiffController := controllers.NewFlavorDiffController(flavorStore, mocks.NewMockHostStore(), mocks.NewMockHostStatusStore())
+		router.Handle("/flavors/{id}/diff", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(flavorDiffController.Diff))).Methods("GET")
+		w = httptest.NewRecorder()
+	})
+
+	diff := func(id uuid.UUID, query string) {
+		req, err := http.NewRequest("GET", "/flavors/"+id.String()+"/diff?"+query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+		router.ServeHTTP(w, req)
+	}
+
+	Describe("Diff two flavors", func() {
+		Context("With a stored other flavor", func() {
+			It("Should return the changed PCRs along with the candidate flavor", func() {
+				diff(flavorId, "otherFlavorId="+otherFlavorId.String()+"&includeCandidateFlavor=true")
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var flavorDiff hvs.FlavorDiff
+				Expect(json.Unmarshal(w.Body.Bytes(), &flavorDiff)).To(Succeed())
+				Expect(flavorDiff.FlavorId).To(Equal(flavorId))
+				Expect(*flavorDiff.OtherFlavorId).To(Equal(otherFlavorId))
+				Expect(flavorDiff.HostId).To(BeNil())
+				Expect(flavorDiff.Pcrs).To(HaveLen(1))
+				Expect(flavorDiff.Pcrs[0].Pcr).To(Equal(pcr0))
+				Expect(flavorDiff.Pcrs[0].Status).To(Equal(hvs.PcrDiffChanged))
+				Expect(flavorDiff.Pcrs[0].Removed).To(Equal([]types.EventLog{microcode}))
+				Expect(flavorDiff.Pcrs[0].Added).To(HaveLen(1))
+				Expect(flavorDiff.Pcrs[0].Added[0].Measurement).To(Equal("bc"))
+
+				Expect(flavorDiff.CandidateFlavor).NotTo(BeNil())
+				Expect(flavorDiff.CandidateFlavor.Meta.ID).To(Equal(uuid.Nil))
+				Expect(flavorDiff.CandidateFlavor.Pcrs[0].Measurement).To(Equal("bc"))
+				Expect(flavorDiff.CandidateFlavor.Pcrs[0].EventlogEqual.Events).To(Equal(flavorDiff.Pcrs[0].Added))
+			})
+
+			It("Should not return the candidate flavor unless requested", func() {
+				diff(flavorId, "otherFlavorId="+flavorId.String())
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var flavorDiff hvs.FlavorDiff
+				Expect(json.Unmarshal(w.Body.Bytes(), &flavorDiff)).To(Succeed())
+				Expect(flavorDiff.Pcrs).To(BeEmpty())
+				Expect(flavorDiff.CandidateFlavor).To(BeNil())
+			})
+		})
+
+		Context("With an unknown flavor", func() {
+			It("Should fail with not found", func() {
+				diff(uuid.New(), "otherFlavorId="+otherFlavorId.String())
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+
+		Context("With an unknown other flavor", func() {
+			It("Should fail with bad request", func() {
+				diff(flavorId, "otherFlavorId="+uuid.New().String())
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	Describe("Diff a flavor with a host manifest", func() {
+		Context("With a host having a stored manifest", func() {
+			It("Should compare the flavor with the latest host manifest", func() {
+				diff(flavorId, "hostId="+hostWithManifest.String())
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var flavorDiff hvs.FlavorDiff
+				Expect(json.Unmarshal(w.Body.Bytes(), &flavorDiff)).To(Succeed())
+				Expect(*flavorDiff.HostId).To(Equal(hostWithManifest))
+				Expect(flavorDiff.OtherFlavorId).To(BeNil())
+				Expect(flavorDiff.Pcrs).To(HaveLen(1))
+				Expect(flavorDiff.Pcrs[0].Status).To(Equal(hvs.PcrDiffChanged))
+				Expect(flavorDiff.Pcrs[0].ExpectedMeasurement).To(Equal("bb"))
+				Expect(flavorDiff.Pcrs[0].ActualMeasurement).NotTo(BeEmpty())
+			})
+		})
+
+		Context("With an unknown host", func() {
+			It("Should fail with bad request", func() {
+				diff(flavorId, "hostId="+uuid.New().String())
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	Describe("Diff with invalid query params", func() {
+		It("Should require exactly one of otherFlavorId and hostId", func() {
+			diff(flavorId, "")
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+			w = httptest.NewRecorder()
+			diff(flavorId, "otherFlavorId="+otherFlavorId.String()+"&hostId="+hostWithManifest.String())
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("Should reject invalid values", func() {
+			diff(flavorId, "otherFlavorId=abc")
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+			w = httptest.NewRecorder()
+			diff(flavorId, "hostId="+hostWithManifest.String()+"&includeCandidateFlavor=maybe")
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+			w = httptest.NewRecorder()
+			diff(flavorId, "hostId="+hostWithManifest.String()+"&flavorgroupName=automatic")
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
@@ -0,0 +1,253 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/services/hosttrust"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	commLogMsg "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/message"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
+	fc "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/common"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+// FlavorDryRunController tells which hosts a flavorgroup would trust if candidate flavors were added to it or its
+// match policies were changed. The hosts are verified against their latest stored manifest, no live host call is
+// made and no report is stored.
+type FlavorDryRunController struct {
+	FGStore  domain.FlavorGroupStore
+	HStore   domain.HostStore
+	HSStore  domain.HostStatusStore
+	Verifier domain.FlavorDryRunVerifier
+}
+
+func NewFlavorDryRunController(fgs domain.FlavorGroupStore, hs domain.HostStore, hss domain.HostStatusStore, verifier domain.FlavorDryRunVerifier) *FlavorDryRunController {
+	return &FlavorDryRunController{
+		FGStore:  fgs,
+		HStore:   hs,
+		HSStore:  hss,
+		Verifier: verifier,
+	}
+}
+
+// DryRun verifies the selected hosts with the candidate flavors and match policies and summarizes their trust status
+func (controller FlavorDryRunController) DryRun(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/flavor_dry_run_controller:DryRun() Entering")
+	defer defaultLog.Trace("controllers/flavor_dry_run_controller:DryRun() Leaving")
+
+	if r.Header.Get("Content-Type") != constants.HTTPMediaTypeJson {
+		return nil, http.StatusUnsupportedMediaType, &commErr.ResourceError{Message: "Invalid Content-Type"}
+	}
+	if r.ContentLength == 0 {
+		secLog.Error("controllers/flavor_dry_run_controller:DryRun() The request body is not provided")
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body is not provided"}
+	}
+
+	var dryRunReq hvs.FlavorDryRunRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&dryRunReq); err != nil {
+		secLog.WithError(err).Errorf("controllers/flavor_dry_run_controller:DryRun() %s : Failed to decode request body as flavor dry run request", commLogMsg.InvalidInputBadEncoding)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Unable to decode JSON request body"}
+	}
+	if err := validateFlavorDryRunRequest(dryRunReq); err != nil {
+		secLog.WithError(err).Errorf("controllers/flavor_dry_run_controller:DryRun() %s : Invalid flavor dry run request", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	flavorgroups, err := controller.FGStore.Search(&models.FlavorGroupFilterCriteria{NameEqualTo: dryRunReq.FlavorgroupName})
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/flavor_dry_run_controller:DryRun() Flavorgroup search operation failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve flavorgroup"}
+	}
+	if len(flavorgroups) == 0 {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Flavorgroup with given name does not exist"}
+	}
+	flavorgroup := flavorgroups[0]
+	if len(dryRunReq.FlavorMatchPolicies) > 0 {
+		flavorgroup.MatchPolicies = dryRunReq.FlavorMatchPolicies
+	}
+
+	candidates, err := getCandidateFlavors(dryRunReq, flavorgroup)
+	if err != nil {
+		secLog.WithError(err).Errorf("controllers/flavor_dry_run_controller:DryRun() %s : Invalid candidate flavor", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	hosts, status, err := controller.selectHosts(dryRunReq, flavorgroup)
+	if err != nil {
+		return nil, status, err
+	}
+
+	dryRunResponse := hvs.FlavorDryRunResponse{
+		FlavorgroupName:     flavorgroup.Name,
+		FlavorMatchPolicies: flavorgroup.MatchPolicies,
+		HostResults:         []hvs.FlavorDryRunHostResult{},
+	}
+	for _, host := range hosts {
+		hostResult := controller.verifyHost(host, flavorgroup, candidates)
+		if hostResult.Error != "" {
+			dryRunResponse.ErrorCount++
+		} else if hostResult.Trusted {
+			dryRunResponse.TrustedCount++
+		} else {
+			dryRunResponse.UntrustedCount++
+		}
+		dryRunResponse.HostResults = append(dryRunResponse.HostResults, hostResult)
+	}
+	return dryRunResponse, http.StatusOK, nil
+}
+
+func (controller FlavorDryRunController) verifyHost(host *hvs.Host, flavorgroup hvs.FlavorGroup, candidates []hvs.SignedFlavor) hvs.FlavorDryRunHostResult {
+	defaultLog.Trace("controllers/flavor_dry_run_controller:verifyHost() Entering")
+	defer defaultLog.Trace("controllers/flavor_dry_run_controller:verifyHost() Leaving")
+
+	hostResult := hvs.FlavorDryRunHostResult{HostId: host.Id, HostName: host.HostName}
+	hostStatuses, err := controller.HSStore.Search(&models.HostStatusFilterCriteria{HostId: host.Id, LatestPerHost: true})
+	if err != nil {
+		defaultLog.WithError(err).Errorf("controllers/flavor_dry_run_controller:verifyHost() Failed to retrieve host status of host %s", host.Id)
+		hostResult.Error = "Failed to retrieve host manifest"
+		return hostResult
+	}
+	if len(hostStatuses) == 0 || hostStatuses[0].HostManifest.HostInfo.HardwareUUID == "" {
+		hostResult.Error = "No host manifest is stored for the host"
+		return hostResult
+	}
+
+	trustReport, err := controller.Verifier.Verify(host.Id, &hostStatuses[0].HostManifest, flavorgroup, candidates)
+	if err != nil {
+		defaultLog.WithError(err).Errorf("controllers/flavor_dry_run_controller:verifyHost() Failed to verify host %s", host.Id)
+		hostResult.Error = "Failed to verify host"
+		return hostResult
+	}
+	hostResult.Trusted = trustReport.Trusted
+	for _, result := range trustReport.Results {
+		if !result.IsTrusted() {
+			hostResult.FailedResults = append(hostResult.FailedResults, result)
+		}
+	}
+	return hostResult
+}
+
+// selectHosts returns the hosts requested by id or name, or all the hosts linked to the flavorgroup
+func (controller FlavorDryRunController) selectHosts(dryRunReq hvs.FlavorDryRunRequest, flavorgroup hvs.FlavorGroup) ([]*hvs.Host, int, error) {
+	defaultLog.Trace("controllers/flavor_dry_run_controller:selectHosts() Entering")
+	defer defaultLog.Trace("controllers/flavor_dry_run_controller:selectHosts() Leaving")
+
+	hostIds := dryRunReq.HostIds
+	if len(dryRunReq.HostIds) == 0 && len(dryRunReq.HostNames) == 0 {
+		var err error
+		hostIds, err = controller.FGStore.SearchHostsByFlavorGroup(flavorgroup.ID)
+		if err != nil {
+			defaultLog.WithError(err).Error("controllers/flavor_dry_run_controller:selectHosts() Failed to retrieve hosts of flavorgroup")
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve hosts of flavorgroup"}
+		}
+	}
+
+	var criteria []models.HostFilterCriteria
+	for _, hostId := range hostIds {
+		criteria = append(criteria, models.HostFilterCriteria{Id: hostId})
+	}
+	for _, hostName := range dryRunReq.HostNames {
+		criteria = append(criteria, models.HostFilterCriteria{NameEqualTo: hostName})
+	}
+
+	var hosts []*hvs.Host
+	selected := make(map[uuid.UUID]bool)
+	for i := range criteria {
+		found, err := controller.HStore.Search(&criteria[i], nil)
+		if err != nil {
+			defaultLog.WithError(err).Error("controllers/flavor_dry_run_controller:selectHosts() Host search operation failed")
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve hosts"}
+		}
+		if len(found) == 0 {
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Host with given id or name does not exist"}
+		}
+		if !selected[found[0].Id] {
+			selected[found[0].Id] = true
+			hosts = append(hosts, found[0])
+		}
+	}
+	return hosts, http.StatusOK, nil
+}
+
+// getCandidateFlavors returns the candidate flavors of the request, a candidate without id is given a random one
+func getCandidateFlavors(dryRunReq hvs.FlavorDryRunRequest, flavorgroup hvs.FlavorGroup) ([]hvs.SignedFlavor, error) {
+	defaultLog.Trace("controllers/flavor_dry_run_controller:getCandidateFlavors() Entering")
+	defer defaultLog.Trace("controllers/flavor_dry_run_controller:getCandidateFlavors() Leaving")
+
+	candidates := append([]hvs.SignedFlavor{}, dryRunReq.SignedFlavorCollection.SignedFlavors...)
+	for _, flavor := range dryRunReq.FlavorCollection.Flavors {
+		candidates = append(candidates, hvs.SignedFlavor{Flavor: flavor.Flavor})
+	}
+
+	matchPolicies, _, _ := flavorgroup.GetMatchPolicyMaps()
+	for i := range candidates {
+		flavorPart, err := hosttrust.GetFlavorPart(candidates[i])
+		if err != nil {
+			return nil, errors.New("Flavor Part must be ASSET_TAG, SOFTWARE, HOST_UNIQUE, PLATFORM or OS")
+		}
+		if _, exists := matchPolicies[flavorPart]; !exists {
+			return nil, errors.Errorf("Flavorgroup has no match policy for flavor part %s", flavorPart)
+		}
+		if candidates[i].Flavor.Meta.ID == uuid.Nil {
+			candidates[i].Flavor.Meta.ID = uuid.New()
+		}
+	}
+	return candidates, nil
+}
+
+func validateFlavorDryRunRequest(dryRunReq hvs.FlavorDryRunRequest) error {
+	defaultLog.Trace("controllers/flavor_dry_run_controller:validateFlavorDryRunRequest() Entering")
+	defer defaultLog.Trace("controllers/flavor_dry_run_controller:validateFlavorDryRunRequest() Leaving")
+
+	if dryRunReq.FlavorgroupName == "" {
+		return errors.New("Flavorgroup name must be specified")
+	}
+	if err := validation.ValidateStrings([]string{dryRunReq.FlavorgroupName}); err != nil {
+		return errors.New("Valid flavorgroup name must be specified")
+	}
+	if len(dryRunReq.FlavorCollection.Flavors) == 0 && len(dryRunReq.SignedFlavorCollection.SignedFlavors) == 0 &&
+		len(dryRunReq.FlavorMatchPolicies) == 0 {
+		return errors.New("Candidate flavors or flavor match policies must be specified")
+	}
+	for _, policy := range dryRunReq.FlavorMatchPolicies {
+		if err := validateFlavorMatchPolicy(policy); err != nil {
+			return err
+		}
+	}
+	for _, hostName := range dryRunReq.HostNames {
+		if err := validation.ValidateHostname(hostName); err != nil {
+			return errors.New("Valid host names must be specified")
+		}
+	}
+	return nil
+}
+
+func validateFlavorMatchPolicy(policy hvs.FlavorMatchPolicy) error {
+	var flavorPart fc.FlavorPart
+	if err := (&flavorPart).Parse(policy.FlavorPart.String()); err != nil {
+		return errors.New("Flavor Part of a match policy must be ASSET_TAG, SOFTWARE, HOST_UNIQUE, PLATFORM or OS")
+	}
+	switch policy.MatchPolicy.MatchType {
+	case hvs.MatchTypeAnyOf, hvs.MatchTypeAllOf, hvs.MatchTypeLatest:
+	default:
+		return errors.New("Match type of a match policy must be ANY_OF, ALL_OF or LATEST")
+	}
+	switch policy.MatchPolicy.Required {
+	case hvs.FlavorRequired, hvs.FlavorRequiredIfDefined:
+	default:
+		return errors.New("Required policy of a match policy must be REQUIRED or REQUIRED_IF_DEFINED")
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/mocks"
+	hvsRoutes "github.com/intel-secl/intel-secl/v4/pkg/hvs/router"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/services/hosttrust"
+	consts "github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
+	cf "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/common"
+	flavormodel "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/model"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/host-connector/types"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/verifier"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeFlavorVerifier trusts the flavors unless their label starts with "untrusted" and records the flavors it
+// verified without checking the signature
+type fakeFlavorVerifier struct {
+	skippedSignatures map[uuid.UUID]bool
+}
+
+func (fv *fakeFlavorVerifier) Verify(hostManifest *types.HostManifest, signedFlavor *hvs.SignedFlavor, skipFlavorSignatureVerification bool) (*hvs.TrustReport, error) {
+	flavorId := signedFlavor.Flavor.Meta.ID
+	if skipFlavorSignatureVerification {
+		fv.skippedSignatures[flavorId] = true
+	}
+	flavorPart, err := hosttrust.GetFlavorPart(*signedFlavor)
+	if err != nil {
+		return nil, err
+	}
+	result := hvs.RuleResult{
+		Rule:     hvs.RuleInfo{Name: "PcrMatchesConstant", Markers: []cf.FlavorPart{flavorPart}},
+		FlavorId: &flavorId,
+		Trusted:  true,
+	}
+	if strings.HasPrefix(signedFlavor.Flavor.Meta.Description[flavormodel.Label].(string), "untrusted") {
+		result.Trusted = false
+		result.Faults = []hvs.Fault{{Name: "PcrValueMismatchSHA256", Description: "Host PCR 0 with value does not match expected value"}}
+	}
+	return &hvs.TrustReport{HostManifest: *hostManifest, Results: []hvs.RuleResult{result}, Trusted: result.Trusted}, nil
+}
+
+func (fv *fakeFlavorVerifier) GetVerifierCerts() verifier.VerifierCertificates {
+	return verifier.VerifierCertificates{}
+}
+
+func candidateFlavor(label string, flavorPart cf.FlavorPart) hvs.Flavors {
+	return hvs.Flavors{Flavor: hvs.Flavor{Meta: flavormodel.Meta{
+		Description: map[string]interface{}{flavormodel.Label: label, flavormodel.FlavorPart: flavorPart.String()},
+	}}}
+}
+
+var _ = Describe("FlavorDryRunController", func() {
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+	var flavorVerifier *fakeFlavorVerifier
+	storedFlavorId := uuid.MustParse("e6612219-bbd5-4259-8c7e-991e43729a86")
+	hostWithManifest := uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2")
+	hostWithoutManifest := uuid.MustParse("e57e5ea0-d465-461e-882d-1600090caa0d")
+	latestPlatformPolicy := hvs.FlavorMatchPolicies{
+		hvs.NewFlavorMatchPolicy(cf.FlavorPartPlatform, hvs.NewMatchPolicy(hvs.MatchTypeLatest, hvs.FlavorRequired)),
+	}
+
+	BeforeEach(func() {
+		router = mux.NewRouter()
+		flavorStore := mocks.NewMockFlavorStore()
+		flavorGroupStore := mocks.NewFakeFlavorgroupStore()
+		hostStore := mocks.NewMockHostStore()
+		flavorStore.FlavorFlavorGroupStore = map[uuid.UUID][]uuid.UUID{hostWithManifest: {storedFlavorId}}
+		flavorVerifier = &fakeFlavorVerifier{skippedSignatures: make(map[uuid.UUID]bool)}
+		dryRunVerifier := &hosttrust.DryRunVerifier{
+			FlavorStore:      flavorStore,
+			FlavorGroupStore: flavorGroupStore,
+			HostStore:        hostStore,
+			FlavorVerifier:   flavorVerifier,
+		}
+		flavorDryRunController := controllers.NewFlavorDryRunController(flavorGroupStore, hostStore, mocks.NewMockHostStatusStore(), dryRunVerifier)
+		router.Handle("/flavors/dry-run", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(flavorDryRunController.DryRun))).Methods("POST")
+		w = httptest.NewRecorder()
+	})
+
+	dryRun := func(dryRunReq hvs.FlavorDryRunRequest) hvs.FlavorDryRunResponse {
+		body, err := json.Marshal(dryRunReq)
+		Expect(err).NotTo(HaveOccurred())
+		req, err := http.NewRequest("POST", "/flavors/dry-run", bytes.NewBuffer(body))
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+		req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		var dryRunResponse hvs.FlavorDryRunResponse
+		Expect(json.Unmarshal(w.Body.Bytes(), &dryRunResponse)).To(Succeed())
+		return dryRunResponse
+	}
+
+	Describe("Dry run of flavor verification", func() {
+		Context("With match policies replacing those of the flavorgroup", func() {
+			It("Should verify the stored flavors against the hosts", func() {
+				dryRunResponse := dryRun(hvs.FlavorDryRunRequest{
+					FlavorgroupName:     "hvs_flavorgroup_test1",
+					FlavorMatchPolicies: latestPlatformPolicy,
+					HostIds:             []uuid.UUID{hostWithManifest},
+				})
+				Expect(dryRunResponse.FlavorMatchPolicies).To(Equal(latestPlatformPolicy))
+				Expect(dryRunResponse.TrustedCount).To(Equal(1))
+				Expect(dryRunResponse.HostResults).To(HaveLen(1))
+				Expect(dryRunResponse.HostResults[0].HostId).To(Equal(hostWithManifest))
+				Expect(dryRunResponse.HostResults[0].Trusted).To(BeTrue())
+				Expect(dryRunResponse.HostResults[0].FailedResults).To(BeEmpty())
+				Expect(flavorVerifier.skippedSignatures[storedFlavorId]).To(BeFalse())
+			})
+		})
+
+		Context("With a candidate flavor replacing the LATEST flavor", func() {
+			It("Should report the failing rules of the candidate", func() {
+				dryRunResponse := dryRun(hvs.FlavorDryRunRequest{
+					FlavorgroupName:     "hvs_flavorgroup_test1",
+					FlavorCollection:    hvs.FlavorCollection{Flavors: []hvs.Flavors{candidateFlavor("untrusted_bios", cf.FlavorPartPlatform)}},
+					FlavorMatchPolicies: latestPlatformPolicy,
+					HostNames:           []string{"localhost1", "localhost2"},
+				})
+				Expect(dryRunResponse.UntrustedCount).To(Equal(1))
+				Expect(dryRunResponse.ErrorCount).To(Equal(1))
+				Expect(dryRunResponse.HostResults).To(HaveLen(2))
+
+				untrusted := dryRunResponse.HostResults[0]
+				Expect(untrusted.HostId).To(Equal(hostWithManifest))
+				Expect(untrusted.Trusted).To(BeFalse())
+				Expect(untrusted.FailedResults).To(HaveLen(1))
+				Expect(untrusted.FailedResults[0].Faults[0].Name).To(Equal("PcrValueMismatchSHA256"))
+				candidateId := *untrusted.FailedResults[0].FlavorId
+				Expect(candidateId).NotTo(Equal(storedFlavorId))
+				Expect(flavorVerifier.skippedSignatures[candidateId]).To(BeTrue())
+
+				Expect(dryRunResponse.HostResults[1].HostId).To(Equal(hostWithoutManifest))
+				Expect(dryRunResponse.HostResults[1].Error).NotTo(BeEmpty())
+			})
+		})
+
+		Context("With invalid requests", func() {
+			It("Should return 400", func() {
+				for _, body := range []string{
+					``,
+					`{"flavorgroup_name": "hvs_flavorgroup_test1"}`,
+					`{"flavorgroup_name": "unknown", "flavor_match_policies": [{"flavor_part": "PLATFORM", "match_policy": {"match_type": "LATEST", "required": "REQUIRED"}}]}`,
+					`{"flavorgroup_name": "hvs_flavorgroup_test1", "flavor_match_policies": [{"flavor_part": "PLATFORM", "match_policy": {"match_type": "SOME_OF", "required": "REQUIRED"}}]}`,
+					`{"flavorgroup_name": "hvs_flavorgroup_test1", "flavor_match_policies": [{"flavor_part": "PLATFORM", "match_policy": {"match_type": "LATEST", "required": "REQUIRED"}}], "host_ids": ["` + uuid.New().String() + `"]}`,
+					`{"flavorgroup_name": "hvs_flavorgroup_test1", "flavor_match_policies": [{"flavor_part": "PLATFORM", "match_policy": {"match_type": "LATEST", "required": "REQUIRED"}}], "flavor_collection": {"flavors": [{"flavor": {"meta": {"description": {"label": "os", "flavor_part": "OS"}}}}]}}`,
+				} {
+					w = httptest.NewRecorder()
+					req, err := http.NewRequest("POST", "/flavors/dry-run", strings.NewReader(body))
+					Expect(err).NotTo(HaveOccurred())
+					req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+					req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+					router.ServeHTTP(w, req)
+					Expect(w.Code).To(Equal(http.StatusBadRequest), body)
+				}
+			})
+		})
+	})
+})
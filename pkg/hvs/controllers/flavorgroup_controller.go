@@ -30,7 +30,10 @@ type FlavorgroupController struct {
 	HTManager           domain.HostTrustManager
 }
 
-var flavorGroupSearchParams = map[string]bool{"id": true, "nameEqualTo": true, "nameContains": true, "includeFlavorContent": true}
+var flavorGroupSearchParams = utils.WithPageQueryParams(map[string]bool{"id": true, "nameEqualTo": true,
+	"nameContains": true, "includeFlavorContent": true})
+
+var flavorGroupSortFields = []string{models.SortByName, models.SortByID}
 
 func (controller FlavorgroupController) Create(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
 	defaultLog.Trace("controllers/flavorgroup_controller:Create() Entering")
@@ -121,6 +124,18 @@ func (controller FlavorgroupController) Search(w http.ResponseWriter, r *http.Re
 		}
 	}
 
+	page, err := utils.ParsePageCriteria(r.URL.Query(), flavorGroupSortFields)
+	if err != nil {
+		secLog.WithError(err).Errorf("controllers/flavorgroup_controller:Search()  %s", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid page criteria"}
+	}
+	if page != nil {
+		if filter == nil {
+			filter = &models.FlavorGroupFilterCriteria{}
+		}
+		filter.Page = page
+	}
+
 	flavorgroups, err := controller.FlavorGroupStore.Search(filter)
 	if err != nil {
 		secLog.WithError(err).Error("controllers/flavorgroup_controller:Search() Flavorgroup get all failed")
@@ -133,6 +148,14 @@ func (controller FlavorgroupController) Search(w http.ResponseWriter, r *http.Re
 			"associated with flavor group")
 		return nil, http.StatusInternalServerError, &commErr.ResourceError{"Unable to search Flavorgroups"}
 	}
+	if page != nil && len(flavorgroups) > 0 {
+		last := flavorgroups[len(flavorgroups)-1]
+		cursor := models.PageCursor{SortBy: page.SortBy, ID: last.ID}
+		if page.SortBy == models.SortByName {
+			cursor.Value = last.Name
+		}
+		flavorgroupCollection.Next = utils.NextPageLink(r, page, len(flavorgroups), cursor)
+	}
 
 	secLog.Infof("%s: Return flavorgroup query to: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
 	return flavorgroupCollection, http.StatusOK, nil
@@ -51,8 +51,13 @@ func NewHostController(hs domain.HostStore, hss domain.HostStatusStore, fs domai
 	}
 }
 
-var hostSearchParams = map[string]bool{"id": true, "nameEqualTo": true, "nameContains": true, "hostHardwareId": true,
-	"key": true, "value": true, "trusted": true, "getTrustStatus": true, "getHostStatus": true, "orderBy": true}
+var hostSearchParams = utils.WithPageQueryParams(map[string]bool{"id": true, "nameEqualTo": true, "nameContains": true,
+	"hostHardwareId": true, "key": true, "value": true, "trusted": true, "getTrustStatus": true, "getHostStatus": true,
+	"orderBy": true, "labelSelector": true})
+
+var hostTrustSummaryParams = map[string]bool{"labelKey": true, "labelSelector": true}
+
+var hostSortFields = []string{models.SortByName, models.SortByID}
 
 var hostRetrieveParams = map[string]bool{"getReport": true, "getHostStatus": true}
 
@@ -144,6 +149,7 @@ func (hc *HostController) Update(w http.ResponseWriter, r *http.Request) (interf
 		Description:      reqHost.Description,
 		ConnectionString: reqHost.ConnectionString,
 		FlavorgroupNames: reqHost.FlavorgroupNames,
+		Labels:           reqHost.Labels,
 	}
 
 	if err := validateHostCreateCriteria(criteria); err != nil {
@@ -233,11 +239,55 @@ func (hc *HostController) Search(w http.ResponseWriter, r *http.Request) (interf
 		return nil, http.StatusInternalServerError, errors.Errorf("Failed to search Hosts")
 	}
 	hostCollection := hvs.HostCollection{Hosts: hosts}
+	if hostFilterCriteria.Page != nil && len(hosts) > 0 {
+		hostCollection.Next = utils.NextPageLink(r, hostFilterCriteria.Page, len(hosts),
+			hostPageCursor(hostFilterCriteria.Page, hosts[len(hosts)-1]))
+	}
 
 	secLog.Infof("%s: Hosts searched by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
 	return hostCollection, http.StatusOK, nil
 }
 
+// TrustSummary counts the hosts sharing each value of a label by trust status, the hosts being optionally selected
+// by a label selector
+func (hc *HostController) TrustSummary(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/host_controller:TrustSummary() Entering")
+	defer defaultLog.Trace("controllers/host_controller:TrustSummary() Leaving")
+
+	params := r.URL.Query()
+	if err := utils.ValidateQueryParams(params, hostTrustSummaryParams); err != nil {
+		secLog.Errorf("controllers/host_controller:TrustSummary() %s", err.Error())
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	labelKey := params.Get("labelKey")
+	if err := hvs.ValidateLabelKey(labelKey); err != nil {
+		secLog.WithError(err).Errorf("controllers/host_controller:TrustSummary() %s Invalid labelKey query param value",
+			commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Valid labelKey query param value must be specified"}
+	}
+
+	var selector hvs.LabelSelector
+	if params.Get("labelSelector") != "" {
+		var err error
+		selector, err = hvs.ParseLabelSelector(params.Get("labelSelector"))
+		if err != nil {
+			secLog.WithError(err).Errorf("controllers/host_controller:TrustSummary() %s Invalid labelSelector query param value",
+				commLogMsg.InvalidInputBadParam)
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid labelSelector query param value"}
+		}
+	}
+
+	counts, err := hc.HStore.SummarizeTrustByLabel(labelKey, selector)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/host_controller:TrustSummary() Host trust summary failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to summarize Host trust"}
+	}
+
+	secLog.Infof("%s: Host trust summarized by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return hvs.HostLabelTrustSummary{LabelKey: labelKey, Values: counts}, http.StatusOK, nil
+}
+
 func (hc *HostController) CreateHost(reqHost hvs.HostCreateRequest) (interface{}, int, error) {
 	defaultLog.Trace("controllers/host_controller:CreateHost() Entering")
 	defer defaultLog.Trace("controllers/host_controller:CreateHost() Leaving")
@@ -317,6 +367,7 @@ func (hc *HostController) CreateHost(reqHost hvs.HostCreateRequest) (interface{}
 		ConnectionString: csWithoutCredentials,
 		HardwareUuid:     hwUuid,
 		FlavorgroupNames: fgNames,
+		Labels:           reqHost.Labels,
 	}
 
 	createdHost, err := hc.HStore.Create(host)
@@ -676,6 +727,9 @@ func validateHostCreateCriteria(host hvs.HostCreateRequest) error {
 			return errors.Wrap(err, "Valid Flavorgroup Names must be specified")
 		}
 	}
+	if err := hvs.ValidateLabels(host.Labels); err != nil {
+		return errors.Wrap(err, "Valid Labels must be specified")
+	}
 	return nil
 }
 
@@ -729,6 +783,14 @@ func populateHostFilterCriteria(params url.Values) (*models.HostFilterCriteria,
 		criteria.Trusted = &trustStatus
 	}
 
+	if params.Get("labelSelector") != "" {
+		selector, err := hvs.ParseLabelSelector(params.Get("labelSelector"))
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid labelSelector query param value")
+		}
+		criteria.LabelSelector = selector
+	}
+
 	if params.Get("orderBy") != "" {
 		orderType, err := models.GetOrderType(params.Get("orderBy"))
 		if err != nil {
@@ -737,9 +799,24 @@ func populateHostFilterCriteria(params url.Values) (*models.HostFilterCriteria,
 		criteria.OrderBy = orderType
 	}
 
+	page, err := utils.ParsePageCriteria(params, hostSortFields)
+	if err != nil {
+		return nil, err
+	}
+	criteria.Page = page
+
 	return &criteria, nil
 }
 
+// hostPageCursor returns the cursor positioned on the given host for the requested sort field
+func hostPageCursor(page *models.PageCriteria, host *hvs.Host) models.PageCursor {
+	cursor := models.PageCursor{SortBy: page.SortBy, ID: host.Id}
+	if page.SortBy == models.SortByName {
+		cursor.Value = host.HostName
+	}
+	return cursor
+}
+
 func populateHostInfoFetchCriteria(params url.Values) (*models.HostInfoFetchCriteria, error) {
 	defaultLog.Trace("controllers/host_controller:populateHostInfoFetchCriteria() Entering")
 	defer defaultLog.Trace("controllers/host_controller:populateHostInfoFetchCriteria() Leaving")
@@ -10,6 +10,7 @@ import (
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/controllers"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/mocks"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
 	hvsRoutes "github.com/intel-secl/intel-secl/v4/pkg/hvs/router"
 	smocks "github.com/intel-secl/intel-secl/v4/pkg/hvs/services/hosttrust/mocks"
 	consts "github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
@@ -19,6 +20,7 @@ import (
 	"net/http/httptest"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -89,6 +91,55 @@ var _ = Describe("HostController", func() {
 				Expect(w.Code).To(Equal(http.StatusCreated))
 			})
 		})
+		Context("Provide a Create request with labels", func() {
+			It("Should create a new labeled Host", func() {
+				router.Handle("/hosts", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostController.Create))).Methods("POST")
+				hostJson := `{
+								"host_name": "localhost3",
+								"connection_string": "intel:https://another.ta.ip.com:1443",
+								"labels": {"env": "prod", "rack": "12"}
+							}`
+
+				req, err := http.NewRequest(
+					"POST",
+					"/hosts",
+					strings.NewReader(hostJson),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusCreated))
+
+				var host hvs.Host
+				err = json.Unmarshal(w.Body.Bytes(), &host)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(host.Labels).To(Equal(map[string]string{"env": "prod", "rack": "12"}))
+			})
+		})
+		Context("Provide a Create request that contains invalid labels", func() {
+			It("Should fail to create new Host", func() {
+				router.Handle("/hosts", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostController.Create))).Methods("POST")
+				hostJson := `{
+								"host_name": "localhost3",
+								"connection_string": "intel:https://another.ta.ip.com:1443",
+								"labels": {"env": "prod env"}
+							}`
+
+				req, err := http.NewRequest(
+					"POST",
+					"/hosts",
+					strings.NewReader(hostJson),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
 		Context("Provide a Create request that contains duplicate hostname", func() {
 			It("Should fail to create new Host", func() {
 				router.Handle("/hosts", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostController.Create))).Methods("POST")
@@ -459,6 +510,60 @@ var _ = Describe("HostController", func() {
 				Expect(w.Code).To(Equal(http.StatusBadRequest))
 			})
 		})
+		Context("Get the Hosts one page at a time", func() {
+			It("Should get each Host in a separate page", func() {
+				router.Handle("/hosts", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostController.Search))).Methods("GET")
+				req, err := http.NewRequest("GET", "/hosts?limit=1", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var hostCollection hvs.HostCollection
+				err = json.Unmarshal(w.Body.Bytes(), &hostCollection)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(hostCollection.Hosts)).To(Equal(1))
+				Expect(hostCollection.Hosts[0].HostName).To(Equal("localhost1"))
+				Expect(hostCollection.Next).NotTo(BeEmpty())
+
+				req, err = http.NewRequest("GET", hostCollection.Next, nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				hostCollection = hvs.HostCollection{}
+				err = json.Unmarshal(w.Body.Bytes(), &hostCollection)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(hostCollection.Hosts)).To(Equal(1))
+				Expect(hostCollection.Hosts[0].HostName).To(Equal("localhost2"))
+			})
+		})
+		Context("Get the Hosts with invalid limit param", func() {
+			It("Should fail to get Hosts", func() {
+				router.Handle("/hosts", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostController.Search))).Methods("GET")
+				req, err := http.NewRequest("GET", "/hosts?limit=0", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+		Context("Get the Hosts with an after cursor issued for another sortBy", func() {
+			It("Should fail to get Hosts", func() {
+				router.Handle("/hosts", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostController.Search))).Methods("GET")
+				after := models.PageCursor{SortBy: models.SortByName, Value: "localhost1", ID: uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2")}
+				req, err := http.NewRequest("GET", "/hosts?sortBy=id&after="+after.Encode(), nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
 		Context("Get all the Hosts with invalid nameContains param", func() {
 			It("Should fail to get Hosts", func() {
 				router.Handle("/hosts", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostController.Search))).Methods("GET")
@@ -528,6 +633,101 @@ var _ = Describe("HostController", func() {
 		})
 	})
 
+	// Specs for HTTP Get to "/hosts" and "/hosts/trust-summary" with labeled Hosts
+	Describe("Search the labeled Hosts", func() {
+		trusted, untrusted := true, false
+		BeforeEach(func() {
+			for _, host := range []*hvs.Host{
+				{Id: uuid.New(), HostName: "rack12-prod", Labels: map[string]string{"rack": "12", "env": "prod"}, Trusted: &trusted},
+				{Id: uuid.New(), HostName: "rack12-dev", Labels: map[string]string{"rack": "12", "env": "dev"}, Trusted: &untrusted},
+				{Id: uuid.New(), HostName: "rack13-prod", Labels: map[string]string{"rack": "13", "env": "prod"}},
+			} {
+				_, err := hostStore.Create(host)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			router.Handle("/hosts", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostController.Search))).Methods("GET")
+			router.Handle("/hosts/trust-summary", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostController.TrustSummary))).Methods("GET")
+		})
+
+		search := func(query string) []string {
+			req, err := http.NewRequest("GET", "/hosts?"+query, nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				return nil
+			}
+
+			var hostCollection hvs.HostCollection
+			Expect(json.Unmarshal(w.Body.Bytes(), &hostCollection)).To(Succeed())
+			var names []string
+			for _, host := range hostCollection.Hosts {
+				names = append(names, host.HostName)
+			}
+			return names
+		}
+
+		Context("Get the Hosts with a valid labelSelector param", func() {
+			It("Should get the Hosts meeting all the requirements", func() {
+				Expect(search("labelSelector=env%3Dprod")).To(ConsistOf("rack12-prod", "rack13-prod"))
+				Expect(search("labelSelector=rack+in+(12,14),env!%3Dprod")).To(ConsistOf("rack12-dev"))
+				// the hosts without the label are not in the set of values
+				Expect(search("labelSelector=rack+notin+(12)")).To(ConsistOf("localhost1", "localhost2", "rack13-prod"))
+				Expect(search("labelSelector=cluster%3Da")).To(BeEmpty())
+				Expect(search("nameContains=prod&labelSelector=rack%3D12")).To(ConsistOf("rack12-prod"))
+			})
+		})
+		Context("Get the Hosts with an invalid labelSelector param", func() {
+			It("Should fail to get Hosts", func() {
+				search("labelSelector=rack+in+12")
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+		Context("Summarize the Host trust by label value", func() {
+			It("Should count the Hosts by trust status", func() {
+				req, err := http.NewRequest("GET", "/hosts/trust-summary?labelKey=rack", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var summary hvs.HostLabelTrustSummary
+				Expect(json.Unmarshal(w.Body.Bytes(), &summary)).To(Succeed())
+				Expect(summary.LabelKey).To(Equal("rack"))
+				Expect(summary.Values).To(Equal([]hvs.HostLabelTrustCount{
+					{Value: "12", Total: 2, Trusted: 1, Untrusted: 1},
+					{Value: "13", Total: 1, Unknown: 1},
+				}))
+
+				req, err = http.NewRequest("GET", "/hosts/trust-summary?labelKey=rack&labelSelector=env%3Dprod", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				summary = hvs.HostLabelTrustSummary{}
+				Expect(json.Unmarshal(w.Body.Bytes(), &summary)).To(Succeed())
+				Expect(summary.Values).To(Equal([]hvs.HostLabelTrustCount{
+					{Value: "12", Total: 1, Trusted: 1},
+					{Value: "13", Total: 1, Unknown: 1},
+				}))
+			})
+		})
+		Context("Summarize the Host trust without labelKey param", func() {
+			It("Should fail to summarize the Host trust", func() {
+				req, err := http.NewRequest("GET", "/hosts/trust-summary?labelSelector=env%3Dprod", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
 	// Specs for HTTP Post to "/hosts/{hId}/flavorgroups"
 	Describe("Create a new Host Flavorgroup link", func() {
 		Context("Provide a valid Flavorgroup Id", func() {
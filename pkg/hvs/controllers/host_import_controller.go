@@ -0,0 +1,242 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/services/hostimport"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/utils"
+	consts "github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	commLogMsg "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/message"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+// HTTPMediaTypeCsv is the content type of the CSV bulk host import requests
+const HTTPMediaTypeCsv = "text/csv"
+
+// csv columns of a bulk host import, the flavorgroup names are separated with ';'
+const (
+	hostImportColumnHostName         = "host_name"
+	hostImportColumnConnectionString = "connection_string"
+	hostImportColumnDescription      = "description"
+	hostImportColumnFlavorgroupNames = "flavorgroup_names"
+	hostImportColumnUsername         = "username"
+	hostImportColumnPassword         = "password"
+)
+
+var hostImportColumns = map[string]bool{hostImportColumnHostName: true, hostImportColumnConnectionString: true,
+	hostImportColumnDescription: true, hostImportColumnFlavorgroupNames: true, hostImportColumnUsername: true,
+	hostImportColumnPassword: true}
+
+// HostImportController registers hosts in bulk, the hosts being registered in the background by the HostImporter
+type HostImportController struct {
+	Importer domain.HostImporter
+	JobStore domain.HostImportJobStore
+}
+
+func NewHostImportController(importer domain.HostImporter, jobStore domain.HostImportJobStore) *HostImportController {
+	return &HostImportController{
+		Importer: importer,
+		JobStore: jobStore,
+	}
+}
+
+// Create submits a bulk host import job from a JSON or CSV list of hosts
+func (controller HostImportController) Create(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/host_import_controller:Create() Entering")
+	defer defaultLog.Trace("controllers/host_import_controller:Create() Leaving")
+
+	if r.ContentLength == 0 {
+		secLog.Error("controllers/host_import_controller:Create() The request body was not provided")
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body was not provided"}
+	}
+
+	var entries []hvs.HostImportEntry
+	var err error
+	switch r.Header.Get("Content-Type") {
+	case consts.HTTPMediaTypeJson:
+		entries, err = decodeHostImportJson(r.Body)
+	case HTTPMediaTypeCsv:
+		entries, err = decodeHostImportCsv(r.Body)
+	default:
+		return nil, http.StatusUnsupportedMediaType, &commErr.ResourceError{Message: "Invalid Content-Type"}
+	}
+	if err != nil {
+		secLog.WithError(err).Errorf("controllers/host_import_controller:Create() %s : Failed to decode request body as host import request", commLogMsg.InvalidInputBadEncoding)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+	if len(entries) == 0 {
+		secLog.Errorf("controllers/host_import_controller:Create() %s : No host provided", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "At least one host must be provided"}
+	}
+	if len(entries) > constants.MaxHostImportRows {
+		secLog.Errorf("controllers/host_import_controller:Create() %s : Too many hosts provided", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: fmt.Sprintf("At most %d hosts can be imported at once", constants.MaxHostImportRows)}
+	}
+
+	job, err := controller.Importer.Submit(newHostImportJob(entries))
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/host_import_controller:Create() Failed to submit host import job")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to submit host import job"}
+	}
+	secLog.WithField("job", job.ID).Infof("%s: Host import of %d host(s) submitted by: %s", commLogMsg.PrivilegeModified, job.Total, r.RemoteAddr)
+	return job, http.StatusAccepted, nil
+}
+
+// Retrieve reports the progress of a bulk host import job along with the result of each of its hosts
+func (controller HostImportController) Retrieve(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/host_import_controller:Retrieve() Entering")
+	defer defaultLog.Trace("controllers/host_import_controller:Retrieve() Leaving")
+
+	id := uuid.MustParse(mux.Vars(r)["id"])
+	job, err := controller.JobStore.Retrieve(id)
+	if err != nil {
+		if strings.Contains(err.Error(), commErr.RowsNotFound) {
+			secLog.WithError(err).WithField("id", id).Info("controllers/host_import_controller:Retrieve() Host import job with given ID does not exist")
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Host import job with given ID does not exist"}
+		}
+		defaultLog.WithError(err).WithField("id", id).Error("controllers/host_import_controller:Retrieve() Failed to retrieve host import job")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve host import job with the given ID"}
+	}
+	return job, http.StatusOK, nil
+}
+
+// Retry registers again the hosts of a completed bulk host import job that failed
+func (controller HostImportController) Retry(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/host_import_controller:Retry() Entering")
+	defer defaultLog.Trace("controllers/host_import_controller:Retry() Leaving")
+
+	id := uuid.MustParse(mux.Vars(r)["id"])
+	job, err := controller.Importer.Retry(id)
+	if err != nil {
+		switch errors.Cause(err) {
+		case hostimport.ErrJobRunning:
+			return nil, http.StatusConflict, &commErr.ResourceError{Message: err.Error()}
+		case hostimport.ErrNoFailedRows:
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+		}
+		if strings.Contains(err.Error(), commErr.RowsNotFound) {
+			secLog.WithError(err).WithField("id", id).Info("controllers/host_import_controller:Retry() Host import job with given ID does not exist")
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Host import job with given ID does not exist"}
+		}
+		defaultLog.WithError(err).WithField("id", id).Error("controllers/host_import_controller:Retry() Failed to retry host import job")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retry host import job with the given ID"}
+	}
+	secLog.WithField("job", id).Infof("%s: Host import of %d host(s) retried by: %s", commLogMsg.PrivilegeModified, job.Pending, r.RemoteAddr)
+	return job, http.StatusAccepted, nil
+}
+
+// newHostImportJob numbers the rows from 1, in the order of the request. The hosts whose name was already provided
+// in a previous row are failed right away.
+func newHostImportJob(entries []hvs.HostImportEntry) *hvs.HostImportJob {
+	job := &hvs.HostImportJob{Rows: make([]hvs.HostImportRow, 0, len(entries))}
+	rowsByName := make(map[string]int)
+	for i, entry := range entries {
+		row := hvs.HostImportRow{
+			Row:              i + 1,
+			HostName:         entry.HostName,
+			ConnectionString: utils.GetConnectionStringWithoutCredentials(entry.ConnectionString),
+			Status:           hvs.HostImportRowPending,
+			Entry:            entry,
+		}
+		if previous, ok := rowsByName[entry.HostName]; ok && entry.HostName != "" {
+			row.Status = hvs.HostImportRowFailed
+			row.Error = fmt.Sprintf("Host name already provided in row %d", previous)
+		} else {
+			rowsByName[entry.HostName] = row.Row
+		}
+		job.Rows = append(job.Rows, row)
+	}
+	job.CountRows()
+	return job
+}
+
+func decodeHostImportJson(body io.Reader) ([]hvs.HostImportEntry, error) {
+	dec := json.NewDecoder(body)
+	dec.DisallowUnknownFields()
+
+	var req hvs.HostImportRequest
+	if err := dec.Decode(&req); err != nil {
+		return nil, errors.New("Unable to decode JSON request body")
+	}
+	return req.Hosts, nil
+}
+
+// decodeHostImportCsv reads the hosts from CSV records, the first record naming the columns
+func decodeHostImportCsv(body io.Reader) ([]hvs.HostImportEntry, error) {
+	reader := csv.NewReader(body)
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("Unable to decode CSV request body header")
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !hostImportColumns[name] {
+			return nil, errors.Errorf("Invalid CSV column %q", name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, errors.Errorf("Duplicate CSV column %q", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns[hostImportColumnHostName]; !ok {
+		return nil, errors.Errorf("CSV column %q must be provided", hostImportColumnHostName)
+	}
+	if _, ok := columns[hostImportColumnConnectionString]; !ok {
+		return nil, errors.Errorf("CSV column %q must be provided", hostImportColumnConnectionString)
+	}
+
+	var entries []hvs.HostImportEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Errorf("Unable to decode CSV request body record %d", len(entries)+1)
+		}
+		if len(entries) == constants.MaxHostImportRows {
+			return nil, errors.Errorf("At most %d hosts can be imported at once", constants.MaxHostImportRows)
+		}
+		// the password is taken as is, the other fields are trimmed
+		rawField := func(column string) string {
+			if i, ok := columns[column]; ok {
+				return record[i]
+			}
+			return ""
+		}
+		field := func(column string) string {
+			return strings.TrimSpace(rawField(column))
+		}
+		entry := hvs.HostImportEntry{
+			HostName:         field(hostImportColumnHostName),
+			ConnectionString: field(hostImportColumnConnectionString),
+			Description:      field(hostImportColumnDescription),
+			Username:         field(hostImportColumnUsername),
+			Password:         rawField(hostImportColumnPassword),
+		}
+		for _, fgName := range strings.Split(field(hostImportColumnFlavorgroupNames), ";") {
+			if fgName = strings.TrimSpace(fgName); fgName != "" {
+				entry.FlavorgroupNames = append(entry.FlavorgroupNames, fgName)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,223 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/mocks"
+	hvsRoutes "github.com/intel-secl/intel-secl/v4/pkg/hvs/router"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/services/hostimport"
+	consts "github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// importHostCreator fails the hosts whose name starts with "bad"
+type importHostCreator struct{}
+
+func (importHostCreator) CreateHost(req hvs.HostCreateRequest) (interface{}, int, error) {
+	if strings.HasPrefix(req.HostName, "bad") {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Host is unreachable"}
+	}
+	return &hvs.Host{Id: uuid.New(), HostName: req.HostName}, http.StatusCreated, nil
+}
+
+var _ = Describe("HostImportController", func() {
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+	var jobStore *mocks.MockHostImportJobStore
+
+	BeforeEach(func() {
+		router = mux.NewRouter()
+		jobStore = mocks.NewMockHostImportJobStore()
+		importer, err := hostimport.NewHostImporter(domain.HostImporterConfig{JobStore: jobStore, HostCreator: importHostCreator{}})
+		Expect(err).NotTo(HaveOccurred())
+		hostImportController := controllers.NewHostImportController(importer, jobStore)
+		router.Handle("/hosts/bulk", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostImportController.Create))).Methods("POST")
+		router.Handle("/hosts/bulk/{id}", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostImportController.Retrieve))).Methods("GET")
+		router.Handle("/hosts/bulk/{id}/retry", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostImportController.Retry))).Methods("POST")
+		w = httptest.NewRecorder()
+	})
+
+	submit := func(contentType, body string) *hvs.HostImportJob {
+		req, err := http.NewRequest("POST", "/hosts/bulk", strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+		req.Header.Set("Content-Type", contentType)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusAccepted {
+			return nil
+		}
+		var job hvs.HostImportJob
+		Expect(json.Unmarshal(w.Body.Bytes(), &job)).To(Succeed())
+		return &job
+	}
+
+	completed := func(id uuid.UUID) *hvs.HostImportJob {
+		var job *hvs.HostImportJob
+		Eventually(func() hvs.HostImportJobStatus {
+			var err error
+			job, err = jobStore.Retrieve(id)
+			Expect(err).NotTo(HaveOccurred())
+			return job.Status
+		}, 5*time.Second, 10*time.Millisecond).Should(Equal(hvs.HostImportJobCompleted))
+		return job
+	}
+
+	Describe("Submit a host import job", func() {
+		Context("With a JSON list of hosts", func() {
+			It("Should register the hosts in the background", func() {
+				job := submit(consts.HTTPMediaTypeJson, `{"hosts": [
+					{"host_name": "host-1", "connection_string": "intel:https://host-1:1443;u=admin;p=password", "flavorgroup_names": ["automatic"]},
+					{"host_name": "bad-host", "connection_string": "intel:https://bad-host:1443", "username": "admin", "password": "password"},
+					{"host_name": "host-1", "connection_string": "intel:https://host-1:1443"}
+				]}`)
+				Expect(w.Code).To(Equal(http.StatusAccepted))
+				Expect(job.ID).NotTo(Equal(uuid.Nil))
+				Expect(job.Total).To(Equal(3))
+				Expect(job.Rows[0].ConnectionString).To(Equal("intel:https://host-1:1443"))
+				Expect(w.Body.String()).NotTo(ContainSubstring("password"))
+
+				stored := completed(job.ID)
+				Expect(stored.Succeeded).To(Equal(1))
+				Expect(stored.Failed).To(Equal(2))
+				Expect(stored.Rows[1].Error).To(Equal("Host is unreachable"))
+				Expect(stored.Rows[2].Error).To(Equal("Host name already provided in row 1"))
+				Expect(stored.Rows[2].Attempts).To(Equal(0))
+			})
+		})
+
+		Context("With a CSV list of hosts", func() {
+			It("Should register the hosts in the background", func() {
+				job := submit(controllers.HTTPMediaTypeCsv, "host_name,connection_string,flavorgroup_names,username,password\n"+
+					"host-1,intel:https://host-1:1443,automatic;platform_software,admin, pass word\n"+
+					"host-2,intel:https://host-2:1443,,,\n")
+				Expect(w.Code).To(Equal(http.StatusAccepted))
+				Expect(job.Total).To(Equal(2))
+
+				stored := completed(job.ID)
+				Expect(stored.Succeeded).To(Equal(2))
+				Expect(stored.Rows[0].Entry.FlavorgroupNames).To(Equal([]string{"automatic", "platform_software"}))
+				Expect(stored.Rows[0].Entry.Password).To(Equal(" pass word"))
+				Expect(stored.Rows[1].Entry.FlavorgroupNames).To(BeEmpty())
+			})
+		})
+
+		Context("With an invalid request", func() {
+			It("Should fail with bad request", func() {
+				submit(consts.HTTPMediaTypeJson, `{"hosts": []}`)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+				w = httptest.NewRecorder()
+				submit(consts.HTTPMediaTypeJson, `{"hosts": [{"host_name": "host-1", "unknown": true}]}`)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+				w = httptest.NewRecorder()
+				submit(controllers.HTTPMediaTypeCsv, "host_name,address\nhost-1,host-1\n")
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+				w = httptest.NewRecorder()
+				submit(controllers.HTTPMediaTypeCsv, "host_name\nhost-1\n")
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+				w = httptest.NewRecorder()
+				submit(controllers.HTTPMediaTypeCsv, "host_name,connection_string\nhost-1\n")
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+
+			It("Should fail with unsupported media type", func() {
+				submit(consts.HTTPMediaTypeXml, "<hosts/>")
+				Expect(w.Code).To(Equal(http.StatusUnsupportedMediaType))
+			})
+		})
+	})
+
+	Describe("Retrieve a host import job", func() {
+		It("Should report the result of each host", func() {
+			job := submit(consts.HTTPMediaTypeJson, `{"hosts": [{"host_name": "host-1", "connection_string": "intel:https://host-1:1443"}]}`)
+			completed(job.ID)
+
+			w = httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/hosts/bulk/"+job.ID.String(), nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			var retrieved hvs.HostImportJob
+			Expect(json.Unmarshal(w.Body.Bytes(), &retrieved)).To(Succeed())
+			Expect(retrieved.Status).To(Equal(hvs.HostImportJobCompleted))
+			Expect(retrieved.Rows).To(HaveLen(1))
+			Expect(retrieved.Rows[0].Status).To(Equal(hvs.HostImportRowSucceeded))
+			Expect(retrieved.Rows[0].HostId).NotTo(BeNil())
+		})
+
+		It("Should fail with not found for an unknown job", func() {
+			req, err := http.NewRequest("GET", "/hosts/bulk/"+uuid.New().String(), nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("Retry a host import job", func() {
+		retry := func(id uuid.UUID) {
+			w = httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/hosts/bulk/"+id.String()+"/retry", nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+			router.ServeHTTP(w, req)
+		}
+
+		It("Should register again the failed hosts only", func() {
+			job := submit(consts.HTTPMediaTypeJson, `{"hosts": [
+				{"host_name": "host-1", "connection_string": "intel:https://host-1:1443"},
+				{"host_name": "bad-host", "connection_string": "intel:https://bad-host:1443"}
+			]}`)
+			completed(job.ID)
+
+			retry(job.ID)
+			Expect(w.Code).To(Equal(http.StatusAccepted))
+			stored := completed(job.ID)
+			Expect(stored.Rows[0].Attempts).To(Equal(1))
+			Expect(stored.Rows[1].Attempts).To(Equal(2))
+			Expect(stored.Rows[1].Status).To(Equal(hvs.HostImportRowFailed))
+		})
+
+		It("Should fail when there is no failed host", func() {
+			job := submit(consts.HTTPMediaTypeJson, `{"hosts": [{"host_name": "host-1", "connection_string": "intel:https://host-1:1443"}]}`)
+			completed(job.ID)
+
+			retry(job.ID)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("Should fail with conflict while the job is running", func() {
+			job, err := jobStore.Create(&hvs.HostImportJob{Status: hvs.HostImportJobRunning})
+			Expect(err).NotTo(HaveOccurred())
+
+			retry(job.ID)
+			Expect(w.Code).To(Equal(http.StatusConflict))
+		})
+
+		It("Should fail with not found for an unknown job", func() {
+			retry(uuid.New())
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
@@ -29,8 +29,12 @@ type HostStatusController struct {
 	Store domain.HostStatusStore
 }
 
-var hostStatusSearchParams = map[string]bool{"id": true, "hostId": true, "hostHardwareId": true, "hostName": true, "hostStatus": true,
-	"fromDate": true, "toDate": true, "latestPerHost": true, "numberOfDays": true, "limit": true}
+var hostStatusSearchParams = utils.WithPageQueryParams(map[string]bool{"id": true, "hostId": true, "hostHardwareId": true,
+	"hostName": true, "hostStatus": true, "fromDate": true, "toDate": true, "latestPerHost": true, "numberOfDays": true,
+	"labelSelector": true})
+
+// hostStatusSortFields are shared by the host status and report searches
+var hostStatusSortFields = []string{models.SortByCreated, models.SortByID}
 
 // Search returns a collection of HostStatus based on HostStatusFilter criteria
 func (controller HostStatusController) Search(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
@@ -55,8 +59,21 @@ func (controller HostStatusController) Search(w http.ResponseWriter, r *http.Req
 		return nil, http.StatusInternalServerError, errors.Errorf("Host Status search operation failed")
 	}
 
+	hsCollection := hvs.HostStatusCollection{HostStatuses: hostStatusCollection}
+	if filter.Page != nil && len(hostStatusCollection) > 0 {
+		last := hostStatusCollection[len(hostStatusCollection)-1]
+		cursor := models.PageCursor{SortBy: filter.Page.SortBy, ID: last.ID}
+		if filter.Page.SortBy == models.SortByCreated {
+			cursor.Value = models.PageCursorTime(last.Created)
+		}
+		if filter.Page.Last != nil {
+			cursor = *filter.Page.Last
+		}
+		hsCollection.Next = utils.NextPageLink(r, filter.Page, len(hostStatusCollection), cursor)
+	}
+
 	secLog.Infof("%s: Return Host Status Search query to: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
-	return hvs.HostStatusCollection{HostStatuses: hostStatusCollection}, http.StatusOK, nil
+	return hsCollection, http.StatusOK, nil
 }
 
 // Retrieve returns an existing HostStatus entry from the HostStatusStore
@@ -142,6 +159,16 @@ func getHSFilterCriteria(params url.Values) (*models.HostStatusFilterCriteria, e
 		hfc.HostStatus = hostState
 	}
 
+	// Label selector
+	labelSelector := strings.TrimSpace(params.Get("labelSelector"))
+	if labelSelector != "" {
+		selector, err := hvs.ParseLabelSelector(labelSelector)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid labelSelector specified")
+		}
+		hfc.LabelSelector = selector
+	}
+
 	// fromDate
 	fromDate := strings.TrimSpace(params.Get("fromDate"))
 	if fromDate != "" {
@@ -201,5 +228,11 @@ func getHSFilterCriteria(params url.Values) (*models.HostStatusFilterCriteria, e
 		hfc.Limit = constants.DefaultSearchResultRowLimit
 	}
 
+	page, err := utils.ParseLimitedSearchPageCriteria(params, hostStatusSortFields)
+	if err != nil {
+		return nil, err
+	}
+	hfc.Page = page
+
 	return &hfc, nil
 }
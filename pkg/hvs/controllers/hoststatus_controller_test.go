@@ -292,6 +292,27 @@ var _ = Describe("HostStatusController", func() {
 				err = json.Unmarshal(w.Body.Bytes(), &hsCollection)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(hsCollection.HostStatuses)).To(Equal(4))
+				// a limit alone does not page the results
+				Expect(hsCollection.Next).To(BeEmpty())
+			})
+		})
+
+		Context("When paging the HostStatus search results", func() {
+			It("Should get a link to the next page", func() {
+				router.Handle("/host-status", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostStatusController.Search))).Methods("GET")
+				req, err := http.NewRequest("GET", "/host-status?sortBy=created&limit=4", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var hsCollection *hvs.HostStatusCollection
+				err = json.Unmarshal(w.Body.Bytes(), &hsCollection)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(hsCollection.HostStatuses)).To(Equal(4))
+				Expect(hsCollection.Next).To(ContainSubstring("after="))
+				Expect(hsCollection.Next).To(ContainSubstring("sortBy=created"))
 			})
 		})
 
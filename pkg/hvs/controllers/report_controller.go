@@ -21,10 +21,22 @@ import (
 	"github.com/pkg/errors"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
+var reportSummaryParams = map[string]bool{"labelSelector": true, "groupBy": true, "fromDate": true, "toDate": true,
+	"numberOfDays": true, "bucketSize": true}
+
+// reportSummaryBreakdowns maps the groupBy values of a report summary to the criteria fields requesting them
+var reportSummaryBreakdowns = map[string]func(*models.ReportSummaryCriteria){
+	"flavorPart": func(criteria *models.ReportSummaryCriteria) { criteria.ByFlavorPart = true },
+	"rule":       func(criteria *models.ReportSummaryCriteria) { criteria.ByRule = true },
+	"hostState":  func(criteria *models.ReportSummaryCriteria) { criteria.ByHostState = true },
+}
+
 type ReportController struct {
 	ReportStore     domain.ReportStore
 	HostStore       domain.HostStore
@@ -65,6 +77,15 @@ func (controller ReportController) Create(w http.ResponseWriter, r *http.Request
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Bad input given in input request"}
 	}
 
+	if reqReportCreateRequest.LabelSelector != "" {
+		hostIds, status, err := controller.verifyHostsByLabel(reqReportCreateRequest.LabelSelector)
+		if err != nil {
+			return nil, status, err
+		}
+		secLog.WithField("labelSelector", reqReportCreateRequest.LabelSelector).Infof("%s: %d host(s) queued for verification by: %s", commLogMsg.PrivilegeModified, len(hostIds), r.RemoteAddr)
+		return hvs.ReportBulkCreateResponse{HostIDs: hostIds}, http.StatusAccepted, nil
+	}
+
 	hvsReport, err := controller.createReport(reqReportCreateRequest)
 	if err != nil {
 		defaultLog.WithError(err).Error("controllers/report_controller:Create() Error while creating report")
@@ -112,6 +133,34 @@ func (controller ReportController) createReport(rsCriteria hvs.ReportCreateReque
 	return hvsReport, nil
 }
 
+// verifyHostsByLabel queues for verification the hosts selected by the label selector, the reports being created
+// in the background
+func (controller ReportController) verifyHostsByLabel(labelSelector string) ([]uuid.UUID, int, error) {
+	defaultLog.Trace("controllers/report_controller:verifyHostsByLabel() Entering")
+	defer defaultLog.Trace("controllers/report_controller:verifyHostsByLabel() Leaving")
+
+	// the selector was validated along with the request
+	selector, _ := hvs.ParseLabelSelector(labelSelector)
+	hosts, err := controller.HostStore.Search(&models.HostFilterCriteria{LabelSelector: selector}, nil)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/report_controller:verifyHostsByLabel() Error while searching hosts")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Error while searching hosts"}
+	}
+	if len(hosts) == 0 {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Host for given criteria does not exist"}
+	}
+
+	hostIds := make([]uuid.UUID, 0, len(hosts))
+	for _, host := range hosts {
+		hostIds = append(hostIds, host.Id)
+	}
+	if err := controller.HTManager.VerifyHostsAsync(hostIds, true, false); err != nil {
+		defaultLog.WithError(err).Error("controllers/report_controller:verifyHostsByLabel() Hosts to Flavor Verify Queue addition failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to add Hosts to Flavor Verify Queue"}
+	}
+	return hostIds, http.StatusAccepted, nil
+}
+
 func (controller ReportController) CreateSaml(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
 	defaultLog.Trace("controllers/report_controller:CreateSaml() Entering")
 	defer defaultLog.Trace("controllers/report_controller:CreateSaml() Leaving")
@@ -144,6 +193,10 @@ func (controller ReportController) CreateSaml(w http.ResponseWriter, r *http.Req
 		secLog.WithError(err).Errorf("controllers/report_controller:CreateSaml() %s : Error validating report create criteria", commLogMsg.InvalidInputBadParam)
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Bad input given in input request"}
 	}
+	if reqReportCreateRequest.LabelSelector != "" {
+		secLog.Errorf("controllers/report_controller:CreateSaml() %s : Label selector provided for SAML report", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "SAML report can only be created for a single host"}
+	}
 
 	hvsReport, err := controller.createReport(reqReportCreateRequest)
 	if err != nil {
@@ -211,6 +264,17 @@ func (controller ReportController) Search(w http.ResponseWriter, r *http.Request
 	for _, hvsReport := range hvsReportCollection {
 		reportCollection.Reports = append(reportCollection.Reports, ConvertToReport(&hvsReport))
 	}
+	if reportFilterCriteria.Page != nil && len(hvsReportCollection) > 0 {
+		last := hvsReportCollection[len(hvsReportCollection)-1]
+		cursor := models.PageCursor{SortBy: reportFilterCriteria.Page.SortBy, ID: last.ID}
+		if reportFilterCriteria.Page.SortBy == models.SortByCreated {
+			cursor.Value = models.PageCursorTime(last.CreatedAt)
+		}
+		if reportFilterCriteria.Page.Last != nil {
+			cursor = *reportFilterCriteria.Page.Last
+		}
+		reportCollection.Next = utils.NextPageLink(r, reportFilterCriteria.Page, len(hvsReportCollection), cursor)
+	}
 	secLog.Infof("%s: Reports searched by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
 	return reportCollection, http.StatusOK, nil
 }
@@ -254,6 +318,146 @@ func (controller ReportController) SearchSaml(w http.ResponseWriter, r *http.Req
 	return samlCollection.String(), http.StatusOK, nil
 }
 
+// Summary returns the aggregate trust counts of the hosts along with the requested breakdowns
+func (controller ReportController) Summary(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/report_controller:Summary() Entering")
+	defer defaultLog.Trace("controllers/report_controller:Summary() Leaving")
+
+	if err := utils.ValidateQueryParams(r.URL.Query(), reportSummaryParams); err != nil {
+		secLog.Errorf("controllers/report_controller:Summary() %s", err.Error())
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	criteria, err := getReportSummaryCriteria(r.URL.Query())
+	if err != nil {
+		secLog.WithError(err).Warnf("controllers/report_controller:Summary() %s", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	summary, err := controller.ReportStore.Summarize(criteria)
+	if err != nil {
+		defaultLog.WithError(err).Warn("controllers/report_controller:Summary() Report summary operation failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to summarize reports"}
+	}
+
+	if criteria.ByHostState {
+		summary.HostStates, err = controller.HostStatusStore.CountByHostState(criteria.LabelSelector)
+		if err != nil {
+			defaultLog.WithError(err).Warn("controllers/report_controller:Summary() Host state count operation failed")
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to summarize reports"}
+		}
+	}
+
+	if !criteria.FromDate.IsZero() {
+		failures, err := controller.HostStatusStore.CountConnectionFailures(criteria)
+		if err != nil {
+			defaultLog.WithError(err).Warn("controllers/report_controller:Summary() Connection failure count operation failed")
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to summarize reports"}
+		}
+		summary.TimeBuckets = mergeTimeBuckets(summary.TimeBuckets, failures)
+	}
+
+	secLog.Infof("%s: Report summary retrieved by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return summary, http.StatusOK, nil
+}
+
+// getReportSummaryCriteria checks the params of the Summary request and returns a valid ReportSummaryCriteria
+func getReportSummaryCriteria(params url.Values) (*models.ReportSummaryCriteria, error) {
+	defaultLog.Trace("controllers/report_controller:getReportSummaryCriteria() Entering")
+	defer defaultLog.Trace("controllers/report_controller:getReportSummaryCriteria() Leaving")
+
+	criteria := models.ReportSummaryCriteria{BucketSize: models.BucketSizeDay}
+
+	labelSelector := strings.TrimSpace(params.Get("labelSelector"))
+	if labelSelector != "" {
+		selector, err := hvs.ParseLabelSelector(labelSelector)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid labelSelector specified")
+		}
+		criteria.LabelSelector = selector
+	}
+
+	groupBy := strings.TrimSpace(params.Get("groupBy"))
+	if groupBy != "" {
+		for _, breakdown := range strings.Split(groupBy, ",") {
+			setBreakdown, ok := reportSummaryBreakdowns[strings.TrimSpace(breakdown)]
+			if !ok {
+				return nil, errors.New("groupBy must list flavorPart, rule or hostState")
+			}
+			setBreakdown(&criteria)
+		}
+	}
+
+	fromDate := strings.TrimSpace(params.Get("fromDate"))
+	if fromDate != "" {
+		pTime, err := utils.ParseDateQueryParam(fromDate)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid fromDate specified")
+		}
+		criteria.FromDate = pTime
+	}
+
+	toDate := strings.TrimSpace(params.Get("toDate"))
+	if toDate != "" {
+		pTime, err := utils.ParseDateQueryParam(toDate)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid toDate specified")
+		}
+		criteria.ToDate = pTime
+	}
+
+	numberOfDays := strings.TrimSpace(params.Get("numberOfDays"))
+	if numberOfDays != "" {
+		numDays, err := strconv.Atoi(numberOfDays)
+		if err != nil || numDays <= 0 || numDays > consts.MaxNumDaysSearchLimit {
+			return nil, errors.New("NumberOfDays must be an integer > 0 and <= 365")
+		}
+		criteria.ToDate = time.Now().UTC()
+		criteria.FromDate = criteria.ToDate.AddDate(0, 0, -numDays)
+	}
+
+	if criteria.FromDate.IsZero() && !criteria.ToDate.IsZero() {
+		return nil, errors.New("fromDate or numberOfDays must be specified along with toDate")
+	}
+	if !criteria.FromDate.IsZero() {
+		if criteria.ToDate.IsZero() {
+			criteria.ToDate = time.Now().UTC()
+		}
+		if !criteria.FromDate.Before(criteria.ToDate) {
+			return nil, errors.New("fromDate must be before toDate")
+		}
+	}
+
+	bucketSize := strings.TrimSpace(params.Get("bucketSize"))
+	if bucketSize != "" {
+		if bucketSize != models.BucketSizeHour && bucketSize != models.BucketSizeDay && bucketSize != models.BucketSizeWeek {
+			return nil, errors.New("bucketSize must be hour, day or week")
+		}
+		criteria.BucketSize = bucketSize
+	}
+
+	return &criteria, nil
+}
+
+// mergeTimeBuckets adds the connection failures to the report time buckets, keeping them ordered by start time
+func mergeTimeBuckets(buckets, failures []hvs.TrustTimeBucket) []hvs.TrustTimeBucket {
+	indexes := make(map[int64]int, len(buckets))
+	for i, bucket := range buckets {
+		indexes[bucket.Start.Unix()] = i
+	}
+	for _, failure := range failures {
+		if i, ok := indexes[failure.Start.Unix()]; ok {
+			buckets[i].ConnectionFailure = failure.ConnectionFailure
+		} else {
+			buckets = append(buckets, failure)
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Start.Before(buckets[j].Start)
+	})
+	return buckets
+}
+
 // getReportFilterCriteria checks for set filter params in the Search request and returns a valid ReportFilterCriteria
 func getReportFilterCriteria(params url.Values) (*models.ReportFilterCriteria, error) {
 	defaultLog.Trace("controllers/report_controller:getReportFilterCriteria() Entering")
@@ -309,6 +513,16 @@ func getReportFilterCriteria(params url.Values) (*models.ReportFilterCriteria, e
 		rfc.HostStatus = hostState
 	}
 
+	// Label selector
+	labelSelector := strings.TrimSpace(params.Get("labelSelector"))
+	if labelSelector != "" {
+		selector, err := hvs.ParseLabelSelector(labelSelector)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid labelSelector specified")
+		}
+		rfc.LabelSelector = selector
+	}
+
 	// fromDate
 	fromDate := strings.TrimSpace(params.Get("fromDate"))
 	if fromDate != "" {
@@ -362,6 +576,12 @@ func getReportFilterCriteria(params url.Values) (*models.ReportFilterCriteria, e
 		rfc.Limit = consts.DefaultSearchResultRowLimit
 	}
 
+	page, err := utils.ParseLimitedSearchPageCriteria(params, hostStatusSortFields)
+	if err != nil {
+		return nil, err
+	}
+	rfc.Page = page
+
 	return &rfc, nil
 }
 
@@ -369,6 +589,16 @@ func validateReportCreateCriteria(re hvs.ReportCreateRequest) error {
 	defaultLog.Trace("controllers/report_controller:validateReportCreateCriteria() Entering")
 	defer defaultLog.Trace("controllers/report_controller:validateReportCreateCriteria() Leaving")
 
+	if re.LabelSelector != "" {
+		if re.HostName != "" || re.HostID != uuid.Nil || re.HardwareUUID != uuid.Nil {
+			return errors.New("labelSelector cannot be specified along with hostName, hostId or hostHardwareUuid")
+		}
+		if _, err := hvs.ParseLabelSelector(re.LabelSelector); err != nil {
+			return errors.Wrap(err, "Invalid labelSelector")
+		}
+		return nil
+	}
+
 	if re.HostName == "" && re.HostID == uuid.Nil && re.HardwareUUID == uuid.Nil {
 		return errors.New("hostName, hostId and hostHardwareUuid must be specified")
 	}
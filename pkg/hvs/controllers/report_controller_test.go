@@ -7,6 +7,7 @@ package controllers_test
 import (
 	"encoding/json"
 	"encoding/xml"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/controllers"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/mocks"
@@ -19,6 +20,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 )
 
 var _ = Describe("ReportController", func() {
@@ -145,6 +147,79 @@ var _ = Describe("ReportController", func() {
 				Expect(w.Code).To(Equal(http.StatusBadRequest))
 			})
 		})
+		Context("Provide a Create request with a label selector", func() {
+			It("Should queue the selected hosts for verification", func() {
+				for _, host := range []*hvs.Host{
+					{Id: uuid.New(), HostName: "rack12-prod", Labels: map[string]string{"rack": "12", "env": "prod"}},
+					{Id: uuid.New(), HostName: "rack12-dev", Labels: map[string]string{"rack": "12", "env": "dev"}},
+				} {
+					_, err := hostStore.Create(host)
+					Expect(err).NotTo(HaveOccurred())
+				}
+				router.Handle("/reports", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Create))).Methods("POST")
+				body := `{
+							"label_selector": "rack=12,env in (prod,staging)"
+						}`
+
+				req, err := http.NewRequest(
+					"POST",
+					"/reports",
+					strings.NewReader(body),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", constants.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusAccepted))
+
+				var response hvs.ReportBulkCreateResponse
+				err = json.Unmarshal(w.Body.Bytes(), &response)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(response.HostIDs).To(HaveLen(1))
+			})
+		})
+		Context("Provide a Create request with a label selector that selects no host", func() {
+			It("Should see an 400 error", func() {
+				router.Handle("/reports", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Create))).Methods("POST")
+				body := `{
+							"label_selector": "rack=99"
+						}`
+
+				req, err := http.NewRequest(
+					"POST",
+					"/reports",
+					strings.NewReader(body),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", constants.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+		Context("Provide a Create request with a label selector along with a hostname", func() {
+			It("Should see an 400 error", func() {
+				router.Handle("/reports", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Create))).Methods("POST")
+				body := `{
+							"host_name": "localhost1",
+							"label_selector": "rack=12"
+						}`
+
+				req, err := http.NewRequest(
+					"POST",
+					"/reports",
+					strings.NewReader(body),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", constants.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
 	})
 
 	// Specs for HTTP Get to "/reports/{rId}"
@@ -324,6 +399,115 @@ var _ = Describe("ReportController", func() {
 				Expect(w.Code).To(Equal(http.StatusBadRequest))
 			})
 		})
+
+		Context("Provide a Create request with a label selector", func() {
+			It("Should fail to create Report", func() {
+				router.Handle("/reports", hvsRoutes.ErrorHandler(hvsRoutes.ResponseHandler(reportController.CreateSaml))).Methods("POST")
+				body := `{
+							"label_selector": "rack=12"
+						}`
+
+				req, err := http.NewRequest(
+					"POST",
+					"/reports",
+					strings.NewReader(body),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", constants.HTTPMediaTypeSaml)
+				req.Header.Set("Content-Type", constants.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	// Specs for HTTP Get to "/reports/summary"
+	Describe("Summarize the Reports", func() {
+		Context("Get the trust counts", func() {
+			It("Should count the hosts by trust status", func() {
+				router.Handle("/reports/summary", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Summary))).Methods("GET")
+				req, err := http.NewRequest("GET", "/reports/summary", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var summary hvs.ReportSummary
+				err = json.Unmarshal(w.Body.Bytes(), &summary)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(summary.Total).To(Equal(2))
+				Expect(summary.Trusted).To(Equal(2))
+				Expect(summary.FlavorParts).To(BeEmpty())
+				Expect(summary.TimeBuckets).To(BeEmpty())
+			})
+		})
+
+		Context("Get the trust counts broken down by flavor part, rule and host state", func() {
+			It("Should return the requested breakdowns", func() {
+				router.Handle("/reports/summary", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Summary))).Methods("GET")
+				req, err := http.NewRequest("GET", "/reports/summary?groupBy=flavorPart,rule,hostState", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var summary hvs.ReportSummary
+				err = json.Unmarshal(w.Body.Bytes(), &summary)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(summary.FlavorParts).To(Equal([]hvs.FlavorPartTrustCount{
+					{FlavorPart: "HOST_UNIQUE", Trusted: 2},
+					{FlavorPart: "OS", Trusted: 2},
+					{FlavorPart: "PLATFORM", Trusted: 2},
+					{FlavorPart: "SOFTWARE", Trusted: 2},
+				}))
+				Expect(summary.FailingRules).To(BeEmpty())
+				Expect(summary.HostStates).To(Equal([]hvs.HostStateCount{
+					{HostState: "CONNECTED", Hosts: 1},
+					{HostState: "CONNECTION_FAILURE", Hosts: 1},
+				}))
+			})
+		})
+
+		Context("Get the trust counts over a date range", func() {
+			It("Should merge the report and connection failure time buckets", func() {
+				router.Handle("/reports/summary", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Summary))).Methods("GET")
+				req, err := http.NewRequest("GET", "/reports/summary?fromDate=2020-06-20&toDate=2020-06-24&bucketSize=day", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var summary hvs.ReportSummary
+				err = json.Unmarshal(w.Body.Bytes(), &summary)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(summary.TimeBuckets).To(HaveLen(2))
+				Expect(summary.TimeBuckets[0].Start.Equal(time.Date(2020, 6, 21, 0, 0, 0, 0, time.UTC))).To(BeTrue())
+				Expect(summary.TimeBuckets[0].Trusted).To(Equal(2))
+				Expect(summary.TimeBuckets[0].ConnectionFailure).To(Equal(1))
+				Expect(summary.TimeBuckets[1].Start.Equal(time.Date(2020, 6, 23, 0, 0, 0, 0, time.UTC))).To(BeTrue())
+				Expect(summary.TimeBuckets[1].Trusted).To(Equal(0))
+				Expect(summary.TimeBuckets[1].ConnectionFailure).To(Equal(2))
+			})
+		})
+
+		Context("Provide invalid summary params", func() {
+			It("Should return bad request", func() {
+				router.Handle("/reports/summary", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Summary))).Methods("GET")
+				for _, query := range []string{"groupBy=host", "bucketSize=month", "toDate=2020-06-24",
+					"fromDate=2020-06-24&toDate=2020-06-20", "numberOfDays=0", "labelSelector=rack", "limit=10"} {
+					req, err := http.NewRequest("GET", "/reports/summary?"+query, nil)
+					Expect(err).NotTo(HaveOccurred())
+					req.Header.Set("Accept", constants.HTTPMediaTypeJson)
+					w = httptest.NewRecorder()
+					router.ServeHTTP(w, req)
+					Expect(w.Code).To(Equal(http.StatusBadRequest), query)
+				}
+			})
+		})
 	})
 
 	// Specs for HTTP Get to "/reports" for accept:samlassertion+xml
@@ -508,7 +508,7 @@ func (controller TagCertificateController) Deploy(w http.ResponseWriter, r *http
 	var flavorPartMap = make(map[fc.FlavorPart][]hvs.SignedFlavor)
 	flavorPartMap[fc.FlavorPartAssetTag] = []hvs.SignedFlavor{*sf}
 
-	linkedSf, err := controller.FlavorController.addFlavorToFlavorgroup(flavorPartMap, nil)
+	linkedSf, err := controller.FlavorController.addFlavorToFlavorgroup(flavorPartMap, nil, nil)
 	if err != nil || linkedSf == nil {
 		defaultLog.WithError(err).WithField("Certid", dtcReq.CertID).WithField("flavorID", sf.Flavor.Meta.ID).
 			Errorf("controllers/tagcertificate_controller:Deploy() %s : Failed to link SignedFlavor to Host "+
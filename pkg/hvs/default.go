@@ -95,6 +95,19 @@ func init() {
 	viper.SetDefault(constants.HrrsRefreshPeriod, hrrs.DefaultRefreshPeriod)
 
 	viper.SetDefault(constants.VcssRefreshPeriod, constants.DefaultVcssRefreshPeriod)
+
+	viper.SetDefault(constants.EventsBufferSize, constants.DefaultEventsBufferSize)
+	viper.SetDefault(constants.EventsWebhookMaxAttempts, constants.DefaultEventsWebhookMaxAttempts)
+	viper.SetDefault(constants.EventsWebhookRetryDelay, constants.DefaultEventsWebhookRetryDelay)
+	viper.SetDefault(constants.EventsWebhookTimeout, constants.DefaultEventsWebhookTimeout)
+
+	viper.SetDefault(constants.HostImportConcurrency, constants.DefaultHostImportConcurrency)
+
+	viper.SetDefault(constants.RevocationCheckEnabled, false)
+	viper.SetDefault(constants.RevocationCheckFetchCrl, true)
+	viper.SetDefault(constants.RevocationCheckOcsp, false)
+	viper.SetDefault(constants.RevocationCheckCrlCacheDuration, constants.DefaultRevocationCheckCrlCacheDuration)
+	viper.SetDefault(constants.RevocationCheckFailOpen, false)
 }
 
 func defaultConfig() *config.Configuration {
@@ -167,12 +180,34 @@ func defaultConfig() *config.Configuration {
 		VCSS: config.VCSSConfig{
 			RefreshPeriod: viper.GetDuration(constants.VcssRefreshPeriod),
 		},
+		Events: config.EventsConfig{
+			BufferSize:         viper.GetInt(constants.EventsBufferSize),
+			WebhookMaxAttempts: viper.GetInt(constants.EventsWebhookMaxAttempts),
+			WebhookRetryDelay:  viper.GetDuration(constants.EventsWebhookRetryDelay),
+			WebhookTimeout:     viper.GetDuration(constants.EventsWebhookTimeout),
+		},
+		HostImport: config.HostImportConfig{
+			Concurrency: viper.GetInt(constants.HostImportConcurrency),
+		},
 		FVS: config.FVSConfig{
 			NumberOfVerifiers:               viper.GetInt(constants.FvsNumberOfVerifiers),
 			NumberOfDataFetchers:            viper.GetInt(constants.FvsNumberOfDataFetchers),
 			SkipFlavorSignatureVerification: viper.GetBool(constants.FvsSkipFlavorSignatureVerification),
 			HostTrustCacheThreshold:         viper.GetInt(constants.FvsHostTrustCacheThreshold),
 		},
+		RevocationCheck: revocationCheckConfig(),
+	}
+}
+
+// revocationCheckConfig returns the certificate revocation check configuration set in the environment
+func revocationCheckConfig() commConfig.RevocationCheckConfig {
+	return commConfig.RevocationCheckConfig{
+		Enabled:          viper.GetBool(constants.RevocationCheckEnabled),
+		CrlFile:          viper.GetString(constants.RevocationCheckCrlFile),
+		FetchCrl:         viper.GetBool(constants.RevocationCheckFetchCrl),
+		Ocsp:             viper.GetBool(constants.RevocationCheckOcsp),
+		CrlCacheDuration: viper.GetDuration(constants.RevocationCheckCrlCacheDuration),
+		FailOpen:         viper.GetBool(constants.RevocationCheckFailOpen),
 	}
 }
 
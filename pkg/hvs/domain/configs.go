@@ -6,6 +6,8 @@
 package domain
 
 import (
+	"time"
+
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/host-connector"
@@ -63,3 +65,20 @@ type HostConnectionConfig struct {
 	ServiceUsername string
 	ServicePassword string
 }
+
+type EventPublisherConfig struct {
+	SubscriptionStore EventSubscriptionStore
+	BufferSize        int
+	// Maximum number of delivery attempts of an event to a webhook
+	WebhookMaxAttempts int
+	// Delay before the first retry of a failed delivery, doubled on each following retry
+	WebhookRetryDelay time.Duration
+	WebhookTimeout    time.Duration
+}
+
+type HostImporterConfig struct {
+	JobStore    HostImportJobStore
+	HostCreator HostCreator
+	// Maximum number of hosts registered at the same time
+	Concurrency int
+}
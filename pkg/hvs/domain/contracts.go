@@ -55,6 +55,9 @@ type (
 		RemoveHostUniqueFlavors(hId uuid.UUID, fIds []uuid.UUID) error
 		RetrieveHostUniqueFlavors(hId uuid.UUID) ([]uuid.UUID, error)
 		RetrieveDistinctUniqueFlavorParts(hId uuid.UUID) ([]string, error)
+		// SummarizeTrustByLabel counts by trust status the hosts selected by the label selector, for each value of
+		// the given label
+		SummarizeTrustByLabel(labelKey string, selector hvs.LabelSelector) ([]hvs.HostLabelTrustCount, error)
 	}
 
 	HostCredentialStore interface {
@@ -71,6 +74,11 @@ type (
 		Retrieve(uuid.UUID) (*hvs.SignedFlavor, error)
 		Search(*models.FlavorVerificationFC) ([]hvs.SignedFlavor, error)
 		Delete(uuid.UUID) error
+		RetrieveLifecycle(uuid.UUID) (*hvs.FlavorLifecycle, error)
+		SearchLifecycles([]uuid.UUID) ([]hvs.FlavorLifecycle, error)
+		UpdateLifecycle(*hvs.FlavorLifecycle) (*hvs.FlavorLifecycle, error)
+		CreateWithLifecycle(*hvs.SignedFlavor, *hvs.FlavorLifecycle) (*hvs.SignedFlavor, error)
+		SearchLifecycleTransitions(from, to time.Time) ([]hvs.FlavorLifecycle, error)
 	}
 
 	TpmEndorsementStore interface {
@@ -102,6 +110,8 @@ type (
 		Delete(uuid.UUID) error
 		Persist(*hvs.HostStatus) error
 		FindHostIdsByKeyValue(key, value string) ([]uuid.UUID, error)
+		CountByHostState(hvs.LabelSelector) ([]hvs.HostStateCount, error)
+		CountConnectionFailures(*models.ReportSummaryCriteria) ([]hvs.TrustTimeBucket, error)
 	}
 
 	QueueStore interface {
@@ -119,6 +129,7 @@ type (
 		Update(*models.HVSReport) (*models.HVSReport, error)
 		Delete(uuid.UUID) error
 		FindHostIdsFromExpiredReports(fromTime time.Time, toTime time.Time) ([]uuid.UUID, error)
+		Summarize(*models.ReportSummaryCriteria) (*hvs.ReportSummary, error)
 	}
 
 	ESXiClusterStore interface {
@@ -178,6 +189,12 @@ type (
 		Verify(hostId uuid.UUID, hostData *types.HostManifest, newData bool, preferHashMatch bool) (*models.HVSReport, error)
 	}
 
+	// FlavorDryRunVerifier verifies a flavorgroup along with candidate flavors against a host manifest without
+	// persisting anything
+	FlavorDryRunVerifier interface {
+		Verify(hostId uuid.UUID, hostData *types.HostManifest, fg hvs.FlavorGroup, candidates []hvs.SignedFlavor) (*hvs.TrustReport, error)
+	}
+
 	AuditLogWriter interface {
 		// creates an entry of auditlog
 		CreateEntry(string, ...interface{}) (*models.AuditLogEntry, error)
@@ -186,10 +203,56 @@ type (
 		Stop()
 	}
 
+	// EventSubscriptionStore specifies the DB operations for the webhook subscriptions to trust events
+	EventSubscriptionStore interface {
+		Create(*hvs.EventSubscription) (*hvs.EventSubscription, error)
+		Retrieve(uuid.UUID) (*hvs.EventSubscription, error)
+		Update(*hvs.EventSubscription) (*hvs.EventSubscription, error)
+		Delete(uuid.UUID) error
+		Search(*models.EventSubscriptionFilterCriteria) ([]hvs.EventSubscription, error)
+	}
+
+	EventPublisher interface {
+		// queues the event for delivery to the webhook subscriptions and the event stream listeners
+		Publish(*hvs.TrustEvent)
+		// registers an event stream listener for the given event types, or for all events when none are given.
+		// The returned function unregisters the listener and must be called once the listener is done.
+		Listen(eventTypes []string) (<-chan *hvs.TrustEvent, func())
+		Stop()
+	}
+
 	AuditLogEntryStore interface {
 		Create(*models.AuditLogEntry) (*models.AuditLogEntry, error)
 		Retrieve(*models.AuditLogEntry) ([]models.AuditLogEntry, error)
 		Update(*models.AuditLogEntry) (*models.AuditLogEntry, error)
 		Delete(uuid.UUID) error
+		Search(*models.AuditLogFilterCriteria) ([]models.AuditLogEntry, error)
+		// RetrieveLatest returns the last entry of the hash chain, nil if the chain is empty
+		RetrieveLatest() (*models.AuditLogEntry, error)
+	}
+
+	// HostImportJobStore specifies the DB operations for the bulk host import jobs, the requested hosts are stored
+	// along with their credentials so that the jobs can be resumed and retried
+	HostImportJobStore interface {
+		Create(*hvs.HostImportJob) (*hvs.HostImportJob, error)
+		Retrieve(uuid.UUID) (*hvs.HostImportJob, error)
+		// SearchByStatus returns the ids of the jobs in the given status
+		SearchByStatus(hvs.HostImportJobStatus) ([]uuid.UUID, error)
+		UpdateStatus(uuid.UUID, hvs.HostImportJobStatus) error
+		UpdateRow(uuid.UUID, *hvs.HostImportRow) error
+	}
+
+	// HostCreator registers a host the same way the hosts API does
+	HostCreator interface {
+		CreateHost(hvs.HostCreateRequest) (interface{}, int, error)
+	}
+
+	HostImporter interface {
+		// stores the job and registers its hosts in the background
+		Submit(*hvs.HostImportJob) (*hvs.HostImportJob, error)
+		// registers again the hosts of the job that failed
+		Retry(uuid.UUID) (*hvs.HostImportJob, error)
+		// resumes the jobs interrupted by a restart of the service
+		Resume() error
 	}
 )
@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package mocks
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	"github.com/pkg/errors"
+)
+
+// MockAuditLogEntryStore provides a mocked implementation of interface domain.AuditLogEntryStore
+type MockAuditLogEntryStore struct {
+	Entries map[uuid.UUID]*models.AuditLogEntry
+}
+
+// Create inserts an AuditLogEntry
+func (store *MockAuditLogEntryStore) Create(entry *models.AuditLogEntry) (*models.AuditLogEntry, error) {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	store.Entries[entry.ID] = entry
+	return entry, nil
+}
+
+// Retrieve returns the AuditLogEntry matching the non empty fields of the entry
+func (store *MockAuditLogEntryStore) Retrieve(entry *models.AuditLogEntry) ([]models.AuditLogEntry, error) {
+	var ret []models.AuditLogEntry
+	for _, e := range store.Entries {
+		if (entry.ID == uuid.Nil || e.ID == entry.ID) &&
+			(entry.EntityID == uuid.Nil || e.EntityID == entry.EntityID) &&
+			(entry.EntityType == "" || e.EntityType == entry.EntityType) &&
+			(entry.Action == "" || e.Action == entry.Action) {
+			ret = append(ret, *e)
+		}
+	}
+	return ret, nil
+}
+
+// Update modifies an AuditLogEntry
+func (store *MockAuditLogEntryStore) Update(entry *models.AuditLogEntry) (*models.AuditLogEntry, error) {
+	if _, ok := store.Entries[entry.ID]; !ok {
+		return nil, errors.New(commErr.RowsNotFound)
+	}
+	store.Entries[entry.ID] = entry
+	return entry, nil
+}
+
+// Delete deletes an AuditLogEntry
+func (store *MockAuditLogEntryStore) Delete(id uuid.UUID) error {
+	if _, ok := store.Entries[id]; !ok {
+		return errors.New(commErr.RowsNotFound)
+	}
+	delete(store.Entries, id)
+	return nil
+}
+
+// Search returns the AuditLogEntry matching the filter criteria, ordered by sequence when walking the chain
+// and by creation time otherwise
+func (store *MockAuditLogEntryStore) Search(criteria *models.AuditLogFilterCriteria) ([]models.AuditLogEntry, error) {
+	var ret []models.AuditLogEntry
+	for _, e := range store.Entries {
+		if (criteria.EntityType != "" && e.EntityType != criteria.EntityType) ||
+			(criteria.EntityID != uuid.Nil && e.EntityID != criteria.EntityID) ||
+			(criteria.Action != "" && e.Action != criteria.Action) ||
+			(!criteria.FromDate.IsZero() && e.CreatedAt.Before(criteria.FromDate)) ||
+			(!criteria.ToDate.IsZero() && e.CreatedAt.After(criteria.ToDate)) {
+			continue
+		}
+		if criteria.ChainAfter != nil && !chainPositionAfter(e, criteria.ChainAfter) {
+			continue
+		}
+		ret = append(ret, *e)
+	}
+
+	if criteria.ChainAfter != nil {
+		sort.Slice(ret, func(i, j int) bool {
+			return chainPositionAfter(&ret[j], &models.AuditLogChainCursor{Sequence: ret[i].Sequence, ID: ret[i].ID})
+		})
+	} else {
+		sort.Slice(ret, func(i, j int) bool { return ret[i].CreatedAt.Before(ret[j].CreatedAt) })
+	}
+
+	limit := criteria.Limit
+	if criteria.ChainAfter == nil && criteria.Page != nil {
+		limit = criteria.Page.Limit
+	}
+	if limit > 0 && len(ret) > limit {
+		ret = ret[:limit]
+	}
+	return ret, nil
+}
+
+// RetrieveLatest returns the AuditLogEntry with the highest sequence
+func (store *MockAuditLogEntryStore) RetrieveLatest() (*models.AuditLogEntry, error) {
+	var latest *models.AuditLogEntry
+	for _, e := range store.Entries {
+		if e.Sequence > 0 && (latest == nil || e.Sequence > latest.Sequence) {
+			latest = e
+		}
+	}
+	return latest, nil
+}
+
+func chainPositionAfter(e *models.AuditLogEntry, cursor *models.AuditLogChainCursor) bool {
+	if e.Sequence != cursor.Sequence {
+		return e.Sequence > cursor.Sequence
+	}
+	return e.ID.String() > cursor.ID.String()
+}
+
+// NewMockAuditLogEntryStore provides an empty mocked audit log
+func NewMockAuditLogEntryStore() *MockAuditLogEntryStore {
+	return &MockAuditLogEntryStore{Entries: map[uuid.UUID]*models.AuditLogEntry{}}
+}
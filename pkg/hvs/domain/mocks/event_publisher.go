@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package mocks
+
+import (
+	"sync"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+)
+
+// MockEventPublisher provides a mocked implementation of interface domain.EventPublisher. The published events are
+// recorded and replayed to the listeners, whose channels are closed once the publisher is stopped.
+type MockEventPublisher struct {
+	lock      sync.Mutex
+	stopped   bool
+	Published []*hvs.TrustEvent
+}
+
+func NewMockEventPublisher() *MockEventPublisher {
+	return &MockEventPublisher{}
+}
+
+func (p *MockEventPublisher) Publish(e *hvs.TrustEvent) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.Published = append(p.Published, e)
+}
+
+func (p *MockEventPublisher) Listen(eventTypes []string) (<-chan *hvs.TrustEvent, func()) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	l := make(chan *hvs.TrustEvent, len(p.Published))
+	for _, e := range p.Published {
+		if len(eventTypes) == 0 || eventTypes[0] == e.Type {
+			l <- e
+		}
+	}
+	if p.stopped {
+		close(l)
+	}
+	return l, func() {}
+}
+
+func (p *MockEventPublisher) Stop() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.stopped = true
+}
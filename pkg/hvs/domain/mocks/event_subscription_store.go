@@ -0,0 +1,114 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package mocks
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+// MockEventSubscriptionStore provides a mocked implementation of interface domain.EventSubscriptionStore
+type MockEventSubscriptionStore struct {
+	subscriptions map[uuid.UUID]hvs.EventSubscription
+}
+
+// Create and inserts a EventSubscription
+func (store *MockEventSubscriptionStore) Create(es *hvs.EventSubscription) (*hvs.EventSubscription, error) {
+	if es.ID == uuid.Nil {
+		es.ID = uuid.New()
+	}
+	if es.Created.IsZero() {
+		es.Created = time.Now()
+	}
+	store.subscriptions[es.ID] = *es
+	return es, nil
+}
+
+// Retrieve returns EventSubscription
+func (store *MockEventSubscriptionStore) Retrieve(id uuid.UUID) (*hvs.EventSubscription, error) {
+	es, ok := store.subscriptions[id]
+	if !ok {
+		return nil, errors.New(commErr.RowsNotFound)
+	}
+	return &es, nil
+}
+
+// Update modifies a EventSubscription, keeping the secret when none is provided
+func (store *MockEventSubscriptionStore) Update(es *hvs.EventSubscription) (*hvs.EventSubscription, error) {
+	existing, ok := store.subscriptions[es.ID]
+	if !ok {
+		return nil, errors.New(commErr.RowsNotFound)
+	}
+	existing.URL = es.URL
+	existing.EventTypes = es.EventTypes
+	if es.Secret != "" {
+		existing.Secret = es.Secret
+	}
+	store.subscriptions[es.ID] = existing
+	return &existing, nil
+}
+
+// Delete deletes EventSubscription
+func (store *MockEventSubscriptionStore) Delete(id uuid.UUID) error {
+	if _, ok := store.subscriptions[id]; !ok {
+		return errors.New(commErr.RowsNotFound)
+	}
+	delete(store.subscriptions, id)
+	return nil
+}
+
+// Search returns a filtered list of EventSubscriptions per the provided EventSubscriptionFilterCriteria
+func (store *MockEventSubscriptionStore) Search(criteria *models.EventSubscriptionFilterCriteria) ([]hvs.EventSubscription, error) {
+	var result []hvs.EventSubscription
+	for _, es := range store.subscriptions {
+		if criteria != nil && criteria.Id != uuid.Nil && es.ID != criteria.Id {
+			continue
+		}
+		if criteria != nil && criteria.EventType != "" && len(es.EventTypes) > 0 {
+			found := false
+			for _, t := range es.EventTypes {
+				if t == criteria.EventType {
+					found = true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+		result = append(result, es)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Created.Before(result[j].Created)
+	})
+	return result, nil
+}
+
+// NewMockEventSubscriptionStore provides two dummy subscriptions, one for all the events and one for the trust changes only
+func NewMockEventSubscriptionStore() *MockEventSubscriptionStore {
+	store := &MockEventSubscriptionStore{subscriptions: make(map[uuid.UUID]hvs.EventSubscription)}
+	created := time.Now()
+	_, _ = store.Create(&hvs.EventSubscription{
+		ID:      uuid.MustParse("0a2a7b2c-4b6f-4d5e-9a1c-2f1e3d4c5b6a"),
+		URL:     "https://siem.example.com/hvs/events",
+		Secret:  "all-events-secret",
+		Created: created,
+	})
+	_, _ = store.Create(&hvs.EventSubscription{
+		ID:         uuid.MustParse("1b3b8c3d-5c7a-4e6f-8b2d-3a2f4e5d6c7b"),
+		URL:        "https://orchestrator.example.com/hvs/trust",
+		Secret:     "trust-events-secret",
+		EventTypes: []string{hvs.TrustEventHostTrustChanged},
+		Created:    created.Add(time.Second),
+	})
+	return store
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"reflect"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
@@ -23,6 +24,8 @@ type MockFlavorStore struct {
 	flavorStore            []hvs.SignedFlavor
 	FlavorFlavorGroupStore map[uuid.UUID][]uuid.UUID
 	FlavorgroupStore       map[uuid.UUID]*hvs.FlavorGroup
+	// FlavorLifecycles holds the lifecycle of the flavors that are not active
+	FlavorLifecycles map[uuid.UUID]hvs.FlavorLifecycle
 }
 
 var flavor = ` {
@@ -605,9 +608,59 @@ func (store *MockFlavorStore) Search(criteria *models.FlavorVerificationFC) ([]h
 			}
 		}
 	}
+	if criteria.Lifecycle != nil {
+		sfFiltered = nil
+		for _, sf := range sfs {
+			state := store.lifecycle(sf.Flavor.Meta.ID).EffectiveState(criteria.Lifecycle.At)
+			inEffect := state == hvs.FlavorStateActive || state == hvs.FlavorStateDeprecated
+			if (criteria.Lifecycle.Shadow && state == hvs.FlavorStateStaged) || (!criteria.Lifecycle.Shadow && inEffect) {
+				sfFiltered = append(sfFiltered, sf)
+			}
+		}
+		sfs = sfFiltered
+	}
 	return sfs, nil
 }
 
+func (store *MockFlavorStore) lifecycle(id uuid.UUID) hvs.FlavorLifecycle {
+	if lifecycle, ok := store.FlavorLifecycles[id]; ok {
+		return lifecycle
+	}
+	return hvs.FlavorLifecycle{FlavorId: id, State: hvs.FlavorStateActive}
+}
+
+// RetrieveLifecycle returns the lifecycle of a Flavor
+func (store *MockFlavorStore) RetrieveLifecycle(id uuid.UUID) (*hvs.FlavorLifecycle, error) {
+	if _, err := store.Retrieve(id); err != nil {
+		return nil, err
+	}
+	lifecycle := store.lifecycle(id)
+	return &lifecycle, nil
+}
+
+// SearchLifecycles returns the lifecycle of the given Flavors
+func (store *MockFlavorStore) SearchLifecycles(ids []uuid.UUID) ([]hvs.FlavorLifecycle, error) {
+	var lifecycles []hvs.FlavorLifecycle
+	for _, id := range ids {
+		if lifecycle, err := store.RetrieveLifecycle(id); err == nil {
+			lifecycles = append(lifecycles, *lifecycle)
+		}
+	}
+	return lifecycles, nil
+}
+
+// UpdateLifecycle updates the lifecycle of a Flavor
+func (store *MockFlavorStore) UpdateLifecycle(lifecycle *hvs.FlavorLifecycle) (*hvs.FlavorLifecycle, error) {
+	if _, err := store.Retrieve(lifecycle.FlavorId); err != nil {
+		return nil, err
+	}
+	if store.FlavorLifecycles == nil {
+		store.FlavorLifecycles = make(map[uuid.UUID]hvs.FlavorLifecycle)
+	}
+	store.FlavorLifecycles[lifecycle.FlavorId] = *lifecycle
+	return lifecycle, nil
+}
+
 // Create inserts a Flavor
 func (store *MockFlavorStore) Create(sf *hvs.SignedFlavor) (*hvs.SignedFlavor, error) {
 	//It is not right way to directly append the pointer, reference will be copied. Copy only the values.
@@ -619,6 +672,40 @@ func (store *MockFlavorStore) Create(sf *hvs.SignedFlavor) (*hvs.SignedFlavor, e
 	return sf, nil
 }
 
+// CreateWithLifecycle inserts a Flavor in the given lifecycle state
+func (store *MockFlavorStore) CreateWithLifecycle(sf *hvs.SignedFlavor, lifecycle *hvs.FlavorLifecycle) (*hvs.SignedFlavor, error) {
+	if sf.Flavor.Meta.ID == uuid.Nil {
+		sf.Flavor.Meta.ID = uuid.New()
+	}
+	if _, err := store.Create(sf); err != nil {
+		return nil, err
+	}
+	if lifecycle != nil {
+		if store.FlavorLifecycles == nil {
+			store.FlavorLifecycles = make(map[uuid.UUID]hvs.FlavorLifecycle)
+		}
+		created := *lifecycle
+		created.FlavorId = sf.Flavor.Meta.ID
+		store.FlavorLifecycles[sf.Flavor.Meta.ID] = created
+	}
+	return sf, nil
+}
+
+// SearchLifecycleTransitions returns the lifecycle of the Flavors which are not revoked and whose not_before or
+// not_after date is in the given window
+func (store *MockFlavorStore) SearchLifecycleTransitions(from, to time.Time) ([]hvs.FlavorLifecycle, error) {
+	inWindow := func(date *time.Time) bool {
+		return date != nil && date.After(from) && !date.After(to)
+	}
+	var lifecycles []hvs.FlavorLifecycle
+	for _, lifecycle := range store.FlavorLifecycles {
+		if lifecycle.State != hvs.FlavorStateRevoked && (inWindow(lifecycle.NotBefore) || inWindow(lifecycle.NotAfter)) {
+			lifecycles = append(lifecycles, lifecycle)
+		}
+	}
+	return lifecycles, nil
+}
+
 // NewMockFlavorStore provides one dummy data for Flavors
 func NewMockFlavorStore() *MockFlavorStore {
 	store := &MockFlavorStore{}
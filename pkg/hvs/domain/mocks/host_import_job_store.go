@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package mocks
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+// MockHostImportJobStore provides a mocked implementation of interface domain.HostImportJobStore
+type MockHostImportJobStore struct {
+	lock sync.Mutex
+	jobs map[uuid.UUID]hvs.HostImportJob
+}
+
+// Create and inserts a HostImportJob
+func (store *MockHostImportJobStore) Create(job *hvs.HostImportJob) (*hvs.HostImportJob, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	job.ID = uuid.New()
+	job.Created = time.Now()
+	job.Updated = job.Created
+	job.CountRows()
+	store.jobs[job.ID] = copyHostImportJob(job)
+	return job, nil
+}
+
+// Retrieve returns HostImportJob
+func (store *MockHostImportJobStore) Retrieve(id uuid.UUID) (*hvs.HostImportJob, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	job, ok := store.jobs[id]
+	if !ok {
+		return nil, errors.New(commErr.RowsNotFound)
+	}
+	job = copyHostImportJob(&job)
+	job.CountRows()
+	return &job, nil
+}
+
+// SearchByStatus returns the ids of the HostImportJobs in the given status
+func (store *MockHostImportJobStore) SearchByStatus(status hvs.HostImportJobStatus) ([]uuid.UUID, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	var jobs []hvs.HostImportJob
+	for _, job := range store.jobs {
+		if job.Status == status {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Created.Before(jobs[j].Created)
+	})
+	var ids []uuid.UUID
+	for _, job := range jobs {
+		ids = append(ids, job.ID)
+	}
+	return ids, nil
+}
+
+// UpdateStatus modifies the status of a HostImportJob
+func (store *MockHostImportJobStore) UpdateStatus(id uuid.UUID, status hvs.HostImportJobStatus) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	job, ok := store.jobs[id]
+	if !ok {
+		return errors.New(commErr.RowsNotFound)
+	}
+	job.Status = status
+	job.Updated = time.Now()
+	store.jobs[id] = job
+	return nil
+}
+
+// UpdateRow modifies the result of a row of a HostImportJob
+func (store *MockHostImportJobStore) UpdateRow(id uuid.UUID, row *hvs.HostImportRow) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	job, ok := store.jobs[id]
+	if !ok {
+		return errors.New(commErr.RowsNotFound)
+	}
+	for i := range job.Rows {
+		if job.Rows[i].Row == row.Row {
+			job.Rows[i].Status = row.Status
+			job.Rows[i].HostId = row.HostId
+			job.Rows[i].Error = row.Error
+			job.Rows[i].Attempts = row.Attempts
+			job.Updated = time.Now()
+			store.jobs[id] = job
+			return nil
+		}
+	}
+	return errors.New(commErr.RowsNotFound)
+}
+
+func copyHostImportJob(job *hvs.HostImportJob) hvs.HostImportJob {
+	jobCopy := *job
+	jobCopy.Rows = append([]hvs.HostImportRow(nil), job.Rows...)
+	return jobCopy
+}
+
+// NewMockHostImportJobStore provides an empty HostImportJobStore
+func NewMockHostImportJobStore() *MockHostImportJobStore {
+	return &MockHostImportJobStore{jobs: make(map[uuid.UUID]hvs.HostImportJob)}
+}
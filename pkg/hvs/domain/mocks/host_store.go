@@ -11,6 +11,7 @@ import (
 	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
 	"github.com/pkg/errors"
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -70,6 +71,15 @@ func (store *MockHostStore) DeleteByHostName(hostName string) error {
 
 // Search returns a collection of Hosts filtered as per HostFilterCriteria
 func (store *MockHostStore) Search(criteria *models.HostFilterCriteria, hostInfoFetchCriteria *models.HostInfoFetchCriteria) ([]*hvs.Host, error) {
+	if criteria != nil && criteria.Page != nil {
+		unpaged := *criteria
+		unpaged.Page = nil
+		hosts, err := store.Search(&unpaged, hostInfoFetchCriteria)
+		if err != nil {
+			return nil, err
+		}
+		return pageHosts(hosts, criteria.Page), nil
+	}
 	if criteria == nil || reflect.DeepEqual(*criteria, models.HostFilterCriteria{}) {
 		return store.hostStore, nil
 	}
@@ -98,10 +108,47 @@ func (store *MockHostStore) Search(criteria *models.HostFilterCriteria, hostInfo
 				hosts = append(hosts, h)
 			}
 		}
+	} else if len(criteria.LabelSelector) > 0 {
+		hosts = store.hostStore
+	}
+
+	if len(criteria.LabelSelector) > 0 {
+		var selected []*hvs.Host
+		for _, h := range hosts {
+			if h.Id != uuid.Nil && criteria.LabelSelector.Matches(h.Labels) {
+				selected = append(selected, h)
+			}
+		}
+		hosts = selected
 	}
 	return hosts, nil
 }
 
+// pageHosts orders the hosts by name and returns those of the requested page
+func pageHosts(hosts []*hvs.Host, page *models.PageCriteria) []*hvs.Host {
+	sorted := make([]*hvs.Host, len(hosts))
+	copy(sorted, hosts)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].HostName != sorted[j].HostName {
+			return sorted[i].HostName < sorted[j].HostName
+		}
+		return sorted[i].Id.String() < sorted[j].Id.String()
+	})
+
+	var paged []*hvs.Host
+	for _, h := range sorted {
+		if page.After != nil && (h.HostName < page.After.Value ||
+			(h.HostName == page.After.Value && h.Id.String() <= page.After.ID.String())) {
+			continue
+		}
+		if len(paged) == page.Limit {
+			break
+		}
+		paged = append(paged, h)
+	}
+	return paged
+}
+
 // AddFlavorgroups associate a Host with specified flavorgroups
 func (store *MockHostStore) AddFlavorgroups(hId uuid.UUID, fgIds []uuid.UUID) error {
 	for _, fgId := range fgIds {
@@ -183,6 +230,39 @@ func (store *MockHostStore) RetrieveDistinctUniqueFlavorParts(uuid.UUID) ([]stri
 	return nil, nil
 }
 
+// SummarizeTrustByLabel counts the hosts by value of the label and trust status, the hosts without trust status
+// being unknown
+func (store *MockHostStore) SummarizeTrustByLabel(labelKey string, selector hvs.LabelSelector) ([]hvs.HostLabelTrustCount, error) {
+	counts := make(map[string]*hvs.HostLabelTrustCount)
+	var values []string
+	for _, h := range store.hostStore {
+		value, ok := h.Labels[labelKey]
+		if h.Id == uuid.Nil || !ok || !selector.Matches(h.Labels) {
+			continue
+		}
+		if _, ok := counts[value]; !ok {
+			counts[value] = &hvs.HostLabelTrustCount{Value: value}
+			values = append(values, value)
+		}
+		count := counts[value]
+		count.Total++
+		if h.Trusted == nil {
+			count.Unknown++
+		} else if *h.Trusted {
+			count.Trusted++
+		} else {
+			count.Untrusted++
+		}
+	}
+
+	sort.Strings(values)
+	summary := make([]hvs.HostLabelTrustCount, 0, len(values))
+	for _, value := range values {
+		summary = append(summary, *counts[value])
+	}
+	return summary, nil
+}
+
 // NewMockHostStore provides two dummy data for Hosts
 func NewMockHostStore() *MockHostStore {
 	store := &MockHostStore{}
@@ -155,6 +155,14 @@ func (store *MockHostStatusStore) Search(criteria *models.HostStatusFilterCriter
 			AddRow(hs3.ID.String(), hs3.HostID.String(), hsi3, hsm3, hs3.Created).
 			AddRow(hs4.ID.String(), hs4.HostID.String(), hsi4, hsm4, hs4.Created))
 
+	// Search No filters, one page at a time
+	store.Mock.ExpectQuery(`SELECT \* FROM "host_status" ORDER BY host_status.created (.+), host_status.id (.+) LIMIT (.+)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "host_id", "status", "host_report", "created"}).
+			AddRow(hs1.ID.String(), hs1.HostID.String(), hsi1, hsm1, hs1.Created).
+			AddRow(hs2.ID.String(), hs2.HostID.String(), hsi2, hsm2, hs2.Created).
+			AddRow(hs3.ID.String(), hs3.HostID.String(), hsi3, hsm3, hs3.Created).
+			AddRow(hs4.ID.String(), hs4.HostID.String(), hsi4, hsm4, hs4.Created))
+
 	// Search by ID
 	store.Mock.ExpectQuery(`SELECT \* FROM "host_status" WHERE \(id = (.+) LIMIT (.+)`).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "host_id", "status", "host_report", "created"}).
@@ -265,6 +273,26 @@ func (store *MockHostStatusStore) FindHostIdsByKeyValue(key, value string) ([]uu
 	return store.HostStatusStore.FindHostIdsByKeyValue(key, value)
 }
 
+// CountByHostState returns the count of hosts in each connection state
+func (store *MockHostStatusStore) CountByHostState(selector hvs.LabelSelector) ([]hvs.HostStateCount, error) {
+	store.Mock.ExpectQuery(`^SELECT COALESCE\(hs.status ->> 'host_state', \$1\) AS host_state, COUNT\(\*\) AS hosts FROM host_status hs`).
+		WillReturnRows(sqlmock.NewRows([]string{"host_state", "hosts"}).
+			AddRow("CONNECTED", 1).
+			AddRow("CONNECTION_FAILURE", 1))
+
+	return store.HostStatusStore.CountByHostState(selector)
+}
+
+// CountConnectionFailures returns the count of hosts that failed to connect by time bucket
+func (store *MockHostStatusStore) CountConnectionFailures(criteria *models.ReportSummaryCriteria) ([]hvs.TrustTimeBucket, error) {
+	store.Mock.ExpectQuery(`^SELECT date_trunc\(\$1, au.created\) AS bucket, COUNT\(DISTINCT (.+)\) AS hosts FROM audit_log_entry au`).
+		WillReturnRows(sqlmock.NewRows([]string{"bucket", "hosts"}).
+			AddRow(time.Date(2020, 6, 21, 0, 0, 0, 0, time.UTC), 1).
+			AddRow(time.Date(2020, 6, 23, 0, 0, 0, 0, time.UTC), 2))
+
+	return store.HostStatusStore.CountConnectionFailures(criteria)
+}
+
 // NewMockHostStatusStore initializes the mock datastore and prepares the MockHostStatusStore
 func NewMockHostStatusStore() *MockHostStatusStore {
 	datastore, mock := postgres.NewSQLMockDataStore()
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"reflect"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -136,6 +137,82 @@ func (store *MockReportStore) FindHostIdsFromExpiredReports(fromTime time.Time,
 	return hostIDs, nil
 }
 
+// Summarize counts the reports by trust status, each report standing for a host
+func (store *MockReportStore) Summarize(criteria *models.ReportSummaryCriteria) (*hvs.ReportSummary, error) {
+	summary := hvs.ReportSummary{}
+	parts := make(map[string]*hvs.FlavorPartTrustCount)
+	rules := make(map[string]int)
+	buckets := make(map[time.Time]*hvs.TrustTimeBucket)
+	for _, r := range store.reportStore {
+		summary.Total++
+		if r.TrustReport.IsTrusted() {
+			summary.Trusted++
+		} else {
+			summary.Untrusted++
+		}
+
+		markers := make(map[string]bool)
+		failingRules := make(map[string]bool)
+		for _, result := range r.TrustReport.Results {
+			for _, marker := range result.Rule.Markers {
+				markers[marker.String()] = true
+			}
+			if !result.IsTrusted() {
+				failingRules[result.Rule.Name] = true
+			}
+		}
+		for marker := range markers {
+			if _, ok := parts[marker]; !ok {
+				parts[marker] = &hvs.FlavorPartTrustCount{FlavorPart: marker}
+			}
+			if r.TrustReport.IsTrustedForMarker(marker) {
+				parts[marker].Trusted++
+			} else {
+				parts[marker].Untrusted++
+			}
+		}
+		for rule := range failingRules {
+			rules[rule]++
+		}
+
+		if !criteria.FromDate.IsZero() && !r.CreatedAt.Before(criteria.FromDate) && r.CreatedAt.Before(criteria.ToDate) {
+			start := r.CreatedAt.Truncate(24 * time.Hour)
+			if _, ok := buckets[start]; !ok {
+				buckets[start] = &hvs.TrustTimeBucket{Start: start}
+			}
+			if r.TrustReport.IsTrusted() {
+				buckets[start].Trusted++
+			} else {
+				buckets[start].Untrusted++
+			}
+		}
+	}
+
+	if criteria.ByFlavorPart {
+		for _, part := range parts {
+			summary.FlavorParts = append(summary.FlavorParts, *part)
+		}
+		sort.Slice(summary.FlavorParts, func(i, j int) bool {
+			return summary.FlavorParts[i].FlavorPart < summary.FlavorParts[j].FlavorPart
+		})
+	}
+	if criteria.ByRule {
+		for rule, hosts := range rules {
+			summary.FailingRules = append(summary.FailingRules, hvs.RuleFailureCount{RuleName: rule, Hosts: hosts})
+		}
+		sort.Slice(summary.FailingRules, func(i, j int) bool {
+			return summary.FailingRules[i].RuleName < summary.FailingRules[j].RuleName
+		})
+	}
+	for _, bucket := range buckets {
+		summary.TimeBuckets = append(summary.TimeBuckets, *bucket)
+	}
+	sort.Slice(summary.TimeBuckets, func(i, j int) bool {
+		return summary.TimeBuckets[i].Start.Before(summary.TimeBuckets[j].Start)
+	})
+	return &summary, nil
+}
+
 // NewMockReportStore provides two dummy data for Reports
 func NewMockReportStore() *MockReportStore {
 	//TODO add more data
@@ -0,0 +1,32 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLogFilterCriteria holds the filter criteria for the audit log entries used by the Search Audit Logs API
+type AuditLogFilterCriteria struct {
+	EntityType string
+	EntityID   uuid.UUID
+	Action     string
+	FromDate   time.Time
+	ToDate     time.Time
+	Limit      int
+	Page       *PageCriteria
+	// ChainAfter restricts the search to the entries positioned after the cursor, ordered by sequence.
+	// It is used to walk the hash chain and takes precedence over Page.
+	ChainAfter *AuditLogChainCursor
+}
+
+// AuditLogChainCursor is the position of an entry in the hash chain, the ID separates the entries that would
+// share a sequence number
+type AuditLogChainCursor struct {
+	Sequence int64
+	ID       uuid.UUID
+}
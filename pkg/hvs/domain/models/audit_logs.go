@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
 )
 
 type AuditLogEntry struct {
@@ -18,6 +19,10 @@ type AuditLogEntry struct {
 	CreatedAt  time.Time
 	Action     string
 	Data       AuditTableData
+	// Sequence, PrevHash and Hash chain the entry to the previous one, see hvs.AuditLogEntry
+	Sequence int64
+	PrevHash string
+	Hash     string
 }
 
 type AuditTableData struct {
@@ -29,3 +34,27 @@ type AuditColumnData struct {
 	Value     interface{}
 	IsUpdated bool
 }
+
+// ToAuditLog returns the API representation of the entry
+func (entry *AuditLogEntry) ToAuditLog() hvs.AuditLogEntry {
+	columns := make([]hvs.AuditLogColumn, 0, len(entry.Data.Columns))
+	for _, c := range entry.Data.Columns {
+		columns = append(columns, hvs.AuditLogColumn{Name: c.Name, Value: c.Value, IsUpdated: c.IsUpdated})
+	}
+	return hvs.AuditLogEntry{
+		ID:         entry.ID,
+		EntityID:   entry.EntityID,
+		EntityType: entry.EntityType,
+		Created:    entry.CreatedAt.UTC(),
+		Action:     entry.Action,
+		Columns:    columns,
+		Sequence:   entry.Sequence,
+		PrevHash:   entry.PrevHash,
+		Hash:       entry.Hash,
+	}
+}
+
+// ComputeHash returns the hash chaining the entry to PrevHash
+func (entry *AuditLogEntry) ComputeHash() (string, error) {
+	return entry.ToAuditLog().ComputeHash()
+}
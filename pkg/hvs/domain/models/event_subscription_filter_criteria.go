@@ -0,0 +1,13 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package models
+
+import "github.com/google/uuid"
+
+type EventSubscriptionFilterCriteria struct {
+	Id        uuid.UUID
+	EventType string
+}
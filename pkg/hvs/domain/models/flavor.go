@@ -6,6 +6,8 @@ package models
 
 import (
 	"encoding/json"
+	"time"
+
 	"github.com/google/uuid"
 	cf "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/common"
 	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
@@ -16,11 +18,12 @@ type flavors []hvs.Flavor
 type signedFlavors []hvs.SignedFlavor
 
 type FlavorCreateRequest struct {
-	ConnectionString       string                     `json:"connection_string,omitempty"`
-	FlavorCollection       hvs.FlavorCollection       `json:"flavor_collection,omitempty"`
-	SignedFlavorCollection hvs.SignedFlavorCollection `json:"signed_flavor_collection,omitempty"`
-	FlavorgroupNames       []string                   `json:"flavorgroup_names,omitempty"`
-	FlavorParts            []cf.FlavorPart            `json:"partial_flavor_types,omitempty"`
+	ConnectionString       string                      `json:"connection_string,omitempty"`
+	FlavorCollection       hvs.FlavorCollection        `json:"flavor_collection,omitempty"`
+	SignedFlavorCollection hvs.SignedFlavorCollection  `json:"signed_flavor_collection,omitempty"`
+	FlavorgroupNames       []string                    `json:"flavorgroup_names,omitempty"`
+	FlavorParts            []cf.FlavorPart             `json:"partial_flavor_types,omitempty"`
+	Lifecycle              *hvs.FlavorLifecycleRequest `json:"lifecycle,omitempty"`
 }
 
 type FlavorFilterCriteria struct {
@@ -29,12 +32,22 @@ type FlavorFilterCriteria struct {
 	Value         string
 	FlavorgroupID uuid.UUID
 	FlavorParts   []cf.FlavorPart
+	Page          *PageCriteria
 }
 
 type FlavorVerificationFC struct {
 	FlavorFC              FlavorFilterCriteria
 	FlavorMeta            map[cf.FlavorPart][]FlavorMetaKv
 	FlavorPartsWithLatest map[cf.FlavorPart]bool
+	// Lifecycle restricts the search to the flavors verified at a given time, all the flavors are searched when nil
+	Lifecycle *FlavorLifecycleFC
+}
+
+// FlavorLifecycleFC selects the flavors by their effective lifecycle state. The active and deprecated flavors are
+// selected unless Shadow is set, in which case the flavors evaluated in shadow are selected.
+type FlavorLifecycleFC struct {
+	At     time.Time
+	Shadow bool
 }
 
 type FlavorMetaKv struct {
@@ -44,23 +57,25 @@ type FlavorMetaKv struct {
 
 func (fcr FlavorCreateRequest) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
-		ConnectionString       string                     `json:"connection_string,omitempty"`
-		FlavorCollection       hvs.FlavorCollection       `json:"flavor_collection,omitempty"`
-		SignedFlavorCollection hvs.SignedFlavorCollection `json:"signed_flavor_collection,omitempty"`
-		FlavorgroupNames       []string                   `json:"flavorgroup_names,omitempty"`
-		FlavorParts            []cf.FlavorPart            `json:"partial_flavor_types,omitempty"`
+		ConnectionString       string                      `json:"connection_string,omitempty"`
+		FlavorCollection       hvs.FlavorCollection        `json:"flavor_collection,omitempty"`
+		SignedFlavorCollection hvs.SignedFlavorCollection  `json:"signed_flavor_collection,omitempty"`
+		FlavorgroupNames       []string                    `json:"flavorgroup_names,omitempty"`
+		FlavorParts            []cf.FlavorPart             `json:"partial_flavor_types,omitempty"`
+		Lifecycle              *hvs.FlavorLifecycleRequest `json:"lifecycle,omitempty"`
 	}{
 		ConnectionString:       fcr.ConnectionString,
 		FlavorCollection:       fcr.FlavorCollection,
 		SignedFlavorCollection: fcr.SignedFlavorCollection,
 		FlavorgroupNames:       fcr.FlavorgroupNames,
 		FlavorParts:            fcr.FlavorParts,
+		Lifecycle:              fcr.Lifecycle,
 	})
 }
 
 func (fcr *FlavorCreateRequest) UnmarshalJSON(b []byte) error {
 	//Validate the FlavorCreateRequest keys as here it is overridden with custom UnmarshalJSON decoder.DisallowUnknownFields doesnt work
-	validKeys := map[string]bool{"connection_string": true, "flavor_collection": true, "signed_flavor_collection": true, "flavorgroup_names": true, "partial_flavor_types": true}
+	validKeys := map[string]bool{"connection_string": true, "flavor_collection": true, "signed_flavor_collection": true, "flavorgroup_names": true, "partial_flavor_types": true, "lifecycle": true}
 	fcrKeysMap := map[string]interface{}{}
 	if err := json.Unmarshal(b, &fcrKeysMap); err != nil {
 		return err
@@ -72,11 +87,12 @@ func (fcr *FlavorCreateRequest) UnmarshalJSON(b []byte) error {
 	}
 
 	decoded := new(struct {
-		ConnectionString       string                     `json:"connection_string,omitempty"`
-		FlavorCollection       hvs.FlavorCollection       `json:"flavor_collection,omitempty"`
-		SignedFlavorCollection hvs.SignedFlavorCollection `json:"signed_flavor_collection,omitempty"`
-		FlavorgroupNames       []string                   `json:"flavorgroup_names,omitempty"`
-		FlavorParts            []cf.FlavorPart            `json:"partial_flavor_types,omitempty"`
+		ConnectionString       string                      `json:"connection_string,omitempty"`
+		FlavorCollection       hvs.FlavorCollection        `json:"flavor_collection,omitempty"`
+		SignedFlavorCollection hvs.SignedFlavorCollection  `json:"signed_flavor_collection,omitempty"`
+		FlavorgroupNames       []string                    `json:"flavorgroup_names,omitempty"`
+		FlavorParts            []cf.FlavorPart             `json:"partial_flavor_types,omitempty"`
+		Lifecycle              *hvs.FlavorLifecycleRequest `json:"lifecycle,omitempty"`
 	})
 	err := json.Unmarshal(b, &decoded)
 	if err == nil {
@@ -85,6 +101,7 @@ func (fcr *FlavorCreateRequest) UnmarshalJSON(b []byte) error {
 		fcr.FlavorCollection = decoded.FlavorCollection
 		fcr.SignedFlavorCollection = decoded.SignedFlavorCollection
 		fcr.FlavorParts = decoded.FlavorParts
+		fcr.Lifecycle = decoded.Lifecycle
 	}
 	return err
 }
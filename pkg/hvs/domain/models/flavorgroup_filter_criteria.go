@@ -11,4 +11,5 @@ type FlavorGroupFilterCriteria struct {
 	FlavorId     *uuid.UUID
 	NameEqualTo  string
 	NameContains string
+	Page         *PageCriteria
 }
@@ -7,6 +7,7 @@ package models
 import (
 	"errors"
 	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
 )
 
 type HostFilterCriteria struct {
@@ -18,7 +19,9 @@ type HostFilterCriteria struct {
 	Value          string
 	IdList         []uuid.UUID
 	Trusted        *bool
+	LabelSelector  hvs.LabelSelector
 	OrderBy        OrderType
+	Page           *PageCriteria
 }
 
 type OrderType string
@@ -6,6 +6,7 @@ package models
 
 import (
 	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
 	"time"
 )
 
@@ -19,6 +20,8 @@ type HostStatusFilterCriteria struct {
 	FromDate       time.Time
 	ToDate         time.Time
 	LatestPerHost  bool
+	LabelSelector  hvs.LabelSelector
 	NumberOfDays   int
 	Limit          int
+	Page           *PageCriteria
 }
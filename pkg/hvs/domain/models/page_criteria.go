@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Sort fields accepted by the sortBy query parameter of the paginated Search APIs
+const (
+	SortByID      = "id"
+	SortByName    = "name"
+	SortByLabel   = "label"
+	SortByCreated = "created"
+)
+
+// PageCriteria holds the keyset pagination parameters of a Search request. A nil PageCriteria
+// leaves the search unpaged.
+type PageCriteria struct {
+	// Limit is the maximum number of records returned in the page
+	Limit int
+	// After is the position of the last record of the previous page, nil for the first page
+	After *PageCursor
+	// SortBy is the field the records are ordered on, the record ID is used as a tie-breaker
+	SortBy  string
+	OrderBy OrderType
+	// Last is set by the searches that page over audit log entries, it is the cursor of the last entry of the page.
+	// The records rebuilt from the audit log share their ID, the cursor holds the ID of the entry instead.
+	Last *PageCursor
+}
+
+// PageCursor identifies a record within an ordered result set. It is handed to clients as
+// an opaque token in the "after" query parameter.
+type PageCursor struct {
+	SortBy string    `json:"s"`
+	Value  string    `json:"v"`
+	ID     uuid.UUID `json:"i"`
+}
+
+// Encode returns the opaque token representation of the cursor
+func (pc PageCursor) Encode() string {
+	// marshalling a struct of strings and a UUID does not fail
+	b, _ := json.Marshal(pc)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodePageCursor parses a token previously produced by PageCursor.Encode
+func DecodePageCursor(token string) (*PageCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid page cursor encoding")
+	}
+
+	var pc PageCursor
+	if err := json.Unmarshal(b, &pc); err != nil {
+		return nil, errors.Wrap(err, "Invalid page cursor content")
+	}
+	if pc.SortBy == "" || pc.ID == uuid.Nil {
+		return nil, errors.New("Page cursor is missing the sort field or record ID")
+	}
+	return &pc, nil
+}
+
+// PageCursorTime formats a creation time the way it is carried in the Value of a PageCursor
+func PageCursorTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
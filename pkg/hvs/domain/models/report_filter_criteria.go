@@ -7,6 +7,7 @@ package models
 
 import (
 	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
 	"time"
 )
 
@@ -20,7 +21,9 @@ type ReportFilterCriteria struct {
 	FromDate       time.Time
 	ToDate         time.Time
 	LatestPerHost  bool
+	LabelSelector  hvs.LabelSelector
 	Limit          int
+	Page           *PageCriteria
 }
 
 type ReportLocator struct {
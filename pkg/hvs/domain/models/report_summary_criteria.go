@@ -0,0 +1,31 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package models
+
+import (
+	"time"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+)
+
+// Time bucket sizes of a report summary, as accepted by the postgres date_trunc function
+const (
+	BucketSizeHour = "hour"
+	BucketSizeDay  = "day"
+	BucketSizeWeek = "week"
+)
+
+// ReportSummaryCriteria selects the hosts counted in a report summary and the breakdowns to compute. The counts are
+// broken down by time bucket when FromDate is set.
+type ReportSummaryCriteria struct {
+	LabelSelector hvs.LabelSelector
+	ByFlavorPart  bool
+	ByRule        bool
+	ByHostState   bool
+	FromDate      time.Time
+	ToDate        time.Time
+	BucketSize    string
+}
@@ -9,11 +9,19 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 )
 
+// auditLogEntryColumns lists the columns of an audit_log_entry aliased as au in the order they are scanned
+// by the report and host status searches
+const auditLogEntryColumns = "au.id, au.entity_id, au.entity_type, au.created, au.action, au.data"
+
+var auditLogPageColumns = pageColumns{models.SortByCreated: "audit_log_entry.created", models.SortByID: "audit_log_entry.id"}
+
 type auditLogEntryStore struct {
 	store *DataStore
 }
@@ -32,18 +40,27 @@ func (as *auditLogEntryStore) Create(entry *models.AuditLogEntry) (*models.Audit
 		entry.Data.Columns == nil {
 		return nil, errors.New("invalid audit log entry for audit_log_entry_store_store:Create()")
 	}
-	newUuid, err := uuid.NewRandom()
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create new UUID")
+	// the ID and creation time of a chained entry are covered by its hash and are set by the writer
+	if entry.ID == uuid.Nil {
+		newUuid, err := uuid.NewRandom()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create new UUID")
+		}
+		entry.ID = newUuid
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
 	}
-	entry.ID = newUuid
 	dbEntry := auditLogEntry{
 		ID:         entry.ID,
 		EntityID:   entry.EntityID,
 		EntityType: entry.EntityType,
-		CreatedAt:  time.Now(),
+		CreatedAt:  entry.CreatedAt,
 		Action:     entry.Action,
 		Data:       PGAuditLogData(entry.Data),
+		Sequence:   entry.Sequence,
+		PrevHash:   entry.PrevHash,
+		Hash:       entry.Hash,
 	}
 	if err := as.store.Db.Create(&dbEntry).Error; err != nil {
 		return nil, errors.Wrap(err, "failed to create audit log entry in db")
@@ -77,15 +94,7 @@ func (as *auditLogEntryStore) Retrieve(entry *models.AuditLogEntry) ([]models.Au
 	}
 	var ret []models.AuditLogEntry
 	for _, e := range matchEntries {
-		eModel := models.AuditLogEntry{
-			ID:         e.ID,
-			EntityID:   e.EntityID,
-			EntityType: e.EntityType,
-			CreatedAt:  e.CreatedAt,
-			Action:     e.Action,
-			Data:       models.AuditTableData(e.Data),
-		}
-		ret = append(ret, eModel)
+		ret = append(ret, auditLogEntryToModel(e))
 	}
 	return ret, nil
 }
@@ -130,15 +139,101 @@ func (as *auditLogEntryStore) FindBetweenTime(from, to time.Time) ([]models.Audi
 	as.store.Db.Model(&auditLogEntry{}).Where("created_at BETWEEN ? AND ?", from, to).Find(&matchEntries)
 	var ret []models.AuditLogEntry
 	for _, e := range matchEntries {
-		eModel := models.AuditLogEntry{
-			ID:         e.ID,
-			EntityID:   e.EntityID,
-			EntityType: e.EntityType,
-			CreatedAt:  e.CreatedAt,
-			Action:     e.Action,
-			Data:       models.AuditTableData(e.Data),
-		}
-		ret = append(ret, eModel)
+		ret = append(ret, auditLogEntryToModel(e))
+	}
+	return ret, nil
+}
+
+// Search returns the audit log entries matching the filter criteria, ordered by creation time unless paged or
+// walking the hash chain
+func (as *auditLogEntryStore) Search(criteria *models.AuditLogFilterCriteria) ([]models.AuditLogEntry, error) {
+	defaultLog.Trace("postgres/audit_log_entry_store_store:Search() Entering")
+	defer defaultLog.Trace("postgres/audit_log_entry_store_store:Search() Leaving")
+
+	if criteria == nil {
+		criteria = &models.AuditLogFilterCriteria{}
+	}
+	tx, err := buildAuditLogSearchQuery(as.store.Db, criteria)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/audit_log_entry_store_store:Search() failed to build search query")
+	}
+
+	var matchEntries []auditLogEntry
+	if err := tx.Find(&matchEntries).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/audit_log_entry_store_store:Search() failed to retrieve records from db")
+	}
+	ret := make([]models.AuditLogEntry, 0, len(matchEntries))
+	for _, e := range matchEntries {
+		ret = append(ret, auditLogEntryToModel(e))
 	}
 	return ret, nil
 }
+
+// RetrieveLatest returns the chained entry with the highest sequence number, nil when no entry is chained yet
+func (as *auditLogEntryStore) RetrieveLatest() (*models.AuditLogEntry, error) {
+	defaultLog.Trace("postgres/audit_log_entry_store_store:RetrieveLatest() Entering")
+	defer defaultLog.Trace("postgres/audit_log_entry_store_store:RetrieveLatest() Leaving")
+
+	var latest auditLogEntry
+	err := as.store.Db.Model(&auditLogEntry{}).Where("sequence > 0").Order("sequence desc, created desc").First(&latest).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/audit_log_entry_store_store:RetrieveLatest() failed to retrieve latest record from db")
+	}
+	entry := auditLogEntryToModel(latest)
+	return &entry, nil
+}
+
+func buildAuditLogSearchQuery(tx *gorm.DB, criteria *models.AuditLogFilterCriteria) (*gorm.DB, error) {
+	defaultLog.Trace("postgres/audit_log_entry_store_store:buildAuditLogSearchQuery() Entering")
+	defer defaultLog.Trace("postgres/audit_log_entry_store_store:buildAuditLogSearchQuery() Leaving")
+
+	tx = tx.Model(&auditLogEntry{})
+	if criteria.EntityType != "" {
+		tx = tx.Where("audit_log_entry.entity_type = ?", criteria.EntityType)
+	}
+	if criteria.EntityID != uuid.Nil {
+		tx = tx.Where("audit_log_entry.entity_id = ?", criteria.EntityID)
+	}
+	if criteria.Action != "" {
+		tx = tx.Where("audit_log_entry.action = ?", criteria.Action)
+	}
+	if !criteria.FromDate.IsZero() {
+		tx = tx.Where("audit_log_entry.created >= ?", criteria.FromDate)
+	}
+	if !criteria.ToDate.IsZero() {
+		tx = tx.Where("audit_log_entry.created <= ?", criteria.ToDate)
+	}
+
+	limit := criteria.Limit
+	if limit <= 0 {
+		limit = constants.DefaultSearchResultRowLimit
+	}
+	switch {
+	case criteria.ChainAfter != nil:
+		tx = tx.Where("(audit_log_entry.sequence, audit_log_entry.id) > (?, ?)",
+			criteria.ChainAfter.Sequence, criteria.ChainAfter.ID).
+			Order("audit_log_entry.sequence asc, audit_log_entry.id asc").Limit(limit)
+	case criteria.Page != nil:
+		return applyPageCriteria(tx, criteria.Page, auditLogPageColumns, "audit_log_entry.id")
+	default:
+		tx = tx.Order("audit_log_entry.created asc, audit_log_entry.id asc").Limit(limit)
+	}
+	return tx, nil
+}
+
+func auditLogEntryToModel(e auditLogEntry) models.AuditLogEntry {
+	return models.AuditLogEntry{
+		ID:         e.ID,
+		EntityID:   e.EntityID,
+		EntityType: e.EntityType,
+		CreatedAt:  e.CreatedAt,
+		Action:     e.Action,
+		Data:       models.AuditTableData(e.Data),
+		Sequence:   e.Sequence,
+		PrevHash:   e.PrevHash,
+		Hash:       e.Hash,
+	}
+}
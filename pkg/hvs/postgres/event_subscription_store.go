@@ -0,0 +1,162 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package postgres
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/utils"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// EventSubscriptionStore persists the webhook subscriptions, the webhook secrets being encrypted with the data encryption key
+type EventSubscriptionStore struct {
+	Store *DataStore
+	Dek   []byte
+}
+
+func NewEventSubscriptionStore(store *DataStore, dek []byte) *EventSubscriptionStore {
+	return &EventSubscriptionStore{
+		Store: store,
+		Dek:   dek,
+	}
+}
+
+func (ess *EventSubscriptionStore) Create(es *hvs.EventSubscription) (*hvs.EventSubscription, error) {
+	defaultLog.Trace("postgres/event_subscription_store:Create() Entering")
+	defer defaultLog.Trace("postgres/event_subscription_store:Create() Leaving")
+
+	encSecret, err := utils.EncryptString(es.Secret, ess.Dek)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/event_subscription_store:Create() failed to encrypt webhook secret")
+	}
+
+	newUuid, err := uuid.NewRandom()
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/event_subscription_store:Create() failed to create new UUID")
+	}
+	es.ID = newUuid
+	es.Created = time.Now()
+	dbEventSubscription := eventSubscription{
+		ID:         es.ID,
+		URL:        es.URL,
+		Secret:     encSecret,
+		EventTypes: PGEventTypes(es.EventTypes),
+		CreatedAt:  es.Created,
+	}
+
+	if err := ess.Store.Db.Create(&dbEventSubscription).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/event_subscription_store:Create() failed to create EventSubscription")
+	}
+	return es, nil
+}
+
+func (ess *EventSubscriptionStore) Retrieve(id uuid.UUID) (*hvs.EventSubscription, error) {
+	defaultLog.Trace("postgres/event_subscription_store:Retrieve() Entering")
+	defer defaultLog.Trace("postgres/event_subscription_store:Retrieve() Leaving")
+
+	row := ess.Store.Db.Model(&eventSubscription{}).Select("id, url, secret, event_types, created").Where(&eventSubscription{ID: id}).Row()
+	dbEventSubscription := eventSubscription{}
+	if err := row.Scan(&dbEventSubscription.ID, &dbEventSubscription.URL, &dbEventSubscription.Secret,
+		&dbEventSubscription.EventTypes, &dbEventSubscription.CreatedAt); err != nil {
+		return nil, errors.Wrap(err, "postgres/event_subscription_store:Retrieve() - Could not scan record ")
+	}
+	return ess.toEventSubscription(&dbEventSubscription)
+}
+
+// Update replaces the URL and the event types of the subscription. The secret is only replaced when a new one is provided.
+func (ess *EventSubscriptionStore) Update(es *hvs.EventSubscription) (*hvs.EventSubscription, error) {
+	defaultLog.Trace("postgres/event_subscription_store:Update() Entering")
+	defer defaultLog.Trace("postgres/event_subscription_store:Update() Leaving")
+
+	updates := map[string]interface{}{
+		"url":         es.URL,
+		"event_types": PGEventTypes(es.EventTypes),
+	}
+	if es.Secret != "" {
+		encSecret, err := utils.EncryptString(es.Secret, ess.Dek)
+		if err != nil {
+			return nil, errors.Wrap(err, "postgres/event_subscription_store:Update() failed to encrypt webhook secret")
+		}
+		updates["secret"] = encSecret
+	}
+
+	if db := ess.Store.Db.Model(&eventSubscription{ID: es.ID}).Updates(updates); db.Error != nil || db.RowsAffected != 1 {
+		if db.Error != nil {
+			return nil, errors.Wrap(db.Error, "postgres/event_subscription_store:Update() failed to update EventSubscription "+es.ID.String())
+		}
+		return nil, errors.New("postgres/event_subscription_store:Update() - no rows affected - Record not found = id : " + es.ID.String())
+	}
+	return ess.Retrieve(es.ID)
+}
+
+func (ess *EventSubscriptionStore) Delete(id uuid.UUID) error {
+	defaultLog.Trace("postgres/event_subscription_store:Delete() Entering")
+	defer defaultLog.Trace("postgres/event_subscription_store:Delete() Leaving")
+
+	if err := ess.Store.Db.Delete(&eventSubscription{ID: id}).Error; err != nil {
+		return errors.Wrap(err, "postgres/event_subscription_store:Delete() failed to delete EventSubscription")
+	}
+	return nil
+}
+
+func (ess *EventSubscriptionStore) Search(criteria *models.EventSubscriptionFilterCriteria) ([]hvs.EventSubscription, error) {
+	defaultLog.Trace("postgres/event_subscription_store:Search() Entering")
+	defer defaultLog.Trace("postgres/event_subscription_store:Search() Leaving")
+
+	var dbEventSubscriptions []eventSubscription
+	if err := buildEventSubscriptionSearchQuery(ess.Store.Db, criteria).Find(&dbEventSubscriptions).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/event_subscription_store:Search() failed to retrieve EventSubscriptions")
+	}
+
+	eventSubscriptions := make([]hvs.EventSubscription, 0, len(dbEventSubscriptions))
+	for i := range dbEventSubscriptions {
+		es, err := ess.toEventSubscription(&dbEventSubscriptions[i])
+		if err != nil {
+			return nil, errors.Wrap(err, "postgres/event_subscription_store:Search() failed to read EventSubscription")
+		}
+		eventSubscriptions = append(eventSubscriptions, *es)
+	}
+	return eventSubscriptions, nil
+}
+
+func buildEventSubscriptionSearchQuery(tx *gorm.DB, criteria *models.EventSubscriptionFilterCriteria) *gorm.DB {
+	defaultLog.Trace("postgres/event_subscription_store:buildEventSubscriptionSearchQuery() Entering")
+	defer defaultLog.Trace("postgres/event_subscription_store:buildEventSubscriptionSearchQuery() Leaving")
+
+	tx = tx.Model(&eventSubscription{}).Order("created")
+	if criteria == nil {
+		return tx
+	}
+	if criteria.Id != uuid.Nil {
+		tx = tx.Where("id = ?", criteria.Id)
+	}
+	if criteria.EventType != "" {
+		// subscriptions without event types receive all the events
+		eventTypes, _ := json.Marshal([]string{criteria.EventType})
+		tx = tx.Where("(event_types = '[]'::JSONB OR event_types @> ?::JSONB)", string(eventTypes))
+	}
+	return tx
+}
+
+func (ess *EventSubscriptionStore) toEventSubscription(dbEventSubscription *eventSubscription) (*hvs.EventSubscription, error) {
+	secret, err := utils.DecryptString(dbEventSubscription.Secret, ess.Dek)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt webhook secret")
+	}
+	return &hvs.EventSubscription{
+		ID:         dbEventSubscription.ID,
+		URL:        dbEventSubscription.URL,
+		Secret:     secret,
+		EventTypes: dbEventSubscription.EventTypes,
+		Created:    dbEventSubscription.CreatedAt,
+	}, nil
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
 	fc "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/common"
 	flavormodel "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/model"
@@ -19,25 +20,35 @@ import (
 )
 
 type FlavorStore struct {
-	Store *DataStore
+	Store          *DataStore
+	AuditLogWriter domain.AuditLogWriter
 }
 
 func NewFlavorStore(store *DataStore) *FlavorStore {
-	return &FlavorStore{store}
+	return &FlavorStore{Store: store}
 }
 
 // create flavors
 func (f *FlavorStore) Create(signedFlavor *hvs.SignedFlavor) (*hvs.SignedFlavor, error) {
 	defaultLog.Trace("postgres/flavor_store:Create() Entering")
 	defer defaultLog.Trace("postgres/flavor_store:Create() Leaving")
+
+	return f.CreateWithLifecycle(signedFlavor, nil)
+}
+
+// CreateWithLifecycle creates a flavor in the given lifecycle state, or active when the lifecycle is nil. The flavor
+// is inserted in its final state so that it is never in effect before it gets its state.
+func (f *FlavorStore) CreateWithLifecycle(signedFlavor *hvs.SignedFlavor, lifecycle *hvs.FlavorLifecycle) (*hvs.SignedFlavor, error) {
+	defaultLog.Trace("postgres/flavor_store:CreateWithLifecycle() Entering")
+	defer defaultLog.Trace("postgres/flavor_store:CreateWithLifecycle() Leaving")
 	if signedFlavor == nil || signedFlavor.Signature == "" || signedFlavor.Flavor.Meta.Description[flavormodel.Label].(string) == "" {
-		return nil, errors.New("postgres/flavor_store:Create()- invalid input : must have content, signature and the label for the flavor")
+		return nil, errors.New("postgres/flavor_store:CreateWithLifecycle()- invalid input : must have content, signature and the label for the flavor")
 	}
 
 	if signedFlavor.Flavor.Meta.ID == uuid.Nil {
 		newUuid, err := uuid.NewRandom()
 		if err != nil {
-			return nil, errors.Wrap(err, "postgres/flavor_store:Create() failed to create new UUID")
+			return nil, errors.Wrap(err, "postgres/flavor_store:CreateWithLifecycle() failed to create new UUID")
 		}
 		signedFlavor.Flavor.Meta.ID = newUuid
 	}
@@ -49,14 +60,22 @@ func (f *FlavorStore) Create(signedFlavor *hvs.SignedFlavor) (*hvs.SignedFlavor,
 		Label:      signedFlavor.Flavor.Meta.Description[flavormodel.Label].(string),
 		FlavorPart: signedFlavor.Flavor.Meta.Description[flavormodel.FlavorPart].(string),
 		Signature:  signedFlavor.Signature,
+		State:      hvs.FlavorStateActive.String(),
+	}
+	if lifecycle != nil {
+		dbf.State = lifecycle.State.String()
+		dbf.NotBefore = lifecycle.NotBefore
+		dbf.NotAfter = lifecycle.NotAfter
 	}
 
 	if err := f.Store.Db.Create(&dbf).Error; err != nil {
-		return nil, errors.Wrap(err, "postgres/flavor_store:Create() failed to create flavor")
+		return nil, errors.Wrap(err, "postgres/flavor_store:CreateWithLifecycle() failed to create flavor")
 	}
 	return signedFlavor, nil
 }
 
+var flavorPageColumns = pageColumns{models.SortByLabel: "f.label", models.SortByID: "f.id"}
+
 func (f *FlavorStore) Search(flavorFilter *models.FlavorVerificationFC) ([]hvs.SignedFlavor, error) {
 	defaultLog.Trace("postgres/flavor_store:Search() Entering")
 	defer defaultLog.Trace("postgres/flavor_store:Search() Leaving")
@@ -83,7 +102,7 @@ func (f *FlavorStore) Search(flavorFilter *models.FlavorVerificationFC) ([]hvs.S
 			flavorFilter.FlavorPartsWithLatest = getFlavorPartsWithLatestMap(flavorFilter.FlavorFC.FlavorParts, flavorFilter.FlavorPartsWithLatest)
 		}
 		// add all flavor parts in list of flavor Parts
-		tx = f.buildMultipleFlavorPartQueryString(tx, flavorFilter.FlavorFC.FlavorgroupID, flavorFilter.FlavorMeta, flavorFilter.FlavorPartsWithLatest)
+		tx = f.buildMultipleFlavorPartQueryString(tx, flavorFilter.FlavorFC.FlavorgroupID, flavorFilter.FlavorMeta, flavorFilter.FlavorPartsWithLatest, flavorFilter.Lifecycle)
 	}
 
 	if tx == nil {
@@ -91,6 +110,17 @@ func (f *FlavorStore) Search(flavorFilter *models.FlavorVerificationFC) ([]hvs.S
 			" object in flavor Search function")
 	}
 
+	// the flavor part queries combine their conditions with OR, so the page is selected from
+	// the matching flavor ids rather than by adding conditions to the same query
+	if flavorFilter.FlavorFC.Page != nil {
+		matching := tx.Select("f.id").SubQuery()
+		tx = f.Store.Db.Table("flavor f").Select("f.id, f.content, f.signature").Where("f.id IN ?", matching)
+		tx, err = applyPageCriteria(tx, flavorFilter.FlavorFC.Page, flavorPageColumns, "f.id")
+		if err != nil {
+			return nil, errors.Wrap(err, "postgres/flavor_store:Search() failed to apply page criteria")
+		}
+	}
+
 	rows, err := tx.Rows()
 	if err != nil {
 		return nil, errors.Wrap(err, "postgres/flavor_store:Search() failed to retrieve records from db")
@@ -114,7 +144,7 @@ func (f *FlavorStore) Search(flavorFilter *models.FlavorVerificationFC) ([]hvs.S
 	return signedFlavors, nil
 }
 
-func (f *FlavorStore) buildMultipleFlavorPartQueryString(tx *gorm.DB, fgId uuid.UUID, flavorMetaInfo map[fc.FlavorPart][]models.FlavorMetaKv, flavorPartsWithLatest map[fc.FlavorPart]bool) *gorm.DB {
+func (f *FlavorStore) buildMultipleFlavorPartQueryString(tx *gorm.DB, fgId uuid.UUID, flavorMetaInfo map[fc.FlavorPart][]models.FlavorMetaKv, flavorPartsWithLatest map[fc.FlavorPart]bool, lifecycle *models.FlavorLifecycleFC) *gorm.DB {
 	defaultLog.Trace("postgres/flavor_store:buildMultipleFlavorPartQueryString() Entering")
 	defer defaultLog.Trace("postgres/flavor_store:buildMultipleFlavorPartQueryString() Leaving")
 
@@ -135,6 +165,8 @@ func (f *FlavorStore) buildMultipleFlavorPartQueryString(tx *gorm.DB, fgId uuid.
 				for _, pfQueryAttribute := range pfQueryAttributes {
 					biosQuery = biosQuery.Where(convertToPgJsonqueryString("f.content", pfQueryAttribute.Key)+" = ?", pfQueryAttribute.Value)
 				}
+				// the latest flavor is selected among the flavors matching the lifecycle criteria
+				biosQuery = applyLifecycleCriteria(biosQuery, lifecycle)
 				// apply limit if latest
 				if flavorPartsWithLatest[fc.FlavorPartPlatform] {
 					biosQuery = biosQuery.Order("f.created_at desc").Limit(1)
@@ -148,6 +180,8 @@ func (f *FlavorStore) buildMultipleFlavorPartQueryString(tx *gorm.DB, fgId uuid.
 				for _, osfQueryAttribute := range osfQueryAttributes {
 					osQuery = osQuery.Where(convertToPgJsonqueryString("f.content", osfQueryAttribute.Key)+" = ?", osfQueryAttribute.Value)
 				}
+				// the latest flavor is selected among the flavors matching the lifecycle criteria
+				osQuery = applyLifecycleCriteria(osQuery, lifecycle)
 				// apply limit if latest
 				if flavorPartsWithLatest[fc.FlavorPartOs] {
 					osQuery = osQuery.Order("f.created_at desc").Limit(1)
@@ -163,6 +197,8 @@ func (f *FlavorStore) buildMultipleFlavorPartQueryString(tx *gorm.DB, fgId uuid.
 				for _, hufQueryAttribute := range hufQueryAttributes {
 					hostUniqueQuery = hostUniqueQuery.Where(convertToPgJsonqueryString("f.content", hufQueryAttribute.Key)+" = ?", hufQueryAttribute.Value)
 				}
+				// the latest flavor is selected among the flavors matching the lifecycle criteria
+				hostUniqueQuery = applyLifecycleCriteria(hostUniqueQuery, lifecycle)
 				// apply limit if latest
 				if flavorPartsWithLatest[fc.FlavorPartHostUnique] {
 					hostUniqueQuery = hostUniqueQuery.Order("f.created_at desc").Limit(1)
@@ -176,6 +212,8 @@ func (f *FlavorStore) buildMultipleFlavorPartQueryString(tx *gorm.DB, fgId uuid.
 				for _, sfQueryAttribute := range sfQueryAttributes {
 					softwareQuery = softwareQuery.Where("f.label IN (?)", sfQueryAttribute.Value.([]string))
 				}
+				// the latest flavor is selected among the flavors matching the lifecycle criteria
+				softwareQuery = applyLifecycleCriteria(softwareQuery, lifecycle)
 				// apply limit if latest
 				if flavorPartsWithLatest[fc.FlavorPartSoftware] {
 					softwareQuery = softwareQuery.Order("f.created_at desc").Limit(1)
@@ -190,6 +228,8 @@ func (f *FlavorStore) buildMultipleFlavorPartQueryString(tx *gorm.DB, fgId uuid.
 				for _, atfQueryAttribute := range atfQueryAttributes {
 					aTagQuery = aTagQuery.Where(convertToPgJsonqueryString("f.content", atfQueryAttribute.Key)+" = ?", atfQueryAttribute.Value)
 				}
+				// the latest flavor is selected among the flavors matching the lifecycle criteria
+				aTagQuery = applyLifecycleCriteria(aTagQuery, lifecycle)
 				// apply limit if latest
 				if flavorPartsWithLatest[fc.FlavorPartAssetTag] {
 					aTagQuery = aTagQuery.Order("f.created_at desc").Limit(1)
@@ -247,13 +287,30 @@ func (f *FlavorStore) buildMultipleFlavorPartQueryString(tx *gorm.DB, fgId uuid.
 	// check if none of the flavor part queries are not formed,
 	if subQuery != nil && (biosQuery != nil || aTagQuery != nil || softwareQuery != nil || hostUniqueQuery != nil || osQuery != nil) {
 		tx = subQuery
-	} else if fgId != uuid.Nil {
-		fgSubQuery := buildFlavorPartQueryStringWithFlavorgroup(fgId.String(), tx).SubQuery()
-		tx = tx.Where("f.id IN ?", fgSubQuery)
+	} else {
+		if fgId != uuid.Nil {
+			fgSubQuery := buildFlavorPartQueryStringWithFlavorgroup(fgId.String(), tx).SubQuery()
+			tx = tx.Where("f.id IN ?", fgSubQuery)
+		}
+		tx = applyLifecycleCriteria(tx, lifecycle)
 	}
 	return tx
 }
 
+// applyLifecycleCriteria restricts the query to the flavors in effect at the time of the criteria, or to the
+// flavors evaluated in shadow. The conditions match the ones of hvs.FlavorLifecycle.EffectiveState()
+func applyLifecycleCriteria(tx *gorm.DB, lifecycle *models.FlavorLifecycleFC) *gorm.DB {
+	if lifecycle == nil {
+		return tx
+	}
+	inEffectStates := []string{hvs.FlavorStateActive.String(), hvs.FlavorStateDeprecated.String()}
+	tx = tx.Where("f.not_after IS NULL OR f.not_after > ?", lifecycle.At)
+	if lifecycle.Shadow {
+		return tx.Where("f.state = ? OR (f.state IN (?) AND f.not_before > ?)", hvs.FlavorStateStaged.String(), inEffectStates, lifecycle.At)
+	}
+	return tx.Where("f.state IN (?) AND (f.not_before IS NULL OR f.not_before <= ?)", inEffectStates, lifecycle.At)
+}
+
 func convertToPgJsonqueryString(queryHead string, jsonKeyPath string) string {
 	jsonQueryStr := queryHead
 	flavorMetaPath := strings.Split(jsonKeyPath, ".")
@@ -340,3 +397,103 @@ func (f *FlavorStore) Delete(flavorId uuid.UUID) error {
 	}
 	return nil
 }
+
+// RetrieveLifecycle returns the lifecycle state of a flavor
+func (f *FlavorStore) RetrieveLifecycle(flavorId uuid.UUID) (*hvs.FlavorLifecycle, error) {
+	defaultLog.Trace("postgres/flavor_store:RetrieveLifecycle() Entering")
+	defer defaultLog.Trace("postgres/flavor_store:RetrieveLifecycle() Leaving")
+
+	lifecycle := hvs.FlavorLifecycle{}
+	row := f.Store.Db.Model(flavor{}).Select("id, state, not_before, not_after").Where(&flavor{ID: flavorId}).Row()
+	if err := row.Scan(&lifecycle.FlavorId, &lifecycle.State, &lifecycle.NotBefore, &lifecycle.NotAfter); err != nil {
+		return nil, errors.Wrap(err, "postgres/flavor_store:RetrieveLifecycle() - Could not scan record ")
+	}
+	return &lifecycle, nil
+}
+
+// SearchLifecycles returns the lifecycle state of the given flavors
+func (f *FlavorStore) SearchLifecycles(flavorIds []uuid.UUID) ([]hvs.FlavorLifecycle, error) {
+	defaultLog.Trace("postgres/flavor_store:SearchLifecycles() Entering")
+	defer defaultLog.Trace("postgres/flavor_store:SearchLifecycles() Leaving")
+
+	lifecycles := []hvs.FlavorLifecycle{}
+	if len(flavorIds) == 0 {
+		return lifecycles, nil
+	}
+	rows, err := f.Store.Db.Model(flavor{}).Select("id, state, not_before, not_after").Where("id IN (?)", flavorIds).Rows()
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/flavor_store:SearchLifecycles() failed to retrieve records from db")
+	}
+	defer func() {
+		derr := rows.Close()
+		if derr != nil {
+			defaultLog.WithError(derr).Error("Error closing rows")
+		}
+	}()
+
+	for rows.Next() {
+		lifecycle := hvs.FlavorLifecycle{}
+		if err := rows.Scan(&lifecycle.FlavorId, &lifecycle.State, &lifecycle.NotBefore, &lifecycle.NotAfter); err != nil {
+			return nil, errors.Wrap(err, "postgres/flavor_store:SearchLifecycles() failed to scan record")
+		}
+		lifecycles = append(lifecycles, lifecycle)
+	}
+	return lifecycles, nil
+}
+
+// SearchLifecycleTransitions returns the lifecycle state of the flavors which are not revoked and whose not_before or
+// not_after date is after from and not after to
+func (f *FlavorStore) SearchLifecycleTransitions(from, to time.Time) ([]hvs.FlavorLifecycle, error) {
+	defaultLog.Trace("postgres/flavor_store:SearchLifecycleTransitions() Entering")
+	defer defaultLog.Trace("postgres/flavor_store:SearchLifecycleTransitions() Leaving")
+
+	rows, err := f.Store.Db.Model(flavor{}).Select("id, state, not_before, not_after").
+		Where("state <> ?", hvs.FlavorStateRevoked.String()).
+		Where("(not_before > ? AND not_before <= ?) OR (not_after > ? AND not_after <= ?)", from, to, from, to).Rows()
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/flavor_store:SearchLifecycleTransitions() failed to retrieve records from db")
+	}
+	defer func() {
+		derr := rows.Close()
+		if derr != nil {
+			defaultLog.WithError(derr).Error("Error closing rows")
+		}
+	}()
+
+	lifecycles := []hvs.FlavorLifecycle{}
+	for rows.Next() {
+		lifecycle := hvs.FlavorLifecycle{}
+		if err := rows.Scan(&lifecycle.FlavorId, &lifecycle.State, &lifecycle.NotBefore, &lifecycle.NotAfter); err != nil {
+			return nil, errors.Wrap(err, "postgres/flavor_store:SearchLifecycleTransitions() failed to scan record")
+		}
+		lifecycles = append(lifecycles, lifecycle)
+	}
+	return lifecycles, nil
+}
+
+// UpdateLifecycle moves a flavor to a lifecycle state and records the transition in the audit log
+func (f *FlavorStore) UpdateLifecycle(lifecycle *hvs.FlavorLifecycle) (*hvs.FlavorLifecycle, error) {
+	defaultLog.Trace("postgres/flavor_store:UpdateLifecycle() Entering")
+	defer defaultLog.Trace("postgres/flavor_store:UpdateLifecycle() Leaving")
+
+	current, err := f.RetrieveLifecycle(lifecycle.FlavorId)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/flavor_store:UpdateLifecycle() failed to retrieve flavor lifecycle")
+	}
+
+	if err := f.Store.Db.Model(&flavor{ID: lifecycle.FlavorId}).Updates(map[string]interface{}{
+		"state":      lifecycle.State.String(),
+		"not_before": lifecycle.NotBefore,
+		"not_after":  lifecycle.NotAfter,
+	}).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/flavor_store:UpdateLifecycle() failed to update flavor lifecycle")
+	}
+	// log to audit log
+	if f.AuditLogWriter != nil {
+		auditEntry, err := f.AuditLogWriter.CreateEntry("update", current, lifecycle)
+		if err == nil {
+			f.AuditLogWriter.Log(auditEntry)
+		}
+	}
+	return lifecycle, nil
+}
@@ -81,6 +81,8 @@ func (f *FlavorGroupStore) Retrieve(flavorGroupId uuid.UUID) (*hvs.FlavorGroup,
 	return &fg, nil
 }
 
+var flavorGroupPageColumns = pageColumns{models.SortByName: "name", models.SortByID: "id"}
+
 func (f *FlavorGroupStore) Search(fgFilter *models.FlavorGroupFilterCriteria) ([]hvs.FlavorGroup, error) {
 	defaultLog.Trace("postgres/flavorgroup_store:Search() Entering")
 	defer defaultLog.Trace("postgres/flavorgroup_store:Search() Leaving")
@@ -104,6 +106,13 @@ func (f *FlavorGroupStore) Search(fgFilter *models.FlavorGroupFilterCriteria) ([
 			" a gorm query object in FlavorGroups Search function.")
 	}
 
+	if fgFilter != nil && fgFilter.Page != nil {
+		tx, err = applyPageCriteria(tx, fgFilter.Page, flavorGroupPageColumns, "id")
+		if err != nil {
+			return nil, errors.Wrap(err, "postgres/flavorgroup_store:Search() failed to apply page criteria")
+		}
+	}
+
 	rows, err := tx.Rows()
 	if err != nil {
 		return nil, errors.Wrap(err, "postgres/flavorgroup_store:Search() failed to retrieve records from db")
@@ -0,0 +1,182 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package postgres
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/utils"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+// HostImportJobStore persists the bulk host import jobs, the requested hosts being encrypted with the data
+// encryption key since they hold the host credentials
+type HostImportJobStore struct {
+	Store *DataStore
+	Dek   []byte
+}
+
+func NewHostImportJobStore(store *DataStore, dek []byte) *HostImportJobStore {
+	return &HostImportJobStore{
+		Store: store,
+		Dek:   dek,
+	}
+}
+
+func (hijs *HostImportJobStore) Create(job *hvs.HostImportJob) (*hvs.HostImportJob, error) {
+	defaultLog.Trace("postgres/host_import_job_store:Create() Entering")
+	defer defaultLog.Trace("postgres/host_import_job_store:Create() Leaving")
+
+	newUuid, err := uuid.NewRandom()
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/host_import_job_store:Create() failed to create new UUID")
+	}
+	job.ID = newUuid
+	job.Created = time.Now()
+	job.Updated = job.Created
+
+	dbRows := make([]hostImportJobRow, 0, len(job.Rows))
+	for i := range job.Rows {
+		entry, err := json.Marshal(job.Rows[i].Entry)
+		if err != nil {
+			return nil, errors.Wrap(err, "postgres/host_import_job_store:Create() failed to marshal host import entry")
+		}
+		encEntry, err := utils.EncryptString(string(entry), hijs.Dek)
+		if err != nil {
+			return nil, errors.Wrap(err, "postgres/host_import_job_store:Create() failed to encrypt host import entry")
+		}
+		dbRows = append(dbRows, hostImportJobRow{
+			JobID:            job.ID,
+			Row:              job.Rows[i].Row,
+			HostName:         job.Rows[i].HostName,
+			ConnectionString: job.Rows[i].ConnectionString,
+			Entry:            encEntry,
+			Status:           string(job.Rows[i].Status),
+			HostID:           job.Rows[i].HostId,
+			Error:            job.Rows[i].Error,
+			Attempts:         job.Rows[i].Attempts,
+		})
+	}
+
+	tx := hijs.Store.Db.Begin()
+	if err := tx.Create(&hostImportJob{
+		ID:        job.ID,
+		Status:    string(job.Status),
+		CreatedAt: job.Created,
+		UpdatedAt: job.Updated,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.Wrap(err, "postgres/host_import_job_store:Create() failed to create HostImportJob")
+	}
+	for i := range dbRows {
+		if err := tx.Create(&dbRows[i]).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.Wrap(err, "postgres/host_import_job_store:Create() failed to create HostImportJob row")
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/host_import_job_store:Create() failed to commit HostImportJob")
+	}
+	job.CountRows()
+	return job, nil
+}
+
+func (hijs *HostImportJobStore) Retrieve(id uuid.UUID) (*hvs.HostImportJob, error) {
+	defaultLog.Trace("postgres/host_import_job_store:Retrieve() Entering")
+	defer defaultLog.Trace("postgres/host_import_job_store:Retrieve() Leaving")
+
+	dbJob := hostImportJob{}
+	row := hijs.Store.Db.Model(&hostImportJob{}).Select("id, status, created, updated").Where(&hostImportJob{ID: id}).Row()
+	if err := row.Scan(&dbJob.ID, &dbJob.Status, &dbJob.CreatedAt, &dbJob.UpdatedAt); err != nil {
+		return nil, errors.Wrap(err, "postgres/host_import_job_store:Retrieve() - Could not scan record ")
+	}
+
+	var dbRows []hostImportJobRow
+	if err := hijs.Store.Db.Where(&hostImportJobRow{JobID: id}).Order("row").Find(&dbRows).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/host_import_job_store:Retrieve() failed to retrieve HostImportJob rows")
+	}
+
+	job := hvs.HostImportJob{
+		ID:      dbJob.ID,
+		Status:  hvs.HostImportJobStatus(dbJob.Status),
+		Created: dbJob.CreatedAt,
+		Updated: dbJob.UpdatedAt,
+		Rows:    make([]hvs.HostImportRow, 0, len(dbRows)),
+	}
+	for i := range dbRows {
+		entry, err := utils.DecryptString(dbRows[i].Entry, hijs.Dek)
+		if err != nil {
+			return nil, errors.Wrap(err, "postgres/host_import_job_store:Retrieve() failed to decrypt host import entry")
+		}
+		importRow := hvs.HostImportRow{
+			Row:              dbRows[i].Row,
+			HostName:         dbRows[i].HostName,
+			ConnectionString: dbRows[i].ConnectionString,
+			Status:           hvs.HostImportRowStatus(dbRows[i].Status),
+			HostId:           dbRows[i].HostID,
+			Error:            dbRows[i].Error,
+			Attempts:         dbRows[i].Attempts,
+		}
+		if err := json.Unmarshal([]byte(entry), &importRow.Entry); err != nil {
+			return nil, errors.Wrap(err, "postgres/host_import_job_store:Retrieve() failed to unmarshal host import entry")
+		}
+		job.Rows = append(job.Rows, importRow)
+	}
+	job.CountRows()
+	return &job, nil
+}
+
+func (hijs *HostImportJobStore) SearchByStatus(status hvs.HostImportJobStatus) ([]uuid.UUID, error) {
+	defaultLog.Trace("postgres/host_import_job_store:SearchByStatus() Entering")
+	defer defaultLog.Trace("postgres/host_import_job_store:SearchByStatus() Leaving")
+
+	var ids []uuid.UUID
+	if err := hijs.Store.Db.Model(&hostImportJob{}).Where("status = ?", string(status)).Order("created").Pluck("id", &ids).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/host_import_job_store:SearchByStatus() failed to search HostImportJobs")
+	}
+	return ids, nil
+}
+
+func (hijs *HostImportJobStore) UpdateStatus(id uuid.UUID, status hvs.HostImportJobStatus) error {
+	defaultLog.Trace("postgres/host_import_job_store:UpdateStatus() Entering")
+	defer defaultLog.Trace("postgres/host_import_job_store:UpdateStatus() Leaving")
+
+	if db := hijs.Store.Db.Model(&hostImportJob{ID: id}).Updates(map[string]interface{}{
+		"status":  string(status),
+		"updated": time.Now(),
+	}); db.Error != nil || db.RowsAffected != 1 {
+		if db.Error != nil {
+			return errors.Wrap(db.Error, "postgres/host_import_job_store:UpdateStatus() failed to update HostImportJob "+id.String())
+		}
+		return errors.New("postgres/host_import_job_store:UpdateStatus() - no rows affected - Record not found = id : " + id.String())
+	}
+	return nil
+}
+
+// UpdateRow records the result of the registration of a host of the job, the requested host is left unchanged
+func (hijs *HostImportJobStore) UpdateRow(jobId uuid.UUID, importRow *hvs.HostImportRow) error {
+	defaultLog.Trace("postgres/host_import_job_store:UpdateRow() Entering")
+	defer defaultLog.Trace("postgres/host_import_job_store:UpdateRow() Leaving")
+
+	if db := hijs.Store.Db.Model(&hostImportJobRow{}).Where("job_id = ? AND row = ?", jobId, importRow.Row).Updates(map[string]interface{}{
+		"status":   string(importRow.Status),
+		"host_id":  importRow.HostId,
+		"error":    importRow.Error,
+		"attempts": importRow.Attempts,
+	}); db.Error != nil || db.RowsAffected != 1 {
+		if db.Error != nil {
+			return errors.Wrapf(db.Error, "postgres/host_import_job_store:UpdateRow() failed to update row %d of HostImportJob %s", importRow.Row, jobId)
+		}
+		return errors.Errorf("postgres/host_import_job_store:UpdateRow() - no rows affected - Record not found = row %d of id : %s", importRow.Row, jobId)
+	}
+	if err := hijs.Store.Db.Model(&hostImportJob{ID: jobId}).Update("updated", time.Now()).Error; err != nil {
+		return errors.Wrap(err, "postgres/host_import_job_store:UpdateRow() failed to update HostImportJob "+jobId.String())
+	}
+	return nil
+}
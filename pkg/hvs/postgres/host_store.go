@@ -14,6 +14,7 @@ import (
 	"github.com/pkg/errors"
 	"reflect"
 	"strings"
+	"time"
 )
 
 type HostStore struct {
@@ -25,9 +26,11 @@ func NewHostStore(store *DataStore) *HostStore {
 }
 
 const (
-	hostFields = "host.id, host.name, host.description, host.connection_string, host.hardware_uuid"
+	hostFields = "host.id, host.name, host.description, host.connection_string, host.hardware_uuid, host.labels"
 )
 
+var hostPageColumns = pageColumns{models.SortByName: "host.name", models.SortByID: "host.id"}
+
 func (hs *HostStore) Create(h *hvs.Host) (*hvs.Host, error) {
 	defaultLog.Trace("postgres/host_store:Create() Entering")
 	defer defaultLog.Trace("postgres/host_store:Create() Leaving")
@@ -42,6 +45,7 @@ func (hs *HostStore) Create(h *hvs.Host) (*hvs.Host, error) {
 		Name:             h.HostName,
 		Description:      h.Description,
 		ConnectionString: h.ConnectionString,
+		Labels:           h.Labels,
 	}
 
 	if h.HardwareUuid != nil {
@@ -68,26 +72,26 @@ func (hs *HostStore) Retrieve(id uuid.UUID, criteria *models.HostInfoFetchCriter
 		row := buildInfoFetchQuery(tx, criteria, nil).Row()
 		if criteria.GetReport && criteria.GetHostStatus {
 			if err := row.Scan(&h.Id, &h.HostName, &h.Description, &h.ConnectionString, &h.HardwareUuid,
-				(*PGTrustReport)(&report), (*PGHostStatusInformation)(&connectionStatus)); err != nil {
+				(*PGHostLabels)(&h.Labels), (*PGTrustReport)(&report), (*PGHostStatusInformation)(&connectionStatus)); err != nil {
 				return nil, errors.Wrap(err, "postgres/host_store:Retrieve() failed to scan record")
 			}
 			h.Report = &report
 			h.ConnectionStatus = &connectionStatus
 		} else if criteria.GetReport {
 			if err := row.Scan(&h.Id, &h.HostName, &h.Description, &h.ConnectionString, &h.HardwareUuid,
-				(*PGTrustReport)(&report)); err != nil {
+				(*PGHostLabels)(&h.Labels), (*PGTrustReport)(&report)); err != nil {
 				return nil, errors.Wrap(err, "postgres/host_store:Retrieve() failed to scan record")
 			}
 			h.Report = &report
 		} else if criteria.GetHostStatus {
 			if err := row.Scan(&h.Id, &h.HostName, &h.Description, &h.ConnectionString, &h.HardwareUuid,
-				(*PGHostStatusInformation)(&connectionStatus)); err != nil {
+				(*PGHostLabels)(&h.Labels), (*PGHostStatusInformation)(&connectionStatus)); err != nil {
 				return nil, errors.Wrap(err, "postgres/host_store:Retrieve() failed to scan record")
 			}
 			h.ConnectionStatus = &connectionStatus
 		}
 	} else {
-		if err := tx.Row().Scan(&h.Id, &h.HostName, &h.Description, &h.ConnectionString, &h.HardwareUuid); err != nil {
+		if err := tx.Select(hostFields).Row().Scan(&h.Id, &h.HostName, &h.Description, &h.ConnectionString, &h.HardwareUuid, (*PGHostLabels)(&h.Labels)); err != nil {
 			return nil, errors.Wrap(err, "postgres/host_store:Retrieve() failed to scan record")
 		}
 	}
@@ -110,13 +114,25 @@ func (hs *HostStore) Update(h *hvs.Host) error {
 		dbHost.HardwareUuid = models.NewHwUUID(*h.HardwareUuid)
 	}
 
-	if db := hs.Store.Db.Model(&dbHost).Updates(&dbHost); db.Error != nil || db.RowsAffected != 1 {
+	tx := hs.Store.Db.Begin()
+	if db := tx.Model(&dbHost).Updates(&dbHost); db.Error != nil || db.RowsAffected != 1 {
+		tx.Rollback()
 		if db.Error != nil {
 			return errors.Wrap(db.Error, "postgres/host_store:Update() failed to update Host  "+dbHost.Id.String())
 		} else {
 			return errors.New("postgres/host_store:Update() - no rows affected - Record not found = id :  " + dbHost.Id.String())
 		}
 	}
+	// the labels given replace the existing ones even when empty, which the update of the non blank fields skips
+	if h.Labels != nil {
+		if err := tx.Model(&dbHost).Update("labels", PGHostLabels(h.Labels)).Error; err != nil {
+			tx.Rollback()
+			return errors.Wrap(err, "postgres/host_store:Update() failed to update the labels of Host "+dbHost.Id.String())
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return errors.Wrap(err, "postgres/host_store:Update() failed to commit the update of Host "+dbHost.Id.String())
+	}
 	return nil
 }
 
@@ -152,6 +168,16 @@ func (hs *HostStore) Search(filterCriteria *models.HostFilterCriteria, infoFetch
 
 	if infoFetchCriteria != nil && (infoFetchCriteria.GetTrustStatus || infoFetchCriteria.GetHostStatus) {
 		tx = buildInfoFetchQuery(tx, infoFetchCriteria, filterCriteria)
+	} else {
+		tx = tx.Select(hostFields)
+	}
+
+	if filterCriteria != nil && filterCriteria.Page != nil {
+		var err error
+		tx, err = applyPageCriteria(tx, filterCriteria.Page, hostPageColumns, "host.id")
+		if err != nil {
+			return nil, errors.Wrap(err, "postgres/host_store:Search() failed to apply page criteria")
+		}
 	}
 
 	rows, err := tx.Rows()
@@ -171,7 +197,7 @@ func (hs *HostStore) Search(filterCriteria *models.HostFilterCriteria, infoFetch
 	} else {
 		for rows.Next() {
 			host := hvs.Host{}
-			if err := rows.Scan(&host.Id, &host.HostName, &host.Description, &host.ConnectionString, &host.HardwareUuid); err != nil {
+			if err := rows.Scan(&host.Id, &host.HostName, &host.Description, &host.ConnectionString, &host.HardwareUuid, (*PGHostLabels)(&host.Labels)); err != nil {
 				return nil, errors.Wrap(err, "postgres/host_store:Search() failed to scan record")
 			}
 			hosts = append(hosts, &host)
@@ -210,6 +236,11 @@ func buildHostSearchQuery(tx *gorm.DB, criteria *models.HostFilterCriteria) *gor
 		tx = tx.Joins("join report on report.host_id = host.id AND report.trusted = ?", criteria.Trusted)
 	}
 
+	if len(criteria.LabelSelector) > 0 {
+		condition, args := labelSelectorCondition("host.labels", criteria.LabelSelector)
+		tx = tx.Where(condition, args...)
+	}
+
 	if criteria.OrderBy == models.Descending {
 		tx = tx.Order("name desc")
 	} else {
@@ -257,18 +288,18 @@ func getAdditionalHostInfo(criteria *models.HostInfoFetchCriteria, rows *sql.Row
 		connectionStatus := hvs.HostStatusInformation{}
 		if criteria.GetTrustStatus && criteria.GetHostStatus {
 			if err := rows.Scan(&host.Id, &host.HostName, &host.Description, &host.ConnectionString, &host.HardwareUuid,
-				&host.Trusted, (*PGHostStatusInformation)(&connectionStatus)); err != nil {
+				(*PGHostLabels)(&host.Labels), &host.Trusted, (*PGHostStatusInformation)(&connectionStatus)); err != nil {
 				return nil, errors.Wrap(err, "postgres/host_store:Search() failed to scan record")
 			}
 			host.ConnectionStatus = &connectionStatus
 		} else if criteria.GetTrustStatus {
 			if err := rows.Scan(&host.Id, &host.HostName, &host.Description, &host.ConnectionString, &host.HardwareUuid,
-				&host.Trusted); err != nil {
+				(*PGHostLabels)(&host.Labels), &host.Trusted); err != nil {
 				return nil, errors.Wrap(err, "postgres/host_store:Search() failed to scan record")
 			}
 		} else if criteria.GetHostStatus {
 			if err := rows.Scan(&host.Id, &host.HostName, &host.Description, &host.ConnectionString, &host.HardwareUuid,
-				(*PGHostStatusInformation)(&connectionStatus)); err != nil {
+				(*PGHostLabels)(&host.Labels), (*PGHostStatusInformation)(&connectionStatus)); err != nil {
 				return nil, errors.Wrap(err, "postgres/host_store:Search() failed to scan record")
 			}
 			host.ConnectionStatus = &connectionStatus
@@ -510,9 +541,50 @@ func (hs *HostStore) RetrieveDistinctUniqueFlavorParts(hId uuid.UUID) ([]string,
 		return nil, errors.New("postgres/host_store:RetrieveDistinctUniqueFlavorParts() Host ID must be set to get the list of host unique flavor ids")
 	}
 	var uniqueFlavorParts []string
-	err := hs.Store.Db.Model(&flavor{}).Where("id in (select flavor_id from hostunique_flavor where host_id = ?)", hId).Pluck(("DISTINCT(flavor_part)"), &uniqueFlavorParts).Error
+	// only the flavors in effect define their flavor part
+	tx := hs.Store.Db.Table("flavor f").Where("f.id in (select flavor_id from hostunique_flavor where host_id = ?)", hId)
+	tx = applyLifecycleCriteria(tx, &models.FlavorLifecycleFC{At: time.Now()})
+	err := tx.Pluck(("DISTINCT(f.flavor_part)"), &uniqueFlavorParts).Error
 	if err != nil {
 		return nil, errors.Wrap(err, "postgres/host_store:RetrieveDistinctUniqueFlavorParts() failed to retrieve records from db")
 	}
 	return uniqueFlavorParts, nil
 }
+
+// SummarizeTrustByLabel counts the hosts sharing each value of the label by trust status, the hosts without report
+// being unknown. The hosts that do not have the label are not counted.
+func (hs *HostStore) SummarizeTrustByLabel(labelKey string, selector hvs.LabelSelector) ([]hvs.HostLabelTrustCount, error) {
+	defaultLog.Trace("postgres/host_store:SummarizeTrustByLabel() Entering")
+	defer defaultLog.Trace("postgres/host_store:SummarizeTrustByLabel() Leaving")
+
+	tx := hs.Store.Db.Table("host").Select("host.labels ->> ? AS label_value, COUNT(*), "+
+		"COUNT(*) FILTER (WHERE report.trusted), COUNT(*) FILTER (WHERE NOT report.trusted), "+
+		"COUNT(*) FILTER (WHERE report.id IS NULL)", labelKey).
+		Joins("LEFT JOIN report ON report.host_id = host.id").
+		Where("host.labels ->> ? IS NOT NULL", labelKey)
+	if len(selector) > 0 {
+		condition, args := labelSelectorCondition("host.labels", selector)
+		tx = tx.Where(condition, args...)
+	}
+
+	rows, err := tx.Group("label_value").Order("label_value").Rows()
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/host_store:SummarizeTrustByLabel() failed to retrieve records from db")
+	}
+	defer func() {
+		derr := rows.Close()
+		if derr != nil {
+			defaultLog.WithError(derr).Error("Error closing rows")
+		}
+	}()
+
+	summary := []hvs.HostLabelTrustCount{}
+	for rows.Next() {
+		count := hvs.HostLabelTrustCount{}
+		if err := rows.Scan(&count.Value, &count.Total, &count.Trusted, &count.Untrusted, &count.Unknown); err != nil {
+			return nil, errors.Wrap(err, "postgres/host_store:SummarizeTrustByLabel() failed to scan record")
+		}
+		summary = append(summary, count)
+	}
+	return summary, nil
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/stretchr/testify/assert"
+)
+
+var testHostId = uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2")
+
+func TestHostStoreUpdateLabels(t *testing.T) {
+	tests := []struct {
+		name           string
+		labels         map[string]string
+		expectedLabels string
+	}{
+		{name: "replace labels", labels: map[string]string{"env": "prod"}, expectedLabels: `{"env":"prod"}`},
+		{name: "clear labels", labels: map[string]string{}, expectedLabels: `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataStore, mock := NewSQLMockDataStore()
+			hostStore := NewHostStore(dataStore)
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE "host" SET "description" = $1, "id" = $2 WHERE "host"."id" = $3`)).
+				WithArgs("updated host", testHostId, testHostId).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE "host" SET "labels" = $1 WHERE "host"."id" = $2`)).
+				WithArgs([]byte(tt.expectedLabels), testHostId).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			err := hostStore.Update(&hvs.Host{Id: testHostId, Description: "updated host", Labels: tt.labels})
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestHostStoreUpdateKeepsLabels(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	hostStore := NewHostStore(dataStore)
+
+	// the labels are left as they are when none are given
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "host" SET "description" = $1, "id" = $2 WHERE "host"."id" = $3`)).
+		WithArgs("updated host", testHostId, testHostId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := hostStore.Update(&hvs.Host{Id: testHostId, Description: "updated host"})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -23,8 +23,17 @@ import (
 type HostStatusStore struct {
 	Store          *DataStore
 	AuditLogWriter domain.AuditLogWriter
+	EventPublisher domain.EventPublisher
 }
 
+var (
+	latestHostStatusPageColumns = pageColumns{models.SortByCreated: "host_status.created", models.SortByID: "host_status.id"}
+	// audit log entries are ordered on the creation time recorded in the host status itself, every update of a host
+	// status is recorded with the same entity ID so the entry ID is the tie-breaker
+	auditHostStatusPageColumns = pageColumns{models.SortByCreated: "CAST(au.data -> 'Columns' -> 4 ->> 'Value' AS TIMESTAMPTZ)",
+		models.SortByID: "au.entity_id"}
+)
+
 func NewHostStatusStore(store *DataStore) *HostStatusStore {
 	return &HostStatusStore{Store: store}
 }
@@ -141,6 +150,9 @@ func (hss *HostStatusStore) Search(hsFilter *models.HostStatusFilterCriteria) ([
 				return nil, errors.Wrap(err, "postgres/hoststatus_store:Search() convert auditlog entry into HostStatus")
 			}
 			hostStatuses = append(hostStatuses, *hs)
+			if hsFilter.Page != nil {
+				hsFilter.Page.Last = auditPageCursor(hsFilter.Page, &result, hs.Created)
+			}
 		}
 	}
 
@@ -166,6 +178,7 @@ func (hss *HostStatusStore) Persist(hs *hvs.HostStatus) error {
 		if err != nil {
 			return errors.Wrap(err, "postgres/hoststatus_store:Persist() - Failed to Create HostStatus record ")
 		} else {
+			hss.publishStateChange(hs, "")
 			return nil
 		}
 	}
@@ -194,6 +207,9 @@ func (hss *HostStatusStore) Persist(hs *hvs.HostStatus) error {
 			hss.AuditLogWriter.Log(auditEntry)
 		}
 	}
+	if oldHs.Status.HostState != hs.HostStatusInformation.HostState {
+		hss.publishStateChange(hs, oldHs.Status.HostState.String())
+	}
 	return nil
 }
 
@@ -219,6 +235,19 @@ func (hss *HostStatusStore) Delete(hostStatusId uuid.UUID) error {
 	return nil
 }
 
+// publishStateChange notifies the change of the connection state of a host
+func (hss *HostStatusStore) publishStateChange(hs *hvs.HostStatus, previousState string) {
+	if hss.EventPublisher == nil {
+		return
+	}
+	hss.EventPublisher.Publish(&hvs.TrustEvent{
+		Type:              hvs.TrustEventHostStateChanged,
+		HostID:            hs.HostID,
+		HostState:         hs.HostStatusInformation.HostState.String(),
+		PreviousHostState: previousState,
+	})
+}
+
 func (hss *HostStatusStore) FindHostIdsByKeyValue(key, value string) ([]uuid.UUID, error) {
 	defaultLog.Trace("postgres/hoststatus_store:FindHostIdsByKeyValue() Entering")
 	defer defaultLog.Trace("postgres/hoststatus_store:FindHostIdsByKeyValue() Leaving")
@@ -245,6 +274,80 @@ func (hss *HostStatusStore) FindHostIdsByKeyValue(key, value string) ([]uuid.UUI
 	return ids, nil
 }
 
+// CountByHostState counts the hosts selected by the label selector in each connection state
+func (hss *HostStatusStore) CountByHostState(selector hvs.LabelSelector) ([]hvs.HostStateCount, error) {
+	defaultLog.Trace("postgres/hoststatus_store:CountByHostState() Entering")
+	defer defaultLog.Trace("postgres/hoststatus_store:CountByHostState() Leaving")
+
+	tx := hss.Store.Db.Table("host_status hs").
+		Select("COALESCE(hs.status ->> 'host_state', ?) AS host_state, COUNT(*) AS hosts", hvs.HostStateUnknown.String())
+	tx = whereHostLabels(tx, "h.id = hs.host_id", selector)
+	rows, err := tx.Group("host_state").Order("host_state").Rows()
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/hoststatus_store:CountByHostState() failed to retrieve records from db")
+	}
+	defer func() {
+		derr := rows.Close()
+		if derr != nil {
+			defaultLog.WithError(derr).Error("Error closing rows")
+		}
+	}()
+
+	var counts []hvs.HostStateCount
+	for rows.Next() {
+		count := hvs.HostStateCount{}
+		if err := rows.Scan(&count.HostState, &count.Hosts); err != nil {
+			return nil, errors.Wrap(err, "postgres/hoststatus_store:CountByHostState() failed to scan record")
+		}
+		counts = append(counts, count)
+	}
+	return counts, nil
+}
+
+// CountConnectionFailures counts by time bucket the hosts that failed to connect over the date range of the criteria,
+// from the host status records of the audit log
+func (hss *HostStatusStore) CountConnectionFailures(criteria *models.ReportSummaryCriteria) ([]hvs.TrustTimeBucket, error) {
+	defaultLog.Trace("postgres/hoststatus_store:CountConnectionFailures() Entering")
+	defer defaultLog.Trace("postgres/hoststatus_store:CountConnectionFailures() Leaving")
+
+	tx := hss.Store.Db.Table("audit_log_entry au").
+		Select("date_trunc(?, au.created) AS bucket, COUNT(DISTINCT au.data -> 'Columns' -> 1 ->> 'Value') AS hosts", criteria.BucketSize).
+		Where("au.entity_type = 'host_status' AND au.action IN ('create', 'update')").
+		Where("au.data -> 'Columns' -> 2 -> 'Value' ->> 'host_state' IN (?)", connectionFailureStates())
+	tx = buildAuditSummaryQuery(tx, criteria)
+	rows, err := tx.Group("bucket").Order("bucket").Rows()
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/hoststatus_store:CountConnectionFailures() failed to retrieve records from db")
+	}
+	defer func() {
+		derr := rows.Close()
+		if derr != nil {
+			defaultLog.WithError(derr).Error("Error closing rows")
+		}
+	}()
+
+	var buckets []hvs.TrustTimeBucket
+	for rows.Next() {
+		bucket := hvs.TrustTimeBucket{}
+		if err := rows.Scan(&bucket.Start, &bucket.ConnectionFailure); err != nil {
+			return nil, errors.Wrap(err, "postgres/hoststatus_store:CountConnectionFailures() failed to scan record")
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, nil
+}
+
+// connectionFailureStates lists the host states reporting a failure to connect to or to attest the host
+func connectionFailureStates() []string {
+	var states []string
+	for state := hvs.HostStateInvalid; state.Valid(); state++ {
+		if state.IsConnectionFailure() {
+			states = append(states, state.String())
+		}
+	}
+	return states
+}
+
 // buildHostStatusSearchQuery is a helper function to build the query object for a hostStatus search inlcuding results
 // from audit table hostStatus records
 func buildHostStatusSearchQuery(tx *gorm.DB, hsFilter *models.HostStatusFilterCriteria) *gorm.DB {
@@ -252,6 +355,7 @@ func buildHostStatusSearchQuery(tx *gorm.DB, hsFilter *models.HostStatusFilterCr
 	defer defaultLog.Trace("postgres/hoststatus_store:buildHostStatusSearchQuery() Leaving")
 
 	var tableJoinString, additionalOptionsQueryString string
+	var labelArgs []interface{}
 
 	// define joins
 	auditLogAbbrv := "au"
@@ -259,12 +363,12 @@ func buildHostStatusSearchQuery(tx *gorm.DB, hsFilter *models.HostStatusFilterCr
 		auditLogAbbrv = "auj"
 	}
 
-	formattedQuery := "SELECT au.* FROM audit_log_entry au"
+	formattedQuery := "SELECT " + auditLogEntryColumns + " FROM audit_log_entry au"
 
 	additionalOptionsQueryString = fmt.Sprintf("WHERE %s.entity_type = 'host_status' ", auditLogAbbrv)
 
-	// Build table join string with host table if host identifier is set
-	if hsFilter.HostName != "" {
+	// Build table join string with host table if host identifier or label selector is set
+	if hsFilter.HostName != "" || len(hsFilter.LabelSelector) > 0 {
 		tableJoinString = fmt.Sprintf("INNER JOIN host h on CAST(h.id AS VARCHAR) = %s.data -> 'Columns' -> 1 ->> 'Value'", auditLogAbbrv)
 	}
 
@@ -284,6 +388,12 @@ func buildHostStatusSearchQuery(tx *gorm.DB, hsFilter *models.HostStatusFilterCr
 			hostHWUUIDQueryString := fmt.Sprintf("h.hardware_uuid = '%s'", hsFilter.HostHardwareId.String())
 			additionalOptionsQueryString = fmt.Sprintf("%s AND %s", additionalOptionsQueryString, hostHWUUIDQueryString)
 		}
+		//Build label selector partial query string, its arguments preceding the page arguments
+		if len(hsFilter.LabelSelector) > 0 {
+			var labelQueryString string
+			labelQueryString, labelArgs = labelSelectorCondition("h.labels", hsFilter.LabelSelector)
+			additionalOptionsQueryString = fmt.Sprintf("%s AND %s", additionalOptionsQueryString, labelQueryString)
+		}
 	} else {
 		//Build host ID partial query string and add it to the additional options query string
 		if hsFilter.HostId != uuid.Nil {
@@ -341,14 +451,38 @@ func buildHostStatusSearchQuery(tx *gorm.DB, hsFilter *models.HostStatusFilterCr
 		additionalOptionsQueryString = strings.Join([]string{tableJoinString, additionalOptionsQueryString}, " ")
 	}
 
+	// the page condition applies to the outer audit log entries only
+	var pageQueryString string
+	var pageArgs []interface{}
+	orderQueryString := "ORDER BY au.Created DESC"
+	if hsFilter.Page != nil {
+		var err error
+		pageQueryString, pageArgs, err = keysetCondition(hsFilter.Page, auditHostStatusPageColumns, "au.id")
+		if err != nil {
+			defaultLog.WithError(err).Error("postgres/hoststatus_store:buildHostStatusSearchQuery() Invalid page criteria")
+			return nil
+		}
+		orderQueryString = "ORDER BY " + keysetOrder(hsFilter.Page, auditHostStatusPageColumns, "au.id")
+		hsFilter.Limit = hsFilter.Page.Limit
+	}
+
 	if hsFilter.LatestPerHost {
 		maxDateQueryString := fmt.Sprintf("INNER JOIN (SELECT entity_id, max(auj.created) AS max_date "+
 			"FROM audit_log_entry auj %s GROUP BY entity_id) a "+
 			"ON a.entity_id = au.entity_id "+
 			"AND a.max_date = au.created", additionalOptionsQueryString)
-		formattedQuery = fmt.Sprintf("%s %s ORDER BY au.Created DESC", formattedQuery, maxDateQueryString)
+		if pageQueryString != "" {
+			maxDateQueryString = fmt.Sprintf("%s WHERE %s", maxDateQueryString, pageQueryString)
+		}
+		formattedQuery = fmt.Sprintf("%s %s %s", formattedQuery, maxDateQueryString, orderQueryString)
 	} else {
 		formattedQuery = fmt.Sprintf("%s %s", formattedQuery, additionalOptionsQueryString)
+		if pageQueryString != "" {
+			formattedQuery = fmt.Sprintf("%s AND %s", formattedQuery, pageQueryString)
+		}
+		if hsFilter.Page != nil {
+			formattedQuery = fmt.Sprintf("%s %s", formattedQuery, orderQueryString)
+		}
 	}
 
 	if hsFilter.Limit == 0 {
@@ -356,7 +490,7 @@ func buildHostStatusSearchQuery(tx *gorm.DB, hsFilter *models.HostStatusFilterCr
 	}
 
 	// finalize query
-	tx = tx.Raw(formattedQuery).Limit(hsFilter.Limit)
+	tx = tx.Raw(formattedQuery, append(labelArgs, pageArgs...)...).Limit(hsFilter.Limit)
 
 	return tx
 }
@@ -379,8 +513,8 @@ func buildLatestHostStatusSearchQuery(tx *gorm.DB, hsFilter *models.HostStatusFi
 		return tx
 	}
 
-	// For validating HostName or HWUUID we join with the Host table
-	if hsFilter.HostName != "" || hsFilter.HostHardwareId != uuid.Nil {
+	// For validating HostName, HWUUID or labels we join with the Host table
+	if hsFilter.HostName != "" || hsFilter.HostHardwareId != uuid.Nil || len(hsFilter.LabelSelector) > 0 {
 		tx = tx.Joins("INNER JOIN host h on h.id = host_id")
 	}
 
@@ -404,6 +538,12 @@ func buildLatestHostStatusSearchQuery(tx *gorm.DB, hsFilter *models.HostStatusFi
 		tx = tx.Where("h.name = ?", hsFilter.HostName)
 	}
 
+	// Host labels
+	if len(hsFilter.LabelSelector) > 0 {
+		condition, args := labelSelectorCondition("h.labels", hsFilter.LabelSelector)
+		tx = tx.Where(condition, args...)
+	}
+
 	// Host Connection Status
 	if hsFilter.HostStatus != "" {
 		tx = tx.Where(`status @> '{"host_state": "` + strings.ToUpper(hsFilter.HostStatus) + `"}'`)
@@ -417,6 +557,18 @@ func buildLatestHostStatusSearchQuery(tx *gorm.DB, hsFilter *models.HostStatusFi
 	// apply result limits
 	tx = tx.Limit(hsFilter.Limit)
 
+	if hsFilter.Page != nil {
+		condition, args, err := keysetCondition(hsFilter.Page, latestHostStatusPageColumns, "host_status.id")
+		if err != nil {
+			defaultLog.WithError(err).Error("postgres/hoststatus_store:buildLatestHostStatusSearchQuery() Invalid page criteria")
+			return nil
+		}
+		if condition != "" {
+			tx = tx.Where(condition, args...)
+		}
+		tx = tx.Order(keysetOrder(hsFilter.Page, latestHostStatusPageColumns, "host_status.id")).Limit(hsFilter.Page.Limit)
+	}
+
 	return tx
 }
 
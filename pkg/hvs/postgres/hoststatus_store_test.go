@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testHostStatusId        = uuid.MustParse("c1a3a7a6-0f7f-4a5e-9b4e-2d1c2b3a4f5e")
+	auditLogEntryColumnList = []string{"id", "entity_id", "entity_type", "created", "action", "data"}
+)
+
+// testHostStatusAuditRows returns the audit log entries of updates of the same host status
+func testHostStatusAuditRows(t *testing.T, entryIds []uuid.UUID, created time.Time) *sqlmock.Rows {
+	rows := sqlmock.NewRows(auditLogEntryColumnList)
+	for _, entryId := range entryIds {
+		data, err := json.Marshal(models.AuditTableData{Columns: []models.AuditColumnData{
+			{Name: "id", Value: testHostStatusId},
+			{Name: "host_id", Value: testHostId},
+			{Name: "status", Value: map[string]string{"host_state": "CONNECTED"}},
+			{Name: "host_report"},
+			{Name: "created", Value: created.Format(time.RFC3339Nano)},
+		}})
+		assert.NoError(t, err)
+		rows.AddRow(entryId, testHostStatusId, "host_status", created, "update", data)
+	}
+	return rows
+}
+
+func TestHostStatusStoreSearchPagesHostStatusUpdates(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	hostStatusStore := NewHostStatusStore(dataStore)
+	created := time.Now().UTC().Truncate(time.Second)
+	entryIds := []uuid.UUID{
+		uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+		uuid.MustParse("00000000-0000-0000-0000-000000000003"),
+	}
+	page := &models.PageCriteria{Limit: 2, SortBy: models.SortByID, OrderBy: models.Ascending}
+	filter := &models.HostStatusFilterCriteria{HostId: testHostId, FromDate: created.Add(-time.Hour), Page: page}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY au.entity_id asc, au.id asc LIMIT 2`)).
+		WillReturnRows(testHostStatusAuditRows(t, entryIds[:2], created))
+
+	hostStatuses, err := hostStatusStore.Search(filter)
+	assert.NoError(t, err)
+	assert.Len(t, hostStatuses, 2)
+	// the updates share the host status ID, the cursor is positioned on the audit log entry
+	if assert.NotNil(t, page.Last) {
+		assert.Equal(t, entryIds[1], page.Last.ID)
+		assert.Equal(t, testHostStatusId.String(), page.Last.Value)
+	}
+
+	page.After, page.Last = page.Last, nil
+	mock.ExpectQuery(regexp.QuoteMeta(`AND (au.entity_id, au.id) > ($1, $2) ORDER BY au.entity_id asc, au.id asc LIMIT 2`)).
+		WithArgs(testHostStatusId, entryIds[1]).
+		WillReturnRows(testHostStatusAuditRows(t, entryIds[2:], created))
+
+	hostStatuses, err = hostStatusStore.Search(filter)
+	assert.NoError(t, err)
+	if assert.Len(t, hostStatuses, 1) {
+		assert.Equal(t, testHostStatusId, hostStatuses[0].ID)
+	}
+	assert.Equal(t, entryIds[2], page.Last.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHostStatusStoreSearchPagesOnCreationTime(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	hostStatusStore := NewHostStatusStore(dataStore)
+	created := time.Now().UTC().Truncate(time.Second)
+	entryId := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	page := &models.PageCriteria{Limit: 2, SortBy: models.SortByCreated, OrderBy: models.Ascending,
+		After: &models.PageCursor{SortBy: models.SortByCreated, Value: models.PageCursorTime(created), ID: entryId}}
+	filter := &models.HostStatusFilterCriteria{HostId: testHostId, FromDate: created.Add(-time.Hour), Page: page}
+
+	// the updates recorded at the same time are told apart by the audit log entry ID
+	mock.ExpectQuery(regexp.QuoteMeta(`AND (CAST(au.data -> 'Columns' -> 4 ->> 'Value' AS TIMESTAMPTZ), au.id) > ($1, $2)`)).
+		WithArgs(created, entryId).
+		WillReturnRows(testHostStatusAuditRows(t, []uuid.UUID{uuid.MustParse("00000000-0000-0000-0000-000000000003")}, created))
+
+	hostStatuses, err := hostStatusStore.Search(filter)
+	assert.NoError(t, err)
+	assert.Len(t, hostStatuses, 1)
+	if assert.NotNil(t, page.Last) {
+		assert.Equal(t, models.PageCursorTime(created), page.Last.Value)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
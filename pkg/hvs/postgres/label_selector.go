@@ -0,0 +1,48 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/jinzhu/gorm"
+)
+
+// labelSelectorCondition returns the SQL condition and arguments selecting the hosts whose labels, held by the given
+// JSONB column, meet all the requirements of the selector. The != and notin requirements also select the hosts that
+// do not have the label.
+func labelSelectorCondition(column string, selector hvs.LabelSelector) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for _, requirement := range selector {
+		switch requirement.Operator {
+		case hvs.LabelOperatorEquals:
+			conditions = append(conditions, fmt.Sprintf("%s ->> ? = ?", column))
+			args = append(args, requirement.Key, requirement.Values[0])
+		case hvs.LabelOperatorNotEquals:
+			conditions = append(conditions, fmt.Sprintf("(%s ->> ?) IS DISTINCT FROM ?", column))
+			args = append(args, requirement.Key, requirement.Values[0])
+		case hvs.LabelOperatorIn:
+			conditions = append(conditions, fmt.Sprintf("%s ->> ? IN (?)", column))
+			args = append(args, requirement.Key, requirement.Values)
+		case hvs.LabelOperatorNotIn:
+			conditions = append(conditions, fmt.Sprintf("(%s ->> ? IS NULL OR %s ->> ? NOT IN (?))", column, column))
+			args = append(args, requirement.Key, requirement.Key, requirement.Values)
+		}
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// whereHostLabels restricts the query to the hosts selected by the label selector, joining the host table as h on the
+// given condition
+func whereHostLabels(tx *gorm.DB, joinCondition string, selector hvs.LabelSelector) *gorm.DB {
+	if len(selector) == 0 {
+		return tx
+	}
+	condition, args := labelSelectorCondition("h.labels", selector)
+	return tx.Joins("INNER JOIN host h ON "+joinCondition).Where(condition, args...)
+}
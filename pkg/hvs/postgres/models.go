@@ -26,6 +26,8 @@ type (
 	PGHostStatusInformation hvs.HostStatusInformation
 	PGFlavorContent         hvs.Flavor
 	PGFlavorTemplateContent hvs.FlavorTemplate
+	PGEventTypes            []string
+	PGHostLabels            map[string]string
 
 	flavorGroup struct {
 		ID                    uuid.UUID             `json:"id" gorm:"primary_key;type:uuid"`
@@ -40,6 +42,9 @@ type (
 		Label      string          `gorm:"unique;not null"`
 		FlavorPart string          `json:"flavor_part"`
 		Signature  string          `json:"signature"`
+		State      string          `gorm:"type:varchar(16);not null;default:'active'"`
+		NotBefore  *time.Time
+		NotAfter   *time.Time
 	}
 
 	host struct {
@@ -48,6 +53,7 @@ type (
 		Description      string
 		ConnectionString string        `gorm:"not null"`
 		HardwareUuid     models.HwUUID `gorm:"type:uuid;index:idx_host_hardware_uuid"`
+		Labels           PGHostLabels  `sql:"type:JSONB NOT NULL DEFAULT '{}'::JSONB"`
 	}
 
 	hostFlavorgroup struct {
@@ -151,6 +157,37 @@ type (
 		CreatedAt  time.Time      `gorm:"column:created; not null"`
 		Action     string         `gorm:"type:varchar(50)"`
 		Data       PGAuditLogData `sql:"type:JSONB"`
+		Sequence   int64          `gorm:"column:sequence;not null;default:0;index"`
+		PrevHash   string         `gorm:"column:prev_hash;type:varchar(96)"`
+		Hash       string         `gorm:"column:hash;type:varchar(96)"`
+	}
+
+	// eventSubscription holds the webhooks to which the trust events are delivered
+	eventSubscription struct {
+		ID         uuid.UUID    `gorm:"primary_key;type:uuid"`
+		URL        string       `gorm:"column:url;not null"`
+		Secret     string       `gorm:"column:secret;not null"`
+		EventTypes PGEventTypes `gorm:"column:event_types" sql:"type:JSONB NOT NULL DEFAULT '[]'::JSONB"`
+		CreatedAt  time.Time    `gorm:"column:created;not null"`
+	}
+
+	hostImportJob struct {
+		ID        uuid.UUID `gorm:"primary_key;type:uuid"`
+		Status    string    `gorm:"type:varchar(16);not null;index:idx_host_import_job_status"`
+		CreatedAt time.Time `gorm:"column:created;not null"`
+		UpdatedAt time.Time `gorm:"column:updated;not null"`
+	}
+
+	hostImportJobRow struct {
+		JobID            uuid.UUID  `gorm:"type:uuid REFERENCES host_import_job(Id) ON UPDATE CASCADE ON DELETE CASCADE;primary_key"`
+		Row              int        `gorm:"primary_key;auto_increment:false"`
+		HostName         string     `gorm:"not null"`
+		ConnectionString string     `gorm:"not null"`
+		Entry            string     `gorm:"not null"`
+		Status           string     `gorm:"type:varchar(16);not null"`
+		HostID           *uuid.UUID `gorm:"type:uuid"`
+		Error            string
+		Attempts         int `gorm:"not null"`
 	}
 
 	tagCertificate struct {
@@ -264,3 +301,34 @@ func (fl *PGFlavorTemplateContent) Scan(value interface{}) error {
 	}
 	return json.Unmarshal(b, &fl)
 }
+
+func (et PGEventTypes) Value() (driver.Value, error) {
+	if et == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(et)
+}
+
+func (et *PGEventTypes) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("postgres/models:PGEventTypes_Scan() - type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, &et)
+}
+
+func (hl PGHostLabels) Value() (driver.Value, error) {
+	if hl == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(hl)
+}
+
+func (hl *PGHostLabels) Scan(value interface{}) error {
+	// no trace comments here as it is a high frequency function.
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("postgres/models:PGHostLabels_Scan() - type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, &hl)
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// pageColumns maps the sort fields supported by a search onto the columns holding them
+type pageColumns map[string]string
+
+// keysetCondition returns the SQL condition and arguments that select the records positioned after the
+// page cursor, comparing the (sort key, id) tuple so that records sharing a sort key are not skipped.
+// An empty condition is returned for the first page.
+func keysetCondition(page *models.PageCriteria, columns pageColumns, idColumn string) (string, []interface{}, error) {
+	sortColumn, ok := columns[page.SortBy]
+	if !ok {
+		return "", nil, errors.Errorf("unsupported sort field %s", page.SortBy)
+	}
+	if page.After == nil {
+		return "", nil, nil
+	}
+
+	var sortValue interface{} = page.After.Value
+	switch page.SortBy {
+	case models.SortByID:
+		sortValue = page.After.ID
+		// the cursors of the audit log entries carry the record ID, their ID is the one of the entry
+		if page.After.Value != "" {
+			id, err := uuid.Parse(page.After.Value)
+			if err != nil {
+				return "", nil, errors.Wrap(err, "invalid page cursor record ID")
+			}
+			sortValue = id
+		}
+	case models.SortByCreated:
+		created, err := time.Parse(time.RFC3339Nano, page.After.Value)
+		if err != nil {
+			return "", nil, errors.Wrap(err, "invalid page cursor creation time")
+		}
+		sortValue = created
+	}
+
+	operator := ">"
+	if page.OrderBy == models.Descending {
+		operator = "<"
+	}
+	return fmt.Sprintf("(%s, %s) %s (?, ?)", sortColumn, idColumn, operator), []interface{}{sortValue, page.After.ID}, nil
+}
+
+// keysetOrder returns the ORDER BY expression matching keysetCondition
+func keysetOrder(page *models.PageCriteria, columns pageColumns, idColumn string) string {
+	direction := "asc"
+	if page.OrderBy == models.Descending {
+		direction = "desc"
+	}
+	return fmt.Sprintf("%s %s, %s %s", columns[page.SortBy], direction, idColumn, direction)
+}
+
+// applyPageCriteria restricts the query to the records of the requested page and orders them on the sort field.
+// Any ordering set previously on the query is replaced.
+func applyPageCriteria(tx *gorm.DB, page *models.PageCriteria, columns pageColumns, idColumn string) (*gorm.DB, error) {
+	condition, args, err := keysetCondition(page, columns, idColumn)
+	if err != nil {
+		return nil, err
+	}
+	if condition != "" {
+		tx = tx.Where(condition, args...)
+	}
+	return tx.Order(keysetOrder(page, columns, idColumn), true).Limit(page.Limit), nil
+}
+
+// auditPageCursor returns the cursor of an audit log entry of a page, the entries recording the same record are
+// told apart by their own ID
+func auditPageCursor(page *models.PageCriteria, entry *models.AuditLogEntry, created time.Time) *models.PageCursor {
+	cursor := &models.PageCursor{SortBy: page.SortBy, ID: entry.ID, Value: entry.EntityID.String()}
+	if page.SortBy == models.SortByCreated {
+		cursor.Value = models.PageCursorTime(created)
+	}
+	return cursor
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 )
@@ -24,9 +25,18 @@ import (
 type ReportStore struct {
 	Store          *DataStore
 	AuditLogWriter domain.AuditLogWriter
+	EventPublisher domain.EventPublisher
 	dbLock         sync.Mutex
 }
 
+var (
+	latestReportPageColumns = pageColumns{models.SortByCreated: "report.created", models.SortByID: "report.id"}
+	// audit log entries are ordered on the creation time recorded in the report itself, the entries of a report
+	// share its entity ID so the entry ID is the tie-breaker
+	auditReportPageColumns = pageColumns{models.SortByCreated: "CAST(au.data -> 'Columns' -> 3 ->> 'Value' AS TIMESTAMPTZ)",
+		models.SortByID: "au.entity_id"}
+)
+
 func NewReportStore(store *DataStore) *ReportStore {
 	return &ReportStore{Store: store}
 }
@@ -71,7 +81,9 @@ func (r *ReportStore) Update(re *models.HVSReport) (*models.HVSReport, error) {
 	}
 
 	// length of hvsReports will always be 1 for a given host ID
+	var previousTrusted *bool
 	if len(hvsReports) == 1 {
+		previousTrusted = &hvsReports[0].TrustReport.Trusted
 		err := r.Delete(hvsReports[0].ID)
 		if err != nil {
 			return nil, errors.Wrap(err, "postgres/report_store:Update() Error while deleting report")
@@ -82,6 +94,19 @@ func (r *ReportStore) Update(re *models.HVSReport) (*models.HVSReport, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "postgres/report_store:Update() Error while creating report")
 	}
+
+	// notify the trust change, including the first report of a host
+	if r.EventPublisher != nil && (previousTrusted == nil || *previousTrusted != vsReport.TrustReport.Trusted) {
+		trusted := vsReport.TrustReport.Trusted
+		reportID := vsReport.ID
+		r.EventPublisher.Publish(&hvs.TrustEvent{
+			Type:            hvs.TrustEventHostTrustChanged,
+			HostID:          vsReport.HostID,
+			ReportID:        &reportID,
+			Trusted:         &trusted,
+			PreviousTrusted: previousTrusted,
+		})
+	}
 	return vsReport, nil
 }
 
@@ -164,13 +189,21 @@ func (r *ReportStore) Search(criteria *models.ReportFilterCriteria) ([]models.HV
 
 	var tx *gorm.DB
 	if fromDate.IsZero() && toDate.IsZero() && criteria.LatestPerHost {
-		tx = buildLatestReportSearchQuery(r.Store.Db, reportID, hostID, hostHardwareUUID, hostName, hostStatus, criteria.Limit)
+		tx = buildLatestReportSearchQuery(r.Store.Db, reportID, hostID, hostHardwareUUID, hostName, hostStatus, criteria.LabelSelector, criteria.Limit)
 
 		if tx == nil {
 			return nil, errors.New("postgres/report_store:Search() Unexpected Error. Could not build" +
 				" a gorm query object in HVSReport Search function.")
 		}
 
+		if criteria.Page != nil {
+			var err error
+			tx, err = applyPageCriteria(tx, criteria.Page, latestReportPageColumns, "report.id")
+			if err != nil {
+				return nil, errors.Wrap(err, "postgres/report_store:Search() failed to apply page criteria")
+			}
+		}
+
 		rows, err := tx.Rows()
 		if err != nil {
 			return nil, errors.Wrap(err, "postgres/report_store:Search() failed to retrieve records from db")
@@ -195,12 +228,20 @@ func (r *ReportStore) Search(criteria *models.ReportFilterCriteria) ([]models.HV
 
 		return reports, nil
 	} else {
-		tx = buildReportSearchQuery(r.Store.Db, hostID, hostHardwareUUID, hostName, hostStatus, fromDate, toDate, latestPerHost, criteria.Limit)
+		tx = buildReportSearchQuery(r.Store.Db, hostID, hostHardwareUUID, hostName, hostStatus, criteria.LabelSelector, fromDate, toDate, latestPerHost, criteria.Limit)
 		if tx == nil {
 			return nil, errors.New("postgres/report_store:Search() Unexpected Error. Could not build" +
 				" a gorm query object in HVSReport Search function.")
 		}
 
+		if criteria.Page != nil {
+			var err error
+			tx, err = applyPageCriteria(tx, criteria.Page, auditReportPageColumns, "au.id")
+			if err != nil {
+				return nil, errors.Wrap(err, "postgres/report_store:Search() failed to apply page criteria")
+			}
+		}
+
 		rows, err := tx.Rows()
 		if err != nil {
 			return nil, errors.Wrap(err, "postgres/report_store:Search() failed to retrieve records from db")
@@ -226,6 +267,9 @@ func (r *ReportStore) Search(criteria *models.ReportFilterCriteria) ([]models.HV
 				return nil, errors.Wrap(err, "postgres/report_store:Search() convert auditloag entry into report")
 			}
 			reports = append(reports, *hvsReport)
+			if criteria.Page != nil {
+				criteria.Page.Last = auditPageCursor(criteria.Page, &result, hvsReport.CreatedAt)
+			}
 		}
 
 		return reports, nil
@@ -268,6 +312,170 @@ func (r *ReportStore) FindHostIdsFromExpiredReports(fromTime time.Time, toTime t
 	return hostIDs, nil
 }
 
+// Summarize counts the hosts selected by the criteria by trust status, along with the breakdowns the criteria request
+func (r *ReportStore) Summarize(criteria *models.ReportSummaryCriteria) (*hvs.ReportSummary, error) {
+	defaultLog.Trace("postgres/report_store:Summarize() Entering")
+	defer defaultLog.Trace("postgres/report_store:Summarize() Leaving")
+
+	hosts := r.Store.Db.Table("host h").
+		Select("COALESCE(hs.status ->> 'host_state', '') IN (?) AS failed, r.trusted", connectionFailureStates()).
+		Joins("LEFT JOIN host_status hs ON hs.host_id = h.id").
+		Joins("LEFT JOIN report r ON r.host_id = h.id")
+	if len(criteria.LabelSelector) > 0 {
+		condition, args := labelSelectorCondition("h.labels", criteria.LabelSelector)
+		hosts = hosts.Where(condition, args...)
+	}
+
+	summary := hvs.ReportSummary{}
+	row := r.Store.Db.Raw("SELECT COUNT(*), "+
+		"COUNT(*) FILTER (WHERE NOT s.failed AND s.trusted), "+
+		"COUNT(*) FILTER (WHERE NOT s.failed AND NOT s.trusted), "+
+		"COUNT(*) FILTER (WHERE NOT s.failed AND s.trusted IS NULL), "+
+		"COUNT(*) FILTER (WHERE s.failed) FROM ? s", hosts.SubQuery()).Row()
+	if err := row.Scan(&summary.Total, &summary.Trusted, &summary.Untrusted, &summary.Unknown, &summary.ConnectionFailure); err != nil {
+		return nil, errors.Wrap(err, "postgres/report_store:Summarize() failed to scan trust counts")
+	}
+
+	var err error
+	if criteria.ByFlavorPart {
+		summary.FlavorParts, err = r.countByFlavorPart(criteria.LabelSelector)
+		if err != nil {
+			return nil, errors.Wrap(err, "postgres/report_store:Summarize() failed to count hosts by flavor part")
+		}
+	}
+	if criteria.ByRule {
+		summary.FailingRules, err = r.countFailingRules(criteria.LabelSelector)
+		if err != nil {
+			return nil, errors.Wrap(err, "postgres/report_store:Summarize() failed to count hosts by failing rule")
+		}
+	}
+	if !criteria.FromDate.IsZero() {
+		summary.TimeBuckets, err = r.countByTimeBucket(criteria)
+		if err != nil {
+			return nil, errors.Wrap(err, "postgres/report_store:Summarize() failed to count hosts by time bucket")
+		}
+	}
+	return &summary, nil
+}
+
+// countByFlavorPart counts the hosts trusted and untrusted for each flavor part of their latest report, a flavor part
+// being trusted when none of the rules marked with it has faults
+func (r *ReportStore) countByFlavorPart(selector hvs.LabelSelector) ([]hvs.FlavorPartTrustCount, error) {
+	defaultLog.Trace("postgres/report_store:countByFlavorPart() Entering")
+	defer defaultLog.Trace("postgres/report_store:countByFlavorPart() Leaving")
+
+	parts := r.Store.Db.Table("report r").
+		Select("m.marker AS flavor_part, bool_and(COALESCE(jsonb_array_length(res -> 'faults'), 0) = 0) AS trusted").
+		Joins("CROSS JOIN LATERAL jsonb_array_elements(" + jsonbArray("r.trust_report -> 'results'") + ") res").
+		Joins("CROSS JOIN LATERAL jsonb_array_elements_text(res -> 'rule' -> 'markers') m(marker)")
+	parts = whereHostLabels(parts, "h.id = r.host_id", selector).Group("r.id, m.marker")
+
+	rows, err := r.Store.Db.Raw("SELECT p.flavor_part, COUNT(*) FILTER (WHERE p.trusted), COUNT(*) FILTER (WHERE NOT p.trusted) "+
+		"FROM ? p GROUP BY p.flavor_part ORDER BY p.flavor_part", parts.SubQuery()).Rows()
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/report_store:countByFlavorPart() failed to retrieve records from db")
+	}
+	defer func() {
+		derr := rows.Close()
+		if derr != nil {
+			defaultLog.WithError(derr).Error("Error closing rows")
+		}
+	}()
+
+	var counts []hvs.FlavorPartTrustCount
+	for rows.Next() {
+		count := hvs.FlavorPartTrustCount{}
+		if err := rows.Scan(&count.FlavorPart, &count.Trusted, &count.Untrusted); err != nil {
+			return nil, errors.Wrap(err, "postgres/report_store:countByFlavorPart() failed to scan record")
+		}
+		counts = append(counts, count)
+	}
+	return counts, nil
+}
+
+// countFailingRules counts for each rule the hosts whose latest report has faults for it
+func (r *ReportStore) countFailingRules(selector hvs.LabelSelector) ([]hvs.RuleFailureCount, error) {
+	defaultLog.Trace("postgres/report_store:countFailingRules() Entering")
+	defer defaultLog.Trace("postgres/report_store:countFailingRules() Leaving")
+
+	tx := r.Store.Db.Table("report r").
+		Select("COALESCE(res -> 'rule' ->> 'rule_name', '') AS rule_name, COUNT(DISTINCT r.host_id) AS hosts").
+		Joins("CROSS JOIN LATERAL jsonb_array_elements(" + jsonbArray("r.trust_report -> 'results'") + ") res").
+		Where("COALESCE(jsonb_array_length(res -> 'faults'), 0) > 0")
+	tx = whereHostLabels(tx, "h.id = r.host_id", selector)
+	rows, err := tx.Group("rule_name").Order("hosts DESC, rule_name").Rows()
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/report_store:countFailingRules() failed to retrieve records from db")
+	}
+	defer func() {
+		derr := rows.Close()
+		if derr != nil {
+			defaultLog.WithError(derr).Error("Error closing rows")
+		}
+	}()
+
+	var counts []hvs.RuleFailureCount
+	for rows.Next() {
+		count := hvs.RuleFailureCount{}
+		if err := rows.Scan(&count.RuleName, &count.Hosts); err != nil {
+			return nil, errors.Wrap(err, "postgres/report_store:countFailingRules() failed to scan record")
+		}
+		counts = append(counts, count)
+	}
+	return counts, nil
+}
+
+// countByTimeBucket counts by time bucket the hosts that got trusted and untrusted reports over the date range of the
+// criteria, from the report records of the audit log
+func (r *ReportStore) countByTimeBucket(criteria *models.ReportSummaryCriteria) ([]hvs.TrustTimeBucket, error) {
+	defaultLog.Trace("postgres/report_store:countByTimeBucket() Entering")
+	defer defaultLog.Trace("postgres/report_store:countByTimeBucket() Leaving")
+
+	tx := r.Store.Db.Table("audit_log_entry au").
+		Select("date_trunc(?, au.created) AS bucket, "+
+			"COUNT(DISTINCT au.data -> 'Columns' -> 1 ->> 'Value') FILTER (WHERE au.data -> 'Columns' -> 2 -> 'Value' ->> 'trusted' = 'true'), "+
+			"COUNT(DISTINCT au.data -> 'Columns' -> 1 ->> 'Value') FILTER (WHERE au.data -> 'Columns' -> 2 -> 'Value' ->> 'trusted' = 'false')",
+			criteria.BucketSize).
+		Where("au.entity_type = 'report' AND au.action = 'create'")
+	tx = buildAuditSummaryQuery(tx, criteria)
+	rows, err := tx.Group("bucket").Order("bucket").Rows()
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/report_store:countByTimeBucket() failed to retrieve records from db")
+	}
+	defer func() {
+		derr := rows.Close()
+		if derr != nil {
+			defaultLog.WithError(derr).Error("Error closing rows")
+		}
+	}()
+
+	var buckets []hvs.TrustTimeBucket
+	for rows.Next() {
+		bucket := hvs.TrustTimeBucket{}
+		if err := rows.Scan(&bucket.Start, &bucket.Trusted, &bucket.Untrusted); err != nil {
+			return nil, errors.Wrap(err, "postgres/report_store:countByTimeBucket() failed to scan record")
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, nil
+}
+
+// buildAuditSummaryQuery restricts a query on the audit log entries of hosts to the date range and the hosts of a
+// report summary
+func buildAuditSummaryQuery(tx *gorm.DB, criteria *models.ReportSummaryCriteria) *gorm.DB {
+	tx = whereHostLabels(tx, "CAST(h.id AS VARCHAR) = au.data -> 'Columns' -> 1 ->> 'Value'", criteria.LabelSelector)
+	tx = tx.Where("CAST(au.created AS TIMESTAMP) >= CAST(? AS TIMESTAMP)", criteria.FromDate)
+	if !criteria.ToDate.IsZero() {
+		tx = tx.Where("CAST(au.created AS TIMESTAMP) < CAST(? AS TIMESTAMP)", criteria.ToDate)
+	}
+	return tx
+}
+
+// jsonbArray guards a JSONB expression expanded with jsonb_array_elements against null and scalar values
+func jsonbArray(expression string) string {
+	return fmt.Sprintf("CASE WHEN jsonb_typeof(%[1]s) = 'array' THEN %[1]s ELSE '[]'::JSONB END", expression)
+}
+
 func auditlogEntryToReport(auRecord models.AuditLogEntry) (*models.HVSReport, error) {
 	defaultLog.Trace("postgres/report_store:auditlogEntryToReport() Entering")
 	defer defaultLog.Trace("postgres/report_store:auditlogEntryToReport() Leaving")
@@ -343,7 +551,7 @@ func (r *ReportStore) Delete(reportId uuid.UUID) error {
 }
 
 // buildReportSearchQuery is a helper function to build the query object for a report search.
-func buildReportSearchQuery(tx *gorm.DB, hostHardwareID, hostID uuid.UUID, hostName, hostState string, fromDate, toDate time.Time, latestPerHost bool, limit int) *gorm.DB {
+func buildReportSearchQuery(tx *gorm.DB, hostHardwareID, hostID uuid.UUID, hostName, hostState string, labelSelector hvs.LabelSelector, fromDate, toDate time.Time, latestPerHost bool, limit int) *gorm.DB {
 	defaultLog.Trace("postgres/report_store:buildReportSearchQuery() Entering")
 	defer defaultLog.Trace("postgres/report_store:buildReportSearchQuery() Leaving")
 	if tx == nil {
@@ -352,20 +560,20 @@ func buildReportSearchQuery(tx *gorm.DB, hostHardwareID, hostID uuid.UUID, hostN
 	if latestPerHost {
 		entity := "auj"
 		txSubQuery := tx.Table("audit_log_entry auj").Select("data -> 'Columns' -> 1 ->> 'Value' AS host_id, max(auj.created) AS max_date ")
-		txSubQuery = buildReportSearchQueryWithCriteria(txSubQuery, hostHardwareID, hostID, entity, hostName, hostState, fromDate, toDate)
+		txSubQuery = buildReportSearchQueryWithCriteria(txSubQuery, hostHardwareID, hostID, entity, hostName, hostState, labelSelector, fromDate, toDate)
 		txSubQuery = txSubQuery.Group("host_id")
 		subQuery := txSubQuery.SubQuery()
-		tx = tx.Table("audit_log_entry au").Select("au.*").Joins("INNER JOIN ? a ON a.host_id = au.data -> 'Columns' -> 1 ->> 'Value' AND a.max_date = au.created", subQuery)
+		tx = tx.Table("audit_log_entry au").Select(auditLogEntryColumns).Joins("INNER JOIN ? a ON a.host_id = au.data -> 'Columns' -> 1 ->> 'Value' AND a.max_date = au.created", subQuery)
 	} else {
 		entity := "au"
-		tx = tx.Table("audit_log_entry au").Select("au.*")
-		tx = buildReportSearchQueryWithCriteria(tx, hostHardwareID, hostID, entity, hostName, hostState, fromDate, toDate)
+		tx = tx.Table("audit_log_entry au").Select(auditLogEntryColumns)
+		tx = buildReportSearchQueryWithCriteria(tx, hostHardwareID, hostID, entity, hostName, hostState, labelSelector, fromDate, toDate)
 	}
 	tx = tx.Limit(limit)
 	return tx
 }
 
-func buildReportSearchQueryWithCriteria(tx *gorm.DB, hostHardwareID, hostID uuid.UUID, entity, hostName string, hostState string, fromDate, toDate time.Time) *gorm.DB {
+func buildReportSearchQueryWithCriteria(tx *gorm.DB, hostHardwareID, hostID uuid.UUID, entity, hostName string, hostState string, labelSelector hvs.LabelSelector, fromDate, toDate time.Time) *gorm.DB {
 	defaultLog.Trace("postgres/report_store:buildReportSearchQueryWithCriteria() Entering")
 	defer defaultLog.Trace("postgres/report_store:buildReportSearchQueryWithCriteria() Leaving")
 
@@ -373,7 +581,7 @@ func buildReportSearchQueryWithCriteria(tx *gorm.DB, hostHardwareID, hostID uuid
 		tx = tx.Joins("INNER JOIN host_status hs on CAST(hs.host_id AS VARCHAR) = " + entity + ".data -> 'Columns' -> 1 ->> 'Value'")
 	}
 
-	if hostName != "" || hostHardwareID != uuid.Nil {
+	if hostName != "" || hostHardwareID != uuid.Nil || len(labelSelector) > 0 {
 		tx = tx.Joins("INNER JOIN host h on CAST(h.id AS VARCHAR) = " + entity + ".data -> 'Columns' -> 1 ->> 'Value'")
 	}
 
@@ -388,6 +596,11 @@ func buildReportSearchQueryWithCriteria(tx *gorm.DB, hostHardwareID, hostID uuid
 		tx = tx.Where("h.hardware_uuid = ?", hostHardwareID.String())
 	}
 
+	if len(labelSelector) > 0 {
+		condition, args := labelSelectorCondition("h.labels", labelSelector)
+		tx = tx.Where(condition, args...)
+	}
+
 	if hostID != uuid.Nil {
 		tx = tx.Where(entity+".data -> 'Columns' -> 1 ->> 'Value' = ?", hostID.String())
 	}
@@ -408,7 +621,7 @@ func buildReportSearchQueryWithCriteria(tx *gorm.DB, hostHardwareID, hostID uuid
 }
 
 // buildLatestReportSearchQuery is a helper function to build the query object for a latest report search.
-func buildLatestReportSearchQuery(tx *gorm.DB, reportID, hostID, hostHardwareID uuid.UUID, hostName, hostState string, limit int) *gorm.DB {
+func buildLatestReportSearchQuery(tx *gorm.DB, reportID, hostID, hostHardwareID uuid.UUID, hostName, hostState string, labelSelector hvs.LabelSelector, limit int) *gorm.DB {
 	defaultLog.Trace("postgres/report_store:buildLatestReportSearchQuery() Entering")
 	defer defaultLog.Trace("postgres/report_store:buildLatestReportSearchQuery() Leaving")
 
@@ -423,7 +636,7 @@ func buildLatestReportSearchQuery(tx *gorm.DB, reportID, hostID, hostHardwareID
 		return tx
 	}
 
-	if hostID != uuid.Nil || hostName != "" || hostHardwareID != uuid.Nil {
+	if hostID != uuid.Nil || hostName != "" || hostHardwareID != uuid.Nil || len(labelSelector) > 0 {
 		tx = tx.Joins("INNER JOIN host h on h.id = host_id")
 	}
 
@@ -440,6 +653,11 @@ func buildLatestReportSearchQuery(tx *gorm.DB, reportID, hostID, hostHardwareID
 		tx = tx.Where("h.hardware_uuid = ?", hostHardwareID.String())
 	}
 
+	if len(labelSelector) > 0 {
+		condition, args := labelSelectorCondition("h.labels", labelSelector)
+		tx = tx.Where(condition, args...)
+	}
+
 	if hostID != uuid.Nil {
 		tx = tx.Where("host_id = ?", hostID.String())
 	}
@@ -0,0 +1,36 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package router
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/postgres"
+)
+
+// SetAuditLogRoutes registers the read-only routes for the audit log
+func SetAuditLogRoutes(router *mux.Router, store *postgres.DataStore, certStore *models.CertificatesStore) *mux.Router {
+	defaultLog.Trace("router/audit_logs:SetAuditLogRoutes() Entering")
+	defer defaultLog.Trace("router/audit_logs:SetAuditLogRoutes() Leaving")
+
+	auditLogEntryStore := postgres.NewAuditLogEntryStore(store)
+	auditLogController := controllers.NewAuditLogController(auditLogEntryStore, certStore)
+
+	router.Handle("/audit-logs",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(auditLogController.Search),
+			[]string{constants.AuditLogSearch}))).Methods("GET")
+
+	router.Handle("/audit-logs/verify",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(auditLogController.Verify),
+			[]string{constants.AuditLogVerify}))).Methods("GET")
+
+	router.Handle("/audit-logs/export",
+		ErrorHandler(permissionsHandler(auditLogController.Export,
+			[]string{constants.AuditLogExport}))).Methods("GET")
+
+	return router
+}
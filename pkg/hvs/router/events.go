@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package router
+
+import (
+	"fmt"
+
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/postgres"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
+)
+
+// SetEventRoutes registers routes for event-subscriptions and the event stream
+func SetEventRoutes(router *mux.Router, store *postgres.DataStore, dek []byte, eventPublisher domain.EventPublisher) *mux.Router {
+	defaultLog.Trace("router/events:SetEventRoutes() Entering")
+	defer defaultLog.Trace("router/events:SetEventRoutes() Leaving")
+
+	eventSubscriptionStore := postgres.NewEventSubscriptionStore(store, dek)
+	eventController := controllers.NewEventController(eventSubscriptionStore, eventPublisher)
+	eventSubscriptionIdExpr := fmt.Sprintf("%s%s", "/event-subscriptions/", validation.IdReg)
+
+	router.Handle("/event-subscriptions",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(eventController.CreateSubscription),
+			[]string{constants.EventSubscriptionCreate}))).Methods("POST")
+
+	router.Handle(eventSubscriptionIdExpr,
+		ErrorHandler(permissionsHandler(JsonResponseHandler(eventController.UpdateSubscription),
+			[]string{constants.EventSubscriptionStore}))).Methods("PUT")
+
+	router.Handle("/event-subscriptions",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(eventController.SearchSubscriptions),
+			[]string{constants.EventSubscriptionSearch}))).Methods("GET")
+
+	router.Handle(eventSubscriptionIdExpr,
+		ErrorHandler(permissionsHandler(ResponseHandler(eventController.DeleteSubscription),
+			[]string{constants.EventSubscriptionDelete}))).Methods("DELETE")
+
+	router.Handle(eventSubscriptionIdExpr,
+		ErrorHandler(permissionsHandler(JsonResponseHandler(eventController.RetrieveSubscription),
+			[]string{constants.EventSubscriptionRetrieve}))).Methods("GET")
+
+	router.Handle("/events/stream",
+		ErrorHandler(permissionsHandler(eventController.Stream,
+			[]string{constants.EventStream}))).Methods("GET")
+
+	return router
+}
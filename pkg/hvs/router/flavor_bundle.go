@@ -0,0 +1,39 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package router
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/postgres"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/verifier"
+)
+
+// SetFlavorBundleRoutes registers the routes exporting and importing signed flavor bundles
+func SetFlavorBundleRoutes(router *mux.Router, store *postgres.DataStore, flavorGroupStore *postgres.FlavorGroupStore, certStore *models.CertificatesStore,
+	hostTrustManager domain.HostTrustManager, hcConfig domain.HostControllerConfig, flavorVerifier verifier.Verifier) *mux.Router {
+	defaultLog.Trace("router/flavor_bundle:SetFlavorBundleRoutes() Entering")
+	defer defaultLog.Trace("router/flavor_bundle:SetFlavorBundleRoutes() Leaving")
+
+	flavorStore := postgres.NewFlavorStore(store)
+	hostStore := postgres.NewHostStore(store)
+	tagCertStore := postgres.NewTagCertificateStore(store)
+	flavorTemplateStore := postgres.NewFlavorTemplateStore(store)
+	flavorController := controllers.NewFlavorController(flavorStore, flavorGroupStore, hostStore, tagCertStore, hostTrustManager, certStore, hcConfig, flavorTemplateStore)
+	flavorBundleController := controllers.NewFlavorBundleController(*flavorController, flavorVerifier.GetVerifierCerts().FlavorCACertificates)
+
+	router.Handle("/flavors/export",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(flavorBundleController.Export),
+			[]string{constants.FlavorExport}))).Methods("GET")
+
+	router.Handle("/flavors/import",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(flavorBundleController.Import),
+			[]string{constants.FlavorImport}))).Methods("POST")
+
+	return router
+}
@@ -0,0 +1,34 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package router
+
+import (
+	"fmt"
+
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/postgres"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
+)
+
+// SetFlavorDiffRoutes registers the route comparing a flavor with another flavor or a host manifest
+func SetFlavorDiffRoutes(router *mux.Router, store *postgres.DataStore) *mux.Router {
+	defaultLog.Trace("router/flavor_diff:SetFlavorDiffRoutes() Entering")
+	defer defaultLog.Trace("router/flavor_diff:SetFlavorDiffRoutes() Leaving")
+
+	flavorStore := postgres.NewFlavorStore(store)
+	hostStore := postgres.NewHostStore(store)
+	hostStatusStore := postgres.NewHostStatusStore(store)
+	flavorDiffController := controllers.NewFlavorDiffController(flavorStore, hostStore, hostStatusStore)
+
+	flavorIdExpr := fmt.Sprintf("%s%s", "/flavors/", validation.IdReg)
+
+	router.Handle(flavorIdExpr+"/diff",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(flavorDiffController.Diff),
+			[]string{constants.FlavorDiff}))).Methods("GET")
+
+	return router
+}
@@ -0,0 +1,38 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package router
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/postgres"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/services/hosttrust"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/verifier"
+)
+
+// SetFlavorDryRunRoutes registers the route verifying candidate flavors against the stored host manifests
+func SetFlavorDryRunRoutes(router *mux.Router, store *postgres.DataStore, flavorGroupStore *postgres.FlavorGroupStore, flavorVerifier verifier.Verifier, skipFlavorSignatureVerification bool) *mux.Router {
+	defaultLog.Trace("router/flavor_dry_run:SetFlavorDryRunRoutes() Entering")
+	defer defaultLog.Trace("router/flavor_dry_run:SetFlavorDryRunRoutes() Leaving")
+
+	flavorStore := postgres.NewFlavorStore(store)
+	hostStore := postgres.NewHostStore(store)
+	hostStatusStore := postgres.NewHostStatusStore(store)
+	dryRunVerifier := &hosttrust.DryRunVerifier{
+		FlavorStore:                     flavorStore,
+		FlavorGroupStore:                flavorGroupStore,
+		HostStore:                       hostStore,
+		FlavorVerifier:                  flavorVerifier,
+		SkipFlavorSignatureVerification: skipFlavorSignatureVerification,
+	}
+	flavorDryRunController := controllers.NewFlavorDryRunController(flavorGroupStore, hostStore, hostStatusStore, dryRunVerifier)
+
+	router.Handle("/flavors/dry-run",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(flavorDryRunController.DryRun),
+			[]string{constants.FlavorDryRun}))).Methods("POST")
+
+	return router
+}
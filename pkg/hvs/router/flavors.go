@@ -17,12 +17,13 @@ import (
 )
 
 // SetFlavorRoutes registers routes for flavors
-func SetFlavorRoutes(router *mux.Router, store *postgres.DataStore, flavorGroupStore *postgres.FlavorGroupStore, certStore *models.CertificatesStore, hostTrustManager domain.HostTrustManager, flavorControllerConfig domain.HostControllerConfig) *mux.Router {
+func SetFlavorRoutes(router *mux.Router, store *postgres.DataStore, flavorGroupStore *postgres.FlavorGroupStore, certStore *models.CertificatesStore, hostTrustManager domain.HostTrustManager, flavorControllerConfig domain.HostControllerConfig, auditLogWriter domain.AuditLogWriter) *mux.Router {
 	defaultLog.Trace("router/flavors:SetFlavorRoutes() Entering")
 	defer defaultLog.Trace("router/flavors:SetFlavorRoutes() Leaving")
 
 	hostStore := postgres.NewHostStore(store)
 	flavorStore := postgres.NewFlavorStore(store)
+	flavorStore.AuditLogWriter = auditLogWriter
 	tagCertStore := postgres.NewTagCertificateStore(store)
 	flavorTemplateStore := postgres.NewFlavorTemplateStore(store)
 	flavorController := controllers.NewFlavorController(flavorStore, flavorGroupStore, hostStore, tagCertStore, hostTrustManager, certStore, flavorControllerConfig, flavorTemplateStore)
@@ -46,5 +47,13 @@ func SetFlavorRoutes(router *mux.Router, store *postgres.DataStore, flavorGroupS
 		ErrorHandler(permissionsHandler(JsonResponseHandler(flavorController.Retrieve),
 			[]string{constants.FlavorRetrieve}))).Methods("GET")
 
+	router.Handle(flavorIdExpr+"/lifecycle",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(flavorController.RetrieveLifecycle),
+			[]string{constants.FlavorRetrieve}))).Methods("GET")
+
+	router.Handle(flavorIdExpr+"/lifecycle",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(flavorController.UpdateLifecycle),
+			[]string{constants.FlavorLifecycleUpdate}))).Methods("PUT")
+
 	return router
 }
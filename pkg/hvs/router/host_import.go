@@ -0,0 +1,37 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package router
+
+import (
+	"fmt"
+
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/postgres"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
+)
+
+// SetHostImportRoutes registers the routes for the bulk host import jobs
+func SetHostImportRoutes(router *mux.Router, store *postgres.DataStore, dek []byte, hostImporter domain.HostImporter) *mux.Router {
+	defaultLog.Trace("router/host_import:SetHostImportRoutes() Entering")
+	defer defaultLog.Trace("router/host_import:SetHostImportRoutes() Leaving")
+
+	hostImportJobStore := postgres.NewHostImportJobStore(store, dek)
+	hostImportController := controllers.NewHostImportController(hostImporter, hostImportJobStore)
+
+	hostImportExpr := "/hosts/bulk"
+	hostImportIdExpr := fmt.Sprintf("%s/%s", hostImportExpr, validation.IdReg)
+
+	router.Handle(hostImportExpr, ErrorHandler(permissionsHandler(JsonResponseHandler(hostImportController.Create),
+		[]string{constants.HostCreate}))).Methods("POST")
+	router.Handle(hostImportIdExpr, ErrorHandler(permissionsHandler(JsonResponseHandler(hostImportController.Retrieve),
+		[]string{constants.HostRetrieve}))).Methods("GET")
+	router.Handle(hostImportIdExpr+"/retry", ErrorHandler(permissionsHandler(JsonResponseHandler(hostImportController.Retry),
+		[]string{constants.HostCreate}))).Methods("POST")
+
+	return router
+}
@@ -45,6 +45,8 @@ func SetHostRoutes(router *mux.Router, store *postgres.DataStore, hostTrustManag
 		[]string{constants.HostDelete}))).Methods("DELETE")
 	router.Handle(hostExpr, ErrorHandler(permissionsHandler(JsonResponseHandler(hostController.Search),
 		[]string{constants.HostSearch}))).Methods("GET")
+	router.Handle(hostExpr+"/trust-summary", ErrorHandler(permissionsHandler(JsonResponseHandler(hostController.TrustSummary),
+		[]string{constants.HostSearch}))).Methods("GET")
 
 	router.Handle(flavorgroupExpr, ErrorHandler(permissionsHandler(JsonResponseHandler(hostController.AddFlavorgroup),
 		[]string{constants.HostCreate}))).Methods("POST")
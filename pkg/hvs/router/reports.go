@@ -44,6 +44,10 @@ func SetReportRoutes(router *mux.Router, store *postgres.DataStore, hostTrustMan
 		ErrorHandler(permissionsHandler(JsonResponseHandler(reportController.Retrieve),
 			[]string{constants.ReportRetrieve}))).Methods("GET")
 
+	router.Handle("/reports/summary",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(reportController.Summary),
+			[]string{constants.ReportSearch}))).Methods("GET")
+
 	router.Handle("/reports",
 		ErrorHandler(permissionsHandler(JsonResponseHandler(reportController.Search),
 			[]string{constants.ReportSearch}))).Methods("GET")
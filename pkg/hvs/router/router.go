@@ -15,6 +15,8 @@ import (
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
 
 	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/clients"
+	"github.com/intel-secl/intel-secl/v4/pkg/clients/aas"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/config"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
@@ -23,6 +25,8 @@ import (
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
 	cmw "github.com/intel-secl/intel-secl/v4/pkg/lib/common/middleware"
 	cos "github.com/intel-secl/intel-secl/v4/pkg/lib/common/os"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/verifier"
+	aasModel "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
 	"github.com/pkg/errors"
 )
 
@@ -34,7 +38,7 @@ type Router struct {
 }
 
 // InitRoutes registers all routes for the application.
-func InitRoutes(cfg *config.Configuration, dataStore *postgres.DataStore, fgs *postgres.FlavorGroupStore, certStore *models.CertificatesStore, hostTrustManager domain.HostTrustManager, hostControllerConfig domain.HostControllerConfig) (*mux.Router, error) {
+func InitRoutes(cfg *config.Configuration, dataStore *postgres.DataStore, fgs *postgres.FlavorGroupStore, certStore *models.CertificatesStore, hostTrustManager domain.HostTrustManager, hostControllerConfig domain.HostControllerConfig, eventPublisher domain.EventPublisher, flavorVerifier verifier.Verifier, auditLogWriter domain.AuditLogWriter, hostImporter domain.HostImporter) (*mux.Router, error) {
 	defaultLog.Trace("router/router:InitRoutes() Entering")
 	defer defaultLog.Trace("router/router:InitRoutes() Leaving")
 
@@ -44,18 +48,18 @@ func InitRoutes(cfg *config.Configuration, dataStore *postgres.DataStore, fgs *p
 	// ISECL-8715 - Prevent potential open redirects to external URLs
 	router.SkipClean(true)
 
-	err := defineSubRoutes(router, constants.OldServiceName, cfg, dataStore, fgs, certStore, hostTrustManager, hostControllerConfig)
+	err := defineSubRoutes(router, constants.OldServiceName, cfg, dataStore, fgs, certStore, hostTrustManager, hostControllerConfig, eventPublisher, flavorVerifier, auditLogWriter, hostImporter)
 	if err != nil {
 		return nil, errors.Wrap(err, "Could not define sub routes")
 	}
-	err = defineSubRoutes(router, strings.ToLower(constants.ServiceName), cfg, dataStore, fgs, certStore, hostTrustManager, hostControllerConfig)
+	err = defineSubRoutes(router, strings.ToLower(constants.ServiceName), cfg, dataStore, fgs, certStore, hostTrustManager, hostControllerConfig, eventPublisher, flavorVerifier, auditLogWriter, hostImporter)
 	if err != nil {
 		return nil, errors.Wrap(err, "Could not define sub routes")
 	}
 	return router, nil
 }
 
-func defineSubRoutes(router *mux.Router, service string, cfg *config.Configuration, dataStore *postgres.DataStore, fgs *postgres.FlavorGroupStore, certStore *models.CertificatesStore, hostTrustManager domain.HostTrustManager, hostControllerConfig domain.HostControllerConfig) error {
+func defineSubRoutes(router *mux.Router, service string, cfg *config.Configuration, dataStore *postgres.DataStore, fgs *postgres.FlavorGroupStore, certStore *models.CertificatesStore, hostTrustManager domain.HostTrustManager, hostControllerConfig domain.HostControllerConfig, eventPublisher domain.EventPublisher, flavorVerifier verifier.Verifier, auditLogWriter domain.AuditLogWriter, hostImporter domain.HostImporter) error {
 	defaultLog.Trace("router/router:defineSubRoutes() Entering")
 	defer defaultLog.Trace("router/router:defineSubRoutes() Leaving")
 
@@ -70,17 +74,21 @@ func defineSubRoutes(router *mux.Router, service string, cfg *config.Configurati
 	if err != nil {
 		return errors.Wrap(err, "Could not parse JWT Certificate cache time")
 	}
-	subRouter.Use(cmw.NewTokenAuth(constants.TrustedJWTSigningCertsDir,
+	subRouter.Use(cmw.NewTokenAuthWithRevocationList(constants.TrustedJWTSigningCertsDir,
 		constants.TrustedRootCACertsDir, cfgRouter.fnGetJwtCerts,
-		cacheTime))
+		cacheTime, cfgRouter.fnGetTokenRevocationList, cmw.DefaultRevocationListCacheTime))
 	subRouter = SetFlavorGroupRoutes(subRouter, dataStore, fgs, hostTrustManager)
 	subRouter = SetFlavorTemplateRoutes(subRouter, dataStore, fgs)
-	subRouter = SetFlavorRoutes(subRouter, dataStore, fgs, certStore, hostTrustManager, hostControllerConfig)
+	subRouter = SetFlavorRoutes(subRouter, dataStore, fgs, certStore, hostTrustManager, hostControllerConfig, auditLogWriter)
+	subRouter = SetFlavorDryRunRoutes(subRouter, dataStore, fgs, flavorVerifier, cfg.FVS.SkipFlavorSignatureVerification)
+	subRouter = SetFlavorBundleRoutes(subRouter, dataStore, fgs, certStore, hostTrustManager, hostControllerConfig, flavorVerifier)
+	subRouter = SetFlavorDiffRoutes(subRouter, dataStore)
 	subRouter = SetTpmEndorsementRoutes(subRouter, dataStore)
 	subRouter = SetCertifyAiksRoutes(subRouter, dataStore, certStore, cfg.AikCertValidity)
 	subRouter = SetHostStatusRoutes(subRouter, dataStore)
 	subRouter = SetCertifyHostKeysRoutes(subRouter, certStore)
 	subRouter = SetHostRoutes(subRouter, dataStore, hostTrustManager, hostControllerConfig)
+	subRouter = SetHostImportRoutes(subRouter, dataStore, hostControllerConfig.DataEncryptionKey, hostImporter)
 	subRouter = SetReportRoutes(subRouter, dataStore, hostTrustManager)
 	subRouter = SetCreateCaCertificatesRoutes(subRouter, certStore)
 	subRouter = SetTagCertificateRoutes(subRouter, cfg, fgs, certStore, hostTrustManager, dataStore)
@@ -88,6 +96,8 @@ func defineSubRoutes(router *mux.Router, service string, cfg *config.Configurati
 	subRouter = SetDeploySoftwareManifestRoute(subRouter, dataStore, hostTrustManager, hostControllerConfig)
 	subRouter = SetManifestsRoute(subRouter, dataStore)
 	subRouter = SetFlavorFromAppManifestRoute(subRouter, dataStore, fgs, certStore, hostTrustManager, hostControllerConfig)
+	subRouter = SetEventRoutes(subRouter, dataStore, hostControllerConfig.DataEncryptionKey, eventPublisher)
+	subRouter = SetAuditLogRoutes(subRouter, dataStore, certStore)
 	return nil
 }
 
@@ -156,3 +166,29 @@ func (r *Router) fnGetJwtCerts() error {
 	}
 	return nil
 }
+
+// Fetch token revocation list from AAS
+func (r *Router) fnGetTokenRevocationList() (*aasModel.TokenRevocationList, error) {
+	defaultLog.Trace("router/router:fnGetTokenRevocationList() Entering")
+	defer defaultLog.Trace("router/router:fnGetTokenRevocationList() Leaving")
+
+	caCerts, err := crypt.GetCertsFromDir(constants.TrustedRootCACertsDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "router/router:fnGetTokenRevocationList() Could not read root CA certificates")
+	}
+	httpClient, err := clients.HTTPClientWithCA(caCerts)
+	if err != nil {
+		return nil, errors.Wrap(err, "router/router:fnGetTokenRevocationList() Could not create http client")
+	}
+	// a hung AAS must not hold up the refresh of the revocation list
+	httpClient.Timeout = cmw.DefaultRevocationListRetrieveTimeout
+	aasClient := aas.Client{
+		BaseURL:    r.cfg.AASApiUrl,
+		HTTPClient: httpClient,
+	}
+	revocationList, err := aasClient.GetTokenRevocationList()
+	if err != nil {
+		return nil, errors.Wrap(err, "router/router:fnGetTokenRevocationList() Could not retrieve token revocation list")
+	}
+	return revocationList, nil
+}
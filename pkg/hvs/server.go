@@ -17,15 +17,19 @@ import (
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
+	"github.com/intel-secl/intel-secl/v4/pkg/clients"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/services/vcss"
 
 	"github.com/pkg/errors"
 
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/config"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/controllers"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/postgres"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/services/auditlog"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/services/events"
 	hostfetcher "github.com/intel-secl/intel-secl/v4/pkg/hvs/services/host-fetcher"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/services/hostimport"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/services/hosttrust"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/services/hrrs"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
@@ -69,19 +73,30 @@ func (a *App) startServer() error {
 
 	// Initialize audit log
 	als := postgres.NewAuditLogEntryStore(dataStore)
-	alw, _ := auditlog.NewAuditLogDBWriter(als, c.AuditLog.BufferSize)
+	alw, err := auditlog.NewAuditLogDBWriter(als, c.AuditLog.BufferSize)
+	if err != nil {
+		return errors.Wrap(err, "An error occurred while initializing audit log writer")
+	}
 
 	// Load Certificates
 	certStore := utils.LoadCertificates(a.loadCertPathStore())
 
+	// Initialize trust event publisher
+	eventPublisher, err := initEventPublisher(c, dataStore, certStore)
+	if err != nil {
+		return errors.Wrap(err, "An error occurred while initializing event publisher")
+	}
+
 	// Initialize Host trust manager
 	fgs := postgres.NewFlavorGroupStore(dataStore)
-	hostTrustManager := initHostTrustManager(c, dataStore, fgs, certStore, alw)
+	flavorVerifier := initFlavorVerifier(c, certStore)
+	hostTrustManager := initHostTrustManager(c, dataStore, fgs, certStore, flavorVerifier, alw, eventPublisher)
 	go hostTrustManager.ProcessQueue()
 
 	// create an instance of the HRRS and start it...
 	reportStore := postgres.NewReportStore(dataStore)
 	reportStore.AuditLogWriter = alw
+	reportStore.EventPublisher = eventPublisher
 	reportRefresher, err := hrrs.NewHostReportRefresher(c.HRRS, reportStore, hostTrustManager)
 	if err != nil {
 		return errors.Wrap(err, "An error occurred while initializing HRRS")
@@ -92,6 +107,13 @@ func (a *App) startServer() error {
 		return errors.Wrap(err, "An error occurred while initializing Report Refresher")
 	}
 
+	// Verify the hosts again when the flavors enter or leave their lifecycle state at their dates
+	flavorLifecycleScheduler := hosttrust.NewFlavorLifecycleScheduler(postgres.NewFlavorStore(dataStore), fgs,
+		postgres.NewHostStore(dataStore), hostTrustManager, constants.FlavorLifecycleSchedulerPeriod)
+	if err = flavorLifecycleScheduler.Run(); err != nil {
+		return errors.Wrap(err, "An error occurred while initializing flavor lifecycle scheduler")
+	}
+
 	// Initialize Host controller config
 	hostControllerConfig := initHostControllerConfig(c, certStore)
 
@@ -106,8 +128,17 @@ func (a *App) startServer() error {
 		return errors.Wrap(err, "An error occurred while initializing vCenter Cluster Syncer")
 	}
 
+	// Initialize bulk host importer and resume the jobs interrupted by the last shutdown
+	hostImporter, err := initHostImporter(c, dataStore, fgs, hostTrustManager, hostControllerConfig)
+	if err != nil {
+		return errors.Wrap(err, "An error occurred while initializing host importer")
+	}
+	if err := hostImporter.Resume(); err != nil {
+		defaultLog.WithError(err).Error("Failed to resume host import jobs")
+	}
+
 	// Initialize routes
-	routes, err := router.InitRoutes(c, dataStore, fgs, certStore, hostTrustManager, hostControllerConfig)
+	routes, err := router.InitRoutes(c, dataStore, fgs, certStore, hostTrustManager, hostControllerConfig, eventPublisher, flavorVerifier, alw, hostImporter)
 	if err != nil {
 		return errors.Wrap(err, "An error occurred while initializing routes")
 	}
@@ -158,10 +189,16 @@ func (a *App) startServer() error {
 		return errors.Wrap(err, "An error occurred while stopping Report Refresher")
 	}
 
+	err = flavorLifecycleScheduler.Stop()
+	if err != nil {
+		return errors.Wrap(err, "An error occurred while stopping flavor lifecycle scheduler")
+	}
+
 	if err := h.Shutdown(ctx); err != nil {
 		defaultLog.WithError(err).Info("Failed to gracefully shutdown webserver")
 		return err
 	}
+	eventPublisher.Stop()
 	secLog.Info(commLogMsg.ServiceStop)
 	return nil
 }
@@ -194,24 +231,43 @@ func getDecodedDek(cfg *config.Configuration) []byte {
 	return dek
 }
 
-func initHostTrustManager(cfg *config.Configuration, dataStore *postgres.DataStore, fgs *postgres.FlavorGroupStore, certStore *models.CertificatesStore, alw domain.AuditLogWriter) domain.HostTrustManager {
-	defaultLog.Trace("server:InitHostTrustManager() Entering")
-	defer defaultLog.Trace("server:InitHostTrustManager() Leaving")
+func initEventPublisher(cfg *config.Configuration, dataStore *postgres.DataStore, certStore *models.CertificatesStore) (domain.EventPublisher, error) {
+	defaultLog.Trace("server:initEventPublisher() Entering")
+	defer defaultLog.Trace("server:initEventPublisher() Leaving")
 
-	//Load store
-	hs := postgres.NewHostStore(dataStore)
-	hc := postgres.NewHostCredentialStore(dataStore, getDecodedDek(cfg))
-	fs := postgres.NewFlavorStore(dataStore)
-	qs := postgres.NewDBQueueStore(dataStore)
-	hss := postgres.NewHostStatusStore(dataStore)
-	hss.AuditLogWriter = alw
-	rs := postgres.NewReportStore(dataStore)
-	rs.AuditLogWriter = alw
+	rootCAs := (*certStore)[models.CaCertTypesRootCa.String()]
+	return events.NewEventPublisher(domain.EventPublisherConfig{
+		SubscriptionStore:  postgres.NewEventSubscriptionStore(dataStore, getDecodedDek(cfg)),
+		BufferSize:         cfg.Events.BufferSize,
+		WebhookMaxAttempts: cfg.Events.WebhookMaxAttempts,
+		WebhookRetryDelay:  cfg.Events.WebhookRetryDelay,
+		WebhookTimeout:     cfg.Events.WebhookTimeout,
+	}, rootCAs.Certificates)
+}
+
+// initHostImporter creates the bulk host importer, the hosts being registered the same way as with the hosts API
+func initHostImporter(cfg *config.Configuration, dataStore *postgres.DataStore, fgs *postgres.FlavorGroupStore, hostTrustManager domain.HostTrustManager, hostControllerConfig domain.HostControllerConfig) (domain.HostImporter, error) {
+	defaultLog.Trace("server:initHostImporter() Entering")
+	defer defaultLog.Trace("server:initHostImporter() Leaving")
+
+	hostController := controllers.NewHostController(postgres.NewHostStore(dataStore), postgres.NewHostStatusStore(dataStore),
+		postgres.NewFlavorStore(dataStore), fgs, postgres.NewHostCredentialStore(dataStore, hostControllerConfig.DataEncryptionKey),
+		hostTrustManager, hostControllerConfig)
+	return hostimport.NewHostImporter(domain.HostImporterConfig{
+		JobStore:    postgres.NewHostImportJobStore(dataStore, hostControllerConfig.DataEncryptionKey),
+		HostCreator: hostController,
+		Concurrency: cfg.HostImport.Concurrency,
+	})
+}
+
+// initFlavorVerifier creates the verifier library instance shared by the host trust manager and the flavor dry run
+func initFlavorVerifier(cfg *config.Configuration, certStore *models.CertificatesStore) verifier.Verifier {
+	defaultLog.Trace("server:initFlavorVerifier() Entering")
+	defer defaultLog.Trace("server:initFlavorVerifier() Leaving")
 
 	//Load certificates
 	rootCAs := (*certStore)[models.CaCertTypesRootCa.String()]
 	tagCAs := (*certStore)[models.CaCertTypesTagCa.String()]
-	samlCert := (*certStore)[models.CertTypesSaml.String()]
 	privacyCAs := (*certStore)[models.CaCertTypesPrivacyCa.String()]
 	signingCerts := (*certStore)[models.CertTypesFlavorSigning.String()]
 	rootCApool := crypt.GetCertPool(rootCAs.Certificates)
@@ -219,13 +275,42 @@ func initHostTrustManager(cfg *config.Configuration, dataStore *postgres.DataSto
 		rootCApool.AddCert(&val) //Add intermediate CA
 	}
 
+	// CRLs and OCSP responses are served by CMS, trust its root CA when fetching them
+	revocationClient, err := clients.HTTPClientWithCA(rootCAs.Certificates)
+	if err != nil {
+		defaultLog.WithError(err).Fatal("Error creating the certificate revocation check client")
+	}
+
 	verifierCerts := verifier.VerifierCertificates{
 		PrivacyCACertificates:    crypt.GetCertPool(privacyCAs.Certificates),
 		AssetTagCACertificates:   crypt.GetCertPool(tagCAs.Certificates),
 		FlavorSigningCertificate: &signingCerts.Certificates[0],
 		FlavorCACertificates:     rootCApool,
+		RevocationChecker:        crypt.NewRevocationChecker(cfg.RevocationCheck, revocationClient),
 	}
 	libVerifier, _ := verifier.NewVerifier(verifierCerts)
+	return libVerifier
+}
+
+func initHostTrustManager(cfg *config.Configuration, dataStore *postgres.DataStore, fgs *postgres.FlavorGroupStore, certStore *models.CertificatesStore, libVerifier verifier.Verifier, alw domain.AuditLogWriter, eventPublisher domain.EventPublisher) domain.HostTrustManager {
+	defaultLog.Trace("server:InitHostTrustManager() Entering")
+	defer defaultLog.Trace("server:InitHostTrustManager() Leaving")
+
+	//Load store
+	hs := postgres.NewHostStore(dataStore)
+	hc := postgres.NewHostCredentialStore(dataStore, getDecodedDek(cfg))
+	fs := postgres.NewFlavorStore(dataStore)
+	qs := postgres.NewDBQueueStore(dataStore)
+	hss := postgres.NewHostStatusStore(dataStore)
+	hss.AuditLogWriter = alw
+	hss.EventPublisher = eventPublisher
+	rs := postgres.NewReportStore(dataStore)
+	rs.AuditLogWriter = alw
+	rs.EventPublisher = eventPublisher
+
+	//Load certificates
+	rootCAs := (*certStore)[models.CaCertTypesRootCa.String()]
+	samlCert := (*certStore)[models.CertTypesSaml.String()]
 	samlKey := samlCert.Key.(*rsa.PrivateKey)
 	samlIssuerConfig := saml.IssuerConfiguration{
 		IssuerName:        cfg.SAML.Issuer,
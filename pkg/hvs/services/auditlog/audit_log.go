@@ -10,7 +10,9 @@ import (
 	log "github.com/sirupsen/logrus"
 	"runtime/debug"
 	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
 	"github.com/pkg/errors"
@@ -24,6 +26,10 @@ type auditLogDB struct {
 	stopChan chan struct{}
 	doneChan chan struct{}
 	lock     sync.Mutex
+
+	// head of the hash chain, only accessed by the creation routine
+	lastSequence int64
+	lastHash     string
 }
 
 var defaultLog = commLog.GetDefaultLogger()
@@ -35,6 +41,9 @@ func NewAuditLogDBWriter(s domain.AuditLogEntryStore, chanBufferSize int) (domai
 	ret := &auditLogDB{
 		store: s,
 	}
+	if err := ret.loadChainHead(); err != nil {
+		return nil, errors.Wrap(err, "failed to load audit log hash chain")
+	}
 	ret.logQueue = make(chan *models.AuditLogEntry, chanBufferSize)
 	ret.stopChan = make(chan struct{})
 	ret.doneChan = make(chan struct{})
@@ -68,18 +77,12 @@ func (alp *auditLogDB) startCreateRoutine() error {
 		for {
 			select {
 			case e := <-alp.logQueue:
-				_, err := alp.store.Create(e)
-				if err != nil {
-					log.WithError(err).Errorf("failed to create audit log routine")
-				}
+				alp.create(e)
 			case <-alp.stopChan:
 				// clean existing queue and return
 				for len(alp.logQueue) > 0 {
 					e := <-alp.logQueue
-					_, err := alp.store.Create(e)
-					if err != nil {
-						log.WithError(err).Errorf("failed to create audit log routine")
-					}
+					alp.create(e)
 				}
 				alp.doneChan <- struct{}{}
 				return
@@ -88,3 +91,48 @@ func (alp *auditLogDB) startCreateRoutine() error {
 	}()
 	return nil
 }
+
+// create chains the entry to the last one written and stores it
+func (alp *auditLogDB) create(e *models.AuditLogEntry) {
+	if err := alp.chain(e); err != nil {
+		log.WithError(err).Errorf("failed to chain audit log entry")
+		return
+	}
+	if _, err := alp.store.Create(e); err != nil {
+		log.WithError(err).Errorf("failed to create audit log routine")
+		// the entry may still have been written, resynchronize with the stored chain
+		if err := alp.loadChainHead(); err != nil {
+			log.WithError(err).Errorf("failed to reload audit log hash chain")
+		}
+		return
+	}
+	alp.lastSequence = e.Sequence
+	alp.lastHash = e.Hash
+}
+
+// chain sets the fields of the entry covered by its hash, then the hash itself
+func (alp *auditLogDB) chain(e *models.AuditLogEntry) error {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return errors.Wrap(err, "failed to create new UUID")
+	}
+	e.ID = id
+	// the database keeps microseconds
+	e.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	e.Sequence = alp.lastSequence + 1
+	e.PrevHash = alp.lastHash
+	e.Hash, err = e.ComputeHash()
+	return err
+}
+
+func (alp *auditLogDB) loadChainHead() error {
+	latest, err := alp.store.RetrieveLatest()
+	if err != nil {
+		return err
+	}
+	if latest != nil {
+		alp.lastSequence = latest.Sequence
+		alp.lastHash = latest.Hash
+	}
+	return nil
+}
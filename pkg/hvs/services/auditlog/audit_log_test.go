@@ -9,6 +9,7 @@ import (
 	"crypto/rand"
 	"github.com/stretchr/testify/assert"
 	"math/big"
+	"sort"
 	"testing"
 
 	"github.com/google/uuid"
@@ -24,9 +25,10 @@ type mockEntryStore struct {
 }
 
 func (me *mockEntryStore) Create(e *models.AuditLogEntry) (*models.AuditLogEntry, error) {
-	id, _ := uuid.NewRandom()
-	e.ID = id
-	me.data[id.String()] = e
+	if e.ID == uuid.Nil {
+		e.ID, _ = uuid.NewRandom()
+	}
+	me.data[e.ID.String()] = e
 	me.t.Log("Create", e)
 	return e, nil
 }
@@ -74,6 +76,31 @@ func (me *mockEntryStore) Delete(id uuid.UUID) error {
 	return nil
 }
 
+func (me *mockEntryStore) Search(criteria *models.AuditLogFilterCriteria) ([]models.AuditLogEntry, error) {
+	var ret []models.AuditLogEntry
+	for _, v := range me.data {
+		if criteria.ChainAfter != nil && v.Sequence <= criteria.ChainAfter.Sequence {
+			continue
+		}
+		if criteria.EntityType != "" && v.EntityType != criteria.EntityType {
+			continue
+		}
+		ret = append(ret, *v)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Sequence < ret[j].Sequence })
+	return ret, nil
+}
+
+func (me *mockEntryStore) RetrieveLatest() (*models.AuditLogEntry, error) {
+	var latest *models.AuditLogEntry
+	for _, v := range me.data {
+		if v.Sequence > 0 && (latest == nil || v.Sequence > latest.Sequence) {
+			latest = v
+		}
+	}
+	return latest, nil
+}
+
 func TestAuditLogService(t *testing.T) {
 	store := &mockEntryStore{
 		t:    t,
@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package auditlog
+
+import (
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+)
+
+// ChainAnchor is a position of the hash chain recorded earlier, for instance from the signature of an export
+type ChainAnchor struct {
+	Sequence int64
+	Hash     string
+}
+
+// ChainVerifier checks the hash chain of the audit log. The chained entries are added in sequence order, the
+// verifier detects the entries whose content was modified and the entries deleted from the chain.
+//
+// The first entry is not required to follow the entries rotated out of the audit log, so the deletion of the
+// oldest entries can only be detected with an anchor. Likewise, the deletion of the newest entries is detected
+// when an anchor past the end of the chain is provided.
+type ChainVerifier struct {
+	anchor *ChainAnchor
+	result hvs.AuditLogChainVerification
+}
+
+func NewChainVerifier(anchor *ChainAnchor) *ChainVerifier {
+	return &ChainVerifier{anchor: anchor}
+}
+
+// Add verifies the next entry of the chain
+func (cv *ChainVerifier) Add(entry *models.AuditLogEntry) {
+	defaultLog.Trace("auditlog/chain:Add() Entering")
+	defer defaultLog.Trace("auditlog/chain:Add() Leaving")
+
+	hash, err := entry.ComputeHash()
+	if err != nil || hash != entry.Hash {
+		defaultLog.WithError(err).Warnf("auditlog/chain:Add() Hash mismatch for audit log entry %d", entry.Sequence)
+		cv.result.ModifiedEntries = append(cv.result.ModifiedEntries, entry.Sequence)
+	}
+
+	if cv.result.EntryCount == 0 {
+		cv.result.FirstSequence = entry.Sequence
+	} else {
+		switch {
+		case entry.Sequence > cv.result.LastSequence+1:
+			cv.result.MissingEntries = append(cv.result.MissingEntries,
+				hvs.AuditLogSequenceRange{From: cv.result.LastSequence + 1, To: entry.Sequence - 1})
+			cv.result.BrokenLinks = append(cv.result.BrokenLinks, entry.Sequence)
+		case entry.PrevHash != cv.result.LastHash:
+			cv.result.BrokenLinks = append(cv.result.BrokenLinks, entry.Sequence)
+		}
+	}
+
+	if cv.anchor != nil && entry.Sequence == cv.anchor.Sequence && cv.result.Anchor != hvs.AuditLogAnchorMismatched {
+		if entry.Hash == cv.anchor.Hash {
+			cv.result.Anchor = hvs.AuditLogAnchorMatched
+		} else {
+			cv.result.Anchor = hvs.AuditLogAnchorMismatched
+		}
+	}
+
+	cv.result.EntryCount++
+	cv.result.LastSequence = entry.Sequence
+	cv.result.LastHash = entry.Hash
+}
+
+// Result returns the outcome of the verification of the entries added so far
+func (cv *ChainVerifier) Result() hvs.AuditLogChainVerification {
+	result := cv.result
+	if cv.anchor != nil && result.Anchor == "" {
+		if result.EntryCount == 0 || cv.anchor.Sequence > result.LastSequence {
+			result.Anchor = hvs.AuditLogAnchorMissing
+			result.MissingEntries = append(result.MissingEntries,
+				hvs.AuditLogSequenceRange{From: result.LastSequence + 1, To: cv.anchor.Sequence})
+		} else if cv.anchor.Sequence < result.FirstSequence {
+			result.Anchor = hvs.AuditLogAnchorRotated
+		} else {
+			// the anchor sequence falls within a range of missing entries
+			result.Anchor = hvs.AuditLogAnchorMissing
+		}
+	}
+	result.Verified = len(result.ModifiedEntries) == 0 && len(result.BrokenLinks) == 0 &&
+		len(result.MissingEntries) == 0 && result.Anchor != hvs.AuditLogAnchorMismatched
+	return result
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package auditlog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeChain logs count host status entries and returns them as read back from the store, in sequence order
+func writeChain(t *testing.T, store *mockEntryStore, count int) []models.AuditLogEntry {
+	w, err := NewAuditLogDBWriter(store, count)
+	assert.NoError(t, err)
+	for i := 0; i < count; i++ {
+		e, err := w.CreateEntry("update", &hvs.HostStatus{ID: uuid.New(), HostID: uuid.New(), Created: time.Now()},
+			&hvs.HostStatus{ID: uuid.New(), HostID: uuid.New(), HostStatusInformation: hvs.HostStatusInformation{
+				HostState: hvs.HostStateConnected, LastTimeConnected: time.Now()}})
+		assert.NoError(t, err)
+		w.Log(e)
+	}
+	w.Stop()
+
+	entries, err := store.Search(&models.AuditLogFilterCriteria{ChainAfter: &models.AuditLogChainCursor{}})
+	assert.NoError(t, err)
+	assert.Len(t, entries, len(store.data))
+	// the column values are read back from JSONB
+	for i := range entries {
+		b, err := json.Marshal(entries[i].Data)
+		assert.NoError(t, err)
+		entries[i].Data = models.AuditTableData{}
+		assert.NoError(t, json.Unmarshal(b, &entries[i].Data))
+		entries[i].CreatedAt = entries[i].CreatedAt.Local()
+	}
+	return entries
+}
+
+func verifyChain(entries []models.AuditLogEntry, anchor *ChainAnchor) hvs.AuditLogChainVerification {
+	verifier := NewChainVerifier(anchor)
+	for i := range entries {
+		verifier.Add(&entries[i])
+	}
+	return verifier.Result()
+}
+
+func TestAuditLogChain(t *testing.T) {
+	store := &mockEntryStore{t: t, data: make(map[string]*models.AuditLogEntry)}
+	entries := writeChain(t, store, 5)
+	for i, e := range entries {
+		assert.Equal(t, int64(i+1), e.Sequence)
+		if i > 0 {
+			assert.Equal(t, entries[i-1].Hash, e.PrevHash)
+		}
+	}
+
+	result := verifyChain(entries, nil)
+	assert.True(t, result.Verified)
+	assert.Equal(t, int64(5), result.EntryCount)
+	assert.Equal(t, int64(1), result.FirstSequence)
+	assert.Equal(t, entries[4].Hash, result.LastHash)
+
+	// a new writer continues the chain
+	entries = writeChain(t, store, 2)
+	assert.Len(t, entries, 7)
+	assert.Equal(t, int64(7), entries[6].Sequence)
+	assert.True(t, verifyChain(entries, nil).Verified)
+}
+
+func TestAuditLogChainTampering(t *testing.T) {
+	store := &mockEntryStore{t: t, data: make(map[string]*models.AuditLogEntry)}
+	entries := writeChain(t, store, 6)
+
+	modified := append([]models.AuditLogEntry{}, entries...)
+	modified[2].Action = "delete"
+	result := verifyChain(modified, nil)
+	assert.False(t, result.Verified)
+	assert.Equal(t, []int64{3}, result.ModifiedEntries)
+	assert.Empty(t, result.BrokenLinks)
+
+	// rehashing the modified entry breaks the link of the next one
+	modified[2].Hash, _ = modified[2].ComputeHash()
+	result = verifyChain(modified, nil)
+	assert.False(t, result.Verified)
+	assert.Empty(t, result.ModifiedEntries)
+	assert.Equal(t, []int64{4}, result.BrokenLinks)
+
+	deleted := append(append([]models.AuditLogEntry{}, entries[:2]...), entries[4:]...)
+	result = verifyChain(deleted, nil)
+	assert.False(t, result.Verified)
+	assert.Equal(t, []hvs.AuditLogSequenceRange{{From: 3, To: 4}}, result.MissingEntries)
+
+	// rotated out entries are only detected with an anchor
+	result = verifyChain(entries[2:], nil)
+	assert.True(t, result.Verified)
+	assert.Equal(t, int64(3), result.FirstSequence)
+	result = verifyChain(entries[2:], &ChainAnchor{Sequence: 1, Hash: entries[0].Hash})
+	assert.True(t, result.Verified)
+	assert.Equal(t, hvs.AuditLogAnchorRotated, result.Anchor)
+
+	result = verifyChain(entries, &ChainAnchor{Sequence: 4, Hash: entries[3].Hash})
+	assert.True(t, result.Verified)
+	assert.Equal(t, hvs.AuditLogAnchorMatched, result.Anchor)
+
+	result = verifyChain(entries, &ChainAnchor{Sequence: 4, Hash: entries[2].Hash})
+	assert.False(t, result.Verified)
+	assert.Equal(t, hvs.AuditLogAnchorMismatched, result.Anchor)
+
+	// the newest entries were deleted
+	result = verifyChain(entries[:4], &ChainAnchor{Sequence: 6, Hash: entries[5].Hash})
+	assert.False(t, result.Verified)
+	assert.Equal(t, hvs.AuditLogAnchorMissing, result.Anchor)
+	assert.Equal(t, []hvs.AuditLogSequenceRange{{From: 5, To: 6}}, result.MissingEntries)
+}
@@ -53,6 +53,14 @@ func (alp *auditLogDB) CreateEntry(action string, values ...interface{}) (*model
 		}
 		cols = append(cols, report2Cols(base, diff)...)
 		return entryHelper(base.ID, "report", action, cols), nil
+	case *hvs.FlavorLifecycle:
+		diff := base
+		if action == "update" {
+			if diff, ok = values[1].(*hvs.FlavorLifecycle); !ok {
+				return nil, errors.New("invalid input for audit log: incoherent input")
+			}
+		}
+		return entryHelper(base.FlavorId, "flavor", action, flavorLifecycle2Cols(base, diff)), nil
 	}
 }
 
@@ -131,3 +139,28 @@ func hostStatus2Cols(old, current *hvs.HostStatus) []models.AuditColumnData {
 		},
 	}
 }
+
+func flavorLifecycle2Cols(old, current *hvs.FlavorLifecycle) []models.AuditColumnData {
+	return []models.AuditColumnData{
+		{
+			Name:      "id",
+			Value:     current.FlavorId,
+			IsUpdated: old.FlavorId != current.FlavorId,
+		},
+		{
+			Name:      "state",
+			Value:     current.State,
+			IsUpdated: old.State != current.State,
+		},
+		{
+			Name:      "not_before",
+			Value:     current.NotBefore,
+			IsUpdated: !reflect.DeepEqual(old.NotBefore, current.NotBefore),
+		},
+		{
+			Name:      "not_after",
+			Value:     current.NotAfter,
+			IsUpdated: !reflect.DeepEqual(old.NotAfter, current.NotAfter),
+		},
+	}
+}
@@ -0,0 +1,303 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	consts "github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
+	commLog "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+var defaultLog = commLog.GetDefaultLogger()
+
+// listenerBufferSize is the number of events buffered for an event stream listener. Events are dropped
+// for the listeners that do not keep up.
+const listenerBufferSize = 100
+
+type publisher struct {
+	store       domain.EventSubscriptionStore
+	httpClient  *http.Client
+	maxAttempts int
+	retryDelay  time.Duration
+
+	eventQueue chan *hvs.TrustEvent
+	stopChan   chan struct{}
+	doneChan   chan struct{}
+	ctx        context.Context
+	cancel     context.CancelFunc
+	deliveries sync.WaitGroup
+	stopOnce   sync.Once
+
+	lock      sync.Mutex
+	stopped   bool
+	listeners map[*listener]struct{}
+}
+
+type listener struct {
+	eventTypes []string
+	events     chan *hvs.TrustEvent
+}
+
+// NewEventPublisher starts the routine delivering the published events to the webhook subscriptions and to the
+// event stream listeners. rootCAs are trusted in addition to the system CAs when connecting to the webhooks.
+func NewEventPublisher(cfg domain.EventPublisherConfig, rootCAs []x509.Certificate) (domain.EventPublisher, error) {
+	if cfg.SubscriptionStore == nil {
+		return nil, errors.New("NewEventPublisher: invalid event subscription store")
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = constants.DefaultEventsBufferSize
+	}
+	if cfg.WebhookMaxAttempts <= 0 {
+		cfg.WebhookMaxAttempts = constants.DefaultEventsWebhookMaxAttempts
+	}
+	if cfg.WebhookRetryDelay <= 0 {
+		cfg.WebhookRetryDelay = constants.DefaultEventsWebhookRetryDelay
+	}
+	if cfg.WebhookTimeout <= 0 {
+		cfg.WebhookTimeout = constants.DefaultEventsWebhookTimeout
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	for i := range rootCAs {
+		pool.AddCert(&rootCAs[i])
+	}
+
+	p := &publisher{
+		store: cfg.SubscriptionStore,
+		httpClient: &http.Client{
+			Timeout: cfg.WebhookTimeout,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					MinVersion: tls.VersionTLS12,
+					RootCAs:    pool,
+				},
+			},
+		},
+		maxAttempts: cfg.WebhookMaxAttempts,
+		retryDelay:  cfg.WebhookRetryDelay,
+		eventQueue:  make(chan *hvs.TrustEvent, cfg.BufferSize),
+		stopChan:    make(chan struct{}),
+		doneChan:    make(chan struct{}),
+		listeners:   make(map[*listener]struct{}),
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	go p.dispatch()
+	return p, nil
+}
+
+// Publish never blocks the caller, the event is dropped when the queue is full
+func (p *publisher) Publish(e *hvs.TrustEvent) {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.Created.IsZero() {
+		e.Created = time.Now()
+	}
+	select {
+	case p.eventQueue <- e:
+	default:
+		defaultLog.Warnf("services/events:Publish() Event queue is full, dropping %s event for host %s", e.Type, e.HostID)
+	}
+}
+
+func (p *publisher) Listen(eventTypes []string) (<-chan *hvs.TrustEvent, func()) {
+	l := &listener{
+		eventTypes: eventTypes,
+		events:     make(chan *hvs.TrustEvent, listenerBufferSize),
+	}
+	p.lock.Lock()
+	if p.stopped {
+		// the stream of a stopped publisher ends right away
+		close(l.events)
+	} else {
+		p.listeners[l] = struct{}{}
+	}
+	p.lock.Unlock()
+
+	var once sync.Once
+	return l.events, func() {
+		once.Do(func() {
+			p.lock.Lock()
+			if _, ok := p.listeners[l]; ok {
+				delete(p.listeners, l)
+				close(l.events)
+			}
+			p.lock.Unlock()
+		})
+	}
+}
+
+// Stop delivers the queued events, waits for the in-flight webhook requests, abandons the pending retries and
+// closes the event streams. Only the first call stops the publisher.
+func (p *publisher) Stop() {
+	p.stopOnce.Do(func() {
+		p.stopChan <- struct{}{}
+		<-p.doneChan
+		p.cancel()
+		p.deliveries.Wait()
+
+		p.lock.Lock()
+		p.stopped = true
+		for l := range p.listeners {
+			delete(p.listeners, l)
+			close(l.events)
+		}
+		p.lock.Unlock()
+	})
+}
+
+func (p *publisher) dispatch() {
+	defer func() {
+		if err := recover(); err != nil {
+			defaultLog.Errorf("Panic occurred: %+v", err)
+			defaultLog.Error(string(debug.Stack()))
+		}
+	}()
+	for {
+		select {
+		case e := <-p.eventQueue:
+			p.handle(e)
+		case <-p.stopChan:
+			for len(p.eventQueue) > 0 {
+				p.handle(<-p.eventQueue)
+			}
+			p.doneChan <- struct{}{}
+			return
+		}
+	}
+}
+
+func (p *publisher) handle(e *hvs.TrustEvent) {
+	defaultLog.Debugf("services/events:handle() Dispatching %s event %s for host %s", e.Type, e.ID, e.HostID)
+
+	p.lock.Lock()
+	for l := range p.listeners {
+		if !matches(l.eventTypes, e.Type) {
+			continue
+		}
+		select {
+		case l.events <- e:
+		default:
+			defaultLog.Warnf("services/events:handle() Event stream listener is not keeping up, dropping event %s", e.ID)
+		}
+	}
+	p.lock.Unlock()
+
+	subscriptions, err := p.store.Search(&models.EventSubscriptionFilterCriteria{EventType: e.Type})
+	if err != nil {
+		defaultLog.WithError(err).Errorf("services/events:handle() Failed to retrieve the subscriptions for event %s", e.ID)
+		return
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		defaultLog.WithError(err).Errorf("services/events:handle() Failed to marshal event %s", e.ID)
+		return
+	}
+	for i := range subscriptions {
+		p.deliveries.Add(1)
+		go p.deliver(subscriptions[i], e, payload)
+	}
+}
+
+// deliver posts the event to the webhook, retrying with an exponential backoff on connection errors,
+// server errors and throttling
+func (p *publisher) deliver(sub hvs.EventSubscription, e *hvs.TrustEvent, payload []byte) {
+	defer p.deliveries.Done()
+
+	delay := p.retryDelay
+	for attempt := 1; ; attempt++ {
+		retry, err := p.post(sub, e, payload)
+		if err == nil {
+			defaultLog.Debugf("services/events:deliver() Event %s delivered to subscription %s", e.ID, sub.ID)
+			return
+		}
+		if !retry || attempt >= p.maxAttempts {
+			defaultLog.WithError(err).Errorf("services/events:deliver() Failed to deliver event %s to subscription %s after %d attempt(s)",
+				e.ID, sub.ID, attempt)
+			return
+		}
+		defaultLog.WithError(err).Warnf("services/events:deliver() Failed to deliver event %s to subscription %s, retrying in %s",
+			e.ID, sub.ID, delay)
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-p.ctx.Done():
+			defaultLog.Warnf("services/events:deliver() Abandoning delivery of event %s to subscription %s on shutdown", e.ID, sub.ID)
+			return
+		}
+	}
+}
+
+// post returns whether a failed delivery can be retried
+func (p *publisher) post(sub hvs.EventSubscription, e *hvs.TrustEvent, payload []byte) (bool, error) {
+	// in-flight requests are bounded by the client timeout and are not aborted on shutdown
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(payload))
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to create webhook request")
+	}
+	req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+	req.Header.Set(constants.EventTypeHeader, e.Type)
+	req.Header.Set(constants.EventSignatureHeader, "sha256="+Sign(sub.Secret, payload))
+
+	rsp, err := p.httpClient.Do(req)
+	if err != nil {
+		return true, errors.Wrap(err, "Failed to reach webhook")
+	}
+	defer func() {
+		derr := rsp.Body.Close()
+		if derr != nil {
+			defaultLog.WithError(derr).Error("Error closing response body")
+		}
+	}()
+	if rsp.StatusCode >= 200 && rsp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := rsp.StatusCode >= 500 || rsp.StatusCode == http.StatusTooManyRequests || rsp.StatusCode == http.StatusRequestTimeout
+	return retry, errors.Errorf("Webhook returned status %d", rsp.StatusCode)
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the payload, as sent in the signature header of the webhook requests
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func matches(eventTypes []string, eventType string) bool {
+	if len(eventTypes) == 0 {
+		return true
+	}
+	for _, t := range eventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package events
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/mocks"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/stretchr/testify/assert"
+)
+
+type delivery struct {
+	eventType string
+	signature string
+	body      []byte
+}
+
+func newTestPublisher(t *testing.T, url string, eventTypes []string, rootCAs []x509.Certificate) domain.EventPublisher {
+	store := mocks.NewMockEventSubscriptionStore()
+	subscriptions, _ := store.Search(nil)
+	for _, s := range subscriptions {
+		assert.NoError(t, store.Delete(s.ID))
+	}
+	_, err := store.Create(&hvs.EventSubscription{URL: url, Secret: "webhook-secret", EventTypes: eventTypes})
+	assert.NoError(t, err)
+
+	p, err := NewEventPublisher(domain.EventPublisherConfig{
+		SubscriptionStore:  store,
+		WebhookMaxAttempts: 3,
+		WebhookRetryDelay:  10 * time.Millisecond,
+		WebhookTimeout:     time.Second,
+	}, rootCAs)
+	assert.NoError(t, err)
+	return p
+}
+
+func TestPublisherDeliversSignedEventWithRetries(t *testing.T) {
+	var attempts int32
+	deliveries := make(chan delivery, 1)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		deliveries <- delivery{
+			eventType: r.Header.Get(constants.EventTypeHeader),
+			signature: r.Header.Get(constants.EventSignatureHeader),
+			body:      body,
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	p := newTestPublisher(t, server.URL, nil, []x509.Certificate{*server.Certificate()})
+	defer p.Stop()
+
+	trusted := false
+	hostId := uuid.New()
+	p.Publish(&hvs.TrustEvent{Type: hvs.TrustEventHostTrustChanged, HostID: hostId, Trusted: &trusted})
+
+	select {
+	case d := <-deliveries:
+		assert.Equal(t, hvs.TrustEventHostTrustChanged, d.eventType)
+		assert.Equal(t, "sha256="+Sign("webhook-secret", d.body), d.signature)
+		var e hvs.TrustEvent
+		assert.NoError(t, json.Unmarshal(d.body, &e))
+		assert.Equal(t, hostId, e.HostID)
+		assert.NotEqual(t, uuid.Nil, e.ID)
+		assert.False(t, *e.Trusted)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
+
+func TestPublisherDoesNotRetryClientErrors(t *testing.T) {
+	var attempts int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	p := newTestPublisher(t, server.URL, nil, []x509.Certificate{*server.Certificate()})
+	p.Publish(&hvs.TrustEvent{Type: hvs.TrustEventHostStateChanged, HostID: uuid.New()})
+	// Stop waits for the pending deliveries
+	p.Stop()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestPublisherFiltersEventTypes(t *testing.T) {
+	var attempts int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+	}))
+	defer server.Close()
+
+	p := newTestPublisher(t, server.URL, []string{hvs.TrustEventHostTrustChanged}, []x509.Certificate{*server.Certificate()})
+	events, unregister := p.Listen([]string{hvs.TrustEventHostStateChanged})
+	defer unregister()
+
+	p.Publish(&hvs.TrustEvent{Type: hvs.TrustEventHostStateChanged, HostID: uuid.New(), HostState: "CONNECTION_FAILURE"})
+	select {
+	case e := <-events:
+		assert.Equal(t, hvs.TrustEventHostStateChanged, e.Type)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not streamed")
+	}
+	p.Stop()
+	assert.Equal(t, int32(0), atomic.LoadInt32(&attempts))
+
+	_, ok := <-events
+	assert.False(t, ok, "listener should be closed once the publisher is stopped")
+}
+
+func TestPublisherStop(t *testing.T) {
+	p := newTestPublisher(t, "https://localhost:1/webhooks", nil, nil)
+	events, unregister := p.Listen(nil)
+	defer unregister()
+
+	p.Stop()
+	// stopping again is a no-op
+	p.Stop()
+	_, ok := <-events
+	assert.False(t, ok, "listener should be closed once the publisher is stopped")
+
+	// listening to a stopped publisher ends right away
+	stoppedEvents, stoppedUnregister := p.Listen(nil)
+	defer stoppedUnregister()
+	_, ok = <-stoppedEvents
+	assert.False(t, ok, "listener of a stopped publisher should be closed")
+}
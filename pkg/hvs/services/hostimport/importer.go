@@ -0,0 +1,214 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package hostimport
+
+import (
+	"runtime/debug"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
+	commLog "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+var defaultLog = commLog.GetDefaultLogger()
+
+var (
+	// ErrJobRunning is returned when retrying a job that is still registering its hosts
+	ErrJobRunning = errors.New("Host import job is still running")
+	// ErrNoFailedRows is returned when retrying a job without any failed host
+	ErrNoFailedRows = errors.New("Host import job has no failed host to retry")
+)
+
+type importer struct {
+	store       domain.HostImportJobStore
+	hostCreator domain.HostCreator
+	concurrency int
+
+	lock sync.Mutex
+	// active holds the jobs processed by this instance
+	active map[uuid.UUID]struct{}
+}
+
+// NewHostImporter returns the service registering the hosts of the bulk host import jobs in the background, at most
+// cfg.Concurrency hosts of a job being registered at the same time
+func NewHostImporter(cfg domain.HostImporterConfig) (domain.HostImporter, error) {
+	if cfg.JobStore == nil {
+		return nil, errors.New("NewHostImporter: invalid host import job store")
+	}
+	if cfg.HostCreator == nil {
+		return nil, errors.New("NewHostImporter: invalid host creator")
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = constants.DefaultHostImportConcurrency
+	}
+	return &importer{
+		store:       cfg.JobStore,
+		hostCreator: cfg.HostCreator,
+		concurrency: cfg.Concurrency,
+		active:      make(map[uuid.UUID]struct{}),
+	}, nil
+}
+
+// Submit stores the job and starts registering its pending hosts, the rows already failed are left as is
+func (imp *importer) Submit(job *hvs.HostImportJob) (*hvs.HostImportJob, error) {
+	defaultLog.Trace("services/hostimport:Submit() Entering")
+	defer defaultLog.Trace("services/hostimport:Submit() Leaving")
+
+	job.Status = hvs.HostImportJobRunning
+	created, err := imp.store.Create(job)
+	if err != nil {
+		return nil, errors.Wrap(err, "services/hostimport:Submit() Failed to store host import job")
+	}
+	imp.start(created.ID)
+	return created, nil
+}
+
+// Retry registers again the hosts of the job that failed
+func (imp *importer) Retry(id uuid.UUID) (*hvs.HostImportJob, error) {
+	defaultLog.Trace("services/hostimport:Retry() Entering")
+	defer defaultLog.Trace("services/hostimport:Retry() Leaving")
+
+	imp.lock.Lock()
+	defer imp.lock.Unlock()
+
+	job, err := imp.store.Retrieve(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "services/hostimport:Retry() Failed to retrieve host import job")
+	}
+	if _, ok := imp.active[id]; ok || job.Status == hvs.HostImportJobRunning {
+		return nil, ErrJobRunning
+	}
+	if job.Failed == 0 {
+		return nil, ErrNoFailedRows
+	}
+
+	for i := range job.Rows {
+		if job.Rows[i].Status != hvs.HostImportRowFailed {
+			continue
+		}
+		job.Rows[i].Status = hvs.HostImportRowPending
+		job.Rows[i].Error = ""
+		if err := imp.store.UpdateRow(id, &job.Rows[i]); err != nil {
+			return nil, errors.Wrap(err, "services/hostimport:Retry() Failed to reset failed host import row")
+		}
+	}
+	if err := imp.store.UpdateStatus(id, hvs.HostImportJobRunning); err != nil {
+		return nil, errors.Wrap(err, "services/hostimport:Retry() Failed to update host import job status")
+	}
+	job.Status = hvs.HostImportJobRunning
+	job.CountRows()
+
+	imp.active[id] = struct{}{}
+	go imp.run(id)
+	return job, nil
+}
+
+// Resume restarts the jobs that were running when the service was stopped, the hosts registered in the meantime
+// are not registered again
+func (imp *importer) Resume() error {
+	defaultLog.Trace("services/hostimport:Resume() Entering")
+	defer defaultLog.Trace("services/hostimport:Resume() Leaving")
+
+	ids, err := imp.store.SearchByStatus(hvs.HostImportJobRunning)
+	if err != nil {
+		return errors.Wrap(err, "services/hostimport:Resume() Failed to search running host import jobs")
+	}
+	for _, id := range ids {
+		defaultLog.Infof("services/hostimport:Resume() Resuming host import job %s", id)
+		imp.start(id)
+	}
+	return nil
+}
+
+func (imp *importer) start(id uuid.UUID) {
+	imp.lock.Lock()
+	defer imp.lock.Unlock()
+
+	if _, ok := imp.active[id]; ok {
+		return
+	}
+	imp.active[id] = struct{}{}
+	go imp.run(id)
+}
+
+func (imp *importer) run(id uuid.UUID) {
+	defer func() {
+		if err := recover(); err != nil {
+			defaultLog.Errorf("Panic occurred: %+v", err)
+			defaultLog.Error(string(debug.Stack()))
+		}
+		imp.lock.Lock()
+		delete(imp.active, id)
+		imp.lock.Unlock()
+	}()
+
+	job, err := imp.store.Retrieve(id)
+	if err != nil {
+		defaultLog.WithError(err).Errorf("services/hostimport:run() Failed to retrieve host import job %s", id)
+		return
+	}
+
+	defaultLog.Infof("services/hostimport:run() Registering %d host(s) of host import job %s", job.Pending, id)
+	sem := make(chan struct{}, imp.concurrency)
+	var wg sync.WaitGroup
+	for i := range job.Rows {
+		if job.Rows[i].Status != hvs.HostImportRowPending {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(row *hvs.HostImportRow) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			imp.register(id, row)
+		}(&job.Rows[i])
+	}
+	wg.Wait()
+
+	if err := imp.store.UpdateStatus(id, hvs.HostImportJobCompleted); err != nil {
+		defaultLog.WithError(err).Errorf("services/hostimport:run() Failed to complete host import job %s", id)
+		return
+	}
+	defaultLog.Infof("services/hostimport:run() Host import job %s completed", id)
+}
+
+// register creates the host of the row and records the result, a host failing to register does not stop the job
+func (imp *importer) register(jobId uuid.UUID, row *hvs.HostImportRow) {
+	defer func() {
+		if err := recover(); err != nil {
+			defaultLog.Errorf("Panic occurred: %+v", err)
+			defaultLog.Error(string(debug.Stack()))
+			row.Status = hvs.HostImportRowFailed
+			row.Error = "Failed to create Host"
+			if err := imp.store.UpdateRow(jobId, row); err != nil {
+				defaultLog.WithError(err).Errorf("services/hostimport:register() Failed to update row %d of host import job %s", row.Row, jobId)
+			}
+		}
+	}()
+
+	row.Attempts++
+	created, _, err := imp.hostCreator.CreateHost(row.Entry.HostCreateRequest())
+	if err != nil {
+		defaultLog.WithError(err).Warnf("services/hostimport:register() Failed to register host %s of host import job %s", row.HostName, jobId)
+		row.Status = hvs.HostImportRowFailed
+		row.Error = err.Error()
+	} else {
+		row.Status = hvs.HostImportRowSucceeded
+		row.Error = ""
+		if host, ok := created.(*hvs.Host); ok {
+			row.HostId = &host.Id
+		}
+	}
+	if err := imp.store.UpdateRow(jobId, row); err != nil {
+		defaultLog.WithError(err).Errorf("services/hostimport:register() Failed to update row %d of host import job %s", row.Row, jobId)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package hostimport
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/mocks"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeHostCreator fails the hosts whose name starts with "bad" until healed, and records the highest number of
+// hosts registered at the same time
+type fakeHostCreator struct {
+	lock        sync.Mutex
+	inFlight    int
+	maxInFlight int
+	healed      bool
+	requests    []hvs.HostCreateRequest
+}
+
+func (c *fakeHostCreator) CreateHost(req hvs.HostCreateRequest) (interface{}, int, error) {
+	c.lock.Lock()
+	c.inFlight++
+	if c.inFlight > c.maxInFlight {
+		c.maxInFlight = c.inFlight
+	}
+	c.requests = append(c.requests, req)
+	healed := c.healed
+	c.lock.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	c.lock.Lock()
+	c.inFlight--
+	c.lock.Unlock()
+
+	if strings.HasPrefix(req.HostName, "bad") && !healed {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Host is unreachable"}
+	}
+	return &hvs.Host{Id: uuid.New(), HostName: req.HostName}, http.StatusCreated, nil
+}
+
+func newTestJob(names ...string) *hvs.HostImportJob {
+	job := &hvs.HostImportJob{}
+	for i, name := range names {
+		job.Rows = append(job.Rows, hvs.HostImportRow{
+			Row:              i + 1,
+			HostName:         name,
+			ConnectionString: "intel:https://" + name + ":1443",
+			Status:           hvs.HostImportRowPending,
+			Entry: hvs.HostImportEntry{
+				HostName:         name,
+				ConnectionString: "intel:https://" + name + ":1443",
+				Username:         "admin",
+				Password:         "password",
+			},
+		})
+	}
+	return job
+}
+
+func waitForJob(t *testing.T, store domain.HostImportJobStore, id uuid.UUID) *hvs.HostImportJob {
+	var job *hvs.HostImportJob
+	assert.Eventually(t, func() bool {
+		var err error
+		job, err = store.Retrieve(id)
+		return err == nil && job.Status == hvs.HostImportJobCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestHostImporterRegistersHostsWithBoundedConcurrency(t *testing.T) {
+	store := mocks.NewMockHostImportJobStore()
+	creator := &fakeHostCreator{}
+	importer, err := NewHostImporter(domain.HostImporterConfig{JobStore: store, HostCreator: creator, Concurrency: 3})
+	assert.NoError(t, err)
+
+	var names []string
+	for i := 0; i < 20; i++ {
+		names = append(names, uuid.New().String())
+	}
+	submitted, err := importer.Submit(newTestJob(names...))
+	assert.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, submitted.ID)
+	assert.Equal(t, 20, submitted.Total)
+
+	job := waitForJob(t, store, submitted.ID)
+	assert.Equal(t, 20, job.Succeeded)
+	assert.Equal(t, 0, job.Pending)
+	for _, row := range job.Rows {
+		assert.Equal(t, hvs.HostImportRowSucceeded, row.Status)
+		assert.NotNil(t, row.HostId)
+		assert.Equal(t, 1, row.Attempts)
+	}
+	assert.LessOrEqual(t, creator.maxInFlight, 3)
+	assert.Len(t, creator.requests, 20)
+	assert.True(t, strings.HasSuffix(creator.requests[0].ConnectionString, ";u=admin;p=password"))
+}
+
+func TestHostImporterRetriesFailedHosts(t *testing.T) {
+	store := mocks.NewMockHostImportJobStore()
+	creator := &fakeHostCreator{}
+	importer, err := NewHostImporter(domain.HostImporterConfig{JobStore: store, HostCreator: creator})
+	assert.NoError(t, err)
+
+	submitted, err := importer.Submit(newTestJob("good-1", "bad-1", "good-2"))
+	assert.NoError(t, err)
+
+	job := waitForJob(t, store, submitted.ID)
+	assert.Equal(t, 2, job.Succeeded)
+	assert.Equal(t, 1, job.Failed)
+	assert.Equal(t, hvs.HostImportRowFailed, job.Rows[1].Status)
+	assert.Equal(t, "Host is unreachable", job.Rows[1].Error)
+	assert.Nil(t, job.Rows[1].HostId)
+
+	creator.lock.Lock()
+	creator.healed = true
+	creator.lock.Unlock()
+
+	retried, err := importer.Retry(job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, hvs.HostImportJobRunning, retried.Status)
+	assert.Equal(t, 1, retried.Pending)
+
+	job = waitForJob(t, store, job.ID)
+	assert.Equal(t, 3, job.Succeeded)
+	assert.Equal(t, 2, job.Rows[1].Attempts)
+	assert.Equal(t, 1, job.Rows[0].Attempts)
+	assert.Len(t, creator.requests, 4)
+
+	_, err = importer.Retry(job.ID)
+	assert.Equal(t, ErrNoFailedRows, err)
+}
+
+func TestHostImporterRejectsRetryOfRunningJob(t *testing.T) {
+	store := mocks.NewMockHostImportJobStore()
+	job, err := store.Create(&hvs.HostImportJob{Status: hvs.HostImportJobRunning, Rows: newTestJob("bad-1").Rows})
+	assert.NoError(t, err)
+
+	importer, err := NewHostImporter(domain.HostImporterConfig{JobStore: store, HostCreator: &fakeHostCreator{}})
+	assert.NoError(t, err)
+	_, err = importer.Retry(job.ID)
+	assert.Equal(t, ErrJobRunning, err)
+
+	_, err = importer.Retry(uuid.New())
+	assert.Error(t, err)
+}
+
+func TestHostImporterResumesRunningJobs(t *testing.T) {
+	store := mocks.NewMockHostImportJobStore()
+	rows := newTestJob("good-1", "good-2").Rows
+	rows[0].Status = hvs.HostImportRowSucceeded
+	rows[0].Attempts = 1
+	job, err := store.Create(&hvs.HostImportJob{Status: hvs.HostImportJobRunning, Rows: rows})
+	assert.NoError(t, err)
+
+	creator := &fakeHostCreator{}
+	importer, err := NewHostImporter(domain.HostImporterConfig{JobStore: store, HostCreator: creator})
+	assert.NoError(t, err)
+	assert.NoError(t, importer.Resume())
+
+	job = waitForJob(t, store, job.ID)
+	assert.Equal(t, 2, job.Succeeded)
+	assert.Len(t, creator.requests, 1)
+	assert.Equal(t, "good-2", creator.requests[0].HostName)
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package hosttrust
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/services/hosttrust/rules"
+	cf "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/common"
+	flavormodel "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/model"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/host-connector/types"
+	flavorVerifier "github.com/intel-secl/intel-secl/v4/pkg/lib/verifier"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+// DryRunVerifier evaluates a flavorgroup against the manifest of a host as if candidate flavors were added to it.
+// Unlike Verifier it neither uses nor updates the flavor trust cache and never stores a trust report.
+type DryRunVerifier struct {
+	FlavorStore                     domain.FlavorStore
+	FlavorGroupStore                domain.FlavorGroupStore
+	HostStore                       domain.HostStore
+	FlavorVerifier                  flavorVerifier.Verifier
+	SkipFlavorSignatureVerification bool
+}
+
+// candidateFlavorVerifier skips the signature verification of the candidate flavors, they are not signed by HVS
+// before they are created
+type candidateFlavorVerifier struct {
+	flavorVerifier.Verifier
+	candidates map[uuid.UUID]bool
+}
+
+func (cv candidateFlavorVerifier) Verify(hostManifest *types.HostManifest, signedFlavor *hvs.SignedFlavor, skipFlavorSignatureVerification bool) (*hvs.TrustReport, error) {
+	skip := skipFlavorSignatureVerification || cv.candidates[signedFlavor.Flavor.Meta.ID]
+	return cv.Verifier.Verify(hostManifest, signedFlavor, skip)
+}
+
+// Verify returns the trust report of the flavorgroup for the host. The match policies of the flavorgroup decide
+// which flavors are verified. The candidate flavors are verified along with the flavors of the flavorgroup, a
+// candidate replaces the stored flavors of its flavor part when the flavor part has a LATEST match policy.
+func (v *DryRunVerifier) Verify(hostId uuid.UUID, hostData *types.HostManifest, fg hvs.FlavorGroup, candidates []hvs.SignedFlavor) (*hvs.TrustReport, error) {
+	defaultLog.Trace("hosttrust/dry_run:Verify() Entering")
+	defer defaultLog.Trace("hosttrust/dry_run:Verify() Leaving")
+
+	if hostData == nil {
+		return nil, ErrInvalidHostManiFest
+	}
+
+	hostUniqueFlavorParts, err := v.HostStore.RetrieveDistinctUniqueFlavorParts(hostId)
+	if err != nil {
+		return nil, errors.Wrap(err, "hosttrust/dry_run:Verify() Error while retrieving host unique flavor parts")
+	}
+	hostUniqueFlavorPartsMap := make(map[cf.FlavorPart]bool)
+	for _, flavorPart := range hostUniqueFlavorParts {
+		hostUniqueFlavorPartsMap[cf.FlavorPart(flavorPart)] = true
+	}
+
+	reqs, err := NewFlvGrpHostTrustReqs(hostId, hostUniqueFlavorPartsMap, fg, v.FlavorStore, v.FlavorGroupStore, hostData, v.SkipFlavorSignatureVerification)
+	if err != nil {
+		return nil, errors.Wrap(err, "hosttrust/dry_run:Verify() Error while retrieving flavorgroup trust requirements")
+	}
+
+	candidateIds := make(map[uuid.UUID]bool)
+	candidateParts := make(map[cf.FlavorPart]bool)
+	for _, candidate := range candidates {
+		flavorPart, err := GetFlavorPart(candidate)
+		if err != nil {
+			return nil, errors.Wrap(err, "hosttrust/dry_run:Verify() Invalid candidate flavor")
+		}
+		candidateIds[candidate.Flavor.Meta.ID] = true
+		candidateParts[flavorPart] = true
+		// a candidate defines its flavor part in the flavorgroup
+		if policy, exists := reqs.FlavorPartMatchPolicy[flavorPart]; exists && policy.Required == hvs.FlavorRequiredIfDefined {
+			reqs.DefinedAndRequiredFlavorTypes[flavorPart] = true
+		}
+	}
+
+	now := time.Now()
+	latestReqAndDefFlavorTypes := reqs.GetLatestFlavorTypeMap()
+	flavorParts := make([]cf.FlavorPart, 0, len(latestReqAndDefFlavorTypes))
+	for flavorPart := range latestReqAndDefFlavorTypes {
+		flavorParts = append(flavorParts, flavorPart)
+	}
+	hostManifestMap, err := getHostManifestMap(hostData, flavorParts)
+	if err != nil {
+		return nil, errors.Wrap(err, "hosttrust/dry_run:Verify() Error while creating host manifest map")
+	}
+	storedFlavors, err := v.FlavorStore.Search(&models.FlavorVerificationFC{
+		FlavorFC: models.FlavorFilterCriteria{
+			FlavorgroupID: fg.ID,
+		},
+		FlavorPartsWithLatest: latestReqAndDefFlavorTypes,
+		FlavorMeta:            hostManifestMap,
+		Lifecycle:             &models.FlavorLifecycleFC{At: now},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "hosttrust/dry_run:Verify() Error while finding flavors")
+	}
+	deprecatedFlavors, err := getDeprecatedFlavors(v.FlavorStore, storedFlavors, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "hosttrust/dry_run:Verify() Error while retrieving deprecated flavors")
+	}
+
+	flavorsToVerify := append([]hvs.SignedFlavor{}, candidates...)
+	for _, storedFlavor := range storedFlavors {
+		flavorPart, err := GetFlavorPart(storedFlavor)
+		if err != nil {
+			return nil, errors.Wrap(err, "hosttrust/dry_run:Verify() Invalid flavor in flavorgroup")
+		}
+		if candidateIds[storedFlavor.Flavor.Meta.ID] ||
+			(candidateParts[flavorPart] && reqs.FlavorPartMatchPolicy[flavorPart].MatchType == hvs.MatchTypeLatest) {
+			continue
+		}
+		flavorsToVerify = append(flavorsToVerify, storedFlavor)
+	}
+
+	fv := candidateFlavorVerifier{Verifier: v.FlavorVerifier, candidates: candidateIds}
+	trustReport, _, err := mergeFlavorReports(fv, hostId, flavorsToVerify, deprecatedFlavors, hostData, *reqs, v.SkipFlavorSignatureVerification)
+	if err != nil {
+		return nil, errors.Wrap(err, "hosttrust/dry_run:Verify() Error while verifying flavors")
+	}
+
+	for flavorPart := range reqs.DefinedAndRequiredFlavorTypes {
+		rule := rules.NewRequiredFlavorTypeExists(flavorPart)
+		trustReport = rule.Apply(*trustReport)
+	}
+
+	// the ALL_OF candidates are part of the report already, only the stored ones are checked for presence
+	ruleAllOfFlavors := rules.NewAllOfFlavors(reqs.AllOfFlavors, reqs.getAllOfMarkers(), v.SkipFlavorSignatureVerification, v.FlavorVerifier.GetVerifierCerts())
+	trustReport, err = ruleAllOfFlavors.AddFaults(trustReport)
+	if err != nil {
+		return nil, errors.Wrap(err, "hosttrust/dry_run:Verify() Error applying ruleAllOfFlavors")
+	}
+	trustReport.Trusted = trustReport.IsTrusted()
+	return trustReport, nil
+}
+
+// GetFlavorPart returns the flavor part in the meta description of the flavor
+func GetFlavorPart(signedFlavor hvs.SignedFlavor) (cf.FlavorPart, error) {
+	var flavorPart cf.FlavorPart
+	description, _ := signedFlavor.Flavor.Meta.Description[flavormodel.FlavorPart].(string)
+	if err := (&flavorPart).Parse(description); err != nil {
+		return flavorPart, errors.Wrapf(err, "flavor %s has an invalid flavor part", signedFlavor.Flavor.Meta.ID)
+	}
+	return flavorPart, nil
+}
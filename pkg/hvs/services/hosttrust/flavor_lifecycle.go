@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package hosttrust
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants/verifier-rules-and-faults"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	cf "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/common"
+	fm "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/model"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+// getFlavorLifecycleStates returns the effective lifecycle state of the flavors at the given time
+func getFlavorLifecycleStates(fs domain.FlavorStore, flavorIds []uuid.UUID, at time.Time) (map[uuid.UUID]hvs.FlavorLifecycleState, error) {
+	defaultLog.Trace("hosttrust/flavor_lifecycle:getFlavorLifecycleStates() Entering")
+	defer defaultLog.Trace("hosttrust/flavor_lifecycle:getFlavorLifecycleStates() Leaving")
+
+	states := make(map[uuid.UUID]hvs.FlavorLifecycleState)
+	if len(flavorIds) == 0 {
+		return states, nil
+	}
+	lifecycles, err := fs.SearchLifecycles(flavorIds)
+	if err != nil {
+		return nil, errors.Wrap(err, "hosttrust/flavor_lifecycle:getFlavorLifecycleStates() Error while retrieving flavor lifecycles")
+	}
+	for _, lifecycle := range lifecycles {
+		states[lifecycle.FlavorId] = lifecycle.EffectiveState(at)
+	}
+	return states, nil
+}
+
+// getDeprecatedFlavors returns the ids of the flavors that are deprecated at the given time
+func getDeprecatedFlavors(fs domain.FlavorStore, flavors []hvs.SignedFlavor, at time.Time) (map[uuid.UUID]bool, error) {
+	flavorIds := make([]uuid.UUID, 0, len(flavors))
+	for _, signedFlavor := range flavors {
+		flavorIds = append(flavorIds, signedFlavor.Flavor.Meta.ID)
+	}
+	states, err := getFlavorLifecycleStates(fs, flavorIds, at)
+	if err != nil {
+		return nil, err
+	}
+	deprecated := make(map[uuid.UUID]bool)
+	for flavorId, state := range states {
+		if state == hvs.FlavorStateDeprecated {
+			deprecated[flavorId] = true
+		}
+	}
+	return deprecated, nil
+}
+
+// isFlavorInEffect tells if the flavors in the given state take part in the trust status of the hosts
+func isFlavorInEffect(state hvs.FlavorLifecycleState) bool {
+	return state == hvs.FlavorStateActive || state == hvs.FlavorStateDeprecated
+}
+
+// hasFlavorsInEffect tells if the flavorgroup holds a flavor of the flavor part that is in effect. The staged and
+// revoked flavors do not define their flavor part in the flavorgroup.
+func hasFlavorsInEffect(fs domain.FlavorStore, flavorGroupId uuid.UUID, flavorPart cf.FlavorPart, at time.Time) (bool, error) {
+	flavors, err := fs.Search(&models.FlavorVerificationFC{
+		FlavorFC: models.FlavorFilterCriteria{
+			FlavorgroupID: flavorGroupId,
+		},
+		// only one flavor is needed
+		FlavorPartsWithLatest: map[cf.FlavorPart]bool{flavorPart: true},
+		Lifecycle:             &models.FlavorLifecycleFC{At: at},
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "hosttrust/flavor_lifecycle:hasFlavorsInEffect() Error while searching flavors")
+	}
+	return len(flavors) > 0, nil
+}
+
+// deprecatedFlavorWarning reports the use of a deprecated flavor, along with its faults when it does not match
+// the host
+func deprecatedFlavorWarning(flavorId uuid.UUID, report *hvs.TrustReport) hvs.TrustWarning {
+	id := flavorId
+	if report.Trusted {
+		return hvs.TrustWarning{
+			Name:        constants.WarningFlavorDeprecated,
+			Description: fmt.Sprintf("Host is trusted by deprecated flavor %s", flavorId),
+			FlavorId:    &id,
+		}
+	}
+	warning := hvs.TrustWarning{
+		Name:        constants.WarningDeprecatedFlavorFault,
+		Description: fmt.Sprintf("Deprecated flavor %s does not match the host", flavorId),
+		FlavorId:    &id,
+	}
+	for _, result := range report.Results {
+		warning.Faults = append(warning.Faults, result.Faults...)
+	}
+	return warning
+}
+
+// GetHostsAssociatedWithFlavor returns the ids of the hosts whose trust status depends on the flavor
+func GetHostsAssociatedWithFlavor(hStore domain.HostStore, fgStore domain.FlavorGroupStore, flavor *hvs.SignedFlavor) ([]uuid.UUID, error) {
+	defaultLog.Trace("hosttrust/flavor_lifecycle:GetHostsAssociatedWithFlavor() Entering")
+	defer defaultLog.Trace("hosttrust/flavor_lifecycle:GetHostsAssociatedWithFlavor() Leaving")
+
+	id := flavor.Flavor.Meta.ID
+	flavorGroups, err := fgStore.Search(&models.FlavorGroupFilterCriteria{FlavorId: &id})
+	if err != nil {
+		return nil, errors.Wrapf(err, "hosttrust/flavor_lifecycle:GetHostsAssociatedWithFlavor() Failed to retrieve flavorgroups "+
+			"associated with flavor %v for trust re-verification", id)
+	}
+
+	var hostIdsForQueue []uuid.UUID
+	for _, flavorGroup := range flavorGroups {
+		//Host unique flavors are associated with only host_unique flavorgroup and associated with only one host uniquely
+		if flavorGroup.Name == models.FlavorGroupsHostUnique.String() {
+			hardwareUUID, err := uuid.Parse(flavor.Flavor.Meta.Description[fm.HardwareUUID].(string))
+			if err != nil {
+				return nil, errors.Wrap(err, "hosttrust/flavor_lifecycle:GetHostsAssociatedWithFlavor() Failed to parse hardwareUUID")
+			}
+			hosts, err := hStore.Search(&models.HostFilterCriteria{
+				HostHardwareId: hardwareUUID,
+			}, nil)
+			if err != nil {
+				return nil, errors.Wrapf(err, "hosttrust/flavor_lifecycle:GetHostsAssociatedWithFlavor() Failed to retrieve hosts "+
+					"associated with flavor %v for trust re-verification", id)
+			}
+			if len(hosts) > 0 {
+				hostIdsForQueue = append(hostIdsForQueue, hosts[0].Id)
+				break
+			}
+		}
+		hostIds, err := fgStore.SearchHostsByFlavorGroup(flavorGroup.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "hosttrust/flavor_lifecycle:GetHostsAssociatedWithFlavor() Failed to retrieve hosts "+
+				"associated with flavorgroup %v for trust re-verification", flavorGroup.ID)
+		}
+		hostIdsForQueue = append(hostIdsForQueue, hostIds...)
+	}
+	return hostIdsForQueue, nil
+}
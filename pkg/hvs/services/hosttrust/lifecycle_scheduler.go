@@ -0,0 +1,150 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package hosttrust
+
+import (
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain"
+	"github.com/pkg/errors"
+)
+
+// FlavorLifecycleScheduler runs in the background and queues the hosts linked to a flavor for verification when the
+// not_before or not_after date of the flavor is reached, since the flavor then enters or leaves its lifecycle state.
+// The hosts are verified as soon as the dates found by the last search are reached, the upcoming dates are searched
+// again at least every period so that the dates set in between are not missed.
+type FlavorLifecycleScheduler struct {
+	flavorStore      domain.FlavorStore
+	flavorGroupStore domain.FlavorGroupStore
+	hostStore        domain.HostStore
+	hostTrustManager domain.HostTrustManager
+	period           time.Duration
+	fromTime         time.Time
+	quit             chan struct{}
+	stopOnce         sync.Once
+}
+
+func NewFlavorLifecycleScheduler(fs domain.FlavorStore, fgs domain.FlavorGroupStore, hs domain.HostStore,
+	htm domain.HostTrustManager, period time.Duration) *FlavorLifecycleScheduler {
+	return &FlavorLifecycleScheduler{
+		flavorStore:      fs,
+		flavorGroupStore: fgs,
+		hostStore:        hs,
+		hostTrustManager: htm,
+		period:           period,
+		// the first search starts from the epoch so that the dates reached while HVS was stopped are not missed
+		fromTime: time.Unix(0, 0).UTC(),
+		quit:     make(chan struct{}),
+	}
+}
+
+func (scheduler *FlavorLifecycleScheduler) Run() error {
+	defaultLog.Trace("hosttrust/lifecycle_scheduler:Run() Entering")
+	defer defaultLog.Trace("hosttrust/lifecycle_scheduler:Run() Leaving")
+
+	if scheduler.period <= 0 {
+		return errors.New("hosttrust/lifecycle_scheduler:Run() The flavor lifecycle scheduler period must be positive")
+	}
+
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				defaultLog.Errorf("Panic occurred: %+v", err)
+				defaultLog.Error(string(debug.Stack()))
+			}
+		}()
+		for {
+			wait, err := scheduler.queueTransitions(time.Now().UTC())
+			if err != nil {
+				// log any errors, but do not stop scheduling the verifications
+				defaultLog.WithError(err).Error("hosttrust/lifecycle_scheduler:Run() Error while scheduling the " +
+					"verification of the hosts on flavor lifecycle dates")
+				wait = scheduler.period
+			}
+
+			select {
+			case <-time.After(wait):
+				// continue with the loop and queue the hosts of the flavors whose dates are reached
+			case <-scheduler.quit:
+				defaultLog.Info("The flavor lifecycle scheduler has been stopped and will now exit")
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (scheduler *FlavorLifecycleScheduler) Stop() error {
+	scheduler.stopOnce.Do(func() {
+		close(scheduler.quit)
+	})
+	return nil
+}
+
+// queueTransitions queues the hosts linked to the flavors whose dates have been reached since the last call and
+// returns the time to wait for the next date, bounded by the period
+func (scheduler *FlavorLifecycleScheduler) queueTransitions(now time.Time) (time.Duration, error) {
+	defaultLog.Trace("hosttrust/lifecycle_scheduler:queueTransitions() Entering")
+	defer defaultLog.Trace("hosttrust/lifecycle_scheduler:queueTransitions() Leaving")
+
+	reached, err := scheduler.flavorStore.SearchLifecycleTransitions(scheduler.fromTime, now)
+	if err != nil {
+		return 0, errors.Wrap(err, "hosttrust/lifecycle_scheduler:queueTransitions() Error while searching the "+
+			"flavor lifecycle dates reached")
+	}
+
+	queued := map[uuid.UUID]bool{}
+	var hostIds []uuid.UUID
+	for _, lifecycle := range reached {
+		// the other flavors are still handled when the hosts of a flavor cannot be found
+		signedFlavor, err := scheduler.flavorStore.Retrieve(lifecycle.FlavorId)
+		if err != nil {
+			defaultLog.WithError(err).Errorf("hosttrust/lifecycle_scheduler:queueTransitions() Error while "+
+				"retrieving flavor %s", lifecycle.FlavorId)
+			continue
+		}
+		flavorHostIds, err := GetHostsAssociatedWithFlavor(scheduler.hostStore, scheduler.flavorGroupStore, signedFlavor)
+		if err != nil {
+			defaultLog.WithError(err).Errorf("hosttrust/lifecycle_scheduler:queueTransitions() Error while "+
+				"retrieving the hosts of flavor %s", lifecycle.FlavorId)
+			continue
+		}
+		for _, hostId := range flavorHostIds {
+			if !queued[hostId] {
+				queued[hostId] = true
+				hostIds = append(hostIds, hostId)
+			}
+		}
+	}
+	if len(hostIds) > 0 {
+		// the host data does not change with the flavor lifecycle, only the flavors in effect do
+		if err = scheduler.hostTrustManager.VerifyHostsAsync(hostIds, false, false); err != nil {
+			return 0, errors.Wrap(err, "hosttrust/lifecycle_scheduler:queueTransitions() Error while queueing the "+
+				"hosts for verification")
+		}
+		defaultLog.Infof("hosttrust/lifecycle_scheduler:queueTransitions() Queued %d hosts for verification on "+
+			"the lifecycle dates of %d flavors", len(hostIds), len(reached))
+	}
+	scheduler.fromTime = now
+
+	upcoming, err := scheduler.flavorStore.SearchLifecycleTransitions(now, now.Add(scheduler.period))
+	if err != nil {
+		return 0, errors.Wrap(err, "hosttrust/lifecycle_scheduler:queueTransitions() Error while searching the "+
+			"upcoming flavor lifecycle dates")
+	}
+	next := now.Add(scheduler.period)
+	for _, lifecycle := range upcoming {
+		for _, date := range []*time.Time{lifecycle.NotBefore, lifecycle.NotAfter} {
+			if date != nil && date.After(now) && date.Before(next) {
+				next = *date
+			}
+		}
+	}
+	return next.Sub(now), nil
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package hosttrust_test
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/mocks"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/services/hosttrust"
+	htmocks "github.com/intel-secl/intel-secl/v4/pkg/hvs/services/hosttrust/mocks"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// flavorgroupByFlavorStore finds the flavorgroup of a flavor, which the flavorgroup store mock does not
+type flavorgroupByFlavorStore struct {
+	*mocks.MockFlavorgroupStore
+	flavorgroups map[uuid.UUID]hvs.FlavorGroup
+}
+
+func (store *flavorgroupByFlavorStore) Search(criteria *models.FlavorGroupFilterCriteria) ([]hvs.FlavorGroup, error) {
+	if criteria != nil && criteria.FlavorId != nil {
+		if flavorgroup, ok := store.flavorgroups[*criteria.FlavorId]; ok {
+			return []hvs.FlavorGroup{flavorgroup}, nil
+		}
+		return nil, nil
+	}
+	return store.MockFlavorgroupStore.Search(criteria)
+}
+
+// queueRecorder sends the hosts queued for verification on a channel
+type queueRecorder struct {
+	htmocks.MockHostTrustManager
+	queued chan []uuid.UUID
+}
+
+func (recorder *queueRecorder) VerifyHostsAsync(hostIds []uuid.UUID, fetchHostData, preferHashMatch bool) error {
+	recorder.queued <- hostIds
+	return nil
+}
+
+var _ = Describe("FlavorLifecycleScheduler", func() {
+	var flavorStore *mocks.MockFlavorStore
+	var flavorgroupStore *flavorgroupByFlavorStore
+	var recorder *queueRecorder
+
+	revokedFlavorId := uuid.MustParse("c36b5412-8c02-4e08-8a74-8bfa40425cf3")
+	activatedFlavorId := uuid.MustParse("e6612219-bbd5-4259-8c7e-991e43729a86")
+	revokedHostId := uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2")
+	activatedHostId := uuid.MustParse("e57e5ea0-d465-461e-882d-1600090caa0d")
+
+	BeforeEach(func() {
+		flavorStore = mocks.NewMockFlavorStore()
+		revokedGroupId, activatedGroupId := uuid.New(), uuid.New()
+		flavorgroupStore = &flavorgroupByFlavorStore{
+			MockFlavorgroupStore: mocks.NewFakeFlavorgroupStore(),
+			flavorgroups: map[uuid.UUID]hvs.FlavorGroup{
+				revokedFlavorId:   {ID: revokedGroupId, Name: "automatic"},
+				activatedFlavorId: {ID: activatedGroupId, Name: "staged"},
+			},
+		}
+		flavorgroupStore.HostFlavorgroupStore = []*hvs.HostFlavorgroup{
+			{HostId: revokedHostId, FlavorgroupId: revokedGroupId},
+			{HostId: activatedHostId, FlavorgroupId: activatedGroupId},
+		}
+		recorder = &queueRecorder{queued: make(chan []uuid.UUID, 2)}
+	})
+
+	Context("Flavors with lifecycle dates", func() {
+		It("Should queue the hosts of the flavors when their dates are reached", func() {
+			notAfter := time.Now().UTC().Add(-time.Hour)
+			notBefore := time.Now().UTC().Add(300 * time.Millisecond)
+			flavorStore.FlavorLifecycles = map[uuid.UUID]hvs.FlavorLifecycle{
+				revokedFlavorId:   {FlavorId: revokedFlavorId, State: hvs.FlavorStateActive, NotAfter: &notAfter},
+				activatedFlavorId: {FlavorId: activatedFlavorId, State: hvs.FlavorStateActive, NotBefore: &notBefore},
+			}
+
+			// the period is far longer than the test so that the hosts are only queued at the dates of the flavors
+			scheduler := hosttrust.NewFlavorLifecycleScheduler(flavorStore, flavorgroupStore, mocks.NewMockHostStore(),
+				recorder, time.Hour)
+			Expect(scheduler.Run()).To(Succeed())
+			defer func() {
+				Expect(scheduler.Stop()).To(Succeed())
+			}()
+
+			// the dates reached before the scheduler is started are not missed
+			Eventually(recorder.queued).Should(Receive(Equal([]uuid.UUID{revokedHostId})))
+			Consistently(recorder.queued, 200*time.Millisecond).ShouldNot(Receive())
+			Eventually(recorder.queued).Should(Receive(Equal([]uuid.UUID{activatedHostId})))
+			Expect(time.Now().UTC().Before(notBefore)).To(BeFalse())
+		})
+	})
+
+	Context("Run with an invalid period", func() {
+		It("Should fail", func() {
+			scheduler := hosttrust.NewFlavorLifecycleScheduler(flavorStore, flavorgroupStore, mocks.NewMockHostStore(),
+				recorder, 0)
+			Expect(scheduler.Run()).NotTo(Succeed())
+		})
+	})
+})
@@ -9,6 +9,7 @@ import (
 	"encoding/xml"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
@@ -17,6 +18,7 @@ import (
 	cf "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/common"
 	flavormodel "github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/model"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/host-connector/types"
+	flavorVerifier "github.com/intel-secl/intel-secl/v4/pkg/lib/verifier"
 	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
 	taModel "github.com/intel-secl/intel-secl/v4/pkg/model/ta"
 	"github.com/pkg/errors"
@@ -106,6 +108,7 @@ func (v *Verifier) createTrustReport(hostId uuid.UUID, hostData *types.HostManif
 	}
 	if !trustCache.isTrustCacheEmpty() {
 		trustReport.AddResults(trustCache.trustReport.Results)
+		trustReport.AddWarnings(trustCache.trustReport.Warnings)
 	}
 
 	for flavorPart := range reqs.DefinedAndRequiredFlavorTypes {
@@ -147,6 +150,33 @@ func (v *Verifier) verifyFlavors(hostID uuid.UUID, flavors []hvs.SignedFlavor, h
 	defaultLog.Trace("hosttrust/trust_report:verifyFlavors() Entering")
 	defer defaultLog.Trace("hosttrust/trust_report:verifyFlavors() Leaving")
 
+	deprecatedFlavors, err := getDeprecatedFlavors(v.FlavorStore, flavors, time.Now())
+	if err != nil {
+		return &hvs.TrustReport{}, err
+	}
+	collectiveTrustReport, newTrustCaches, err := mergeFlavorReports(v.FlavorVerifier, hostID, flavors, deprecatedFlavors, hostData, hostTrustReqs, v.SkipFlavorSignatureVerification)
+	if err != nil {
+		return &hvs.TrustReport{}, err
+	}
+	if len(newTrustCaches) == 0 {
+		return collectiveTrustReport, nil
+	}
+	// save the trust cache // ignore error since it is just a cache.
+	if _, err := v.HostStore.AddTrustCacheFlavors(hostID, newTrustCaches); err != nil {
+		log.Error("hosttrust/trust_report:verifyFlavors() error while adding flavor trust cache to store for host id ", hostID, "error - ", err)
+	}
+
+	return collectiveTrustReport, nil
+}
+
+// mergeFlavorReports verifies the flavors against the host manifest and merges the individual reports into a
+// collective report as required by the match policies. It returns the collective report along with the ids of
+// the flavors it is made of. The use of the deprecated flavors is reported as warnings and the deprecated flavors
+// that do not match the host are left out of the collective report.
+func mergeFlavorReports(fv flavorVerifier.Verifier, hostID uuid.UUID, flavors []hvs.SignedFlavor, deprecatedFlavors map[uuid.UUID]bool, hostData *types.HostManifest, hostTrustReqs flvGrpHostTrustReqs, skipFlavorSignatureVerification bool) (*hvs.TrustReport, []uuid.UUID, error) {
+	defaultLog.Trace("hosttrust/trust_report:mergeFlavorReports() Entering")
+	defer defaultLog.Trace("hosttrust/trust_report:mergeFlavorReports() Leaving")
+
 	collectiveTrustReport := hvs.TrustReport{}
 
 	// need to create a map to hold all the untrusted individual reports and group them by the flavor part/type.
@@ -158,6 +188,7 @@ func (v *Verifier) verifyFlavors(hostID uuid.UUID, flavors []hvs.SignedFlavor, h
 		flavorPartMap: make(map[cf.FlavorPart][]flavorReport),
 	}
 
+	var warnings []hvs.TrustWarning
 	newTrustCaches := make([]uuid.UUID, 0, len(flavors))
 	for _, signedFlavor := range flavors {
 		for _, flvMatchPolicy := range hostTrustReqs.FlavorMatchPolicies {
@@ -167,9 +198,16 @@ func (v *Verifier) verifyFlavors(hostID uuid.UUID, flavors []hvs.SignedFlavor, h
 			flvPart := signedFlavor.Flavor.Meta.Description[flavormodel.FlavorPart].(string)
 			if flvPart == flvMatchPolicy.FlavorPart.String() {
 
-				individualTrustReport, err := v.FlavorVerifier.Verify(hostData, &signedFlavor, v.SkipFlavorSignatureVerification)
+				individualTrustReport, err := fv.Verify(hostData, &signedFlavor, skipFlavorSignatureVerification)
 				if err != nil {
-					return &hvs.TrustReport{}, errors.Wrap(err, "hosttrust/trust_report:verifyFlavors() Error verifying flavor")
+					return &hvs.TrustReport{}, nil, errors.Wrap(err, "hosttrust/trust_report:mergeFlavorReports() Error verifying flavor")
+				}
+				if deprecatedFlavors[signedFlavor.Flavor.Meta.ID] {
+					warnings = append(warnings, deprecatedFlavorWarning(signedFlavor.Flavor.Meta.ID, individualTrustReport))
+					if !individualTrustReport.Trusted {
+						log.Debugf("Deprecated flavor [%s] did not match host [%s]", signedFlavor.Flavor.Meta.ID, hostID)
+						continue
+					}
 				}
 				if individualTrustReport.Trusted {
 					if reflect.DeepEqual(collectiveTrustReport, hvs.TrustReport{}) {
@@ -202,11 +240,11 @@ func (v *Verifier) verifyFlavors(hostID uuid.UUID, flavors []hvs.SignedFlavor, h
 	}
 
 	for flavPart, flavPartReports := range untrusted.flavorPartMap {
-		log.Debug("hosttrust/trust_report:verifyFlavors() Processing untrusted trust report for flavor part:", flavPart)
+		log.Debug("hosttrust/trust_report:mergeFlavorReports() Processing untrusted trust report for flavor part:", flavPart)
 		if hostTrustReqs.DefinedAndRequiredFlavorTypes[flavPart] &&
 			len(collectiveTrustReport.Results) == 0 || !collectiveTrustReport.IsTrustedForMarker(flavPart.String()) {
 			if matchPolicy, matchPolicyExists := hostTrustReqs.FlavorPartMatchPolicy[flavPart]; matchPolicyExists && matchPolicy.MatchType == hvs.MatchTypeAllOf {
-				log.Debug("hosttrust/trust_report:verifyFlavors() Flavor Part :", flavPart, " requires ALL_OF policy - each untrusted flavor needs to be added to collective report")
+				log.Debug("hosttrust/trust_report:mergeFlavorReports() Flavor Part :", flavPart, " requires ALL_OF policy - each untrusted flavor needs to be added to collective report")
 				for _, flavorReport := range flavPartReports {
 					log.Debug("Adding untrusted trust report to collective report for ALL_OF flavor part", flavPart, " with flavor ID ", flavorReport.id)
 					collectiveTrustReport.AddResults(flavorReport.report.Results)
@@ -215,7 +253,7 @@ func (v *Verifier) verifyFlavors(hostID uuid.UUID, flavors []hvs.SignedFlavor, h
 
 			} else if matchPolicy, matchPolicyExists := hostTrustReqs.FlavorPartMatchPolicy[flavPart]; matchPolicyExists && (matchPolicy.MatchType == hvs.MatchTypeAnyOf ||
 				matchPolicy.MatchType == hvs.MatchTypeLatest) {
-				log.Debug("hosttrust/trust_report:verifyFlavors() Flavor part requires ANY_OF policy, untrusted flavor report with least faults must be added to the collective report", flavPart)
+				log.Debug("hosttrust/trust_report:mergeFlavorReports() Flavor part requires ANY_OF policy, untrusted flavor report with least faults must be added to the collective report", flavPart)
 				var leastFaultReport *flavorReport
 				for _, flavorReport := range flavPartReports {
 
@@ -225,7 +263,7 @@ func (v *Verifier) verifyFlavors(hostID uuid.UUID, flavors []hvs.SignedFlavor, h
 					}
 				}
 				if leastFaultReport != nil {
-					log.Debug("hosttrust/trust_report:verifyFlavors() Adding untrusted trust report to collective report for ANY_OF flavor part, ",
+					log.Debug("hosttrust/trust_report:mergeFlavorReports() Adding untrusted trust report to collective report for ANY_OF flavor part, ",
 						leastFaultReport.flavorPart, "with flavor ID ", leastFaultReport.id)
 					collectiveTrustReport.AddResults(leastFaultReport.report.Results)
 					newTrustCaches = append(newTrustCaches, leastFaultReport.id)
@@ -237,14 +275,12 @@ func (v *Verifier) verifyFlavors(hostID uuid.UUID, flavors []hvs.SignedFlavor, h
 		//TODO - check if we return an error here
 		return &hvs.TrustReport{
 			HostManifest: *hostData,
-		}, nil
-	}
-	// save the trust cache // ignore error since it is just a cache.
-	if _, err := v.HostStore.AddTrustCacheFlavors(hostID, newTrustCaches); err != nil {
-		log.Error("hosttrust/trust_report:verifyFlavors() error while adding flavor trust cache to store for host id ", hostID, "error - ", err)
+			Warnings:     warnings,
+		}, nil, nil
 	}
 
-	return &collectiveTrustReport, nil
+	collectiveTrustReport.AddWarnings(warnings)
+	return &collectiveTrustReport, newTrustCaches, nil
 }
 
 // FlavorVerify.java: 684
@@ -259,6 +295,7 @@ func (v *Verifier) findFlavors(flavorGroupID uuid.UUID, latestReqAndDefFlavorTyp
 		},
 		FlavorPartsWithLatest: latestReqAndDefFlavorTypes,
 		FlavorMeta:            hostManifestMap,
+		Lifecycle:             &models.FlavorLifecycleFC{At: time.Now()},
 	}
 
 	signedFlavors, err := v.FlavorStore.Search(&flvrFilterCriteria)
@@ -16,6 +16,7 @@ import (
 	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
 	"github.com/pkg/errors"
 	"reflect"
+	"time"
 )
 
 type flvGrpHostTrustReqs struct {
@@ -43,6 +44,7 @@ func NewFlvGrpHostTrustReqs(hostId uuid.UUID, definedUniqueFlavorParts map[cf.Fl
 	}
 
 	var fgRequirePolicyMap map[hvs.FlavorRequiredPolicy][]cf.FlavorPart
+	now := time.Now()
 
 	reqs.FlavorPartMatchPolicy, reqs.MatchTypeFlavorParts, fgRequirePolicyMap = fg.GetMatchPolicyMaps()
 
@@ -64,10 +66,23 @@ func NewFlvGrpHostTrustReqs(hostId uuid.UUID, definedUniqueFlavorParts map[cf.Fl
 			},
 			FlavorMeta:            hostManifestMap,
 			FlavorPartsWithLatest: nil,
+			Lifecycle:             &models.FlavorLifecycleFC{At: now},
 		})
 		if err != nil {
 			return nil, errors.Wrap(err, "error searching flavor for host id "+hostId.String())
 		}
+		// the faults of the deprecated flavors are reported as warnings, they are not required to match the host
+		deprecatedFlavors, err := getDeprecatedFlavors(fs, reqs.AllOfFlavors, now)
+		if err != nil {
+			return nil, errors.Wrap(err, "error retrieving deprecated flavors for host id "+hostId.String())
+		}
+		allOfFlavors := make([]hvs.SignedFlavor, 0, len(reqs.AllOfFlavors))
+		for _, signedFlavor := range reqs.AllOfFlavors {
+			if !deprecatedFlavors[signedFlavor.Flavor.Meta.ID] {
+				allOfFlavors = append(allOfFlavors, signedFlavor)
+			}
+		}
+		reqs.AllOfFlavors = allOfFlavors
 		defaultLog.Debugf("From Flavorgroup %v, %v Flavors retrieved with ALL_OF policy", fg.ID, len(reqs.AllOfFlavors))
 	}
 
@@ -88,7 +103,14 @@ func NewFlvGrpHostTrustReqs(hostId uuid.UUID, definedUniqueFlavorParts map[cf.Fl
 	// those flavor parts that are required if defined
 	for _, part := range reqIfdefPartsMap {
 		if _, exists := flavorPartsInFlavorGroup[part]; exists {
-			reqs.DefinedAndRequiredFlavorTypes[part] = true
+			// staged and revoked flavors do not define their flavor part
+			inEffect, err := hasFlavorsInEffect(fs, fg.ID, part, now)
+			if err != nil {
+				return nil, errors.Wrap(err, "error searching flavors in effect in flavorgroup")
+			}
+			if inEffect {
+				reqs.DefinedAndRequiredFlavorTypes[part] = true
+			}
 		}
 	}
 
@@ -19,6 +19,7 @@ import (
 	taModel "github.com/intel-secl/intel-secl/v4/pkg/model/ta"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"time"
 )
 
 var ErrInvalidHostManiFest = errors.New("invalid host data")
@@ -142,14 +143,14 @@ func (v *Verifier) Verify(hostId uuid.UUID, hostData *types.HostManifest, newDat
 		if err != nil {
 			return nil, errors.Wrap(err, "hosttrust/verifier:Verify() Error while retrieving NewFlvGrpHostTrustReqs")
 		}
-		fgCachedFlavors, err := v.getCachedFlavors(hostId, (fg).ID)
+		fgCachedFlavors, deprecatedCachedFlavors, err := v.getCachedFlavors(hostId, (fg).ID)
 		if err != nil {
 			return nil, errors.Wrap(err, "hosttrust/verifier:Verify() Error while retrieving getCachedFlavors")
 		}
 
 		var fgTrustCache hostTrustCache
 		if len(fgCachedFlavors) > 0 {
-			fgTrustCache, err = v.validateCachedFlavors(hostId, hostData, fgCachedFlavors)
+			fgTrustCache, err = v.validateCachedFlavors(hostId, hostData, fgCachedFlavors, deprecatedCachedFlavors)
 			if err != nil {
 				return nil, errors.Wrap(err, "hosttrust/verifier:Verify() Error while validating cache")
 			}
@@ -167,6 +168,13 @@ func (v *Verifier) Verify(hostId uuid.UUID, hostData *types.HostManifest, newDat
 		log.Debug("hosttrust/verifier:Verify() Trust status for host id ", hostId, " for flavorgroup ", fg.ID, " is ", fgTrustReport.IsTrusted())
 		// append the results
 		finalTrustReport.AddResults(fgTrustReport.Results)
+		finalTrustReport.AddWarnings(fgTrustReport.Warnings)
+
+		shadowResults, err := v.verifyShadowFlavors(hostData, *fgTrustReqs)
+		if err != nil {
+			return nil, errors.Wrap(err, "hosttrust/verifier:Verify() Error while verifying shadow flavors")
+		}
+		finalTrustReport.ShadowResults = append(finalTrustReport.ShadowResults, shadowResults...)
 	}
 	// create a new report if we actually have any results and either the Final Report is untrusted or
 	// we have new Data from the host and therefore need to update based on the new report.
@@ -195,26 +203,47 @@ func (v *Verifier) Verify(hostId uuid.UUID, hostData *types.HostManifest, newDat
 	return hvsReport, nil
 }
 
-func (v *Verifier) getCachedFlavors(hostId uuid.UUID, flavGrpId uuid.UUID) ([]hvs.SignedFlavor, error) {
+// getCachedFlavors returns the cached flavors that are still in effect along with the ids of the deprecated ones,
+// the other flavors are removed from the trust cache
+func (v *Verifier) getCachedFlavors(hostId uuid.UUID, flavGrpId uuid.UUID) ([]hvs.SignedFlavor, map[uuid.UUID]bool, error) {
 	defaultLog.Trace("hosttrust/verifier:getCachedFlavors() Entering")
 	defer defaultLog.Trace("hosttrust/verifier:getCachedFlavors() Leaving")
 	// retrieve the IDs of the trusted flavors from the host store
 	if flIds, err := v.HostStore.RetrieveTrustCacheFlavors(hostId, flavGrpId); err != nil && len(flIds) == 0 {
-		return nil, errors.Wrap(err, "hosttrust/verifier:Verify() Error while retrieving TrustCacheFlavors")
+		return nil, nil, errors.Wrap(err, "hosttrust/verifier:Verify() Error while retrieving TrustCacheFlavors")
 	} else {
+		states, err := getFlavorLifecycleStates(v.FlavorStore, flIds, time.Now())
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "hosttrust/verifier:getCachedFlavors() Error while retrieving flavor lifecycle states")
+		}
 		result := make([]hvs.SignedFlavor, 0, len(flIds))
+		deprecatedFlavors := make(map[uuid.UUID]bool)
+		var trustCachesToDelete []uuid.UUID
 		for _, flvId := range flIds {
+			if state, ok := states[flvId]; ok && !isFlavorInEffect(state) {
+				trustCachesToDelete = append(trustCachesToDelete, flvId)
+				continue
+			}
 			if flv, err := v.FlavorStore.Retrieve(flvId); err == nil {
 				result = append(result, *flv)
+				deprecatedFlavors[flvId] = states[flvId] == hvs.FlavorStateDeprecated
 			}
 		}
-		return result, nil
+		if len(trustCachesToDelete) > 0 {
+			// remove cache entries for flavors that are staged or revoked
+			err := v.HostStore.RemoveTrustCacheFlavors(hostId, trustCachesToDelete)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "hosttrust/verifier:getCachedFlavors() could not remove trust cache flavors")
+			}
+		}
+		return result, deprecatedFlavors, nil
 	}
 }
 
 func (v *Verifier) validateCachedFlavors(hostId uuid.UUID,
 	hostData *types.HostManifest,
-	cachedFlavors []hvs.SignedFlavor) (hostTrustCache, error) {
+	cachedFlavors []hvs.SignedFlavor,
+	deprecatedFlavors map[uuid.UUID]bool) (hostTrustCache, error) {
 	defaultLog.Trace("hosttrust/verifier:validateCachedFlavors() Entering")
 	defer defaultLog.Trace("hosttrust/verifier:validateCachedFlavors() Leaving")
 
@@ -232,6 +261,9 @@ func (v *Verifier) validateCachedFlavors(hostId uuid.UUID,
 		if report.Trusted {
 			htc.trustedFlavors = append(htc.trustedFlavors, cachedFlavor.Flavor)
 			collectiveReport.Results = append(collectiveReport.Results, report.Results...)
+			if deprecatedFlavors[cachedFlavor.Flavor.Meta.ID] {
+				collectiveReport.AddWarnings([]hvs.TrustWarning{deprecatedFlavorWarning(cachedFlavor.Flavor.Meta.ID, report)})
+			}
 		} else {
 			trustCachesToDelete = append(trustCachesToDelete, cachedFlavor.Flavor.Meta.ID)
 		}
@@ -247,6 +279,47 @@ func (v *Verifier) validateCachedFlavors(hostId uuid.UUID,
 	return htc, nil
 }
 
+// verifyShadowFlavors verifies the flavors of the flavorgroup that are evaluated in shadow, i.e. the staged flavors
+// and the ones that are not in effect yet. Their results neither affect the trust status nor update the trust cache.
+func (v *Verifier) verifyShadowFlavors(hostData *types.HostManifest, reqs flvGrpHostTrustReqs) ([]hvs.RuleResult, error) {
+	defaultLog.Trace("hosttrust/verifier:verifyShadowFlavors() Entering")
+	defer defaultLog.Trace("hosttrust/verifier:verifyShadowFlavors() Leaving")
+
+	flavorParts := make([]common.FlavorPart, 0, len(reqs.FlavorPartMatchPolicy))
+	for flavorPart := range reqs.FlavorPartMatchPolicy {
+		flavorParts = append(flavorParts, flavorPart)
+	}
+	if len(flavorParts) == 0 {
+		return nil, nil
+	}
+	hostManifestMap, err := getHostManifestMap(hostData, flavorParts)
+	if err != nil {
+		return nil, errors.Wrap(err, "hosttrust/verifier:verifyShadowFlavors() Error while creating host manifest map")
+	}
+	shadowFlavors, err := v.FlavorStore.Search(&models.FlavorVerificationFC{
+		FlavorFC: models.FlavorFilterCriteria{
+			FlavorgroupID: reqs.FlavorGroupId,
+			FlavorParts:   flavorParts,
+		},
+		FlavorMeta: hostManifestMap,
+		Lifecycle:  &models.FlavorLifecycleFC{At: time.Now(), Shadow: true},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "hosttrust/verifier:verifyShadowFlavors() Error while finding flavors")
+	}
+
+	var shadowResults []hvs.RuleResult
+	for _, shadowFlavor := range shadowFlavors {
+		report, err := v.FlavorVerifier.Verify(hostData, &shadowFlavor, v.SkipFlavorSignatureVerification)
+		if err != nil {
+			return nil, errors.Wrap(err, "hosttrust/verifier:verifyShadowFlavors() Error from flavor verifier")
+		}
+		log.Debugf("hosttrust/verifier:verifyShadowFlavors() Shadow flavor %s trusted: %t", shadowFlavor.Flavor.Meta.ID, report.Trusted)
+		shadowResults = append(shadowResults, report.Results...)
+	}
+	return shadowResults, nil
+}
+
 func (v *Verifier) refreshTrustReport(hostID uuid.UUID, cache *models.QuoteReportCache) (*models.HVSReport, error) {
 	defaultLog.Trace("hosttrust/verifier:refreshTrustReport() Entering")
 	defer defaultLog.Trace("hosttrust/verifier:refreshTrustReport() Leaving")
@@ -167,12 +167,13 @@ func (a *App) setupTaskRunner() (*setup.Runner, error) {
 		Subject: pkix.Name{
 			CommonName: viper.GetString("tls-common-name"),
 		},
-		SanList:       viper.GetString("tls-san-list"),
-		CertType:      "tls",
-		CaCertDirPath: constants.TrustedCaCertsDir,
-		ConsoleWriter: a.consoleWriter(),
-		CmsBaseURL:    viper.GetString("cms-base-url"),
-		BearerToken:   viper.GetString("bearer-token"),
+		SanList:         viper.GetString("tls-san-list"),
+		CertType:        "tls",
+		CaCertDirPath:   constants.TrustedCaCertsDir,
+		ConsoleWriter:   a.consoleWriter(),
+		CmsBaseURL:      viper.GetString("cms-base-url"),
+		BearerToken:     viper.GetString("bearer-token"),
+		RevocationCheck: revocationCheckConfig(),
 	})
 	runner.AddTask("update-service-config", "", &tasks.UpdateServiceConfig{
 		ServiceConfig: commConfig.ServiceConfig{
@@ -248,11 +249,12 @@ func (a *App) downloadCertTask(certType string) setup.Task {
 		Subject: pkix.Name{
 			CommonName: viper.GetString(certType + "-common-name"),
 		},
-		CertType:      certTypeReq,
-		CaCertDirPath: constants.TrustedCaCertsDir,
-		ConsoleWriter: a.consoleWriter(),
-		CmsBaseURL:    viper.GetString("cms-base-url"),
-		BearerToken:   viper.GetString("bearer-token"),
+		CertType:        certTypeReq,
+		CaCertDirPath:   constants.TrustedCaCertsDir,
+		ConsoleWriter:   a.consoleWriter(),
+		CmsBaseURL:      viper.GetString("cms-base-url"),
+		BearerToken:     viper.GetString("bearer-token"),
+		RevocationCheck: revocationCheckConfig(),
 	}
 }
 
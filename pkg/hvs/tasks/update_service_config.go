@@ -40,10 +40,19 @@ var envHelp = map[string]string{
 	"AAS_BASE_URL":                           "AAS Base URL",
 	"HRRS_REFRESH_PERIOD":                    "Host report refresh service period",
 	"VCSS_REFRESH_PERIOD":                    "VCenter refresh service period",
+	"EVENTS_WEBHOOK_MAX_ATTEMPTS":            "Maximum number of delivery attempts of a trust event to a webhook",
+	"EVENTS_WEBHOOK_RETRY_DELAY":             "Delay before retrying a failed trust event delivery, doubled on each retry",
+	"EVENTS_WEBHOOK_TIMEOUT":                 "Timeout of a trust event delivery to a webhook",
 	"FVS_NUMBER_OF_VERIFIERS":                "Number of Flavor verification verifier threads",
 	"FVS_NUMBER_OF_DATA_FETCHERS":            "Number of Flavor verification data fetcher threads",
 	"FVS_SKIP_FLAVOR_SIGNATURE_VERIFICATION": "Skips flavor signature verification when set to true",
 	"HOST_TRUST_CACHE_THRESHOLD":             "Maximum number of entries to be cached in the Trust/Flavor caches",
+	"REVOCATION_CHECK_ENABLED":               "Checks the revocation status of the AIK, tag and flavor signing certificates when set to true",
+	"REVOCATION_CHECK_CRL_FILE":              "PEM or DER encoded CRL file used by the revocation check",
+	"REVOCATION_CHECK_FETCH_CRL":             "Downloads the CRLs from the distribution points of the certificates when set to true",
+	"REVOCATION_CHECK_OCSP":                  "Queries the OCSP responders of the certificates when set to true",
+	"REVOCATION_CHECK_CRL_CACHE_DURATION":    "Maximum duration the downloaded CRLs are cached",
+	"REVOCATION_CHECK_FAIL_OPEN":             "Accepts the certificates whose revocation status can not be determined when set to true",
 	"SERVER_PORT":                            "The Port on which Server listens to",
 	"SERVER_READ_TIMEOUT":                    "Request Read Timeout Duration in Seconds",
 	"SERVER_READ_HEADER_TIMEOUT":             "Request Read Header Timeout Duration in Seconds",
@@ -84,12 +93,29 @@ func (uc UpdateServiceConfig) Run() error {
 	(*uc.AppConfig).VCSS = config.VCSSConfig{
 		RefreshPeriod: viper.GetDuration(constants.VcssRefreshPeriod),
 	}
+	(*uc.AppConfig).Events = config.EventsConfig{
+		BufferSize:         viper.GetInt(constants.EventsBufferSize),
+		WebhookMaxAttempts: viper.GetInt(constants.EventsWebhookMaxAttempts),
+		WebhookRetryDelay:  viper.GetDuration(constants.EventsWebhookRetryDelay),
+		WebhookTimeout:     viper.GetDuration(constants.EventsWebhookTimeout),
+	}
+	(*uc.AppConfig).HostImport = config.HostImportConfig{
+		Concurrency: viper.GetInt(constants.HostImportConcurrency),
+	}
 	(*uc.AppConfig).FVS = config.FVSConfig{
 		NumberOfVerifiers:               viper.GetInt(constants.FvsNumberOfVerifiers),
 		NumberOfDataFetchers:            viper.GetInt(constants.FvsNumberOfDataFetchers),
 		SkipFlavorSignatureVerification: viper.GetBool(constants.FvsSkipFlavorSignatureVerification),
 		HostTrustCacheThreshold:         viper.GetInt(constants.FvsHostTrustCacheThreshold),
 	}
+	(*uc.AppConfig).RevocationCheck = commConfig.RevocationCheckConfig{
+		Enabled:          viper.GetBool(constants.RevocationCheckEnabled),
+		CrlFile:          viper.GetString(constants.RevocationCheckCrlFile),
+		FetchCrl:         viper.GetBool(constants.RevocationCheckFetchCrl),
+		Ocsp:             viper.GetBool(constants.RevocationCheckOcsp),
+		CrlCacheDuration: viper.GetDuration(constants.RevocationCheckCrlCacheDuration),
+		FailOpen:         viper.GetBool(constants.RevocationCheckFailOpen),
+	}
 
 	if uc.NatServers != "" {
 		(*uc.AppConfig).NATS = config.NatsConfig{
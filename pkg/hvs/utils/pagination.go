@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package utils
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/hvs/domain/models"
+	"github.com/pkg/errors"
+)
+
+// PageQueryParams are the query parameters accepted by the paginated Search APIs
+var PageQueryParams = []string{"limit", "after", "sortBy", "orderBy"}
+
+// WithPageQueryParams returns a copy of searchParams that also accepts the pagination query parameters
+func WithPageQueryParams(searchParams map[string]bool) map[string]bool {
+	params := make(map[string]bool, len(searchParams)+len(PageQueryParams))
+	for k, v := range searchParams {
+		params[k] = v
+	}
+	for _, p := range PageQueryParams {
+		params[p] = true
+	}
+	return params
+}
+
+// ParsePageCriteria builds the PageCriteria from the limit, after, sortBy and orderBy query parameters.
+// Paging is only enabled when at least one of limit, after or sortBy is given, in which case nil is
+// returned otherwise. sortFields lists the accepted sort fields, the first one being the default.
+func ParsePageCriteria(params url.Values, sortFields []string) (*models.PageCriteria, error) {
+	defaultLog.Trace("utils/pagination:ParsePageCriteria() Entering")
+	defer defaultLog.Trace("utils/pagination:ParsePageCriteria() Leaving")
+
+	return parsePageCriteria(params, sortFields, true)
+}
+
+// ParseLimitedSearchPageCriteria is ParsePageCriteria for the Search APIs whose results could be limited before
+// paging was introduced. Paging is only enabled when after or sortBy is given, a limit alone keeps the original
+// ordering of the results.
+func ParseLimitedSearchPageCriteria(params url.Values, sortFields []string) (*models.PageCriteria, error) {
+	defaultLog.Trace("utils/pagination:ParseLimitedSearchPageCriteria() Entering")
+	defer defaultLog.Trace("utils/pagination:ParseLimitedSearchPageCriteria() Leaving")
+
+	return parsePageCriteria(params, sortFields, false)
+}
+
+func parsePageCriteria(params url.Values, sortFields []string, pageOnLimit bool) (*models.PageCriteria, error) {
+	limit := strings.TrimSpace(params.Get("limit"))
+	after := strings.TrimSpace(params.Get("after"))
+	sortBy := strings.TrimSpace(params.Get("sortBy"))
+	if (!pageOnLimit || limit == "") && after == "" && sortBy == "" {
+		return nil, nil
+	}
+
+	page := models.PageCriteria{
+		Limit:   constants.DefaultSearchResultRowLimit,
+		SortBy:  sortFields[0],
+		OrderBy: models.Ascending,
+	}
+
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l <= 0 {
+			return nil, errors.New("limit must be an integer > 0")
+		}
+		page.Limit = l
+	}
+
+	if sortBy != "" {
+		valid := false
+		for _, f := range sortFields {
+			if f == sortBy {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, errors.Errorf("sortBy must be one of %s", strings.Join(sortFields, ", "))
+		}
+		page.SortBy = sortBy
+	}
+
+	if orderBy := strings.TrimSpace(params.Get("orderBy")); orderBy != "" {
+		orderType, err := models.GetOrderType(orderBy)
+		if err != nil {
+			return nil, errors.New("orderBy must be asc/desc")
+		}
+		page.OrderBy = orderType
+	}
+
+	if after != "" {
+		cursor, err := models.DecodePageCursor(after)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid after query param value")
+		}
+		if cursor.SortBy != page.SortBy {
+			return nil, errors.New("after cursor was issued for a different sortBy field")
+		}
+		page.After = cursor
+	}
+
+	return &page, nil
+}
+
+// NextPageLink returns the link to the page following the one served for request r. The link is only
+// generated when the page is full, as a shorter page is necessarily the last one.
+func NextPageLink(r *http.Request, page *models.PageCriteria, count int, last models.PageCursor) string {
+	if page == nil || count < page.Limit {
+		return ""
+	}
+
+	query := r.URL.Query()
+	query.Set("after", last.Encode())
+	if query.Get("limit") == "" {
+		query.Set("limit", strconv.Itoa(page.Limit))
+	}
+	next := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	return next.String()
+}
@@ -6,6 +6,7 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
 	commConfig "github.com/intel-secl/intel-secl/v4/pkg/lib/common/config"
@@ -26,13 +27,20 @@ type Configuration struct {
 
 	EndpointURL string `yaml:"endpoint-url" mapstructure:"endpoint-url"`
 	KeyManager  string `yaml:"key-manager" mapstructure:"key-manager"`
+	StoreType   string `yaml:"store-type" mapstructure:"store-type"`
 
 	TLS    commConfig.TLSCertConfig `yaml:"tls" mapstructure:"tls"`
 	Log    commConfig.LogConfig     `yaml:"log" mapstructure:"log"`
 	Server commConfig.ServerConfig  `yaml:"server" mapstructure:"server"`
+	DB     commConfig.DBConfig      `yaml:"db" mapstructure:"db"`
 
-	Kmip KmipConfig `yaml:"kmip" mapstructure:"kmip"`
-	Skc  SKCConfig  `yaml:"skc" mapstructure:"skc"`
+	Kmip      KmipConfig      `yaml:"kmip" mapstructure:"kmip"`
+	Directory DirectoryConfig `yaml:"directory" mapstructure:"directory"`
+	Pkcs11    Pkcs11Config    `yaml:"pkcs11" mapstructure:"pkcs11"`
+	Skc       SKCConfig       `yaml:"skc" mapstructure:"skc"`
+
+	KeyRotation     KeyRotationConfig                `yaml:"key-rotation" mapstructure:"key-rotation"`
+	RevocationCheck commConfig.RevocationCheckConfig `yaml:"revocation-check" mapstructure:"revocation-check"`
 }
 
 type KBSConfig struct {
@@ -52,6 +60,24 @@ type KmipConfig struct {
 	RootCertificateFilePath   string `yaml:"root-cert-path" mapstructure:"root-cert-path"`
 }
 
+// DirectoryConfig configures the directory key manager, which keeps the keys in the KBS key store wrapped with a
+// master key
+type DirectoryConfig struct {
+	MasterKeyFilePath string `yaml:"master-key-path" mapstructure:"master-key-path"`
+}
+
+// Pkcs11Config configures the PKCS#11 key manager
+type Pkcs11Config struct {
+	ModulePath string `yaml:"module-path" mapstructure:"module-path"`
+	TokenLabel string `yaml:"token-label" mapstructure:"token-label"`
+	UserPin    string `yaml:"user-pin" mapstructure:"user-pin"`
+}
+
+// KeyRotationConfig configures the automatic rotation of the keys having a rotation interval
+type KeyRotationConfig struct {
+	CheckInterval time.Duration `yaml:"check-interval" mapstructure:"check-interval"`
+}
+
 type SKCConfig struct {
 	StmLabel          string `yaml:"challenge-type" mapstructure:"challenge-type"`
 	SQVSUrl           string `yaml:"sqvs-url" mapstructure:"sqvs-url"`
@@ -41,6 +41,9 @@ const (
 	DefaultTLSCertPath = ConfigDir + "tls-cert.pem"
 	DefaultTLSKeyPath  = ConfigDir + "tls.key"
 
+	// default location of the master key wrapping the keys of the directory key manager
+	DefaultMasterKeyPath = ConfigDir + "master-key"
+
 	// service remove command
 	ServiceRemoveCmd = "systemctl disable kbs"
 
@@ -66,7 +69,25 @@ const (
 	DefaultKBSListenerPort   = 9443
 
 	// keymanager constants
-	KmipKeyManager = "kmip"
+	KmipKeyManager      = "kmip"
+	DirectoryKeyManager = "directory"
+	Pkcs11KeyManager    = "pkcs11"
+
+	// key rotation constants
+	MinKeyRotationInterval          = time.Hour
+	DefaultKeyRotationCheckInterval = 10 * time.Minute
+	KeyVersionHeader                = "Key-Version"
+
+	// skc session constants
+	DefaultSessionCleanupInterval = 5 * time.Minute
+
+	// certificate revocation check constants
+	DefaultRevocationCheckCrlCacheDuration = time.Hour
+
+	// store type constants
+	DirectoryStoreType = "directory"
+	PostgresStoreType  = "postgres"
+	DefaultStoreType   = DirectoryStoreType
 
 	// algorithm constants
 	CRYPTOALG_AES = "AES"
@@ -83,6 +104,22 @@ const (
 	NonceLength = 32
 )
 
+// db constants
+const (
+	DBTypePostgres = "postgres"
+
+	DefaultDbConnRetryAttempts = 4
+	DefaultDbConnRetryTime     = 1
+	DefaultSSLCertFilePath     = ConfigDir + "kbsdbsslcert.pem"
+
+	//Postgres connection SslModes
+	SslModeAllow      = "allow"
+	SslModePrefer     = "prefer"
+	SslModeVerifyCa   = "verify-ca"
+	SslModeRequire    = "require"
+	SslModeVerifyFull = "verify-full"
+)
+
 // SKC Specific constants
 const (
 	DefaultSGXLabel         = "SGX"
@@ -14,6 +14,7 @@ const (
 	KeySearch   = "keys:search"
 	KeyRegister = "keys:register"
 	KeyTransfer = "keys:transfer"
+	KeyRotate   = "keys:rotate"
 
 	SamlCertCreate   = "saml_certificates:create"
 	SamlCertRetrieve = "saml_certificates:retrieve"
@@ -31,4 +32,6 @@ const (
 	KeyTransferPolicySearch   = "key_transfer_policies:search"
 
 	SessionCreate = "key-session-api:create"
+	SessionSearch = "sessions:search"
+	SessionDelete = "sessions:delete"
 )
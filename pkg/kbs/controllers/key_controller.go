@@ -18,6 +18,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -34,6 +35,7 @@ import (
 	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
 	commLogMsg "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/message"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/common"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/saml"
 	ct "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
 	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
@@ -55,6 +57,7 @@ func NewKeyController(rm *keymanager.RemoteManager, ps domain.KeyTransferPolicyS
 }
 
 var keySearchParams = map[string]bool{"algorithm": true, "keyLength": true, "curveType": true, "transferPolicyId": true}
+var keyTransferParams = map[string]bool{"version": true}
 var allowedAlgorithms = map[string]bool{"AES": true, "RSA": true, "EC": true, "aes": true, "rsa": true, "ec": true}
 var allowedCurveTypes = map[string]bool{"secp256r1": true, "secp384r1": true, "secp521r1": true, "prime256v1": true}
 var allowedKeyLengths = map[int]bool{128: true, 192: true, 256: true, 2048: true, 3072: true, 4096: true, 7680: true}
@@ -190,6 +193,50 @@ func (kc KeyController) Delete(responseWriter http.ResponseWriter, request *http
 	return nil, http.StatusNoContent, nil
 }
 
+//Rotate : Function to create a new version of key
+func (kc KeyController) Rotate(responseWriter http.ResponseWriter, request *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/key_controller:Rotate() Entering")
+	defer defaultLog.Trace("controllers/key_controller:Rotate() Leaving")
+
+	// the request body is optional, it replaces the rotation interval of the key
+	var rotateRequest kbs.KeyRotateRequest
+	if request.ContentLength != 0 {
+		if request.Header.Get("Content-Type") != constants.HTTPMediaTypeJson {
+			return nil, http.StatusUnsupportedMediaType, &commErr.ResourceError{Message: "Invalid Content-Type"}
+		}
+
+		dec := json.NewDecoder(request.Body)
+		dec.DisallowUnknownFields()
+		err := dec.Decode(&rotateRequest)
+		if err != nil {
+			secLog.WithError(err).Errorf("controllers/key_controller:Rotate() %s : Failed to decode request body as KeyRotateRequest", commLogMsg.InvalidInputBadEncoding)
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Unable to decode JSON request body"}
+		}
+
+		if rotateRequest.RotationInterval != nil {
+			if err := validateRotationInterval(*rotateRequest.RotationInterval); err != nil {
+				secLog.WithError(err).Errorf("controllers/key_controller:Rotate() %s : Invalid rotation interval", commLogMsg.InvalidInputBadParam)
+				return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+			}
+		}
+	}
+
+	id := uuid.MustParse(mux.Vars(request)["id"])
+	rotatedKey, err := kc.remoteManager.RotateKey(id, &rotateRequest)
+	if err != nil {
+		if err.Error() == commErr.RecordNotFound {
+			defaultLog.Error("controllers/key_controller:Rotate() Key with specified id could not be located")
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Key with specified id does not exist"}
+		} else {
+			defaultLog.WithError(err).Error("controllers/key_controller:Rotate() Key rotate failed")
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to rotate key"}
+		}
+	}
+
+	secLog.WithField("Id", id).Infof("controllers/key_controller:Rotate() %s: Key rotated to version %d by: %s", commLogMsg.PrivilegeModified, rotatedKey.Version, request.RemoteAddr)
+	return rotatedKey, http.StatusOK, nil
+}
+
 //Search : Function to search keys
 func (kc KeyController) Search(responseWriter http.ResponseWriter, request *http.Request) (interface{}, int, error) {
 	defaultLog.Trace("controllers/key_controller:Search() Entering")
@@ -247,16 +294,23 @@ func (kc KeyController) Transfer(responseWriter http.ResponseWriter, request *ht
 	}
 	envelopeKey := key.(*rsa.PublicKey)
 
+	version, err := getKeyVersion(request.URL.Query())
+	if err != nil {
+		secLog.WithError(err).Errorf("controllers/key_controller:Transfer() %s : Invalid key version", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
 	// Wrap key with public key
 	id := uuid.MustParse(mux.Vars(request)["id"])
-	wrappedKey, status, err := kc.wrapSecretKey(id, envelopeKey, sha512.New384(), nil)
+	wrappedKey, keyVersion, status, err := kc.wrapSecretKey(id, version, envelopeKey, sha512.New384(), nil)
 	if err != nil {
 		return nil, status, err
 	}
 
 	transferKeyResponse := kbs.KeyTransferAttributes{
 		KeyId:   id,
-		KeyData: base64.StdEncoding.EncodeToString(wrappedKey.([]byte)),
+		KeyData: base64.StdEncoding.EncodeToString(wrappedKey),
+		Version: keyVersion,
 	}
 
 	secLog.WithField("Id", id).Infof("controllers/key_controller:Transfer() %s: Key transferred using Envelope key by: %s", commLogMsg.PrivilegeModified, request.RemoteAddr)
@@ -292,9 +346,19 @@ func (kc KeyController) TransferWithSaml(responseWriter http.ResponseWriter, req
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Failed to unmarshal saml report"}
 	}
 
+	version, err := getKeyVersion(request.URL.Query())
+	if err != nil {
+		secLog.WithError(err).Errorf("controllers/key_controller:TransferWithSaml() %s : Invalid key version", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
 	// Validate saml report in request
 	id := uuid.MustParse(mux.Vars(request)["id"])
-	trusted, bindingCert := keytransfer.IsTrustedByHvs(string(bytes), samlReport, id, kc.config, kc.remoteManager)
+	trusted, bindingCert, violation := keytransfer.IsTrustedByHvs(string(bytes), samlReport, id, kc.config, kc.remoteManager)
+	if violation != nil {
+		secLog.Errorf("controllers/key_controller:TransferWithSaml() Key usage policy is not satisfied, clause %s", violation.Clause)
+		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "Key usage policy not satisfied - " + violation.Error()}
+	}
 	if !trusted {
 		secLog.Error("controllers/key_controller:TransferWithSaml() Saml report is not trusted")
 		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "Client not trusted by Hvs"}
@@ -302,27 +366,32 @@ func (kc KeyController) TransferWithSaml(responseWriter http.ResponseWriter, req
 	envelopeKey := bindingCert.PublicKey.(*rsa.PublicKey)
 
 	// Wrap key with binding key
-	wrappedKey, status, err := kc.wrapSecretKey(id, envelopeKey, sha256.New(), []byte("TPM2\000"))
+	wrappedKey, keyVersion, status, err := kc.wrapSecretKey(id, version, envelopeKey, sha256.New(), []byte("TPM2\000"))
 	if err != nil {
 		return nil, status, err
 	}
+	responseWriter.Header().Set(consts.KeyVersionHeader, strconv.Itoa(keyVersion))
 
 	secLog.WithField("Id", id).Infof("controllers/key_controller:TransferWithSaml() %s: Key transferred using saml report by: %s", commLogMsg.PrivilegeModified, request.RemoteAddr)
 	return wrappedKey, http.StatusOK, nil
 }
 
-func (kc KeyController) wrapSecretKey(id uuid.UUID, publicKey *rsa.PublicKey, hash hash.Hash, label []byte) (interface{}, int, error) {
+// wrapSecretKey returns the given version of the key wrapped with the public key along with the version number
+func (kc KeyController) wrapSecretKey(id uuid.UUID, version int, publicKey *rsa.PublicKey, hash hash.Hash, label []byte) ([]byte, int, int, error) {
 	defaultLog.Trace("controllers/key_controller:wrapSecretKey() Entering")
 	defer defaultLog.Trace("controllers/key_controller:wrapSecretKey() Leaving")
 
-	secretKey, err := kc.remoteManager.TransferKey(id)
+	secretKey, keyVersion, err := kc.remoteManager.TransferKey(id, version)
 	if err != nil {
 		if err.Error() == commErr.RecordNotFound {
 			defaultLog.Error("controllers/key_controller:wrapSecretKey() Key with specified id could not be located")
-			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Key with specified id does not exist"}
+			return nil, 0, http.StatusNotFound, &commErr.ResourceError{Message: "Key with specified id does not exist"}
+		} else if err.Error() == keymanager.KeyVersionNotFound {
+			defaultLog.Errorf("controllers/key_controller:wrapSecretKey() Version %d of key could not be located", version)
+			return nil, 0, http.StatusNotFound, &commErr.ResourceError{Message: "Key version with specified number does not exist"}
 		} else {
 			defaultLog.WithError(err).Error("controllers/key_controller:wrapSecretKey() Key transfer failed")
-			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to transfer Key"}
+			return nil, 0, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to transfer Key"}
 		}
 	}
 
@@ -330,10 +399,10 @@ func (kc KeyController) wrapSecretKey(id uuid.UUID, publicKey *rsa.PublicKey, ha
 	wrappedKey, err := rsa.EncryptOAEP(hash, rand.Reader, publicKey, secretKey, label)
 	if err != nil {
 		defaultLog.WithError(err).Error("controllers/key_controller:wrapSecretKey() Wrap key failed")
-		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to wrap key"}
+		return nil, 0, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to wrap key"}
 	}
 
-	return wrappedKey, http.StatusOK, nil
+	return wrappedKey, keyVersion, http.StatusOK, nil
 }
 
 //validateKeyCreateRequest checks for various attributes in the Key Create request and returns a boolean value
@@ -384,11 +453,103 @@ func validateKeyCreateRequest(requestKey kbs.KeyRequest) error {
 		if err := validation.ValidateTextString(requestKey.Usage); err != nil {
 			return errors.New("valid contents for usage must be specified")
 		}
+		if requestKey.UsagePolicy != nil {
+			return errors.New("usage and usage_policy cannot both be specified")
+		}
+	}
+
+	if requestKey.UsagePolicy != nil {
+		if err := validateUsagePolicy(requestKey.UsagePolicy); err != nil {
+			return err
+		}
 	}
 
+	return validateRotationInterval(requestKey.RotationInterval)
+}
+
+//validateUsagePolicy checks that each clause of the key usage policy is well formed
+func validateUsagePolicy(usagePolicy *kbs.KeyUsagePolicy) error {
+	if usagePolicy.AssetTags != nil {
+		assetTags := usagePolicy.AssetTags
+		if len(assetTags.AllOf) == 0 && len(assetTags.AnyOf) == 0 && len(assetTags.NoneOf) == 0 {
+			return errors.New("usage_policy asset_tags must include at least one tag")
+		}
+		for _, tags := range [][]kbs.AssetTag{assetTags.AllOf, assetTags.AnyOf, assetTags.NoneOf} {
+			for _, tag := range tags {
+				if validation.ValidateTextString(tag.Key) != nil || validation.ValidateTextString(tag.Value) != nil {
+					return errors.New("usage_policy asset_tags must have a valid key and value")
+				}
+			}
+		}
+	}
+
+	for _, flavorPart := range usagePolicy.RequiredTrustedFlavorParts {
+		var fp common.FlavorPart
+		if err := fp.Parse(flavorPart); err != nil {
+			return errors.Errorf("usage_policy required_trusted_flavor_parts has an invalid flavor part %s", flavorPart)
+		}
+	}
+
+	if usagePolicy.MaxReportAge != "" {
+		maxReportAge, err := time.ParseDuration(usagePolicy.MaxReportAge)
+		if err != nil || maxReportAge <= 0 {
+			return errors.New("usage_policy max_report_age must be a positive duration, e.g. 10m")
+		}
+	}
+
+	for feature, allowedValues := range usagePolicy.AllowedHardwareFeatures {
+		if err := validation.ValidateNameString(feature); err != nil {
+			return errors.New("usage_policy allowed_hardware_features must have valid feature names")
+		}
+		if len(allowedValues) == 0 {
+			return errors.Errorf("usage_policy allowed_hardware_features must list the allowed values of %s", feature)
+		}
+		if err := validation.ValidateStrings(allowedValues); err != nil {
+			return errors.Errorf("usage_policy allowed_hardware_features has invalid values for %s", feature)
+		}
+	}
+
+	for _, issuer := range usagePolicy.AllowedAikIssuers {
+		if issuer == "" || validation.ValidateIssuer(issuer) != nil {
+			return errors.New("usage_policy allowed_aik_issuers must have valid issuer names")
+		}
+	}
+
+	return nil
+}
+
+//validateRotationInterval checks that the rotation interval of a key is empty or a duration of at least an hour
+func validateRotationInterval(rotationInterval string) error {
+	if rotationInterval == "" {
+		return nil
+	}
+	interval, err := time.ParseDuration(rotationInterval)
+	if err != nil {
+		return errors.New("rotation_interval must be a duration, e.g. 720h")
+	}
+	if interval < consts.MinKeyRotationInterval {
+		return errors.Errorf("rotation_interval must be at least %s", consts.MinKeyRotationInterval)
+	}
 	return nil
 }
 
+//getKeyVersion returns the key version requested by the version query parameter of a key transfer, 0 when the
+//active version is requested
+func getKeyVersion(params url.Values) (int, error) {
+	if err := utils.ValidateQueryParams(params, keyTransferParams); err != nil {
+		return 0, err
+	}
+	param := strings.TrimSpace(params.Get("version"))
+	if param == "" {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(param)
+	if err != nil || version < 1 {
+		return 0, errors.New("Invalid version query param value, must be a positive Integer")
+	}
+	return version, nil
+}
+
 //getKeyFilterCriteria checks for set filter params in the Search request and returns a valid KeyFilterCriteria
 func getKeyFilterCriteria(params url.Values) (*models.KeyFilterCriteria, error) {
 	defaultLog.Trace("controllers/key_controller:getKeyFilterCriteria() Entering")
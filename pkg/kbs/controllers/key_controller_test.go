@@ -72,9 +72,9 @@ var _ = Describe("KeyController", func() {
 
 	newId, _ := uuid.NewRandom()
 	keyControllerConfig = domain.KeyControllerConfig{
-		SamlCertsDir:            samlCertsDir,
+		SamlCertStore:           mocks.NewFakeCertificateStore(),
 		TrustedCaCertsDir:       trustedCaCertsDir,
-		TpmIdentityCertsDir:     tpmIdentityCertsDir,
+		TpmIdentityCertStore:    mocks.NewFakeCertificateStore(),
 		DefaultTransferPolicyId: newId,
 	}
 
@@ -118,6 +118,115 @@ var _ = Describe("KeyController", func() {
 				Expect(w.Code).To(Equal(http.StatusCreated))
 			})
 		})
+		Context("Provide a Create request that contains invalid rotation interval", func() {
+			It("Should fail to create new Key", func() {
+				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Create))).Methods("POST")
+				keyJson := `{
+								"key_information": {
+									"algorithm": "AES",
+									"key_length": 256
+								},
+								"rotation_interval": "monthly"
+							}`
+
+				req, err := http.NewRequest(
+					"POST",
+					"/keys",
+					strings.NewReader(keyJson),
+				)
+
+				permissions := aas.PermissionInfo{
+					Service: constants.ServiceName,
+					Rules:   []string{constants.KeyCreate},
+				}
+				req = context.SetUserPermissions(req, []aas.PermissionInfo{permissions})
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+		Context("Provide a Create request that contains a usage policy", func() {
+			It("Should create a new Key with the usage policy", func() {
+				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Create))).Methods("POST")
+				keyJson := `{
+								"key_information": {
+									"algorithm": "AES",
+									"key_length": 256
+								},
+								"usage_policy": {
+									"asset_tags": {
+										"any_of": [{"key": "Country", "value": "US"}, {"key": "Country", "value": "CA"}],
+										"none_of": [{"key": "State", "value": "Quarantined"}]
+									},
+									"required_trusted_flavor_parts": ["PLATFORM", "OS"],
+									"max_report_age": "10m",
+									"allowed_hardware_features": {"TXT": ["true"]}
+								}
+							}`
+
+				req, err := http.NewRequest(
+					"POST",
+					"/keys",
+					strings.NewReader(keyJson),
+				)
+
+				permissions := aas.PermissionInfo{
+					Service: constants.ServiceName,
+					Rules:   []string{constants.KeyCreate},
+				}
+				req = context.SetUserPermissions(req, []aas.PermissionInfo{permissions})
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusCreated))
+			})
+		})
+		Context("Provide a Create request that contains a usage policy with an invalid flavor part", func() {
+			It("Should fail to create new Key", func() {
+				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Create))).Methods("POST")
+				keyJson := `{
+								"key_information": {
+									"algorithm": "AES",
+									"key_length": 256
+								},
+								"usage_policy": {
+									"asset_tags": {
+										"any_of": [{"key": "Country", "value": "US"}, {"key": "Country", "value": "CA"}],
+										"none_of": [{"key": "State", "value": "Quarantined"}]
+									},
+									"required_trusted_flavor_parts": ["PLATFORM", "FIRMWARE"],
+									"max_report_age": "10m",
+									"allowed_hardware_features": {"TXT": ["true"]}
+								}
+							}`
+
+				req, err := http.NewRequest(
+					"POST",
+					"/keys",
+					strings.NewReader(keyJson),
+				)
+
+				permissions := aas.PermissionInfo{
+					Service: constants.ServiceName,
+					Rules:   []string{constants.KeyCreate},
+				}
+				req = context.SetUserPermissions(req, []aas.PermissionInfo{permissions})
+
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
 		Context("Provide a Create request that contains non-existent key-transfer-policy", func() {
 			It("Should fail to create new Key", func() {
 				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Create))).Methods("POST")
@@ -507,6 +616,114 @@ var _ = Describe("KeyController", func() {
 		})
 	})
 
+	// Specs for HTTP Post to "/keys/{id}/rotate"
+	Describe("Rotate an existing Key", func() {
+		Context("Rotate Key by ID", func() {
+			It("Should create a new version of the Key", func() {
+				router.Handle("/keys/{id}/rotate", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Rotate))).Methods("POST")
+				req, err := http.NewRequest("POST", "/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/rotate", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var keyResponse kbs.KeyResponse
+				err = json.Unmarshal(w.Body.Bytes(), &keyResponse)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(keyResponse.Version).To(Equal(2))
+				Expect(keyResponse.Versions).To(HaveLen(2))
+				Expect(keyResponse.NextRotation).To(BeNil())
+			})
+		})
+		Context("Rotate Key by ID with a rotation interval", func() {
+			It("Should create a new version of the Key and schedule its rotation", func() {
+				router.Handle("/keys/{id}/rotate", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Rotate))).Methods("POST")
+				req, err := http.NewRequest("POST", "/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/rotate", strings.NewReader(`{"rotation_interval": "720h"}`))
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var keyResponse kbs.KeyResponse
+				err = json.Unmarshal(w.Body.Bytes(), &keyResponse)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(keyResponse.RotationInterval).To(Equal("720h"))
+				Expect(keyResponse.NextRotation).NotTo(BeNil())
+			})
+		})
+		Context("Rotate Key by ID with a too short rotation interval", func() {
+			It("Should fail to rotate Key", func() {
+				router.Handle("/keys/{id}/rotate", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Rotate))).Methods("POST")
+				req, err := http.NewRequest("POST", "/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/rotate", strings.NewReader(`{"rotation_interval": "30m"}`))
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+		Context("Rotate Key by non-existent ID", func() {
+			It("Should fail to rotate Key", func() {
+				router.Handle("/keys/{id}/rotate", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Rotate))).Methods("POST")
+				req, err := http.NewRequest("POST", "/keys/73755fda-c910-46be-821f-e8ddeab189e9/rotate", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+		Context("Transfer the versions of a rotated Key", func() {
+			It("Should transfer the active and the prior versions of the Key", func() {
+				router.Handle("/keys/{id}/rotate", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Rotate))).Methods("POST")
+				router.Handle("/keys/{id}/transfer", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Transfer))).Methods("POST")
+				req, err := http.NewRequest("POST", "/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/rotate", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				transfers := []struct {
+					query   string
+					status  int
+					version int
+				}{
+					{query: "", status: http.StatusOK, version: 2},
+					{query: "?version=1", status: http.StatusOK, version: 1},
+					{query: "?version=2", status: http.StatusOK, version: 2},
+					{query: "?version=3", status: http.StatusNotFound},
+					{query: "?version=first", status: http.StatusBadRequest},
+					{query: "?revision=1", status: http.StatusBadRequest},
+				}
+				for _, transfer := range transfers {
+					req, err = http.NewRequest(
+						"POST",
+						"/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/transfer"+transfer.query,
+						strings.NewReader(string(validEnvelopeKey)),
+					)
+					Expect(err).NotTo(HaveOccurred())
+					req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+					req.Header.Set("Content-Type", consts.HTTPMediaTypePlain)
+					w = httptest.NewRecorder()
+					router.ServeHTTP(w, req)
+					Expect(w.Code).To(Equal(transfer.status))
+
+					if transfer.status == http.StatusOK {
+						var transferResponse kbs.KeyTransferAttributes
+						err = json.Unmarshal(w.Body.Bytes(), &transferResponse)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(transferResponse.Version).To(Equal(transfer.version))
+					}
+				}
+			})
+		})
+	})
+
 	// Specs for HTTP Get to "/keys/{id}"
 	Describe("Retrieve an existing Key", func() {
 		Context("Retrieve Key by ID", func() {
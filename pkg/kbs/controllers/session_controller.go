@@ -14,12 +14,18 @@ import (
 	"fmt"
 	"math/big"
 	"net/http"
-	"reflect"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/config"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
-	"github.com/intel-secl/intel-secl/v4/pkg/kbs/keytransfer"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/session"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/utils"
 	commConstants "github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
 	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
 	commLogMsg "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/message"
@@ -29,16 +35,20 @@ import (
 )
 
 type SessionController struct {
+	store            domain.SessionStore
 	config           *config.Configuration
 	trustedCaCertDir string
 }
 
-func NewSessionController(kc *config.Configuration, caCertDir string) *SessionController {
-	return &SessionController{config: kc,
+func NewSessionController(ss domain.SessionStore, kc *config.Configuration, caCertDir string) *SessionController {
+	return &SessionController{store: ss,
+		config:           kc,
 		trustedCaCertDir: caCertDir,
 	}
 }
 
+var sessionSearchParams = map[string]bool{"stmLabel": true}
+
 // from challenge uuid remove '-' and prepare nonce
 func getNonce(challengeUUID string) ([]byte, error) {
 	decodedUUID, err := base64.StdEncoding.DecodeString(challengeUUID)
@@ -55,6 +65,10 @@ func getNonce(challengeUUID string) ([]byte, error) {
 	return nonce, nil
 }
 
+// the quote received from skc client starts with 4 bytes for key exponent length, 4 bytes for modulus length
+// and 4 bytes for quote size
+const quoteHeaderSize = 12
+
 // remove public key blob from quote received from skc client
 // send only sgx ecdsa quote to quote verification service
 func extractKeyFromQuote(quote string) (string, []byte, error) {
@@ -62,23 +76,23 @@ func extractKeyFromQuote(quote string) (string, []byte, error) {
 	if err != nil {
 		return "", nil, errors.New("not a base64 encoded Quote")
 	}
-	// quote starts with 4 bytes for key exponent length
-	// next four bytes account for modulus length
-	pubKeyExponent := binary.LittleEndian.Uint32(decodedQuote[:])
-	pubKeyModulus := binary.LittleEndian.Uint32(decodedQuote[4:])
-	pubKeySize := int(pubKeyModulus + pubKeyExponent)
-	// 12 bytes below account for key exponent/modulus length and quote size
-	pubKeyStart := 12
-	pubKeyEnd := pubKeyStart + pubKeySize
+	if len(decodedQuote) < quoteHeaderSize {
+		return "", nil, errors.Errorf("quote is truncated, %d bytes expected, %d bytes received", quoteHeaderSize, len(decodedQuote))
+	}
+	// the lengths come from the client, they are added as 64 bits values so that they can not overflow
+	pubKeyExponent := uint64(binary.LittleEndian.Uint32(decodedQuote[0:4]))
+	pubKeyModulus := uint64(binary.LittleEndian.Uint32(decodedQuote[4:8]))
+	pubKeySize := pubKeyExponent + pubKeyModulus
+	if pubKeySize > uint64(len(decodedQuote)-quoteHeaderSize) {
+		return "", nil, errors.Errorf("quote is truncated, public key of %d bytes expected, %d bytes left", pubKeySize, len(decodedQuote)-quoteHeaderSize)
+	}
+	pubKeyEnd := quoteHeaderSize + int(pubKeySize)
 
 	publicKey := make([]byte, pubKeySize)
-	copy(publicKey, decodedQuote[pubKeyStart:pubKeyEnd])
+	copy(publicKey, decodedQuote[quoteHeaderSize:pubKeyEnd])
 
-	newSize := len(decodedQuote) - pubKeySize - pubKeyStart
-	quoteWithoutKey := make([]byte, newSize)
-	quoteStart := pubKeyStart + pubKeySize
-	quoteEnd := len(decodedQuote)
-	copy(quoteWithoutKey, decodedQuote[quoteStart:quoteEnd])
+	quoteWithoutKey := make([]byte, len(decodedQuote)-pubKeyEnd)
+	copy(quoteWithoutKey, decodedQuote[pubKeyEnd:])
 	encodedQuote := base64.StdEncoding.EncodeToString(quoteWithoutKey)
 	return encodedQuote, publicKey, nil
 }
@@ -119,12 +133,14 @@ func (sc *SessionController) Create(responseWriter http.ResponseWriter, request
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid create request"}
 	}
 
-	keyInfo := keytransfer.GetKeyInfo()
-	sessionObj := keyInfo.GetSessionObj(sessionRequest.Challenge)
-
-	if reflect.DeepEqual(sessionObj, kbs.KeyTransferSession{}) {
-		defaultLog.WithError(err).Error("controllers/session_controller:Create() no session object found.")
-		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "no session object found"}
+	sessionObj, err := sc.store.Retrieve(sessionRequest.Challenge)
+	if err != nil {
+		if err.Error() == commErr.RecordNotFound {
+			defaultLog.Error("controllers/session_controller:Create() no session object found.")
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "no session object found"}
+		}
+		defaultLog.WithError(err).Error("controllers/session_controller:Create() Session retrieve failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve session"}
 	}
 
 	var responseAttributes *kbs.QuoteVerifyAttributes
@@ -139,7 +155,8 @@ func (sc *SessionController) Create(responseWriter http.ResponseWriter, request
 	}
 	Quote, Key, err := extractKeyFromQuote(sessionRequest.Quote)
 	if err != nil {
-		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Error while extracting public key"}
+		secLog.WithError(err).Errorf("controllers/session_controller:Create() %s : Failed to extract public key from quote", commLogMsg.InvalidInputProtocolViolation)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Error while extracting public key"}
 	}
 	UserData := addKeyandNonce(Key, nonce)
 	// send ecdsa quote and user data(Enclave Public Key + nonce) to Quote Verification Service
@@ -150,7 +167,7 @@ func (sc *SessionController) Create(responseWriter http.ResponseWriter, request
 	}
 	responseAttributes.ChallengeKeyType = constants.CRYPTOALG_RSA
 	responseAttributes.ChallengeRsaPublicKey = string(rsaKey)
-	keyInfo.SessionResponseMap[sessionRequest.Challenge] = *responseAttributes
+	sessionObj.QuoteVerifyAttributes = responseAttributes
 
 	swkKey, err := session.SessionCreateSwk()
 	if err != nil {
@@ -159,7 +176,11 @@ func (sc *SessionController) Create(responseWriter http.ResponseWriter, request
 	}
 
 	sessionObj.SWK = swkKey
-	keyInfo.SessionMap[sessionRequest.Challenge] = sessionObj
+	_, err = sc.store.Update(sessionObj)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/session_controller:Create() Session update failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to update session"}
+	}
 
 	var respAttr kbs.SessionResponseAttributes
 	if responseAttributes.ChallengeKeyType == constants.CRYPTOALG_RSA {
@@ -186,6 +207,102 @@ func (sc *SessionController) Create(responseWriter http.ResponseWriter, request
 	return respAttr, http.StatusCreated, nil
 }
 
+// Search returns the active sessions, the session wrapping keys are not exposed
+func (sc *SessionController) Search(responseWriter http.ResponseWriter, request *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/session_controller:Search() Entering")
+	defer defaultLog.Trace("controllers/session_controller:Search() Leaving")
+
+	criteria, err := getSessionFilterCriteria(request.URL.Query())
+	if err != nil {
+		secLog.WithError(err).Errorf("controllers/session_controller:Search() %s : Invalid filter criteria", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	sessions, err := sc.store.Search(criteria)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/session_controller:Search() Session search failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to search sessions"}
+	}
+
+	sessionInfos := []kbs.SessionInfo{}
+	for _, s := range sessions {
+		sessionInfo, err := toSessionInfo(s)
+		if err != nil {
+			defaultLog.WithError(err).Warnf("controllers/session_controller:Search() Skipping session with invalid id %s", s.SessionId)
+			continue
+		}
+		sessionInfos = append(sessionInfos, *sessionInfo)
+	}
+
+	secLog.Infof("controllers/session_controller:Search() %s: Sessions searched by: %s", commLogMsg.AuthorizedAccess, request.RemoteAddr)
+	return sessionInfos, http.StatusOK, nil
+}
+
+// Delete revokes a session, the enclave has to be attested again before the next key transfer
+func (sc *SessionController) Delete(responseWriter http.ResponseWriter, request *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/session_controller:Delete() Entering")
+	defer defaultLog.Trace("controllers/session_controller:Delete() Leaving")
+
+	id := uuid.MustParse(mux.Vars(request)["id"])
+	err := sc.store.Delete(base64.StdEncoding.EncodeToString([]byte(id.String())))
+	if err != nil {
+		if err.Error() == commErr.RecordNotFound {
+			defaultLog.Error("controllers/session_controller:Delete() Session with specified id could not be located")
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Session with specified id does not exist"}
+		}
+		defaultLog.WithError(err).Error("controllers/session_controller:Delete() Session delete failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to delete session"}
+	}
+
+	secLog.WithField("Id", id).Infof("controllers/session_controller:Delete() Session revoked by: %s", request.RemoteAddr)
+	return nil, http.StatusNoContent, nil
+}
+
+// getSessionFilterCriteria checks for set filter params in the Search request and returns a valid SessionFilterCriteria
+func getSessionFilterCriteria(params url.Values) (*models.SessionFilterCriteria, error) {
+	defaultLog.Trace("controllers/session_controller:getSessionFilterCriteria() Entering")
+	defer defaultLog.Trace("controllers/session_controller:getSessionFilterCriteria() Leaving")
+
+	criteria := models.SessionFilterCriteria{ActiveAt: time.Now().UTC()}
+	if err := utils.ValidateQueryParams(params, sessionSearchParams); err != nil {
+		return nil, err
+	}
+
+	// stmLabel
+	if param := strings.TrimSpace(params.Get("stmLabel")); param != "" {
+		if err := validation.ValidateStrings([]string{param}); err != nil {
+			return nil, errors.New("Valid contents for stmLabel must be specified")
+		}
+		criteria.StmLabel = param
+	}
+	return &criteria, nil
+}
+
+// toSessionInfo describes the session by the id sent to the skc_library in the Session-Id header
+func toSessionInfo(s kbs.KeyTransferSession) (*kbs.SessionInfo, error) {
+	decodedId, err := base64.StdEncoding.DecodeString(s.SessionId)
+	if err != nil {
+		return nil, errors.Wrap(err, "session id is not base64 encoded")
+	}
+	id, err := uuid.Parse(string(decodedId))
+	if err != nil {
+		return nil, errors.Wrap(err, "session id is not a UUID")
+	}
+
+	sessionInfo := kbs.SessionInfo{
+		ID:             id,
+		StmLabel:       s.Stmlabel,
+		ClientCertHash: s.ClientCertHash,
+		Attested:       s.QuoteVerifyAttributes != nil,
+		CreatedAt:      s.CreatedAt,
+		ExpiresAt:      s.SessionExpiryTime,
+	}
+	if s.QuoteVerifyAttributes != nil {
+		sessionInfo.TCBLevel = s.QuoteVerifyAttributes.TCBLevel
+	}
+	return &sessionInfo, nil
+}
+
 func validateSessionCreateRequest(sessionRequest kbs.SessionManagementAttributes) error {
 	defaultLog.Trace("controllers/session_controller:validateSessionCreateRequest() Entering")
 	defer defaultLog.Trace("controllers/session_controller:validateSessionCreateRequest() Leaving")
@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestQuote returns a base64 encoded skc client quote with the given key lengths in its header
+func newTestQuote(exponentLength, modulusLength uint32, body []byte) string {
+	quote := make([]byte, quoteHeaderSize, quoteHeaderSize+len(body))
+	binary.LittleEndian.PutUint32(quote[0:], exponentLength)
+	binary.LittleEndian.PutUint32(quote[4:], modulusLength)
+	binary.LittleEndian.PutUint32(quote[8:], uint32(len(body)))
+	return base64.StdEncoding.EncodeToString(append(quote, body...))
+}
+
+func TestExtractKeyFromQuote(t *testing.T) {
+	quote, key, err := extractKeyFromQuote(newTestQuote(3, 5, []byte("expmodulecdsa quote")))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("expmodul"), key)
+	decodedQuote, err := base64.StdEncoding.DecodeString(quote)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("ecdsa quote"), decodedQuote)
+}
+
+func TestExtractKeyFromQuoteMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		quote string
+	}{
+		{name: "not base64", quote: "not a quote"},
+		{name: "empty quote", quote: ""},
+		{name: "4 bytes quote", quote: base64.StdEncoding.EncodeToString(make([]byte, 4))},
+		{name: "11 bytes quote", quote: base64.StdEncoding.EncodeToString(make([]byte, 11))},
+		{name: "key longer than quote", quote: newTestQuote(4, 260, make([]byte, 100))},
+		{name: "key lengths overflow", quote: newTestQuote(0xffffffff, 0xffffffff, make([]byte, 100))},
+		{name: "key lengths overflow 32 bits", quote: newTestQuote(0xffffffff, 2, make([]byte, 1))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := extractKeyFromQuote(tt.quote)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -6,11 +6,12 @@
 package controllers_test
 
 import (
+	"encoding/json"
 	"github.com/gorilla/mux"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/config"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/controllers"
-	"github.com/intel-secl/intel-secl/v4/pkg/kbs/keytransfer"
 	kbsRoutes "github.com/intel-secl/intel-secl/v4/pkg/kbs/router"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/session"
 	consts "github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
 	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
 	. "github.com/onsi/ginkgo"
@@ -19,6 +20,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 )
 
 const (
@@ -26,6 +28,7 @@ const (
 	KBSServicePassword = "kbspassword"
 	skcClientCertPath  = "./resources/skc-client/skc_client_certificate.pem"
 	EncodedSessionId   = "MTRjZmNlZDEtMDNlZS00YTY4LThiNTAtNmQ0NTY0MjNiMDc4"
+	SessionId          = "14cfced1-03ee-4a68-8b50-6d456423b078"
 )
 
 func setupServer(server *ghttp.Server) {
@@ -74,11 +77,7 @@ var _ = Describe("SessionController", func() {
 	var w *httptest.ResponseRecorder
 	var sessionController *controllers.SessionController
 	var kbsConfig *config.Configuration
-
-	var keyTransfer kbs.KeyTransferSession
-	keyInfo := keytransfer.GetKeyInfo()
-	keyTransfer.SessionId = EncodedSessionId
-	keyInfo.SessionMap[EncodedSessionId] = keyTransfer
+	var sessionStore *session.MemoryStore
 
 	BeforeEach(func() {
 		router = mux.NewRouter()
@@ -94,7 +93,21 @@ var _ = Describe("SessionController", func() {
 				SQVSUrl:  "http://" + server.Addr() + "/svs/v1",
 			},
 		}
-		sessionController = controllers.NewSessionController(kbsConfig, trustedCaCertsDir)
+		now := time.Now().UTC()
+		sessionStore = session.NewMemoryStore()
+		_, _ = sessionStore.Create(&kbs.KeyTransferSession{
+			SessionId:         EncodedSessionId,
+			Stmlabel:          "SGX",
+			CreatedAt:         now,
+			SessionExpiryTime: now.Add(time.Minute),
+		})
+		_, _ = sessionStore.Create(&kbs.KeyTransferSession{
+			SessionId:         "ZWU5MzNmNjItNmJmOS00MGE0LWEzNGMtODhiMTRiMzFmMGJk",
+			Stmlabel:          "SGX",
+			CreatedAt:         now.Add(-2 * time.Minute),
+			SessionExpiryTime: now.Add(-time.Minute),
+		})
+		sessionController = controllers.NewSessionController(sessionStore, kbsConfig, trustedCaCertsDir)
 		setupServer(server)
 	})
 
@@ -231,4 +244,82 @@ var _ = Describe("SessionController", func() {
 			})
 		})
 	})
+
+	// Specs for HTTP Get to "/sessions"
+	Describe("Search the Sessions", func() {
+		Context("Search without filter", func() {
+			It("Should return the active sessions only", func() {
+				router.Handle("/sessions", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(sessionController.Search))).Methods("GET")
+				req, err := http.NewRequest("GET", "/sessions", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var sessions []kbs.SessionInfo
+				Expect(json.Unmarshal(w.Body.Bytes(), &sessions)).To(Succeed())
+				Expect(sessions).To(HaveLen(1))
+				Expect(sessions[0].ID.String()).To(Equal(SessionId))
+				Expect(sessions[0].StmLabel).To(Equal("SGX"))
+				Expect(sessions[0].Attested).To(BeFalse())
+				Expect(w.Body.String()).NotTo(ContainSubstring("swk"))
+			})
+		})
+		Context("Search with an stm label without sessions", func() {
+			It("Should return an empty list", func() {
+				router.Handle("/sessions", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(sessionController.Search))).Methods("GET")
+				req, err := http.NewRequest("GET", "/sessions?stmLabel=SW", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var sessions []kbs.SessionInfo
+				Expect(json.Unmarshal(w.Body.Bytes(), &sessions)).To(Succeed())
+				Expect(sessions).To(BeEmpty())
+			})
+		})
+		Context("Search with an invalid query parameter", func() {
+			It("Should fail to search the sessions", func() {
+				router.Handle("/sessions", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(sessionController.Search))).Methods("GET")
+				req, err := http.NewRequest("GET", "/sessions?badParam=SGX", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	// Specs for HTTP Delete to "/sessions/{id}"
+	Describe("Revoke a Session", func() {
+		Context("Revoke an existing Session", func() {
+			It("Should delete the Session", func() {
+				router.Handle("/sessions/{id}", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(sessionController.Delete))).Methods("DELETE")
+				req, err := http.NewRequest("DELETE", "/sessions/"+SessionId, nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNoContent))
+
+				_, err = sessionStore.Retrieve(EncodedSessionId)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		Context("Revoke a non-existent Session", func() {
+			It("Should fail to delete the Session", func() {
+				router.Handle("/sessions/{id}", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(sessionController.Delete))).Methods("DELETE")
+				req, err := http.NewRequest("DELETE", "/sessions/73755fda-c910-46be-821f-e8ddeab189e9", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
 })
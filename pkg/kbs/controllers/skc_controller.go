@@ -28,14 +28,16 @@ import (
 type SKCController struct {
 	remoteManager    *keymanager.RemoteManager
 	policyStore      domain.KeyTransferPolicyStore
+	sessionStore     domain.SessionStore
 	config           *config.Configuration
 	trustedCaCertDir string
 }
 
-func NewSKCController(rm *keymanager.RemoteManager, ps domain.KeyTransferPolicyStore, kc *config.Configuration, caCertDir string) *SKCController {
+func NewSKCController(rm *keymanager.RemoteManager, ps domain.KeyTransferPolicyStore, ss domain.SessionStore, kc *config.Configuration, caCertDir string) *SKCController {
 	return &SKCController{
 		remoteManager:    rm,
 		policyStore:      ps,
+		sessionStore:     ss,
 		config:           kc,
 		trustedCaCertDir: caCertDir,
 	}
@@ -54,6 +56,7 @@ func (kc *SKCController) TransferApplicationKey(responseWriter http.ResponseWrit
 	keyID := uuid.MustParse(mux.Vars(request)["id"])
 
 	keyInfo := keytransfer.GetKeyInfo()
+	keyInfo.SessionStore = kc.sessionStore
 
 	keyInfo.PopulateStmLabels(stmChallenge, kc.config.Skc.StmLabel)
 
@@ -141,7 +144,7 @@ func (kc *SKCController) TransferApplicationKey(responseWriter http.ResponseWrit
 		}
 
 		defaultLog.Debug("Session is valid. Hence directly transfer the key")
-		keyData, err := kc.remoteManager.TransferKey(keyID)
+		keyData, keyVersion, err := kc.remoteManager.TransferKey(keyID, 0)
 		if err != nil {
 			defaultLog.WithError(err).Error("controllers/skc_controller:TransferApplicationKey() Key retrieve failed")
 			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve key"}
@@ -160,6 +163,7 @@ func (kc *SKCController) TransferApplicationKey(responseWriter http.ResponseWrit
 		outputKeyData.KeyInfo.KeyId = keyID
 		outputKeyData.KeyInfo.KeyData = applicationKey
 		outputKeyData.KeyInfo.KeyLength = key.KeyInformation.KeyLength
+		outputKeyData.KeyInfo.Version = keyVersion
 		outputKeyData.KeyInfo.Policy.Link.KeyTransfer.Href = url
 		outputKeyData.KeyInfo.Policy.Link.KeyTransfer.Method = "get"
 		outputKeyData.Operation = constants.KeyTransferOpertaion
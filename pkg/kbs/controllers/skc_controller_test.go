@@ -15,6 +15,7 @@ import (
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/keymanager"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/kmipclient"
 	kbsRoutes "github.com/intel-secl/intel-secl/v4/pkg/kbs/router"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/session"
 	consts "github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	. "github.com/onsi/ginkgo"
@@ -32,6 +33,7 @@ var _ = Describe("SKCKeyTransferController", func() {
 	var w *httptest.ResponseRecorder
 	var keyStore *mocks.MockKeyStore
 	var policyStore *mocks.MockKeyTransferPolicyStore
+	var sessionStore *session.MemoryStore
 	var remoteManager *keymanager.RemoteManager
 	var skcController *controllers.SKCController
 	var kbsConfig *config.Configuration
@@ -48,6 +50,7 @@ var _ = Describe("SKCKeyTransferController", func() {
 		server = ghttp.NewServer()
 		keyStore = mocks.NewFakeKeyStore()
 		policyStore = mocks.NewFakeKeyTransferPolicyStore()
+		sessionStore = session.NewMemoryStore()
 		kbsConfig = &config.Configuration{
 			AASApiUrl: "http://" + server.Addr() + "/aas/",
 			KBS: config.KBSConfig{
@@ -61,7 +64,7 @@ var _ = Describe("SKCKeyTransferController", func() {
 		}
 
 		remoteManager = keymanager.NewRemoteManager(keyStore, keyManager, endpointUrl)
-		skcController = controllers.NewSKCController(remoteManager, policyStore, kbsConfig, trustedCaCertsDir)
+		skcController = controllers.NewSKCController(remoteManager, policyStore, sessionStore, kbsConfig, trustedCaCertsDir)
 		setupServer(server)
 	})
 
@@ -73,7 +76,7 @@ var _ = Describe("SKCKeyTransferController", func() {
 	Describe("Transfers an existing Key", func() {
 		Context("Provide a valid Transfer request", func() {
 			BeforeEach(func() {
-				sessionController := controllers.NewSessionController(kbsConfig, trustedCaCertsDir)
+				sessionController := controllers.NewSessionController(sessionStore, kbsConfig, trustedCaCertsDir)
 				router.Handle("/session", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(sessionController.Create))).Methods("POST")
 				sessionJson := `{
 									"challenge_type": "SGX",
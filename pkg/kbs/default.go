@@ -17,6 +17,7 @@ import (
 func init() {
 	viper.SetDefault("endpoint-url", constants.DefaultEndpointUrl)
 	viper.SetDefault("key-manager", constants.DefaultKeyManager)
+	viper.SetDefault("store-type", constants.DefaultStoreType)
 
 	// Set default values for tls
 	viper.SetDefault("tls-cert-file", constants.DefaultTLSCertPath)
@@ -32,6 +33,29 @@ func init() {
 	// Set default value for kmip version
 	viper.SetDefault("kmip-version", constants.KMIP_2_0)
 
+	// Set default value for the master key of the directory key manager
+	viper.SetDefault("directory-master-key-path", constants.DefaultMasterKeyPath)
+
+	// Set default value for the automatic key rotation
+	viper.SetDefault("key-rotation-check-interval", constants.DefaultKeyRotationCheckInterval)
+
+	// Set default values for the revocation check of the TLS client certificates
+	viper.SetDefault("revocation-check-enabled", false)
+	viper.SetDefault("revocation-check-fetch-crl", true)
+	viper.SetDefault("revocation-check-ocsp", false)
+	viper.SetDefault("revocation-check-crl-cache-duration", constants.DefaultRevocationCheckCrlCacheDuration)
+	viper.SetDefault("revocation-check-fail-open", false)
+
+	// Set default values for db, used by the postgres store type
+	viper.SetDefault("db-vendor", constants.DBTypePostgres)
+	viper.SetDefault("db-host", "localhost")
+	viper.SetDefault("db-port", "5432")
+	viper.SetDefault("db-name", "kbs_db")
+	viper.SetDefault("db-ssl-mode", constants.SslModeVerifyFull)
+	viper.SetDefault("db-ssl-cert", constants.DefaultSSLCertFilePath)
+	viper.SetDefault("db-conn-retry-attempts", constants.DefaultDbConnRetryAttempts)
+	viper.SetDefault("db-conn-retry-time", constants.DefaultDbConnRetryTime)
+
 	// Set default values for server
 	viper.SetDefault("server-port", constants.DefaultKBSListenerPort)
 	viper.SetDefault("server-read-timeout", constants.DefaultReadTimeout)
@@ -51,6 +75,7 @@ func defaultConfig() *config.Configuration {
 
 		EndpointURL: viper.GetString("endpoint-url"),
 		KeyManager:  viper.GetString("key-manager"),
+		StoreType:   viper.GetString("store-type"),
 
 		KBS: config.KBSConfig{
 			UserName: viper.GetString("kbs-service-username"),
@@ -75,6 +100,19 @@ func defaultConfig() *config.Configuration {
 			IdleTimeout:       viper.GetDuration("server-idle-timeout"),
 			MaxHeaderBytes:    viper.GetInt("server-max-header-bytes"),
 		},
+		DB: commConfig.DBConfig{
+			Vendor:   viper.GetString("db-vendor"),
+			Host:     viper.GetString("db-host"),
+			Port:     viper.GetInt("db-port"),
+			DBName:   viper.GetString("db-name"),
+			Username: viper.GetString("db-username"),
+			Password: viper.GetString("db-password"),
+			SSLMode:  viper.GetString("db-ssl-mode"),
+			SSLCert:  viper.GetString("db-ssl-cert"),
+
+			ConnectionRetryAttempts: viper.GetInt("db-conn-retry-attempts"),
+			ConnectionRetryTime:     viper.GetInt("db-conn-retry-time"),
+		},
 		Kmip: config.KmipConfig{
 			Version:                   viper.GetString("kmip-version"),
 			ServerIP:                  viper.GetString("kmip-server-ip"),
@@ -86,18 +124,51 @@ func defaultConfig() *config.Configuration {
 			ClientCertificateFilePath: viper.GetString("kmip-client-cert-path"),
 			RootCertificateFilePath:   viper.GetString("kmip-root-cert-path"),
 		},
+		Directory: config.DirectoryConfig{
+			MasterKeyFilePath: viper.GetString("directory-master-key-path"),
+		},
+		Pkcs11: config.Pkcs11Config{
+			ModulePath: viper.GetString("pkcs11-module-path"),
+			TokenLabel: viper.GetString("pkcs11-token-label"),
+			UserPin:    viper.GetString("pkcs11-user-pin"),
+		},
 		Skc: config.SKCConfig{
 			StmLabel:          viper.GetString("skc-challenge-type"),
 			SQVSUrl:           viper.GetString("sqvs-url"),
 			SessionExpiryTime: viper.GetInt("session-expiry-time"),
 		},
+		KeyRotation: config.KeyRotationConfig{
+			CheckInterval: viper.GetDuration("key-rotation-check-interval"),
+		},
+		RevocationCheck: revocationCheckConfig(),
+	}
+}
+
+// revocationCheckConfig returns the certificate revocation check configuration set in the environment
+func revocationCheckConfig() commConfig.RevocationCheckConfig {
+	return commConfig.RevocationCheckConfig{
+		Enabled:          viper.GetBool("revocation-check-enabled"),
+		CrlFile:          viper.GetString("revocation-check-crl-file"),
+		FetchCrl:         viper.GetBool("revocation-check-fetch-crl"),
+		Ocsp:             viper.GetBool("revocation-check-ocsp"),
+		CrlCacheDuration: viper.GetDuration("revocation-check-crl-cache-duration"),
+		FailOpen:         viper.GetBool("revocation-check-fail-open"),
 	}
 }
 
 func loadAlias() {
 	alias := map[string]string{
-		"tls-san-list": "SAN_LIST",
-		"aas-base-url": "AAS_API_URL",
+		"tls-san-list":       "SAN_LIST",
+		"aas-base-url":       "AAS_API_URL",
+		"db-vendor":          "KBS_DB_VENDOR",
+		"db-host":            "KBS_DB_HOSTNAME",
+		"db-port":            "KBS_DB_PORT",
+		"db-name":            "KBS_DB_NAME",
+		"db-username":        "KBS_DB_USERNAME",
+		"db-password":        "KBS_DB_PASSWORD",
+		"db-ssl-cert":        "KBS_DB_SSLCERT",
+		"db-ssl-cert-source": "KBS_DB_SSLCERTSRC",
+		"db-ssl-mode":        "KBS_DB_SSL_MODE",
 	}
 	for k, v := range alias {
 		if env := os.Getenv(v); env != "" {
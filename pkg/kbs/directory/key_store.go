@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sync"
 
 	"github.com/google/uuid"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
@@ -19,10 +20,12 @@ import (
 
 type KeyStore struct {
 	dir string
+	// the key files are local to the KBS instance, their rotations only need to be serialized within the process
+	rotationLock sync.Mutex
 }
 
 func NewKeyStore(dir string) *KeyStore {
-	return &KeyStore{dir}
+	return &KeyStore{dir: dir}
 }
 
 func (ks *KeyStore) Create(key *models.KeyAttributes) (*models.KeyAttributes, error) {
@@ -64,6 +67,50 @@ func (ks *KeyStore) Retrieve(id uuid.UUID) (*models.KeyAttributes, error) {
 	return &key, nil
 }
 
+func (ks *KeyStore) Update(key *models.KeyAttributes) (*models.KeyAttributes, error) {
+	defaultLog.Trace("directory/key_store:Update() Entering")
+	defer defaultLog.Trace("directory/key_store:Update() Leaving")
+
+	keyFile := filepath.Join(ks.dir, key.ID.String())
+	if _, err := os.Stat(keyFile); err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New(commErr.RecordNotFound)
+		}
+		return nil, errors.Wrapf(err, "directory/key_store:Update() Unable to read key file : %s", key.ID.String())
+	}
+
+	bytes, err := json.Marshal(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/key_store:Update() Failed to marshal key attributes")
+	}
+
+	err = ioutil.WriteFile(keyFile, bytes, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/key_store:Update() Failed to store key attributes in file")
+	}
+
+	return key, nil
+}
+
+func (ks *KeyStore) Rotate(id uuid.UUID, rotate func(*models.KeyAttributes) (*models.KeyAttributes, error)) (*models.KeyAttributes, error) {
+	defaultLog.Trace("directory/key_store:Rotate() Entering")
+	defer defaultLog.Trace("directory/key_store:Rotate() Leaving")
+
+	ks.rotationLock.Lock()
+	defer ks.rotationLock.Unlock()
+
+	key, err := ks.Retrieve(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rotatedKey, err := rotate(key)
+	if err != nil || rotatedKey == nil {
+		return nil, err
+	}
+	return ks.Update(rotatedKey)
+}
+
 func (ks *KeyStore) Delete(id uuid.UUID) error {
 	defaultLog.Trace("directory/key_store:Delete() Entering")
 	defer defaultLog.Trace("directory/key_store:Delete() Leaving")
@@ -7,8 +7,8 @@ package domain
 import "github.com/google/uuid"
 
 type KeyControllerConfig struct {
-	SamlCertsDir            string
+	SamlCertStore           CertificateStore
 	TrustedCaCertsDir       string
-	TpmIdentityCertsDir     string
+	TpmIdentityCertStore    CertificateStore
 	DefaultTransferPolicyId uuid.UUID
 }
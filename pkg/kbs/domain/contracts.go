@@ -5,6 +5,8 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
 	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
@@ -14,6 +16,10 @@ type (
 	KeyStore interface {
 		Create(*models.KeyAttributes) (*models.KeyAttributes, error)
 		Retrieve(uuid.UUID) (*models.KeyAttributes, error)
+		Update(*models.KeyAttributes) (*models.KeyAttributes, error)
+		// Rotate stores the key attributes returned by rotate for the key, the rotations of a key are serialized
+		// across the KBS instances sharing the store. Nothing is stored when rotate returns nil attributes.
+		Rotate(id uuid.UUID, rotate func(*models.KeyAttributes) (*models.KeyAttributes, error)) (*models.KeyAttributes, error)
 		Delete(uuid.UUID) error
 		Search(criteria *models.KeyFilterCriteria) ([]models.KeyAttributes, error)
 	}
@@ -31,4 +37,24 @@ type (
 		Delete(uuid.UUID) error
 		Search(criteria *models.CertificateFilterCriteria) ([]kbs.Certificate, error)
 	}
+
+	// SessionStore holds the SKC sessions by session id, DeleteExpired removes the sessions expired at the given time
+	// and returns how many were removed
+	SessionStore interface {
+		Create(*kbs.KeyTransferSession) (*kbs.KeyTransferSession, error)
+		Retrieve(string) (*kbs.KeyTransferSession, error)
+		Update(*kbs.KeyTransferSession) (*kbs.KeyTransferSession, error)
+		Delete(string) error
+		Search(criteria *models.SessionFilterCriteria) ([]kbs.KeyTransferSession, error)
+		DeleteExpired(time.Time) (int, error)
+	}
 )
+
+// Stores holds the stores of the KBS resources, backed either by the directories or by the database
+type Stores struct {
+	KeyStore               KeyStore
+	KeyTransferPolicyStore KeyTransferPolicyStore
+	SamlCertStore          CertificateStore
+	TpmIdentityCertStore   CertificateStore
+	SessionStore           SessionStore
+}
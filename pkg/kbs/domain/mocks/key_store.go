@@ -37,6 +37,28 @@ func (store *MockKeyStore) Retrieve(id uuid.UUID) (*models.KeyAttributes, error)
 	return nil, errors.New(commErr.RecordNotFound)
 }
 
+// Update replaces a Key in the store
+func (store *MockKeyStore) Update(k *models.KeyAttributes) (*models.KeyAttributes, error) {
+	if _, ok := store.KeyStore[k.ID]; ok {
+		store.KeyStore[k.ID] = k
+		return k, nil
+	}
+	return nil, errors.New(commErr.RecordNotFound)
+}
+
+// Rotate replaces a Key in the store with the one returned by rotate
+func (store *MockKeyStore) Rotate(id uuid.UUID, rotate func(*models.KeyAttributes) (*models.KeyAttributes, error)) (*models.KeyAttributes, error) {
+	k, err := store.Retrieve(id)
+	if err != nil {
+		return nil, err
+	}
+	rotated, err := rotate(k)
+	if err != nil || rotated == nil {
+		return nil, err
+	}
+	return store.Update(rotated)
+}
+
 // Delete deletes Key from the store
 func (store *MockKeyStore) Delete(id uuid.UUID) error {
 	if _, ok := store.KeyStore[id]; ok {
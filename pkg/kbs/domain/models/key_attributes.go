@@ -13,19 +13,98 @@ import (
 
 // KeyAttributes - Contains all possible key attributes.
 type KeyAttributes struct {
-	ID               uuid.UUID `json:"id"`
-	Algorithm        string    `json:"algorithm"`
-	KeyLength        int       `json:"key_length,omitempty"`
-	KeyData          string    `json:"key,omitempty"`
-	CurveType        string    `json:"curve_type,omitempty"`
-	PublicKey        string    `json:"public_key,omitempty"`
-	PrivateKey       string    `json:"private_key,omitempty"`
-	KmipKeyID        string    `json:"kmip_key_id,omitempty"`
-	TransferPolicyId uuid.UUID `json:"transfer_policy_id,omitempty"`
-	TransferLink     string    `json:"transfer_link,omitempty"`
-	CreatedAt        time.Time `json:"created_at,omitempty"`
-	Label            string    `json:"label,omitempty"`
-	Usage            string    `json:"usage,omitempty"`
+	ID               uuid.UUID           `json:"id"`
+	Algorithm        string              `json:"algorithm"`
+	KeyLength        int                 `json:"key_length,omitempty"`
+	KeyData          string              `json:"key,omitempty"`
+	CurveType        string              `json:"curve_type,omitempty"`
+	PublicKey        string              `json:"public_key,omitempty"`
+	PrivateKey       string              `json:"private_key,omitempty"`
+	KmipKeyID        string              `json:"kmip_key_id,omitempty"`
+	TransferPolicyId uuid.UUID           `json:"transfer_policy_id,omitempty"`
+	TransferLink     string              `json:"transfer_link,omitempty"`
+	CreatedAt        time.Time           `json:"created_at,omitempty"`
+	Label            string              `json:"label,omitempty"`
+	Usage            string              `json:"usage,omitempty"`
+	UsagePolicy      *kbs.KeyUsagePolicy `json:"usage_policy,omitempty"`
+	Version          int                 `json:"version,omitempty"`
+	RotationInterval string              `json:"rotation_interval,omitempty"`
+	RotatedAt        time.Time           `json:"rotated_at,omitempty"`
+	PriorVersions    []KeyVersion        `json:"prior_versions,omitempty"`
+}
+
+// KeyVersion - Contains the key material of a version replaced by a key rotation.
+type KeyVersion struct {
+	Version    int       `json:"version"`
+	KeyData    string    `json:"key,omitempty"`
+	PublicKey  string    `json:"public_key,omitempty"`
+	PrivateKey string    `json:"private_key,omitempty"`
+	KmipKeyID  string    `json:"kmip_key_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ActiveVersion returns the version of the current key material, keys which were never rotated are at version 1
+func (ka *KeyAttributes) ActiveVersion() int {
+	if ka.Version < 1 {
+		return 1
+	}
+	return ka.Version
+}
+
+// ActiveVersionCreatedAt returns the creation time of the current key material
+func (ka *KeyAttributes) ActiveVersionCreatedAt() time.Time {
+	if ka.RotatedAt.IsZero() {
+		return ka.CreatedAt
+	}
+	return ka.RotatedAt
+}
+
+// VersionAttributes returns a copy of the key attributes holding the key material of the given version, 0 being the
+// active version. The second return value is false when the key has no such version.
+func (ka *KeyAttributes) VersionAttributes(version int) (*KeyAttributes, bool) {
+	attributes := *ka
+	attributes.PriorVersions = nil
+	if version == 0 || version == ka.ActiveVersion() {
+		attributes.Version = ka.ActiveVersion()
+		return &attributes, true
+	}
+	for _, kv := range ka.PriorVersions {
+		if kv.Version == version {
+			attributes.Version = kv.Version
+			attributes.KeyData = kv.KeyData
+			attributes.PublicKey = kv.PublicKey
+			attributes.PrivateKey = kv.PrivateKey
+			attributes.KmipKeyID = kv.KmipKeyID
+			return &attributes, true
+		}
+	}
+	return nil, false
+}
+
+// ToKeyVersion returns the key material of the active version, to be kept in the prior versions on rotation
+func (ka *KeyAttributes) ToKeyVersion() KeyVersion {
+	return KeyVersion{
+		Version:    ka.ActiveVersion(),
+		KeyData:    ka.KeyData,
+		PublicKey:  ka.PublicKey,
+		PrivateKey: ka.PrivateKey,
+		KmipKeyID:  ka.KmipKeyID,
+		CreatedAt:  ka.ActiveVersionCreatedAt(),
+	}
+}
+
+// NextRotation returns the time at which the key is due for an automatic rotation, nil when the key has no
+// rotation schedule
+func (ka *KeyAttributes) NextRotation() *time.Time {
+	if ka.RotationInterval == "" {
+		return nil
+	}
+	interval, err := time.ParseDuration(ka.RotationInterval)
+	if err != nil || interval <= 0 {
+		return nil
+	}
+	nextRotation := ka.ActiveVersionCreatedAt().Add(interval)
+	return &nextRotation
 }
 
 func (ka *KeyAttributes) ToKeyResponse() *kbs.KeyResponse {
@@ -38,6 +117,18 @@ func (ka *KeyAttributes) ToKeyResponse() *kbs.KeyResponse {
 		KmipKeyID: ka.KmipKeyID,
 	}
 
+	versions := make([]kbs.KeyVersionInformation, 0, len(ka.PriorVersions)+1)
+	for _, kv := range ka.PriorVersions {
+		versions = append(versions, kbs.KeyVersionInformation{
+			Version:   kv.Version,
+			CreatedAt: kv.CreatedAt,
+		})
+	}
+	versions = append(versions, kbs.KeyVersionInformation{
+		Version:   ka.ActiveVersion(),
+		CreatedAt: ka.ActiveVersionCreatedAt(),
+	})
+
 	keyResponse := kbs.KeyResponse{
 		KeyInformation:   &keyInformation,
 		TransferPolicyID: ka.TransferPolicyId,
@@ -45,6 +136,11 @@ func (ka *KeyAttributes) ToKeyResponse() *kbs.KeyResponse {
 		CreatedAt:        ka.CreatedAt,
 		Label:            ka.Label,
 		Usage:            ka.Usage,
+		UsagePolicy:      ka.UsagePolicy,
+		Version:          ka.ActiveVersion(),
+		Versions:         versions,
+		RotationInterval: ka.RotationInterval,
+		NextRotation:     ka.NextRotation(),
 	}
 
 	return &keyResponse
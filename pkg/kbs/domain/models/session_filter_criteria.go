@@ -0,0 +1,14 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package models
+
+import "time"
+
+// SessionFilterCriteria stores the parameters for filtering the SKC sessions, ActiveAt selects the sessions not yet
+// expired at that time
+type SessionFilterCriteria struct {
+	StmLabel string
+	ActiveAt time.Time
+}
@@ -33,6 +33,8 @@ Available Tasks for setup:
 	all                                 Runs all setup tasks
 	download-ca-cert                    Download CMS root CA certificate
 	download-cert-tls                   Download CA certificate from CMS for tls
+	database                            Setup kbs database when STORE_TYPE is postgres
+	migrate-directory-stores            Migrate the keys, key transfer policies and certificates of the directory stores to the database
 	create-default-key-transfer-policy  Create default key transfer policy for KBS
 	update-service-config               Sets or Updates the Service configuration 
 `
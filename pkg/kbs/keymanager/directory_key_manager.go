@@ -0,0 +1,220 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package keymanager
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+	"github.com/pkg/errors"
+)
+
+const masterKeyLength = 32
+
+// DirectoryManager keeps the key material in the key attributes persisted by the KBS key store. The material
+// is wrapped with AES-256-GCM using a master key read from the KBS configuration directory, the key ID and
+// version are used as additional data so that wrapped material cannot be swapped between keys or versions.
+type DirectoryManager struct {
+	aead cipher.AEAD
+}
+
+// NewDirectoryManager loads the master key from masterKeyFile, the master key is created when the file does
+// not exist
+func NewDirectoryManager(masterKeyFile string) (*DirectoryManager, error) {
+	defaultLog.Trace("keymanager/directory_key_manager:NewDirectoryManager() Entering")
+	defer defaultLog.Trace("keymanager/directory_key_manager:NewDirectoryManager() Leaving")
+
+	masterKey, err := LoadMasterKey(masterKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to initialize master key cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to initialize master key cipher")
+	}
+	return &DirectoryManager{aead: aead}, nil
+}
+
+// LoadMasterKey reads the KBS master key from masterKeyFile, the master key is created when the file does not exist.
+// Besides the key material of the directory key manager, it wraps the SKC session keys persisted in the database.
+func LoadMasterKey(masterKeyFile string) ([]byte, error) {
+	masterKey, err := ioutil.ReadFile(masterKeyFile)
+	if err == nil {
+		if len(masterKey) != masterKeyLength {
+			return nil, errors.Errorf("master key %s must be %d bytes long", masterKeyFile, masterKeyLength)
+		}
+		return masterKey, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to read master key %s", masterKeyFile)
+	}
+
+	defaultLog.Infof("keymanager/directory_key_manager:LoadMasterKey() Creating master key %s", masterKeyFile)
+	masterKey = make([]byte, masterKeyLength)
+	if _, err := rand.Read(masterKey); err != nil {
+		return nil, errors.Wrap(err, "failed to generate master key")
+	}
+	file, err := os.OpenFile(masterKeyFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create master key %s", masterKeyFile)
+	}
+	defer func() {
+		derr := file.Close()
+		if derr != nil {
+			defaultLog.WithError(derr).Error("Error closing master key file")
+		}
+	}()
+	if _, err := file.Write(masterKey); err != nil {
+		return nil, errors.Wrapf(err, "failed to write master key %s", masterKeyFile)
+	}
+	return masterKey, nil
+}
+
+func (dm *DirectoryManager) CreateKey(request *kbs.KeyRequest) (*models.KeyAttributes, error) {
+	defaultLog.Trace("keymanager/directory_key_manager:CreateKey() Entering")
+	defer defaultLog.Trace("keymanager/directory_key_manager:CreateKey() Leaving")
+
+	keyMaterial, err := generateKeyMaterial(request.KeyInformation)
+	if err != nil {
+		return nil, err
+	}
+	return dm.newKeyAttributes(request, keyMaterial)
+}
+
+func (dm *DirectoryManager) DeleteKey(attributes *models.KeyAttributes) error {
+	defaultLog.Trace("keymanager/directory_key_manager:DeleteKey() Entering")
+	defer defaultLog.Trace("keymanager/directory_key_manager:DeleteKey() Leaving")
+
+	// the wrapped key material is removed along with the key attributes by the key store
+	return nil
+}
+
+func (dm *DirectoryManager) RegisterKey(request *kbs.KeyRequest) (*models.KeyAttributes, error) {
+	defaultLog.Trace("keymanager/directory_key_manager:RegisterKey() Entering")
+	defer defaultLog.Trace("keymanager/directory_key_manager:RegisterKey() Leaving")
+
+	keyMaterial, err := parseKeyString(request.KeyInformation)
+	if err != nil {
+		return nil, err
+	}
+	return dm.newKeyAttributes(request, keyMaterial)
+}
+
+func (dm *DirectoryManager) TransferKey(attributes *models.KeyAttributes) ([]byte, error) {
+	defaultLog.Trace("keymanager/directory_key_manager:TransferKey() Entering")
+	defer defaultLog.Trace("keymanager/directory_key_manager:TransferKey() Leaving")
+
+	var wrappedKey string
+	switch attributes.Algorithm {
+	case constants.CRYPTOALG_AES:
+		wrappedKey = attributes.KeyData
+	case constants.CRYPTOALG_RSA, constants.CRYPTOALG_EC:
+		wrappedKey = attributes.PrivateKey
+	default:
+		return nil, errors.Errorf("%s algorithm is not supported", attributes.Algorithm)
+	}
+	if wrappedKey == "" {
+		return nil, errors.New("key is not created with directory key manager")
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(wrappedKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode wrapped key")
+	}
+	nonceSize := dm.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, errors.New("wrapped key is too short")
+	}
+	keyMaterial, err := dm.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], versionedID(attributes.ID, attributes.Version))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unwrap key")
+	}
+	return keyMaterial, nil
+}
+
+func (dm *DirectoryManager) RotateKey(attributes *models.KeyAttributes) (*models.KeyAttributes, error) {
+	defaultLog.Trace("keymanager/directory_key_manager:RotateKey() Entering")
+	defer defaultLog.Trace("keymanager/directory_key_manager:RotateKey() Leaving")
+
+	keyMaterial, err := generateKeyMaterial(keyInformation(attributes))
+	if err != nil {
+		return nil, err
+	}
+	rotated := nextKeyVersion(attributes)
+	if err := dm.wrapKeyMaterial(rotated, keyMaterial); err != nil {
+		return nil, err
+	}
+	return rotated, nil
+}
+
+func (dm *DirectoryManager) newKeyAttributes(request *kbs.KeyRequest, keyMaterial []byte) (*models.KeyAttributes, error) {
+	newUuid, err := uuid.NewRandom()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create new UUID")
+	}
+	keyAttributes := &models.KeyAttributes{
+		ID:               newUuid,
+		Algorithm:        request.KeyInformation.Algorithm,
+		TransferPolicyId: request.TransferPolicyID,
+		CreatedAt:        time.Now().UTC(),
+		Label:            request.Label,
+		Usage:            request.Usage,
+	}
+	if request.KeyInformation.Algorithm == constants.CRYPTOALG_EC {
+		keyAttributes.CurveType = request.KeyInformation.CurveType
+	}
+	if err := dm.wrapKeyMaterial(keyAttributes, keyMaterial); err != nil {
+		return nil, err
+	}
+	return keyAttributes, nil
+}
+
+// wrapKeyMaterial sets the wrapped key material of the version of the key described by keyAttributes
+func (dm *DirectoryManager) wrapKeyMaterial(keyAttributes *models.KeyAttributes, keyMaterial []byte) error {
+	nonce := make([]byte, dm.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return errors.Wrap(err, "failed to generate nonce")
+	}
+	additionalData := versionedID(keyAttributes.ID, keyAttributes.Version)
+	wrappedKey := base64.StdEncoding.EncodeToString(dm.aead.Seal(nonce, nonce, keyMaterial, additionalData))
+
+	switch keyAttributes.Algorithm {
+	case constants.CRYPTOALG_AES:
+		keyAttributes.KeyLength = len(keyMaterial) * 8
+		keyAttributes.KeyData = wrappedKey
+	case constants.CRYPTOALG_RSA:
+		key, err := x509.ParsePKCS1PrivateKey(keyMaterial)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse RSA private key")
+		}
+		keyAttributes.KeyLength = key.N.BitLen()
+		keyAttributes.PrivateKey = wrappedKey
+	case constants.CRYPTOALG_EC:
+		keyAttributes.PrivateKey = wrappedKey
+	}
+	if keyAttributes.PrivateKey != "" {
+		var err error
+		keyAttributes.PublicKey, err = publicKeyPem(keyAttributes.Algorithm, keyMaterial)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
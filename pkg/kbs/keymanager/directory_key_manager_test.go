@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package keymanager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+)
+
+func TestDirectoryManager_Conformance(t *testing.T) {
+	manager, err := NewDirectoryManager(filepath.Join(t.TempDir(), "master-key"))
+	if err != nil {
+		t.Fatalf("NewDirectoryManager() error = %v", err)
+	}
+	runConformanceTests(t, conformanceSuite{
+		manager:    manager,
+		algorithms: map[string]bool{constants.CRYPTOALG_AES: true, constants.CRYPTOALG_RSA: true, constants.CRYPTOALG_EC: true},
+		register:   keyStringRequest,
+	})
+}
+
+func TestDirectoryManager_MasterKey(t *testing.T) {
+	masterKeyFile := filepath.Join(t.TempDir(), "master-key")
+	manager, err := NewDirectoryManager(masterKeyFile)
+	if err != nil {
+		t.Fatalf("NewDirectoryManager() error = %v", err)
+	}
+	info, err := os.Stat(masterKeyFile)
+	if err != nil {
+		t.Fatalf("master key is not created: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("master key permissions are %v, expected 0600", info.Mode().Perm())
+	}
+
+	request := &kbs.KeyRequest{KeyInformation: &kbs.KeyInformation{Algorithm: constants.CRYPTOALG_AES, KeyLength: 256}}
+	attributes, err := manager.CreateKey(request)
+	if err != nil {
+		t.Fatalf("CreateKey() error = %v", err)
+	}
+	keyMaterial, err := manager.TransferKey(attributes)
+	if err != nil {
+		t.Fatalf("TransferKey() error = %v", err)
+	}
+
+	// the master key is reloaded from the file
+	reloaded, err := NewDirectoryManager(masterKeyFile)
+	if err != nil {
+		t.Fatalf("NewDirectoryManager() error = %v", err)
+	}
+	transferred, err := reloaded.TransferKey(attributes)
+	if err != nil || string(transferred) != string(keyMaterial) {
+		t.Fatalf("TransferKey() must unwrap the key with the persisted master key, error = %v", err)
+	}
+
+	// the wrapped material is bound to the key ID
+	swapped := *attributes
+	swapped.ID = uuid.New()
+	if _, err := manager.TransferKey(&swapped); err == nil {
+		t.Fatalf("TransferKey() should fail when the key ID does not match the wrapped key")
+	}
+
+	// the wrapped material is bound to the key version
+	rotated, err := manager.RotateKey(attributes)
+	if err != nil {
+		t.Fatalf("RotateKey() error = %v", err)
+	}
+	swapped = *rotated
+	swapped.Version = 1
+	if _, err := manager.TransferKey(&swapped); err == nil {
+		t.Fatalf("TransferKey() should fail when the key version does not match the wrapped key")
+	}
+
+	// another master key cannot unwrap the key
+	other, err := NewDirectoryManager(filepath.Join(t.TempDir(), "master-key"))
+	if err != nil {
+		t.Fatalf("NewDirectoryManager() error = %v", err)
+	}
+	if _, err := other.TransferKey(attributes); err == nil {
+		t.Fatalf("TransferKey() should fail with another master key")
+	}
+}
+
+func TestDirectoryManager_InvalidMasterKey(t *testing.T) {
+	masterKeyFile := filepath.Join(t.TempDir(), "master-key")
+	if err := ioutil.WriteFile(masterKeyFile, []byte("short"), 0600); err != nil {
+		t.Fatalf("failed to write master key: %v", err)
+	}
+	if _, err := NewDirectoryManager(masterKeyFile); err == nil {
+		t.Fatalf("NewDirectoryManager() should fail with an invalid master key")
+	}
+}
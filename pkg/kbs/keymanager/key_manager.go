@@ -5,8 +5,10 @@
 package keymanager
 
 import (
+	"encoding/binary"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/config"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
@@ -22,7 +24,8 @@ func NewKeyManager(cfg *config.Configuration) (KeyManager, error) {
 	defaultLog.Trace("keymanager/key_manager:NewKeyManager() Entering")
 	defer defaultLog.Trace("keymanager/key_manager:NewKeyManager() Leaving")
 
-	if strings.ToLower(cfg.KeyManager) == constants.KmipKeyManager {
+	switch strings.ToLower(cfg.KeyManager) {
+	case constants.KmipKeyManager:
 		kmipClient := kmipclient.NewKmipClient()
 		err := kmipClient.InitializeClient(cfg.Kmip.Version, cfg.Kmip.ServerIP, cfg.Kmip.ServerPort, cfg.Kmip.Hostname, cfg.Kmip.Username, cfg.Kmip.Password, cfg.Kmip.ClientKeyFilePath, cfg.Kmip.ClientCertificateFilePath, cfg.Kmip.RootCertificateFilePath)
 		if err != nil {
@@ -30,7 +33,25 @@ func NewKeyManager(cfg *config.Configuration) (KeyManager, error) {
 			return nil, errors.New("Failed to initialize KeyManager")
 		}
 		return NewKmipManager(kmipClient), nil
-	} else {
+	case constants.DirectoryKeyManager:
+		masterKeyFile := cfg.Directory.MasterKeyFilePath
+		if masterKeyFile == "" {
+			masterKeyFile = constants.DefaultMasterKeyPath
+		}
+		directoryManager, err := NewDirectoryManager(masterKeyFile)
+		if err != nil {
+			defaultLog.WithError(err).Error("keymanager/key_manager:NewKeyManager() Failed to load master key")
+			return nil, errors.New("Failed to initialize KeyManager")
+		}
+		return directoryManager, nil
+	case constants.Pkcs11KeyManager:
+		pkcs11Manager, err := NewPkcs11Manager(cfg.Pkcs11.ModulePath, cfg.Pkcs11.TokenLabel, cfg.Pkcs11.UserPin)
+		if err != nil {
+			defaultLog.WithError(err).Error("keymanager/key_manager:NewKeyManager() Failed to open PKCS#11 token")
+			return nil, errors.New("Failed to initialize KeyManager")
+		}
+		return pkcs11Manager, nil
+	default:
 		defaultLog.Errorf("keymanager/key_manager:NewKeyManager() No Key Manager supported for provider: %s", cfg.KeyManager)
 		return nil, errors.Errorf("No Key Manager supported for provider: %s", cfg.KeyManager)
 	}
@@ -41,4 +62,40 @@ type KeyManager interface {
 	DeleteKey(*models.KeyAttributes) error
 	RegisterKey(*kbs.KeyRequest) (*models.KeyAttributes, error)
 	TransferKey(*models.KeyAttributes) ([]byte, error)
+	// RotateKey creates the key material of the next version of the key, the returned attributes are a copy of
+	// the given attributes holding the new version and its key material
+	RotateKey(*models.KeyAttributes) (*models.KeyAttributes, error)
+}
+
+// nextKeyVersion returns a copy of the key attributes for the next version of the key, without key material
+func nextKeyVersion(attributes *models.KeyAttributes) *models.KeyAttributes {
+	rotated := *attributes
+	rotated.Version = attributes.ActiveVersion() + 1
+	rotated.KeyData = ""
+	rotated.PublicKey = ""
+	rotated.PrivateKey = ""
+	rotated.KmipKeyID = ""
+	rotated.PriorVersions = nil
+	return &rotated
+}
+
+// keyInformation returns the key information needed to create new key material for the key
+func keyInformation(attributes *models.KeyAttributes) *kbs.KeyInformation {
+	return &kbs.KeyInformation{
+		Algorithm: attributes.Algorithm,
+		KeyLength: attributes.KeyLength,
+		CurveType: attributes.CurveType,
+	}
+}
+
+// versionedID returns the identifier of a version of the key, that is the key ID for the first version and the
+// key ID followed by the big endian version number for the next ones
+func versionedID(id uuid.UUID, version int) []byte {
+	if version <= 1 {
+		return id[:]
+	}
+	versioned := make([]byte, len(id)+4)
+	copy(versioned, id[:])
+	binary.BigEndian.PutUint32(versioned[len(id):], uint32(version))
+	return versioned
 }
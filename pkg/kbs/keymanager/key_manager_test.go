@@ -0,0 +1,317 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package keymanager
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+)
+
+// conformanceSuite describes a key manager under test. register returns the register request of the given key
+// material for the key manager.
+type conformanceSuite struct {
+	manager    KeyManager
+	algorithms map[string]bool
+	register   func(t *testing.T, keyInfo *kbs.KeyInformation, keyMaterial []byte) *kbs.KeyRequest
+}
+
+var conformanceKeys = []struct {
+	name    string
+	keyInfo kbs.KeyInformation
+}{
+	{name: "AES-128", keyInfo: kbs.KeyInformation{Algorithm: constants.CRYPTOALG_AES, KeyLength: 128}},
+	{name: "AES-256", keyInfo: kbs.KeyInformation{Algorithm: constants.CRYPTOALG_AES, KeyLength: 256}},
+	{name: "RSA-2048", keyInfo: kbs.KeyInformation{Algorithm: constants.CRYPTOALG_RSA, KeyLength: 2048}},
+	{name: "EC-secp256r1", keyInfo: kbs.KeyInformation{Algorithm: constants.CRYPTOALG_EC, CurveType: "secp256r1"}},
+	{name: "EC-secp384r1", keyInfo: kbs.KeyInformation{Algorithm: constants.CRYPTOALG_EC, CurveType: "secp384r1"}},
+}
+
+// runConformanceTests checks the behaviour expected from all the key managers: the keys they create or register
+// are transferred in the format expected by the key transfer, that is the raw AES key, the PKCS#1 DER encoded
+// RSA private key or the SEC 1 DER encoded EC private key, can be rotated keeping the prior versions transferable,
+// and can be deleted.
+func runConformanceTests(t *testing.T, suite conformanceSuite) {
+	for _, tt := range conformanceKeys {
+		keyInfo := tt.keyInfo
+		supported := suite.algorithms[keyInfo.Algorithm]
+
+		t.Run("create "+tt.name, func(t *testing.T) {
+			request := &kbs.KeyRequest{
+				KeyInformation:   &keyInfo,
+				TransferPolicyID: uuid.New(),
+				Label:            "conformance",
+				Usage:            "test",
+			}
+			attributes, err := suite.manager.CreateKey(request)
+			if !supported {
+				if err == nil {
+					t.Fatalf("CreateKey() should fail for unsupported algorithm %s", keyInfo.Algorithm)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateKey() error = %v", err)
+			}
+			if attributes.ID == uuid.Nil || attributes.CreatedAt.IsZero() {
+				t.Fatalf("CreateKey() key ID and creation time must be set")
+			}
+			if attributes.Algorithm != keyInfo.Algorithm || attributes.TransferPolicyId != request.TransferPolicyID ||
+				attributes.Label != request.Label || attributes.Usage != request.Usage {
+				t.Fatalf("CreateKey() attributes do not match the request: %+v", attributes)
+			}
+
+			keyMaterial, err := suite.manager.TransferKey(attributes)
+			if err != nil {
+				t.Fatalf("TransferKey() error = %v", err)
+			}
+			verifyKeyMaterial(t, &keyInfo, keyMaterial)
+
+			other, err := suite.manager.CreateKey(request)
+			if err != nil {
+				t.Fatalf("CreateKey() error = %v", err)
+			}
+			otherMaterial, err := suite.manager.TransferKey(other)
+			if err != nil {
+				t.Fatalf("TransferKey() error = %v", err)
+			}
+			if other.ID == attributes.ID || bytes.Equal(otherMaterial, keyMaterial) {
+				t.Fatalf("CreateKey() must create distinct keys")
+			}
+
+			if err := suite.manager.DeleteKey(attributes); err != nil {
+				t.Fatalf("DeleteKey() error = %v", err)
+			}
+			if err := suite.manager.DeleteKey(other); err != nil {
+				t.Fatalf("DeleteKey() error = %v", err)
+			}
+		})
+
+		t.Run("rotate "+tt.name, func(t *testing.T) {
+			if !supported {
+				t.Skipf("%s algorithm is not supported", keyInfo.Algorithm)
+			}
+			attributes, err := suite.manager.CreateKey(&kbs.KeyRequest{KeyInformation: &keyInfo})
+			if err != nil {
+				t.Fatalf("CreateKey() error = %v", err)
+			}
+			keyMaterial, err := suite.manager.TransferKey(attributes)
+			if err != nil {
+				t.Fatalf("TransferKey() error = %v", err)
+			}
+
+			rotated, err := suite.manager.RotateKey(attributes)
+			if err != nil {
+				t.Fatalf("RotateKey() error = %v", err)
+			}
+			if rotated.ID != attributes.ID || rotated.Version != 2 || rotated.Algorithm != attributes.Algorithm {
+				t.Fatalf("RotateKey() must create the version 2 of the key: %+v", rotated)
+			}
+			rotatedMaterial, err := suite.manager.TransferKey(rotated)
+			if err != nil {
+				t.Fatalf("TransferKey() error = %v", err)
+			}
+			verifyKeyMaterial(t, &keyInfo, rotatedMaterial)
+			if bytes.Equal(rotatedMaterial, keyMaterial) {
+				t.Fatalf("RotateKey() must create new key material")
+			}
+
+			// the prior version remains transferable
+			transferred, err := suite.manager.TransferKey(attributes)
+			if err != nil {
+				t.Fatalf("TransferKey() error = %v", err)
+			}
+			if !bytes.Equal(transferred, keyMaterial) {
+				t.Fatalf("TransferKey() must return the key material of the prior version")
+			}
+
+			rotatedAgain, err := suite.manager.RotateKey(rotated)
+			if err != nil {
+				t.Fatalf("RotateKey() error = %v", err)
+			}
+			if rotatedAgain.Version != 3 {
+				t.Fatalf("RotateKey() must create the version 3 of the key, got %d", rotatedAgain.Version)
+			}
+
+			for _, version := range []*models.KeyAttributes{attributes, rotated, rotatedAgain} {
+				if err := suite.manager.DeleteKey(version); err != nil {
+					t.Fatalf("DeleteKey() version %d error = %v", version.Version, err)
+				}
+			}
+		})
+
+		t.Run("register "+tt.name, func(t *testing.T) {
+			keyMaterial, err := generateKeyMaterial(&keyInfo)
+			if err != nil {
+				t.Fatalf("generateKeyMaterial() error = %v", err)
+			}
+			attributes, err := suite.manager.RegisterKey(suite.register(t, &keyInfo, keyMaterial))
+			if !supported {
+				if err == nil {
+					t.Fatalf("RegisterKey() should fail for unsupported algorithm %s", keyInfo.Algorithm)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RegisterKey() error = %v", err)
+			}
+			if attributes.ID == uuid.Nil || attributes.Algorithm != keyInfo.Algorithm {
+				t.Fatalf("RegisterKey() attributes do not match the request: %+v", attributes)
+			}
+
+			transferred, err := suite.manager.TransferKey(attributes)
+			if err != nil {
+				t.Fatalf("TransferKey() error = %v", err)
+			}
+			if !bytes.Equal(transferred, keyMaterial) {
+				t.Fatalf("TransferKey() must return the registered key")
+			}
+
+			if err := suite.manager.DeleteKey(attributes); err != nil {
+				t.Fatalf("DeleteKey() error = %v", err)
+			}
+		})
+	}
+
+	t.Run("unknown algorithm", func(t *testing.T) {
+		request := &kbs.KeyRequest{
+			KeyInformation: &kbs.KeyInformation{Algorithm: "DES", KeyLength: 64},
+		}
+		if _, err := suite.manager.CreateKey(request); err == nil {
+			t.Fatalf("CreateKey() should fail for unknown algorithm")
+		}
+	})
+}
+
+func verifyKeyMaterial(t *testing.T, keyInfo *kbs.KeyInformation, keyMaterial []byte) {
+	switch keyInfo.Algorithm {
+	case constants.CRYPTOALG_AES:
+		if len(keyMaterial)*8 != keyInfo.KeyLength {
+			t.Fatalf("AES key is %d bits long, expected %d", len(keyMaterial)*8, keyInfo.KeyLength)
+		}
+	case constants.CRYPTOALG_RSA:
+		key, err := x509.ParsePKCS1PrivateKey(keyMaterial)
+		if err != nil {
+			t.Fatalf("RSA key is not PKCS#1 encoded: %v", err)
+		}
+		if key.N.BitLen() != keyInfo.KeyLength {
+			t.Fatalf("RSA key is %d bits long, expected %d", key.N.BitLen(), keyInfo.KeyLength)
+		}
+	case constants.CRYPTOALG_EC:
+		key, err := x509.ParseECPrivateKey(keyMaterial)
+		if err != nil {
+			t.Fatalf("EC key is not SEC 1 encoded: %v", err)
+		}
+		curve, _ := ellipticCurve(keyInfo.CurveType)
+		if key.Curve != curve {
+			t.Fatalf("EC key is on curve %s, expected %s", key.Curve.Params().Name, keyInfo.CurveType)
+		}
+	}
+}
+
+// keyStringRequest returns a register request providing the key material in the key_string
+func keyStringRequest(t *testing.T, keyInfo *kbs.KeyInformation, keyMaterial []byte) *kbs.KeyRequest {
+	registerInfo := *keyInfo
+	switch keyInfo.Algorithm {
+	case constants.CRYPTOALG_AES:
+		registerInfo.KeyString = base64.StdEncoding.EncodeToString(keyMaterial)
+	case constants.CRYPTOALG_RSA:
+		registerInfo.KeyString = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: keyMaterial}))
+	case constants.CRYPTOALG_EC:
+		// EC keys are provided PKCS#8 encoded to cover the conversion to SEC 1
+		key, err := x509.ParseECPrivateKey(keyMaterial)
+		if err != nil {
+			t.Fatalf("failed to parse EC key: %v", err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatalf("failed to marshal EC key: %v", err)
+		}
+		registerInfo.KeyString = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	}
+	return &kbs.KeyRequest{KeyInformation: &registerInfo}
+}
+
+func TestParseKeyString(t *testing.T) {
+	rsaKey, err := generateKeyMaterial(&kbs.KeyInformation{Algorithm: constants.CRYPTOALG_RSA, KeyLength: 2048})
+	if err != nil {
+		t.Fatalf("generateKeyMaterial() error = %v", err)
+	}
+	rsaPem := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: rsaKey}))
+	ecKey, err := generateKeyMaterial(&kbs.KeyInformation{Algorithm: constants.CRYPTOALG_EC, CurveType: "prime256v1"})
+	if err != nil {
+		t.Fatalf("generateKeyMaterial() error = %v", err)
+	}
+	ecPem := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecKey}))
+
+	tests := []struct {
+		name    string
+		keyInfo kbs.KeyInformation
+		wantErr bool
+	}{
+		{
+			name:    "AES key",
+			keyInfo: kbs.KeyInformation{Algorithm: "AES", KeyLength: 128, KeyString: base64.StdEncoding.EncodeToString(make([]byte, 16))},
+		},
+		{
+			name:    "negative test - AES key length mismatch",
+			keyInfo: kbs.KeyInformation{Algorithm: "AES", KeyLength: 256, KeyString: base64.StdEncoding.EncodeToString(make([]byte, 16))},
+			wantErr: true,
+		},
+		{
+			name:    "negative test - AES key is not base64",
+			keyInfo: kbs.KeyInformation{Algorithm: "AES", KeyLength: 256, KeyString: "not base64!"},
+			wantErr: true,
+		},
+		{
+			name:    "RSA key",
+			keyInfo: kbs.KeyInformation{Algorithm: "RSA", KeyLength: 2048, KeyString: rsaPem},
+		},
+		{
+			name:    "negative test - RSA key length mismatch",
+			keyInfo: kbs.KeyInformation{Algorithm: "RSA", KeyLength: 3072, KeyString: rsaPem},
+			wantErr: true,
+		},
+		{
+			name:    "negative test - RSA algorithm with EC key",
+			keyInfo: kbs.KeyInformation{Algorithm: "RSA", KeyLength: 2048, KeyString: ecPem},
+			wantErr: true,
+		},
+		{
+			name:    "EC key",
+			keyInfo: kbs.KeyInformation{Algorithm: "EC", CurveType: "secp256r1", KeyString: ecPem},
+		},
+		{
+			name:    "negative test - EC curve mismatch",
+			keyInfo: kbs.KeyInformation{Algorithm: "EC", CurveType: "secp384r1", KeyString: ecPem},
+			wantErr: true,
+		},
+		{
+			name:    "negative test - key string is not PEM",
+			keyInfo: kbs.KeyInformation{Algorithm: "EC", CurveType: "secp256r1", KeyString: "abcd"},
+			wantErr: true,
+		},
+		{
+			name:    "negative test - key string is empty",
+			keyInfo: kbs.KeyInformation{Algorithm: "EC", CurveType: "secp256r1"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseKeyString(&tt.keyInfo)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseKeyString() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
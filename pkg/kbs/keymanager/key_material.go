@@ -0,0 +1,219 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package keymanager
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+	"github.com/pkg/errors"
+)
+
+// The key material handled by the software backed key managers is in the format returned by TransferKey:
+// the raw bytes of AES keys, the PKCS#1 DER encoding of RSA private keys and the SEC 1 DER encoding of
+// EC private keys.
+
+var (
+	oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidNamedCurveP384 = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+	oidNamedCurveP521 = asn1.ObjectIdentifier{1, 3, 132, 0, 35}
+)
+
+// ellipticCurve returns the curve matching the curve_type of a key request
+func ellipticCurve(curveType string) (elliptic.Curve, error) {
+	switch strings.ToLower(curveType) {
+	case "secp256r1", "prime256v1":
+		return elliptic.P256(), nil
+	case "secp384r1":
+		return elliptic.P384(), nil
+	case "secp521r1":
+		return elliptic.P521(), nil
+	default:
+		return nil, errors.Errorf("%s curve type is not supported", curveType)
+	}
+}
+
+func curveOID(curve elliptic.Curve) (asn1.ObjectIdentifier, error) {
+	switch curve {
+	case elliptic.P256():
+		return oidNamedCurveP256, nil
+	case elliptic.P384():
+		return oidNamedCurveP384, nil
+	case elliptic.P521():
+		return oidNamedCurveP521, nil
+	default:
+		return nil, errors.New("curve is not supported")
+	}
+}
+
+func curveFromOID(oid asn1.ObjectIdentifier) (elliptic.Curve, error) {
+	switch {
+	case oid.Equal(oidNamedCurveP256):
+		return elliptic.P256(), nil
+	case oid.Equal(oidNamedCurveP384):
+		return elliptic.P384(), nil
+	case oid.Equal(oidNamedCurveP521):
+		return elliptic.P521(), nil
+	default:
+		return nil, errors.Errorf("curve %s is not supported", oid.String())
+	}
+}
+
+func validateAESKeyLength(keyLength int) error {
+	if keyLength != 128 && keyLength != 192 && keyLength != 256 {
+		return errors.Errorf("%d bits is not a valid AES key length", keyLength)
+	}
+	return nil
+}
+
+// generateKeyMaterial creates a new key for the algorithm of the request
+func generateKeyMaterial(keyInfo *kbs.KeyInformation) ([]byte, error) {
+	switch keyInfo.Algorithm {
+	case constants.CRYPTOALG_AES:
+		if err := validateAESKeyLength(keyInfo.KeyLength); err != nil {
+			return nil, err
+		}
+		key := make([]byte, keyInfo.KeyLength/8)
+		if _, err := rand.Read(key); err != nil {
+			return nil, errors.Wrap(err, "failed to generate AES key")
+		}
+		return key, nil
+	case constants.CRYPTOALG_RSA:
+		if keyInfo.KeyLength < 2048 {
+			return nil, errors.Errorf("%d bits is not a valid RSA key length", keyInfo.KeyLength)
+		}
+		key, err := rsa.GenerateKey(rand.Reader, keyInfo.KeyLength)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate RSA key pair")
+		}
+		return x509.MarshalPKCS1PrivateKey(key), nil
+	case constants.CRYPTOALG_EC:
+		curve, err := ellipticCurve(keyInfo.CurveType)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate EC key pair")
+		}
+		return x509.MarshalECPrivateKey(key)
+	default:
+		return nil, errors.Errorf("%s algorithm is not supported", keyInfo.Algorithm)
+	}
+}
+
+// parseKeyString converts the key_string of a register request to the key material. AES keys are provided
+// base64 encoded, RSA and EC keys are provided as PEM encoded PKCS#1, SEC 1 or PKCS#8 private keys.
+// The key length or the curve type of the request are checked against the provided key when set.
+func parseKeyString(keyInfo *kbs.KeyInformation) ([]byte, error) {
+	if keyInfo.KeyString == "" {
+		return nil, errors.New("key_string cannot be empty for register operation")
+	}
+
+	if keyInfo.Algorithm == constants.CRYPTOALG_AES {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(keyInfo.KeyString))
+		if err != nil {
+			return nil, errors.Wrap(err, "key_string must be base64 encoded for AES keys")
+		}
+		if err := validateAESKeyLength(len(key) * 8); err != nil {
+			return nil, err
+		}
+		if keyInfo.KeyLength != 0 && keyInfo.KeyLength != len(key)*8 {
+			return nil, errors.New("key_string does not match the requested key_length")
+		}
+		return key, nil
+	}
+
+	block, _ := pem.Decode([]byte(keyInfo.KeyString))
+	if block == nil {
+		return nil, errors.New("key_string must be PEM encoded")
+	}
+	privateKey, err := parsePrivateKey(block)
+	if err != nil {
+		return nil, err
+	}
+
+	switch keyInfo.Algorithm {
+	case constants.CRYPTOALG_RSA:
+		key, ok := privateKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("key_string is not an RSA private key")
+		}
+		if keyInfo.KeyLength != 0 && keyInfo.KeyLength != key.N.BitLen() {
+			return nil, errors.New("key_string does not match the requested key_length")
+		}
+		return x509.MarshalPKCS1PrivateKey(key), nil
+	case constants.CRYPTOALG_EC:
+		key, ok := privateKey.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("key_string is not an EC private key")
+		}
+		if keyInfo.CurveType != "" {
+			curve, err := ellipticCurve(keyInfo.CurveType)
+			if err != nil {
+				return nil, err
+			}
+			if curve != key.Curve {
+				return nil, errors.New("key_string does not match the requested curve_type")
+			}
+		}
+		return x509.MarshalECPrivateKey(key)
+	default:
+		return nil, errors.Errorf("%s algorithm is not supported", keyInfo.Algorithm)
+	}
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.PrivateKey, error) {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		return key, errors.Wrap(err, "failed to parse RSA private key")
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		return key, errors.Wrap(err, "failed to parse EC private key")
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		return key, errors.Wrap(err, "failed to parse PKCS#8 private key")
+	default:
+		return nil, errors.Errorf("%s PEM block is not supported", block.Type)
+	}
+}
+
+// publicKeyPem returns the PEM encoded public key of RSA and EC key material
+func publicKeyPem(algorithm string, keyMaterial []byte) (string, error) {
+	var publicKey crypto.PublicKey
+	switch algorithm {
+	case constants.CRYPTOALG_RSA:
+		key, err := x509.ParsePKCS1PrivateKey(keyMaterial)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to parse RSA private key")
+		}
+		publicKey = &key.PublicKey
+	case constants.CRYPTOALG_EC:
+		key, err := x509.ParseECPrivateKey(keyMaterial)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to parse EC private key")
+		}
+		publicKey = &key.PublicKey
+	default:
+		return "", errors.Errorf("%s algorithm has no public key", algorithm)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal public key")
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
@@ -34,24 +34,12 @@ func (km *KmipManager) CreateKey(request *kbs.KeyRequest) (*models.KeyAttributes
 		Usage:            request.Usage,
 	}
 
-	switch request.KeyInformation.Algorithm {
-	case constants.CRYPTOALG_AES:
-		kmipId, err := km.client.CreateSymmetricKey(request.KeyInformation.KeyLength)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to create AES key")
-		}
-		keyAttributes.KeyLength = request.KeyInformation.KeyLength
-		keyAttributes.KmipKeyID = kmipId
-	case constants.CRYPTOALG_RSA:
-		kmipId, err := km.client.CreateAsymmetricKeyPair(constants.CRYPTOALG_RSA, "", request.KeyInformation.KeyLength)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to create RSA key pair")
-		}
-		keyAttributes.KeyLength = request.KeyInformation.KeyLength
-		keyAttributes.KmipKeyID = kmipId
-	default:
-		return nil, errors.Errorf("%s algorithm is not supported", request.KeyInformation.Algorithm)
+	kmipId, err := km.createKey(request.KeyInformation)
+	if err != nil {
+		return nil, err
 	}
+	keyAttributes.KeyLength = request.KeyInformation.KeyLength
+	keyAttributes.KmipKeyID = kmipId
 
 	newUuid, err := uuid.NewRandom()
 	if err != nil {
@@ -63,6 +51,40 @@ func (km *KmipManager) CreateKey(request *kbs.KeyRequest) (*models.KeyAttributes
 	return keyAttributes, nil
 }
 
+func (km *KmipManager) RotateKey(attributes *models.KeyAttributes) (*models.KeyAttributes, error) {
+	defaultLog.Trace("keymanager/kmip_key_manager:RotateKey() Entering")
+	defer defaultLog.Trace("keymanager/kmip_key_manager:RotateKey() Leaving")
+
+	rotated := nextKeyVersion(attributes)
+	kmipId, err := km.createKey(keyInformation(attributes))
+	if err != nil {
+		return nil, err
+	}
+	rotated.KmipKeyID = kmipId
+
+	return rotated, nil
+}
+
+// createKey creates a key on the KMIP server and returns its KMIP identifier
+func (km *KmipManager) createKey(keyInfo *kbs.KeyInformation) (string, error) {
+	switch keyInfo.Algorithm {
+	case constants.CRYPTOALG_AES:
+		kmipId, err := km.client.CreateSymmetricKey(keyInfo.KeyLength)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to create AES key")
+		}
+		return kmipId, nil
+	case constants.CRYPTOALG_RSA:
+		kmipId, err := km.client.CreateAsymmetricKeyPair(constants.CRYPTOALG_RSA, "", keyInfo.KeyLength)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to create RSA key pair")
+		}
+		return kmipId, nil
+	default:
+		return "", errors.Errorf("%s algorithm is not supported", keyInfo.Algorithm)
+	}
+}
+
 func (km *KmipManager) DeleteKey(attributes *models.KeyAttributes) error {
 	defaultLog.Trace("keymanager/kmip_key_manager:DeleteKey() Entering")
 	defer defaultLog.Trace("keymanager/kmip_key_manager:DeleteKey() Leaving")
@@ -81,6 +103,9 @@ func (km *KmipManager) RegisterKey(request *kbs.KeyRequest) (*models.KeyAttribut
 	if request.KeyInformation.KmipKeyID == "" {
 		return nil, errors.New("kmip_key_id cannot be empty for register operation in kmip mode")
 	}
+	if request.KeyInformation.Algorithm != constants.CRYPTOALG_AES && request.KeyInformation.Algorithm != constants.CRYPTOALG_RSA {
+		return nil, errors.Errorf("%s algorithm is not supported", request.KeyInformation.Algorithm)
+	}
 
 	newUuid, err := uuid.NewRandom()
 	if err != nil {
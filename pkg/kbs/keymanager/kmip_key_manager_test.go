@@ -7,9 +7,12 @@ package keymanager
 import (
 	"testing"
 
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/kmipclient"
 	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -237,3 +240,64 @@ func TestKmipManager_TransferKey(t *testing.T) {
 		})
 	}
 }
+
+// fakeKmipClient keeps the keys in memory in the format returned by a KMIP server
+type fakeKmipClient struct {
+	kmipclient.MockKmipClient
+	keys map[string][]byte
+}
+
+func newFakeKmipClient() *fakeKmipClient {
+	return &fakeKmipClient{keys: map[string][]byte{}}
+}
+
+func (c *fakeKmipClient) store(keyMaterial []byte) string {
+	id := uuid.New().String()
+	c.keys[id] = keyMaterial
+	return id
+}
+
+func (c *fakeKmipClient) CreateSymmetricKey(length int) (string, error) {
+	keyMaterial, err := generateKeyMaterial(&kbs.KeyInformation{Algorithm: constants.CRYPTOALG_AES, KeyLength: length})
+	if err != nil {
+		return "", err
+	}
+	return c.store(keyMaterial), nil
+}
+
+func (c *fakeKmipClient) CreateAsymmetricKeyPair(algorithm, curveType string, length int) (string, error) {
+	keyMaterial, err := generateKeyMaterial(&kbs.KeyInformation{Algorithm: algorithm, CurveType: curveType, KeyLength: length})
+	if err != nil {
+		return "", err
+	}
+	return c.store(keyMaterial), nil
+}
+
+func (c *fakeKmipClient) DeleteKey(id string) error {
+	if _, ok := c.keys[id]; !ok {
+		return errors.Errorf("key %s not found", id)
+	}
+	delete(c.keys, id)
+	return nil
+}
+
+func (c *fakeKmipClient) GetKey(id string, algorithm string) ([]byte, error) {
+	keyMaterial, ok := c.keys[id]
+	if !ok {
+		return nil, errors.Errorf("key %s not found", id)
+	}
+	return keyMaterial, nil
+}
+
+func TestKmipManager_Conformance(t *testing.T) {
+	client := newFakeKmipClient()
+	runConformanceTests(t, conformanceSuite{
+		manager:    NewKmipManager(client),
+		algorithms: map[string]bool{constants.CRYPTOALG_AES: true, constants.CRYPTOALG_RSA: true},
+		register: func(t *testing.T, keyInfo *kbs.KeyInformation, keyMaterial []byte) *kbs.KeyRequest {
+			registerInfo := *keyInfo
+			registerInfo.KmipKeyID = client.store(keyMaterial)
+			return &kbs.KeyRequest{KeyInformation: &registerInfo}
+		},
+	})
+}
@@ -0,0 +1,422 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package keymanager
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+	"github.com/miekg/pkcs11"
+	"github.com/pkg/errors"
+)
+
+// Pkcs11Manager keeps the key material on a PKCS#11 token. The token objects are identified by a CKA_ID set to
+// the key ID, followed by the version number for the versions created by a key rotation. Since the purpose of KBS
+// is to release the keys, the private and secret keys are created extractable and non sensitive so that their value
+// can be read back on transfer.
+type Pkcs11Manager struct {
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	// the session is shared between the requests, PKCS#11 sessions cannot be used concurrently
+	lock sync.Mutex
+}
+
+// NewPkcs11Manager loads the PKCS#11 module and logs in the token with the given label
+func NewPkcs11Manager(modulePath, tokenLabel, userPin string) (*Pkcs11Manager, error) {
+	defaultLog.Trace("keymanager/pkcs11_key_manager:NewPkcs11Manager() Entering")
+	defer defaultLog.Trace("keymanager/pkcs11_key_manager:NewPkcs11Manager() Leaving")
+
+	ctx := pkcs11.New(modulePath)
+	if ctx == nil {
+		return nil, errors.Errorf("failed to load PKCS#11 module %s", modulePath)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, errors.Wrap(err, "failed to initialize PKCS#11 module")
+	}
+
+	session, err := openSession(ctx, tokenLabel, userPin)
+	if err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, err
+	}
+	return &Pkcs11Manager{ctx: ctx, session: session}, nil
+}
+
+func openSession(ctx *pkcs11.Ctx, tokenLabel, userPin string) (pkcs11.SessionHandle, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list PKCS#11 slots")
+	}
+	for _, slot := range slots {
+		tokenInfo, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to retrieve token of PKCS#11 slot %d", slot)
+		}
+		if strings.TrimSpace(tokenInfo.Label) != tokenLabel {
+			continue
+		}
+
+		session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to open session on PKCS#11 token %s", tokenLabel)
+		}
+		err = ctx.Login(session, pkcs11.CKU_USER, userPin)
+		if err != nil && err != pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+			_ = ctx.CloseSession(session)
+			return 0, errors.Wrapf(err, "failed to login PKCS#11 token %s", tokenLabel)
+		}
+		return session, nil
+	}
+	return 0, errors.Errorf("PKCS#11 token %s not found", tokenLabel)
+}
+
+func (pm *Pkcs11Manager) CreateKey(request *kbs.KeyRequest) (*models.KeyAttributes, error) {
+	defaultLog.Trace("keymanager/pkcs11_key_manager:CreateKey() Entering")
+	defer defaultLog.Trace("keymanager/pkcs11_key_manager:CreateKey() Leaving")
+
+	keyAttributes, err := newPkcs11KeyAttributes(request)
+	if err != nil {
+		return nil, err
+	}
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	if err := pm.generateKey(keyAttributes.ID[:], request.KeyInformation); err != nil {
+		return nil, err
+	}
+	if request.KeyInformation.Algorithm == constants.CRYPTOALG_EC {
+		keyAttributes.CurveType = request.KeyInformation.CurveType
+	} else {
+		keyAttributes.KeyLength = request.KeyInformation.KeyLength
+	}
+	return keyAttributes, nil
+}
+
+func (pm *Pkcs11Manager) RotateKey(attributes *models.KeyAttributes) (*models.KeyAttributes, error) {
+	defaultLog.Trace("keymanager/pkcs11_key_manager:RotateKey() Entering")
+	defer defaultLog.Trace("keymanager/pkcs11_key_manager:RotateKey() Leaving")
+
+	rotated := nextKeyVersion(attributes)
+
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	if err := pm.generateKey(versionedID(rotated.ID, rotated.Version), keyInformation(attributes)); err != nil {
+		return nil, err
+	}
+	return rotated, nil
+}
+
+// generateKey creates the token objects of a new key identified by id
+func (pm *Pkcs11Manager) generateKey(id []byte, keyInfo *kbs.KeyInformation) error {
+	switch keyInfo.Algorithm {
+	case constants.CRYPTOALG_AES:
+		if err := validateAESKeyLength(keyInfo.KeyLength); err != nil {
+			return err
+		}
+		template := append(secretKeyTemplate(id), pkcs11.NewAttribute(pkcs11.CKA_VALUE_LEN, keyInfo.KeyLength/8))
+		_, err := pm.ctx.GenerateKey(pm.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_AES_KEY_GEN, nil)}, template)
+		if err != nil {
+			return errors.Wrap(err, "failed to create AES key")
+		}
+	case constants.CRYPTOALG_RSA:
+		if keyInfo.KeyLength < 2048 {
+			return errors.Errorf("%d bits is not a valid RSA key length", keyInfo.KeyLength)
+		}
+		publicTemplate := append(publicKeyTemplate(id, pkcs11.CKK_RSA),
+			pkcs11.NewAttribute(pkcs11.CKA_MODULUS_BITS, keyInfo.KeyLength),
+			pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, []byte{1, 0, 1}))
+		_, _, err := pm.ctx.GenerateKeyPair(pm.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS_KEY_PAIR_GEN, nil)},
+			publicTemplate, privateKeyTemplate(id, pkcs11.CKK_RSA))
+		if err != nil {
+			return errors.Wrap(err, "failed to create RSA key pair")
+		}
+	case constants.CRYPTOALG_EC:
+		curve, err := ellipticCurve(keyInfo.CurveType)
+		if err != nil {
+			return err
+		}
+		ecParams, err := ecParams(curve)
+		if err != nil {
+			return err
+		}
+		publicTemplate := append(publicKeyTemplate(id, pkcs11.CKK_EC), pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ecParams))
+		_, _, err = pm.ctx.GenerateKeyPair(pm.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)},
+			publicTemplate, privateKeyTemplate(id, pkcs11.CKK_EC))
+		if err != nil {
+			return errors.Wrap(err, "failed to create EC key pair")
+		}
+	default:
+		return errors.Errorf("%s algorithm is not supported", keyInfo.Algorithm)
+	}
+	return nil
+}
+
+func (pm *Pkcs11Manager) DeleteKey(attributes *models.KeyAttributes) error {
+	defaultLog.Trace("keymanager/pkcs11_key_manager:DeleteKey() Entering")
+	defer defaultLog.Trace("keymanager/pkcs11_key_manager:DeleteKey() Leaving")
+
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	objects, err := pm.findObjects([]*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_ID, versionedID(attributes.ID, attributes.Version))})
+	if err != nil {
+		return err
+	}
+	if len(objects) == 0 {
+		return errors.New("key is not created with PKCS#11 key manager")
+	}
+	for _, object := range objects {
+		if err := pm.ctx.DestroyObject(pm.session, object); err != nil {
+			return errors.Wrap(err, "failed to delete PKCS#11 object")
+		}
+	}
+	return nil
+}
+
+func (pm *Pkcs11Manager) RegisterKey(request *kbs.KeyRequest) (*models.KeyAttributes, error) {
+	defaultLog.Trace("keymanager/pkcs11_key_manager:RegisterKey() Entering")
+	defer defaultLog.Trace("keymanager/pkcs11_key_manager:RegisterKey() Leaving")
+
+	keyMaterial, err := parseKeyString(request.KeyInformation)
+	if err != nil {
+		return nil, err
+	}
+	keyAttributes, err := newPkcs11KeyAttributes(request)
+	if err != nil {
+		return nil, err
+	}
+	id := keyAttributes.ID[:]
+
+	var template []*pkcs11.Attribute
+	switch request.KeyInformation.Algorithm {
+	case constants.CRYPTOALG_AES:
+		template = append(secretKeyTemplate(id), pkcs11.NewAttribute(pkcs11.CKA_VALUE, keyMaterial))
+		keyAttributes.KeyLength = len(keyMaterial) * 8
+	case constants.CRYPTOALG_RSA:
+		key, err := x509.ParsePKCS1PrivateKey(keyMaterial)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse RSA private key")
+		}
+		if len(key.Primes) != 2 {
+			return nil, errors.New("multi-prime RSA keys are not supported")
+		}
+		template = append(privateKeyTemplate(id, pkcs11.CKK_RSA),
+			pkcs11.NewAttribute(pkcs11.CKA_MODULUS, key.N.Bytes()),
+			pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, big.NewInt(int64(key.E)).Bytes()),
+			pkcs11.NewAttribute(pkcs11.CKA_PRIVATE_EXPONENT, key.D.Bytes()),
+			pkcs11.NewAttribute(pkcs11.CKA_PRIME_1, key.Primes[0].Bytes()),
+			pkcs11.NewAttribute(pkcs11.CKA_PRIME_2, key.Primes[1].Bytes()),
+			pkcs11.NewAttribute(pkcs11.CKA_EXPONENT_1, key.Precomputed.Dp.Bytes()),
+			pkcs11.NewAttribute(pkcs11.CKA_EXPONENT_2, key.Precomputed.Dq.Bytes()),
+			pkcs11.NewAttribute(pkcs11.CKA_COEFFICIENT, key.Precomputed.Qinv.Bytes()))
+		keyAttributes.KeyLength = key.N.BitLen()
+	case constants.CRYPTOALG_EC:
+		key, err := x509.ParseECPrivateKey(keyMaterial)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse EC private key")
+		}
+		ecParams, err := ecParams(key.Curve)
+		if err != nil {
+			return nil, err
+		}
+		template = append(privateKeyTemplate(id, pkcs11.CKK_EC),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ecParams),
+			pkcs11.NewAttribute(pkcs11.CKA_VALUE, key.D.FillBytes(make([]byte, (key.Curve.Params().BitSize+7)/8))))
+		keyAttributes.CurveType = request.KeyInformation.CurveType
+	default:
+		return nil, errors.Errorf("%s algorithm is not supported", request.KeyInformation.Algorithm)
+	}
+
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	if _, err := pm.ctx.CreateObject(pm.session, template); err != nil {
+		return nil, errors.Wrap(err, "failed to import key in PKCS#11 token")
+	}
+	return keyAttributes, nil
+}
+
+func (pm *Pkcs11Manager) TransferKey(attributes *models.KeyAttributes) ([]byte, error) {
+	defaultLog.Trace("keymanager/pkcs11_key_manager:TransferKey() Entering")
+	defer defaultLog.Trace("keymanager/pkcs11_key_manager:TransferKey() Leaving")
+
+	var class uint
+	var valueTypes []uint
+	switch attributes.Algorithm {
+	case constants.CRYPTOALG_AES:
+		class = pkcs11.CKO_SECRET_KEY
+		valueTypes = []uint{pkcs11.CKA_VALUE}
+	case constants.CRYPTOALG_RSA:
+		class = pkcs11.CKO_PRIVATE_KEY
+		valueTypes = []uint{pkcs11.CKA_MODULUS, pkcs11.CKA_PUBLIC_EXPONENT, pkcs11.CKA_PRIVATE_EXPONENT, pkcs11.CKA_PRIME_1, pkcs11.CKA_PRIME_2}
+	case constants.CRYPTOALG_EC:
+		class = pkcs11.CKO_PRIVATE_KEY
+		valueTypes = []uint{pkcs11.CKA_EC_PARAMS, pkcs11.CKA_VALUE}
+	default:
+		return nil, errors.Errorf("%s algorithm is not supported", attributes.Algorithm)
+	}
+
+	values, err := pm.readKey(versionedID(attributes.ID, attributes.Version), class, valueTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch attributes.Algorithm {
+	case constants.CRYPTOALG_AES:
+		return values[pkcs11.CKA_VALUE], nil
+	case constants.CRYPTOALG_RSA:
+		key := &rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{
+				N: new(big.Int).SetBytes(values[pkcs11.CKA_MODULUS]),
+				E: int(new(big.Int).SetBytes(values[pkcs11.CKA_PUBLIC_EXPONENT]).Int64()),
+			},
+			D: new(big.Int).SetBytes(values[pkcs11.CKA_PRIVATE_EXPONENT]),
+			Primes: []*big.Int{
+				new(big.Int).SetBytes(values[pkcs11.CKA_PRIME_1]),
+				new(big.Int).SetBytes(values[pkcs11.CKA_PRIME_2]),
+			},
+		}
+		if err := key.Validate(); err != nil {
+			return nil, errors.Wrap(err, "invalid RSA private key read from PKCS#11 token")
+		}
+		key.Precompute()
+		return x509.MarshalPKCS1PrivateKey(key), nil
+	default:
+		var oid asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(values[pkcs11.CKA_EC_PARAMS], &oid); err != nil {
+			return nil, errors.Wrap(err, "failed to parse EC parameters read from PKCS#11 token")
+		}
+		curve, err := curveFromOID(oid)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{Curve: curve},
+			D:         new(big.Int).SetBytes(values[pkcs11.CKA_VALUE]),
+		}
+		key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(values[pkcs11.CKA_VALUE])
+		return x509.MarshalECPrivateKey(key)
+	}
+}
+
+// readKey returns the values of the requested attributes of the token object holding the key
+func (pm *Pkcs11Manager) readKey(id []byte, class uint, valueTypes []uint) (map[uint][]byte, error) {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	objects, err := pm.findObjects([]*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) != 1 {
+		return nil, errors.New("key is not created with PKCS#11 key manager")
+	}
+
+	template := make([]*pkcs11.Attribute, len(valueTypes))
+	for i, valueType := range valueTypes {
+		template[i] = pkcs11.NewAttribute(valueType, nil)
+	}
+	attributes, err := pm.ctx.GetAttributeValue(pm.session, objects[0], template)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read key from PKCS#11 token")
+	}
+	values := make(map[uint][]byte, len(attributes))
+	for _, attribute := range attributes {
+		values[attribute.Type] = attribute.Value
+	}
+	return values, nil
+}
+
+func (pm *Pkcs11Manager) findObjects(template []*pkcs11.Attribute) ([]pkcs11.ObjectHandle, error) {
+	if err := pm.ctx.FindObjectsInit(pm.session, template); err != nil {
+		return nil, errors.Wrap(err, "failed to search PKCS#11 objects")
+	}
+	objects, _, err := pm.ctx.FindObjects(pm.session, 10)
+	if ferr := pm.ctx.FindObjectsFinal(pm.session); ferr != nil && err == nil {
+		err = ferr
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to search PKCS#11 objects")
+	}
+	return objects, nil
+}
+
+func newPkcs11KeyAttributes(request *kbs.KeyRequest) (*models.KeyAttributes, error) {
+	newUuid, err := uuid.NewRandom()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create new UUID")
+	}
+	return &models.KeyAttributes{
+		ID:               newUuid,
+		Algorithm:        request.KeyInformation.Algorithm,
+		TransferPolicyId: request.TransferPolicyID,
+		CreatedAt:        time.Now().UTC(),
+		Label:            request.Label,
+		Usage:            request.Usage,
+	}, nil
+}
+
+func ecParams(curve elliptic.Curve) ([]byte, error) {
+	oid, err := curveOID(curve)
+	if err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(oid)
+	return params, errors.Wrap(err, "failed to marshal EC parameters")
+}
+
+func secretKeyTemplate(id []byte) []*pkcs11.Attribute {
+	return []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_AES),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_ENCRYPT, true),
+		pkcs11.NewAttribute(pkcs11.CKA_DECRYPT, true),
+	}
+}
+
+func publicKeyTemplate(id []byte, keyType uint) []*pkcs11.Attribute {
+	return []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, keyType),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+	}
+}
+
+func privateKeyTemplate(id []byte, keyType uint) []*pkcs11.Attribute {
+	return []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, keyType),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+	}
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package keymanager
+
+import (
+	"os"
+	"testing"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+)
+
+// The PKCS#11 tests run against a token initialized for the test, e.g. with SoftHSM:
+//
+//	softhsm2-util --init-token --free --label kbs-test --pin 1234 --so-pin 1234
+//	KBS_TEST_PKCS11_MODULE=/usr/lib/softhsm/libsofthsm2.so KBS_TEST_PKCS11_TOKEN_LABEL=kbs-test \
+//	KBS_TEST_PKCS11_USER_PIN=1234 go test ./pkg/kbs/keymanager/
+func newTestPkcs11Manager(t *testing.T) *Pkcs11Manager {
+	modulePath := os.Getenv("KBS_TEST_PKCS11_MODULE")
+	if modulePath == "" {
+		t.Skip("KBS_TEST_PKCS11_MODULE is not set, skipping PKCS#11 tests")
+	}
+	manager, err := NewPkcs11Manager(modulePath, os.Getenv("KBS_TEST_PKCS11_TOKEN_LABEL"), os.Getenv("KBS_TEST_PKCS11_USER_PIN"))
+	if err != nil {
+		t.Fatalf("NewPkcs11Manager() error = %v", err)
+	}
+	return manager
+}
+
+func TestPkcs11Manager_Conformance(t *testing.T) {
+	manager := newTestPkcs11Manager(t)
+	runConformanceTests(t, conformanceSuite{
+		manager:    manager,
+		algorithms: map[string]bool{constants.CRYPTOALG_AES: true, constants.CRYPTOALG_RSA: true, constants.CRYPTOALG_EC: true},
+		register:   keyStringRequest,
+	})
+}
+
+func TestPkcs11Manager_DeleteKey(t *testing.T) {
+	manager := newTestPkcs11Manager(t)
+	request := &kbs.KeyRequest{KeyInformation: &kbs.KeyInformation{Algorithm: constants.CRYPTOALG_EC, CurveType: "secp256r1"}}
+	attributes, err := manager.CreateKey(request)
+	if err != nil {
+		t.Fatalf("CreateKey() error = %v", err)
+	}
+	if err := manager.DeleteKey(attributes); err != nil {
+		t.Fatalf("DeleteKey() error = %v", err)
+	}
+	if _, err := manager.TransferKey(attributes); err == nil {
+		t.Fatalf("TransferKey() should fail once the key is deleted")
+	}
+	if err := manager.DeleteKey(attributes); err == nil {
+		t.Fatalf("DeleteKey() should fail once the key is deleted")
+	}
+}
+
+func TestNewPkcs11Manager_InvalidModule(t *testing.T) {
+	if _, err := NewPkcs11Manager("/nonexistent/libpkcs11.so", "kbs-test", "1234"); err == nil {
+		t.Fatalf("NewPkcs11Manager() should fail with an invalid module")
+	}
+}
@@ -7,13 +7,18 @@ package keymanager
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
 	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+	"github.com/pkg/errors"
 )
 
+// KeyVersionNotFound is returned by TransferKey when the key has no such version
+const KeyVersionNotFound = "key version not found"
+
 type RemoteManager struct {
 	store       domain.KeyStore
 	manager     KeyManager
@@ -38,6 +43,8 @@ func (rm *RemoteManager) CreateKey(request *kbs.KeyRequest) (*kbs.KeyResponse, e
 	}
 
 	keyAttributes.TransferLink = rm.getTransferLink(keyAttributes.ID)
+	keyAttributes.UsagePolicy = request.UsagePolicy
+	keyAttributes.RotationInterval = request.RotationInterval
 	storedKey, err := rm.store.Create(keyAttributes)
 	if err != nil {
 		return nil, err
@@ -67,7 +74,14 @@ func (rm *RemoteManager) DeleteKey(keyId uuid.UUID) error {
 		return err
 	}
 
-	if err := rm.manager.DeleteKey(keyAttributes); err != nil {
+	for _, keyVersion := range keyAttributes.PriorVersions {
+		versionAttributes, _ := keyAttributes.VersionAttributes(keyVersion.Version)
+		if err := rm.manager.DeleteKey(versionAttributes); err != nil {
+			return err
+		}
+	}
+	versionAttributes, _ := keyAttributes.VersionAttributes(0)
+	if err := rm.manager.DeleteKey(versionAttributes); err != nil {
 		return err
 	}
 
@@ -101,6 +115,8 @@ func (rm *RemoteManager) RegisterKey(request *kbs.KeyRequest) (*kbs.KeyResponse,
 	}
 
 	keyAttributes.TransferLink = rm.getTransferLink(keyAttributes.ID)
+	keyAttributes.UsagePolicy = request.UsagePolicy
+	keyAttributes.RotationInterval = request.RotationInterval
 	storedKey, err := rm.store.Create(keyAttributes)
 	if err != nil {
 		return nil, err
@@ -109,16 +125,116 @@ func (rm *RemoteManager) RegisterKey(request *kbs.KeyRequest) (*kbs.KeyResponse,
 	return storedKey.ToKeyResponse(), nil
 }
 
-func (rm *RemoteManager) TransferKey(keyId uuid.UUID) ([]byte, error) {
+// TransferKey returns the key material of the given version of the key along with the version number, the active
+// version is transferred when version is 0
+func (rm *RemoteManager) TransferKey(keyId uuid.UUID, version int) ([]byte, int, error) {
 	defaultLog.Trace("keymanager/remote_key_manager:TransferKey() Entering")
 	defer defaultLog.Trace("keymanager/remote_key_manager:TransferKey() Leaving")
 
 	keyAttributes, err := rm.store.Retrieve(keyId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	versionAttributes, ok := keyAttributes.VersionAttributes(version)
+	if !ok {
+		return nil, 0, errors.New(KeyVersionNotFound)
+	}
+
+	keyMaterial, err := rm.manager.TransferKey(versionAttributes)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return keyMaterial, versionAttributes.Version, nil
+}
+
+// RotateKey creates a new version of the key, the prior versions remain transferable. The rotation interval of the
+// key is replaced when it is provided in the request.
+func (rm *RemoteManager) RotateKey(keyId uuid.UUID, request *kbs.KeyRotateRequest) (*kbs.KeyResponse, error) {
+	defaultLog.Trace("keymanager/remote_key_manager:RotateKey() Entering")
+	defer defaultLog.Trace("keymanager/remote_key_manager:RotateKey() Leaving")
+
+	rotatedKey, err := rm.rotateKey(keyId, func(keyAttributes *models.KeyAttributes) bool {
+		if request != nil && request.RotationInterval != nil {
+			keyAttributes.RotationInterval = *request.RotationInterval
+		}
+		return true
+	}, time.Now().UTC())
 	if err != nil {
 		return nil, err
 	}
 
-	return rm.manager.TransferKey(keyAttributes)
+	return rotatedKey.ToKeyResponse(), nil
+}
+
+// RotateDueKeys rotates the keys whose automatic rotation is due at the given time and returns the number of rotated
+// keys. A key failing to rotate does not prevent the rotation of the other keys.
+func (rm *RemoteManager) RotateDueKeys(now time.Time) (int, error) {
+	defaultLog.Trace("keymanager/remote_key_manager:RotateDueKeys() Entering")
+	defer defaultLog.Trace("keymanager/remote_key_manager:RotateDueKeys() Leaving")
+
+	keyAttributesList, err := rm.store.Search(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var rotatedKeys int
+	for _, keyAttributes := range keyAttributesList {
+		if !isRotationDue(&keyAttributes, now) {
+			continue
+		}
+		// the rotation is checked again once the key is locked, the key may have been rotated or deleted in the meantime
+		rotatedKey, err := rm.rotateKey(keyAttributes.ID, func(lockedKey *models.KeyAttributes) bool {
+			return isRotationDue(lockedKey, now)
+		}, now)
+		if err != nil {
+			defaultLog.WithError(err).Errorf("keymanager/remote_key_manager:RotateDueKeys() Failed to rotate key %s", keyAttributes.ID)
+			continue
+		}
+		if rotatedKey != nil {
+			defaultLog.Infof("keymanager/remote_key_manager:RotateDueKeys() Key %s rotated to version %d", keyAttributes.ID, rotatedKey.Version)
+			rotatedKeys++
+		}
+	}
+
+	return rotatedKeys, nil
+}
+
+// rotateKey creates the next version of the key and stores it while the key is locked in the store, the active version
+// is moved to the prior versions. The key is left unchanged and nil is returned when prepare returns false.
+func (rm *RemoteManager) rotateKey(keyId uuid.UUID, prepare func(*models.KeyAttributes) bool, now time.Time) (*models.KeyAttributes, error) {
+	var createdKey *models.KeyAttributes
+	rotatedKey, err := rm.store.Rotate(keyId, func(keyAttributes *models.KeyAttributes) (*models.KeyAttributes, error) {
+		if !prepare(keyAttributes) {
+			return nil, nil
+		}
+		rotatedKey, err := rm.manager.RotateKey(keyAttributes)
+		if err != nil {
+			return nil, err
+		}
+		createdKey = rotatedKey
+
+		rotatedKey.PriorVersions = append(append([]models.KeyVersion{}, keyAttributes.PriorVersions...), keyAttributes.ToKeyVersion())
+		rotatedKey.RotatedAt = now
+		return rotatedKey, nil
+	})
+	if err != nil {
+		if createdKey != nil {
+			// the new version is not referenced by the store, its key material is released
+			if derr := rm.manager.DeleteKey(createdKey); derr != nil {
+				defaultLog.WithError(derr).Errorf("keymanager/remote_key_manager:rotateKey() Failed to delete version %d of key %s", createdKey.Version, createdKey.ID)
+			}
+		}
+		return nil, err
+	}
+
+	return rotatedKey, nil
+}
+
+func isRotationDue(keyAttributes *models.KeyAttributes, now time.Time) bool {
+	nextRotation := keyAttributes.NextRotation()
+	return nextRotation != nil && !nextRotation.After(now)
 }
 
 func (rm *RemoteManager) getTransferLink(keyId uuid.UUID) string {
@@ -24,7 +24,9 @@ import (
 	aasClient "github.com/intel-secl/intel-secl/v4/pkg/clients/aas"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/config"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
 	commLogMsg "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/message"
 	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
@@ -38,7 +40,8 @@ const (
 	wrapSize = 4
 )
 
-// KeyDetails - Key info for skc transfer application key
+// KeyDetails - Key info for skc transfer application key. SessionIDMap holds the sessions presented by the
+// skc_library, the sessions themselves are kept in the SessionStore.
 type KeyDetails struct {
 	IssuerCommonName         string
 	ActiveStmLabel           string
@@ -48,8 +51,7 @@ type KeyDetails struct {
 	FinalStmLabels           []string
 	TransferPolicyAttributes *kbs.KeyTransferPolicyAttributes
 	SessionIDMap             map[string]string
-	SessionMap               map[string]kbs.KeyTransferSession
-	SessionResponseMap       map[string]kbs.QuoteVerifyAttributes
+	SessionStore             domain.SessionStore
 }
 
 var keyInfo *KeyDetails
@@ -61,8 +63,6 @@ func InitializeKeyInfo() *KeyDetails {
 	defer defaultLog.Trace("keytransfer/skc_key_transfer:InitializeKeyInfo() Leaving")
 	keyInfo := new(KeyDetails)
 	keyInfo.SessionIDMap = make(map[string]string)
-	keyInfo.SessionMap = make(map[string]kbs.KeyTransferSession)
-	keyInfo.SessionResponseMap = make(map[string]kbs.QuoteVerifyAttributes)
 	return keyInfo
 }
 
@@ -335,21 +335,19 @@ func (keyInfo *KeyDetails) IsValidSession(stmLabel string) (validSession, validS
 	defer defaultLog.Trace("keytransfer/skc_key_transfer:IsValidSession() leaving")
 
 	var sessionID string
+	var keyTransferSession kbs.KeyTransferSession
 	sessionFound := false
 	for _, value := range keyInfo.SessionIDMap {
 		sessionID = value
-		_, session := keyInfo.SessionMap[sessionID]
+		keyTransferSession = keyInfo.GetSessionObj(sessionID)
 		// ensure that session id and the stmlabel in key transfer request
-		// are the same as in session map
-		if session && keyInfo.SessionMap[sessionID].Stmlabel == stmLabel {
+		// are the same as in session store
+		if keyTransferSession.SessionId != "" && keyTransferSession.Stmlabel == stmLabel {
 			sessionFound = true
 
-			expiryTime := keyInfo.SessionMap[sessionID].SessionExpiryTime
-
-			if expiryTime.Before(time.Now()) {
+			if keyTransferSession.SessionExpiryTime.Before(time.Now()) {
 				defaultLog.Debug("session has expired hence exiting")
-				// delete session from map
-				delete(keyInfo.SessionMap, sessionID)
+				keyInfo.deleteSession(sessionID)
 				return true, true, false
 			}
 			break
@@ -357,10 +355,12 @@ func (keyInfo *KeyDetails) IsValidSession(stmLabel string) (validSession, validS
 	}
 
 	if sessionFound {
-		keyTransferSession := keyInfo.GetSessionObj(sessionID)
 		if keyInfo.ClientCertSHA == keyTransferSession.ClientCertHash {
 			if keyInfo.ActiveStmLabel == constants.DefaultSGXLabel {
-				attributes := keyInfo.SessionResponseMap[sessionID]
+				var attributes kbs.QuoteVerifyAttributes
+				if keyTransferSession.QuoteVerifyAttributes != nil {
+					attributes = *keyTransferSession.QuoteVerifyAttributes
+				}
 				if keyInfo.TransferPolicyAttributes.SGXEnforceTCBUptoDate && attributes.TCBLevel == constants.TCBLevelOutOfDate {
 					defaultLog.Debug("keytransfer/skc_key_transfer:IsValidSession() Platform TCB Status is Out of Date")
 					return true, false, true
@@ -376,8 +376,7 @@ func (keyInfo *KeyDetails) IsValidSession(stmLabel string) (validSession, validS
 					defaultLog.Debug("keytransfer/skc_key_transfer:IsValidSession() All sgx attributes in stm attestation report match key transfer policy")
 					return true, true, true
 				} else {
-					// delete session from map
-					delete(keyInfo.SessionMap, sessionID)
+					keyInfo.deleteSession(sessionID)
 					defaultLog.Debug("keytransfer/skc_key_transfer:IsValidSession() Sgx attribute validation failed")
 					return true, false, true
 				}
@@ -391,15 +390,14 @@ func (keyInfo *KeyDetails) IsValidSession(stmLabel string) (validSession, validS
 	return false, false, false
 }
 
-func (keyInfo *KeyDetails) deleteExpiredSessions() {
-
-	defaultLog.Trace("keytransfer/skc_key_transfer:deleteExpiredSessions() entering")
-	defer defaultLog.Trace("keytransfer/skc_key_transfer:deleteExpiredSessions() leaving")
+// deleteSession removes the session from the session store, the expired sessions that are not presented again are
+// removed by the periodic cleanup of the store
+func (keyInfo *KeyDetails) deleteSession(sessionID string) {
+	defaultLog.Trace("keytransfer/skc_key_transfer:deleteSession() entering")
+	defer defaultLog.Trace("keytransfer/skc_key_transfer:deleteSession() leaving")
 
-	for k := range keyInfo.SessionMap {
-		if keyInfo.SessionMap[k].SessionExpiryTime.Before(time.Now()) {
-			delete(keyInfo.SessionMap, k)
-		}
+	if err := keyInfo.SessionStore.Delete(sessionID); err != nil && err.Error() != commErr.RecordNotFound {
+		defaultLog.WithError(err).Error("keytransfer/skc_key_transfer:deleteSession() Failed to delete session")
 	}
 }
 
@@ -407,8 +405,6 @@ func (keyInfo *KeyDetails) BuildChallengeJsonRequest(cfg *config.Configuration)
 	defaultLog.Trace("keytransfer/skc_key_transfer:BuildChallengeJsonRequest() entering")
 	defer defaultLog.Trace("keytransfer/skc_key_transfer:BuildChallengeJsonRequest() leaving")
 
-	keyInfo.deleteExpiredSessions()
-
 	var challengeReq kbs.ChallengeRequest
 
 	challengeReq.ChallengeType = keyInfo.ActiveStmLabel
@@ -426,12 +422,20 @@ func (keyInfo *KeyDetails) BuildChallengeJsonRequest(cfg *config.Configuration)
 	return challengeReq, nil
 }
 
-// GetSessionObj - Function to get the key transfer attributes
+// GetSessionObj - Function to get the key transfer attributes, an empty session is returned when the session does not
+// exist
 func (keyInfo KeyDetails) GetSessionObj(encSessionID string) kbs.KeyTransferSession {
 	defaultLog.Trace("keytransfer/skc_key_transfer:GetSessionObj() Entering")
 	defer defaultLog.Trace("keytransfer/skc_key_transfer:GetSessionObj() Leaving")
 
-	return keyInfo.SessionMap[encSessionID]
+	session, err := keyInfo.SessionStore.Retrieve(encSessionID)
+	if err != nil {
+		if err.Error() != commErr.RecordNotFound {
+			defaultLog.WithError(err).Error("keytransfer/skc_key_transfer:GetSessionObj() Failed to retrieve session")
+		}
+		return kbs.KeyTransferSession{}
+	}
+	return *session
 }
 
 // validateSgxEnclaveIssuer - Function to Validate SgxEnclaveIssuer
@@ -590,9 +594,12 @@ func (keyInfo KeyDetails) generateStmChallenge(mins int) (string, error) {
 	keytransfer.SessionId = encSessionID
 	keytransfer.ClientCertHash = keyInfo.ClientCertSHA
 	keytransfer.Stmlabel = keyInfo.ActiveStmLabel
-	keytransfer.SessionExpiryTime = time.Now().Add(time.Minute * time.Duration(mins))
+	keytransfer.CreatedAt = time.Now().UTC()
+	keytransfer.SessionExpiryTime = keytransfer.CreatedAt.Add(time.Minute * time.Duration(mins))
 
-	keyInfo.SessionMap[encSessionID] = keytransfer
+	if _, err := keyInfo.SessionStore.Create(&keytransfer); err != nil {
+		return "", errors.Wrap(err, "keytransfer/skc_key_transfer:generateStmChallenge() failed to store the session")
+	}
 
 	return encSessionID, nil
 }
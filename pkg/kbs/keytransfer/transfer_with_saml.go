@@ -8,18 +8,19 @@ import (
 	"crypto/x509"
 	"encoding/asn1"
 	"encoding/base64"
-	"io/ioutil"
-	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/keymanager"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/privacyca"
 	samlLib "github.com/intel-secl/intel-secl/v4/pkg/lib/saml"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
 	model "github.com/intel-secl/intel-secl/v4/pkg/model/wlagent"
 )
 
@@ -28,34 +29,32 @@ var (
 	pattern    = regexp.MustCompile(`( *)<`)
 )
 
-//IsTrustedByHvs verifies if the client can be trusted for transfer
-func IsTrustedByHvs(saml string, samlReport *samlLib.Saml, keyId uuid.UUID, config domain.KeyControllerConfig, remoteManager *keymanager.RemoteManager) (bool, *x509.Certificate) {
+//IsTrustedByHvs verifies if the client can be trusted for transfer, a host which is trusted but does not satisfy the
+//usage policy of the key is reported along with the clause of the policy which failed
+func IsTrustedByHvs(saml string, samlReport *samlLib.Saml, keyId uuid.UUID, config domain.KeyControllerConfig, remoteManager *keymanager.RemoteManager) (bool, *x509.Certificate, *UsagePolicyViolation) {
 	defaultLog.Trace("keytransfer/transfer_with_saml:IsTrustedByHvs() Entering")
 	defer defaultLog.Trace("keytransfer/transfer_with_saml:IsTrustedByHvs() Leaving")
 
-	usagePolicyTags := make(map[string]string, 0)
+	var usagePolicy *kbs.KeyUsagePolicy
 	key, _ := remoteManager.RetrieveKey(keyId)
-	if key != nil && key.Usage != "" {
-		usagePolicies := strings.Split(key.Usage, ",")
-
-		// create a map for the usage policies
-		for _, usagePolicy := range usagePolicies {
-			tagKeyValuePair := strings.Split(usagePolicy, ":")
-			usagePolicyTags[strings.ToLower(tagKeyValuePair[0])] = tagKeyValuePair[1]
+	if key != nil {
+		usagePolicy = key.UsagePolicy
+		if usagePolicy == nil {
+			usagePolicy = UsagePolicyFromTags(key.Usage)
 		}
 	}
 
 	//Remove Indentation from Request body
 	saml = pattern.ReplaceAllString(saml, "<")
-	verified := verifySamlSignature(saml, config.SamlCertsDir, config.TrustedCaCertsDir)
+	verified := verifySamlSignature(saml, config.SamlCertStore, config.TrustedCaCertsDir)
 	if !verified {
 		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Invalid signature on trust report")
-		return false, nil
+		return false, nil, nil
 	}
 
 	var err error
-	var assetTagDeployed bool
-	tagsDeployedOnHost := make(map[string]string, 0)
+	report := newReportAttributes()
+	report.issueTime = samlReport.Subject.NotBefore
 	var bindingKeyCertBytes, aikCertBytes []byte
 	for _, as := range samlReport.Attribute {
 
@@ -63,110 +62,106 @@ func IsTrustedByHvs(saml string, samlReport *samlLib.Saml, keyId uuid.UUID, conf
 		case "TRUST_OVERALL":
 			if as.AttributeValue != "true" {
 				defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Host is not trusted")
-				return false, nil
+				return false, nil, nil
 			}
 		case "tpmVersion":
 			if as.AttributeValue != "2.0" {
 				defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() TPM version not supported")
-				return false, nil
+				return false, nil, nil
 			}
 		case "Binding_Key_Certificate":
 			bindingKeyCertBytes, err = base64.StdEncoding.DecodeString(as.AttributeValue)
 			if err != nil {
 				defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Unable to decode Binding Key Certificate")
-				return false, nil
+				return false, nil, nil
 			}
 		case "AIK_Certificate":
 			aikCertBytes, err = base64.StdEncoding.DecodeString(as.AttributeValue)
 			if err != nil {
 				defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Unable to decode AIK certificate")
-				return false, nil
+				return false, nil, nil
 			}
 		case "TRUST_ASSET_TAG":
 			// check if asset tag is deployed on the host
 			if as.AttributeValue == "true" {
-				assetTagDeployed = true
+				report.assetTagDeployed = true
 			}
+			report.flavorPartTrust[strings.TrimPrefix(as.Name, "TRUST_")] = as.AttributeValue
 		default:
-			// get the list of deployed tags on the host and add it in the map
+			// get the list of deployed tags, trusted flavor parts and hardware features of the host
 			if strings.HasPrefix(as.Name, "TAG_") {
-				tagsDeployedOnHost[strings.ToLower(strings.TrimPrefix(as.Name, "TAG_"))] = as.AttributeValue
+				report.tags[strings.ToLower(strings.TrimPrefix(as.Name, "TAG_"))] = as.AttributeValue
+			} else if strings.HasPrefix(as.Name, "TRUST_") {
+				report.flavorPartTrust[strings.TrimPrefix(as.Name, "TRUST_")] = as.AttributeValue
+			} else if strings.HasPrefix(as.Name, "FEATURE_") {
+				report.features[strings.ToLower(strings.TrimPrefix(as.Name, "FEATURE_"))] = as.AttributeValue
 			}
 		}
 	}
 
 	if len(aikCertBytes) == 0 {
 		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Assertion does not include AIK Certificate")
-		return false, nil
+		return false, nil, nil
 	}
 
 	aikCert, err := x509.ParseCertificate(aikCertBytes)
 	if err != nil {
 		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Unable to parse AIK certificate")
-		return false, nil
+		return false, nil, nil
 	}
 
-	verified = verifySignature(aikCert, config.TpmIdentityCertsDir)
+	verified = verifySignature(aikCert, config.TpmIdentityCertStore)
 	if !verified {
 		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() AIK certificate not verified by any trusted authority")
-		return false, nil
+		return false, nil, nil
 	}
 
 	if len(bindingKeyCertBytes) == 0 {
 		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() No binding key certificate in trust report")
-		return false, nil
+		return false, nil, nil
 	}
 
 	bindingKeyCert, err := x509.ParseCertificate(bindingKeyCertBytes)
 	if err != nil {
 		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Unable to parse Binding Key certificate")
-		return false, nil
+		return false, nil, nil
 	}
 
-	verified = verifySignature(bindingKeyCert, config.TpmIdentityCertsDir)
+	verified = verifySignature(bindingKeyCert, config.TpmIdentityCertStore)
 	if !verified {
 		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Binding key certificate not verified by any trusted authority")
-		return false, nil
+		return false, nil, nil
 	}
 
 	verified = verifyTpmBindingKeyCertificate(bindingKeyCert, aikCert)
 	if !verified {
 		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Binding key certificate has invalid attributes or cannot be verified with the AIK")
-		return false, nil
+		return false, nil, nil
 	}
 
-	if len(usagePolicyTags) != 0 {
-		if !assetTagDeployed {
-			defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Asset tags are not deployed on the host, but a usage policy is defined for the requested key")
-			return false, nil
-		}
-
-		// check if all the keys in tagsDeployedOnHost exist in usagePolicyTags and their values match
-		for key, value := range usagePolicyTags {
-			if v, ok := tagsDeployedOnHost[key]; !ok || strings.ToLower(v) != strings.ToLower(value) {
-				defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Usage policy requirements of the key does not match with tags deployed on the host")
-				return false, nil
-			}
-		}
+	report.aikIssuer = aikCert.Issuer
+	if violation := evaluateUsagePolicy(usagePolicy, report, time.Now()); violation != nil {
+		defaultLog.Errorf("keytransfer/transfer_with_saml:IsTrustedByHvs() Usage policy of the key is not satisfied by the host - %s", violation.Error())
+		return false, nil, violation
 	}
 
-	return true, bindingKeyCert
+	return true, bindingKeyCert, nil
 }
 
 //verifySamlSignature verifies signature of the saml report
-func verifySamlSignature(saml, samlCertsDir, trustedCaCertsDir string) bool {
+func verifySamlSignature(saml string, samlCertStore domain.CertificateStore, trustedCaCertsDir string) bool {
 	defaultLog.Trace("keytransfer/transfer_with_saml:VerifySamlSignature() Entering")
 	defer defaultLog.Trace("keytransfer/transfer_with_saml:VerifySamlSignature() Leaving")
 
-	samlCertFiles, err := ioutil.ReadDir(samlCertsDir)
+	samlCerts, err := samlCertStore.Search(&models.CertificateFilterCriteria{})
 	if err != nil {
-		defaultLog.WithError(err).Error("keytransfer/transfer_with_saml:VerifySamlSignature() Error while reading the directory: ")
+		defaultLog.WithError(err).Error("keytransfer/transfer_with_saml:VerifySamlSignature() Error while retrieving the SAML certificates")
 		return false
 	}
 
 	var verified bool
-	for _, samlCertFile := range samlCertFiles {
-		if isValidSaml := samlLib.VerifySamlSignature(saml, filepath.Join(samlCertsDir, samlCertFile.Name()), trustedCaCertsDir); isValidSaml {
+	for _, samlCert := range samlCerts {
+		if isValidSaml := samlLib.VerifySamlSignatureWithCert(saml, samlCert.Certificate, trustedCaCertsDir); isValidSaml {
 			verified = true
 		}
 	}
@@ -175,16 +170,26 @@ func verifySamlSignature(saml, samlCertsDir, trustedCaCertsDir string) bool {
 }
 
 //verifySignature verifies the signature of certificate
-func verifySignature(cert *x509.Certificate, signingCertsDir string) bool {
+func verifySignature(cert *x509.Certificate, signingCertStore domain.CertificateStore) bool {
 	defaultLog.Trace("keytransfer/transfer_with_saml:VerifySignature() Entering")
 	defer defaultLog.Trace("keytransfer/transfer_with_saml:VerifySignature() Leaving")
 
-	signingCerts, err := crypt.GetCertsFromDir(signingCertsDir)
+	storedCerts, err := signingCertStore.Search(&models.CertificateFilterCriteria{})
 	if err != nil {
-		defaultLog.WithError(err).Errorf("keytransfer/transfer_with_saml:VerifySignature() Error retrieving signing certificates from %s", signingCertsDir)
+		defaultLog.WithError(err).Error("keytransfer/transfer_with_saml:VerifySignature() Error retrieving signing certificates")
 		return false
 	}
 
+	var signingCerts []x509.Certificate
+	for _, storedCert := range storedCerts {
+		signingCert, err := crypt.GetCertFromPem(storedCert.Certificate)
+		if err != nil {
+			defaultLog.WithError(err).Warnf("keytransfer/transfer_with_saml:VerifySignature() Skipping invalid signing certificate %s", storedCert.ID)
+			continue
+		}
+		signingCerts = append(signingCerts, *signingCert)
+	}
+
 	verifyRootCAOpts := x509.VerifyOptions{
 		Roots: crypt.GetCertPool(signingCerts),
 	}
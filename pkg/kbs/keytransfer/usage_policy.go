@@ -0,0 +1,195 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package keytransfer
+
+import (
+	"crypto/x509/pkix"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+)
+
+// Clauses of the key usage policy, named after their json fields
+const (
+	ClauseAssetTagsAllOf             = "asset_tags.all_of"
+	ClauseAssetTagsAnyOf             = "asset_tags.any_of"
+	ClauseAssetTagsNoneOf            = "asset_tags.none_of"
+	ClauseRequiredTrustedFlavorParts = "required_trusted_flavor_parts"
+	ClauseMaxReportAge               = "max_report_age"
+	ClauseAllowedHardwareFeatures    = "allowed_hardware_features"
+	ClauseAllowedAikIssuers          = "allowed_aik_issuers"
+)
+
+// UsagePolicyViolation names the clause of the key usage policy which the SAML report does not satisfy
+type UsagePolicyViolation struct {
+	Clause string
+	Reason string
+}
+
+func (upv *UsagePolicyViolation) Error() string {
+	return fmt.Sprintf("%s: %s", upv.Clause, upv.Reason)
+}
+
+// reportAttributes holds the attributes of a SAML report the key usage policy is evaluated against, the tag and
+// feature names are lower case
+type reportAttributes struct {
+	issueTime        time.Time
+	assetTagDeployed bool
+	tags             map[string]string
+	flavorPartTrust  map[string]string
+	features         map[string]string
+	aikIssuer        pkix.Name
+}
+
+func newReportAttributes() *reportAttributes {
+	return &reportAttributes{
+		tags:            make(map[string]string),
+		flavorPartTrust: make(map[string]string),
+		features:        make(map[string]string),
+	}
+}
+
+// UsagePolicyFromTags converts the legacy usage of a key, a comma separated list of tag:value pairs, to a usage
+// policy requiring all of the tags
+func UsagePolicyFromTags(usage string) *kbs.KeyUsagePolicy {
+	if usage == "" {
+		return nil
+	}
+
+	var tags []kbs.AssetTag
+	for _, usageTag := range strings.Split(usage, ",") {
+		tagKeyValuePair := strings.SplitN(usageTag, ":", 2)
+		tag := kbs.AssetTag{Key: tagKeyValuePair[0]}
+		if len(tagKeyValuePair) == 2 {
+			tag.Value = tagKeyValuePair[1]
+		}
+		tags = append(tags, tag)
+	}
+	return &kbs.KeyUsagePolicy{AssetTags: &kbs.AssetTagPolicy{AllOf: tags}}
+}
+
+// evaluateUsagePolicy checks the report attributes against each clause of the usage policy in turn and returns the
+// first clause which is not satisfied, nil when the report satisfies the policy
+func evaluateUsagePolicy(policy *kbs.KeyUsagePolicy, report *reportAttributes, now time.Time) *UsagePolicyViolation {
+	defaultLog.Trace("keytransfer/usage_policy:evaluateUsagePolicy() Entering")
+	defer defaultLog.Trace("keytransfer/usage_policy:evaluateUsagePolicy() Leaving")
+
+	if policy == nil {
+		return nil
+	}
+
+	if policy.AssetTags != nil {
+		if violation := evaluateAssetTags(policy.AssetTags, report); violation != nil {
+			return violation
+		}
+	}
+
+	for _, flavorPart := range policy.RequiredTrustedFlavorParts {
+		if report.flavorPartTrust[strings.ToUpper(flavorPart)] != "true" {
+			return &UsagePolicyViolation{
+				Clause: ClauseRequiredTrustedFlavorParts,
+				Reason: fmt.Sprintf("host is not trusted for flavor part %s", strings.ToUpper(flavorPart)),
+			}
+		}
+	}
+
+	if policy.MaxReportAge != "" {
+		maxAge, err := time.ParseDuration(policy.MaxReportAge)
+		if err != nil {
+			return &UsagePolicyViolation{Clause: ClauseMaxReportAge, Reason: "invalid duration"}
+		}
+		if report.issueTime.IsZero() {
+			return &UsagePolicyViolation{Clause: ClauseMaxReportAge, Reason: "report does not include its issue time"}
+		}
+		if age := now.Sub(report.issueTime); age > maxAge {
+			return &UsagePolicyViolation{
+				Clause: ClauseMaxReportAge,
+				Reason: fmt.Sprintf("report was issued %s ago", age.Round(time.Second)),
+			}
+		}
+	}
+
+	for feature, allowedValues := range policy.AllowedHardwareFeatures {
+		value, ok := report.features[strings.ToLower(feature)]
+		if !ok || !containsFold(allowedValues, value) {
+			return &UsagePolicyViolation{
+				Clause: ClauseAllowedHardwareFeatures,
+				Reason: fmt.Sprintf("value of hardware feature %s is not allowed", feature),
+			}
+		}
+	}
+
+	if len(policy.AllowedAikIssuers) != 0 &&
+		!containsFold(policy.AllowedAikIssuers, report.aikIssuer.CommonName) &&
+		!containsFold(policy.AllowedAikIssuers, report.aikIssuer.String()) {
+		return &UsagePolicyViolation{
+			Clause: ClauseAllowedAikIssuers,
+			Reason: fmt.Sprintf("AIK certificate issuer %s is not allowed", report.aikIssuer.String()),
+		}
+	}
+
+	return nil
+}
+
+// evaluateAssetTags checks the tags deployed on the host against the asset tag clauses, the all_of and any_of
+// clauses require the asset tags to be deployed
+func evaluateAssetTags(policy *kbs.AssetTagPolicy, report *reportAttributes) *UsagePolicyViolation {
+	if len(policy.AllOf) != 0 {
+		if !report.assetTagDeployed {
+			return &UsagePolicyViolation{Clause: ClauseAssetTagsAllOf, Reason: "asset tags are not deployed on the host"}
+		}
+		for _, tag := range policy.AllOf {
+			if !report.hasTag(tag) {
+				return &UsagePolicyViolation{
+					Clause: ClauseAssetTagsAllOf,
+					Reason: fmt.Sprintf("tag %s:%s is not deployed on the host", tag.Key, tag.Value),
+				}
+			}
+		}
+	}
+
+	if len(policy.AnyOf) != 0 {
+		if !report.assetTagDeployed {
+			return &UsagePolicyViolation{Clause: ClauseAssetTagsAnyOf, Reason: "asset tags are not deployed on the host"}
+		}
+		matched := false
+		for _, tag := range policy.AnyOf {
+			if report.hasTag(tag) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return &UsagePolicyViolation{Clause: ClauseAssetTagsAnyOf, Reason: "none of the tags are deployed on the host"}
+		}
+	}
+
+	for _, tag := range policy.NoneOf {
+		if report.hasTag(tag) {
+			return &UsagePolicyViolation{
+				Clause: ClauseAssetTagsNoneOf,
+				Reason: fmt.Sprintf("tag %s:%s is deployed on the host", tag.Key, tag.Value),
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ra *reportAttributes) hasTag(tag kbs.AssetTag) bool {
+	value, ok := ra.tags[strings.ToLower(tag.Key)]
+	return ok && strings.EqualFold(value, tag.Value)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package keytransfer
+
+import (
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestReport(now time.Time) *reportAttributes {
+	report := newReportAttributes()
+	report.issueTime = now.Add(-5 * time.Minute)
+	report.assetTagDeployed = true
+	report.tags["country"] = "US"
+	report.tags["state"] = "CA"
+	report.flavorPartTrust["PLATFORM"] = "true"
+	report.flavorPartTrust["OS"] = "true"
+	report.flavorPartTrust["SOFTWARE"] = "false"
+	report.features["txt"] = "true"
+	report.features["cbntprofile"] = "BTGP5"
+	report.aikIssuer = pkix.Name{CommonName: "HVS Privacy Certificate", Organization: []string{"INTEL"}}
+	return report
+}
+
+func TestUsagePolicyFromTags(t *testing.T) {
+	assert.Nil(t, UsagePolicyFromTags(""))
+
+	policy := UsagePolicyFromTags("Country:US,State")
+	assert.Equal(t, []kbs.AssetTag{{Key: "Country", Value: "US"}, {Key: "State"}}, policy.AssetTags.AllOf)
+}
+
+func TestEvaluateUsagePolicy(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		policy *kbs.KeyUsagePolicy
+		clause string
+	}{
+		{"no policy", nil, ""},
+		{"legacy tags", UsagePolicyFromTags("country:us,STATE:CA"), ""},
+		{"all of missing tag", UsagePolicyFromTags("Country:US,City:Folsom"), ClauseAssetTagsAllOf},
+		{"any of", &kbs.KeyUsagePolicy{AssetTags: &kbs.AssetTagPolicy{AnyOf: []kbs.AssetTag{{Key: "Country", Value: "DE"}, {Key: "Country", Value: "US"}}}}, ""},
+		{"any of none deployed", &kbs.KeyUsagePolicy{AssetTags: &kbs.AssetTagPolicy{AnyOf: []kbs.AssetTag{{Key: "Country", Value: "DE"}}}}, ClauseAssetTagsAnyOf},
+		{"none of deployed", &kbs.KeyUsagePolicy{AssetTags: &kbs.AssetTagPolicy{NoneOf: []kbs.AssetTag{{Key: "State", Value: "CA"}}}}, ClauseAssetTagsNoneOf},
+		{"trusted flavor parts", &kbs.KeyUsagePolicy{RequiredTrustedFlavorParts: []string{"platform", "OS"}}, ""},
+		{"untrusted flavor part", &kbs.KeyUsagePolicy{RequiredTrustedFlavorParts: []string{"PLATFORM", "SOFTWARE"}}, ClauseRequiredTrustedFlavorParts},
+		{"missing flavor part", &kbs.KeyUsagePolicy{RequiredTrustedFlavorParts: []string{"HOST_UNIQUE"}}, ClauseRequiredTrustedFlavorParts},
+		{"recent report", &kbs.KeyUsagePolicy{MaxReportAge: "10m"}, ""},
+		{"stale report", &kbs.KeyUsagePolicy{MaxReportAge: "1m"}, ClauseMaxReportAge},
+		{"allowed features", &kbs.KeyUsagePolicy{AllowedHardwareFeatures: map[string][]string{"TXT": {"true"}, "cbntProfile": {"BTGP3", "BTGP5"}}}, ""},
+		{"disallowed feature value", &kbs.KeyUsagePolicy{AllowedHardwareFeatures: map[string][]string{"cbntProfile": {"BTGP3"}}}, ClauseAllowedHardwareFeatures},
+		{"missing feature", &kbs.KeyUsagePolicy{AllowedHardwareFeatures: map[string][]string{"UEFI": {"true"}}}, ClauseAllowedHardwareFeatures},
+		{"allowed aik issuer", &kbs.KeyUsagePolicy{AllowedAikIssuers: []string{"HVS Privacy Certificate"}}, ""},
+		{"allowed aik issuer dn", &kbs.KeyUsagePolicy{AllowedAikIssuers: []string{"CN=HVS Privacy Certificate,O=INTEL"}}, ""},
+		{"disallowed aik issuer", &kbs.KeyUsagePolicy{AllowedAikIssuers: []string{"Other CA"}}, ClauseAllowedAikIssuers},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			violation := evaluateUsagePolicy(test.policy, newTestReport(now), now)
+			if test.clause == "" {
+				assert.Nil(t, violation)
+			} else if assert.NotNil(t, violation) {
+				assert.Equal(t, test.clause, violation.Clause)
+			}
+		})
+	}
+}
+
+func TestEvaluateUsagePolicyWithoutAssetTags(t *testing.T) {
+	now := time.Now()
+	report := newTestReport(now)
+	report.assetTagDeployed = false
+
+	violation := evaluateUsagePolicy(UsagePolicyFromTags("Country:US"), report, now)
+	assert.Equal(t, ClauseAssetTagsAllOf, violation.Clause)
+
+	violation = evaluateUsagePolicy(&kbs.KeyUsagePolicy{AssetTags: &kbs.AssetTagPolicy{NoneOf: []kbs.AssetTag{{Key: "Country", Value: "DE"}}}}, report, now)
+	assert.Nil(t, violation)
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// CertificateStore persists the certificates of a single type, SamlCertType or TpmIdentityCertType
+type CertificateStore struct {
+	Store    *DataStore
+	certType string
+}
+
+func NewCertificateStore(store *DataStore, certType string) *CertificateStore {
+	return &CertificateStore{
+		Store:    store,
+		certType: certType,
+	}
+}
+
+func (cs *CertificateStore) Create(cert *kbs.Certificate) (*kbs.Certificate, error) {
+	defaultLog.Trace("postgres/certificate_store:Create() Entering")
+	defer defaultLog.Trace("postgres/certificate_store:Create() Leaving")
+
+	newUuid, err := uuid.NewRandom()
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/certificate_store:Create() failed to create new UUID")
+	}
+	cert.ID = newUuid
+
+	return cs.Import(cert)
+}
+
+// Import persists the certificate keeping its ID, it is used to migrate the certificates from the directory store.
+// The subject, issuer, validity and digest are derived from the certificate so that they can be searched.
+func (cs *CertificateStore) Import(cert *kbs.Certificate) (*kbs.Certificate, error) {
+	defaultLog.Trace("postgres/certificate_store:Import() Entering")
+	defer defaultLog.Trace("postgres/certificate_store:Import() Leaving")
+
+	x509Cert, err := crypt.GetCertFromPem(cert.Certificate)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/certificate_store:Import() Error in decoding the certificate")
+	}
+
+	fingerprint := sha512.Sum384(x509Cert.Raw)
+	cert.Subject = x509Cert.Subject.CommonName
+	cert.Issuer = x509Cert.Issuer.CommonName
+	cert.NotBefore = &x509Cert.NotBefore
+	cert.NotAfter = &x509Cert.NotAfter
+	cert.Digest = hex.EncodeToString(fingerprint[:])
+
+	dbCert := certificate{
+		ID:          cert.ID,
+		Type:        cs.certType,
+		Certificate: cert.Certificate,
+		Subject:     cert.Subject,
+		Issuer:      cert.Issuer,
+		NotBefore:   cert.NotBefore,
+		NotAfter:    cert.NotAfter,
+		Revoked:     cert.Revoked,
+		Digest:      cert.Digest,
+	}
+	if err := cs.Store.Db.Create(&dbCert).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/certificate_store:Import() Failed to create certificate")
+	}
+	return cert, nil
+}
+
+func (cs *CertificateStore) Retrieve(id uuid.UUID) (*kbs.Certificate, error) {
+	defaultLog.Trace("postgres/certificate_store:Retrieve() Entering")
+	defer defaultLog.Trace("postgres/certificate_store:Retrieve() Leaving")
+
+	var dbCert certificate
+	if err := cs.Store.Db.Where(&certificate{ID: id, Type: cs.certType}).First(&dbCert).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, errors.New(commErr.RecordNotFound)
+		}
+		return nil, errors.Wrapf(err, "postgres/certificate_store:Retrieve() Failed to retrieve certificate : %s", id.String())
+	}
+	return fromDbCertificate(&dbCert), nil
+}
+
+func (cs *CertificateStore) Delete(id uuid.UUID) error {
+	defaultLog.Trace("postgres/certificate_store:Delete() Entering")
+	defer defaultLog.Trace("postgres/certificate_store:Delete() Leaving")
+
+	db := cs.Store.Db.Where("type = ?", cs.certType).Delete(&certificate{ID: id})
+	if db.Error != nil {
+		return errors.Wrapf(db.Error, "postgres/certificate_store:Delete() Failed to delete certificate : %s", id.String())
+	}
+	if db.RowsAffected == 0 {
+		return errors.New(commErr.RecordNotFound)
+	}
+	return nil
+}
+
+func (cs *CertificateStore) Search(criteria *models.CertificateFilterCriteria) ([]kbs.Certificate, error) {
+	defaultLog.Trace("postgres/certificate_store:Search() Entering")
+	defer defaultLog.Trace("postgres/certificate_store:Search() Leaving")
+
+	tx := cs.Store.Db.Model(&certificate{}).Where("type = ?", cs.certType)
+	if criteria != nil {
+		if criteria.SubjectEqualTo != "" {
+			tx = tx.Where("subject = ?", criteria.SubjectEqualTo)
+		}
+		if criteria.SubjectContains != "" {
+			tx = tx.Where("subject LIKE ?", "%"+escapeLike(criteria.SubjectContains)+"%")
+		}
+		if criteria.IssuerEqualTo != "" {
+			tx = tx.Where("lower(issuer) = ?", strings.ToLower(criteria.IssuerEqualTo))
+		}
+		if criteria.IssuerContains != "" {
+			tx = tx.Where("issuer ILIKE ?", "%"+escapeLike(criteria.IssuerContains)+"%")
+		}
+		if !criteria.ValidBefore.IsZero() {
+			tx = tx.Where("not_after < ?", criteria.ValidBefore)
+		}
+		if !criteria.ValidAfter.IsZero() {
+			tx = tx.Where("not_before > ?", criteria.ValidAfter)
+		}
+		if !criteria.ValidOn.IsZero() {
+			tx = tx.Where("not_before < ? AND not_after > ?", criteria.ValidOn, criteria.ValidOn)
+		}
+	}
+
+	var dbCerts []certificate
+	if err := tx.Find(&dbCerts).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/certificate_store:Search() Failed to search certificates")
+	}
+
+	certs := []kbs.Certificate{}
+	for i := range dbCerts {
+		certs = append(certs, *fromDbCertificate(&dbCerts[i]))
+	}
+	return certs, nil
+}
+
+func fromDbCertificate(dbCert *certificate) *kbs.Certificate {
+	return &kbs.Certificate{
+		ID:          dbCert.ID,
+		Certificate: dbCert.Certificate,
+		Subject:     dbCert.Subject,
+		Issuer:      dbCert.Issuer,
+		NotBefore:   dbCert.NotBefore,
+		NotAfter:    dbCert.NotAfter,
+		Revoked:     dbCert.Revoked,
+		Digest:      dbCert.Digest,
+	}
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
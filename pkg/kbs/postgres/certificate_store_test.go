@@ -0,0 +1,145 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testCertId             = uuid.MustParse("fb2d3f1e-0a3b-4c5d-8e9f-a0b1c2d3e4f5")
+	certificateColumnNames = []string{"id", "type", "certificate", "subject", "issuer", "not_before", "not_after",
+		"revoked", "digest"}
+)
+
+// newTestCertificatePem returns a PEM encoded self signed certificate
+func newTestCertificatePem(t *testing.T, commonName string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func testCertificateRow(certPem []byte) *sqlmock.Rows {
+	now := time.Now().UTC()
+	return sqlmock.NewRows(certificateColumnNames).AddRow(testCertId, SamlCertType, certPem, "SAML Signing", "SAML Signing",
+		now.Add(-time.Hour), now.Add(time.Hour), false, "digest")
+}
+
+func TestCertificateStoreCreate(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	certStore := NewCertificateStore(dataStore, SamlCertType)
+	certPem := newTestCertificatePem(t, "SAML Signing")
+
+	// the searched attributes are derived from the certificate
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "certificate" ("id","type","certificate","subject","issuer","not_before","not_after","revoked","digest") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING "certificate"."id"`)).
+		WithArgs(sqlmock.AnyArg(), SamlCertType, certPem, "SAML Signing", "SAML Signing", sqlmock.AnyArg(),
+			sqlmock.AnyArg(), false, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testCertId))
+	mock.ExpectCommit()
+
+	cert, err := certStore.Create(&kbs.Certificate{Certificate: certPem})
+	assert.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, cert.ID)
+	assert.Equal(t, "SAML Signing", cert.Subject)
+	assert.NotNil(t, cert.NotAfter)
+	assert.Len(t, cert.Digest, 96)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCertificateStoreCreateInvalidCertificate(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	certStore := NewCertificateStore(dataStore, SamlCertType)
+
+	_, err := certStore.Create(&kbs.Certificate{Certificate: []byte("not a certificate")})
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCertificateStoreRetrieve(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	certStore := NewCertificateStore(dataStore, TpmIdentityCertType)
+	certPem := newTestCertificatePem(t, "SAML Signing")
+
+	// the certificates of the other type are not found
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "certificate" WHERE ("certificate"."id" = $1) AND ("certificate"."type" = $2) ORDER BY "certificate"."id" ASC LIMIT 1`)).
+		WithArgs(testCertId, TpmIdentityCertType).
+		WillReturnRows(testCertificateRow(certPem))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "certificate"`)).
+		WithArgs(testCertId, TpmIdentityCertType).
+		WillReturnRows(sqlmock.NewRows(certificateColumnNames))
+
+	cert, err := certStore.Retrieve(testCertId)
+	assert.NoError(t, err)
+	assert.Equal(t, testCertId, cert.ID)
+	assert.Equal(t, certPem, cert.Certificate)
+
+	_, err = certStore.Retrieve(testCertId)
+	assert.EqualError(t, err, commErr.RecordNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCertificateStoreDelete(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	certStore := NewCertificateStore(dataStore, SamlCertType)
+
+	for _, rowsAffected := range []int64{1, 0} {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "certificate" WHERE "certificate"."id" = $1 AND ((type = $2))`)).
+			WithArgs(testCertId, SamlCertType).
+			WillReturnResult(sqlmock.NewResult(0, rowsAffected))
+		mock.ExpectCommit()
+	}
+
+	assert.NoError(t, certStore.Delete(testCertId))
+	assert.EqualError(t, certStore.Delete(testCertId), commErr.RecordNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCertificateStoreSearch(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	certStore := NewCertificateStore(dataStore, SamlCertType)
+	validOn := time.Now().UTC()
+
+	// the LIKE wildcards of the criteria are matched literally
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "certificate" WHERE (type = $1) AND (subject LIKE $2) AND (lower(issuer) = $3) AND (not_before < $4 AND not_after > $5)`)).
+		WithArgs(SamlCertType, `%SAML\_Sign\%%`, "saml signing", validOn, validOn).
+		WillReturnRows(testCertificateRow(newTestCertificatePem(t, "SAML Signing")))
+
+	certs, err := certStore.Search(&models.CertificateFilterCriteria{
+		SubjectContains: "SAML_Sign%",
+		IssuerEqualTo:   "SAML Signing",
+		ValidOn:         validOn,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, certs, 1) {
+		assert.Equal(t, testCertId, certs[0].ID)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
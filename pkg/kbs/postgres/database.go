@@ -0,0 +1,30 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
+	commConfig "github.com/intel-secl/intel-secl/v4/pkg/lib/common/config"
+	"github.com/pkg/errors"
+)
+
+// InitDatabase connects to the KBS database and creates or updates its schema
+func InitDatabase(cfg *commConfig.DBConfig) (*DataStore, error) {
+	defaultLog.Trace("postgres/database:InitDatabase() Entering")
+	defer defaultLog.Trace("postgres/database:InitDatabase() Leaving")
+
+	if cfg.Vendor != constants.DBTypePostgres {
+		return nil, errors.Errorf("Unsupported database vendor %s", cfg.Vendor)
+	}
+
+	dataStore, err := New(NewDatabaseConfig(cfg.Vendor, cfg))
+	if err != nil {
+		return nil, errors.Wrap(err, "Error instantiating Database")
+	}
+	defaultLog.Info("Migrating Database")
+	dataStore.Migrate()
+
+	return dataStore, nil
+}
@@ -0,0 +1,221 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+type KeyStore struct {
+	Store *DataStore
+}
+
+func NewKeyStore(store *DataStore) *KeyStore {
+	return &KeyStore{store}
+}
+
+// Create persists the key attributes, the key ID being the one assigned by the key manager
+func (ks *KeyStore) Create(keyAttributes *models.KeyAttributes) (*models.KeyAttributes, error) {
+	defaultLog.Trace("postgres/key_store:Create() Entering")
+	defer defaultLog.Trace("postgres/key_store:Create() Leaving")
+
+	dbKey := toDbKey(keyAttributes)
+	if err := ks.Store.Db.Create(dbKey).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/key_store:Create() Failed to create key")
+	}
+	return keyAttributes, nil
+}
+
+func (ks *KeyStore) Retrieve(id uuid.UUID) (*models.KeyAttributes, error) {
+	defaultLog.Trace("postgres/key_store:Retrieve() Entering")
+	defer defaultLog.Trace("postgres/key_store:Retrieve() Leaving")
+
+	var dbKey key
+	if err := ks.Store.Db.Where(&key{ID: id}).First(&dbKey).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, errors.New(commErr.RecordNotFound)
+		}
+		return nil, errors.Wrapf(err, "postgres/key_store:Retrieve() Failed to retrieve key : %s", id.String())
+	}
+	return fromDbKey(&dbKey), nil
+}
+
+// Update replaces the key attributes
+func (ks *KeyStore) Update(keyAttributes *models.KeyAttributes) (*models.KeyAttributes, error) {
+	defaultLog.Trace("postgres/key_store:Update() Entering")
+	defer defaultLog.Trace("postgres/key_store:Update() Leaving")
+
+	db := ks.Store.Db.Model(&key{}).Where("id = ?", keyAttributes.ID).Updates(keyColumns(toDbKey(keyAttributes)))
+	if db.Error != nil {
+		return nil, errors.Wrapf(db.Error, "postgres/key_store:Update() Failed to update key : %s", keyAttributes.ID.String())
+	}
+	if db.RowsAffected == 0 {
+		return nil, errors.New(commErr.RecordNotFound)
+	}
+	return keyAttributes, nil
+}
+
+// Rotate locks the row of the key for the duration of the rotation, a concurrent rotation of the key by another
+// KBS instance waits for the lock and retrieves the rotated key
+func (ks *KeyStore) Rotate(id uuid.UUID, rotate func(*models.KeyAttributes) (*models.KeyAttributes, error)) (*models.KeyAttributes, error) {
+	defaultLog.Trace("postgres/key_store:Rotate() Entering")
+	defer defaultLog.Trace("postgres/key_store:Rotate() Leaving")
+
+	tx := ks.Store.Db.Begin()
+	if tx.Error != nil {
+		return nil, errors.Wrap(tx.Error, "postgres/key_store:Rotate() Failed to begin transaction")
+	}
+
+	var dbKey key
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").Where(&key{ID: id}).First(&dbKey).Error; err != nil {
+		tx.Rollback()
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, errors.New(commErr.RecordNotFound)
+		}
+		return nil, errors.Wrapf(err, "postgres/key_store:Rotate() Failed to retrieve key : %s", id.String())
+	}
+
+	rotatedKey, err := rotate(fromDbKey(&dbKey))
+	if err != nil || rotatedKey == nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Model(&key{}).Where("id = ?", id).Updates(keyColumns(toDbKey(rotatedKey))).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.Wrapf(err, "postgres/key_store:Rotate() Failed to update key : %s", id.String())
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.Wrapf(err, "postgres/key_store:Rotate() Failed to commit rotation of key : %s", id.String())
+	}
+	return rotatedKey, nil
+}
+
+func (ks *KeyStore) Delete(id uuid.UUID) error {
+	defaultLog.Trace("postgres/key_store:Delete() Entering")
+	defer defaultLog.Trace("postgres/key_store:Delete() Leaving")
+
+	db := ks.Store.Db.Delete(&key{ID: id})
+	if db.Error != nil {
+		return errors.Wrapf(db.Error, "postgres/key_store:Delete() Failed to delete key : %s", id.String())
+	}
+	if db.RowsAffected == 0 {
+		return errors.New(commErr.RecordNotFound)
+	}
+	return nil
+}
+
+func (ks *KeyStore) Search(criteria *models.KeyFilterCriteria) ([]models.KeyAttributes, error) {
+	defaultLog.Trace("postgres/key_store:Search() Entering")
+	defer defaultLog.Trace("postgres/key_store:Search() Leaving")
+
+	tx := ks.Store.Db.Model(&key{})
+	if criteria != nil {
+		if criteria.Algorithm != "" {
+			tx = tx.Where("algorithm = ?", criteria.Algorithm)
+		}
+		if criteria.KeyLength != 0 {
+			tx = tx.Where("key_length = ?", criteria.KeyLength)
+		}
+		if criteria.CurveType != "" {
+			tx = tx.Where("curve_type = ?", criteria.CurveType)
+		}
+		if criteria.TransferPolicyId != uuid.Nil {
+			tx = tx.Where("transfer_policy_id = ?", criteria.TransferPolicyId)
+		}
+	}
+
+	var dbKeys []key
+	if err := tx.Order("created").Find(&dbKeys).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/key_store:Search() Failed to search keys")
+	}
+
+	keys := []models.KeyAttributes{}
+	for i := range dbKeys {
+		keys = append(keys, *fromDbKey(&dbKeys[i]))
+	}
+	return keys, nil
+}
+
+func toDbKey(keyAttributes *models.KeyAttributes) *key {
+	dbKey := key{
+		ID:               keyAttributes.ID,
+		Algorithm:        keyAttributes.Algorithm,
+		KeyLength:        keyAttributes.KeyLength,
+		KeyData:          keyAttributes.KeyData,
+		CurveType:        keyAttributes.CurveType,
+		PublicKey:        keyAttributes.PublicKey,
+		PrivateKey:       keyAttributes.PrivateKey,
+		KmipKeyID:        keyAttributes.KmipKeyID,
+		TransferPolicyId: keyAttributes.TransferPolicyId,
+		TransferLink:     keyAttributes.TransferLink,
+		CreatedAt:        keyAttributes.CreatedAt,
+		Label:            keyAttributes.Label,
+		Usage:            keyAttributes.Usage,
+		Version:          keyAttributes.Version,
+		RotationInterval: keyAttributes.RotationInterval,
+		RotatedAt:        keyAttributes.RotatedAt,
+		PriorVersions:    PGKeyVersions(keyAttributes.PriorVersions),
+	}
+	if keyAttributes.UsagePolicy != nil {
+		usagePolicy := PGKeyUsagePolicy(*keyAttributes.UsagePolicy)
+		dbKey.UsagePolicy = &usagePolicy
+	}
+	return &dbKey
+}
+
+// keyColumns returns the columns replaced by an update of the key
+func keyColumns(dbKey *key) map[string]interface{} {
+	return map[string]interface{}{
+		"algorithm":          dbKey.Algorithm,
+		"key_length":         dbKey.KeyLength,
+		"key_data":           dbKey.KeyData,
+		"curve_type":         dbKey.CurveType,
+		"public_key":         dbKey.PublicKey,
+		"private_key":        dbKey.PrivateKey,
+		"kmip_key_id":        dbKey.KmipKeyID,
+		"transfer_policy_id": dbKey.TransferPolicyId,
+		"transfer_link":      dbKey.TransferLink,
+		"label":              dbKey.Label,
+		"usage":              dbKey.Usage,
+		"usage_policy":       dbKey.UsagePolicy,
+		"version":            dbKey.Version,
+		"rotation_interval":  dbKey.RotationInterval,
+		"rotated":            dbKey.RotatedAt,
+		"prior_versions":     dbKey.PriorVersions,
+	}
+}
+
+func fromDbKey(dbKey *key) *models.KeyAttributes {
+	keyAttributes := models.KeyAttributes{
+		ID:               dbKey.ID,
+		Algorithm:        dbKey.Algorithm,
+		KeyLength:        dbKey.KeyLength,
+		KeyData:          dbKey.KeyData,
+		CurveType:        dbKey.CurveType,
+		PublicKey:        dbKey.PublicKey,
+		PrivateKey:       dbKey.PrivateKey,
+		KmipKeyID:        dbKey.KmipKeyID,
+		TransferPolicyId: dbKey.TransferPolicyId,
+		TransferLink:     dbKey.TransferLink,
+		CreatedAt:        dbKey.CreatedAt,
+		Label:            dbKey.Label,
+		Usage:            dbKey.Usage,
+		Version:          dbKey.Version,
+		RotationInterval: dbKey.RotationInterval,
+		RotatedAt:        dbKey.RotatedAt,
+		PriorVersions:    []models.KeyVersion(dbKey.PriorVersions),
+	}
+	if dbKey.UsagePolicy != nil {
+		usagePolicy := kbs.KeyUsagePolicy(*dbKey.UsagePolicy)
+		keyAttributes.UsagePolicy = &usagePolicy
+	}
+	return &keyAttributes
+}
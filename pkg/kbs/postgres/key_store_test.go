@@ -0,0 +1,226 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testKeyId      = uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2")
+	keyColumnNames = []string{"id", "algorithm", "key_length", "key_data", "curve_type", "public_key", "private_key",
+		"kmip_key_id", "transfer_policy_id", "transfer_link", "created", "label", "usage", "usage_policy", "version",
+		"rotation_interval", "rotated", "prior_versions"}
+)
+
+func testKeyRow(created time.Time, version int) *sqlmock.Rows {
+	return sqlmock.NewRows(keyColumnNames).AddRow(testKeyId, "AES", 256, "key-data-1", "", "", "", "",
+		uuid.Nil, "https://kbs.com:9443/kbs/v1/keys/"+testKeyId.String()+"/transfer", created, "", "", nil, version,
+		"", time.Time{}, nil)
+}
+
+func TestKeyStoreRotate(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	keyStore := NewKeyStore(dataStore)
+	created := time.Now().UTC().Add(-time.Hour)
+
+	// the key row is locked until the rotated key is stored
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "key" WHERE ("key"."id" = $1) ORDER BY "key"."id" ASC LIMIT 1 FOR UPDATE`)).
+		WithArgs(testKeyId).
+		WillReturnRows(testKeyRow(created, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "key" SET`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	rotatedKey, err := keyStore.Rotate(testKeyId, func(keyAttributes *models.KeyAttributes) (*models.KeyAttributes, error) {
+		assert.Equal(t, 1, keyAttributes.ActiveVersion())
+		rotated := *keyAttributes
+		rotated.Version = 2
+		rotated.KeyData = "key-data-2"
+		rotated.PriorVersions = []models.KeyVersion{keyAttributes.ToKeyVersion()}
+		return &rotated, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, rotatedKey.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKeyStoreRotateUnchanged(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	keyStore := NewKeyStore(dataStore)
+
+	// the lock is released without updating the key
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`FOR UPDATE`)).
+		WithArgs(testKeyId).
+		WillReturnRows(testKeyRow(time.Now().UTC(), 2))
+	mock.ExpectRollback()
+
+	rotatedKey, err := keyStore.Rotate(testKeyId, func(keyAttributes *models.KeyAttributes) (*models.KeyAttributes, error) {
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, rotatedKey)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKeyStoreRotateNotFound(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	keyStore := NewKeyStore(dataStore)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`FOR UPDATE`)).
+		WithArgs(testKeyId).
+		WillReturnRows(sqlmock.NewRows(keyColumnNames))
+	mock.ExpectRollback()
+
+	_, err := keyStore.Rotate(testKeyId, func(keyAttributes *models.KeyAttributes) (*models.KeyAttributes, error) {
+		t.Fatal("a missing key must not be rotated")
+		return nil, nil
+	})
+	assert.EqualError(t, err, commErr.RecordNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKeyStoreCreate(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	keyStore := NewKeyStore(dataStore)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "key" ("id","algorithm","key_length","key_data"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testKeyId))
+	mock.ExpectCommit()
+
+	createdKey, err := keyStore.Create(&models.KeyAttributes{
+		ID:        testKeyId,
+		Algorithm: "AES",
+		KeyLength: 256,
+		KeyData:   "key-data-1",
+		CreatedAt: time.Now().UTC(),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, testKeyId, createdKey.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKeyStoreRetrieve(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	keyStore := NewKeyStore(dataStore)
+	created := time.Now().UTC()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "key" WHERE ("key"."id" = $1) ORDER BY "key"."id" ASC LIMIT 1`)).
+		WithArgs(testKeyId).
+		WillReturnRows(testKeyRow(created, 2))
+
+	keyAttributes, err := keyStore.Retrieve(testKeyId)
+	assert.NoError(t, err)
+	assert.Equal(t, testKeyId, keyAttributes.ID)
+	assert.Equal(t, "AES", keyAttributes.Algorithm)
+	assert.Equal(t, 256, keyAttributes.KeyLength)
+	assert.Equal(t, 2, keyAttributes.Version)
+	assert.True(t, created.Equal(keyAttributes.CreatedAt))
+	assert.Nil(t, keyAttributes.UsagePolicy)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKeyStoreRetrieveNotFound(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	keyStore := NewKeyStore(dataStore)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "key"`)).
+		WithArgs(testKeyId).
+		WillReturnRows(sqlmock.NewRows(keyColumnNames))
+
+	_, err := keyStore.Retrieve(testKeyId)
+	assert.EqualError(t, err, commErr.RecordNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKeyStoreUpdate(t *testing.T) {
+	tests := []struct {
+		name          string
+		rowsAffected  int64
+		expectedError string
+	}{
+		{name: "update", rowsAffected: 1},
+		{name: "not found", rowsAffected: 0, expectedError: commErr.RecordNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataStore, mock := NewSQLMockDataStore()
+			keyStore := NewKeyStore(dataStore)
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE "key" SET`) + `.*"version" = \$\d+.*` + regexp.QuoteMeta(`WHERE (id = $17)`)).
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+			mock.ExpectCommit()
+
+			_, err := keyStore.Update(&models.KeyAttributes{ID: testKeyId, Algorithm: "AES", KeyLength: 256, Version: 2})
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestKeyStoreDelete(t *testing.T) {
+	tests := []struct {
+		name          string
+		rowsAffected  int64
+		expectedError string
+	}{
+		{name: "delete", rowsAffected: 1},
+		{name: "not found", rowsAffected: 0, expectedError: commErr.RecordNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataStore, mock := NewSQLMockDataStore()
+			keyStore := NewKeyStore(dataStore)
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "key" WHERE "key"."id" = $1`)).
+				WithArgs(testKeyId).
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+			mock.ExpectCommit()
+
+			err := keyStore.Delete(testKeyId)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestKeyStoreSearch(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	keyStore := NewKeyStore(dataStore)
+	transferPolicyId := uuid.MustParse("4d2c1b0a-9e8f-4a7b-b6c5-d4e3f2a1b0c9")
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "key" WHERE (algorithm = $1) AND (key_length = $2) AND (transfer_policy_id = $3) ORDER BY "created"`)).
+		WithArgs("AES", 256, transferPolicyId).
+		WillReturnRows(testKeyRow(time.Now().UTC(), 0))
+
+	keys, err := keyStore.Search(&models.KeyFilterCriteria{Algorithm: "AES", KeyLength: 256, TransferPolicyId: transferPolicyId})
+	assert.NoError(t, err)
+	if assert.Len(t, keys, 1) {
+		assert.Equal(t, testKeyId, keys[0].ID)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testPolicyId          = uuid.MustParse("ed37c360-7eae-4250-a677-6ee12adce8e3")
+	policyColumnNames     = []string{"id", "content", "created"}
+	testPolicyAttestation = []string{"SGX"}
+)
+
+func testPolicyRow(t *testing.T, created time.Time) *sqlmock.Rows {
+	content, err := json.Marshal(kbs.KeyTransferPolicyAttributes{
+		ID:                   testPolicyId,
+		CreatedAt:            created,
+		AttestationTypeAnyof: testPolicyAttestation,
+	})
+	assert.NoError(t, err)
+	return sqlmock.NewRows(policyColumnNames).AddRow(testPolicyId, content, created)
+}
+
+func TestKeyTransferPolicyStoreCreate(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	policyStore := NewKeyTransferPolicyStore(dataStore)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "key_transfer_policy" ("id","content","created") VALUES ($1,$2,$3) RETURNING "key_transfer_policy"."id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testPolicyId))
+	mock.ExpectCommit()
+
+	policy, err := policyStore.Create(&kbs.KeyTransferPolicyAttributes{AttestationTypeAnyof: testPolicyAttestation})
+	assert.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, policy.ID)
+	assert.False(t, policy.CreatedAt.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKeyTransferPolicyStoreImport(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	policyStore := NewKeyTransferPolicyStore(dataStore)
+	created := time.Now().UTC().Add(-time.Hour)
+
+	// the migrated policies keep their ID and creation time
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "key_transfer_policy"`)).
+		WithArgs(testPolicyId, sqlmock.AnyArg(), created).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testPolicyId))
+	mock.ExpectCommit()
+
+	policy, err := policyStore.Import(&kbs.KeyTransferPolicyAttributes{ID: testPolicyId, CreatedAt: created})
+	assert.NoError(t, err)
+	assert.Equal(t, testPolicyId, policy.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKeyTransferPolicyStoreRetrieve(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	policyStore := NewKeyTransferPolicyStore(dataStore)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "key_transfer_policy" WHERE ("key_transfer_policy"."id" = $1) ORDER BY "key_transfer_policy"."id" ASC LIMIT 1`)).
+		WithArgs(testPolicyId).
+		WillReturnRows(testPolicyRow(t, time.Now().UTC()))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "key_transfer_policy"`)).
+		WithArgs(testPolicyId).
+		WillReturnRows(sqlmock.NewRows(policyColumnNames))
+
+	policy, err := policyStore.Retrieve(testPolicyId)
+	assert.NoError(t, err)
+	assert.Equal(t, testPolicyId, policy.ID)
+	assert.Equal(t, testPolicyAttestation, policy.AttestationTypeAnyof)
+
+	_, err = policyStore.Retrieve(testPolicyId)
+	assert.EqualError(t, err, commErr.RecordNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKeyTransferPolicyStoreDelete(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	policyStore := NewKeyTransferPolicyStore(dataStore)
+
+	for _, rowsAffected := range []int64{1, 0} {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "key_transfer_policy" WHERE "key_transfer_policy"."id" = $1`)).
+			WithArgs(testPolicyId).
+			WillReturnResult(sqlmock.NewResult(0, rowsAffected))
+		mock.ExpectCommit()
+	}
+
+	assert.NoError(t, policyStore.Delete(testPolicyId))
+	assert.EqualError(t, policyStore.Delete(testPolicyId), commErr.RecordNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKeyTransferPolicyStoreSearch(t *testing.T) {
+	dataStore, mock := NewSQLMockDataStore()
+	policyStore := NewKeyTransferPolicyStore(dataStore)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "key_transfer_policy" ORDER BY "created"`)).
+		WillReturnRows(testPolicyRow(t, time.Now().UTC()))
+
+	policies, err := policyStore.Search(nil)
+	assert.NoError(t, err)
+	if assert.Len(t, policies, 1) {
+		assert.Equal(t, testPolicyId, policies[0].ID)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
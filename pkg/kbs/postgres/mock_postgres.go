@@ -0,0 +1,21 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jinzhu/gorm"
+)
+
+// NewSQLMockDataStore returns an instance of DataStore with a Mock Database connection injected into it
+func NewSQLMockDataStore() (*DataStore, sqlmock.Sqlmock) {
+	db, mock, _ := sqlmock.New()
+	gdb, _ := gorm.Open("postgres", db)
+
+	// enable single table setting
+	gdb.SingularTable(true)
+
+	return &DataStore{Db: gdb}, mock
+}
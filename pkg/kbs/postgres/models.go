@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+	"github.com/pkg/errors"
+)
+
+// Certificate types of the certificate table
+const (
+	SamlCertType        = "saml"
+	TpmIdentityCertType = "tpm-identity"
+)
+
+// Define all struct types here
+type (
+	PGKeyTransferPolicy kbs.KeyTransferPolicyAttributes
+	PGKeyVersions       []models.KeyVersion
+	PGQuoteAttributes   kbs.QuoteVerifyAttributes
+	PGKeyUsagePolicy    kbs.KeyUsagePolicy
+
+	// key holds the key attributes, the columns used by the key search are indexed
+	key struct {
+		ID               uuid.UUID `gorm:"primary_key;type:uuid"`
+		Algorithm        string    `gorm:"type:varchar(16);not null;index:idx_key_algorithm"`
+		KeyLength        int       `gorm:"index:idx_key_key_length"`
+		KeyData          string
+		CurveType        string `gorm:"type:varchar(32);index:idx_key_curve_type"`
+		PublicKey        string
+		PrivateKey       string
+		KmipKeyID        string
+		TransferPolicyId uuid.UUID `gorm:"type:uuid;index:idx_key_transfer_policy_id"`
+		TransferLink     string
+		CreatedAt        time.Time `gorm:"column:created;not null"`
+		Label            string
+		Usage            string
+		UsagePolicy      *PGKeyUsagePolicy `gorm:"column:usage_policy" sql:"type:JSONB"`
+		Version          int
+		RotationInterval string        `gorm:"type:varchar(32)"`
+		RotatedAt        time.Time     `gorm:"column:rotated"`
+		PriorVersions    PGKeyVersions `gorm:"column:prior_versions" sql:"type:JSONB"`
+	}
+
+	keyTransferPolicy struct {
+		ID        uuid.UUID           `gorm:"primary_key;type:uuid"`
+		Content   PGKeyTransferPolicy `gorm:"column:content" sql:"type:JSONB NOT NULL"`
+		CreatedAt time.Time           `gorm:"column:created;not null"`
+	}
+
+	// certificate holds the SAML and TPM identity certificates, told apart by the certificate type
+	certificate struct {
+		ID          uuid.UUID  `gorm:"primary_key;type:uuid"`
+		Type        string     `gorm:"type:varchar(32);not null;index:idx_certificate_type"`
+		Certificate []byte     `gorm:"not null"`
+		Subject     string     `gorm:"type:varchar(255);index:idx_certificate_subject"`
+		Issuer      string     `gorm:"type:varchar(255);index:idx_certificate_issuer"`
+		NotBefore   *time.Time `gorm:"index:idx_certificate_not_before"`
+		NotAfter    *time.Time `gorm:"index:idx_certificate_not_after"`
+		Revoked     bool       `gorm:"not null"`
+		Digest      string     `gorm:"type:varchar(128)"`
+	}
+
+	// keyTransferSession holds the SKC sessions so that they survive restarts and are shared by the KBS instances,
+	// the quote attributes are null until the enclave is attested
+	keyTransferSession struct {
+		ID                    string             `gorm:"primary_key;type:varchar(128)"`
+		SWK                   []byte             `gorm:"column:swk"`
+		ClientCertHash        string             `gorm:"type:varchar(128)"`
+		StmLabel              string             `gorm:"type:varchar(32);not null"`
+		QuoteVerifyAttributes *PGQuoteAttributes `gorm:"column:quote_verify_attributes" sql:"type:JSONB"`
+		CreatedAt             time.Time          `gorm:"column:created;not null"`
+		ExpiresAt             time.Time          `gorm:"column:expires;not null;index:idx_key_transfer_session_expires"`
+	}
+)
+
+func (ktp PGKeyTransferPolicy) Value() (driver.Value, error) {
+	return json.Marshal(ktp)
+}
+
+func (ktp *PGKeyTransferPolicy) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("postgres/models:PGKeyTransferPolicy_Scan() - type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, &ktp)
+}
+
+func (kv PGKeyVersions) Value() (driver.Value, error) {
+	return json.Marshal(kv)
+}
+
+func (kv *PGKeyVersions) Scan(value interface{}) error {
+	// the column is null for the keys stored before the versions were added
+	if value == nil {
+		*kv = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("postgres/models:PGKeyVersions_Scan() - type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, &kv)
+}
+
+func (qa PGQuoteAttributes) Value() (driver.Value, error) {
+	return json.Marshal(qa)
+}
+
+func (qa *PGQuoteAttributes) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("postgres/models:PGQuoteAttributes_Scan() - type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, &qa)
+}
+
+func (up PGKeyUsagePolicy) Value() (driver.Value, error) {
+	return json.Marshal(up)
+}
+
+func (up *PGKeyUsagePolicy) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("postgres/models:PGKeyUsagePolicy_Scan() - type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, &up)
+}
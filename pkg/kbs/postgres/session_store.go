@@ -0,0 +1,218 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// SessionStore persists the SKC sessions, letting the enclaves keep their sessions across KBS restarts and between
+// the KBS instances sharing the database. The session wrapping keys are stored wrapped with AES-256-GCM using the KBS
+// master key, the session ID is used as additional data so that a wrapped key cannot be moved to another session.
+type SessionStore struct {
+	Store *DataStore
+	aead  cipher.AEAD
+}
+
+// NewSessionStore returns a session store wrapping the session keys with masterKey, the KBS instances sharing the
+// database must use the same master key
+func NewSessionStore(store *DataStore, masterKey []byte) (*SessionStore, error) {
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/session_store:NewSessionStore() Failed to initialize master key cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/session_store:NewSessionStore() Failed to initialize master key cipher")
+	}
+	return &SessionStore{Store: store, aead: aead}, nil
+}
+
+func (ss *SessionStore) Create(session *kbs.KeyTransferSession) (*kbs.KeyTransferSession, error) {
+	defaultLog.Trace("postgres/session_store:Create() Entering")
+	defer defaultLog.Trace("postgres/session_store:Create() Leaving")
+
+	dbSession, err := ss.toDbSession(session)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/session_store:Create() Failed to wrap session key")
+	}
+	if err := ss.Store.Db.Create(dbSession).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/session_store:Create() Failed to create session")
+	}
+	return session, nil
+}
+
+func (ss *SessionStore) Retrieve(sessionId string) (*kbs.KeyTransferSession, error) {
+	defaultLog.Trace("postgres/session_store:Retrieve() Entering")
+	defer defaultLog.Trace("postgres/session_store:Retrieve() Leaving")
+
+	var dbSession keyTransferSession
+	if err := ss.Store.Db.Where("id = ?", sessionId).First(&dbSession).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, errors.New(commErr.RecordNotFound)
+		}
+		return nil, errors.Wrap(err, "postgres/session_store:Retrieve() Failed to retrieve session")
+	}
+	session, err := ss.fromDbSession(&dbSession)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/session_store:Retrieve() Failed to unwrap session key")
+	}
+	return session, nil
+}
+
+// Update stores the session wrapping key and the quote attributes of an attested session along with its expiry time
+func (ss *SessionStore) Update(session *kbs.KeyTransferSession) (*kbs.KeyTransferSession, error) {
+	defaultLog.Trace("postgres/session_store:Update() Entering")
+	defer defaultLog.Trace("postgres/session_store:Update() Leaving")
+
+	dbSession, err := ss.toDbSession(session)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/session_store:Update() Failed to wrap session key")
+	}
+	db := ss.Store.Db.Model(&keyTransferSession{}).Where("id = ?", session.SessionId).Updates(map[string]interface{}{
+		"swk":                     dbSession.SWK,
+		"quote_verify_attributes": dbSession.QuoteVerifyAttributes,
+		"expires":                 dbSession.ExpiresAt,
+	})
+	if db.Error != nil {
+		return nil, errors.Wrap(db.Error, "postgres/session_store:Update() Failed to update session")
+	}
+	if db.RowsAffected == 0 {
+		return nil, errors.New(commErr.RecordNotFound)
+	}
+	return session, nil
+}
+
+func (ss *SessionStore) Delete(sessionId string) error {
+	defaultLog.Trace("postgres/session_store:Delete() Entering")
+	defer defaultLog.Trace("postgres/session_store:Delete() Leaving")
+
+	db := ss.Store.Db.Where("id = ?", sessionId).Delete(&keyTransferSession{})
+	if db.Error != nil {
+		return errors.Wrap(db.Error, "postgres/session_store:Delete() Failed to delete session")
+	}
+	if db.RowsAffected == 0 {
+		return errors.New(commErr.RecordNotFound)
+	}
+	return nil
+}
+
+// Search returns the sessions matching the criteria ordered by creation time
+func (ss *SessionStore) Search(criteria *models.SessionFilterCriteria) ([]kbs.KeyTransferSession, error) {
+	defaultLog.Trace("postgres/session_store:Search() Entering")
+	defer defaultLog.Trace("postgres/session_store:Search() Leaving")
+
+	tx := ss.Store.Db.Model(&keyTransferSession{})
+	if criteria != nil {
+		if criteria.StmLabel != "" {
+			tx = tx.Where("stm_label = ?", criteria.StmLabel)
+		}
+		if !criteria.ActiveAt.IsZero() {
+			tx = tx.Where("expires > ?", criteria.ActiveAt)
+		}
+	}
+
+	var dbSessions []keyTransferSession
+	if err := tx.Order("created").Find(&dbSessions).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/session_store:Search() Failed to search sessions")
+	}
+
+	sessions := []kbs.KeyTransferSession{}
+	for i := range dbSessions {
+		session, err := ss.fromDbSession(&dbSessions[i])
+		if err != nil {
+			return nil, errors.Wrap(err, "postgres/session_store:Search() Failed to unwrap session key")
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, nil
+}
+
+func (ss *SessionStore) DeleteExpired(now time.Time) (int, error) {
+	defaultLog.Trace("postgres/session_store:DeleteExpired() Entering")
+	defer defaultLog.Trace("postgres/session_store:DeleteExpired() Leaving")
+
+	db := ss.Store.Db.Where("expires < ?", now).Delete(&keyTransferSession{})
+	if db.Error != nil {
+		return 0, errors.Wrap(db.Error, "postgres/session_store:DeleteExpired() Failed to delete expired sessions")
+	}
+	return int(db.RowsAffected), nil
+}
+
+func (ss *SessionStore) toDbSession(session *kbs.KeyTransferSession) (*keyTransferSession, error) {
+	wrappedSwk, err := ss.wrapSwk(session.SessionId, session.SWK)
+	if err != nil {
+		return nil, err
+	}
+	dbSession := keyTransferSession{
+		ID:             session.SessionId,
+		SWK:            wrappedSwk,
+		ClientCertHash: session.ClientCertHash,
+		StmLabel:       session.Stmlabel,
+		CreatedAt:      session.CreatedAt,
+		ExpiresAt:      session.SessionExpiryTime,
+	}
+	if session.QuoteVerifyAttributes != nil {
+		attributes := PGQuoteAttributes(*session.QuoteVerifyAttributes)
+		dbSession.QuoteVerifyAttributes = &attributes
+	}
+	return &dbSession, nil
+}
+
+func (ss *SessionStore) fromDbSession(dbSession *keyTransferSession) (*kbs.KeyTransferSession, error) {
+	swk, err := ss.unwrapSwk(dbSession.ID, dbSession.SWK)
+	if err != nil {
+		return nil, err
+	}
+	session := kbs.KeyTransferSession{
+		SWK:               swk,
+		SessionId:         dbSession.ID,
+		ClientCertHash:    dbSession.ClientCertHash,
+		Stmlabel:          dbSession.StmLabel,
+		CreatedAt:         dbSession.CreatedAt,
+		SessionExpiryTime: dbSession.ExpiresAt,
+	}
+	if dbSession.QuoteVerifyAttributes != nil {
+		attributes := kbs.QuoteVerifyAttributes(*dbSession.QuoteVerifyAttributes)
+		session.QuoteVerifyAttributes = &attributes
+	}
+	return &session, nil
+}
+
+// wrapSwk returns the nonce followed by the sealed session wrapping key, sessions without a key are stored without one
+func (ss *SessionStore) wrapSwk(sessionId string, swk []byte) ([]byte, error) {
+	if len(swk) == 0 {
+		return nil, nil
+	}
+	nonce := make([]byte, ss.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+	return ss.aead.Seal(nonce, nonce, swk, []byte(sessionId)), nil
+}
+
+func (ss *SessionStore) unwrapSwk(sessionId string, wrappedSwk []byte) ([]byte, error) {
+	if len(wrappedSwk) == 0 {
+		return nil, nil
+	}
+	nonceSize := ss.aead.NonceSize()
+	if len(wrappedSwk) < nonceSize {
+		return nil, errors.New("wrapped session key is too short")
+	}
+	swk, err := ss.aead.Open(nil, wrappedSwk[:nonceSize], wrappedSwk[nonceSize:], []byte(sessionId))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unwrap session key")
+	}
+	return swk, nil
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSessionId = "4d2c1b0a-9e8f-4a7b-b6c5-d4e3f2a1b0c9"
+
+// capturedBytes matches any byte slice argument and keeps it
+type capturedBytes struct {
+	value []byte
+}
+
+func (c *capturedBytes) Match(v driver.Value) bool {
+	b, ok := v.([]byte)
+	c.value = b
+	return ok
+}
+
+func newTestSessionStore(t *testing.T) (*SessionStore, sqlmock.Sqlmock) {
+	dataStore, mock := NewSQLMockDataStore()
+	sessionStore, err := NewSessionStore(dataStore, bytes.Repeat([]byte{0x5a}, 32))
+	assert.NoError(t, err)
+	return sessionStore, mock
+}
+
+func TestSessionStoreWrapsSessionKey(t *testing.T) {
+	sessionStore, mock := newTestSessionStore(t)
+	swk := bytes.Repeat([]byte{0x42}, 32)
+	now := time.Now()
+
+	wrappedSwk := &capturedBytes{}
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "key_transfer_session"`)).
+		WithArgs(testSessionId, wrappedSwk, "", "stm-label", nil, now, now.Add(time.Hour)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testSessionId))
+	mock.ExpectCommit()
+
+	_, err := sessionStore.Create(&kbs.KeyTransferSession{
+		SessionId:         testSessionId,
+		SWK:               swk,
+		Stmlabel:          "stm-label",
+		CreatedAt:         now,
+		SessionExpiryTime: now.Add(time.Hour),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// the database never sees the session key
+	assert.NotEmpty(t, wrappedSwk.value)
+	assert.False(t, bytes.Contains(wrappedSwk.value, swk))
+
+	columns := []string{"id", "swk", "client_cert_hash", "stm_label", "quote_verify_attributes", "created", "expires"}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "key_transfer_session" WHERE (id = $1)`)).
+		WithArgs(testSessionId).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(testSessionId, wrappedSwk.value, "", "stm-label", nil, now, now.Add(time.Hour)))
+	session, err := sessionStore.Retrieve(testSessionId)
+	assert.NoError(t, err)
+	assert.Equal(t, swk, session.SWK)
+
+	// a wrapped key moved to another session cannot be unwrapped
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "key_transfer_session" WHERE (id = $1)`)).
+		WithArgs("5e3d2c1b-0a9f-4b8c-a7d6-e5f4a3b2c1d0").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("5e3d2c1b-0a9f-4b8c-a7d6-e5f4a3b2c1d0", wrappedSwk.value, "", "stm-label", nil, now, now.Add(time.Hour)))
+	_, err = sessionStore.Retrieve("5e3d2c1b-0a9f-4b8c-a7d6-e5f4a3b2c1d0")
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -132,7 +132,7 @@ func permissionsHandler(eh endpointHandler, permissionNames []string) endpointHa
 	}
 }
 
-func permissionsHandlerUsingTLSMAuth(eh endpointHandler, aasAPIUrl string, kbsConfig config.KBSConfig) endpointHandler {
+func permissionsHandlerUsingTLSMAuth(eh endpointHandler, aasAPIUrl string, kbsConfig config.KBSConfig, revocationChecker *crypt.RevocationChecker) endpointHandler {
 	defaultLog.Trace("router/handlers:permissionsHandlerUsingTLSMAuth() Entering")
 	defer defaultLog.Trace("router/handlers:permissionsHandlerUsingTLSMAuth() Leaving")
 
@@ -155,13 +155,23 @@ func permissionsHandlerUsingTLSMAuth(eh endpointHandler, aasAPIUrl string, kbsCo
 			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
 		}
 
-		if _, err := request.TLS.PeerCertificates[0].Verify(verifyRootCAOpts); err != nil {
+		chains, err := request.TLS.PeerCertificates[0].Verify(verifyRootCAOpts)
+		if err != nil {
 			secLog.WithError(err).Error("router/handlers:permissionsHandlerUsingTLSMAuth() Error verifying certificate chain for TLS certificate. No " +
 				"valid certificate chain could be found")
 			return errors.New("Error verifying certificate chain for TLS certificate. No " +
 				"valid certificate chain could be found")
 		}
 
+		if err = revocationChecker.CheckChain(chains[0]); err != nil {
+			if crypt.IsCertificateRevoked(err) {
+				secLog.WithError(err).Errorf("router/handlers:permissionsHandlerUsingTLSMAuth() %s TLS client certificate is revoked", commLogMsg.UnauthorizedAccess)
+				return &commErr.PrivilegeError{Message: "TLS client certificate is revoked", StatusCode: http.StatusUnauthorized}
+			}
+			secLog.WithError(err).Error("router/handlers:permissionsHandlerUsingTLSMAuth() Error checking the revocation status of the TLS certificate")
+			return &commErr.PrivilegeError{Message: "Revocation status of the TLS client certificate could not be determined", StatusCode: http.StatusUnauthorized}
+		}
+
 		secLog.Debug("router/handlers:permissionsHandlerUsingTLSMAuth() TLS certificate chain verification successful")
 
 		client, err := clients.HTTPClientWithCA(caCerts)
@@ -8,18 +8,16 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/controllers"
-	"github.com/intel-secl/intel-secl/v4/pkg/kbs/directory"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
 )
 
 //setKeyTransferPolicyRoutes registers routes to perform KeyTransferPolicy CRUD operations
-func setKeyTransferPolicyRoutes(router *mux.Router) *mux.Router {
+func setKeyTransferPolicyRoutes(router *mux.Router, stores domain.Stores) *mux.Router {
 	defaultLog.Trace("router/key_transfer_policy:setKeyTransferPolicyRoutes() Entering")
 	defer defaultLog.Trace("router/key_transfer_policy:setKeyTransferPolicyRoutes() Leaving")
 
-	keyStore := directory.NewKeyStore(constants.KeysDir)
-	policyStore := directory.NewKeyTransferPolicyStore(constants.KeysTransferPolicyDir)
-	transferPolicyController := controllers.NewKeyTransferPolicyController(policyStore, keyStore)
+	transferPolicyController := controllers.NewKeyTransferPolicyController(stores.KeyTransferPolicyStore, stores.KeyStore)
 	keyTransferPolicyIdExpr := "/key-transfer-policies/" + validation.IdReg
 
 	router.Handle("/key-transfer-policies",
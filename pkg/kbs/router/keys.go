@@ -9,22 +9,20 @@ import (
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/config"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/controllers"
-	"github.com/intel-secl/intel-secl/v4/pkg/kbs/directory"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/keymanager"
 	consts "github.com/intel-secl/intel-secl/v4/pkg/lib/common/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
 )
 
 //setKeyRoutes registers routes to perform Key CRUD operations
-func setKeyRoutes(router *mux.Router, endpointUrl string, config domain.KeyControllerConfig, keyManager keymanager.KeyManager) *mux.Router {
+func setKeyRoutes(router *mux.Router, endpointUrl string, config domain.KeyControllerConfig, keyManager keymanager.KeyManager, stores domain.Stores) *mux.Router {
 	defaultLog.Trace("router/keys:setKeyRoutes() Entering")
 	defer defaultLog.Trace("router/keys:setKeyRoutes() Leaving")
 
-	keyStore := directory.NewKeyStore(constants.KeysDir)
-	policyStore := directory.NewKeyTransferPolicyStore(constants.KeysTransferPolicyDir)
-	remoteManager := keymanager.NewRemoteManager(keyStore, keyManager, endpointUrl)
-	keyController := controllers.NewKeyController(remoteManager, policyStore, config)
+	remoteManager := keymanager.NewRemoteManager(stores.KeyStore, keyManager, endpointUrl)
+	keyController := controllers.NewKeyController(remoteManager, stores.KeyTransferPolicyStore, config)
 	keyIdExpr := "/keys/" + validation.IdReg
 
 	router.Handle("/keys",
@@ -47,18 +45,20 @@ func setKeyRoutes(router *mux.Router, endpointUrl string, config domain.KeyContr
 		ErrorHandler(permissionsHandler(JsonResponseHandler(keyController.Transfer),
 			[]string{constants.KeyTransfer}))).Methods("POST")
 
+	router.Handle(keyIdExpr+"/rotate",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(keyController.Rotate),
+			[]string{constants.KeyRotate}))).Methods("POST")
+
 	return router
 }
 
 //setKeyTransferRoutes registers routes to perform Key Transfer operations
-func setKeyTransferRoutes(router *mux.Router, endpointUrl string, config domain.KeyControllerConfig, keyManager keymanager.KeyManager) *mux.Router {
+func setKeyTransferRoutes(router *mux.Router, endpointUrl string, config domain.KeyControllerConfig, keyManager keymanager.KeyManager, stores domain.Stores) *mux.Router {
 	defaultLog.Trace("router/keys:setKeyTransferRoutes() Entering")
 	defer defaultLog.Trace("router/keys:setKeyTransferRoutes() Leaving")
 
-	keyStore := directory.NewKeyStore(constants.KeysDir)
-	policyStore := directory.NewKeyTransferPolicyStore(constants.KeysTransferPolicyDir)
-	remoteManager := keymanager.NewRemoteManager(keyStore, keyManager, endpointUrl)
-	keyController := controllers.NewKeyController(remoteManager, policyStore, config)
+	remoteManager := keymanager.NewRemoteManager(stores.KeyStore, keyManager, endpointUrl)
+	keyController := controllers.NewKeyController(remoteManager, stores.KeyTransferPolicyStore, config)
 	keyIdExpr := "/keys/" + validation.IdReg
 
 	router.Handle(keyIdExpr+"/transfer",
@@ -68,19 +68,17 @@ func setKeyTransferRoutes(router *mux.Router, endpointUrl string, config domain.
 }
 
 //setSKCKeyTransferRoutes registers routes to perform SKC Transfer operations
-func setSKCKeyTransferRoutes(router *mux.Router, kbsConfig *config.Configuration, keyManager keymanager.KeyManager) *mux.Router {
+func setSKCKeyTransferRoutes(router *mux.Router, kbsConfig *config.Configuration, keyManager keymanager.KeyManager, stores domain.Stores, revocationChecker *crypt.RevocationChecker) *mux.Router {
 	defaultLog.Trace("router/keys:setSKCKeyTransferRoutes() Entering")
 	defer defaultLog.Trace("router/keys:setSKCKeyTransferRoutes() Leaving")
 
-	keyStore := directory.NewKeyStore(constants.KeysDir)
-	policyStore := directory.NewKeyTransferPolicyStore(constants.KeysTransferPolicyDir)
-	remoteManager := keymanager.NewRemoteManager(keyStore, keyManager, kbsConfig.EndpointURL)
-	skcController := controllers.NewSKCController(remoteManager, policyStore, kbsConfig, constants.TrustedCaCertsDir)
+	remoteManager := keymanager.NewRemoteManager(stores.KeyStore, keyManager, kbsConfig.EndpointURL)
+	skcController := controllers.NewSKCController(remoteManager, stores.KeyTransferPolicyStore, stores.SessionStore, kbsConfig, constants.TrustedCaCertsDir)
 	keyIdExpr := "/keys/" + validation.IdReg
 
 	router.Handle(keyIdExpr+"/dhsm2-transfer",
 		ErrorHandler(permissionsHandlerUsingTLSMAuth(JsonResponseHandler(skcController.TransferApplicationKey),
-			kbsConfig.AASApiUrl, kbsConfig.KBS))).Methods("GET")
+			kbsConfig.AASApiUrl, kbsConfig.KBS, revocationChecker))).Methods("GET")
 
 	return router
 }
@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v4/pkg/clients"
+	"github.com/intel-secl/intel-secl/v4/pkg/clients/aas"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/config"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain"
@@ -21,6 +23,7 @@ import (
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
 	cmw "github.com/intel-secl/intel-secl/v4/pkg/lib/common/middleware"
 	cos "github.com/intel-secl/intel-secl/v4/pkg/lib/common/os"
+	aasModel "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
 	"github.com/pkg/errors"
 )
 
@@ -32,7 +35,7 @@ type Router struct {
 }
 
 // InitRoutes registers all routes for the application.
-func InitRoutes(cfg *config.Configuration, keyConfig domain.KeyControllerConfig, keyManager keymanager.KeyManager) *mux.Router {
+func InitRoutes(cfg *config.Configuration, keyConfig domain.KeyControllerConfig, keyManager keymanager.KeyManager, stores domain.Stores, revocationChecker *crypt.RevocationChecker) *mux.Router {
 	defaultLog.Trace("router/router:InitRoutes() Entering")
 	defer defaultLog.Trace("router/router:InitRoutes() Leaving")
 
@@ -43,34 +46,35 @@ func InitRoutes(cfg *config.Configuration, keyConfig domain.KeyControllerConfig,
 	router.SkipClean(true)
 
 	// Define sub routes for path /kbs/v1
-	defineSubRoutes(router, "/"+strings.ToLower(constants.ServiceName)+constants.ApiVersion, cfg, keyConfig, keyManager)
+	defineSubRoutes(router, "/"+strings.ToLower(constants.ServiceName)+constants.ApiVersion, cfg, keyConfig, keyManager, stores, revocationChecker)
 
 	// Define sub routes for path /v1
-	defineSubRoutes(router, constants.ApiVersion, cfg, keyConfig, keyManager)
+	defineSubRoutes(router, constants.ApiVersion, cfg, keyConfig, keyManager, stores, revocationChecker)
 
 	return router
 }
 
-func defineSubRoutes(router *mux.Router, serviceApi string, cfg *config.Configuration, keyConfig domain.KeyControllerConfig, keyManager keymanager.KeyManager) {
+func defineSubRoutes(router *mux.Router, serviceApi string, cfg *config.Configuration, keyConfig domain.KeyControllerConfig, keyManager keymanager.KeyManager, stores domain.Stores, revocationChecker *crypt.RevocationChecker) {
 	defaultLog.Trace("router/router:defineSubRoutes() Entering")
 	defer defaultLog.Trace("router/router:defineSubRoutes() Leaving")
 
 	subRouter := router.PathPrefix(serviceApi).Subrouter()
 	subRouter = setVersionRoutes(subRouter)
-	subRouter = setKeyTransferRoutes(subRouter, cfg.EndpointURL, keyConfig, keyManager)
-	subRouter = setSKCKeyTransferRoutes(subRouter, cfg, keyManager)
-	subRouter = setSessionRoutes(subRouter, cfg)
+	subRouter = setKeyTransferRoutes(subRouter, cfg.EndpointURL, keyConfig, keyManager, stores)
+	subRouter = setSKCKeyTransferRoutes(subRouter, cfg, keyManager, stores, revocationChecker)
+	subRouter = setSessionRoutes(subRouter, cfg, stores.SessionStore, revocationChecker)
 	subRouter = router.PathPrefix(serviceApi).Subrouter()
 	cfgRouter := Router{cfg: cfg}
 	var cacheTime, _ = time.ParseDuration(constants.JWTCertsCacheTime)
 
-	subRouter.Use(cmw.NewTokenAuth(constants.TrustedJWTSigningCertsDir,
+	subRouter.Use(cmw.NewTokenAuthWithRevocationList(constants.TrustedJWTSigningCertsDir,
 		constants.TrustedCaCertsDir, cfgRouter.fnGetJwtCerts,
-		cacheTime))
-	subRouter = setKeyRoutes(subRouter, cfg.EndpointURL, keyConfig, keyManager)
-	subRouter = setKeyTransferPolicyRoutes(subRouter)
-	subRouter = setSamlCertRoutes(subRouter)
-	subRouter = setTpmIdentityCertRoutes(subRouter)
+		cacheTime, cfgRouter.fnGetTokenRevocationList, cmw.DefaultRevocationListCacheTime))
+	subRouter = setKeyRoutes(subRouter, cfg.EndpointURL, keyConfig, keyManager, stores)
+	subRouter = setKeyTransferPolicyRoutes(subRouter, stores)
+	subRouter = setSamlCertRoutes(subRouter, stores.SamlCertStore)
+	subRouter = setTpmIdentityCertRoutes(subRouter, stores.TpmIdentityCertStore)
+	subRouter = setSessionAdminRoutes(subRouter, cfg, stores.SessionStore)
 }
 
 // Fetch JWT certificate from AAS
@@ -128,3 +132,29 @@ func (router *Router) fnGetJwtCerts() error {
 	}
 	return nil
 }
+
+// Fetch token revocation list from AAS
+func (router *Router) fnGetTokenRevocationList() (*aasModel.TokenRevocationList, error) {
+	defaultLog.Trace("router/router:fnGetTokenRevocationList() Entering")
+	defer defaultLog.Trace("router/router:fnGetTokenRevocationList() Leaving")
+
+	caCerts, err := crypt.GetCertsFromDir(constants.TrustedCaCertsDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "router/router:fnGetTokenRevocationList() Could not read root CA certificates")
+	}
+	httpClient, err := clients.HTTPClientWithCA(caCerts)
+	if err != nil {
+		return nil, errors.Wrap(err, "router/router:fnGetTokenRevocationList() Could not create http client")
+	}
+	// a hung AAS must not hold up the refresh of the revocation list
+	httpClient.Timeout = cmw.DefaultRevocationListRetrieveTimeout
+	aasClient := aas.Client{
+		BaseURL:    router.cfg.AASApiUrl,
+		HTTPClient: httpClient,
+	}
+	revocationList, err := aasClient.GetTokenRevocationList()
+	if err != nil {
+		return nil, errors.Wrap(err, "router/router:fnGetTokenRevocationList() Could not retrieve token revocation list")
+	}
+	return revocationList, nil
+}
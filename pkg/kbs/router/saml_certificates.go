@@ -8,16 +8,15 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/controllers"
-	"github.com/intel-secl/intel-secl/v4/pkg/kbs/directory"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
 )
 
 //setSamlCertRoutes registers routes to perform SamlCertificate CRUD operations
-func setSamlCertRoutes(router *mux.Router) *mux.Router {
+func setSamlCertRoutes(router *mux.Router, certStore domain.CertificateStore) *mux.Router {
 	defaultLog.Trace("router/saml_certificates:setSamlCertRoutes() Entering")
 	defer defaultLog.Trace("router/saml_certificates:setSamlCertRoutes() Leaving")
 
-	certStore := directory.NewCertificateStore(constants.SamlCertsDir)
 	samlCertController := controllers.NewCertificateController(certStore)
 	certIdExpr := "/saml-certificates/" + validation.IdReg
 
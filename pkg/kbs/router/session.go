@@ -9,17 +9,37 @@ import (
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/config"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/controllers"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
 )
 
 //setSessionRoutes registers routes to perform session management operations
-func setSessionRoutes(router *mux.Router, kbsConfig *config.Configuration) *mux.Router {
+func setSessionRoutes(router *mux.Router, kbsConfig *config.Configuration, sessionStore domain.SessionStore, revocationChecker *crypt.RevocationChecker) *mux.Router {
 	defaultLog.Trace("router/keys:setSessionRoutes() Entering")
 	defer defaultLog.Trace("router/keys:setSessionRoutes() Leaving")
 
-	sessionController := controllers.NewSessionController(kbsConfig, constants.TrustedCaCertsDir)
+	sessionController := controllers.NewSessionController(sessionStore, kbsConfig, constants.TrustedCaCertsDir)
 
 	router.Handle("/session",
 		ErrorHandler(permissionsHandlerUsingTLSMAuth(JsonResponseHandler(sessionController.Create),
-			kbsConfig.AASApiUrl, kbsConfig.KBS))).Methods("POST")
+			kbsConfig.AASApiUrl, kbsConfig.KBS, revocationChecker))).Methods("POST")
+	return router
+}
+
+//setSessionAdminRoutes registers routes to list and revoke the active sessions
+func setSessionAdminRoutes(router *mux.Router, kbsConfig *config.Configuration, sessionStore domain.SessionStore) *mux.Router {
+	defaultLog.Trace("router/session:setSessionAdminRoutes() Entering")
+	defer defaultLog.Trace("router/session:setSessionAdminRoutes() Leaving")
+
+	sessionController := controllers.NewSessionController(sessionStore, kbsConfig, constants.TrustedCaCertsDir)
+	sessionIdExpr := "/sessions/" + validation.IdReg
+
+	router.Handle("/sessions", ErrorHandler(permissionsHandler(JsonResponseHandler(sessionController.Search),
+		[]string{constants.SessionSearch}))).Methods("GET")
+
+	router.Handle(sessionIdExpr, ErrorHandler(permissionsHandler(JsonResponseHandler(sessionController.Delete),
+		[]string{constants.SessionDelete}))).Methods("DELETE")
+
 	return router
 }
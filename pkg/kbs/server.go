@@ -12,15 +12,22 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gorilla/handlers"
+	"github.com/intel-secl/intel-secl/v4/pkg/clients"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/config"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/directory"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/keymanager"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/postgres"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/router"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/session"
 	"github.com/intel-secl/intel-secl/v4/pkg/kbs/utils"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	commLog "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
 	commLogMsg "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/message"
 	"github.com/pkg/errors"
@@ -42,8 +49,17 @@ func (app *App) startServer() error {
 		return err
 	}
 
+	// Initialize the stores
+	stores, dataStore, err := initStores(configuration)
+	if err != nil {
+		return err
+	}
+	if dataStore != nil {
+		defer dataStore.Close()
+	}
+
 	// Initialize KeyControllerConfig
-	kcc, err := initKeyControllerConfig()
+	kcc, err := initKeyControllerConfig(stores)
 	if err != nil {
 		return err
 	}
@@ -54,8 +70,24 @@ func (app *App) startServer() error {
 		return err
 	}
 
+	// Initialize the revocation check of the TLS client certificates
+	revocationChecker, err := initRevocationChecker(configuration)
+	if err != nil {
+		return err
+	}
+
 	// Initialize routes
-	routes := router.InitRoutes(configuration, kcc, km)
+	routes := router.InitRoutes(configuration, kcc, km, stores, revocationChecker)
+
+	// Start the automatic key rotation
+	rotationDone := make(chan struct{})
+	defer close(rotationDone)
+	go rotateKeys(keymanager.NewRemoteManager(stores.KeyStore, km, configuration.EndpointURL), configuration.KeyRotation.CheckInterval, rotationDone)
+
+	// Start the cleanup of the expired SKC sessions
+	cleanupDone := make(chan struct{})
+	defer close(cleanupDone)
+	go deleteExpiredSessions(stores.SessionStore, constants.DefaultSessionCleanupInterval, cleanupDone)
 
 	defaultLog.Info("kbs/server:startServer() Starting server")
 	tlsConfig := &tls.Config{
@@ -106,7 +138,106 @@ func (app *App) startServer() error {
 	return nil
 }
 
-func initKeyControllerConfig() (domain.KeyControllerConfig, error) {
+// rotateKeys rotates the keys due for automatic rotation every checkInterval until done is closed
+func rotateKeys(remoteManager *keymanager.RemoteManager, checkInterval time.Duration, done <-chan struct{}) {
+	defaultLog.Trace("kbs/server:rotateKeys() Entering")
+	defer defaultLog.Trace("kbs/server:rotateKeys() Leaving")
+
+	if checkInterval <= 0 {
+		checkInterval = constants.DefaultKeyRotationCheckInterval
+	}
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			rotatedKeys, err := remoteManager.RotateDueKeys(now.UTC())
+			if err != nil {
+				defaultLog.WithError(err).Error("kbs/server:rotateKeys() Failed to rotate the keys due for rotation")
+				continue
+			}
+			if rotatedKeys > 0 {
+				defaultLog.Infof("kbs/server:rotateKeys() %d keys rotated", rotatedKeys)
+			}
+		}
+	}
+}
+
+// deleteExpiredSessions removes the expired SKC sessions from the session store every cleanupInterval until done is
+// closed
+func deleteExpiredSessions(sessionStore domain.SessionStore, cleanupInterval time.Duration, done <-chan struct{}) {
+	defaultLog.Trace("kbs/server:deleteExpiredSessions() Entering")
+	defer defaultLog.Trace("kbs/server:deleteExpiredSessions() Leaving")
+
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			deleted, err := sessionStore.DeleteExpired(now.UTC())
+			if err != nil {
+				defaultLog.WithError(err).Error("kbs/server:deleteExpiredSessions() Failed to delete the expired sessions")
+				continue
+			}
+			if deleted > 0 {
+				defaultLog.Debugf("kbs/server:deleteExpiredSessions() %d expired sessions deleted", deleted)
+			}
+		}
+	}
+}
+
+// initStores returns the stores of the configured store type. The data store is returned along with the
+// postgres stores so that the database connection can be closed on shutdown.
+func initStores(cfg *config.Configuration) (domain.Stores, *postgres.DataStore, error) {
+	defaultLog.Trace("server:initStores() Entering")
+	defer defaultLog.Trace("server:initStores() Leaving")
+
+	switch strings.ToLower(cfg.StoreType) {
+	case "", constants.DirectoryStoreType:
+		return domain.Stores{
+			KeyStore:               directory.NewKeyStore(constants.KeysDir),
+			KeyTransferPolicyStore: directory.NewKeyTransferPolicyStore(constants.KeysTransferPolicyDir),
+			SamlCertStore:          directory.NewCertificateStore(constants.SamlCertsDir),
+			TpmIdentityCertStore:   directory.NewCertificateStore(constants.TpmIdentityCertsDir),
+			SessionStore:           session.NewMemoryStore(),
+		}, nil, nil
+	case constants.PostgresStoreType:
+		dataStore, err := postgres.InitDatabase(&cfg.DB)
+		if err != nil {
+			return domain.Stores{}, nil, errors.Wrap(err, "kbs/server:initStores() Failed to initialize database")
+		}
+		// the session wrapping keys are wrapped with the master key before they are persisted
+		masterKeyFile := cfg.Directory.MasterKeyFilePath
+		if masterKeyFile == "" {
+			masterKeyFile = constants.DefaultMasterKeyPath
+		}
+		masterKey, err := keymanager.LoadMasterKey(masterKeyFile)
+		if err != nil {
+			return domain.Stores{}, nil, errors.Wrap(err, "kbs/server:initStores() Failed to load master key")
+		}
+		sessionStore, err := postgres.NewSessionStore(dataStore, masterKey)
+		if err != nil {
+			return domain.Stores{}, nil, errors.Wrap(err, "kbs/server:initStores() Failed to initialize session store")
+		}
+		return domain.Stores{
+			KeyStore:               postgres.NewKeyStore(dataStore),
+			KeyTransferPolicyStore: postgres.NewKeyTransferPolicyStore(dataStore),
+			SamlCertStore:          postgres.NewCertificateStore(dataStore, postgres.SamlCertType),
+			TpmIdentityCertStore:   postgres.NewCertificateStore(dataStore, postgres.TpmIdentityCertType),
+			SessionStore:           sessionStore,
+		}, dataStore, nil
+	default:
+		return domain.Stores{}, nil, errors.Errorf("kbs/server:initStores() Unsupported store type %s", cfg.StoreType)
+	}
+}
+
+func initKeyControllerConfig(stores domain.Stores) (domain.KeyControllerConfig, error) {
 	defaultLog.Trace("server:initKeyControllerConfig() Entering")
 	defer defaultLog.Trace("server:initKeyControllerConfig() Leaving")
 
@@ -116,10 +247,32 @@ func initKeyControllerConfig() (domain.KeyControllerConfig, error) {
 	}
 
 	kcc := domain.KeyControllerConfig{
-		SamlCertsDir:            constants.SamlCertsDir,
+		SamlCertStore:           stores.SamlCertStore,
 		TrustedCaCertsDir:       constants.TrustedCaCertsDir,
-		TpmIdentityCertsDir:     constants.TpmIdentityCertsDir,
+		TpmIdentityCertStore:    stores.TpmIdentityCertStore,
 		DefaultTransferPolicyId: id,
 	}
 	return kcc, nil
 }
+
+// initRevocationChecker creates the checker of the revocation status of the TLS client certificates, it is nil when
+// the revocation check is disabled
+func initRevocationChecker(cfg *config.Configuration) (*crypt.RevocationChecker, error) {
+	defaultLog.Trace("server:initRevocationChecker() Entering")
+	defer defaultLog.Trace("server:initRevocationChecker() Leaving")
+
+	if !cfg.RevocationCheck.Enabled {
+		return nil, nil
+	}
+
+	// the CRLs and OCSP responses are published by CMS, trust the CAs of KBS when fetching them
+	caCerts, err := crypt.GetCertsFromDir(constants.TrustedCaCertsDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "server:initRevocationChecker() Error while getting certs from %s", constants.TrustedCaCertsDir)
+	}
+	client, err := clients.HTTPClientWithCA(caCerts)
+	if err != nil {
+		return nil, errors.Wrap(err, "server:initRevocationChecker() Error creating HTTP client")
+	}
+	return crypt.NewRevocationChecker(cfg.RevocationCheck, client), nil
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package session
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+	"github.com/pkg/errors"
+)
+
+// MemoryStore keeps the SKC sessions in the memory of the KBS process, the sessions are lost on restart and are not
+// shared between KBS instances
+type MemoryStore struct {
+	mutex    sync.RWMutex
+	sessions map[string]kbs.KeyTransferSession
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]kbs.KeyTransferSession),
+	}
+}
+
+func (ms *MemoryStore) Create(session *kbs.KeyTransferSession) (*kbs.KeyTransferSession, error) {
+	defaultLog.Trace("session/memory_store:Create() Entering")
+	defer defaultLog.Trace("session/memory_store:Create() Leaving")
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if _, ok := ms.sessions[session.SessionId]; ok {
+		return nil, errors.Errorf("session/memory_store:Create() Session %s already exists", session.SessionId)
+	}
+	ms.sessions[session.SessionId] = *session
+	return session, nil
+}
+
+func (ms *MemoryStore) Retrieve(sessionId string) (*kbs.KeyTransferSession, error) {
+	defaultLog.Trace("session/memory_store:Retrieve() Entering")
+	defer defaultLog.Trace("session/memory_store:Retrieve() Leaving")
+
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	session, ok := ms.sessions[sessionId]
+	if !ok {
+		return nil, errors.New(commErr.RecordNotFound)
+	}
+	return &session, nil
+}
+
+func (ms *MemoryStore) Update(session *kbs.KeyTransferSession) (*kbs.KeyTransferSession, error) {
+	defaultLog.Trace("session/memory_store:Update() Entering")
+	defer defaultLog.Trace("session/memory_store:Update() Leaving")
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if _, ok := ms.sessions[session.SessionId]; !ok {
+		return nil, errors.New(commErr.RecordNotFound)
+	}
+	ms.sessions[session.SessionId] = *session
+	return session, nil
+}
+
+func (ms *MemoryStore) Delete(sessionId string) error {
+	defaultLog.Trace("session/memory_store:Delete() Entering")
+	defer defaultLog.Trace("session/memory_store:Delete() Leaving")
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if _, ok := ms.sessions[sessionId]; !ok {
+		return errors.New(commErr.RecordNotFound)
+	}
+	delete(ms.sessions, sessionId)
+	return nil
+}
+
+// Search returns the sessions matching the criteria ordered by creation time
+func (ms *MemoryStore) Search(criteria *models.SessionFilterCriteria) ([]kbs.KeyTransferSession, error) {
+	defaultLog.Trace("session/memory_store:Search() Entering")
+	defer defaultLog.Trace("session/memory_store:Search() Leaving")
+
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	sessions := []kbs.KeyTransferSession{}
+	for _, session := range ms.sessions {
+		if criteria != nil {
+			if criteria.StmLabel != "" && session.Stmlabel != criteria.StmLabel {
+				continue
+			}
+			if !criteria.ActiveAt.IsZero() && !session.SessionExpiryTime.After(criteria.ActiveAt) {
+				continue
+			}
+		}
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+func (ms *MemoryStore) DeleteExpired(now time.Time) (int, error) {
+	defaultLog.Trace("session/memory_store:DeleteExpired() Entering")
+	defer defaultLog.Trace("session/memory_store:DeleteExpired() Leaving")
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	deleted := 0
+	for sessionId, session := range ms.sessions {
+		if session.SessionExpiryTime.Before(now) {
+			delete(ms.sessions, sessionId)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now().UTC()
+
+	_, err := store.Create(&kbs.KeyTransferSession{SessionId: "active", Stmlabel: "SGX", CreatedAt: now, SessionExpiryTime: now.Add(time.Minute)})
+	assert.NoError(t, err)
+	_, err = store.Create(&kbs.KeyTransferSession{SessionId: "expired", Stmlabel: "SGX", CreatedAt: now.Add(-2 * time.Minute), SessionExpiryTime: now.Add(-time.Minute)})
+	assert.NoError(t, err)
+	_, err = store.Create(&kbs.KeyTransferSession{SessionId: "active"})
+	assert.Error(t, err)
+
+	session, err := store.Retrieve("active")
+	assert.NoError(t, err)
+	session.SWK = []byte("swk")
+	session.QuoteVerifyAttributes = &kbs.QuoteVerifyAttributes{TCBLevel: "UpToDate"}
+	_, err = store.Update(session)
+	assert.NoError(t, err)
+	_, err = store.Update(&kbs.KeyTransferSession{SessionId: "unknown"})
+	assert.EqualError(t, err, commErr.RecordNotFound)
+
+	session, err = store.Retrieve("active")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("swk"), session.SWK)
+	assert.Equal(t, "UpToDate", session.QuoteVerifyAttributes.TCBLevel)
+
+	sessions, err := store.Search(nil)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, "expired", sessions[0].SessionId)
+
+	sessions, err = store.Search(&models.SessionFilterCriteria{StmLabel: "SGX", ActiveAt: now})
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, "active", sessions[0].SessionId)
+
+	deleted, err := store.DeleteExpired(now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	_, err = store.Retrieve("expired")
+	assert.EqualError(t, err, commErr.RecordNotFound)
+
+	assert.NoError(t, store.Delete("active"))
+	assert.EqualError(t, store.Delete("active"), commErr.RecordNotFound)
+}
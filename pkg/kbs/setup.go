@@ -130,12 +130,42 @@ func (app *App) setupTaskRunner() (*setup.Runner, error) {
 		Subject: pkix.Name{
 			CommonName: viper.GetString("tls-common-name"),
 		},
-		SanList:       viper.GetString("tls-san-list"),
-		CertType:      "tls",
-		CaCertDirPath: constants.TrustedCaCertsDir,
+		SanList:         viper.GetString("tls-san-list"),
+		CertType:        "tls",
+		CaCertDirPath:   constants.TrustedCaCertsDir,
+		ConsoleWriter:   app.consoleWriter(),
+		CmsBaseURL:      viper.GetString("cms-base-url"),
+		BearerToken:     viper.GetString("bearer-token"),
+		RevocationCheck: revocationCheckConfig(),
+	})
+	dbConf := commConfig.DBConfig{
+		Vendor:   viper.GetString("db-vendor"),
+		Host:     viper.GetString("db-host"),
+		Port:     viper.GetInt("db-port"),
+		DBName:   viper.GetString("db-name"),
+		Username: viper.GetString("db-username"),
+		Password: viper.GetString("db-password"),
+		SSLMode:  viper.GetString("db-ssl-mode"),
+		SSLCert:  viper.GetString("db-ssl-cert"),
+
+		ConnectionRetryAttempts: viper.GetInt("db-conn-retry-attempts"),
+		ConnectionRetryTime:     viper.GetInt("db-conn-retry-time"),
+	}
+	runner.AddTask("database", "", &tasks.DBSetup{
+		DBConfigPtr:   &app.Config.DB,
+		DBConfig:      dbConf,
+		SSLCertSource: viper.GetString("db-ssl-cert-source"),
+		StoreType:     viper.GetString("store-type"),
 		ConsoleWriter: app.consoleWriter(),
-		CmsBaseURL:    viper.GetString("cms-base-url"),
-		BearerToken:   viper.GetString("bearer-token"),
+	})
+	runner.AddTask("migrate-directory-stores", "", &tasks.MigrateDirectoryStores{
+		StoreType:            viper.GetString("store-type"),
+		DBConfig:             &app.Config.DB,
+		KeysDir:              constants.KeysDir,
+		KeyTransferPolicyDir: constants.KeysTransferPolicyDir,
+		SamlCertsDir:         constants.SamlCertsDir,
+		TpmIdentityCertsDir:  constants.TpmIdentityCertsDir,
+		ConsoleWriter:        app.consoleWriter(),
 	})
 	runner.AddTask("create-default-key-transfer-policy", "", &tasks.CreateDefaultTransferPolicy{
 		DefaultTransferPolicyFile: constants.DefaultTransferPolicyFile,
@@ -171,10 +201,11 @@ func (app *App) downloadCertTask(certType string) setup.Task {
 		Subject: pkix.Name{
 			CommonName: viper.GetString(certType + "-common-name"),
 		},
-		CertType:      certType,
-		CaCertDirPath: constants.TrustedCaCertsDir,
-		ConsoleWriter: app.consoleWriter(),
-		CmsBaseURL:    viper.GetString("cms-base-url"),
-		BearerToken:   viper.GetString("bearer-token"),
+		CertType:        certType,
+		CaCertDirPath:   constants.TrustedCaCertsDir,
+		ConsoleWriter:   app.consoleWriter(),
+		CmsBaseURL:      viper.GetString("cms-base-url"),
+		BearerToken:     viper.GetString("bearer-token"),
+		RevocationCheck: revocationCheckConfig(),
 	}
 }
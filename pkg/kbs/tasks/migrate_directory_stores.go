@@ -0,0 +1,210 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package tasks
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/directory"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/postgres"
+	commConfig "github.com/intel-secl/intel-secl/v4/pkg/lib/common/config"
+	commErr "github.com/intel-secl/intel-secl/v4/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/setup"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// MigrateDirectoryStores copies the keys, the key transfer policies and the certificates of the directory stores
+// into the database. The records already present in the database are left untouched so that the task can be run
+// again, the directories are not modified.
+type MigrateDirectoryStores struct {
+	StoreType            string
+	DBConfig             *commConfig.DBConfig
+	KeysDir              string
+	KeyTransferPolicyDir string
+	SamlCertsDir         string
+	TpmIdentityCertsDir  string
+	ConsoleWriter        io.Writer
+}
+
+const migrateEnvHelpPrompt = "Following environment variables are required for migrate-directory-stores setup:"
+
+var migrateEnvHelp = map[string]string{
+	"STORE_TYPE": "Store type of KBS, the directory stores are migrated when set to postgres",
+}
+
+func (m MigrateDirectoryStores) Run() error {
+	log.Trace("tasks/migrate_directory_stores:Run() Entering")
+	defer log.Trace("tasks/migrate_directory_stores:Run() Leaving")
+
+	if !isPostgresStore(m.StoreType) {
+		fmt.Fprintln(m.ConsoleWriter, "STORE_TYPE is not postgres, skipping migration of the directory stores")
+		return nil
+	}
+	_, err := m.migrate(true)
+	return err
+}
+
+// Validate succeeds once all the records of the directory stores are present in the database
+func (m MigrateDirectoryStores) Validate() error {
+	log.Trace("tasks/migrate_directory_stores:Validate() Entering")
+	defer log.Trace("tasks/migrate_directory_stores:Validate() Leaving")
+
+	if !isPostgresStore(m.StoreType) {
+		return nil
+	}
+	pending, err := m.migrate(false)
+	if err != nil {
+		return err
+	}
+	if pending != 0 {
+		return errors.Errorf("%d records of the directory stores are not migrated to the database", pending)
+	}
+	return nil
+}
+
+// migrate returns the number of records of the directory stores missing from the database, the missing records
+// are imported into the database when apply is set
+func (m MigrateDirectoryStores) migrate(apply bool) (int, error) {
+	if m.DBConfig == nil {
+		return 0, errors.New("Pointer to database configuration structure can not be nil")
+	}
+	dataStore, err := postgres.InitDatabase(m.DBConfig)
+	if err != nil {
+		return 0, errors.Wrap(err, "tasks/migrate_directory_stores:migrate() Failed to initialize database")
+	}
+	defer dataStore.Close()
+
+	return m.migrateStores(dataStore, apply)
+}
+
+func (m MigrateDirectoryStores) migrateStores(dataStore *postgres.DataStore, apply bool) (int, error) {
+	var pending int
+	count, err := m.migratePolicies(postgres.NewKeyTransferPolicyStore(dataStore), apply)
+	if err != nil {
+		return 0, err
+	}
+	pending += count
+
+	count, err = m.migrateKeys(postgres.NewKeyStore(dataStore), apply)
+	if err != nil {
+		return 0, err
+	}
+	pending += count
+
+	count, err = m.migrateCertificates(m.SamlCertsDir, postgres.NewCertificateStore(dataStore, postgres.SamlCertType), apply)
+	if err != nil {
+		return 0, err
+	}
+	pending += count
+
+	count, err = m.migrateCertificates(m.TpmIdentityCertsDir, postgres.NewCertificateStore(dataStore, postgres.TpmIdentityCertType), apply)
+	if err != nil {
+		return 0, err
+	}
+	return pending + count, nil
+}
+
+func (m MigrateDirectoryStores) migratePolicies(pgStore *postgres.KeyTransferPolicyStore, apply bool) (int, error) {
+	if !dirExists(m.KeyTransferPolicyDir) {
+		return 0, nil
+	}
+	policies, err := directory.NewKeyTransferPolicyStore(m.KeyTransferPolicyDir).Search(nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "tasks/migrate_directory_stores:migratePolicies() Failed to read key transfer policies")
+	}
+
+	var pending int
+	for i := range policies {
+		if _, err := pgStore.Retrieve(policies[i].ID); err == nil {
+			continue
+		} else if err.Error() != commErr.RecordNotFound {
+			return 0, errors.Wrapf(err, "tasks/migrate_directory_stores:migratePolicies() Failed to retrieve key transfer policy %s", policies[i].ID)
+		}
+		pending++
+		if apply {
+			if _, err := pgStore.Import(&policies[i]); err != nil {
+				return 0, errors.Wrapf(err, "tasks/migrate_directory_stores:migratePolicies() Failed to migrate key transfer policy %s", policies[i].ID)
+			}
+		}
+	}
+	if apply {
+		fmt.Fprintf(m.ConsoleWriter, "Migrated %d of %d key transfer policies\n", pending, len(policies))
+	}
+	return pending, nil
+}
+
+func (m MigrateDirectoryStores) migrateKeys(pgStore *postgres.KeyStore, apply bool) (int, error) {
+	if !dirExists(m.KeysDir) {
+		return 0, nil
+	}
+	keys, err := directory.NewKeyStore(m.KeysDir).Search(nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "tasks/migrate_directory_stores:migrateKeys() Failed to read keys")
+	}
+
+	var pending int
+	for i := range keys {
+		if _, err := pgStore.Retrieve(keys[i].ID); err == nil {
+			continue
+		} else if err.Error() != commErr.RecordNotFound {
+			return 0, errors.Wrapf(err, "tasks/migrate_directory_stores:migrateKeys() Failed to retrieve key %s", keys[i].ID)
+		}
+		pending++
+		if apply {
+			if _, err := pgStore.Create(&keys[i]); err != nil {
+				return 0, errors.Wrapf(err, "tasks/migrate_directory_stores:migrateKeys() Failed to migrate key %s", keys[i].ID)
+			}
+		}
+	}
+	if apply {
+		fmt.Fprintf(m.ConsoleWriter, "Migrated %d of %d keys\n", pending, len(keys))
+	}
+	return pending, nil
+}
+
+func (m MigrateDirectoryStores) migrateCertificates(dir string, pgStore *postgres.CertificateStore, apply bool) (int, error) {
+	if !dirExists(dir) {
+		return 0, nil
+	}
+	certs, err := directory.NewCertificateStore(dir).Search(nil)
+	if err != nil {
+		return 0, errors.Wrapf(err, "tasks/migrate_directory_stores:migrateCertificates() Failed to read certificates from %s", dir)
+	}
+
+	var pending int
+	for i := range certs {
+		if _, err := pgStore.Retrieve(certs[i].ID); err == nil {
+			continue
+		} else if err.Error() != commErr.RecordNotFound {
+			return 0, errors.Wrapf(err, "tasks/migrate_directory_stores:migrateCertificates() Failed to retrieve certificate %s", certs[i].ID)
+		}
+		pending++
+		if apply {
+			if _, err := pgStore.Import(&certs[i]); err != nil {
+				return 0, errors.Wrapf(err, "tasks/migrate_directory_stores:migrateCertificates() Failed to migrate certificate %s", certs[i].ID)
+			}
+		}
+	}
+	if apply {
+		fmt.Fprintf(m.ConsoleWriter, "Migrated %d of %d certificates from %s\n", pending, len(certs), dir)
+	}
+	return pending, nil
+}
+
+func (m MigrateDirectoryStores) PrintHelp(w io.Writer) {
+	setup.PrintEnvHelp(w, migrateEnvHelpPrompt, "", migrateEnvHelp)
+	fmt.Fprintln(w, "")
+}
+
+func (m MigrateDirectoryStores) SetName(n, e string) {
+}
+
+func dirExists(dir string) bool {
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir()
+}
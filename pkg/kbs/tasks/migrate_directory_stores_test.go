@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package tasks
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/directory"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v4/pkg/kbs/postgres"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/kbs"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestDirectoryStores populates the directory stores with a key transfer policy, a key and a SAML certificate
+func newTestDirectoryStores(t *testing.T) MigrateDirectoryStores {
+	tempDir, err := ioutil.TempDir("", "kbs-migrate")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+
+	m := MigrateDirectoryStores{
+		StoreType:            "postgres",
+		KeysDir:              filepath.Join(tempDir, "keys"),
+		KeyTransferPolicyDir: filepath.Join(tempDir, "keys-transfer-policy"),
+		SamlCertsDir:         filepath.Join(tempDir, "saml"),
+		TpmIdentityCertsDir:  filepath.Join(tempDir, "tpm-identity"),
+		ConsoleWriter:        &bytes.Buffer{},
+	}
+	for _, dir := range []string{m.KeysDir, m.KeyTransferPolicyDir, m.SamlCertsDir} {
+		assert.NoError(t, os.Mkdir(dir, 0700))
+	}
+
+	policy, err := directory.NewKeyTransferPolicyStore(m.KeyTransferPolicyDir).Create(&kbs.KeyTransferPolicyAttributes{
+		AttestationTypeAnyof: []string{"SGX"},
+	})
+	assert.NoError(t, err)
+	_, err = directory.NewKeyStore(m.KeysDir).Create(&models.KeyAttributes{
+		ID:               uuid.New(),
+		Algorithm:        "AES",
+		KeyLength:        256,
+		TransferPolicyId: policy.ID,
+		CreatedAt:        time.Now().UTC(),
+	})
+	assert.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "SAML Signing"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	_, err = directory.NewCertificateStore(m.SamlCertsDir).Create(&kbs.Certificate{
+		Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	})
+	assert.NoError(t, err)
+	return m
+}
+
+// expectRecords sets the expected lookups of the migrated records, the lookups return the given rows
+func expectRecords(mock sqlmock.Sqlmock, rows func() *sqlmock.Rows, inserted bool) {
+	for _, table := range []string{"key_transfer_policy", "key", "certificate"} {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "` + table + `"`)).WillReturnRows(rows())
+		if inserted {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "` + table + `"`)).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+			mock.ExpectCommit()
+		}
+	}
+}
+
+func TestMigrateDirectoryStores(t *testing.T) {
+	m := newTestDirectoryStores(t)
+	dataStore, mock := postgres.NewSQLMockDataStore()
+	missing := func() *sqlmock.Rows { return sqlmock.NewRows([]string{"id"}) }
+	present := func() *sqlmock.Rows { return sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()) }
+
+	// the records missing from the database are imported
+	expectRecords(mock, missing, true)
+	pending, err := m.migrateStores(dataStore, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, pending)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// running the migration again does not import the records twice
+	expectRecords(mock, present, false)
+	pending, err = m.migrateStores(dataStore, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, pending)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// validation only reports the records missing from the database
+	expectRecords(mock, missing, false)
+	pending, err = m.migrateStores(dataStore, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, pending)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateDirectoryStoresNotPostgres(t *testing.T) {
+	m := MigrateDirectoryStores{StoreType: "directory", ConsoleWriter: &bytes.Buffer{}}
+
+	assert.NoError(t, m.Run())
+	assert.NoError(t, m.Validate())
+}
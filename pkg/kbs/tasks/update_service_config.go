@@ -15,6 +15,7 @@ import (
 	"github.com/spf13/viper"
 	"io"
 	"strings"
+	"time"
 )
 
 type UpdateServiceConfig struct {
@@ -29,32 +30,45 @@ type UpdateServiceConfig struct {
 const envHelpPrompt = "Following environment variables are required for update-service-config setup:"
 
 var allowedSKCChallengeTypes = map[string]bool{"sgx": true}
-var allowedKeyManagers = map[string]bool{"kmip": true}
+var allowedKeyManagers = map[string]bool{"kmip": true, "directory": true, "pkcs11": true}
+var allowedStoreTypes = map[string]bool{"directory": true, "postgres": true}
 
 var envHelp = map[string]string{
-	"SERVICE_USERNAME":           "The service username as configured in AAS",
-	"SERVICE_PASSWORD":           "The service password as configured in AAS",
-	"LOG_LEVEL":                  "Log level",
-	"LOG_MAX_LENGTH":             "Max length of log statement",
-	"LOG_ENABLE_STDOUT":          "Enable console log",
-	"AAS_BASE_URL":               "AAS Base URL",
-	"KMIP_SERVER_IP":             "IP of KMIP server",
-	"KMIP_SERVER_PORT":           "PORT of KMIP server",
-	"KMIP_HOSTNAME":              "HOSTNAME of KMIP server",
-	"KMIP_USERNAME":              "USERNAME of KMIP server",
-	"KMIP_PASSWORD":              "PASSWORD of KMIP server",
-	"KMIP_CLIENT_CERT_PATH":      "KMIP Client certificate path",
-	"KMIP_CLIENT_KEY_PATH":       "KMIP Client key path",
-	"KMIP_ROOT_CERT_PATH":        "KMIP Root Certificate path",
-	"SKC_CHALLENGE_TYPE":         "SKC challenge type",
-	"SQVS_URL":                   "SQVS URL",
-	"SESSION_EXPIRY_TIME":        "Session Expiry Time",
-	"SERVER_PORT":                "The Port on which Server Listens to",
-	"SERVER_READ_TIMEOUT":        "Request Read Timeout Duration in Seconds",
-	"SERVER_READ_HEADER_TIMEOUT": "Request Read Header Timeout Duration in Seconds",
-	"SERVER_WRITE_TIMEOUT":       "Request Write Timeout Duration in Seconds",
-	"SERVER_IDLE_TIMEOUT":        "Request Idle Timeout in Seconds",
-	"SERVER_MAX_HEADER_BYTES":    "Max Length Of Request Header in Bytes ",
+	"SERVICE_USERNAME":                    "The service username as configured in AAS",
+	"SERVICE_PASSWORD":                    "The service password as configured in AAS",
+	"LOG_LEVEL":                           "Log level",
+	"LOG_MAX_LENGTH":                      "Max length of log statement",
+	"LOG_ENABLE_STDOUT":                   "Enable console log",
+	"AAS_BASE_URL":                        "AAS Base URL",
+	"KMIP_SERVER_IP":                      "IP of KMIP server",
+	"KMIP_SERVER_PORT":                    "PORT of KMIP server",
+	"KMIP_HOSTNAME":                       "HOSTNAME of KMIP server",
+	"KMIP_USERNAME":                       "USERNAME of KMIP server",
+	"KMIP_PASSWORD":                       "PASSWORD of KMIP server",
+	"KMIP_CLIENT_CERT_PATH":               "KMIP Client certificate path",
+	"KMIP_CLIENT_KEY_PATH":                "KMIP Client key path",
+	"KMIP_ROOT_CERT_PATH":                 "KMIP Root Certificate path",
+	"DIRECTORY_MASTER_KEY_PATH":           "Path of the master key wrapping the keys of the directory key manager and the persisted session keys",
+	"PKCS11_MODULE_PATH":                  "Path of the PKCS#11 module library",
+	"PKCS11_TOKEN_LABEL":                  "Label of the PKCS#11 token holding the keys",
+	"PKCS11_USER_PIN":                     "User PIN of the PKCS#11 token",
+	"STORE_TYPE":                          "Store of the keys, key transfer policies and certificates, either directory or postgres",
+	"KEY_ROTATION_CHECK_INTERVAL":         "Interval of the checks for keys due for automatic rotation, e.g. 10m",
+	"REVOCATION_CHECK_ENABLED":            "Checks the revocation status of the TLS client certificates when set to true",
+	"REVOCATION_CHECK_CRL_FILE":           "PEM or DER encoded CRL file used by the revocation check",
+	"REVOCATION_CHECK_FETCH_CRL":          "Downloads the CRLs from the distribution points of the certificates when set to true",
+	"REVOCATION_CHECK_OCSP":               "Queries the OCSP responders of the certificates when set to true",
+	"REVOCATION_CHECK_CRL_CACHE_DURATION": "Maximum duration the downloaded CRLs are cached",
+	"REVOCATION_CHECK_FAIL_OPEN":          "Accepts the certificates whose revocation status can not be determined when set to true",
+	"SKC_CHALLENGE_TYPE":                  "SKC challenge type",
+	"SQVS_URL":                            "SQVS URL",
+	"SESSION_EXPIRY_TIME":                 "Session Expiry Time",
+	"SERVER_PORT":                         "The Port on which Server Listens to",
+	"SERVER_READ_TIMEOUT":                 "Request Read Timeout Duration in Seconds",
+	"SERVER_READ_HEADER_TIMEOUT":          "Request Read Header Timeout Duration in Seconds",
+	"SERVER_WRITE_TIMEOUT":                "Request Write Timeout Duration in Seconds",
+	"SERVER_IDLE_TIMEOUT":                 "Request Idle Timeout in Seconds",
+	"SERVER_MAX_HEADER_BYTES":             "Max Length Of Request Header in Bytes ",
 }
 
 func (uc UpdateServiceConfig) Run() error {
@@ -91,12 +105,32 @@ func (uc UpdateServiceConfig) Run() error {
 		ClientCertificateFilePath: viper.GetString("kmip-client-cert-path"),
 		RootCertificateFilePath:   viper.GetString("kmip-root-cert-path"),
 	}
+	(*uc.AppConfig).Directory = config.DirectoryConfig{
+		MasterKeyFilePath: viper.GetString("directory-master-key-path"),
+	}
+	(*uc.AppConfig).Pkcs11 = config.Pkcs11Config{
+		ModulePath: viper.GetString("pkcs11-module-path"),
+		TokenLabel: viper.GetString("pkcs11-token-label"),
+		UserPin:    viper.GetString("pkcs11-user-pin"),
+	}
 	(*uc.AppConfig).Skc = config.SKCConfig{
 		StmLabel:          viper.GetString("skc-challenge-type"),
 		SQVSUrl:           viper.GetString("sqvs-url"),
 		SessionExpiryTime: viper.GetInt("session-expiry-time"),
 	}
+	(*uc.AppConfig).KeyRotation = config.KeyRotationConfig{
+		CheckInterval: viper.GetDuration("key-rotation-check-interval"),
+	}
+	(*uc.AppConfig).RevocationCheck = commConfig.RevocationCheckConfig{
+		Enabled:          viper.GetBool("revocation-check-enabled"),
+		CrlFile:          viper.GetString("revocation-check-crl-file"),
+		FetchCrl:         viper.GetBool("revocation-check-fetch-crl"),
+		Ocsp:             viper.GetBool("revocation-check-ocsp"),
+		CrlCacheDuration: viper.GetDuration("revocation-check-crl-cache-duration"),
+		FailOpen:         viper.GetBool("revocation-check-fail-open"),
+	}
 	(*uc.AppConfig).KeyManager = viper.GetString("key-manager")
+	(*uc.AppConfig).StoreType = viper.GetString("store-type")
 	return nil
 }
 
@@ -109,7 +143,18 @@ func (uc UpdateServiceConfig) Validate() error {
 		return errors.New("Configured port is not valid")
 	}
 	if _, validInput := allowedKeyManagers[strings.ToLower((*uc.AppConfig).KeyManager)]; !validInput {
-		return errors.New("Invalid value provided for KEY_MANAGER. Value should be kmip")
+		return errors.New("Invalid value provided for KEY_MANAGER. Value should be one of kmip, directory or pkcs11")
+	}
+	if strings.ToLower((*uc.AppConfig).KeyManager) == "pkcs11" {
+		if (*uc.AppConfig).Pkcs11.ModulePath == "" || (*uc.AppConfig).Pkcs11.TokenLabel == "" {
+			return errors.New("PKCS11_MODULE_PATH and PKCS11_TOKEN_LABEL must be set for the pkcs11 key manager")
+		}
+	}
+	if _, validInput := allowedStoreTypes[strings.ToLower((*uc.AppConfig).StoreType)]; !validInput {
+		return errors.New("Invalid value provided for STORE_TYPE. Value should be one of directory or postgres")
+	}
+	if (*uc.AppConfig).KeyRotation.CheckInterval < time.Minute {
+		return errors.New("Invalid value provided for KEY_ROTATION_CHECK_INTERVAL. Value should be at least 1m")
 	}
 	if (*uc.AppConfig).Skc.StmLabel != "" {
 		if _, validInput := allowedSKCChallengeTypes[strings.ToLower((*uc.AppConfig).Skc.StmLabel)]; !validInput {
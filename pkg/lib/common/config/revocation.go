@@ -0,0 +1,20 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package config
+
+import "time"
+
+// RevocationCheckConfig configures the optional revocation check of the certificates validated by a service. The
+// CRLs are read from CrlFile and, when FetchCrl is set, downloaded from the CRL distribution points of the
+// certificates. OCSP is queried when Ocsp is set and no CRL covers the certificate.
+type RevocationCheckConfig struct {
+	Enabled          bool          `yaml:"enabled" mapstructure:"enabled"`
+	CrlFile          string        `yaml:"crl-file" mapstructure:"crl-file"`
+	FetchCrl         bool          `yaml:"fetch-crl" mapstructure:"fetch-crl"`
+	Ocsp             bool          `yaml:"ocsp" mapstructure:"ocsp"`
+	CrlCacheDuration time.Duration `yaml:"crl-cache-duration" mapstructure:"crl-cache-duration"`
+	FailOpen         bool          `yaml:"fail-open" mapstructure:"fail-open"`
+}
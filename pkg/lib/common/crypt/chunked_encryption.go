@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package crypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"io"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// ChunkHeader follows the EncryptionHeader in the files of version V2. The plaintext is split in chunks of ChunkSize
+// bytes, the last one being shorter, and each chunk is sealed with AES GCM into ChunkSize + 16 bytes. The nonce of a
+// chunk is the IV of the EncryptionHeader with the chunk index added to its last 8 bytes, and the additional data of a
+// chunk is both headers followed by a byte set to 1 for the last chunk only, so the chunks can neither be reordered
+// nor truncated.
+type ChunkHeader struct {
+	ChunkSizeInLittleEndian uint32
+}
+
+// GCM parameters and chunk flags of the V2 format
+const (
+	gcmTagSize     = 16
+	gcmNonceSize   = 12
+	lastChunkFlag  = 1
+	otherChunkFlag = 0
+)
+
+// ChunkedEncrypter encrypts the plaintext written to it in the V2 format, it holds at most one chunk of plaintext in
+// memory. Close must be called to write the last chunk.
+type ChunkedEncrypter struct {
+	writer  io.Writer
+	gcm     cipher.AEAD
+	iv      []byte
+	headers []byte
+	chunk   []byte
+	sealed  []byte
+	index   uint64
+	closed  bool
+}
+
+// NewChunkedEncrypter writes the V2 headers with a random IV to the writer and returns an encrypter for the
+// plaintext, chunkSize defaults to DefaultEncryptionChunkSize when not positive
+func NewChunkedEncrypter(writer io.Writer, key []byte, chunkSize int) (*ChunkedEncrypter, error) {
+	if chunkSize <= 0 {
+		chunkSize = DefaultEncryptionChunkSize
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	iv, err := GetRandomBytes(gcmNonceSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating random IV value")
+	}
+
+	var encryptionHeader EncryptionHeader
+	chunkHeader := ChunkHeader{ChunkSizeInLittleEndian: uint32(chunkSize)}
+	copy(encryptionHeader.MagicText[:], EncryptionHeaderMagicText)
+	copy(encryptionHeader.EncryptionAlgorithm[:], GCMEncryptionAlgorithm)
+	copy(encryptionHeader.IV[:], iv)
+	copy(encryptionHeader.Version[:], EncryptionHeaderVersionV2)
+	encryptionHeader.OffsetInLittleEndian = uint32(unsafe.Sizeof(encryptionHeader) + unsafe.Sizeof(chunkHeader))
+
+	headers := &bytes.Buffer{}
+	if err = binary.Write(headers, binary.LittleEndian, encryptionHeader); err != nil {
+		return nil, errors.Wrap(err, "Error while writing encryption header")
+	}
+	if err = binary.Write(headers, binary.LittleEndian, chunkHeader); err != nil {
+		return nil, errors.Wrap(err, "Error while writing chunk header")
+	}
+	if _, err = writer.Write(headers.Bytes()); err != nil {
+		return nil, errors.Wrap(err, "Error while writing encryption headers")
+	}
+
+	return &ChunkedEncrypter{
+		writer:  writer,
+		gcm:     gcm,
+		iv:      iv,
+		headers: headers.Bytes(),
+		chunk:   make([]byte, 0, chunkSize),
+		sealed:  make([]byte, 0, chunkSize+gcmTagSize),
+	}, nil
+}
+
+// Write encrypts the plaintext, the chunks are written out as soon as they are followed by more plaintext
+func (ce *ChunkedEncrypter) Write(plaintext []byte) (int, error) {
+	if ce.closed {
+		return 0, errors.New("Write to closed encrypter")
+	}
+
+	written := 0
+	for len(plaintext) > 0 {
+		if len(ce.chunk) == cap(ce.chunk) {
+			if err := ce.sealChunk(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(ce.chunk[len(ce.chunk):cap(ce.chunk)], plaintext)
+		ce.chunk = ce.chunk[:len(ce.chunk)+n]
+		plaintext = plaintext[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close writes the last chunk, it does not close the underlying writer
+func (ce *ChunkedEncrypter) Close() error {
+	if ce.closed {
+		return nil
+	}
+	ce.closed = true
+	return ce.sealChunk(true)
+}
+
+func (ce *ChunkedEncrypter) sealChunk(last bool) error {
+	ce.sealed = ce.gcm.Seal(ce.sealed[:0], chunkNonce(ce.iv, ce.index), ce.chunk, chunkAdditionalData(ce.headers, last))
+	if _, err := ce.writer.Write(ce.sealed); err != nil {
+		return errors.Wrapf(err, "Error while writing encrypted chunk %d", ce.index)
+	}
+	ce.chunk = ce.chunk[:0]
+	ce.index++
+	return nil
+}
+
+// chunkNonce adds the chunk index to the last 8 bytes of the IV
+func chunkNonce(iv []byte, index uint64) []byte {
+	nonce := make([]byte, len(iv))
+	copy(nonce, iv)
+	counter := binary.BigEndian.Uint64(nonce[len(nonce)-8:])
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], counter+index)
+	return nonce
+}
+
+func chunkAdditionalData(headers []byte, last bool) []byte {
+	additionalData := make([]byte, len(headers)+1)
+	copy(additionalData, headers)
+	additionalData[len(headers)] = otherChunkFlag
+	if last {
+		additionalData[len(headers)] = lastChunkFlag
+	}
+	return additionalData
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error initializing cipher")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating a cipher block")
+	}
+	return gcm, nil
+}
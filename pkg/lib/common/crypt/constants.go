@@ -8,5 +8,8 @@ package crypt
 const (
 	EncryptionHeaderMagicText = "ISecL-VMC"
 	EncryptionHeaderVersion   = "V1"
-	GCMEncryptionAlgorithm    = "GCM-256"
+	// EncryptionHeaderVersionV2 files are encrypted in chunks of ChunkHeader.ChunkSize bytes, each sealed separately
+	EncryptionHeaderVersionV2  = "V2"
+	GCMEncryptionAlgorithm     = "GCM-256"
+	DefaultEncryptionChunkSize = 1024 * 1024
 )
@@ -0,0 +1,246 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package crypt
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/binary"
+	"io"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// EncryptedImage gives random access to the plaintext of an image encrypted in the V1 or V2 format. The V1 format is
+// sealed as a whole, its plaintext is decrypted in memory when the image is opened. The V2 chunks are decrypted on
+// demand, only the last chunk read is kept in memory.
+type EncryptedImage struct {
+	Header      EncryptionHeader
+	ChunkHeader *ChunkHeader
+
+	reader    io.ReaderAt
+	gcm       cipher.AEAD
+	headers   []byte
+	dataSize  int64
+	size      int64
+	plaintext []byte
+
+	mutex      sync.Mutex
+	chunkIndex int64
+	chunk      []byte
+}
+
+// ReadEncryptionHeaders reads the encryption header and, for the V2 format, the chunk header of an encrypted image
+func ReadEncryptionHeaders(reader io.ReaderAt) (*EncryptionHeader, *ChunkHeader, error) {
+	var encryptionHeader EncryptionHeader
+	headerSize := int64(unsafe.Sizeof(encryptionHeader))
+	if err := binary.Read(io.NewSectionReader(reader, 0, headerSize), binary.LittleEndian, &encryptionHeader); err != nil {
+		return nil, nil, errors.Wrap(err, "Error reading encryption header")
+	}
+	if !strings.Contains(string(encryptionHeader.MagicText[:]), EncryptionHeaderMagicText) {
+		return nil, nil, errors.New("Image is not encrypted, encryption header not found")
+	}
+
+	switch encryptionHeader.GetVersion() {
+	case EncryptionHeaderVersion:
+		return &encryptionHeader, nil, nil
+	case EncryptionHeaderVersionV2:
+		var chunkHeader ChunkHeader
+		chunkHeaderSize := int64(unsafe.Sizeof(chunkHeader))
+		if err := binary.Read(io.NewSectionReader(reader, headerSize, chunkHeaderSize), binary.LittleEndian, &chunkHeader); err != nil {
+			return nil, nil, errors.Wrap(err, "Error reading chunk header")
+		}
+		if chunkHeader.ChunkSizeInLittleEndian == 0 || int64(encryptionHeader.OffsetInLittleEndian) != headerSize+chunkHeaderSize {
+			return nil, nil, errors.New("Invalid chunk header")
+		}
+		return &encryptionHeader, &chunkHeader, nil
+	default:
+		return nil, nil, errors.Errorf("Unsupported encryption header version %s", encryptionHeader.GetVersion())
+	}
+}
+
+// GetVersion returns the version of the encryption header without the padding
+func (eh *EncryptionHeader) GetVersion() string {
+	return strings.TrimRight(string(eh.Version[:]), "\x00")
+}
+
+// OpenEncryptedImage detects the format of the encrypted image of the given size and returns its plaintext reader,
+// the V1 images are authenticated when opened and the V2 chunks are authenticated as they are read
+func OpenEncryptedImage(reader io.ReaderAt, size int64, key []byte) (*EncryptedImage, error) {
+	encryptionHeader, chunkHeader, err := ReadEncryptionHeaders(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := int64(encryptionHeader.OffsetInLittleEndian)
+	if size < offset+gcmTagSize {
+		return nil, errors.New("Encrypted image is truncated")
+	}
+
+	headers := make([]byte, offset)
+	if _, err = reader.ReadAt(headers, 0); err != nil {
+		return nil, errors.Wrap(err, "Error reading encryption headers")
+	}
+
+	image := &EncryptedImage{
+		Header:      *encryptionHeader,
+		ChunkHeader: chunkHeader,
+		reader:      reader,
+		gcm:         gcm,
+		headers:     headers,
+		dataSize:    size - offset,
+		chunkIndex:  -1,
+	}
+
+	if chunkHeader == nil {
+		ciphertext := make([]byte, image.dataSize)
+		if _, err = reader.ReadAt(ciphertext, offset); err != nil {
+			return nil, errors.Wrap(err, "Error reading encrypted image")
+		}
+		image.plaintext, err = gcm.Open(nil, encryptionHeader.IV[:], ciphertext, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error decrypting image")
+		}
+		image.size = int64(len(image.plaintext))
+		return image, nil
+	}
+
+	// the last chunk holds at least one byte unless the plaintext is empty
+	sealedChunkSize := int64(chunkHeader.ChunkSizeInLittleEndian) + gcmTagSize
+	chunks := (image.dataSize + sealedChunkSize - 1) / sealedChunkSize
+	lastChunkSize := image.dataSize - (chunks-1)*sealedChunkSize - gcmTagSize
+	if lastChunkSize < 0 || (lastChunkSize == 0 && chunks > 1) {
+		return nil, errors.New("Encrypted image is truncated")
+	}
+	image.size = (chunks-1)*int64(chunkHeader.ChunkSizeInLittleEndian) + lastChunkSize
+
+	// authenticate the last chunk so that a truncated image is detected when it is opened
+	if _, err = image.readChunk(chunks - 1); err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
+// Size returns the size of the plaintext
+func (ei *EncryptedImage) Size() int64 {
+	return ei.size
+}
+
+// ReadAt reads the plaintext at the given offset, it fails if any chunk read was tampered with
+func (ei *EncryptedImage) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("Negative offset")
+	}
+	if off >= ei.size {
+		return 0, io.EOF
+	}
+
+	if ei.ChunkHeader == nil {
+		n := copy(p, ei.plaintext[off:])
+		if n < len(p) {
+			return n, io.EOF
+		}
+		return n, nil
+	}
+
+	ei.mutex.Lock()
+	defer ei.mutex.Unlock()
+
+	chunkSize := int64(ei.ChunkHeader.ChunkSizeInLittleEndian)
+	read := 0
+	for read < len(p) && off < ei.size {
+		chunk, err := ei.readChunk(off / chunkSize)
+		if err != nil {
+			return read, err
+		}
+		n := copy(p[read:], chunk[off%chunkSize:])
+		read += n
+		off += int64(n)
+	}
+	if read < len(p) {
+		return read, io.EOF
+	}
+	return read, nil
+}
+
+// readChunk decrypts the chunk at the given index, the caller must hold the mutex
+func (ei *EncryptedImage) readChunk(index int64) ([]byte, error) {
+	if index == ei.chunkIndex {
+		return ei.chunk, nil
+	}
+
+	sealedChunkSize := int64(ei.ChunkHeader.ChunkSizeInLittleEndian) + gcmTagSize
+	offset := index * sealedChunkSize
+	length := sealedChunkSize
+	if offset+length > ei.dataSize {
+		length = ei.dataSize - offset
+	}
+	last := offset+length == ei.dataSize
+
+	sealed := make([]byte, length)
+	if _, err := ei.reader.ReadAt(sealed, int64(ei.Header.OffsetInLittleEndian)+offset); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "Error reading encrypted chunk %d", index)
+	}
+
+	chunk, err := ei.gcm.Open(ei.chunk[:0], chunkNonce(ei.Header.IV[:], uint64(index)), sealed, chunkAdditionalData(ei.headers, last))
+	if err != nil {
+		ei.chunkIndex = -1
+		return nil, errors.Wrapf(err, "Error decrypting chunk %d", index)
+	}
+	ei.chunk = chunk
+	ei.chunkIndex = index
+	return chunk, nil
+}
+
+// DecryptTo writes the whole plaintext to the writer
+func (ei *EncryptedImage) DecryptTo(writer io.Writer) (int64, error) {
+	return io.Copy(writer, io.NewSectionReader(ei, 0, ei.size))
+}
+
+// EncryptImage encrypts an image in memory in the V1 format, the image is sealed as a whole after the encryption header
+func EncryptImage(image []byte, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := GetRandomBytes(gcmNonceSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating random IV value")
+	}
+
+	var encryptionHeader EncryptionHeader
+	copy(encryptionHeader.MagicText[:], EncryptionHeaderMagicText)
+	copy(encryptionHeader.EncryptionAlgorithm[:], GCMEncryptionAlgorithm)
+	copy(encryptionHeader.IV[:], iv)
+	copy(encryptionHeader.Version[:], EncryptionHeaderVersion)
+	encryptionHeader.OffsetInLittleEndian = uint32(unsafe.Sizeof(encryptionHeader))
+
+	header := &bytes.Buffer{}
+	if err = binary.Write(header, binary.LittleEndian, encryptionHeader); err != nil {
+		return nil, errors.Wrap(err, "Error while writing encryption header struc values in to buffer")
+	}
+	return gcm.Seal(header.Bytes(), iv, image, nil), nil
+}
+
+// DecryptImage decrypts an image encrypted in the V1 or V2 format in memory
+func DecryptImage(encryptedImage []byte, key []byte) ([]byte, error) {
+	image, err := OpenEncryptedImage(bytes.NewReader(encryptedImage), int64(len(encryptedImage)), key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := &bytes.Buffer{}
+	if _, err = image.DecryptTo(plaintext); err != nil {
+		return nil, err
+	}
+	return plaintext.Bytes(), nil
+}
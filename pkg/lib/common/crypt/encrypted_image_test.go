@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package crypt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testChunkSize = 64
+
+func encryptChunked(t *testing.T, plaintext, key []byte) []byte {
+	encrypted := &bytes.Buffer{}
+	encrypter, err := NewChunkedEncrypter(encrypted, key, testChunkSize)
+	assert.NoError(t, err)
+	// write in pieces which do not line up with the chunks
+	for len(plaintext) > 0 {
+		n := 37
+		if n > len(plaintext) {
+			n = len(plaintext)
+		}
+		_, err = encrypter.Write(plaintext[:n])
+		assert.NoError(t, err)
+		plaintext = plaintext[n:]
+	}
+	assert.NoError(t, encrypter.Close())
+	return encrypted.Bytes()
+}
+
+func TestChunkedEncryptionRoundTrip(t *testing.T) {
+	key, _ := GetRandomBytes(32)
+	for _, size := range []int{0, 1, testChunkSize - 1, testChunkSize, testChunkSize + 1, 3 * testChunkSize, 1000} {
+		plaintext, _ := GetRandomBytes(size)
+		encrypted := encryptChunked(t, plaintext, key)
+
+		header, chunkHeader, err := ReadEncryptionHeaders(bytes.NewReader(encrypted))
+		assert.NoError(t, err)
+		assert.Equal(t, EncryptionHeaderVersionV2, header.GetVersion())
+		assert.Equal(t, uint32(testChunkSize), chunkHeader.ChunkSizeInLittleEndian)
+
+		decrypted, err := DecryptImage(encrypted, key)
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(plaintext, decrypted), "size %d", size)
+	}
+}
+
+func TestChunkedEncryptionRandomAccess(t *testing.T) {
+	key, _ := GetRandomBytes(32)
+	plaintext, _ := GetRandomBytes(1000)
+	encrypted := encryptChunked(t, plaintext, key)
+
+	image, err := OpenEncryptedImage(bytes.NewReader(encrypted), int64(len(encrypted)), key)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), image.Size())
+
+	for _, off := range []int64{900, 0, 63, 64, 500, 990} {
+		p := make([]byte, 100)
+		n, err := image.ReadAt(p, off)
+		expected := plaintext[off:]
+		if len(expected) >= 100 {
+			expected = expected[:100]
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, io.EOF, err)
+		}
+		assert.Equal(t, expected, p[:n])
+	}
+
+	_, err = image.ReadAt(make([]byte, 1), 1000)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestChunkedEncryptionTampering(t *testing.T) {
+	key, _ := GetRandomBytes(32)
+	plaintext, _ := GetRandomBytes(3 * testChunkSize)
+	encrypted := encryptChunked(t, plaintext, key)
+	offset := int(unsafe.Sizeof(EncryptionHeader{}) + unsafe.Sizeof(ChunkHeader{}))
+	sealedChunkSize := testChunkSize + gcmTagSize
+
+	// swap the first two chunks
+	reordered := append([]byte{}, encrypted[:offset]...)
+	reordered = append(reordered, encrypted[offset+sealedChunkSize:offset+2*sealedChunkSize]...)
+	reordered = append(reordered, encrypted[offset:offset+sealedChunkSize]...)
+	reordered = append(reordered, encrypted[offset+2*sealedChunkSize:]...)
+	_, err := DecryptImage(reordered, key)
+	assert.Error(t, err)
+
+	// drop the last chunk
+	_, err = DecryptImage(encrypted[:offset+2*sealedChunkSize], key)
+	assert.Error(t, err)
+
+	// cut the last chunk
+	_, err = DecryptImage(encrypted[:len(encrypted)-10], key)
+	assert.Error(t, err)
+
+	// change the chunk size
+	resized := append([]byte{}, encrypted...)
+	binary.LittleEndian.PutUint32(resized[offset-4:offset], testChunkSize/2)
+	_, err = DecryptImage(resized, key)
+	assert.Error(t, err)
+
+	// flip a bit of the second chunk
+	flipped := append([]byte{}, encrypted...)
+	flipped[offset+sealedChunkSize+1] ^= 1
+	_, err = DecryptImage(flipped, key)
+	assert.Error(t, err)
+
+	wrongKey, _ := GetRandomBytes(32)
+	_, err = DecryptImage(encrypted, wrongKey)
+	assert.Error(t, err)
+}
+
+func TestDecryptImageV1(t *testing.T) {
+	key, _ := GetRandomBytes(32)
+	plaintext, _ := GetRandomBytes(1000)
+	encrypted, err := EncryptImage(plaintext, key)
+	assert.NoError(t, err)
+
+	image, err := OpenEncryptedImage(bytes.NewReader(encrypted), int64(len(encrypted)), key)
+	assert.NoError(t, err)
+	assert.Nil(t, image.ChunkHeader)
+	assert.Equal(t, EncryptionHeaderVersion, image.Header.GetVersion())
+
+	p := make([]byte, 10)
+	n, err := image.ReadAt(p, 500)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext[500:510], p[:n])
+
+	decrypted, err := DecryptImage(encrypted, key)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestReadEncryptionHeadersNotEncrypted(t *testing.T) {
+	_, _, err := ReadEncryptionHeaders(bytes.NewReader(make([]byte, 64)))
+	assert.Error(t, err)
+}
@@ -0,0 +1,337 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package crypt
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	commConfig "github.com/intel-secl/intel-secl/v4/pkg/lib/common/config"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	defaultCrlCacheDuration  = time.Hour
+	defaultRevocationTimeout = 10 * time.Second
+	maxRevocationDataBytes   = 10 * 1024 * 1024
+)
+
+// CertificateRevokedError is returned by the RevocationChecker when a certificate of the chain is revoked
+type CertificateRevokedError struct {
+	SerialNumber *big.Int
+	Subject      string
+	RevokedAt    time.Time
+	Reason       int
+}
+
+func (e *CertificateRevokedError) Error() string {
+	return fmt.Sprintf("certificate %s with serial number %s was revoked at %s", e.Subject, e.SerialNumber.Text(16),
+		e.RevokedAt.Format(time.RFC3339))
+}
+
+// IsCertificateRevoked reports whether the error, or the error it wraps, is a CertificateRevokedError
+func IsCertificateRevoked(err error) bool {
+	_, ok := errors.Cause(err).(*CertificateRevokedError)
+	return ok
+}
+
+// RevocationChecker checks the revocation status of certificate chains against CRLs and OCSP responders. A nil
+// RevocationChecker is valid and considers every certificate as not revoked, which keeps the check opt-in.
+type RevocationChecker struct {
+	crlFile       string
+	fetchCrl      bool
+	useOcsp       bool
+	failOpen      bool
+	cacheDuration time.Duration
+	httpClient    *http.Client
+
+	mu   sync.Mutex
+	crls map[string]*cachedCrls
+}
+
+type cachedCrls struct {
+	crls      []*x509.RevocationList
+	expiresAt time.Time
+}
+
+// NewRevocationChecker returns the checker configured by revocationConfig or nil when the check is disabled. The
+// HTTP client is used to download the CRLs and to query the OCSP responders.
+func NewRevocationChecker(revocationConfig commConfig.RevocationCheckConfig, httpClient *http.Client) *RevocationChecker {
+	if !revocationConfig.Enabled {
+		return nil
+	}
+	cacheDuration := revocationConfig.CrlCacheDuration
+	if cacheDuration <= 0 {
+		cacheDuration = defaultCrlCacheDuration
+	}
+	client := http.Client{}
+	if httpClient != nil {
+		client = *httpClient
+	}
+	// the revocation check runs inline with the validation, never let an unresponsive server block it
+	if client.Timeout == 0 {
+		client.Timeout = defaultRevocationTimeout
+	}
+	return &RevocationChecker{
+		crlFile:       revocationConfig.CrlFile,
+		fetchCrl:      revocationConfig.FetchCrl,
+		useOcsp:       revocationConfig.Ocsp,
+		failOpen:      revocationConfig.FailOpen,
+		cacheDuration: cacheDuration,
+		httpClient:    &client,
+		crls:          map[string]*cachedCrls{},
+	}
+}
+
+// CheckChain checks every certificate of a verified chain, ordered from the leaf to the root, against its issuer.
+// The root is trusted as is. A CertificateRevokedError is returned when a certificate is revoked.
+func (rc *RevocationChecker) CheckChain(chain []*x509.Certificate) error {
+	if rc == nil {
+		return nil
+	}
+	for i := 0; i < len(chain)-1; i++ {
+		if err := rc.Check(chain[i], chain[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check checks the revocation status of the certificate issued by issuer. The configured CRL file and the CRLs of
+// the distribution points are checked first, OCSP is queried when none of them covers the certificate. A
+// certificate that advertises no revocation information and that is not covered by the CRL file is considered as
+// not revoked. An error is returned when the status could not be determined, unless the checker fails open.
+func (rc *RevocationChecker) Check(cert, issuer *x509.Certificate) error {
+	if rc == nil {
+		return nil
+	}
+
+	var sources int
+	var lastErr error
+	if rc.crlFile != "" {
+		crls, err := rc.getCrls(rc.crlFile, readCrlFile)
+		if err != nil {
+			sources++
+			lastErr = err
+		} else if revoked, found := findInCrls(crls, cert, issuer); found {
+			return revocationResult(revoked)
+		}
+	}
+
+	if rc.fetchCrl {
+		for _, distributionPoint := range cert.CRLDistributionPoints {
+			if !strings.HasPrefix(distributionPoint, "http://") && !strings.HasPrefix(distributionPoint, "https://") {
+				continue
+			}
+			sources++
+			crls, err := rc.getCrls(distributionPoint, rc.downloadCrl)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if revoked, found := findInCrls(crls, cert, issuer); found {
+				return revocationResult(revoked)
+			}
+			lastErr = errors.Errorf("CRL from %s is not issued by %s", distributionPoint, issuer.Subject.String())
+		}
+	}
+
+	if rc.useOcsp {
+		for _, server := range cert.OCSPServer {
+			sources++
+			revoked, err := rc.queryOcsp(server, cert, issuer)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			return revocationResult(revoked)
+		}
+	}
+
+	if sources == 0 {
+		return nil
+	}
+	err := errors.Wrapf(lastErr, "crypt/revocation:Check() Could not determine revocation status of certificate %s",
+		cert.Subject.String())
+	if rc.failOpen {
+		log.WithError(err).Warn("crypt/revocation:Check() Revocation check failing open")
+		return nil
+	}
+	return err
+}
+
+// revocationResult avoids returning a nil *CertificateRevokedError as a non nil error
+func revocationResult(revoked *CertificateRevokedError) error {
+	if revoked == nil {
+		return nil
+	}
+	return revoked
+}
+
+// findInCrls returns whether one of the CRLs is issued by issuer and, if so, the revocation of the certificate
+func findInCrls(crls []*x509.RevocationList, cert, issuer *x509.Certificate) (*CertificateRevokedError, bool) {
+	for _, crl := range crls {
+		if !bytes.Equal(crl.RawIssuer, issuer.RawSubject) || crl.CheckSignatureFrom(issuer) != nil {
+			continue
+		}
+		for _, entry := range crl.RevokedCertificateEntries {
+			if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return &CertificateRevokedError{
+					SerialNumber: cert.SerialNumber,
+					Subject:      cert.Subject.String(),
+					RevokedAt:    entry.RevocationTime,
+					Reason:       entry.ReasonCode,
+				}, true
+			}
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+// getCrls returns the cached CRLs of the source, they are loaded again once the cache duration elapsed or once the
+// next update of one of them is due
+func (rc *RevocationChecker) getCrls(source string, load func(string) ([]*x509.RevocationList, error)) ([]*x509.RevocationList, error) {
+	rc.mu.Lock()
+	cached, ok := rc.crls[source]
+	rc.mu.Unlock()
+	now := time.Now()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.crls, nil
+	}
+
+	crls, err := load(source)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := now.Add(rc.cacheDuration)
+	for _, crl := range crls {
+		if !crl.NextUpdate.IsZero() && crl.NextUpdate.Before(expiresAt) {
+			expiresAt = crl.NextUpdate
+		}
+	}
+	rc.mu.Lock()
+	rc.crls[source] = &cachedCrls{crls: crls, expiresAt: expiresAt}
+	rc.mu.Unlock()
+	return crls, nil
+}
+
+func (rc *RevocationChecker) downloadCrl(url string) ([]*x509.RevocationList, error) {
+	body, err := rc.httpGet(url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "crypt/revocation:downloadCrl() Failed to download CRL from %s", url)
+	}
+	return parseCrls(body)
+}
+
+func (rc *RevocationChecker) httpGet(url string) ([]byte, error) {
+	resp, err := rc.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		derr := resp.Body.Close()
+		if derr != nil {
+			log.WithError(derr).Error("Error closing response body")
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, maxRevocationDataBytes))
+}
+
+func (rc *RevocationChecker) queryOcsp(server string, cert, issuer *x509.Certificate) (*CertificateRevokedError, error) {
+	request, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "crypt/revocation:queryOcsp() Failed to create OCSP request")
+	}
+	resp, err := rc.httpClient.Post(server, "application/ocsp-request", bytes.NewReader(request))
+	if err != nil {
+		return nil, errors.Wrapf(err, "crypt/revocation:queryOcsp() Failed to query OCSP responder %s", server)
+	}
+	defer func() {
+		derr := resp.Body.Close()
+		if derr != nil {
+			log.WithError(derr).Error("Error closing response body")
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("crypt/revocation:queryOcsp() OCSP responder %s returned status code %d", server, resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, maxRevocationDataBytes))
+	if err != nil {
+		return nil, errors.Wrapf(err, "crypt/revocation:queryOcsp() Failed to read OCSP response from %s", server)
+	}
+
+	response, err := ocsp.ParseResponseForCert(body, cert, issuer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "crypt/revocation:queryOcsp() Invalid OCSP response from %s", server)
+	}
+	if !response.NextUpdate.IsZero() && response.NextUpdate.Before(time.Now()) {
+		return nil, errors.Errorf("crypt/revocation:queryOcsp() Stale OCSP response from %s", server)
+	}
+	switch response.Status {
+	case ocsp.Good:
+		return nil, nil
+	case ocsp.Revoked:
+		return &CertificateRevokedError{
+			SerialNumber: cert.SerialNumber,
+			Subject:      cert.Subject.String(),
+			RevokedAt:    response.RevokedAt,
+			Reason:       response.RevocationReason,
+		}, nil
+	default:
+		return nil, errors.Errorf("crypt/revocation:queryOcsp() OCSP responder %s does not know the certificate", server)
+	}
+}
+
+func readCrlFile(path string) ([]*x509.RevocationList, error) {
+	crlBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "crypt/revocation:readCrlFile() Failed to read CRL file %s", path)
+	}
+	return parseCrls(crlBytes)
+}
+
+// parseCrls parses a DER encoded CRL or a list of PEM encoded CRLs
+func parseCrls(crlBytes []byte) ([]*x509.RevocationList, error) {
+	var crls []*x509.RevocationList
+	rest := crlBytes
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "X509 CRL" {
+			continue
+		}
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "crypt/revocation:parseCrls() Failed to parse CRL")
+		}
+		crls = append(crls, crl)
+	}
+	if len(crls) != 0 {
+		return crls, nil
+	}
+
+	crl, err := x509.ParseRevocationList(crlBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "crypt/revocation:parseCrls() Failed to parse CRL")
+	}
+	return []*x509.RevocationList{crl}, nil
+}
@@ -0,0 +1,186 @@
+package crypt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	commConfig "github.com/intel-secl/intel-secl/v4/pkg/lib/common/config"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ocsp"
+)
+
+type testIssuer struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Acme Revocation Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return &testIssuer{cert: cert, key: key}
+}
+
+func (ti *testIssuer) issue(t *testing.T, serialNumber int64, crlDistributionPoints, ocspServers []string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serialNumber),
+		Subject:               pkix.Name{CommonName: "Acme Revocation Test Leaf"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		CRLDistributionPoints: crlDistributionPoints,
+		OCSPServer:            ocspServers,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ti.cert, key.Public(), ti.key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return cert
+}
+
+func (ti *testIssuer) crl(t *testing.T, revokedSerialNumbers ...int64) []byte {
+	var entries []x509.RevocationListEntry
+	for _, serialNumber := range revokedSerialNumbers {
+		entries = append(entries, x509.RevocationListEntry{
+			SerialNumber:   big.NewInt(serialNumber),
+			RevocationTime: time.Now().Add(-time.Minute),
+			ReasonCode:     ocsp.KeyCompromise,
+		})
+	}
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		RevokedCertificateEntries: entries,
+		Number:                    big.NewInt(1),
+		ThisUpdate:                time.Now().Add(-time.Minute),
+		NextUpdate:                time.Now().Add(time.Hour),
+	}, ti.cert, ti.key)
+	assert.NoError(t, err)
+	return crl
+}
+
+func TestRevocationCheckerDisabled(t *testing.T) {
+	checker := NewRevocationChecker(commConfig.RevocationCheckConfig{}, nil)
+	assert.Nil(t, checker)
+
+	issuer := newTestIssuer(t)
+	leaf := issuer.issue(t, 10, nil, nil)
+	assert.NoError(t, checker.CheckChain([]*x509.Certificate{leaf, issuer.cert}))
+}
+
+func TestRevocationCheckerCrlFile(t *testing.T) {
+	issuer := newTestIssuer(t)
+	revoked := issuer.issue(t, 10, nil, nil)
+	good := issuer.issue(t, 11, nil, nil)
+
+	dir, err := ioutil.TempDir("", "revocation")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	crlFile := filepath.Join(dir, "ca.crl")
+	crlPem := pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: issuer.crl(t, 10)})
+	assert.NoError(t, ioutil.WriteFile(crlFile, crlPem, 0600))
+
+	checker := NewRevocationChecker(commConfig.RevocationCheckConfig{Enabled: true, CrlFile: crlFile}, nil)
+	err = checker.CheckChain([]*x509.Certificate{revoked, issuer.cert})
+	assert.Error(t, err)
+	assert.True(t, IsCertificateRevoked(err))
+	assert.Equal(t, ocsp.KeyCompromise, err.(*CertificateRevokedError).Reason)
+	assert.NoError(t, checker.CheckChain([]*x509.Certificate{good, issuer.cert}))
+
+	// a CRL that does not cover the issuer does not decide on the revocation status
+	otherIssuer := newTestIssuer(t)
+	assert.NoError(t, checker.Check(otherIssuer.issue(t, 10, nil, nil), otherIssuer.cert))
+}
+
+func TestRevocationCheckerDistributionPoint(t *testing.T) {
+	issuer := newTestIssuer(t)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write(issuer.crl(t, 10))
+	}))
+	defer server.Close()
+
+	revoked := issuer.issue(t, 10, []string{server.URL + "/crl"}, nil)
+	good := issuer.issue(t, 11, []string{server.URL + "/crl"}, nil)
+
+	checker := NewRevocationChecker(commConfig.RevocationCheckConfig{Enabled: true, FetchCrl: true}, server.Client())
+	assert.True(t, IsCertificateRevoked(checker.Check(revoked, issuer.cert)))
+	assert.NoError(t, checker.Check(good, issuer.cert))
+	// the CRL is cached until its next update
+	assert.Equal(t, 1, requests)
+}
+
+func TestRevocationCheckerOcsp(t *testing.T) {
+	issuer := newTestIssuer(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		request, err := ocsp.ParseRequest(body)
+		assert.NoError(t, err)
+		template := ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: request.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+		}
+		if request.SerialNumber.Int64() == 10 {
+			template.Status = ocsp.Revoked
+			template.RevokedAt = time.Now().Add(-time.Minute)
+			template.RevocationReason = ocsp.Superseded
+		}
+		response, err := ocsp.CreateResponse(issuer.cert, issuer.cert, template, issuer.key)
+		assert.NoError(t, err)
+		_, _ = w.Write(response)
+	}))
+	defer server.Close()
+
+	revoked := issuer.issue(t, 10, nil, []string{server.URL})
+	good := issuer.issue(t, 11, nil, []string{server.URL})
+
+	checker := NewRevocationChecker(commConfig.RevocationCheckConfig{Enabled: true, Ocsp: true}, server.Client())
+	err := checker.Check(revoked, issuer.cert)
+	assert.True(t, IsCertificateRevoked(err))
+	assert.Equal(t, ocsp.Superseded, err.(*CertificateRevokedError).Reason)
+	assert.NoError(t, checker.Check(good, issuer.cert))
+}
+
+func TestRevocationCheckerUnavailable(t *testing.T) {
+	issuer := newTestIssuer(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	leaf := issuer.issue(t, 10, []string{server.URL + "/crl"}, []string{server.URL})
+
+	checker := NewRevocationChecker(commConfig.RevocationCheckConfig{Enabled: true, FetchCrl: true, Ocsp: true}, server.Client())
+	err := checker.Check(leaf, issuer.cert)
+	assert.Error(t, err)
+	assert.False(t, IsCertificateRevoked(err))
+
+	checker = NewRevocationChecker(commConfig.RevocationCheckConfig{Enabled: true, FetchCrl: true, Ocsp: true, FailOpen: true}, server.Client())
+	assert.NoError(t, checker.Check(leaf, issuer.cert))
+}
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	jwt "github.com/Waterdrips/jwt-go"
+	"github.com/google/uuid"
 )
 
 const (
@@ -112,6 +113,28 @@ func (t *Token) GetSubject() string {
 	return t.standardClaims.Subject
 }
 
+// GetId returns the unique identifier (jti) of the token
+func (t *Token) GetId() string {
+	if t.standardClaims == nil {
+		return ""
+	}
+	return t.standardClaims.Id
+}
+
+// GetIssuedAt returns the time the token was issued at
+func (t *Token) GetIssuedAt() time.Time {
+	if t.standardClaims == nil {
+		return time.Time{}
+	}
+	return time.Unix(t.standardClaims.IssuedAt, 0)
+}
+
+// IssuedAtCutoff returns the issued at time below which the tokens were created before issuedBefore, taking into
+// account the clock skew applied by the factory
+func IssuedAtCutoff(issuedBefore time.Time) time.Time {
+	return issuedBefore.Add(-1 * gracePeriodForClockSkew)
+}
+
 type verifierKey struct {
 	pubKey  crypto.PublicKey
 	expTime time.Time
@@ -226,6 +249,8 @@ func (f *JwtFactory) Create(clms interface{}, subject string, validity time.Dura
 	jwtclaim.StandardClaims.ExpiresAt = now.Add(validity).Unix()
 	jwtclaim.StandardClaims.Issuer = f.issuer
 	jwtclaim.StandardClaims.Subject = subject
+	// the token id allows to revoke the token
+	jwtclaim.StandardClaims.Id = uuid.New().String()
 
 	jwtclaim.customClaims = clms
 	token := jwt.NewWithClaims(f.signingMethod, jwtclaim)
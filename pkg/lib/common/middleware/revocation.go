@@ -0,0 +1,97 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	jwtauth "github.com/intel-secl/intel-secl/v4/pkg/lib/common/jwt"
+	ct "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
+)
+
+// DefaultRevocationListCacheTime is how long the token revocation list is used before it is retrieved again
+const DefaultRevocationListCacheTime = time.Minute
+
+// DefaultRevocationListRetrieveTimeout bounds the retrieval of the token revocation list from AAS
+const DefaultRevocationListRetrieveTimeout = 10 * time.Second
+
+// RetrieveTokenRevocationListFn returns the list of the revoked tokens, usually from AAS
+type RetrieveTokenRevocationListFn func() (*ct.TokenRevocationList, error)
+
+// revocationList caches the token revocation list. The list is retrieved outside of the lock by a single refresh at a
+// time and the last retrieved list is used while the refresh is in progress, so that a slow or unavailable AAS does
+// not hold up the requests. When the list cannot be retrieved, the last retrieved list is used until the next attempt.
+type revocationList struct {
+	retrieve  RetrieveTokenRevocationListFn
+	cacheTime time.Duration
+
+	mu        sync.Mutex
+	jtis      map[string]bool
+	subjects  map[string]time.Time
+	retrieved bool
+	expiresAt time.Time
+	// refreshing is closed when the refresh in progress is done, it is nil when no refresh is in progress
+	refreshing chan struct{}
+}
+
+func newRevocationList(fnGetRevocationList RetrieveTokenRevocationListFn, cacheTime time.Duration) *revocationList {
+	return &revocationList{retrieve: fnGetRevocationList, cacheTime: cacheTime}
+}
+
+// isRevoked returns true if the jti of the token is revoked or if the token was issued to a revoked subject before
+// the subject was revoked. Only the requests received before a list has ever been retrieved wait for the refresh.
+func (rl *revocationList) isRevoked(token *jwtauth.Token) bool {
+	rl.mu.Lock()
+	if rl.refreshing == nil && time.Now().After(rl.expiresAt) {
+		rl.refreshing = make(chan struct{})
+		go rl.refresh(rl.refreshing)
+	}
+	refreshing, retrieved := rl.refreshing, rl.retrieved
+	rl.mu.Unlock()
+
+	if !retrieved && refreshing != nil {
+		<-refreshing
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.jtis[token.GetId()] {
+		return true
+	}
+	issuedBefore, ok := rl.subjects[token.GetSubject()]
+	// the issued at time has a resolution of seconds, a token issued in the second of the revocation is revoked
+	return ok && token.GetIssuedAt().Unix() <= issuedBefore.Unix()
+}
+
+// refresh retrieves the list without holding the lock and closes done once the retrieved list is in use
+func (rl *revocationList) refresh(done chan struct{}) {
+	list, err := rl.retrieve()
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	defer close(done)
+
+	rl.refreshing = nil
+	rl.expiresAt = time.Now().Add(rl.cacheTime)
+	if err != nil || list == nil {
+		log.WithError(err).Error("failed to retrieve token revocation list, using the last retrieved list")
+		return
+	}
+
+	jtis := make(map[string]bool, len(list.Jtis))
+	for _, jti := range list.Jtis {
+		if jti != "" {
+			jtis[jti] = true
+		}
+	}
+	subjects := make(map[string]time.Time, len(list.Subjects))
+	for _, subject := range list.Subjects {
+		if latest, ok := subjects[subject.Subject]; !ok || subject.IssuedBefore.After(latest) {
+			subjects[subject.Subject] = subject.IssuedBefore
+		}
+	}
+	rl.jtis, rl.subjects, rl.retrieved = jtis, subjects, true
+}
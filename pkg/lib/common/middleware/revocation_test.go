@@ -0,0 +1,176 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package middleware
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
+	jwtauth "github.com/intel-secl/intel-secl/v4/pkg/lib/common/jwt"
+	ct "github.com/intel-secl/intel-secl/v4/pkg/model/aas"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSubject = "admin"
+
+// newTestToken sets up the jwt verifier of the middleware and returns a token issued to the test subject along with
+// its jti
+func newTestToken(t *testing.T) (string, string) {
+	certDer, pkcs8Der, err := crypt.CreateKeyPairAndCertificate("AAS JWT Signing", "", "ecdsa", 0)
+	assert.NoError(t, err)
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer})
+
+	factory, err := jwtauth.NewTokenFactory(pkcs8Der, true, certPem, "AAS JWT Issuer", time.Hour)
+	assert.NoError(t, err)
+	jwtVerifier, err = jwtauth.NewVerifier(certPem, nil, time.Hour)
+	assert.NoError(t, err)
+
+	tokenString, err := factory.Create(&ct.AuthClaims{}, testSubject, 0)
+	assert.NoError(t, err)
+	token, err := jwtVerifier.ValidateTokenAndGetClaims(tokenString, &ct.AuthClaims{})
+	assert.NoError(t, err)
+	return tokenString, token.GetId()
+}
+
+func serveWithToken(handler http.Handler, token string) int {
+	req := httptest.NewRequest(http.MethodGet, "/hosts", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr.Code
+}
+
+func newTestTokenAuth(retrieve RetrieveTokenRevocationListFn, cacheTime time.Duration) http.Handler {
+	fnGetJwtCerts := func() error { return nil }
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return NewTokenAuthWithRevocationList("", "", fnGetJwtCerts, time.Hour, retrieve, cacheTime)(next)
+}
+
+func TestTokenAuthRevocationList(t *testing.T) {
+	token, jti := newTestToken(t)
+
+	tests := []struct {
+		name           string
+		revocationList *ct.TokenRevocationList
+		retrieveErr    error
+		expectedStatus int
+	}{
+		{
+			name:           "not revoked",
+			revocationList: &ct.TokenRevocationList{Jtis: []string{"9a1f3e2c-57b6-4c0f-9f7e-3d0f1c2b4a5e"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "revoked jti",
+			revocationList: &ct.TokenRevocationList{Jtis: []string{jti}},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "subject revoked after the token was issued",
+			revocationList: &ct.TokenRevocationList{Subjects: []ct.SubjectRevocation{
+				{Subject: testSubject, IssuedBefore: jwtauth.IssuedAtCutoff(time.Now())},
+			}},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "subject revoked before the token was issued",
+			revocationList: &ct.TokenRevocationList{Subjects: []ct.SubjectRevocation{
+				{Subject: testSubject, IssuedBefore: time.Now().Add(-time.Hour)},
+			}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "other subject revoked",
+			revocationList: &ct.TokenRevocationList{Subjects: []ct.SubjectRevocation{
+				{Subject: "operator", IssuedBefore: time.Now().Add(time.Minute)},
+			}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "revocation list cannot be retrieved",
+			retrieveErr:    errors.New("AAS unavailable"),
+			expectedStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestTokenAuth(func() (*ct.TokenRevocationList, error) {
+				return tt.revocationList, tt.retrieveErr
+			}, time.Minute)
+			assert.Equal(t, tt.expectedStatus, serveWithToken(handler, token))
+		})
+	}
+}
+
+func TestTokenAuthRevocationListRefreshFailure(t *testing.T) {
+	token, jti := newTestToken(t)
+
+	retrieved := make(chan struct{}, 1)
+	retrieveErr := error(nil)
+	handler := newTestTokenAuth(func() (*ct.TokenRevocationList, error) {
+		defer func() {
+			select {
+			case retrieved <- struct{}{}:
+			default:
+			}
+		}()
+		if retrieveErr != nil {
+			return nil, retrieveErr
+		}
+		return &ct.TokenRevocationList{Jtis: []string{jti}}, nil
+	}, time.Nanosecond)
+
+	assert.Equal(t, http.StatusUnauthorized, serveWithToken(handler, token))
+	<-retrieved
+
+	// the last retrieved list is used when the refresh fails
+	retrieveErr = errors.New("AAS unavailable")
+	assert.Equal(t, http.StatusUnauthorized, serveWithToken(handler, token))
+	<-retrieved
+	assert.Equal(t, http.StatusUnauthorized, serveWithToken(handler, token))
+}
+
+func TestTokenAuthRevocationListRefreshDoesNotBlock(t *testing.T) {
+	token, jti := newTestToken(t)
+
+	release := make(chan struct{})
+	calls := 0
+	handler := newTestTokenAuth(func() (*ct.TokenRevocationList, error) {
+		calls++
+		if calls == 1 {
+			return &ct.TokenRevocationList{}, nil
+		}
+		// AAS hangs until released, then revokes the token
+		<-release
+		return &ct.TokenRevocationList{Jtis: []string{jti}}, nil
+	}, time.Nanosecond)
+
+	assert.Equal(t, http.StatusOK, serveWithToken(handler, token))
+
+	// the requests are served with the last retrieved list while the refresh hangs
+	done := make(chan int)
+	go func() {
+		done <- serveWithToken(handler, token)
+	}()
+	select {
+	case status := <-done:
+		assert.Equal(t, http.StatusOK, status)
+	case <-time.After(5 * time.Second):
+		t.Fatal("request blocked by the refresh of the revocation list")
+	}
+	assert.Equal(t, http.StatusOK, serveWithToken(handler, token))
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		return serveWithToken(handler, token) == http.StatusUnauthorized
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -51,6 +51,18 @@ func retrieveAndSaveTrustedJwtSigningCerts() error {
 type RetriveJwtCertFn func() error
 
 func NewTokenAuth(signingCertsDir, trustedCAsDir string, fnGetJwtCerts RetriveJwtCertFn, cacheTime time.Duration) mux.MiddlewareFunc {
+	return NewTokenAuthWithRevocationList(signingCertsDir, trustedCAsDir, fnGetJwtCerts, cacheTime, nil, 0)
+}
+
+// NewTokenAuthWithRevocationList returns the token authentication middleware rejecting the revoked tokens. The token
+// revocation list is retrieved with fnGetRevocationList and cached for revocationCacheTime, revocations are not
+// checked when fnGetRevocationList is nil.
+func NewTokenAuthWithRevocationList(signingCertsDir, trustedCAsDir string, fnGetJwtCerts RetriveJwtCertFn, cacheTime time.Duration,
+	fnGetRevocationList RetrieveTokenRevocationListFn, revocationCacheTime time.Duration) mux.MiddlewareFunc {
+	var revokedTokens *revocationList
+	if fnGetRevocationList != nil {
+		revokedTokens = newRevocationList(fnGetRevocationList, revocationCacheTime)
+	}
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -113,6 +125,13 @@ func NewTokenAuth(signingCertsDir, trustedCAsDir string, fnGetJwtCerts RetriveJw
 				return
 			}
 
+			if revokedTokens != nil && revokedTokens.isRevoked(token) {
+				log.Error("token has been revoked")
+				w.WriteHeader(http.StatusUnauthorized)
+				slog.Warningf("%s: Revoked token of %s, requested from %s: ", commLogMsg.AuthenticationFailed, token.GetSubject(), r.RemoteAddr)
+				return
+			}
+
 			r = context.SetUserRoles(r, claims.Roles)
 			r = context.SetUserPermissions(r, claims.Permissions)
 			r = context.SetTokenSubject(r, token.GetSubject())
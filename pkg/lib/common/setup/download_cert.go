@@ -19,6 +19,7 @@ import (
 	"os"
 	"strings"
 
+	commConfig "github.com/intel-secl/intel-secl/v4/pkg/lib/common/config"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	cos "github.com/intel-secl/intel-secl/v4/pkg/lib/common/os"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
@@ -38,6 +39,11 @@ type DownloadCert struct {
 	CmsBaseURL  string
 	BearerToken string
 
+	// RevocationCheck configures the check of the revocation status of the downloaded certificate chain. The
+	// checker is created when the task runs, the CRLs are published by CMS and its CA is downloaded by an earlier
+	// setup task.
+	RevocationCheck commConfig.RevocationCheckConfig
+
 	ConsoleWriter io.Writer
 
 	envPrefix   string
@@ -93,6 +99,11 @@ func (dc *DownloadCert) Run() error {
 		printToWriter(dc.ConsoleWriter, dc.commandName, "Failed to download certificate")
 		return err
 	}
+	err = checkCertificateRevocation(cert, dc.CaCertDirPath, dc.RevocationCheck)
+	if err != nil {
+		printToWriter(dc.ConsoleWriter, dc.commandName, "Failed to check the revocation status of the certificate")
+		return err
+	}
 	err = crypt.SavePrivateKeyAsPKCS8(key, dc.KeyFile)
 	if err != nil {
 		return errors.Wrap(err, "crypt.SavePrivateKeyAsPKCS8 failed")
@@ -174,9 +185,37 @@ func getCertificateFromCMS(certType string, keyAlg string, keyLen int, cmsBaseUr
 	req.Header.Set("Content-Type", "application/x-pem-file")
 	req.Header.Set("Authorization", "Bearer "+bearerToken)
 
+	client, err := newCMSClient(CaCertDirPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Failed to perform HTTP request to CMS")
+	}
+	defer func() {
+		derr := resp.Body.Close()
+		if derr != nil {
+			log.WithError(derr).Error("Error closing response")
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		text, _ := ioutil.ReadAll(resp.Body)
+		reqErr := fmt.Errorf("Status %d: %s", resp.StatusCode, string(text))
+		return nil, nil, errors.Wrap(reqErr, "CMS request failed to download Certificate")
+	}
+	cert, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Failed to read CMS response body")
+	}
+	return
+}
+
+// newCMSClient returns an HTTP client trusting the CA certificates of the directory along with the system ones
+func newCMSClient(CaCertDirPath string) (*http.Client, error) {
 	rootCaCertPems, err := cos.GetDirFileContents(CaCertDirPath, "*.pem")
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "cos.GetDirFileContents failed")
+		return nil, errors.Wrap(err, "cos.GetDirFileContents failed")
 	}
 
 	rootCAs, _ := x509.SystemCertPool()
@@ -185,11 +224,11 @@ func getCertificateFromCMS(certType string, keyAlg string, keyLen int, cmsBaseUr
 	}
 	for _, rootCACert := range rootCaCertPems {
 		if ok := rootCAs.AppendCertsFromPEM(rootCACert); !ok {
-			return nil, nil, errors.New("AppendCertsFromPEM failed on cert pool")
+			return nil, errors.New("AppendCertsFromPEM failed on cert pool")
 		}
 	}
 
-	client := &http.Client{
+	return &http.Client{
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{
 				MinVersion:         tls.VersionTLS13,
@@ -197,25 +236,44 @@ func getCertificateFromCMS(certType string, keyAlg string, keyLen int, cmsBaseUr
 				RootCAs:            rootCAs,
 			},
 		},
+	}, nil
+}
+
+// checkCertificateRevocation verifies the downloaded certificate chain against the CA certificates of the directory
+// and checks that none of its certificates is revoked. Nothing is checked when the revocation check is disabled.
+func checkCertificateRevocation(certPem []byte, CaCertDirPath string, revocationCheck commConfig.RevocationCheckConfig) error {
+	if !revocationCheck.Enabled {
+		return nil
 	}
-	resp, err := client.Do(req)
+	certs, err := crypt.GetSubjectCertsMapFromPem(certPem)
+	if err != nil || len(certs) == 0 {
+		return errors.New("Failed to parse the downloaded certificate")
+	}
+	caCerts, err := crypt.GetCertsFromDir(CaCertDirPath)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "Failed to perform HTTP request to CMS")
+		return errors.Wrapf(err, "Failed to read the CA certificates from %s", CaCertDirPath)
 	}
-	defer func() {
-		derr := resp.Body.Close()
-		if derr != nil {
-			log.WithError(derr).Error("Error closing response")
-		}
-	}()
-	if resp.StatusCode != http.StatusOK {
-		text, _ := ioutil.ReadAll(resp.Body)
-		reqErr := fmt.Errorf("Status %d: %s", resp.StatusCode, string(text))
-		return nil, nil, errors.Wrap(reqErr, "CMS request failed to download Certificate")
+	intermediates := x509.NewCertPool()
+	for i := 1; i < len(certs); i++ {
+		intermediates.AddCert(&certs[i])
 	}
-	cert, err = ioutil.ReadAll(resp.Body)
+	chains, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         crypt.GetCertPool(caCerts),
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "Failed to read CMS response body")
+		return errors.Wrap(err, "Failed to verify the downloaded certificate against the trusted CAs")
 	}
-	return
+
+	// the CRLs and the OCSP responder are served by CMS
+	client, err := newCMSClient(CaCertDirPath)
+	if err != nil {
+		return err
+	}
+	checker := crypt.NewRevocationChecker(revocationCheck, client)
+	if err = checker.CheckChain(chains[0]); err != nil {
+		return errors.Wrap(err, "Failed to check the revocation status of the downloaded certificate")
+	}
+	return nil
 }
@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package setup
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	commConfig "github.com/intel-secl/intel-secl/v4/pkg/lib/common/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestCertificate returns a certificate signed by the given issuer, or a self signed one when there is no issuer
+func newTestCertificate(t *testing.T, serialNumber int64, isCA bool, issuer *x509.Certificate, issuerKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serialNumber),
+		Subject:               pkix.Name{CommonName: "Download Cert Test " + big.NewInt(serialNumber).String()},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+	}
+	if issuer == nil {
+		issuer, issuerKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, issuerKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return cert, key
+}
+
+func TestCheckCertificateRevocation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "download-cert")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	caCertDir := filepath.Join(dir, "trustedca")
+	assert.NoError(t, os.Mkdir(caCertDir, 0700))
+
+	ca, caKey := newTestCertificate(t, 1, true, nil, nil)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(caCertDir, "ca.pem"),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}), 0600))
+	revoked, _ := newTestCertificate(t, 10, false, ca, caKey)
+	good, _ := newTestCertificate(t, 11, false, ca, caKey)
+	untrusted, _ := newTestCertificate(t, 12, false, nil, nil)
+
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		RevokedCertificateEntries: []x509.RevocationListEntry{{SerialNumber: revoked.SerialNumber, RevocationTime: time.Now()}},
+		Number:                    big.NewInt(1),
+		ThisUpdate:                time.Now().Add(-time.Minute),
+		NextUpdate:                time.Now().Add(time.Hour),
+	}, ca, caKey)
+	assert.NoError(t, err)
+	crlFile := filepath.Join(dir, "ca.crl")
+	assert.NoError(t, ioutil.WriteFile(crlFile, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl}), 0600))
+	enabled := commConfig.RevocationCheckConfig{Enabled: true, CrlFile: crlFile}
+
+	tests := []struct {
+		name            string
+		cert            *x509.Certificate
+		revocationCheck commConfig.RevocationCheckConfig
+		wantErr         bool
+	}{
+		{name: "revocation check disabled", cert: revoked, revocationCheck: commConfig.RevocationCheckConfig{}},
+		{name: "certificate not revoked", cert: good, revocationCheck: enabled},
+		{name: "certificate revoked", cert: revoked, revocationCheck: enabled, wantErr: true},
+		{name: "certificate not issued by a trusted CA", cert: untrusted, revocationCheck: enabled, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tt.cert.Raw})
+			err := checkCertificateRevocation(certPem, caCertDir, tt.revocationCheck)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
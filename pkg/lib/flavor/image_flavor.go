@@ -167,3 +167,45 @@ func GetSignedImageFlavor(flavorString string, rsaPrivateKeyLocation string) (st
 
 	return string(signedFlavorJSON), nil
 }
+
+//VerifySignedImageFlavor checks the signature of a signed image flavor with the flavor signing certificate and
+//returns the signed image flavor
+func VerifySignedImageFlavor(signedFlavorString string, signingCert *x509.Certificate) (*SignedImageFlavor, error) {
+	log.Trace("flavor/image_flavor:VerifySignedImageFlavor() Entering")
+	defer log.Trace("flavor/image_flavor:VerifySignedImageFlavor() Leaving")
+
+	var signedFlavor SignedImageFlavor
+	if err := json.Unmarshal([]byte(signedFlavorString), &signedFlavor); err != nil {
+		return nil, errors.Wrap(err, "Error while unmarshalling signed image flavor")
+	}
+	// the flavor is kept as it was written so that the signature is verified over the bytes which were signed
+	var rawSignedFlavor struct {
+		ImageFlavor json.RawMessage `json:"flavor"`
+	}
+	if err := json.Unmarshal([]byte(signedFlavorString), &rawSignedFlavor); err != nil {
+		return nil, errors.Wrap(err, "Error while unmarshalling signed image flavor")
+	}
+
+	publicKey, ok := signingCert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("Flavor signing certificate does not have an RSA public key")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(signedFlavor.Signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error decoding image flavor signature")
+	}
+
+	// the signature is computed over the image flavor marshalled by GetImageFlavor, which wraps the flavor in the
+	// same "flavor" member as the signed image flavor
+	flavorJSON := make([]byte, 0, len(rawSignedFlavor.ImageFlavor)+len(`{"flavor":}`))
+	flavorJSON = append(flavorJSON, `{"flavor":`...)
+	flavorJSON = append(flavorJSON, rawSignedFlavor.ImageFlavor...)
+	flavorJSON = append(flavorJSON, '}')
+	hash := sha512.Sum384(flavorJSON)
+	if err = rsa.VerifyPKCS1v15(publicKey, crypto.SHA384, hash[:], signature); err != nil {
+		return nil, errors.Wrap(err, "Image flavor signature verification failed")
+	}
+
+	return &signedFlavor, nil
+}
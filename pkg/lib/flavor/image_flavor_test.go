@@ -5,8 +5,17 @@
 package flavor
 
 import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -27,3 +36,67 @@ func TestImageFlavorWithoutEncryption(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, flavor)
 }
+
+func TestVerifySignedImageFlavor(t *testing.T) {
+	certDer, keyDer, err := crypt.CreateKeyPairAndCertificate("WPM Flavor Signing Certificate", "", "rsa", 3072)
+	assert.NoError(t, err)
+	signingCert, err := x509.ParseCertificate(certDer)
+	assert.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "flavor-signing.key")
+	assert.NoError(t, crypt.SavePrivateKeyAsPKCS8(keyDer, keyFile))
+
+	flavorInput, err := GetImageFlavor("Cirros-Enc-Label", true,
+		"http://kbs.server.com:20080/v1/keys/73755fda-c910-46be-821f-e8ddeab189e9/transfer",
+		"261209df1789073192285e4e408addadb35068421ef4890a5d4d434")
+	assert.NoError(t, err)
+	flavor, err := json.Marshal(flavorInput)
+	assert.NoError(t, err)
+	signedFlavor, err := GetSignedImageFlavor(string(flavor), keyFile)
+	assert.NoError(t, err)
+
+	verifiedFlavor, err := VerifySignedImageFlavor(signedFlavor, signingCert)
+	assert.NoError(t, err)
+	assert.Equal(t, flavorInput.Image.Encryption.Digest, verifiedFlavor.ImageFlavor.Encryption.Digest)
+
+	tamperedFlavor := strings.Replace(signedFlavor, "261209df", "361209df", 1)
+	_, err = VerifySignedImageFlavor(tamperedFlavor, signingCert)
+	assert.Error(t, err)
+
+	otherCertDer, _, err := crypt.CreateKeyPairAndCertificate("Other", "", "rsa", 3072)
+	assert.NoError(t, err)
+	otherCert, err := x509.ParseCertificate(otherCertDer)
+	assert.NoError(t, err)
+	_, err = VerifySignedImageFlavor(signedFlavor, otherCert)
+	assert.Error(t, err)
+}
+
+func TestVerifySignedImageFlavorOriginalBytes(t *testing.T) {
+	certDer, keyDer, err := crypt.CreateKeyPairAndCertificate("WPM Flavor Signing Certificate", "", "rsa", 3072)
+	assert.NoError(t, err)
+	signingCert, err := x509.ParseCertificate(certDer)
+	assert.NoError(t, err)
+	key, err := x509.ParsePKCS8PrivateKey(keyDer)
+	assert.NoError(t, err)
+
+	// a flavor written by a newer WPM, with a member which is lost when the flavor is marshalled again
+	flavorInput, err := GetImageFlavor("Cirros-Enc-Label", true,
+		"http://kbs.server.com:20080/v1/keys/73755fda-c910-46be-821f-e8ddeab189e9/transfer",
+		"261209df1789073192285e4e408addadb35068421ef4890a5d4d434")
+	assert.NoError(t, err)
+	imageJSON, err := json.Marshal(flavorInput.Image)
+	assert.NoError(t, err)
+	imageJSON = append(imageJSON[:len(imageJSON)-1], []byte(`,"future_member":"value"}`)...)
+
+	hash := sha512.Sum384([]byte(`{"flavor":` + string(imageJSON) + `}`))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA384, hash[:])
+	assert.NoError(t, err)
+	signedFlavor := `{"flavor":` + string(imageJSON) + `,"signature":"` + base64.StdEncoding.EncodeToString(signature) + `"}`
+
+	verifiedFlavor, err := VerifySignedImageFlavor(signedFlavor, signingCert)
+	assert.NoError(t, err)
+	assert.Equal(t, flavorInput.Image.Meta.ID, verifiedFlavor.ImageFlavor.Meta.ID)
+
+	tamperedFlavor := strings.Replace(signedFlavor, `"future_member":"value"`, `"future_member":"other"`, 1)
+	_, err = VerifySignedImageFlavor(tamperedFlavor, signingCert)
+	assert.Error(t, err)
+}
@@ -45,6 +45,12 @@ func NewFlavor(meta *Meta, bios *Bios, hardware *Hardware, pcrs []types.FlavorPc
 	}
 }
 
+// Digest returns the SHA384 hash the flavor signature is computed over, it does not depend on the flavor id
+// so that flavors with the same content have the same digest.
+func (flavor *Flavor) Digest() ([]byte, error) {
+	return flavor.getFlavorDigest()
+}
+
 // GetFlavorDigest Calculates the SHA384 hash of the Flavor's json data for use when
 // signing/verifying signed flavors.
 func (flavor *Flavor) getFlavorDigest() ([]byte, error) {
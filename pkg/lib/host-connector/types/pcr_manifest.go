@@ -360,6 +360,63 @@ func (eventLogEntry *TpmEventLog) Subtract(eventsToSubtract *TpmEventLog) (*TpmE
 	return &subtractedEvents, &mismatchedEvents, nil
 }
 
+// EventLogChange holds an expected event along with the actual event that has the same measurement
+// and type id but a different type name or tags.
+type EventLogChange struct {
+	Expected EventLog `json:"expected"`
+	Actual   EventLog `json:"actual"`
+}
+
+// EventLogDiff lists the events of a PCR that were added, removed or changed between an expected
+// and an actual event log.
+type EventLogDiff struct {
+	Pcr     Pcr              `json:"pcr"`
+	Added   []EventLog       `json:"added_events,omitempty"`
+	Removed []EventLog       `json:"removed_events,omitempty"`
+	Changed []EventLogChange `json:"changed_events,omitempty"`
+}
+
+// IsEmpty returns true when the event logs hold the same events.
+func (eventLogDiff *EventLogDiff) IsEmpty() bool {
+	return len(eventLogDiff.Added) == 0 && len(eventLogDiff.Removed) == 0 && len(eventLogDiff.Changed) == 0
+}
+
+// Diff compares the expected event log ('eventLogEntry') with the 'actual' one.  The events are
+// matched on their measurement and type id as in Subtract: the events of 'actual' that are not
+// expected are added, the expected events that are not in 'actual' are removed and the events
+// whose type name or tags differ are changed.  Returns an error if the bank/index of the event
+// logs do not match.
+func (eventLogEntry *TpmEventLog) Diff(actual *TpmEventLog) (*EventLogDiff, error) {
+	added, changed, err := actual.Subtract(eventLogEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	removed, _, err := eventLogEntry.Subtract(actual)
+	if err != nil {
+		return nil, err
+	}
+
+	eventLogDiff := EventLogDiff{
+		Pcr:     eventLogEntry.Pcr,
+		Added:   added.TpmEvent,
+		Removed: removed.TpmEvent,
+	}
+
+	expectedEvents := make(map[eventLogKeyAttr]EventLog)
+	for _, eventLog := range eventLogEntry.TpmEvent {
+		expectedEvents[eventLogKeyAttr{Measurement: eventLog.Measurement, TypeID: eventLog.TypeID}] = eventLog
+	}
+	for _, eventLog := range changed.TpmEvent {
+		eventLogDiff.Changed = append(eventLogDiff.Changed, EventLogChange{
+			Expected: expectedEvents[eventLogKeyAttr{Measurement: eventLog.Measurement, TypeID: eventLog.TypeID}],
+			Actual:   eventLog,
+		})
+	}
+
+	return &eventLogDiff, nil
+}
+
 // Returns the string value of the "cumulative" hash of the
 // an event log.
 func (eventLogEntry *TpmEventLog) Replay() (string, error) {
@@ -6,14 +6,10 @@ package util
 
 import (
 	"bytes"
-	"crypto"
 	"crypto/rand"
-	"crypto/rsa"
 	"crypto/sha1"
-	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
-	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"regexp"
@@ -22,6 +18,7 @@ import (
 
 	commLog "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/host-connector/types"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/tpm2"
 	taModel "github.com/intel-secl/intel-secl/v4/pkg/model/ta"
 	"github.com/pkg/errors"
 )
@@ -54,12 +51,6 @@ const (
 var PCR_NUMBER_PATTERN = regexp.MustCompile("[0-9]|[0-1][0-9]|2[0-3]")
 var PCR_VALUE_PATTERN = regexp.MustCompile("[0-9a-fA-F]+")
 
-type pcrSelection struct {
-	size        int
-	hashAlg     uint16
-	pcrSelected []byte
-}
-
 func VerifyQuoteAndGetPCRManifest(decodedEventLog string, verificationNonce []byte, tpmQuoteInBytes []byte,
 	aikCertificate *x509.Certificate) (types.PcrManifest, []byte, error) {
 
@@ -72,112 +63,57 @@ func VerifyQuoteAndGetPCRManifest(decodedEventLog string, verificationNonce []by
 	hashAlgPcrSizeMap[TPM_API_ALG_ID_SHA512] = SHA512_SIZE
 	hashAlgPcrSizeMap[TPM_API_ALG_ID_SM3_SHA256] = SHA256_SIZE
 
-	//Get the length of quote
-	index := 0
-	quoteInfoLen := binary.BigEndian.Uint16(tpmQuoteInBytes[0:2])
-
-	index += 2
-	quoteInfo := tpmQuoteInBytes[index : index+int(quoteInfoLen)]
-
-	index += 6
-	tpm2bNameSize := binary.BigEndian.Uint16(tpmQuoteInBytes[index : index+2])
-
-	index += 2 + int(tpm2bNameSize)
-	tpm2bDataSize := binary.BigEndian.Uint16(tpmQuoteInBytes[index : index+2])
+	quote, err := tpm2.DecodeQuote(tpmQuoteInBytes)
+	if err != nil {
+		return types.PcrManifest{}, nil, errors.Wrap(err, "util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() AIK Quote "+
+			"verification failed, Error decoding quote")
+	}
+	if quote.Attest.Magic != tpm2.TpmGeneratedValue {
+		return types.PcrManifest{}, nil, errors.New("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() AIK Quote " +
+			"verification failed, Quote was not generated by the TPM")
+	}
 
-	index += 2
-	tpm2bData := tpmQuoteInBytes[index : index+int(tpm2bDataSize)]
 	secLog.Debugf("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() "+
-		"Received nonce is : %s", base64.StdEncoding.EncodeToString(tpm2bData))
-	if !bytes.EqualFold(tpm2bData, verificationNonce) {
+		"Received nonce is : %s", base64.StdEncoding.EncodeToString(quote.Attest.ExtraData))
+	if !bytes.EqualFold(quote.Attest.ExtraData, verificationNonce) {
 		return types.PcrManifest{}, nil, errors.New("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() Challenge " +
 			"and received nonce does not match")
 	}
 
-	index += int(tpm2bDataSize)
-	/* Parse quote file
-	 * The quote result is constructed as follows for now
+	/* The quote is constructed as follows
 	 *
-	 * part1: pcr values (0-23), sha1 pcr bank. so the length is 20*24=480
-	 * part2: the quoted information: TPM2B_ATTEST
-	 * part3: the signature: TPMT_SIGNATURE
+	 * part1: the quoted information: TPM2B_ATTEST
+	 * part2: the signature: TPMT_SIGNATURE
+	 * part3: the values of the selected pcrs
 	 */
-	index += 17 // skip over the TPMS_CLOCKINFO structure - Not interested
-	index += 8  // skip over the firmware info - Not interested
-
-	pcrBankCount := binary.BigEndian.Uint32(tpmQuoteInBytes[index : index+4])
-	secLog.Debugf("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() PCR bank count is : %v", pcrBankCount)
-	if pcrBankCount > MAX_PCR_BANKS {
+	pcrSelections := quote.Attest.Quote.PcrSelections
+	secLog.Debugf("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() PCR bank count is : %v", len(pcrSelections))
+	if len(pcrSelections) > MAX_PCR_BANKS {
 		return types.PcrManifest{}, nil, errors.New("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() AIK Quote " +
 			"verification failed, Number of PCR selection array in " + "the quote is greater than 5. PCRBankCount " +
-			": " + fmt.Sprint(pcrBankCount))
+			": " + fmt.Sprint(len(pcrSelections)))
 	}
 
-	index += 4
-	pcrSelection := make([]pcrSelection, pcrBankCount)
-	for i := 0; i < int(pcrBankCount); i++ {
-		pcrSelection[i].hashAlg = binary.BigEndian.Uint16(tpmQuoteInBytes[index : index+2])
-		index += 2
-		pcrSelection[i].size = int(tpmQuoteInBytes[index])
-		index += 1
-		pcrSelection[i].pcrSelected = tpmQuoteInBytes[index : index+pcrSelection[i].size]
-		index += pcrSelection[i].size
-	}
-
-	tpm2bDigestSize := binary.BigEndian.Uint16(tpmQuoteInBytes[index : index+2])
-	secLog.Debugf("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() tpm2bDigestSize is : %v", tpm2bDigestSize)
-	index += 2
-	tpm2bDigest := tpmQuoteInBytes[index : index+int(tpm2bDigestSize)]
+	tpm2bDigest := quote.Attest.Quote.PcrDigest
 	secLog.Debugf("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest()  PCR manifest digest: %v", tpm2bDigest)
 
-	/* PART 2: TPMT_SIGNATURE
-	Skip the first 2 bytes having the quote info size and remaining bytes, which includes signer info, nonce, pcr selection
-	and extra data. So jump to TPMT_SIGNATURE
-	*/
-
-	tpmtSigIndex := 2 + quoteInfoLen
-	tpmtSig := tpmQuoteInBytes[tpmtSigIndex:]
-	var pos uint16 = 0
-	/* sigAlg -indicates the signature algorithm TPMI_SIG_ALG_SCHEME
-	 * for now, it is TPM_ALG_RSASSA with value 0x0014
+	/* sigAlg indicates the signature algorithm TPMI_SIG_ALG_SCHEME, TPM_ALG_RSASSA or TPM_ALG_RSAPSS for RSA AIKs and
+	 * TPM_ALG_ECDSA for ECC AIKs, it is followed by the hash algorithm and the signature
 	 */
-	tpmtSignatureAlg := binary.BigEndian.Uint16(tpmtSig[0:2])
-	secLog.Debugf("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() TPM signature Algorithm: %v", tpmtSignatureAlg)
-	/* hashAlg used by the signature algorithm indicated above
-	 * TPM_ALG_HASH
-	 * for TPM_ALG_RSASSA, the default hash algorithm is TPM_ALG_SHA256 with value 0x000b
-	 */
-	pos += 2
-	tpmtSignatureHashAlg := binary.BigEndian.Uint16(tpmtSig[pos : pos+2])
-	secLog.Debugf("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() TPM signature Hash Algorithm: %v", tpmtSignatureHashAlg)
-
-	pos += 2
-	tpmtSignatureSize := binary.BigEndian.Uint16(tpmtSig[pos : pos+2])
+	secLog.Debugf("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() TPM signature Algorithm: %v", quote.Signature.SigAlg)
+	secLog.Debugf("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() TPM signature Hash Algorithm: %v", quote.Signature.HashAlg)
 
-	pos += 2
-	tpmtSignature := tpmtSig[pos : pos+tpmtSignatureSize]
-	secLog.Debugf("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() TPMT signature : %v", tpmtSignature)
-
-	hash := sha256.New()
-	_, err := hash.Write(quoteInfo)
-	if err != nil {
-		return types.PcrManifest{}, nil, errors.Wrap(err, "Error writing quote information")
-	}
-	pcrsDigest := hash.Sum(nil)
-	secLog.Debugf("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() Quote signature : %v", pcrsDigest)
-	err = rsa.VerifyPKCS1v15(aikCertificate.PublicKey.(*rsa.PublicKey), crypto.SHA256, pcrsDigest, tpmtSignature)
+	err = quote.Signature.Verify(quote.AttestBytes, aikCertificate.PublicKey)
 	if err != nil {
 		return types.PcrManifest{}, nil, errors.Wrap(err, "util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() "+
 			"Error verifying pcrs digest")
 	}
 
-	pos += tpmtSignatureSize
-	pcrLen := uint16(len(tpmQuoteInBytes)) - (pos + tpmtSigIndex)
-	if pcrLen <= 0 {
+	pcrs := quote.PcrValues
+	if len(pcrs) == 0 {
 		return types.PcrManifest{}, nil, errors.New("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() " +
 			"AIK Quote verification failed, No PCR values included in quote")
 	}
-	pcrs := tpmtSig[pos : pos+pcrLen]
 	pcrConcatLen := SHA256_SIZE * 24 * 3
 	pcrPos := 0
 	count := 0
@@ -185,8 +121,8 @@ func VerifyQuoteAndGetPCRManifest(decodedEventLog string, verificationNonce []by
 	var pcrSize int
 	var buffer bytes.Buffer
 
-	for j := 0; j < int(pcrBankCount); j++ {
-		hashAlg := pcrSelection[j].hashAlg
+	for _, pcrSelection := range pcrSelections {
+		hashAlg := pcrSelection.HashAlg
 		if value, ok := hashAlgPcrSizeMap[int(hashAlg)]; ok {
 			pcrSize = value
 		} else {
@@ -195,42 +131,45 @@ func VerifyQuoteAndGetPCRManifest(decodedEventLog string, verificationNonce []by
 			return types.PcrManifest{}, nil, errors.New("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest()" +
 				"AIK Quote verification failed, Unsupported PCR banks, hash algorithm id : %s" + strconv.Itoa(int(hashAlg)))
 		}
-		/* For each pcr bank iterate through each pcr selection array.
-		Here pcrSelection.pcrSelected byte array contains 3 elements, where each bit of this element corresponds to pcr entry.
-		8 bits pcrSelection.pcrSelected value corresponds to 8 PCR entries.
-		*/
-		for pcr := 0; pcr < 8*pcrSelection[j].size; pcr++ {
-			pcrSelected := pcrSelection[j].pcrSelected
-			selected := pcrSelected[pcr/8] & (1 << (uint16(pcr) % 8))
-			if selected > 0 {
-				if (pcrPos + pcrSize) < pcrConcatLen {
-					pcrConcat = append(pcrConcat, pcrs[pcrPos:pcrPos+pcrSize]...)
-				}
-				if hashAlg == TPM_API_ALG_ID_SHA1 {
-					buffer.WriteString(fmt.Sprintf("%2d ", pcr))
-				} else if hashAlg == TPM_API_ALG_ID_SHA256 {
-					buffer.WriteString(fmt.Sprintf("%2d_SHA256 ", pcr))
-				} else if hashAlg == TPM_API_ALG_ID_SHA384 {
-					buffer.WriteString(fmt.Sprintf("%2d_SHA384 ", pcr))
-				}
-				//Ignore the pcr banks other than SHA1 SHA256 and SHA384
-				if hashAlg == TPM_API_ALG_ID_SHA1 || hashAlg == TPM_API_ALG_ID_SHA256 || hashAlg == TPM_API_ALG_ID_SHA384 {
-					for i := 0; i < pcrSize; i++ {
-						buffer.WriteString(fmt.Sprintf("%02x", pcrs[pcrPos+i]))
-					}
+		// For each pcr bank iterate through the selected pcrs, their values follow each other in the quote
+		for _, pcr := range pcrSelection.Pcrs() {
+			if pcrPos+pcrSize > len(pcrs) {
+				return types.PcrManifest{}, nil, errors.New("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() " +
+					"AIK Quote verification failed, Quote does not include the values of all the selected PCRs")
+			}
+			if (pcrPos + pcrSize) < pcrConcatLen {
+				pcrConcat = append(pcrConcat, pcrs[pcrPos:pcrPos+pcrSize]...)
+			}
+			if hashAlg == TPM_API_ALG_ID_SHA1 {
+				buffer.WriteString(fmt.Sprintf("%2d ", pcr))
+			} else if hashAlg == TPM_API_ALG_ID_SHA256 {
+				buffer.WriteString(fmt.Sprintf("%2d_SHA256 ", pcr))
+			} else if hashAlg == TPM_API_ALG_ID_SHA384 {
+				buffer.WriteString(fmt.Sprintf("%2d_SHA384 ", pcr))
+			}
+			//Ignore the pcr banks other than SHA1 SHA256 and SHA384
+			if hashAlg == TPM_API_ALG_ID_SHA1 || hashAlg == TPM_API_ALG_ID_SHA256 || hashAlg == TPM_API_ALG_ID_SHA384 {
+				for i := 0; i < pcrSize; i++ {
+					buffer.WriteString(fmt.Sprintf("%02x", pcrs[pcrPos+i]))
 				}
-				buffer.WriteString("\n")
-				count++
-				pcrPos += pcrSize
 			}
+			buffer.WriteString("\n")
+			count++
+			pcrPos += pcrSize
 		}
 	}
-	hash = sha256.New()
+	// the PCR digest is computed with the hash algorithm of the signing scheme of the AIK
+	digestAlg, err := tpm2.HashAlgorithm(quote.Signature.HashAlg)
+	if err != nil {
+		return types.PcrManifest{}, nil, errors.Wrap(err, "util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() AIK "+
+			"Quote verification failed, Unsupported PCR digest hash algorithm")
+	}
+	hash := digestAlg.New()
 	_, err = hash.Write(pcrConcat)
 	if err != nil {
 		return types.PcrManifest{}, nil, errors.Wrap(err, "Error writing pcr hash")
 	}
-	pcrsDigest = hash.Sum(nil)
+	pcrsDigest := hash.Sum(nil)
 
 	if !bytes.EqualFold(pcrsDigest, tpm2bDigest) {
 		log.Error("util/aik_quote_verifier:VerifyQuoteAndGetPCRManifest() AIK Quote verification failed, Digest " +
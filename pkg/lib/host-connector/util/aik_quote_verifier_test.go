@@ -6,13 +6,26 @@
 package util
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/pem"
 	"encoding/xml"
 	"io/ioutil"
+	"math/big"
 	"testing"
 
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/privacyca/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/tpm2"
 	taModel "github.com/intel-secl/intel-secl/v4/pkg/model/ta"
 	"github.com/stretchr/testify/assert"
 )
@@ -92,3 +105,163 @@ func TestGetVerificationNonceAssetTagProvisioned(t *testing.T) {
 	_, err = GetVerificationNonce(nonceInBytes, tpmQuoteResponse)
 	assert.NoError(t, err)
 }
+
+// resignSampleQuote replaces the signature of the sample quote by one made with the AIK, the way a TPM signs the quote
+// with an ECC AIK or an RSA AIK using the RSAPSS scheme. The PCR digest of the quote is recomputed when the scheme
+// uses another hash algorithm than SHA256. It returns the quote, the verification nonce and the AIK certificate
+func resignSampleQuote(t *testing.T, aik crypto.Signer, sigAlg, hashAlg uint16) ([]byte, []byte, *x509.Certificate) {
+	var tpmQuoteResponse taModel.TpmQuoteResponse
+	b, err := ioutil.ReadFile("../test/sample_tpm_quote.xml")
+	assert.NoError(t, err)
+	err = xml.Unmarshal(b, &tpmQuoteResponse)
+	assert.NoError(t, err)
+
+	nonceInBytes, err := base64.StdEncoding.DecodeString("ZGVhZGJlZWZkZWFkYmVlZmRlYWRiZWVmZGVhZGJlZWZkZWFkYmVlZiA=")
+	assert.NoError(t, err)
+	verificationNonce, err := GetVerificationNonce(nonceInBytes, tpmQuoteResponse)
+	assert.NoError(t, err)
+	verificationNonceInBytes, err := base64.StdEncoding.DecodeString(verificationNonce)
+	assert.NoError(t, err)
+
+	tpmQuoteInBytes, err := base64.StdEncoding.DecodeString(tpmQuoteResponse.Quote)
+	assert.NoError(t, err)
+	quoteInfoLen := int(binary.BigEndian.Uint16(tpmQuoteInBytes[0:2]))
+	quoteInfo := tpmQuoteInBytes[2 : 2+quoteInfoLen]
+	_, signatureLen, err := tpm2.DecodeSignature(tpmQuoteInBytes[2+quoteInfoLen:])
+	assert.NoError(t, err)
+	pcrs := tpmQuoteInBytes[2+quoteInfoLen+signatureLen:]
+
+	digestAlg, err := tpm2.HashAlgorithm(hashAlg)
+	assert.NoError(t, err)
+	if digestAlg != crypto.SHA256 {
+		// the PCR digest ends the quote information, it is replaced by the digest of the PCR values with the hash
+		// algorithm of the scheme
+		pcrDigest := digestAlg.New()
+		pcrDigest.Write(pcrs)
+		quoteInfo = append(append([]byte{}, quoteInfo[:len(quoteInfo)-2-sha256.Size]...), 0, byte(digestAlg.Size()))
+		quoteInfo = append(quoteInfo, pcrDigest.Sum(nil)...)
+	}
+
+	digest := digestAlg.New()
+	digest.Write(quoteInfo)
+	signature := new(bytes.Buffer)
+	_ = binary.Write(signature, binary.BigEndian, []uint16{sigAlg, hashAlg})
+	switch key := aik.(type) {
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest.Sum(nil))
+		assert.NoError(t, err)
+		for _, value := range []*big.Int{r, s} {
+			_ = binary.Write(signature, binary.BigEndian, uint16(len(value.Bytes())))
+			signature.Write(value.Bytes())
+		}
+	case *rsa.PrivateKey:
+		sig, err := rsa.SignPSS(rand.Reader, key, digestAlg, digest.Sum(nil), &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		assert.NoError(t, err)
+		_ = binary.Write(signature, binary.BigEndian, uint16(len(sig)))
+		signature.Write(sig)
+	}
+
+	quote := []byte{byte(len(quoteInfo) >> 8), byte(len(quoteInfo))}
+	quote = append(append(append(quote, quoteInfo...), signature.Bytes()...), pcrs...)
+
+	template := x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "AIK"}}
+	aikCertBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, aik.Public(), aik)
+	assert.NoError(t, err)
+	aikCertificate, err := x509.ParseCertificate(aikCertBytes)
+	assert.NoError(t, err)
+	return quote, verificationNonceInBytes, aikCertificate
+}
+
+func TestVerifyQuoteAndGetPCRManifestEcdsa(t *testing.T) {
+	aik, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	quote, verificationNonce, aikCertificate := resignSampleQuote(t, aik, constants.TPM_ALG_ID_ECDSA, constants.TPM_ALG_ID_SHA256)
+
+	decodedEventLogBytes, err := ioutil.ReadFile("../test/sample_measure_log.json")
+	assert.NoError(t, err)
+
+	_, _, err = VerifyQuoteAndGetPCRManifest(string(decodedEventLogBytes), verificationNonce, quote, aikCertificate)
+	assert.NoError(t, err)
+
+	// a quote signed by another key is rejected
+	otherAik, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	_, _, otherAikCertificate := resignSampleQuote(t, otherAik, constants.TPM_ALG_ID_ECDSA, constants.TPM_ALG_ID_SHA256)
+	_, _, err = VerifyQuoteAndGetPCRManifest(string(decodedEventLogBytes), verificationNonce, quote, otherAikCertificate)
+	assert.Error(t, err)
+}
+
+func TestVerifyQuoteAndGetPCRManifestRsaPss(t *testing.T) {
+	aik, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	quote, verificationNonce, aikCertificate := resignSampleQuote(t, aik, constants.TPM_ALG_ID_RSAPSS, constants.TPM_ALG_ID_SHA256)
+
+	decodedEventLogBytes, err := ioutil.ReadFile("../test/sample_measure_log.json")
+	assert.NoError(t, err)
+
+	_, _, err = VerifyQuoteAndGetPCRManifest(string(decodedEventLogBytes), verificationNonce, quote, aikCertificate)
+	assert.NoError(t, err)
+}
+
+func TestVerifyQuoteAndGetPCRManifestEcdsaP384(t *testing.T) {
+	aik, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	quote, verificationNonce, aikCertificate := resignSampleQuote(t, aik, constants.TPM_ALG_ID_ECDSA, constants.TPM_ALG_ID_SHA384)
+
+	decodedEventLogBytes, err := ioutil.ReadFile("../test/sample_measure_log.json")
+	assert.NoError(t, err)
+
+	_, pcrsDigest, err := VerifyQuoteAndGetPCRManifest(string(decodedEventLogBytes), verificationNonce, quote, aikCertificate)
+	assert.NoError(t, err)
+	assert.Len(t, pcrsDigest, sha512.Size384)
+}
+
+// the ECDSA quotes were captured from the TCG reference TPM 2.0 simulator with SHA1 and SHA256 PCR banks
+// selected and the verification nonce "verification nonce"
+func TestVerifyQuoteAndGetPCRManifestTpmEcdsaQuotes(t *testing.T) {
+	decodedEventLogBytes, err := ioutil.ReadFile("../test/sample_measure_log.json")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		quoteFile          string
+		expectedDigestSize int
+	}{
+		{quoteFile: "../test/sample_tpm_quote_ecdsa_p256.xml", expectedDigestSize: sha256.Size},
+		{quoteFile: "../test/sample_tpm_quote_ecdsa_p384.xml", expectedDigestSize: sha512.Size384},
+	}
+	for _, tt := range tests {
+		t.Run(tt.quoteFile, func(t *testing.T) {
+			var tpmQuoteResponse taModel.TpmQuoteResponse
+			b, err := ioutil.ReadFile(tt.quoteFile)
+			assert.NoError(t, err)
+			assert.NoError(t, xml.Unmarshal(b, &tpmQuoteResponse))
+
+			aikCertInBytes, err := base64.StdEncoding.DecodeString(tpmQuoteResponse.Aik)
+			assert.NoError(t, err)
+			aikPem, _ := pem.Decode(aikCertInBytes)
+			aikCertificate, err := x509.ParseCertificate(aikPem.Bytes)
+			assert.NoError(t, err)
+			tpmQuoteInBytes, err := base64.StdEncoding.DecodeString(tpmQuoteResponse.Quote)
+			assert.NoError(t, err)
+
+			verificationNonce, err := GetVerificationNonce([]byte("verification nonce"), tpmQuoteResponse)
+			assert.NoError(t, err)
+			verificationNonceInBytes, err := base64.StdEncoding.DecodeString(verificationNonce)
+			assert.NoError(t, err)
+
+			pcrManifest, pcrsDigest, err := VerifyQuoteAndGetPCRManifest(string(decodedEventLogBytes), verificationNonceInBytes, tpmQuoteInBytes, aikCertificate)
+			assert.NoError(t, err)
+			assert.Len(t, pcrsDigest, tt.expectedDigestSize)
+			assert.Len(t, pcrManifest.Sha1Pcrs, 24)
+			assert.Len(t, pcrManifest.Sha256Pcrs, 24)
+
+			// a quote for another nonce is rejected
+			otherNonce, err := GetVerificationNonce([]byte("another nonce"), tpmQuoteResponse)
+			assert.NoError(t, err)
+			otherNonceInBytes, err := base64.StdEncoding.DecodeString(otherNonce)
+			assert.NoError(t, err)
+			_, _, err = VerifyQuoteAndGetPCRManifest(string(decodedEventLogBytes), otherNonceInBytes, tpmQuoteInBytes, aikCertificate)
+			assert.Error(t, err)
+		})
+	}
+}
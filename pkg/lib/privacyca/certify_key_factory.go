@@ -6,6 +6,7 @@
 package privacyca
 
 import (
+	"crypto"
 	"crypto/rsa"
 	"crypto/x509"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/privacyca/tpm2utils"
@@ -17,8 +18,9 @@ type CertifyKey interface {
 	IsCertifiedKeySignatureValid(aikCert *x509.Certificate) (bool, error)
 	ValidateNameDigest() error
 	ValidatePublicKey() (bool, error)
-	CertifyKey(caCert *x509.Certificate, rsaPubKey *rsa.PublicKey, caKey *rsa.PrivateKey, cn string) ([]byte, error)
+	CertifyKey(caCert *x509.Certificate, pubKey crypto.PublicKey, caKey crypto.Signer, cn string) ([]byte, error)
 	GetPublicKeyFromModulus() (*rsa.PublicKey, error)
+	GetPublicKey() (crypto.PublicKey, error)
 	IsTpmGeneratedKey() bool
 }
 
@@ -8,6 +8,7 @@ package constants
 const (
 	TPM2AlgorithmSymmetricAES   = "AES"
 	SymmetricKeyBits128         = 128
+	SymmetricKeyBits256         = 256
 	TPM_ALG_AES                 = 0x6
 	TPM_ES_NONE                 = 0x1
 	SHORT_BYTES                 = 2
@@ -19,6 +20,18 @@ const (
 	INTEGRITY                   = "INTEGRITY"
 	TPM_ALG_ID_SHA256           = 0x000B
 	TPM_ALG_ID_SHA384           = 0x000C
+	TPM_ALG_ID_SHA1             = 0x0004
+	TPM_ALG_ID_SHA512           = 0x000D
+	TPM_ALG_ID_RSA              = 0x0001
+	TPM_ALG_ID_ECC              = 0x0023
+	TPM_ALG_ID_NULL             = 0x0010
+	TPM_ALG_ID_RSASSA           = 0x0014
+	TPM_ALG_ID_RSAPSS           = 0x0016
+	TPM_ALG_ID_ECDSA            = 0x0018
+	TPM_ALG_ID_ECDH             = 0x0019
+	TPM_ALG_ID_ECDAA            = 0x001A
+	TPM_ECC_NIST_P256           = 0x0003
+	TPM_ECC_NIST_P384           = 0x0004
 	HOST_KEYS_CERT_VALIDITY     = 10
 	Tpm2NameDigestPrefixPadding = "22000b"
 	Tpm2NameDigestSuffixPadding = "00000000000000000000000000000000000000000000000000000000000000000000"
@@ -7,7 +7,6 @@ package privacyca
 
 import (
 	"crypto"
-	"crypto/rsa"
 	commLog "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
 	"github.com/intel-secl/intel-secl/v4/pkg/model/ta"
 )
@@ -18,5 +17,5 @@ var log = commLog.GetDefaultLogger()
 type PrivacyCa interface {
 	ProcessIdentityRequest(model.IdentityRequest, crypto.PublicKey, []byte) (model.IdentityProofRequest, error)
 	GetEkCert(model.IdentityChallengePayload, crypto.PrivateKey) ([]byte, error)
-	GetIdentityChallengeRequest([]byte, *rsa.PublicKey, model.IdentityRequest) (model.IdentityChallengePayload, error)
+	GetIdentityChallengeRequest([]byte, crypto.PublicKey, model.IdentityRequest) (model.IdentityChallengePayload, error)
 }
@@ -9,8 +9,12 @@ import (
 	"bytes"
 	"crypto"
 	"crypto/aes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/base64"
@@ -267,3 +271,346 @@ func TestGetPublicKeyFromModulus(t *testing.T) {
 	_, err = certifyKey20.GetPublicKeyFromModulus()
 	assert.NoError(t, err)
 }
+
+// eccPublicArea returns the TPMT_PUBLIC of an ECC signing key, the way the TPM creates the AIKs and the signing keys
+func eccPublicArea(pubKey *ecdsa.PublicKey, nameAlg uint16) []byte {
+	curveID := uint16(consts.TPM_ECC_NIST_P256)
+	if pubKey.Curve == elliptic.P384() {
+		curveID = consts.TPM_ECC_NIST_P384
+	}
+	size := (pubKey.Curve.Params().BitSize + 7) / 8
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, []uint16{consts.TPM_ALG_ID_ECC, nameAlg})
+	binary.Write(buf, binary.BigEndian, uint32(0x00050072))
+	// empty auth policy, no symmetric algorithm, ECDSA scheme, curve and no KDF
+	binary.Write(buf, binary.BigEndian, []uint16{0, consts.TPM_ALG_ID_NULL, consts.TPM_ALG_ID_ECDSA, nameAlg, curveID, consts.TPM_ALG_ID_NULL})
+	for _, coordinate := range []*big.Int{pubKey.X, pubKey.Y} {
+		binary.Write(buf, binary.BigEndian, uint16(size))
+		buf.Write(coordinate.FillBytes(make([]byte, size)))
+	}
+	return buf.Bytes()
+}
+
+// activateEccCredential recovers the credential made for the ECC EK, the way the TPM does in ActivateCredential, it
+// returns nil when the integrity of the credential cannot be verified
+func activateEccCredential(t *testing.T, ek *ecdsa.PrivateKey, nameAlg crypto.Hash, symKeySizeInBits int, aikName []byte, proofReq taModel.IdentityProofRequest) []byte {
+	size := (ek.Curve.Params().BitSize + 7) / 8
+
+	// the secret is the ephemeral public key of the Privacy CA
+	var secretLength, coordinateLength uint16
+	buf := bytes.NewBuffer(proofReq.Secret)
+	binary.Read(buf, binary.BigEndian, &secretLength)
+	assert.Equal(t, 4+2*size, int(secretLength))
+	binary.Read(buf, binary.BigEndian, &coordinateLength)
+	x := buf.Next(int(coordinateLength))
+	binary.Read(buf, binary.BigEndian, &coordinateLength)
+	y := buf.Next(int(coordinateLength))
+
+	ephemeralKey := ecdsa.PublicKey{Curve: ek.Curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	ecdhEphemeralKey, err := ephemeralKey.ECDH()
+	assert.NoError(t, err)
+	ecdhEk, err := ek.ECDH()
+	assert.NoError(t, err)
+	z, err := ecdhEk.ECDH(ecdhEphemeralKey)
+	assert.NoError(t, err)
+	seed, err := tpm2utils.KDFe(nameAlg, z, consts.IDENTITY, x, ek.X.FillBytes(make([]byte, size)), nameAlg.Size()*8)
+	assert.NoError(t, err)
+
+	var credentialBlobLength, integrityLength int16
+	buf = bytes.NewBuffer(proofReq.Credential)
+	binary.Read(buf, binary.BigEndian, &credentialBlobLength)
+	binary.Read(buf, binary.BigEndian, &integrityLength)
+	integrity := buf.Next(int(integrityLength))
+	encryptedCredential := buf.Next(int(credentialBlobLength) - int(integrityLength) - consts.SHORT_BYTES)
+
+	hmacKey, err := tpm2utils.KDFa(nameAlg, seed, consts.INTEGRITY, nil, nil, nameAlg.Size()*8)
+	assert.NoError(t, err)
+	mac := hmac.New(nameAlg.New, hmacKey)
+	mac.Write(encryptedCredential)
+	mac.Write(aikName)
+	if !hmac.Equal(mac.Sum(nil), integrity) {
+		return nil
+	}
+
+	symKey, err := tpm2utils.KDFa(nameAlg, seed, consts.STORAGE, aikName, nil, symKeySizeInBits)
+	assert.NoError(t, err)
+	credential, err := tpm2utils.DecryptSym(encryptedCredential, symKey, make([]byte, aes.BlockSize), "CBF", consts.TPM_ALG_AES)
+	assert.NoError(t, err)
+	var credentialLength int16
+	buf = bytes.NewBuffer(credential)
+	binary.Read(buf, binary.BigEndian, &credentialLength)
+	return buf.Next(int(credentialLength))
+}
+
+func TestProcessMakeCredentialEcc(t *testing.T) {
+	for _, tc := range []struct {
+		curve            elliptic.Curve
+		nameAlg          crypto.Hash
+		symKeySizeInBits int
+	}{
+		{elliptic.P256(), crypto.SHA256, 128},
+		{elliptic.P384(), crypto.SHA384, 256},
+	} {
+		ek, err := ecdsa.GenerateKey(tc.curve, rand.Reader)
+		assert.NoError(t, err)
+		aik, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.NoError(t, err)
+		aikName, err := tpm2utils.Tpm2Name(eccPublicArea(&aik.PublicKey, consts.TPM_ALG_ID_SHA256))
+		assert.NoError(t, err)
+
+		identityChallengeNonce, _ := crypt.GetRandomBytes(32)
+		identityRequest := model.IdentityRequest{
+			TpmVersion: "2.0",
+			AikName:    aikName,
+		}
+		privacycaTpm2, err := privacyca.NewPrivacyCA(identityRequest)
+		assert.NoError(t, err)
+
+		tpm2IdentityProofReq, err := privacycaTpm2.ProcessIdentityRequest(identityRequest, &ek.PublicKey, identityChallengeNonce)
+		assert.NoError(t, err)
+
+		key := activateEccCredential(t, ek, tc.nameAlg, tc.symKeySizeInBits, aikName, tpm2IdentityProofReq)
+		dataBlob, err := tpm2utils.DecryptSym(tpm2IdentityProofReq.SymmetricBlob, key, tpm2IdentityProofReq.TpmSymmetricKeyParams.IV, "CBC", consts.TPM_ALG_AES)
+		assert.NoError(t, err)
+		assert.Equal(t, identityChallengeNonce, dataBlob)
+	}
+}
+
+func TestMakeCredentialEccWrongEk(t *testing.T) {
+	ek, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	credential, err := tpm2utils.MakeCredential(&ek.PublicKey, consts.TPM2AlgorithmSymmetricAES, 128, crypto.SHA256, []byte("credential"), aikName)
+	assert.NoError(t, err)
+
+	// the seed derived with another EK does not match the integrity of the credential
+	otherEk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	proofReq := taModel.IdentityProofRequest{Secret: credential.Secret, Credential: credential.CredentialBlob}
+	assert.Nil(t, activateEccCredential(t, otherEk, crypto.SHA256, 128, aikName, proofReq))
+	assert.Equal(t, []byte("credential"), activateEccCredential(t, ek, crypto.SHA256, 128, aikName, proofReq))
+}
+
+func TestGetEkCertEcc(t *testing.T) {
+	eccIdentityReq := model.IdentityRequest{TpmVersion: "2.0", AikModulus: aikModulus, AikName: aikName}
+	privacyCA, err := privacyca.NewPrivacyCA(eccIdentityReq)
+	assert.NoError(t, err)
+	pcaKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+
+	ekCertBytes, _ := crypt.GetRandomBytes(16)
+	idPayload, err := privacyCA.GetIdentityChallengeRequest(ekCertBytes, &pcaKey.PublicKey, eccIdentityReq)
+	assert.NoError(t, err)
+	assert.Equal(t, consts.TPM_ALG_ID_ECDH, idPayload.TpmAsymmetricKeyParams.TpmAlgEncScheme)
+
+	decryptedEkCertBytes, err := privacyCA.GetEkCert(idPayload, pcaKey)
+	assert.NoError(t, err)
+	assert.Equal(t, ekCertBytes, decryptedEkCertBytes)
+
+	otherPcaKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	_, err = privacyCA.GetEkCert(idPayload, otherPcaKey)
+	assert.Error(t, err)
+}
+
+func TestGetAikPublicKey(t *testing.T) {
+	// RSA AIKs are sent with their modulus only
+	aikPubKey, err := privacyca.GetAikPublicKey(identityReq)
+	assert.NoError(t, err)
+	assert.Equal(t, new(big.Int).SetBytes(aikModulus), aikPubKey.(*rsa.PublicKey).N)
+
+	aik, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	publicArea := eccPublicArea(&aik.PublicKey, consts.TPM_ALG_ID_SHA256)
+	name, err := tpm2utils.Tpm2Name(publicArea)
+	assert.NoError(t, err)
+
+	eccIdentityReq := model.IdentityRequest{TpmVersion: "2.0", AikName: name, AikPublicArea: publicArea}
+	aikPubKey, err = privacyca.GetAikPublicKey(eccIdentityReq)
+	assert.NoError(t, err)
+	assert.True(t, aik.PublicKey.Equal(aikPubKey))
+
+	// the public area must match the name the credential is bound to
+	eccIdentityReq.AikName = aikName
+	_, err = privacyca.GetAikPublicKey(eccIdentityReq)
+	assert.Error(t, err)
+}
+
+// certifyKeyPayload returns the registration of a key certified by the AIK with the signature scheme
+func certifyKeyPayload(t *testing.T, aik crypto.Signer, sigAlg uint16) wlaModel.RegisterKeyInfo {
+	digest := sha256.Sum256(tpmCertifyKey[2:])
+	signature := new(bytes.Buffer)
+	binary.Write(signature, binary.BigEndian, []uint16{sigAlg, consts.TPM_ALG_ID_SHA256})
+	switch key := aik.(type) {
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		assert.NoError(t, err)
+		for _, value := range []*big.Int{r, s} {
+			binary.Write(signature, binary.BigEndian, uint16(len(value.Bytes())))
+			signature.Write(value.Bytes())
+		}
+	case *rsa.PrivateKey:
+		sig, err := rsa.SignPSS(rand.Reader, key, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		assert.NoError(t, err)
+		binary.Write(signature, binary.BigEndian, uint16(len(sig)))
+		signature.Write(sig)
+	}
+
+	certder, keyder, _ := crypt.CreateKeyPairAndCertificate(constants.DefaultPrivacyCaIdentityIssuer, "", constants.DefaultKeyAlgorithm, constants.DefaultKeyLength)
+	privKey, _ := x509.ParsePKCS8PrivateKey(keyder)
+	cert, _ := x509.ParseCertificate(certder)
+	clientCRTTemplate := x509.Certificate{
+		Issuer: pkix.Name{
+			CommonName: "HVS",
+		},
+		SerialNumber: big.NewInt(1),
+	}
+	aikCert, err := x509.CreateCertificate(rand.Reader, &clientCRTTemplate, cert, aik.Public(), privKey.(*rsa.PrivateKey))
+	assert.NoError(t, err)
+
+	return wlaModel.RegisterKeyInfo{
+		PublicKeyModulus:       publicKeyModulus,
+		TpmCertifyKey:          tpmCertifyKey[2:],
+		TpmCertifyKeySignature: signature.Bytes(),
+		AikDerCertificate:      aikCert,
+		NameDigest:             append(nameDigest[1:], make([]byte, 34)...),
+		TpmVersion:             "2.0",
+		OsType:                 "Linux",
+	}
+}
+
+func TestIsCertifiedKeySignatureValidEcdsa(t *testing.T) {
+	aik, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	regKeyInfo := certifyKeyPayload(t, aik, consts.TPM_ALG_ID_ECDSA)
+
+	certifyKey20, err := privacyca.NewCertifyKey(regKeyInfo)
+	assert.NoError(t, err)
+	aikCert, err := x509.ParseCertificate(regKeyInfo.AikDerCertificate)
+	assert.NoError(t, err)
+	valid, err := certifyKey20.IsCertifiedKeySignatureValid(aikCert)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	// the certified key is tampered
+	regKeyInfo.TpmCertifyKey = append([]byte{}, regKeyInfo.TpmCertifyKey...)
+	regKeyInfo.TpmCertifyKey[len(regKeyInfo.TpmCertifyKey)-1] ^= 0xff
+	certifyKey20, err = privacyca.NewCertifyKey(regKeyInfo)
+	assert.NoError(t, err)
+	valid, err = certifyKey20.IsCertifiedKeySignatureValid(aikCert)
+	assert.Error(t, err)
+	assert.False(t, valid)
+}
+
+func TestIsCertifiedKeySignatureValidRsaPss(t *testing.T) {
+	aik, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	regKeyInfo := certifyKeyPayload(t, aik, consts.TPM_ALG_ID_RSAPSS)
+
+	certifyKey20, err := privacyca.NewCertifyKey(regKeyInfo)
+	assert.NoError(t, err)
+	aikCert, err := x509.ParseCertificate(regKeyInfo.AikDerCertificate)
+	assert.NoError(t, err)
+	valid, err := certifyKey20.IsCertifiedKeySignatureValid(aikCert)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	// the signature scheme must match the AIK
+	regKeyInfo.TpmCertifyKeySignature[1] = byte(consts.TPM_ALG_ID_ECDSA)
+	certifyKey20, err = privacyca.NewCertifyKey(regKeyInfo)
+	assert.NoError(t, err)
+	valid, err = certifyKey20.IsCertifiedKeySignatureValid(aikCert)
+	assert.Error(t, err)
+	assert.False(t, valid)
+}
+
+func TestCertifyKeyEcc(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	publicArea := eccPublicArea(&key.PublicKey, consts.TPM_ALG_ID_SHA256)
+	regKeyInfo := regKeyInfoPayload
+	regKeyInfo.PublicKeyModulus = append([]byte{byte(len(publicArea) >> 8), byte(len(publicArea))}, publicArea...)
+
+	certifyKey20, err := privacyca.NewCertifyKey(regKeyInfo)
+	assert.NoError(t, err)
+	pubKey, err := certifyKey20.GetPublicKey()
+	assert.NoError(t, err)
+	assert.True(t, key.PublicKey.Equal(pubKey))
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	caTemplate := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: constants.DefaultPrivacyCaIdentityIssuer},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caCertBytes, err := x509.CreateCertificate(rand.Reader, &caTemplate, &caTemplate, &caKey.PublicKey, caKey)
+	assert.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caCertBytes)
+	assert.NoError(t, err)
+
+	certBytes, err := certifyKey20.CertifyKey(caCert, pubKey, caKey, "SigningKey")
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(certBytes)
+	assert.NoError(t, err)
+	assert.NoError(t, cert.CheckSignatureFrom(caCert))
+	assert.Equal(t, x509.KeyUsageDigitalSignature|x509.KeyUsageKeyAgreement, cert.KeyUsage)
+}
+
+// the credentials were made with TPM2_MakeCredential by the TCG reference TPM 2.0 simulator for an ECC EK loaded
+// with LoadExternal, whose private key is known, and bound to the name of an ECC AIK of the simulator
+var tpmEccCredentialVectors = []struct {
+	curve            elliptic.Curve
+	nameAlg          crypto.Hash
+	symKeySizeInBits int
+	ekPrivateKey     string
+	aikName          string
+	credential       string
+	credentialBlob   string
+	secret           string
+}{
+	{
+		curve:            elliptic.P256(),
+		nameAlg:          crypto.SHA256,
+		symKeySizeInBits: 128,
+		ekPrivateKey:     "Yt94GiebQyq64ur1apDgl5DP+6G3HfoR7U50VlDSjMk=",
+		aikName:          "AAv0gG/86+576XbTJN5wI3b/DdapLpjTypC2hKAVJx2ZCg==",
+		credential:       "t3GyZqwvWxJQxiTBzRiPff0JbR5ijXH7I00QIiAqEnM=",
+		credentialBlob:   "AEQAIOdd0MqoOB3ZR89hnGyjfS1BIahucR7dUZXlMHdaYJ+ED5RWqS5o7iWWMwLqrtN4znOKEb7I3dFcsswSls97qTXR3g==",
+		secret:           "AEQAIKlaCni4e9tM+O9Pw/JcVFVJbQfhNzIs84smMlPIG/TbACBU8wzGKLbjg/B8fZuRst2Q2tMn/ZcTFL1iGPrDZtyihg==",
+	},
+	{
+		curve:            elliptic.P384(),
+		nameAlg:          crypto.SHA384,
+		symKeySizeInBits: 256,
+		ekPrivateKey:     "HyjBBdYK/WBuRiFJHhKZBXIvkn4v97FPI7VbjmQBYgolcX2Ho3xzuZ+fS3BUcawo",
+		aikName:          "AAv0gG/86+576XbTJN5wI3b/DdapLpjTypC2hKAVJx2ZCg==",
+		credential:       "ck96kyi8XkjhMpUKfrMdIz+fUh4vI53ZH/6W7WtsAJM=",
+		credentialBlob:   "AFQAMAy/LYGthyIUt9g6cTp8k4SrIOoxWzKLIfOdXu2rlGLMQl3sbk1Viheie/thI0q1eD4yufvKfNVhAlXwDkQ7HOq0mBAH/YTvbyQw/EgirnD0ZFU=",
+		secret:           "AGQAMEtotTI1j/JQ3nNJYSIDWuhO36HJW+jVV2aOzqyEJCd1oxAkxvP3DpBrXOYPwDj9ywAwBy00DMOq8Jz1FH8vBuTVP6mB5ickXUffvBAGRMUNzJ/DqS3657lLOn2FrZc9eO2n",
+	},
+}
+
+func TestActivateTpmEccCredential(t *testing.T) {
+	for _, tv := range tpmEccCredentialVectors {
+		ekPrivateKey, err := base64.StdEncoding.DecodeString(tv.ekPrivateKey)
+		assert.NoError(t, err)
+		ek := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(ekPrivateKey)}
+		ek.Curve = tv.curve
+		ek.X, ek.Y = tv.curve.ScalarBaseMult(ekPrivateKey)
+
+		vectorAikName, err := base64.StdEncoding.DecodeString(tv.aikName)
+		assert.NoError(t, err)
+		credential, err := base64.StdEncoding.DecodeString(tv.credential)
+		assert.NoError(t, err)
+		credentialBlob, err := base64.StdEncoding.DecodeString(tv.credentialBlob)
+		assert.NoError(t, err)
+		secret, err := base64.StdEncoding.DecodeString(tv.secret)
+		assert.NoError(t, err)
+
+		// the seed, integrity and encryption of the TPM match the ones of the Privacy CA MakeCredential
+		proofReq := taModel.IdentityProofRequest{Secret: secret, Credential: credentialBlob}
+		assert.Equal(t, credential, activateEccCredential(t, ek, tv.nameAlg, tv.symKeySizeInBits, vectorAikName, proofReq))
+	}
+}
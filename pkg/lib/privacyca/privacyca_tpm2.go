@@ -7,6 +7,8 @@ package privacyca
 import (
 	"bytes"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rsa"
 	"crypto/sha256"
 	"encoding/binary"
@@ -15,6 +17,7 @@ import (
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/privacyca/tpm2utils"
 	model "github.com/intel-secl/intel-secl/v4/pkg/model/ta"
 	"github.com/pkg/errors"
+	"math/big"
 )
 
 //-------------------------------------------------------------------------------------------------
@@ -58,7 +61,8 @@ func (privacycatpm2 *PrivacyCATpm2) ProcessIdentityRequest(request model.Identit
 	if err != nil {
 		return model.IdentityProofRequest{}, errors.Wrap(err, "privacyca/privacyca_tpm2:ProcessIdentityRequest() Error writing identity challenge")
 	}
-	credential, err := tpm2utils.MakeCredential(pubEk, consts.TPM2AlgorithmSymmetricAES, consts.SymmetricKeyBits128, crypto.SHA256, key, request.AikName)
+	nameAlgorithm, symKeySizeInBits := ekCredentialParameters(pubEk)
+	credential, err := tpm2utils.MakeCredential(pubEk, consts.TPM2AlgorithmSymmetricAES, symKeySizeInBits, nameAlgorithm, key, request.AikName)
 	if err != nil {
 		return model.IdentityProofRequest{}, errors.Errorf("privacyca/privacyca_tpm2:ProcessIdentityRequest() Error while performing MakeCredential %+v", err)
 	}
@@ -80,6 +84,43 @@ func (privacycatpm2 *PrivacyCATpm2) ProcessIdentityRequest(request model.Identit
 	return identityProofRequest, nil
 }
 
+// ekCredentialParameters returns the name algorithm and the size of the symmetric key of the EK, which protect the
+// credential. They are the ones of the EK templates of the TCG EK Credential Profile, the P-384 EKs use SHA384 and
+// AES-256 while the RSA and P-256 EKs use SHA256 and AES-128
+func ekCredentialParameters(pubEk crypto.PublicKey) (crypto.Hash, int) {
+	if eccEk, ok := pubEk.(*ecdsa.PublicKey); ok && eccEk.Curve == elliptic.P384() {
+		return crypto.SHA384, consts.SymmetricKeyBits256
+	}
+	return crypto.SHA256, consts.SymmetricKeyBits128
+}
+
+// GetAikPublicKey returns the public key of the AIK of the identity request. The ECC AIKs are sent with their public
+// area, which must match the AIK name, while the RSA AIKs may be sent with their modulus only
+func GetAikPublicKey(request model.IdentityRequest) (crypto.PublicKey, error) {
+	log.Trace("privacyca/privacyca_tpm2:GetAikPublicKey() Entering")
+	defer log.Trace("privacyca/privacyca_tpm2:GetAikPublicKey() Leaving")
+
+	if len(request.AikPublicArea) == 0 {
+		if len(request.AikModulus) == 0 {
+			return nil, errors.New("privacyca/privacyca_tpm2:GetAikPublicKey() Identity request does not include the AIK public key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(request.AikModulus), E: 65537}, nil
+	}
+
+	aikName, err := tpm2utils.Tpm2Name(request.AikPublicArea)
+	if err != nil {
+		return nil, errors.Wrap(err, "privacyca/privacyca_tpm2:GetAikPublicKey() Error computing AIK name")
+	}
+	if !bytes.Equal(aikName, request.AikName) {
+		return nil, errors.New("privacyca/privacyca_tpm2:GetAikPublicKey() AIK public area does not match the AIK name")
+	}
+	aikPublic, err := tpm2utils.ParseTpm2Public(request.AikPublicArea)
+	if err != nil {
+		return nil, errors.Wrap(err, "privacyca/privacyca_tpm2:GetAikPublicKey() Error parsing AIK public area")
+	}
+	return aikPublic.PublicKey, nil
+}
+
 /**
  * Returns the decrypted ekcert bytes.
  * This function will decrypt a blob of data using privacyca private key and returns decrypted symmetric key.
@@ -109,13 +150,14 @@ func (privacycatpm2 *PrivacyCATpm2) GetEkCert(identityChallengePayload model.Ide
 /**
  * Returns the encrypted endorsement cert bytes.
  * This function will encrypt a blob of data using randomly generated key using CBC AES Encryption scheme.
- * The symmetric key is encrypted with RSA SHA256 algorithm using public portion of Privacyca Cert
+ * The symmetric key is encrypted with RSA SHA256 algorithm, or with a key derived from an ECDH shared secret for ECC
+ * keys, using public portion of Privacyca Cert
  * param payload data to be encrypted
  * param pubKey public portion of privacyca certificate
  * param identity Request.
  * return IdentityChallengePayload
  */
-func (privacycatpm2 *PrivacyCATpm2) GetIdentityChallengeRequest(payload []byte, pubKey *rsa.PublicKey, request model.IdentityRequest) (model.IdentityChallengePayload, error) {
+func (privacycatpm2 *PrivacyCATpm2) GetIdentityChallengeRequest(payload []byte, pubKey crypto.PublicKey, request model.IdentityRequest) (model.IdentityChallengePayload, error) {
 	log.Trace("privacyca/privacyca_tpm2:GetIdentityChallengeRequest() Entering")
 	defer log.Trace("privacyca/privacyca_tpm2:GetIdentityChallengeRequest() Leaving")
 	//---------------------------------------------------------------------------------------------
@@ -146,24 +188,44 @@ func (privacycatpm2 *PrivacyCATpm2) GetIdentityChallengeRequest(payload []byte,
 		IV:                    iv,
 	}
 
-	asymKey, err := crypt.GetRandomBytes(32)
-	if err != nil {
-		return model.IdentityChallengePayload{}, err
-	}
+	var asymmetricBytes []byte
+	var asymmetricKeyParams model.TpmAsymmetricKeyParams
+	switch key := pubKey.(type) {
+	case *rsa.PublicKey:
+		asymKey, err := crypt.GetRandomBytes(32)
+		if err != nil {
+			return model.IdentityChallengePayload{}, err
+		}
 
-	// Encrypt the symmetric key using rsa sha256 Algorithm
-	asymmetricBytes, err := rsa.EncryptOAEP(sha256.New(), bytes.NewBuffer(asymKey), pubKey, cipherKey, nil)
-	if err != nil {
-		return model.IdentityChallengePayload{}, errors.Wrap(err, "privacyca/privacyca_tpm2:GetIdentityChallengeRequest() Error while encrypting symmetric key")
-	}
+		// Encrypt the symmetric key using rsa sha256 Algorithm
+		asymmetricBytes, err = rsa.EncryptOAEP(sha256.New(), bytes.NewBuffer(asymKey), key, cipherKey, nil)
+		if err != nil {
+			return model.IdentityChallengePayload{}, errors.Wrap(err, "privacyca/privacyca_tpm2:GetIdentityChallengeRequest() Error while encrypting symmetric key")
+		}
 
-	asymmetricKeyParams := model.TpmAsymmetricKeyParams{
-		TpmAlgId:              consts.TPM_ALG_RSA,
-		TpmAlgEncScheme:       consts.TPM_ALG_ID_SHA256,
-		TpmAlgSignatureScheme: consts.TPM_SS_NONE,
-		KeyLength:             2048,
-		PrimesCount:           2,
-		ExponentSize:          0,
+		asymmetricKeyParams = model.TpmAsymmetricKeyParams{
+			TpmAlgId:              consts.TPM_ALG_RSA,
+			TpmAlgEncScheme:       consts.TPM_ALG_ID_SHA256,
+			TpmAlgSignatureScheme: consts.TPM_SS_NONE,
+			KeyLength:             2048,
+			PrimesCount:           2,
+			ExponentSize:          0,
+		}
+	case *ecdsa.PublicKey:
+		// Encrypt the symmetric key with a key derived from an ECDH shared secret
+		asymmetricBytes, err = tpm2utils.EncryptAsymEcdh(cipherKey, key)
+		if err != nil {
+			return model.IdentityChallengePayload{}, errors.Wrap(err, "privacyca/privacyca_tpm2:GetIdentityChallengeRequest() Error while encrypting symmetric key")
+		}
+
+		asymmetricKeyParams = model.TpmAsymmetricKeyParams{
+			TpmAlgId:              consts.TPM_ALG_ID_ECC,
+			TpmAlgEncScheme:       consts.TPM_ALG_ID_ECDH,
+			TpmAlgSignatureScheme: consts.TPM_SS_NONE,
+			KeyLength:             key.Curve.Params().BitSize,
+		}
+	default:
+		return model.IdentityChallengePayload{}, errors.New("privacyca/privacyca_tpm2:GetIdentityChallengeRequest() Unsupported privacy CA key algorithm")
 	}
 
 	identityChallengePayload := model.IdentityChallengePayload{
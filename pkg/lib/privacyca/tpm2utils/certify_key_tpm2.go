@@ -8,6 +8,7 @@ package tpm2utils
 import (
 	"bytes"
 	"crypto"
+	"crypto/ecdsa"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
@@ -17,6 +18,7 @@ import (
 	"encoding/hex"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/privacyca/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/tpm2"
 	model "github.com/intel-secl/intel-secl/v4/pkg/model/wlagent"
 	"github.com/pkg/errors"
 	"math/big"
@@ -34,9 +36,7 @@ func (certifyKey20 *CertifyKey20) IsCertifiedKeySignatureValid(aikCert *x509.Cer
 	defaultLog.Trace("tpm2utils/certify_key_tpm2:IsCertifiedKeySignatureValid() Entering")
 	defer defaultLog.Trace("tpm2utils/certify_key_tpm2:IsCertifiedKeySignatureValid() Leaving")
 
-	var signedSignatureBytes []byte
 	tpmCertifyKeyBytes := certifyKey20.RegKeyInfo.TpmCertifyKey
-	tpmCertifyKeySignatureBytes := certifyKey20.RegKeyInfo.TpmCertifyKeySignature
 
 	var tpm2CertifyKey Tpm2CertifiedKey
 	err := tpm2CertifyKey.PopulateTpmCertifyKey20(certifyKey20.RegKeyInfo.TpmCertifyKey)
@@ -44,31 +44,13 @@ func (certifyKey20 *CertifyKey20) IsCertifiedKeySignatureValid(aikCert *x509.Cer
 		return false, errors.New("tpm2utils/certify_key_tpm2:IsCertifiedKeySignatureValid() Error populating TPM Certify Key")
 	}
 
-	hashAlg, _, err := tpm2CertifyKey.GetTpmtHashAlgDigest()
+	// the certify key signature is a TPMT_SIGNATURE made with the AIK, RSASSA or RSAPSS for RSA AIKs and ECDSA for ECC AIKs
+	signature, _, err := tpm2.DecodeSignature(certifyKey20.RegKeyInfo.TpmCertifyKeySignature)
 	if err != nil {
-		return false, errors.New("tpm2utils/certify_key_tpm2:IsCertifiedKeySignatureValid() Error while getting hash algorithm from tpm certificate")
+		return false, errors.Wrap(err, "tpm2utils/certify_key_tpm2:IsCertifiedKeySignatureValid() Error parsing certify key signature")
 	}
 
-	if len(tpmCertifyKeySignatureBytes) > 256 {
-		defaultLog.Debug("tpm2utils/certify_key_tpm2:IsCertifiedKeySignatureValid() Length of certifyKeySignatureBlob is larger then 256, TPM 2.0. Will only parse out the required 256 bytes:")
-		signedSignatureBytes = make([]byte, 256)
-		signedSignatureBytes = tpmCertifyKeySignatureBytes[len(tpmCertifyKeySignatureBytes)-256:]
-	} else {
-		return false, errors.New("tpm2utils/certify_key_tpm2:IsCertifiedKeySignatureValid() Length of certifyKeySignatureBlob is 256 or less, TPM 1.2")
-	}
-
-	aikRsaPubKey := aikCert.PublicKey.(*rsa.PublicKey)
-	if hashAlg != constants.TPM_ALG_ID_SHA256 {
-		return false, errors.Errorf("tpm2utils/certify_key_tpm2:IsCertifiedKeySignatureValid() Unsupported hash algorithm, hash alg ID: %d", hashAlg)
-	}
-
-	h := sha256.New()
-	_, err = h.Write(tpmCertifyKeyBytes)
-	if err != nil {
-		return false, errors.Wrap(err, "tpm2utils/certify_key_tpm2:IsCertifiedKeySignatureValid() Error writing key")
-	}
-	computedDigest := h.Sum(nil)
-	err = rsa.VerifyPKCS1v15(aikRsaPubKey, crypto.SHA256, computedDigest, signedSignatureBytes)
+	err = signature.Verify(tpmCertifyKeyBytes, aikCert.PublicKey)
 	if err != nil {
 		return false, errors.Wrap(err, "tpm2utils/certify_key_tpm2:IsCertifiedKeySignatureValid() Error during signature verification.")
 	}
@@ -84,19 +66,25 @@ func (certifyKey20 *CertifyKey20) ValidateNameDigest() error {
 	tcgCertificate := certifyKey20.RegKeyInfo.TpmCertifyKey
 	padding, _ := hex.DecodeString(constants.Tpm2NameDigestPrefixPadding)
 	endPadding, _ := hex.DecodeString(constants.Tpm2NameDigestSuffixPadding)
+	// the name digest is the last byte of the size of the name followed by the name and padded to a fixed size
+	nameDigestSize := len(padding) + sha256.Size + len(endPadding)
 
 	var tpmCertifyKey20 Tpm2CertifiedKey
 	err := tpmCertifyKey20.PopulateTpmCertifyKey20(tcgCertificate)
 	if err != nil {
 		return errors.Wrap(err, "tpm2utils/certify_key_tpm2:ValidateNameDigest() Error populating TPM Certify Key")
 	}
-	_, digest, err := tpmCertifyKey20.GetTpmtHashAlgDigest()
+	hashAlg, digest, err := tpmCertifyKey20.GetTpmtHashAlgDigest()
 	if err != nil {
 		return errors.Wrap(err, "tpm2utils/certify_key_tpm2:ValidateNameDigest() Error while extracting digest from tpm certified key")
 	}
-	digest = append(padding, digest...)
-	digest = append(digest, endPadding...)
-	if !bytes.Equal(digest, nameDigest) {
+	if len(padding)+len(digest) > nameDigestSize {
+		return errors.New("tpm2utils/certify_key_tpm2:ValidateNameDigest() Digest in tpm certified key blob is too large")
+	}
+	expectedNameDigest := []byte{byte(len(digest) + 2), byte(hashAlg >> 8), byte(hashAlg)}
+	expectedNameDigest = append(expectedNameDigest, digest...)
+	expectedNameDigest = append(expectedNameDigest, make([]byte, nameDigestSize-len(expectedNameDigest))...)
+	if !bytes.Equal(expectedNameDigest, nameDigest) {
 		return errors.New("tpm2utils/certify_key_tpm2:ValidateNameDigest() Name digest does not  match with digest in tpm certified key blob")
 	}
 	return nil
@@ -175,7 +163,24 @@ func (certifyKey20 *CertifyKey20) GetPublicKeyFromModulus() (*rsa.PublicKey, err
 	return &pubKey, nil
 }
 
-func (certifyKey20 *CertifyKey20) CertifyKey(caCert *x509.Certificate, rsaPubKey *rsa.PublicKey, caKey *rsa.PrivateKey, cn string) ([]byte, error) {
+// GetPublicKey returns the public key of the certified key from its public area, RSA or ECC
+func (certifyKey20 *CertifyKey20) GetPublicKey() (crypto.PublicKey, error) {
+	defaultLog.Trace("tpm2utils/certify_key_tpm2:GetPublicKey() Entering")
+	defer defaultLog.Trace("tpm2utils/certify_key_tpm2:GetPublicKey() Leaving")
+
+	pubKeyMod := certifyKey20.RegKeyInfo.PublicKeyModulus
+	if len(pubKeyMod) < 2 {
+		return nil, errors.New("tpm2utils/certify_key_tpm2:GetPublicKey() Received tpm key public area is too short")
+	}
+	//remove first two bytes that represent the public area size
+	tpm2Public, err := ParseTpm2Public(pubKeyMod[2:])
+	if err != nil {
+		return nil, errors.Wrap(err, "tpm2utils/certify_key_tpm2:GetPublicKey() Error parsing tpm key public area")
+	}
+	return tpm2Public.PublicKey, nil
+}
+
+func (certifyKey20 *CertifyKey20) CertifyKey(caCert *x509.Certificate, pubKey crypto.PublicKey, caKey crypto.Signer, cn string) ([]byte, error) {
 	defaultLog.Trace("tpm2utils/certify_key_tpm2:CertifyKey() Entering")
 	defer defaultLog.Trace("tpm2utils/certify_key_tpm2:CertifyKey() Leaving")
 
@@ -192,15 +197,22 @@ func (certifyKey20 *CertifyKey20) CertifyKey(caCert *x509.Certificate, rsaPubKey
 		Subject: pkix.Name{
 			CommonName: cn,
 		},
-		SignatureAlgorithm: x509.SHA384WithRSA,
-		PublicKey:          rsaPubKey,
-		NotBefore:          time.Now(),
-		NotAfter:           time.Now().AddDate(constants.HOST_KEYS_CERT_VALIDITY, 0, 0),
-		KeyUsage:           x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
-		ExtraExtensions:    extensions,
+		PublicKey:       pubKey,
+		NotBefore:       time.Now(),
+		NotAfter:        time.Now().AddDate(constants.HOST_KEYS_CERT_VALIDITY, 0, 0),
+		KeyUsage:        x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtraExtensions: extensions,
+	}
+	// the signature algorithm is left to the default of the CA key unless it is RSA
+	if _, ok := caKey.Public().(*rsa.PublicKey); ok {
+		csrTemplate.SignatureAlgorithm = x509.SHA384WithRSA
+	}
+	// ECC keys do not encipher keys, they agree on them
+	if _, ok := pubKey.(*ecdsa.PublicKey); ok {
+		csrTemplate.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyAgreement
 	}
 
-	certificate, err := x509.CreateCertificate(rand.Reader, &csrTemplate, caCert, rsaPubKey, caKey)
+	certificate, err := x509.CreateCertificate(rand.Reader, &csrTemplate, caCert, pubKey, caKey)
 	if err != nil {
 		return nil, errors.Wrap(err, "tpm2utils/certify_key_tpm2:CertifyKey() Cannot create certificate")
 	}
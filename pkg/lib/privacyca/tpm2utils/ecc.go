@@ -0,0 +1,135 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package tpm2utils
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
+	consts "github.com/intel-secl/intel-secl/v4/pkg/lib/privacyca/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/tpm2"
+	"github.com/pkg/errors"
+)
+
+// KDFe is the key derivation function of the TPM for ECDH shared secrets, TPM 2.0 Part 1 section 11.4.10.3
+func KDFe(hashAlg crypto.Hash, z []byte, label string, partyUInfo, partyVInfo []byte, sizeInBits int) ([]byte, error) {
+	defaultLog.Trace("privacyca/tpm2utils/ecc:KDFe() Entering")
+	defer defaultLog.Trace("privacyca/tpm2utils/ecc:KDFe() Leaving")
+
+	if !isSupportedHashAlgorithm(hashAlg) {
+		return nil, errors.Errorf("privacyca/tpm2utils/ecc:KDFe() Algorithm: %s, is not a supported hashing algorithm", crypt.GetHashingAlgorithmName(hashAlg))
+	}
+
+	symBytesLen := (sizeInBits + 7) / 8
+	var outBuf []byte
+	for counter := uint32(1); len(outBuf) < symBytesLen; counter++ {
+		h := hashAlg.New()
+		counterBytes := make([]byte, 4)
+		binary.BigEndian.PutUint32(counterBytes, counter)
+		h.Write(counterBytes)
+		h.Write(z)
+		// the label includes its terminating null
+		h.Write([]byte(label))
+		h.Write([]byte{0})
+		h.Write(partyUInfo)
+		h.Write(partyVInfo)
+		outBuf = h.Sum(outBuf)
+	}
+	outBuf = outBuf[:symBytesLen]
+
+	if (sizeInBits % 8) != 0 {
+		outBuf[0] &= byte((1 << uint(sizeInBits%8)) - 1)
+	}
+	return outBuf, nil
+}
+
+// eccSecret generates an ephemeral key on the curve of the public key and derives a seed from the ECDH shared secret,
+// it returns the seed and the TPMS_ECC_POINT of the ephemeral public key the owner of the key derives it from
+func eccSecret(pubKey *ecdsa.PublicKey, hashAlg crypto.Hash, label string) ([]byte, []byte, error) {
+	ephemeralKey, err := ecdsa.GenerateKey(pubKey.Curve, rand.Reader)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "privacyca/tpm2utils/ecc:eccSecret() Error generating ephemeral key")
+	}
+	seed, err := eccSeed(ephemeralKey, pubKey, &ephemeralKey.PublicKey, pubKey, hashAlg, label)
+	if err != nil {
+		return nil, nil, err
+	}
+	point := new(bytes.Buffer)
+	writeTpmsEccPoint(point, pubKey.Curve, ephemeralKey.X, ephemeralKey.Y)
+	return seed, point.Bytes(), nil
+}
+
+// eccSeed derives the seed from the ECDH shared secret of the private key and the public key of the other party,
+// partyU is the ephemeral public key and partyV the static one
+func eccSeed(privKey *ecdsa.PrivateKey, pubKey, partyU, partyV *ecdsa.PublicKey, hashAlg crypto.Hash, label string) ([]byte, error) {
+	ecdhPrivKey, err := privKey.ECDH()
+	if err != nil {
+		return nil, errors.Wrap(err, "privacyca/tpm2utils/ecc:eccSeed() Invalid private key")
+	}
+	ecdhPubKey, err := pubKey.ECDH()
+	if err != nil {
+		return nil, errors.Wrap(err, "privacyca/tpm2utils/ecc:eccSeed() Invalid public key")
+	}
+	z, err := ecdhPrivKey.ECDH(ecdhPubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "privacyca/tpm2utils/ecc:eccSeed() Error computing shared secret")
+	}
+
+	size := (privKey.Curve.Params().BitSize + 7) / 8
+	return KDFe(hashAlg, z, label, partyU.X.FillBytes(make([]byte, size)), partyV.X.FillBytes(make([]byte, size)), hashAlg.Size()*8)
+}
+
+// EncryptAsymEcdh encrypts the payload for the owner of the ECC key, the key is derived from an ECDH shared secret the
+// way the TPM shares secrets and the payload is encrypted with AES GCM. The ephemeral public key precedes the
+// encrypted payload
+func EncryptAsymEcdh(payload []byte, pubKey *ecdsa.PublicKey) ([]byte, error) {
+	defaultLog.Trace("privacyca/tpm2utils/ecc:EncryptAsymEcdh() Entering")
+	defer defaultLog.Trace("privacyca/tpm2utils/ecc:EncryptAsymEcdh() Leaving")
+
+	seed, point, err := eccSecret(pubKey, crypto.SHA256, consts.IDENTITY)
+	if err != nil {
+		return nil, err
+	}
+	encryptedPayload, err := crypt.AesEncrypt(payload, seed)
+	if err != nil {
+		return nil, errors.Wrap(err, "privacyca/tpm2utils/ecc:EncryptAsymEcdh() Error encrypting payload")
+	}
+	return append(point, encryptedPayload...), nil
+}
+
+// decryptAsymEcdh decrypts the payload encrypted by EncryptAsymEcdh with the private part of the ECC key
+func decryptAsymEcdh(ciphertext []byte, privKey *ecdsa.PrivateKey) ([]byte, error) {
+	defaultLog.Trace("privacyca/tpm2utils/ecc:decryptAsymEcdh() Entering")
+	defer defaultLog.Trace("privacyca/tpm2utils/ecc:decryptAsymEcdh() Leaving")
+
+	xBytes, yBytes, pointLen, err := tpm2.DecodeEccPoint(ciphertext)
+	if err != nil {
+		return nil, errors.Wrap(err, "privacyca/tpm2utils/ecc:decryptAsymEcdh() Error reading ephemeral key")
+	}
+	x, y := bigInt(xBytes), bigInt(yBytes)
+	if !privKey.Curve.IsOnCurve(x, y) {
+		return nil, errors.New("privacyca/tpm2utils/ecc:decryptAsymEcdh() Ephemeral key is not on the curve")
+	}
+	ephemeralKey := &ecdsa.PublicKey{Curve: privKey.Curve, X: x, Y: y}
+	seed, err := eccSeed(privKey, ephemeralKey, ephemeralKey, &privKey.PublicKey, crypto.SHA256, consts.IDENTITY)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := crypt.AesDecrypt(ciphertext[pointLen:], seed)
+	if err != nil {
+		return nil, errors.Wrap(err, "privacyca/tpm2utils/ecc:decryptAsymEcdh() Error decrypting payload")
+	}
+	return payload, nil
+}
+
+func bigInt(b []byte) *big.Int {
+	return new(big.Int).SetBytes(b)
+}
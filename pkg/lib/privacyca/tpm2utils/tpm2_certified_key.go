@@ -6,8 +6,8 @@
 package tpm2utils
 
 import (
-	"bytes"
 	"encoding/binary"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/tpm2"
 	"github.com/pkg/errors"
 )
 
@@ -47,55 +47,26 @@ func (tpm2CertifiedKey *Tpm2CertifiedKey) PopulateTpmCertifyKey20(tpmCertifiedKe
 	defaultLog.Trace("tpm2utils/tpm2_certified_key:PopulateTpmCertifyKey20() Entering")
 	defer defaultLog.Trace("tpm2utils/tpm2_certified_key:PopulateTpmCertifyKey20() Leaving")
 
-	buf := bytes.NewBuffer(tpmCertifiedKey)
-	err := binary.Read(buf, binary.BigEndian, &tpm2CertifiedKey.Magic)
+	attest, err := tpm2.DecodeAttest(tpmCertifiedKey)
 	if err != nil {
-		return errors.Wrap(err, "tpm2utils/tpm2_certified_key:Error reading magic")
+		return errors.Wrap(err, "tpm2utils/tpm2_certified_key:Error decoding certified key")
 	}
-	err = binary.Read(buf, binary.BigEndian, &tpm2CertifiedKey.Type)
-	if err != nil {
-		return errors.Wrap(err, "tpm2utils/tpm2_certified_key:Error reading key type")
-	}
-
-	err = binary.Read(buf, binary.BigEndian, &tpm2CertifiedKey.Tpm2bName.Size)
-	if err != nil {
-		return errors.Wrap(err, "tpm2utils/tpm2_certified_key:Error reading key name size")
-	}
-	tpm2CertifiedKey.Tpm2bName.Name = buf.Next(int(tpm2CertifiedKey.Tpm2bName.Size))
-
-	err = binary.Read(buf, binary.BigEndian, &tpm2CertifiedKey.Tpm2bData.Size)
-	if err != nil {
-		return errors.Wrap(err, "tpm2utils/tpm2_certified_key:Error reading external info size")
-	}
-	tpm2CertifiedKey.Tpm2bData.Buffer = buf.Next(int(tpm2CertifiedKey.Tpm2bData.Size))
-
-	err = binary.Read(buf, binary.BigEndian, &tpm2CertifiedKey.TpmsClockInfo.Clock)
-	if err != nil {
-		return errors.Wrap(err, "tpm2utils/tpm2_certified_key:Error reading clock")
-	}
-	err = binary.Read(buf, binary.BigEndian, &tpm2CertifiedKey.TpmsClockInfo.ResetCount)
-	if err != nil {
-		return errors.Wrap(err, "tpm2utils/tpm2_certified_key:Error reading reset count")
-	}
-	err = binary.Read(buf, binary.BigEndian, &tpm2CertifiedKey.TpmsClockInfo.RestartCount)
-	if err != nil {
-		return errors.Wrap(err, "tpm2utils/tpm2_certified_key:Error reading restart count")
-	}
-	err = binary.Read(buf, binary.BigEndian, &tpm2CertifiedKey.TpmsClockInfo.Safe)
-	if err != nil {
-		return errors.Wrap(err, "tpm2utils/tpm2_certified_key:Error reading safe")
+	if attest.Certify == nil {
+		return errors.Errorf("tpm2utils/tpm2_certified_key:Attestation type 0x%x is not a certified key", attest.Type)
 	}
 
-	err = binary.Read(buf, binary.BigEndian, &tpm2CertifiedKey.FirmwareVersion)
-	if err != nil {
-		return errors.Wrap(err, "tpm2utils/tpm2_certified_key:Error reading firmware version")
+	binary.BigEndian.PutUint32(tpm2CertifiedKey.Magic[:], attest.Magic)
+	binary.BigEndian.PutUint16(tpm2CertifiedKey.Type[:], attest.Type)
+	tpm2CertifiedKey.Tpm2bName = Tpm2bName{Size: uint16(len(attest.QualifiedSigner)), Name: attest.QualifiedSigner}
+	tpm2CertifiedKey.Tpm2bData = Tpm2bData{Size: uint16(len(attest.ExtraData)), Buffer: attest.ExtraData}
+	binary.BigEndian.PutUint64(tpm2CertifiedKey.TpmsClockInfo.Clock[:], attest.ClockInfo.Clock)
+	tpm2CertifiedKey.TpmsClockInfo.ResetCount = attest.ClockInfo.ResetCount
+	tpm2CertifiedKey.TpmsClockInfo.RestartCount = attest.ClockInfo.RestartCount
+	if attest.ClockInfo.Safe {
+		tpm2CertifiedKey.TpmsClockInfo.Safe = 1
 	}
-
-	err = binary.Read(buf, binary.BigEndian, &tpm2CertifiedKey.TpmuAttest.Tpm2bName.Size)
-	if err != nil {
-		return errors.Wrap(err, "tpm2utils/tpm2_certified_key:Error reading attestation information size")
-	}
-	tpm2CertifiedKey.TpmuAttest.Tpm2bName.Name = buf.Next(int(tpm2CertifiedKey.TpmuAttest.Tpm2bName.Size))
+	binary.BigEndian.PutUint64(tpm2CertifiedKey.FirmwareVersion[:], attest.FirmwareVersion)
+	tpm2CertifiedKey.TpmuAttest.Tpm2bName = Tpm2bName{Size: uint16(len(attest.Certify.Name)), Name: attest.Certify.Name}
 	return nil
 }
 
@@ -103,15 +74,10 @@ func (tpm2CertifiedKey *Tpm2CertifiedKey) GetTpmtHashAlgDigest() (int, []byte, e
 	defaultLog.Trace("tpm2utils/tpm2_certified_key:GetTpmtHashAlgDigest() Entering")
 	defer defaultLog.Trace("tpm2utils/tpm2_certified_key:GetTpmtHashAlgDigest() Leaving")
 
-	buf := bytes.NewBuffer(tpm2CertifiedKey.TpmuAttest.Tpm2bName.Name)
-	var hashAlg int16
-	err := binary.Read(buf, binary.BigEndian, &hashAlg)
-	if err != nil {
-		return 0, nil, errors.Wrap(err, "tpm2utils/tpm2_certified_key:Error reading hash algorithm")
-	}
-	digest := buf.Next(int(tpm2CertifiedKey.TpmuAttest.Tpm2bName.Size) - 2)
-	if digest == nil {
-		return 0, nil, errors.Wrap(err, "tpm2utils/tpm2_certified_key:Digest bytes are empty")
+	// the name of the certified key is the hash algorithm followed by the digest of its public area
+	name := tpm2CertifiedKey.TpmuAttest.Tpm2bName.Name
+	if len(name) <= 2 {
+		return 0, nil, errors.New("tpm2utils/tpm2_certified_key:Digest bytes are empty")
 	}
-	return int(hashAlg), digest, nil
+	return int(binary.BigEndian.Uint16(name[:2])), name[2:], nil
 }
@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package tpm2utils
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/elliptic"
+	"encoding/binary"
+	"math/big"
+
+	consts "github.com/intel-secl/intel-secl/v4/pkg/lib/privacyca/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/tpm2"
+	"github.com/pkg/errors"
+)
+
+// Tpm2Public is the key of a TPMT_PUBLIC structure
+type Tpm2Public struct {
+	Type      uint16
+	NameAlg   uint16
+	PublicKey crypto.PublicKey
+}
+
+// ParseTpm2Public reads the key of a TPMT_PUBLIC structure, the public area of a TPM key without its size
+func ParseTpm2Public(publicArea []byte) (*Tpm2Public, error) {
+	defaultLog.Trace("tpm2utils/tpm2_public:ParseTpm2Public() Entering")
+	defer defaultLog.Trace("tpm2utils/tpm2_public:ParseTpm2Public() Leaving")
+
+	public, err := tpm2.DecodePublic(publicArea)
+	if err != nil {
+		return nil, errors.Wrap(err, "tpm2utils/tpm2_public:ParseTpm2Public() Error decoding public area")
+	}
+	if public.Ecc != nil && public.Scheme.Alg == consts.TPM_ALG_ID_ECDAA {
+		return nil, errors.New("tpm2utils/tpm2_public:ParseTpm2Public() ECDAA keys are not supported")
+	}
+	publicKey, err := public.Key()
+	if err != nil {
+		return nil, errors.Wrap(err, "tpm2utils/tpm2_public:ParseTpm2Public() Invalid public key")
+	}
+	return &Tpm2Public{Type: public.Type, NameAlg: public.NameAlg, PublicKey: publicKey}, nil
+}
+
+// Tpm2Name returns the TPM name of a public area, the name algorithm followed by the digest of the public area
+func Tpm2Name(publicArea []byte) ([]byte, error) {
+	defaultLog.Trace("tpm2utils/tpm2_public:Tpm2Name() Entering")
+	defer defaultLog.Trace("tpm2utils/tpm2_public:Tpm2Name() Leaving")
+
+	if len(publicArea) < 4 {
+		return nil, errors.New("tpm2utils/tpm2_public:Tpm2Name() Public area is too short")
+	}
+	nameAlg := binary.BigEndian.Uint16(publicArea[2:4])
+	hashAlg, err := tpm2.HashAlgorithm(nameAlg)
+	if err != nil {
+		return nil, err
+	}
+	h := hashAlg.New()
+	_, err = h.Write(publicArea)
+	if err != nil {
+		return nil, errors.Wrap(err, "tpm2utils/tpm2_public:Tpm2Name() Error writing public area")
+	}
+	return append(publicArea[2:4:4], h.Sum(nil)...), nil
+}
+
+// writeTpmsEccPoint writes the coordinates of a TPMS_ECC_POINT, padded to the size of the curve
+func writeTpmsEccPoint(buf *bytes.Buffer, curve elliptic.Curve, x, y *big.Int) {
+	size := (curve.Params().BitSize + 7) / 8
+	for _, coordinate := range []*big.Int{x, y} {
+		_ = binary.Write(buf, binary.BigEndian, uint16(size))
+		buf.Write(coordinate.FillBytes(make([]byte, size)))
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"crypto"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/ecdsa"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/rsa"
@@ -26,7 +27,7 @@ import (
 
 func isSupportedAsymAlgorithm(pubKey crypto.PublicKey) bool {
 	switch pubKey.(type) {
-	case *rsa.PublicKey:
+	case *rsa.PublicKey, *ecdsa.PublicKey:
 		return true
 	default:
 		return false
@@ -35,7 +36,7 @@ func isSupportedAsymAlgorithm(pubKey crypto.PublicKey) bool {
 
 func isSupportedHashAlgorithm(hashAlg crypto.Hash) bool {
 	switch hashAlg {
-	case crypto.SHA256:
+	case crypto.SHA256, crypto.SHA384:
 		return true
 	default:
 		return false
@@ -95,8 +96,8 @@ func MakeCredential(ekPubKey crypto.PublicKey, symmetricAlgorithm string, symKey
 			}
 
 			switch nameAlgorithm {
-			case crypto.SHA256:
-				encryptedSecret, err := rsa.EncryptOAEP(sha256.New(), bytes.NewBuffer(asymKey), ekPubKey.(*rsa.PublicKey), secretData, identityBuf.Bytes())
+			case crypto.SHA256, crypto.SHA384:
+				encryptedSecret, err := rsa.EncryptOAEP(nameAlgorithm.New(), bytes.NewBuffer(asymKey), ekPubKey.(*rsa.PublicKey), secretData, identityBuf.Bytes())
 				if err != nil {
 					return types.Tpm2Credential{}, err
 				}
@@ -114,6 +115,18 @@ func MakeCredential(ekPubKey crypto.PublicKey, symmetricAlgorithm string, symKey
 			}
 		}
 		break
+	case *ecdsa.PublicKey:
+		//Generate the seed from an ECDH shared secret with the EK, the secret is the ephemeral public key
+		secretData, encryptedSecret, err := eccSecret(ekPubKey.(*ecdsa.PublicKey), nameAlgorithm, consts.IDENTITY)
+		if err != nil {
+			return types.Tpm2Credential{}, errors.Wrap(err, "privacyca/tpm2utils/utils:MakeCredential() Unable to generate ECC secret")
+		}
+		seed = secretData
+		err = binary.Write(encryptedSecretByteBuffer, binary.BigEndian, uint16(len(encryptedSecret)))
+		if err != nil {
+			return types.Tpm2Credential{}, errors.Wrapf(err, "privacyca/tpm2utils/utils:MakeCredential() Failed to write secret size")
+		}
+		encryptedSecretByteBuffer.Write(encryptedSecret)
 	default:
 		return types.Tpm2Credential{}, errors.New("privacyca/tpm2utils/utils:MakeCredential() Key Algorithm is not currently supported")
 	}
@@ -145,12 +158,8 @@ func MakeCredential(ekPubKey crypto.PublicKey, symmetricAlgorithm string, symKey
 		return types.Tpm2Credential{}, err
 	}
 
-	if nameAlgorithm != crypto.SHA256 {
-		return types.Tpm2Credential{}, errors.New("privacyca/tpm2utils/utils:MakeCredential() Unsupported algorithm for hmac")
-	}
-
-	//Calculate hmac sha256 digest of encryptedCredential and aikName
-	mac := hmac.New(sha256.New, hmacKey)
+	//Calculate hmac digest of encryptedCredential and aikName with the name algorithm
+	mac := hmac.New(nameAlgorithm.New, hmacKey)
 	integrityBuf := new(bytes.Buffer)
 	err = binary.Write(integrityBuf, binary.BigEndian, encryptedCredential)
 	if err != nil {
@@ -201,7 +210,7 @@ func KDFa(hashAlg crypto.Hash, key []byte, label string, contextU, contextV []by
 	defaultLog.Trace("privacyca/tpm2utils/utils:KDFa() Entering")
 	defer defaultLog.Trace("privacyca/tpm2utils/utils:KDFa() Leaving")
 
-	if hashAlg != crypto.SHA256 {
+	if !isSupportedHashAlgorithm(hashAlg) {
 		return nil, errors.Errorf("privacyca/tpm2utils/utils:KDFa() Algorithm: %s, is not a supported hashing algorithm", crypt.GetHashingAlgorithmName(hashAlg))
 	}
 
@@ -217,15 +226,14 @@ func KDFa(hashAlg crypto.Hash, key []byte, label string, contextU, contextV []by
 	symBytesLen := (sizeInBits + 7) / 8
 	hashLen := hashAlg.Size()
 	counter := 0
-	curPos := 0
-	outBuf := make([]byte, symBytesLen)
+	var outBuf []byte
 
 	for symBytesLen > 0 {
 		if symBytesLen < hashLen {
 			hashLen = symBytesLen
 		}
 		counter = counter + 1
-		mac := hmac.New(sha256.New, key)
+		mac := hmac.New(hashAlg.New, key)
 		b := new(bytes.Buffer)
 		err := binary.Write(b, binary.BigEndian, int32(counter))
 		if err != nil {
@@ -267,8 +275,7 @@ func KDFa(hashAlg crypto.Hash, key []byte, label string, contextU, contextV []by
 		}
 
 		hmacHashValBytes := mac.Sum(nil)
-		outBuf = hmacHashValBytes[curPos : curPos+hashLen]
-		curPos += hashLen
+		outBuf = append(outBuf, hmacHashValBytes[:hashLen]...)
 		symBytesLen -= hashLen
 	}
 
@@ -384,6 +391,12 @@ func Tpm2DecryptAsym(ciphertext []byte, key crypto.PrivateKey, encScheme int, la
 	defaultLog.Trace("privacyca/tpm2utils/utils:Tpm2DecryptAsym() Entering")
 	defer defaultLog.Trace("privacyca/tpm2utils/utils:Tpm2DecryptAsym() Leaving")
 	switch encScheme {
+	case consts.TPM_ALG_ID_ECDH:
+		eccKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("privacyca/tpm2utils/utils:Tpm2DecryptAsym() ECDH scheme requires an ECC private key")
+		}
+		return decryptAsymEcdh(ciphertext, eccKey)
 	case consts.TPM_ALG_ID_SHA256:
 		var rng io.Reader
 		decryptedBytes, err := rsa.DecryptOAEP(sha256.New(), rng, key.(*rsa.PrivateKey), ciphertext, label)
@@ -12,6 +12,7 @@ import (
 	commLog "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
 	rtvalidator "github.com/mattermost/xml-roundtrip-validator"
 	dsig "github.com/russellhaering/goxmldsig"
+	"io/ioutil"
 	"strings"
 )
 
@@ -23,15 +24,29 @@ func VerifySamlSignature(samlReport, SamlCertPath, CACertDirPath string) bool {
 	log.Trace("saml/saml-verifier:VerifySamlSignature() Entering")
 	defer log.Trace("saml/saml-verifier:VerifySamlSignature() Leaving")
 
-	caCerts, err := crypt.GetCertsFromDir(CACertDirPath)
+	samlCertPem, err := ioutil.ReadFile(SamlCertPath)
 	if err != nil {
-		log.WithError(err).Errorf("saml/saml-verifier:VerifySamlSignature() Error retrieving CA certificates from %s", CACertDirPath)
+		log.WithError(err).Error("saml/saml-verifier:VerifySamlSignature() Error while retrieving SAML certificate")
 		return false
 	}
+	return VerifySamlSignatureWithCert(samlReport, samlCertPem, CACertDirPath)
+}
+
+//VerifySamlSignatureWithCert Verify Cert chain and SAML signature of the Report with the PEM encoded SAML certificate chain
+func VerifySamlSignatureWithCert(samlReport string, samlCertPem []byte, CACertDirPath string) bool {
+
+	log.Trace("saml/saml-verifier:VerifySamlSignatureWithCert() Entering")
+	defer log.Trace("saml/saml-verifier:VerifySamlSignatureWithCert() Leaving")
 
-	certPemSlice, err := crypt.GetSubjectCertsMapFromPemFile(SamlCertPath)
+	caCerts, err := crypt.GetCertsFromDir(CACertDirPath)
 	if err != nil {
-		log.WithError(err).Error("saml/saml-verifier:VerifySamlSignature() Error while retrieving SAML certificate")
+		log.WithError(err).Errorf("saml/saml-verifier:VerifySamlSignatureWithCert() Error retrieving CA certificates from %s", CACertDirPath)
+		return false
+	}
+
+	certPemSlice, err := crypt.GetSubjectCertsMapFromPem(samlCertPem)
+	if err != nil || len(certPemSlice) == 0 {
+		log.WithError(err).Error("saml/saml-verifier:VerifySamlSignatureWithCert() Error while retrieving SAML certificate")
 		return false
 	}
 
@@ -47,7 +62,7 @@ func VerifySamlSignature(samlReport, SamlCertPath, CACertDirPath string) bool {
 			if _, err := cert.Verify(verifyRootCAOpts); err != nil {
 				continue
 			} else {
-				log.Debug("saml/saml-verifier:VerifySamlSignature() SAML certificate chain verification successful")
+				log.Debug("saml/saml-verifier:VerifySamlSignatureWithCert() SAML certificate chain verification successful")
 				trustedCertChainFound = true
 				break
 			}
@@ -55,21 +70,21 @@ func VerifySamlSignature(samlReport, SamlCertPath, CACertDirPath string) bool {
 	}
 
 	if !trustedCertChainFound {
-		log.Error("saml/saml-verifier:VerifySamlSignature() Error verifying certificate chain for SAML certificate. No " +
+		log.Error("saml/saml-verifier:VerifySamlSignatureWithCert() Error verifying certificate chain for SAML certificate. No " +
 			"valid certificate chain could be found")
 		return false
 	}
 
 	pemBlock, _ := pem.Decode(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certPemSlice[0].Raw}))
 
-	log.Debug("saml/saml-verifier:VerifySamlSignature() Validating saml signature from HVS")
+	log.Debug("saml/saml-verifier:VerifySamlSignatureWithCert() Validating saml signature from HVS")
 	isValidated := validateSamlSignature(samlReport, pemBlock.Bytes)
 	if !isValidated {
-		log.Error("saml/saml-verifier:VerifySamlSignature() SAML signature verification failed")
+		log.Error("saml/saml-verifier:VerifySamlSignatureWithCert() SAML signature verification failed")
 		return false
 	}
 
-	log.Debug("saml/saml-verifier:VerifySamlSignature() Successfully validated SAML signature")
+	log.Debug("saml/saml-verifier:VerifySamlSignatureWithCert() Successfully validated SAML signature")
 	return true
 }
 
@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package tpm2
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ClockInfo is a TPMS_CLOCK_INFO
+type ClockInfo struct {
+	Clock        uint64
+	ResetCount   uint32
+	RestartCount uint32
+	Safe         bool
+}
+
+// QuoteInfo is a TPMS_QUOTE_INFO, the PCRs of a quote and the digest of their values
+type QuoteInfo struct {
+	PcrSelections []PcrSelection
+	PcrDigest     []byte
+}
+
+// CertifyInfo is a TPMS_CERTIFY_INFO, the names of a certified key
+type CertifyInfo struct {
+	Name          []byte
+	QualifiedName []byte
+}
+
+// Attest is a TPMS_ATTEST, the structure signed by the TPM. Quote or Certify is set depending on the type
+type Attest struct {
+	Magic           uint32
+	Type            uint16
+	QualifiedSigner []byte
+	ExtraData       []byte
+	ClockInfo       ClockInfo
+	FirmwareVersion uint64
+	Quote           *QuoteInfo
+	Certify         *CertifyInfo
+}
+
+// DecodeAttest decodes a TPMS_ATTEST of a quote or a certified key. The magic is not checked, it is up to the caller
+// to reject the structures which were not generated by the TPM
+func DecodeAttest(tpmsAttest []byte) (*Attest, error) {
+	defaultLog.Trace("tpm2/attest:DecodeAttest() Entering")
+	defer defaultLog.Trace("tpm2/attest:DecodeAttest() Leaving")
+
+	d := newDecoder(tpmsAttest)
+	attest, err := d.attest()
+	if err != nil {
+		return nil, errors.Wrap(err, "tpm2/attest:DecodeAttest() Error decoding attestation")
+	}
+	if err = d.end("attestation"); err != nil {
+		return nil, errors.Wrap(err, "tpm2/attest:DecodeAttest() Error decoding attestation")
+	}
+	return attest, nil
+}
+
+func (d *decoder) attest() (*Attest, error) {
+	var attest Attest
+	var err error
+	if attest.Magic, err = d.uint32("magic"); err != nil {
+		return nil, err
+	}
+	if attest.Type, err = d.uint16("type"); err != nil {
+		return nil, err
+	}
+	if attest.QualifiedSigner, err = d.tpm2b("qualified signer"); err != nil {
+		return nil, err
+	}
+	if attest.ExtraData, err = d.tpm2b("extra data"); err != nil {
+		return nil, err
+	}
+	if attest.ClockInfo.Clock, err = d.uint64("clock"); err != nil {
+		return nil, err
+	}
+	if attest.ClockInfo.ResetCount, err = d.uint32("reset count"); err != nil {
+		return nil, err
+	}
+	if attest.ClockInfo.RestartCount, err = d.uint32("restart count"); err != nil {
+		return nil, err
+	}
+	safe, err := d.uint8("safe")
+	if err != nil {
+		return nil, err
+	}
+	if safe > 1 {
+		return nil, errors.Errorf("invalid safe value %d", safe)
+	}
+	attest.ClockInfo.Safe = safe == 1
+	if attest.FirmwareVersion, err = d.uint64("firmware version"); err != nil {
+		return nil, err
+	}
+
+	switch attest.Type {
+	case TpmStAttestQuote:
+		var quote QuoteInfo
+		if quote.PcrSelections, err = d.pcrSelectionList(); err != nil {
+			return nil, err
+		}
+		if quote.PcrDigest, err = d.tpm2b("PCR digest"); err != nil {
+			return nil, err
+		}
+		attest.Quote = &quote
+	case TpmStAttestCertify:
+		var certify CertifyInfo
+		if certify.Name, err = d.tpm2b("name"); err != nil {
+			return nil, err
+		}
+		if certify.QualifiedName, err = d.tpm2b("qualified name"); err != nil {
+			return nil, err
+		}
+		attest.Certify = &certify
+	default:
+		return nil, errors.Errorf("unsupported attestation type 0x%x", attest.Type)
+	}
+	return &attest, nil
+}
@@ -0,0 +1,21 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package tpm2
+
+const (
+	// TpmGeneratedValue is the magic of the structures signed by the TPM, TPM_GENERATED_VALUE
+	TpmGeneratedValue = 0xff544347
+
+	// TPM_ST values of the attestation structures
+	TpmStAttestCertify = 0x8017
+	TpmStAttestQuote   = 0x8018
+
+	// MaxPcrBanks bounds the number of PCR selections of a TPML_PCR_SELECTION, the TPM limits it to the number of
+	// hash algorithms it implements
+	MaxPcrBanks = 16
+	// MaxPcrSelectSize bounds the size of the PCR bitmap of a TPMS_PCR_SELECTION, 4 bytes cover 32 PCRs
+	MaxPcrSelectSize = 4
+)
@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+// Package tpm2 decodes the TPM 2.0 structures sent by the trust agent, quotes, attestations, signatures and public
+// areas. The structures come from the network, every length is checked against the remaining bytes and malformed
+// structures are reported as errors.
+package tpm2
+
+import (
+	"encoding/binary"
+
+	commLog "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
+	"github.com/pkg/errors"
+)
+
+var defaultLog = commLog.GetDefaultLogger()
+
+// ErrTruncated is the cause of the errors returned when a structure ends before one of its fields
+var ErrTruncated = errors.New("TPM structure is truncated")
+
+// decoder reads the big endian fields of a TPM structure
+type decoder struct {
+	buf []byte
+	pos int
+}
+
+func newDecoder(buf []byte) *decoder {
+	return &decoder{buf: buf}
+}
+
+// remaining returns the number of bytes left to read
+func (d *decoder) remaining() int {
+	return len(d.buf) - d.pos
+}
+
+// next returns the next n bytes, they are not copied
+func (d *decoder) next(n int, field string) ([]byte, error) {
+	if n < 0 || d.remaining() < n {
+		return nil, errors.Wrapf(ErrTruncated, "reading %s, %d bytes expected, %d bytes left", field, n, d.remaining())
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) uint8(field string) (uint8, error) {
+	b, err := d.next(1, field)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (d *decoder) uint16(field string) (uint16, error) {
+	b, err := d.next(2, field)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(b), nil
+}
+
+func (d *decoder) uint32(field string) (uint32, error) {
+	b, err := d.next(4, field)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
+
+func (d *decoder) uint64(field string) (uint64, error) {
+	b, err := d.next(8, field)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// tpm2b reads a sized buffer, the size is a uint16
+func (d *decoder) tpm2b(field string) ([]byte, error) {
+	size, err := d.uint16(field + " size")
+	if err != nil {
+		return nil, err
+	}
+	return d.next(int(size), field)
+}
+
+// end checks the whole structure has been read
+func (d *decoder) end(structure string) error {
+	if d.remaining() != 0 {
+		return errors.Errorf("%d unexpected bytes after %s", d.remaining(), structure)
+	}
+	return nil
+}
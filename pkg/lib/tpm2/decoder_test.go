@@ -0,0 +1,142 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package tpm2
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/xml"
+	"io/ioutil"
+	"testing"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/privacyca/constants"
+	taModel "github.com/intel-secl/intel-secl/v4/pkg/model/ta"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// the TPMS_ATTEST of a certified binding key and the TPM2B_PUBLIC of the key
+var tpmCertifyKey, _ = base64.StdEncoding.DecodeString("AJH/VENHgBcAIgAL1+gJcMsLhnCM31xJ1WGMdOfCoXGk+Lj9/cGDlbUGYdEABAD/VaoAAAAAhGT5nQAAAAgAAAAAAQAHACgACDIAACIAC/gUMncc7bnLWVlrtGaGT0WVlFXdxNwNVJW1DT1it8RkACIACyjbYjRmoPAu54z17ffnj+YxzjFx3yO6T2fqKRKy25vc")
+var bindingKeyPublic, _ = base64.StdEncoding.DecodeString("ARYAAQALAAIAcgAAABAAEAgAAAAAAAEAnY4+SdHJYtd2cWgZWJPZYlG77k4nty/4qTXW7ovbx08PCRI2XtiW3x8DaGEOsjpv43vc4GBXOyAP/zZxCBBUTnh8ZxbrQY33vEvK51phPC1ADabMpcmvgntNXOUbYOL95raQpAbA0+ksKpHlA0s+Yx6T5AsLypCYVoCQ+GQoN0pQu9JTmhlo7/+KVP87hmqMiziKr3dYrBDrDlwDd1+UgrN6UvweHNOtct5xKkXa5WCF2GrXTaDZNZpHyL6AXtblGkrnVFbfNGiIuOy1717YqjyCEikXmj1Ar67XogGS0/KG1Aug2C2xEI1wDEZUvkpHg9rU8AAbWhkp756xKFhIcw==")
+
+func sampleQuote(t testing.TB) []byte {
+	var tpmQuoteResponse taModel.TpmQuoteResponse
+	b, err := ioutil.ReadFile("../host-connector/test/sample_tpm_quote.xml")
+	assert.NoError(t, err)
+	err = xml.Unmarshal(b, &tpmQuoteResponse)
+	assert.NoError(t, err)
+	quote, err := base64.StdEncoding.DecodeString(tpmQuoteResponse.Quote)
+	assert.NoError(t, err)
+	return quote
+}
+
+func TestDecodeQuote(t *testing.T) {
+	quote, err := DecodeQuote(sampleQuote(t))
+	assert.NoError(t, err)
+
+	assert.Equal(t, uint32(TpmGeneratedValue), quote.Attest.Magic)
+	assert.Equal(t, uint16(TpmStAttestQuote), quote.Attest.Type)
+	assert.Nil(t, quote.Attest.Certify)
+	assert.Len(t, quote.Attest.Quote.PcrDigest, 32)
+	assert.NotEmpty(t, quote.Attest.Quote.PcrSelections)
+	assert.Equal(t, uint16(constants.TPM_ALG_ID_RSASSA), quote.Signature.SigAlg)
+	assert.Len(t, quote.Signature.Signature, 256)
+
+	// the values of all the selected PCRs follow the signature
+	pcrValuesLen := 0
+	for _, pcrSelection := range quote.Attest.Quote.PcrSelections {
+		hashAlg, err := HashAlgorithm(pcrSelection.HashAlg)
+		assert.NoError(t, err)
+		pcrValuesLen += hashAlg.Size() * len(pcrSelection.Pcrs())
+	}
+	assert.Equal(t, pcrValuesLen, len(quote.PcrValues))
+}
+
+func TestDecodeTruncatedQuote(t *testing.T) {
+	b := sampleQuote(t)
+	quote, err := DecodeQuote(b)
+	assert.NoError(t, err)
+	quoteLen := len(b) - len(quote.PcrValues)
+
+	for i := 0; i < quoteLen; i++ {
+		_, err := DecodeQuote(b[:i])
+		assert.Error(t, err, "quote truncated to %d bytes", i)
+	}
+}
+
+func TestDecodeQuoteHostileLengths(t *testing.T) {
+	b := append([]byte{}, sampleQuote(t)...)
+	// the size of the attestation exceeds the quote
+	b[0], b[1] = 0xff, 0xff
+	_, err := DecodeQuote(b)
+	assert.Error(t, err)
+	assert.Equal(t, ErrTruncated, errors.Cause(err))
+
+	// the PCR selection count exceeds the limit
+	b = append([]byte{}, sampleQuote(t)...)
+	attest, err := DecodeAttest(b[2 : 2+int(b[0])<<8+int(b[1])])
+	assert.NoError(t, err)
+	countPos := 2 + 4 + 2 + 2 + len(attest.QualifiedSigner) + 2 + len(attest.ExtraData) + 17 + 8
+	b[countPos] = 0xff
+	_, err = DecodeQuote(b)
+	assert.Error(t, err)
+}
+
+func TestDecodeCertifyAttest(t *testing.T) {
+	attest, err := DecodeAttest(tpmCertifyKey[2:])
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(TpmStAttestCertify), attest.Type)
+	assert.Nil(t, attest.Quote)
+	assert.Len(t, attest.Certify.Name, 34)
+
+	_, err = DecodeAttest(append(tpmCertifyKey[2:], 0))
+	assert.Error(t, err)
+
+	// a quote attestation is not a certified key
+	_, err = DecodeQuote(tpmCertifyKey)
+	assert.Error(t, err)
+}
+
+func TestDecodePublic2B(t *testing.T) {
+	public, err := DecodePublic2B(bindingKeyPublic)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(constants.TPM_ALG_ID_RSA), public.Type)
+	assert.Equal(t, uint16(constants.TPM_ALG_ID_SHA256), public.NameAlg)
+	assert.Nil(t, public.Ecc)
+
+	key, err := public.Key()
+	assert.NoError(t, err)
+	assert.Equal(t, 2048, key.(*rsa.PublicKey).N.BitLen())
+	assert.Equal(t, 65537, key.(*rsa.PublicKey).E)
+
+	for i := 0; i < len(bindingKeyPublic); i++ {
+		_, err := DecodePublic2B(bindingKeyPublic[:i])
+		assert.Error(t, err, "public area truncated to %d bytes", i)
+	}
+}
+
+func TestDecodeSignature(t *testing.T) {
+	signature, signatureLen, err := DecodeSignature([]byte{0x00, 0x18, 0x00, 0x0b, 0x00, 0x01, 0xaa, 0x00, 0x02, 0xbb, 0xcc, 0xdd})
+	assert.NoError(t, err)
+	assert.Equal(t, 11, signatureLen)
+	assert.Equal(t, []byte{0xaa}, signature.R)
+	assert.Equal(t, []byte{0xbb, 0xcc}, signature.S)
+
+	_, _, err = DecodeSignature([]byte{0x00, 0x14, 0x00, 0x0b, 0x01, 0x00, 0xaa})
+	assert.Error(t, err)
+	_, _, err = DecodeSignature([]byte{0x00, 0x10, 0x00, 0x0b})
+	assert.Error(t, err)
+}
+
+func TestPcrSelection(t *testing.T) {
+	pcrSelections, err := DecodePcrSelectionList([]byte{0x00, 0x00, 0x00, 0x01, 0x00, 0x0b, 0x03, 0x81, 0x00, 0x80})
+	assert.NoError(t, err)
+	assert.Len(t, pcrSelections, 1)
+	assert.Equal(t, []int{0, 7, 23}, pcrSelections[0].Pcrs())
+
+	_, err = DecodePcrSelectionList([]byte{0x00, 0x00, 0x00, 0x01, 0x00, 0x0b, 0xff})
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package tpm2
+
+import (
+	"testing"
+)
+
+// The fuzz targets run their seeds with go test, run them with go test -fuzz=FuzzDecodeQuote ./pkg/lib/tpm2/ to
+// explore. The decoders must return errors on malformed structures, never panic, and what they decode must lie within
+// the input
+
+func FuzzDecodeQuote(f *testing.F) {
+	f.Add(sampleQuote(f))
+	f.Add(tpmCertifyKey)
+	f.Fuzz(func(t *testing.T, b []byte) {
+		quote, err := DecodeQuote(b)
+		if err != nil {
+			return
+		}
+		if quote.Attest.Quote == nil {
+			t.Fatal("decoded quote has no quote information")
+		}
+		if len(quote.AttestBytes)+len(quote.PcrValues) > len(b) {
+			t.Fatal("decoded quote exceeds the input")
+		}
+		for _, pcrSelection := range quote.Attest.Quote.PcrSelections {
+			if len(pcrSelection.Pcrs()) > 8*MaxPcrSelectSize {
+				t.Fatal("decoded PCR selection exceeds the limit")
+			}
+		}
+	})
+}
+
+func FuzzDecodeAttest(f *testing.F) {
+	quote := sampleQuote(f)
+	f.Add(quote[2:])
+	f.Add(tpmCertifyKey[2:])
+	f.Fuzz(func(t *testing.T, b []byte) {
+		attest, err := DecodeAttest(b)
+		if err != nil {
+			return
+		}
+		if (attest.Quote == nil) == (attest.Certify == nil) {
+			t.Fatal("decoded attestation must be either a quote or a certified key")
+		}
+	})
+}
+
+func FuzzDecodePublic2B(f *testing.F) {
+	f.Add(bindingKeyPublic)
+	f.Fuzz(func(t *testing.T, b []byte) {
+		public, err := DecodePublic2B(b)
+		if err != nil {
+			return
+		}
+		if (public.Rsa == nil) == (public.Ecc == nil) {
+			t.Fatal("decoded public area must be either RSA or ECC")
+		}
+		// the key may be invalid, it must not panic
+		_, _ = public.Key()
+	})
+}
+
+func FuzzDecodeSignature(f *testing.F) {
+	quote := sampleQuote(f)
+	f.Add(quote[2+int(quote[0])<<8+int(quote[1]):])
+	f.Add([]byte{0x00, 0x18, 0x00, 0x0b, 0x00, 0x01, 0xaa, 0x00, 0x01, 0xbb})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		_, signatureLen, err := DecodeSignature(b)
+		if err != nil {
+			return
+		}
+		if signatureLen > len(b) {
+			t.Fatal("decoded signature exceeds the input")
+		}
+	})
+}
+
+func FuzzDecodePcrSelectionList(f *testing.F) {
+	f.Add([]byte{0x00, 0x00, 0x00, 0x01, 0x00, 0x0b, 0x03, 0xff, 0xff, 0xff})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		pcrSelections, err := DecodePcrSelectionList(b)
+		if err != nil {
+			return
+		}
+		if len(pcrSelections) > MaxPcrBanks {
+			t.Fatal("decoded PCR selection count exceeds the limit")
+		}
+	})
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package tpm2
+
+import (
+	"github.com/pkg/errors"
+)
+
+// PcrSelection is a TPMS_PCR_SELECTION, the PCRs of one bank
+type PcrSelection struct {
+	HashAlg uint16
+	// Select is the bitmap of the selected PCRs, bit i of byte j selects PCR 8*j+i
+	Select []byte
+}
+
+// Pcrs returns the indices of the selected PCRs in ascending order
+func (pcrSelection *PcrSelection) Pcrs() []int {
+	var pcrs []int
+	for pcr := 0; pcr < 8*len(pcrSelection.Select); pcr++ {
+		if pcrSelection.Select[pcr/8]&(1<<uint(pcr%8)) != 0 {
+			pcrs = append(pcrs, pcr)
+		}
+	}
+	return pcrs
+}
+
+// DecodePcrSelectionList decodes a TPML_PCR_SELECTION
+func DecodePcrSelectionList(tpmlPcrSelection []byte) ([]PcrSelection, error) {
+	defaultLog.Trace("tpm2/pcr_selection:DecodePcrSelectionList() Entering")
+	defer defaultLog.Trace("tpm2/pcr_selection:DecodePcrSelectionList() Leaving")
+
+	d := newDecoder(tpmlPcrSelection)
+	pcrSelections, err := d.pcrSelectionList()
+	if err != nil {
+		return nil, errors.Wrap(err, "tpm2/pcr_selection:DecodePcrSelectionList() Error decoding PCR selection list")
+	}
+	if err = d.end("PCR selection list"); err != nil {
+		return nil, errors.Wrap(err, "tpm2/pcr_selection:DecodePcrSelectionList() Error decoding PCR selection list")
+	}
+	return pcrSelections, nil
+}
+
+func (d *decoder) pcrSelectionList() ([]PcrSelection, error) {
+	count, err := d.uint32("PCR selection count")
+	if err != nil {
+		return nil, err
+	}
+	if count > MaxPcrBanks {
+		return nil, errors.Errorf("PCR selection count %d exceeds %d", count, MaxPcrBanks)
+	}
+
+	pcrSelections := make([]PcrSelection, count)
+	for i := range pcrSelections {
+		pcrSelections[i].HashAlg, err = d.uint16("PCR selection hash algorithm")
+		if err != nil {
+			return nil, err
+		}
+		size, err := d.uint8("PCR selection size")
+		if err != nil {
+			return nil, err
+		}
+		if size > MaxPcrSelectSize {
+			return nil, errors.Errorf("PCR selection size %d exceeds %d", size, MaxPcrSelectSize)
+		}
+		pcrSelections[i].Select, err = d.next(int(size), "PCR selection")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pcrSelections, nil
+}
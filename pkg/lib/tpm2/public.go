@@ -0,0 +1,245 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package tpm2
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"math/big"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/privacyca/constants"
+	"github.com/pkg/errors"
+)
+
+// the TPM_ALG_ID of the RSA encryption scheme, the only scheme without a hash algorithm
+const tpmAlgRsaes = 0x0015
+
+// the default public exponent of the RSA keys, used when the exponent of the public area is zero
+const defaultRsaExponent = 65537
+
+// SymDefObject is a TPMT_SYM_DEF_OBJECT, KeyBits and Mode are zero when the algorithm is TPM_ALG_NULL
+type SymDefObject struct {
+	Alg     uint16
+	KeyBits uint16
+	Mode    uint16
+}
+
+// Scheme is a signing, encryption or key derivation scheme, HashAlg is zero when the scheme has no hash algorithm and
+// Count is only used by ECDAA
+type Scheme struct {
+	Alg     uint16
+	HashAlg uint16
+	Count   uint16
+}
+
+// RsaParameters are the TPMS_RSA_PARMS and the modulus of an RSA key
+type RsaParameters struct {
+	KeyBits  uint16
+	Exponent uint32
+	Modulus  []byte
+}
+
+// EccParameters are the TPMS_ECC_PARMS and the point of an ECC key
+type EccParameters struct {
+	CurveID uint16
+	Kdf     Scheme
+	X       []byte
+	Y       []byte
+}
+
+// Public is a TPMT_PUBLIC of an RSA or ECC key, Rsa or Ecc is set depending on the type
+type Public struct {
+	Type             uint16
+	NameAlg          uint16
+	ObjectAttributes uint32
+	AuthPolicy       []byte
+	Symmetric        SymDefObject
+	Scheme           Scheme
+	Rsa              *RsaParameters
+	Ecc              *EccParameters
+}
+
+// EccCurve returns the elliptic curve of a TPM_ECC_CURVE
+func EccCurve(curveID uint16) (elliptic.Curve, error) {
+	switch curveID {
+	case constants.TPM_ECC_NIST_P256:
+		return elliptic.P256(), nil
+	case constants.TPM_ECC_NIST_P384:
+		return elliptic.P384(), nil
+	default:
+		return nil, errors.Errorf("tpm2/public:EccCurve() Unsupported ECC curve, curve ID: %d", curveID)
+	}
+}
+
+// DecodePublic decodes a TPMT_PUBLIC, the public area of a key without its size
+func DecodePublic(tpmtPublic []byte) (*Public, error) {
+	defaultLog.Trace("tpm2/public:DecodePublic() Entering")
+	defer defaultLog.Trace("tpm2/public:DecodePublic() Leaving")
+
+	d := newDecoder(tpmtPublic)
+	public, err := d.public()
+	if err != nil {
+		return nil, errors.Wrap(err, "tpm2/public:DecodePublic() Error decoding public area")
+	}
+	if err = d.end("public area"); err != nil {
+		return nil, errors.Wrap(err, "tpm2/public:DecodePublic() Error decoding public area")
+	}
+	return public, nil
+}
+
+// DecodePublic2B decodes a TPM2B_PUBLIC, the public area of a key preceded by its size
+func DecodePublic2B(tpm2bPublic []byte) (*Public, error) {
+	defaultLog.Trace("tpm2/public:DecodePublic2B() Entering")
+	defer defaultLog.Trace("tpm2/public:DecodePublic2B() Leaving")
+
+	d := newDecoder(tpm2bPublic)
+	publicArea, err := d.tpm2b("public area")
+	if err != nil {
+		return nil, errors.Wrap(err, "tpm2/public:DecodePublic2B() Error decoding public area")
+	}
+	if err = d.end("public area"); err != nil {
+		return nil, errors.Wrap(err, "tpm2/public:DecodePublic2B() Error decoding public area")
+	}
+	return DecodePublic(publicArea)
+}
+
+// DecodeEccPoint decodes the TPMS_ECC_POINT at the start of the bytes, it returns the coordinates and the number of
+// bytes the point spans
+func DecodeEccPoint(tpmsEccPoint []byte) ([]byte, []byte, int, error) {
+	d := newDecoder(tpmsEccPoint)
+	x, err := d.tpm2b("ECC point x")
+	if err != nil {
+		return nil, nil, 0, errors.Wrap(err, "tpm2/public:DecodeEccPoint() Error decoding ECC point")
+	}
+	y, err := d.tpm2b("ECC point y")
+	if err != nil {
+		return nil, nil, 0, errors.Wrap(err, "tpm2/public:DecodeEccPoint() Error decoding ECC point")
+	}
+	return x, y, d.pos, nil
+}
+
+func (d *decoder) public() (*Public, error) {
+	var public Public
+	var err error
+	if public.Type, err = d.uint16("type"); err != nil {
+		return nil, err
+	}
+	if public.NameAlg, err = d.uint16("name algorithm"); err != nil {
+		return nil, err
+	}
+	if public.ObjectAttributes, err = d.uint32("object attributes"); err != nil {
+		return nil, err
+	}
+	if public.AuthPolicy, err = d.tpm2b("auth policy"); err != nil {
+		return nil, err
+	}
+	if public.Type != constants.TPM_ALG_ID_RSA && public.Type != constants.TPM_ALG_ID_ECC {
+		return nil, errors.Errorf("unsupported key type 0x%x", public.Type)
+	}
+
+	// TPMT_SYM_DEF_OBJECT, the key bits and the mode follow the algorithm unless it is TPM_ALG_NULL
+	if public.Symmetric.Alg, err = d.uint16("symmetric algorithm"); err != nil {
+		return nil, err
+	}
+	if public.Symmetric.Alg != constants.TPM_ALG_ID_NULL {
+		if public.Symmetric.KeyBits, err = d.uint16("symmetric key bits"); err != nil {
+			return nil, err
+		}
+		if public.Symmetric.Mode, err = d.uint16("symmetric mode"); err != nil {
+			return nil, err
+		}
+	}
+
+	if public.Scheme, err = d.scheme("scheme"); err != nil {
+		return nil, err
+	}
+
+	switch public.Type {
+	case constants.TPM_ALG_ID_RSA:
+		var rsaParameters RsaParameters
+		if rsaParameters.KeyBits, err = d.uint16("RSA key bits"); err != nil {
+			return nil, err
+		}
+		if rsaParameters.Exponent, err = d.uint32("RSA exponent"); err != nil {
+			return nil, err
+		}
+		if rsaParameters.Modulus, err = d.tpm2b("RSA modulus"); err != nil {
+			return nil, err
+		}
+		public.Rsa = &rsaParameters
+
+	case constants.TPM_ALG_ID_ECC:
+		var eccParameters EccParameters
+		if eccParameters.CurveID, err = d.uint16("ECC curve"); err != nil {
+			return nil, err
+		}
+		if eccParameters.Kdf, err = d.scheme("ECC KDF scheme"); err != nil {
+			return nil, err
+		}
+		if eccParameters.X, err = d.tpm2b("ECC point x"); err != nil {
+			return nil, err
+		}
+		if eccParameters.Y, err = d.tpm2b("ECC point y"); err != nil {
+			return nil, err
+		}
+		public.Ecc = &eccParameters
+	}
+	return &public, nil
+}
+
+// scheme reads a scheme and the parameters which follow it, none for TPM_ALG_NULL and RSAES, a hash algorithm and a
+// count for ECDAA and a hash algorithm for the others
+func (d *decoder) scheme(field string) (Scheme, error) {
+	var scheme Scheme
+	var err error
+	if scheme.Alg, err = d.uint16(field); err != nil {
+		return Scheme{}, err
+	}
+	if scheme.Alg == constants.TPM_ALG_ID_NULL || scheme.Alg == tpmAlgRsaes {
+		return scheme, nil
+	}
+	if scheme.HashAlg, err = d.uint16(field + " hash algorithm"); err != nil {
+		return Scheme{}, err
+	}
+	if scheme.Alg == constants.TPM_ALG_ID_ECDAA {
+		if scheme.Count, err = d.uint16(field + " count"); err != nil {
+			return Scheme{}, err
+		}
+	}
+	return scheme, nil
+}
+
+// Key returns the public key of the public area
+func (public *Public) Key() (crypto.PublicKey, error) {
+	switch {
+	case public.Rsa != nil:
+		if len(public.Rsa.Modulus) == 0 {
+			return nil, errors.New("tpm2/public:Key() RSA modulus is empty")
+		}
+		exponent := int(public.Rsa.Exponent)
+		if exponent == 0 {
+			exponent = defaultRsaExponent
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(public.Rsa.Modulus), E: exponent}, nil
+
+	case public.Ecc != nil:
+		curve, err := EccCurve(public.Ecc.CurveID)
+		if err != nil {
+			return nil, err
+		}
+		x := new(big.Int).SetBytes(public.Ecc.X)
+		y := new(big.Int).SetBytes(public.Ecc.Y)
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("tpm2/public:Key() ECC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	default:
+		return nil, errors.Errorf("tpm2/public:Key() Unsupported key type: %d", public.Type)
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package tpm2
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Quote is a quote as sent by the trust agent, the TPM2B_ATTEST of the quote, its TPMT_SIGNATURE and the values of
+// the quoted PCRs
+type Quote struct {
+	Attest *Attest
+	// AttestBytes is the TPMS_ATTEST the signature is computed on
+	AttestBytes []byte
+	Signature   *Signature
+	// PcrValues are the values of the selected PCRs, in the order of the PCR selections
+	PcrValues []byte
+}
+
+// DecodeQuote decodes a quote of the trust agent
+func DecodeQuote(quote []byte) (*Quote, error) {
+	defaultLog.Trace("tpm2/quote:DecodeQuote() Entering")
+	defer defaultLog.Trace("tpm2/quote:DecodeQuote() Leaving")
+
+	d := newDecoder(quote)
+	attestBytes, err := d.tpm2b("attestation")
+	if err != nil {
+		return nil, errors.Wrap(err, "tpm2/quote:DecodeQuote() Error decoding quote")
+	}
+	attest, err := DecodeAttest(attestBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "tpm2/quote:DecodeQuote() Error decoding quote")
+	}
+	if attest.Quote == nil {
+		return nil, errors.Errorf("tpm2/quote:DecodeQuote() Attestation type 0x%x is not a quote", attest.Type)
+	}
+	signature, err := d.signature()
+	if err != nil {
+		return nil, errors.Wrap(err, "tpm2/quote:DecodeQuote() Error decoding quote signature")
+	}
+
+	return &Quote{
+		Attest:      attest,
+		AttestBytes: attestBytes,
+		Signature:   signature,
+		PcrValues:   d.buf[d.pos:],
+	}, nil
+}
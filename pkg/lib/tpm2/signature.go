@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package tpm2
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"math/big"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/privacyca/constants"
+	"github.com/pkg/errors"
+)
+
+// Signature is a TPMT_SIGNATURE, RSA signatures are held in Signature, ECDSA ones in R and S
+type Signature struct {
+	SigAlg    uint16
+	HashAlg   uint16
+	Signature []byte
+	R         []byte
+	S         []byte
+}
+
+// HashAlgorithm returns the hash algorithm of a TPM_ALG_ID
+func HashAlgorithm(tpmAlgID uint16) (crypto.Hash, error) {
+	switch tpmAlgID {
+	case constants.TPM_ALG_ID_SHA1:
+		return crypto.SHA1, nil
+	case constants.TPM_ALG_ID_SHA256:
+		return crypto.SHA256, nil
+	case constants.TPM_ALG_ID_SHA384:
+		return crypto.SHA384, nil
+	case constants.TPM_ALG_ID_SHA512:
+		return crypto.SHA512, nil
+	default:
+		return 0, errors.Errorf("tpm2/signature:HashAlgorithm() Unsupported hash algorithm, hash alg ID: %d", tpmAlgID)
+	}
+}
+
+// DecodeSignature decodes the TPMT_SIGNATURE at the start of the bytes, it returns the signature and the number of
+// bytes it spans
+func DecodeSignature(tpmtSignature []byte) (*Signature, int, error) {
+	defaultLog.Trace("tpm2/signature:DecodeSignature() Entering")
+	defer defaultLog.Trace("tpm2/signature:DecodeSignature() Leaving")
+
+	d := newDecoder(tpmtSignature)
+	signature, err := d.signature()
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "tpm2/signature:DecodeSignature() Error decoding signature")
+	}
+	return signature, d.pos, nil
+}
+
+func (d *decoder) signature() (*Signature, error) {
+	var signature Signature
+	var err error
+	if signature.SigAlg, err = d.uint16("signature algorithm"); err != nil {
+		return nil, err
+	}
+	if signature.HashAlg, err = d.uint16("signature hash algorithm"); err != nil {
+		return nil, err
+	}
+
+	switch signature.SigAlg {
+	case constants.TPM_ALG_ID_RSASSA, constants.TPM_ALG_ID_RSAPSS:
+		if signature.Signature, err = d.tpm2b("RSA signature"); err != nil {
+			return nil, err
+		}
+	case constants.TPM_ALG_ID_ECDSA:
+		if signature.R, err = d.tpm2b("ECDSA signature R"); err != nil {
+			return nil, err
+		}
+		if signature.S, err = d.tpm2b("ECDSA signature S"); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("unsupported signature algorithm 0x%x", signature.SigAlg)
+	}
+	return &signature, nil
+}
+
+// Verify checks the signature of the data, signed by the TPM with the private part of the public key
+func (signature *Signature) Verify(data []byte, publicKey crypto.PublicKey) error {
+	defaultLog.Trace("tpm2/signature:Verify() Entering")
+	defer defaultLog.Trace("tpm2/signature:Verify() Leaving")
+
+	hashAlg, err := HashAlgorithm(signature.HashAlg)
+	if err != nil {
+		return err
+	}
+	if hashAlg == crypto.SHA1 {
+		return errors.New("tpm2/signature:Verify() SHA1 signatures are not supported")
+	}
+	h := hashAlg.New()
+	_, err = h.Write(data)
+	if err != nil {
+		return errors.Wrap(err, "tpm2/signature:Verify() Error writing signed data")
+	}
+	digest := h.Sum(nil)
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		switch signature.SigAlg {
+		case constants.TPM_ALG_ID_RSASSA:
+			err = rsa.VerifyPKCS1v15(key, hashAlg, digest, signature.Signature)
+		case constants.TPM_ALG_ID_RSAPSS:
+			// the salt size depends on the TPM, it is found from the signature
+			err = rsa.VerifyPSS(key, hashAlg, digest, signature.Signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+		default:
+			return errors.Errorf("tpm2/signature:Verify() Signature algorithm %d does not match the RSA key", signature.SigAlg)
+		}
+	case *ecdsa.PublicKey:
+		if signature.SigAlg != constants.TPM_ALG_ID_ECDSA {
+			return errors.Errorf("tpm2/signature:Verify() Signature algorithm %d does not match the ECC key", signature.SigAlg)
+		}
+		if !ecdsa.Verify(key, digest, new(big.Int).SetBytes(signature.R), new(big.Int).SetBytes(signature.S)) {
+			err = errors.New("ECDSA verification error")
+		}
+	default:
+		return errors.New("tpm2/signature:Verify() Unsupported public key algorithm")
+	}
+	if err != nil {
+		return errors.Wrap(err, "tpm2/signature:Verify() Error during signature verification")
+	}
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/google/uuid"
 	asset_tag "github.com/intel-secl/intel-secl/v4/pkg/lib/asset-tag"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/common"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/host-connector/types"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/verifier/rules"
@@ -136,7 +137,7 @@ func getAssetTagMatchesRule(flavor *hvs.Flavor) (rules.Rule, error) {
 
 //getTagCertificateTrustedRule method will create TagCertificateTrustedRule and return the rule
 //return nil if error occurs
-func getTagCertificateTrustedRule(assetTagCACertificates *x509.CertPool, flavor *hvs.Flavor) (rules.Rule, error) {
+func getTagCertificateTrustedRule(assetTagCACertificates *x509.CertPool, revocationChecker *crypt.RevocationChecker, flavor *hvs.Flavor) (rules.Rule, error) {
 	var rule rules.Rule
 	var err error
 
@@ -145,7 +146,7 @@ func getTagCertificateTrustedRule(assetTagCACertificates *x509.CertPool, flavor
 		return nil, errors.New("'External' was not present in the flavor")
 	}
 
-	rule, err = rules.NewTagCertificateTrusted(assetTagCACertificates, &flavor.External.AssetTag.TagCertificate)
+	rule, err = rules.NewTagCertificateTrusted(assetTagCACertificates, revocationChecker, &flavor.External.AssetTag.TagCertificate)
 	if err != nil {
 		return nil, errors.Wrap(err, "Could not create the TagCertificateTrusted rule")
 	}
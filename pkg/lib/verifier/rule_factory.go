@@ -134,6 +134,7 @@ func (factory *ruleFactory) GetVerificationRules() ([]rules.Rule, string, error)
 		flavorTrusted, err := rules.NewFlavorTrusted(factory.signedFlavor,
 			factory.verifierCertificates.FlavorSigningCertificate,
 			factory.verifierCertificates.FlavorCACertificates,
+			factory.verifierCertificates.RevocationChecker,
 			flavorPart)
 
 		if err != nil {
@@ -52,7 +52,7 @@ func (builder *ruleBuilderIntelTpm20) GetAikCertificateTrustedRule(flavorPart co
 	//
 	// Add 'AikCertificateTrusted' rule...
 	//
-	aikCertificateTrusted, err := rules.NewAikCertificateTrusted(builder.verifierCertificates.PrivacyCACertificates, flavorPart)
+	aikCertificateTrusted, err := rules.NewAikCertificateTrusted(builder.verifierCertificates.PrivacyCACertificates, builder.verifierCertificates.RevocationChecker, flavorPart)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error in getting AikCertificateTrusted rule")
 	}
@@ -73,7 +73,7 @@ func (builder *ruleBuilderIntelTpm20) GetAssetTagRules() ([]rules.Rule, error) {
 	//
 	// TagCertificateTrusted
 	//
-	tagCertificateTrusted, err := getTagCertificateTrustedRule(builder.verifierCertificates.AssetTagCACertificates, &builder.signedFlavor.Flavor)
+	tagCertificateTrusted, err := getTagCertificateTrustedRule(builder.verifierCertificates.AssetTagCACertificates, builder.verifierCertificates.RevocationChecker, &builder.signedFlavor.Flavor)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error in getting TagCertificateTrusted rule")
 	}
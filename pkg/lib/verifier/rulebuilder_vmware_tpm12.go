@@ -46,7 +46,7 @@ func (builder *ruleBuilderVMWare12) GetAssetTagRules() ([]rules.Rule, error) {
 	//
 	// TagCertificateTrusted
 	//
-	tagCertificateTrusted, err := getTagCertificateTrustedRule(builder.verifierCertificates.AssetTagCACertificates, &builder.signedFlavor.Flavor)
+	tagCertificateTrusted, err := getTagCertificateTrustedRule(builder.verifierCertificates.AssetTagCACertificates, builder.verifierCertificates.RevocationChecker, &builder.signedFlavor.Flavor)
 	if err != nil {
 		return nil, err
 	}
@@ -47,7 +47,7 @@ func (builder *ruleBuilderVMWare20) GetAssetTagRules() ([]rules.Rule, error) {
 	//
 	// TagCertificateTrusted
 	//
-	tagCertificateTrusted, err := getTagCertificateTrustedRule(builder.verifierCertificates.AssetTagCACertificates, &builder.signedFlavor.Flavor)
+	tagCertificateTrusted, err := getTagCertificateTrustedRule(builder.verifierCertificates.AssetTagCACertificates, builder.verifierCertificates.RevocationChecker, &builder.signedFlavor.Flavor)
 	if err != nil {
 		return nil, err
 	}
@@ -12,6 +12,7 @@ import (
 	"crypto/x509"
 	"fmt"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants/verifier-rules-and-faults"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/common"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/host-connector/types"
 	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
@@ -19,7 +20,7 @@ import (
 	"time"
 )
 
-func NewAikCertificateTrusted(privacyCACertificates *x509.CertPool, marker common.FlavorPart) (Rule, error) {
+func NewAikCertificateTrusted(privacyCACertificates *x509.CertPool, revocationChecker *crypt.RevocationChecker, marker common.FlavorPart) (Rule, error) {
 
 	if privacyCACertificates == nil {
 		return nil, errors.New("The privacy CAs cannot be nil")
@@ -27,6 +28,7 @@ func NewAikCertificateTrusted(privacyCACertificates *x509.CertPool, marker commo
 
 	rule := aikCertTrusted{
 		privacyCACertificates: privacyCACertificates,
+		revocationChecker:     revocationChecker,
 		marker:                marker,
 	}
 	return &rule, nil
@@ -34,6 +36,7 @@ func NewAikCertificateTrusted(privacyCACertificates *x509.CertPool, marker commo
 
 type aikCertTrusted struct {
 	privacyCACertificates *x509.CertPool
+	revocationChecker     *crypt.RevocationChecker
 	marker                common.FlavorPart
 }
 
@@ -41,6 +44,8 @@ type aikCertTrusted struct {
 // - if the host cert is not valid, raise 'aik expired' or 'aik not yet valid' faults
 // - check the host's aik against the trustedAuthority certs and raise 'not trusted' fault
 //   if none are valid
+// - when revocation checking is enabled, raise 'aik revoked' fault if the aik or one of its
+//   CAs was revoked and 'not trusted' fault if the revocation status is unknown
 func (rule *aikCertTrusted) Apply(hostManifest *types.HostManifest) (*hvs.RuleResult, error) {
 
 	var fault *hvs.Fault
@@ -76,12 +81,25 @@ func (rule *aikCertTrusted) Apply(hostManifest *types.HostManifest) (*hvs.RuleRe
 				Roots: rule.privacyCACertificates,
 			}
 
-			_, err := aik.Verify(opts)
+			chains, err := aik.Verify(opts)
 			if err != nil {
 				fault = &hvs.Fault{
 					Name:        constants.FaultAikCertificateNotTrusted,
 					Description: "AIK certificate is not signed by any trusted CA",
 				}
+			} else if err = rule.revocationChecker.CheckChain(chains[0]); err != nil {
+				if crypt.IsCertificateRevoked(err) {
+					fault = &hvs.Fault{
+						Name:        constants.FaultAikCertificateRevoked,
+						Description: fmt.Sprintf("AIK certificate chain is revoked: %s", err.Error()),
+					}
+				} else {
+					log.WithError(err).Error("AikCertificateNotTrusted fault: Could not check the revocation status of the AIK certificate")
+					fault = &hvs.Fault{
+						Name:        constants.FaultAikCertificateNotTrusted,
+						Description: "Revocation status of the AIK certificate could not be determined",
+					}
+				}
 			}
 		}
 	}
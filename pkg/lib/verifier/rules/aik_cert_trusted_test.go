@@ -13,9 +13,13 @@ import (
 	"encoding/base64"
 	"encoding/pem"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants/verifier-rules-and-faults"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/config"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/host-connector/types"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
 	"math/big"
+	"os"
 	"testing"
 	"time"
 )
@@ -124,7 +128,7 @@ func TestAikCertificateTrustedNoFault(t *testing.T) {
 		AIKCertificate: base64.StdEncoding.EncodeToString([]byte(aikBytes)),
 	}
 
-	rule, err := NewAikCertificateTrusted(trustedAuthorityCerts, "PLATFORM")
+	rule, err := NewAikCertificateTrusted(trustedAuthorityCerts, nil, "PLATFORM")
 	assert.NoError(t, err)
 
 	result, err := rule.Apply(&hostManifest)
@@ -143,7 +147,7 @@ func TestAikCertificateTrustedMissingFault(t *testing.T) {
 		AIKCertificate: "",
 	}
 
-	rule, err := NewAikCertificateTrusted(&trustedAuthorityCerts, "PLATFORM")
+	rule, err := NewAikCertificateTrusted(&trustedAuthorityCerts, nil, "PLATFORM")
 	assert.NoError(t, err)
 
 	result, err := rule.Apply(&hostManifest)
@@ -174,7 +178,7 @@ func TestAikCertificateTrustedExpiredFault(t *testing.T) {
 		AIKCertificate: base64.StdEncoding.EncodeToString([]byte(aikBytes)),
 	}
 
-	rule, err := NewAikCertificateTrusted(&trustedAuthorityCerts, "PLATFORM")
+	rule, err := NewAikCertificateTrusted(&trustedAuthorityCerts, nil, "PLATFORM")
 	assert.NoError(t, err)
 
 	result, err := rule.Apply(&hostManifest)
@@ -205,7 +209,7 @@ func TestAikCertificateTrustedNotBeforeFault(t *testing.T) {
 		AIKCertificate: base64.StdEncoding.EncodeToString([]byte(aikBytes)),
 	}
 
-	rule, err := NewAikCertificateTrusted(&trustedAuthorityCerts, "PLATFORM")
+	rule, err := NewAikCertificateTrusted(&trustedAuthorityCerts, nil, "PLATFORM")
 	assert.NoError(t, err)
 
 	result, err := rule.Apply(&hostManifest)
@@ -231,7 +235,7 @@ func TestAikCertificateTrustedNotTrustedFault(t *testing.T) {
 		AIKCertificate: base64.StdEncoding.EncodeToString([]byte(aikBytes)),
 	}
 
-	rule, err := NewAikCertificateTrusted(&trustedAuthorityCerts, "PLATFORM")
+	rule, err := NewAikCertificateTrusted(&trustedAuthorityCerts, nil, "PLATFORM")
 	assert.NoError(t, err)
 
 	result, err := rule.Apply(&hostManifest)
@@ -241,3 +245,63 @@ func TestAikCertificateTrustedNotTrustedFault(t *testing.T) {
 	assert.Equal(t, result.Faults[0].Name, constants.FaultAikCertificateNotTrusted)
 	t.Logf("Fault description: %s", result.Faults[0].Description)
 }
+
+func TestAikCertificateTrustedRevokedFault(t *testing.T) {
+
+	caPrivateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	caTemplate, err := newCertificateTemplate()
+	assert.NoError(t, err)
+	caTemplate.IsCA = true
+	caTemplate.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+
+	caBytes, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caPrivateKey.PublicKey, caPrivateKey)
+	assert.NoError(t, err)
+	caCertificate, err := x509.ParseCertificate(caBytes)
+	assert.NoError(t, err)
+
+	trustedAuthorityCerts := x509.NewCertPool()
+	trustedAuthorityCerts.AddCert(caCertificate)
+
+	aikCertificate, err := newCertificateTemplate()
+	assert.NoError(t, err)
+	aikCertificate.SerialNumber = big.NewInt(2021)
+
+	aikPrivateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	aikBytes, err := x509.CreateCertificate(rand.Reader, aikCertificate, caCertificate, &aikPrivateKey.PublicKey, caPrivateKey)
+	assert.NoError(t, err)
+
+	// revoke the aik in the crl of the ca
+	crlBytes, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		RevokedCertificateEntries: []x509.RevocationListEntry{{SerialNumber: aikCertificate.SerialNumber, RevocationTime: time.Now()}},
+		Number:                    big.NewInt(1),
+		ThisUpdate:                time.Now(),
+		NextUpdate:                time.Now().Add(time.Hour),
+	}, caCertificate, caPrivateKey)
+	assert.NoError(t, err)
+
+	crlFile, err := ioutil.TempFile("", "aik-ca.crl")
+	assert.NoError(t, err)
+	defer os.Remove(crlFile.Name())
+	_, err = crlFile.Write(crlBytes)
+	assert.NoError(t, err)
+	assert.NoError(t, crlFile.Close())
+
+	revocationChecker := crypt.NewRevocationChecker(config.RevocationCheckConfig{Enabled: true, CrlFile: crlFile.Name()}, nil)
+
+	hostManifest := types.HostManifest{
+		AIKCertificate: base64.StdEncoding.EncodeToString(aikBytes),
+	}
+
+	rule, err := NewAikCertificateTrusted(trustedAuthorityCerts, revocationChecker, "PLATFORM")
+	assert.NoError(t, err)
+
+	result, err := rule.Apply(&hostManifest)
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, len(result.Faults), 1)
+	assert.Equal(t, result.Faults[0].Name, constants.FaultAikCertificateRevoked)
+	t.Logf("Fault description: %s", result.Faults[0].Description)
+}
@@ -10,18 +10,20 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/intel-secl/intel-secl/v4/pkg/hvs/constants/verifier-rules-and-faults"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/common"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/host-connector/types"
 	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
 )
 
-func NewFlavorTrusted(signedFlavor *hvs.SignedFlavor, flavorSigningCertificate *x509.Certificate, flavorCaCertificates *x509.CertPool, marker common.FlavorPart) (Rule, error) {
+func NewFlavorTrusted(signedFlavor *hvs.SignedFlavor, flavorSigningCertificate *x509.Certificate, flavorCaCertificates *x509.CertPool, revocationChecker *crypt.RevocationChecker, marker common.FlavorPart) (Rule, error) {
 
 	return &flavorTrusted{
 		signedFlavor:             signedFlavor,
 		flavorId:                 signedFlavor.Flavor.Meta.ID,
 		flavorSigningCertificate: flavorSigningCertificate,
 		flavorCaCertificates:     flavorCaCertificates,
+		revocationChecker:        revocationChecker,
 		marker:                   marker,
 	}, nil
 }
@@ -31,12 +33,14 @@ type flavorTrusted struct {
 	flavorId                 uuid.UUID
 	flavorSigningCertificate *x509.Certificate
 	flavorCaCertificates     *x509.CertPool
+	revocationChecker        *crypt.RevocationChecker
 	marker                   common.FlavorPart
 }
 
 // - If the flavor does not have a signature create a FaultFlavorSignatureMissing
 // - If the flavor's signature does not verify with the signing certificate and CAs, create a
 //   FaultFlavorSignatureNotTrusted
+// - If the signing certificate or one of its CAs was revoked, create FaultFlavorSigningCertificateRevoked
 // - If any errors occur during verification, create FaultFlavorSignatureVerificationFailed
 func (rule *flavorTrusted) Apply(hostManifest *types.HostManifest) (*hvs.RuleResult, error) {
 
@@ -65,13 +69,28 @@ func (rule *flavorTrusted) Apply(hostManifest *types.HostManifest) (*hvs.RuleRes
 			Roots: rule.flavorCaCertificates,
 		}
 
-		_, err := rule.flavorSigningCertificate.Verify(opts)
+		chains, err := rule.flavorSigningCertificate.Verify(opts)
 		if err != nil {
 			log.Error("FlavorSignatureVerificationFailed fault: The flavor signing certificate did not validate against the CAs")
 			result.Faults = append(result.Faults, newFlavorSignatureVerificationFailed(rule.flavorId))
 			return &result, nil
 		}
 
+		err = rule.revocationChecker.CheckChain(chains[0])
+		if crypt.IsCertificateRevoked(err) {
+			log.WithError(err).Error("FlavorSigningCertificateRevoked fault: The flavor signing certificate chain is revoked")
+			fault := hvs.Fault{
+				Name:        constants.FaultFlavorSigningCertificateRevoked,
+				Description: fmt.Sprintf("Signing certificate is revoked for flavor with id %s", rule.flavorId),
+			}
+			result.Faults = append(result.Faults, fault)
+			return &result, nil
+		} else if err != nil {
+			log.WithError(err).Error("FlavorSignatureVerificationFailed fault: Could not check the revocation status of the flavor signing certificate")
+			result.Faults = append(result.Faults, newFlavorSignatureVerificationFailed(rule.flavorId))
+			return &result, nil
+		}
+
 		// get the public key for verifying the signed flavor
 		var ok bool
 		var publicKey *rsa.PublicKey
@@ -34,7 +34,7 @@ func TestFlavorTrustedNoFault(t *testing.T) {
 	assert.NoError(t, err)
 
 	// create the rule
-	rule, err := NewFlavorTrusted(signedFlavor, flavorSigningCertificate, flavorCaCertificates, common.FlavorPartPlatform)
+	rule, err := NewFlavorTrusted(signedFlavor, flavorSigningCertificate, flavorCaCertificates, nil, common.FlavorPartPlatform)
 	assert.NoError(t, err)
 
 	// apply the rule, the hostManifest has no impact on FlavorTrusted rule
@@ -65,7 +65,7 @@ func TestFlavorTrustedNoFaultFromJSON(t *testing.T) {
 	assert.NoError(t, err)
 
 	// create the rule
-	rule, err := NewFlavorTrusted(signedFlavor, flavorSigningCertificate, flavorCaCertificates, common.FlavorPartPlatform)
+	rule, err := NewFlavorTrusted(signedFlavor, flavorSigningCertificate, flavorCaCertificates, nil, common.FlavorPartPlatform)
 	assert.NoError(t, err)
 
 	// apply the rule, the hostManifest has no impact on FlavorTrusted rule
@@ -95,7 +95,7 @@ func TestFlavorTrustedFlavorSignatureMissingFault(t *testing.T) {
 	signedFlavor.Signature = ""
 
 	// create the rule
-	rule, err := NewFlavorTrusted(signedFlavor, flavorSigningCertificate, flavorCaCertificates, common.FlavorPartPlatform)
+	rule, err := NewFlavorTrusted(signedFlavor, flavorSigningCertificate, flavorCaCertificates, nil, common.FlavorPartPlatform)
 	assert.NoError(t, err)
 
 	// apply the rule, the hostManifest has no impact on FlavorTrusted rule
@@ -126,7 +126,7 @@ func TestFlavorTrustedMissingFlavorSigningCertificate(t *testing.T) {
 
 	// create the rule without the flavorSigningCertificate to invoke
 	// FaultFlavorSignatureVerificationFailed
-	rule, err := NewFlavorTrusted(signedFlavor, nil, flavorCaCertificates, common.FlavorPartPlatform)
+	rule, err := NewFlavorTrusted(signedFlavor, nil, flavorCaCertificates, nil, common.FlavorPartPlatform)
 	assert.NoError(t, err)
 
 	// apply the rule, the hostManifest has no impact on FlavorTrusted rule
@@ -157,7 +157,7 @@ func TestFlavorTrustedMissingCACertificates(t *testing.T) {
 
 	// create the rule without the CA certs to invoke
 	// FaultFlavorSignatureVerificationFailed
-	rule, err := NewFlavorTrusted(signedFlavor, flavorSigningCertificate, nil, common.FlavorPartPlatform)
+	rule, err := NewFlavorTrusted(signedFlavor, flavorSigningCertificate, nil, nil, common.FlavorPartPlatform)
 	assert.NoError(t, err)
 
 	// apply the rule, the hostManifest has no impact on FlavorTrusted rule
@@ -195,7 +195,7 @@ func TestFlavorTrustedInvalidRootCA(t *testing.T) {
 
 	// create the rule without the CA certs to invoke the
 	// FaultFlavorSignatureVerificationFailed
-	rule, err := NewFlavorTrusted(signedFlavor, flavorSigningCertificate, invalidCaCertificates, common.FlavorPartPlatform)
+	rule, err := NewFlavorTrusted(signedFlavor, flavorSigningCertificate, invalidCaCertificates, nil, common.FlavorPartPlatform)
 	assert.NoError(t, err)
 
 	// apply the rule, the hostManifest has no impact on FlavorTrusted rule
@@ -227,7 +227,7 @@ func TestFlavorTrustedForceSignatureVerificationToFail(t *testing.T) {
 	signedFlavor.Signature = "invalidsignature"
 
 	// create the rule
-	rule, err := NewFlavorTrusted(signedFlavor, flavorSigningCertificate, flavorCaCertificates, common.FlavorPartPlatform)
+	rule, err := NewFlavorTrusted(signedFlavor, flavorSigningCertificate, flavorCaCertificates, nil, common.FlavorPartPlatform)
 	assert.NoError(t, err)
 
 	// apply the rule, the hostManifest has no impact on FlavorTrusted rule
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	faultsConst "github.com/intel-secl/intel-secl/v4/pkg/hvs/constants/verifier-rules-and-faults"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/common"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/model"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/host-connector/types"
@@ -17,13 +18,14 @@ import (
 	"github.com/pkg/errors"
 )
 
-func NewTagCertificateTrusted(assetTagCACertificates *x509.CertPool, attributeCertificate *model.X509AttributeCertificate) (Rule, error) {
+func NewTagCertificateTrusted(assetTagCACertificates *x509.CertPool, revocationChecker *crypt.RevocationChecker, attributeCertificate *model.X509AttributeCertificate) (Rule, error) {
 	if assetTagCACertificates == nil {
 		return nil, errors.New("The tag certificates cannot be nil")
 	}
 
 	rule := tagCertificateTrusted{
 		assetTagCACertificates: assetTagCACertificates,
+		revocationChecker:      revocationChecker,
 		attributeCertificate:   attributeCertificate,
 	}
 
@@ -32,6 +34,7 @@ func NewTagCertificateTrusted(assetTagCACertificates *x509.CertPool, attributeCe
 
 type tagCertificateTrusted struct {
 	assetTagCACertificates *x509.CertPool
+	revocationChecker      *crypt.RevocationChecker
 	attributeCertificate   *model.X509AttributeCertificate
 }
 
@@ -41,6 +44,8 @@ type tagCertificateTrusted struct {
 //   raise a TagCertificateNotYetValid fault.
 // - If the attributeCertificate is valid but has a 'NotAfter' value after 'today,
 //   raise a TagCertificateNotYetExpired fault.
+// - If revocation checking is enabled and the attributeCertificate or one of its CAs was revoked,
+//   raise a TagCertificateRevoked fault.
 func (rule *tagCertificateTrusted) Apply(hostManifest *types.HostManifest) (*hvs.RuleResult, error) {
 
 	var fault *hvs.Fault
@@ -65,12 +70,25 @@ func (rule *tagCertificateTrusted) Apply(hostManifest *types.HostManifest) (*hvs
 			Roots: rule.assetTagCACertificates,
 		}
 
-		_, err = tagCertificate.Verify(opts)
+		chains, err := tagCertificate.Verify(opts)
 		if err != nil {
 			fault = &hvs.Fault{
 				Name:        faultsConst.FaultTagCertificateNotTrusted,
 				Description: "Tag certificate is not signed by any trusted CA",
 			}
+		} else if err = rule.revocationChecker.CheckChain(chains[0]); err != nil {
+			if crypt.IsCertificateRevoked(err) {
+				fault = &hvs.Fault{
+					Name:        faultsConst.FaultTagCertificateRevoked,
+					Description: fmt.Sprintf("Tag certificate chain is revoked: %s", err.Error()),
+				}
+			} else {
+				log.WithError(err).Error("TagCertificateNotTrusted fault: Could not check the revocation status of the tag certificate")
+				fault = &hvs.Fault{
+					Name:        faultsConst.FaultTagCertificateNotTrusted,
+					Description: "Revocation status of the tag certificate could not be determined",
+				}
+			}
 		} else {
 			// check to see if the attribute certificate's 'not before' is before today...
 			if time.Now().Before(rule.attributeCertificate.NotBefore) {
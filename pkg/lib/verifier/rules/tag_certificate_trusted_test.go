@@ -38,7 +38,7 @@ func TestTagCertificateTrustedNoFault(t *testing.T) {
 	}
 
 	// create the rule
-	rule, err := NewTagCertificateTrusted(trustedAuthorityCerts, &attributeCertificate)
+	rule, err := NewTagCertificateTrusted(trustedAuthorityCerts, nil, &attributeCertificate)
 	assert.NoError(t, err)
 
 	// apply the rule, the hostManifest has no impact on TagCertificateTrusted rule
@@ -60,7 +60,7 @@ func TestTagCertificateTrustedMissingFault(t *testing.T) {
 
 	// create the rule, not provding the attribute certificate to invoke
 	// FaultTagCertificateMissing.
-	rule, err := NewTagCertificateTrusted(trustedAuthorityCerts, nil)
+	rule, err := NewTagCertificateTrusted(trustedAuthorityCerts, nil, nil)
 	assert.NoError(t, err)
 
 	// apply the rule, the hostManifest has no impact on TagCertificateTrusted rule
@@ -94,7 +94,7 @@ func TestTagCertificateTrustedNotTrusted(t *testing.T) {
 	}
 
 	// create the rule
-	rule, err := NewTagCertificateTrusted(trustedAuthorityCerts, &attributeCertificate)
+	rule, err := NewTagCertificateTrusted(trustedAuthorityCerts, nil, &attributeCertificate)
 	assert.NoError(t, err)
 
 	// apply the rule, the hostManifest has no impact on TagCertificateTrusted rule
@@ -131,7 +131,7 @@ func TestTagCertificateTrustedExpiredFault(t *testing.T) {
 	}
 
 	// create the rule
-	rule, err := NewTagCertificateTrusted(trustedAuthorityCerts, &attributeCertificate)
+	rule, err := NewTagCertificateTrusted(trustedAuthorityCerts, nil, &attributeCertificate)
 	assert.NoError(t, err)
 
 	// apply the rule, the hostManifest has no impact on TagCertificateTrusted rule
@@ -168,7 +168,7 @@ func TestTagCertificateTrustedNotYetValidFault(t *testing.T) {
 	}
 
 	// create the rule
-	rule, err := NewTagCertificateTrusted(trustedAuthorityCerts, &attributeCertificate)
+	rule, err := NewTagCertificateTrusted(trustedAuthorityCerts, nil, &attributeCertificate)
 	assert.NoError(t, err)
 
 	// apply the rule, the hostManifest has no impact on TagCertificateTrusted rule
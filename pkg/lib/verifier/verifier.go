@@ -11,6 +11,7 @@ package verifier
 
 import (
 	"crypto/x509"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	commLog "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/host-connector/types"
 	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
@@ -24,6 +25,8 @@ type VerifierCertificates struct {
 	AssetTagCACertificates   *x509.CertPool
 	FlavorSigningCertificate *x509.Certificate
 	FlavorCACertificates     *x509.CertPool
+	// RevocationChecker is optional, the revocation status of the certificates is not checked when nil
+	RevocationChecker *crypt.RevocationChecker
 }
 
 // Verifier The interface that exposes the verification of a host manifest
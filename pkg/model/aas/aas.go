@@ -4,6 +4,8 @@
  */
 package aas
 
+import "time"
+
 type RoleInfo struct {
 	Service string `json:"service"`
 	// Name: UpdateHost
@@ -57,6 +59,40 @@ type UserCred struct {
 	Password string `json:"password"`
 }
 
+type OidcTokenRequest struct {
+	IdToken string `json:"id_token"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// RevokeTokenRequest revokes the token with the jti or all the tokens issued so far to the user
+type RevokeTokenRequest struct {
+	Jti      string `json:"jti,omitempty"`
+	UserName string `json:"username,omitempty"`
+}
+
+// TokenRevocationList lists the revoked tokens that are not expired yet. A token is revoked when its jti is listed
+// or when it was issued to a listed subject before the subject was revoked.
+type TokenRevocationList struct {
+	Jtis     []string            `json:"jtis,omitempty"`
+	Subjects []SubjectRevocation `json:"subjects,omitempty"`
+}
+
+type SubjectRevocation struct {
+	Subject string `json:"subject"`
+	// tokens with an issued at before this time are revoked
+	IssuedBefore time.Time `json:"issued_before"`
+}
+
 type PasswordChange struct {
 	UserName        string `json:"username"`
 	OldPassword     string `json:"old_password"`
@@ -0,0 +1,38 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package cms
+
+import (
+	"time"
+)
+
+// IssuedCertificate is the inventory record kept by CMS for every certificate it signs
+type IssuedCertificate struct {
+	// SerialNumber is the hexadecimal serial number of the certificate
+	SerialNumber     string     `json:"serial_number"`
+	Subject          string     `json:"subject"`
+	CertType         string     `json:"cert_type"`
+	IssuingCa        string     `json:"issuing_ca"`
+	NotBefore        time.Time  `json:"not_before"`
+	NotAfter         time.Time  `json:"not_after"`
+	Revoked          bool       `json:"revoked"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	RevocationReason string     `json:"revocation_reason,omitempty"`
+}
+
+// RevokeCertificateRequest is the optional body of the certificate revocation request
+type RevokeCertificateRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// Revocation reasons accepted by the certificate revocation request, as defined by RFC 5280
+const (
+	RevocationReasonUnspecified          = "unspecified"
+	RevocationReasonKeyCompromise        = "key_compromise"
+	RevocationReasonCACompromise         = "ca_compromise"
+	RevocationReasonAffiliationChanged   = "affiliation_changed"
+	RevocationReasonSuperseded           = "superseded"
+	RevocationReasonCessationOfOperation = "cessation_of_operation"
+)
@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package hvs
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// AuditLogEntry records a change of a report or of a host status. The entries are chained: the hash of an entry
+// covers its content and the hash of the entry preceding it in the sequence.
+type AuditLogEntry struct {
+	// swagger:strfmt uuid
+	ID uuid.UUID `json:"id"`
+	// swagger:strfmt uuid
+	EntityID   uuid.UUID        `json:"entity_id"`
+	EntityType string           `json:"entity_type"`
+	Created    time.Time        `json:"created"`
+	Action     string           `json:"action"`
+	Columns    []AuditLogColumn `json:"columns"`
+	// Sequence is the position of the entry in the hash chain, 0 for the entries written before chaining was enabled
+	Sequence int64  `json:"sequence"`
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+}
+
+// AuditLogColumn holds the value of a column of the audited entity
+type AuditLogColumn struct {
+	Name      string      `json:"name"`
+	Value     interface{} `json:"value"`
+	IsUpdated bool        `json:"is_updated"`
+}
+
+// AuditLogEntryCollection holds a collection of AuditLogEntry in response to an API query
+type AuditLogEntryCollection struct {
+	AuditLogEntries []AuditLogEntry `json:"audit_logs" xml:"audit_logs"`
+	// Next is the link to the following page of a paginated search
+	Next string `json:"next,omitempty" xml:"next,omitempty"`
+}
+
+// ComputeHash returns the hex encoded SHA-384 hash of the entry: the hash of its canonical JSON encoding, with
+// the creation time in UTC, the object keys sorted and the hash field omitted
+func (entry AuditLogEntry) ComputeHash() (string, error) {
+	entry.Hash = ""
+	entry.Created = entry.Created.UTC()
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to marshal audit log entry")
+	}
+	// decoding into generic maps sorts the object keys once marshalled again, and normalizes the column values
+	// the same way as when they are read back from the database
+	var canonical interface{}
+	if err := json.Unmarshal(b, &canonical); err != nil {
+		return "", errors.Wrap(err, "Failed to decode audit log entry")
+	}
+	if b, err = json.Marshal(canonical); err != nil {
+		return "", errors.Wrap(err, "Failed to marshal canonical audit log entry")
+	}
+	digest := sha512.Sum384(b)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// AuditLogChainVerification is the result of the verification of the audit log hash chain
+type AuditLogChainVerification struct {
+	// Verified is true when no entry of the chain was found modified or missing
+	Verified      bool   `json:"verified"`
+	EntryCount    int64  `json:"entry_count"`
+	FirstSequence int64  `json:"first_sequence,omitempty"`
+	LastSequence  int64  `json:"last_sequence,omitempty"`
+	LastHash      string `json:"last_hash,omitempty"`
+	// ModifiedEntries lists the sequences of the entries whose content does not match their hash
+	ModifiedEntries []int64 `json:"modified_entries,omitempty"`
+	// BrokenLinks lists the sequences of the entries that do not reference the hash of their predecessor
+	BrokenLinks []int64 `json:"broken_links,omitempty"`
+	// MissingEntries lists the ranges of sequences missing from the chain
+	MissingEntries []AuditLogSequenceRange `json:"missing_entries,omitempty"`
+	// Anchor is the outcome of the check of the anchor provided by the client, if any
+	Anchor string `json:"anchor,omitempty"`
+}
+
+// AuditLogSequenceRange is an inclusive range of sequences
+type AuditLogSequenceRange struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// Outcomes of the check of a previously recorded chain position
+const (
+	// AuditLogAnchorMatched is reported when the entry at the anchor sequence has the anchor hash
+	AuditLogAnchorMatched = "matched"
+	// AuditLogAnchorMismatched is reported when the entry at the anchor sequence has another hash
+	AuditLogAnchorMismatched = "mismatched"
+	// AuditLogAnchorMissing is reported when the chain ends before the anchor sequence
+	AuditLogAnchorMissing = "missing"
+	// AuditLogAnchorRotated is reported when the anchor entry was rotated out of the audit log
+	AuditLogAnchorRotated = "rotated"
+)
+
+// AuditLogExportSignature is the last line of an audit log export. The signature is computed over the SHA-384
+// digest of all the lines preceding it, newlines included.
+type AuditLogExportSignature struct {
+	EntryCount    int    `json:"entry_count"`
+	FirstSequence int64  `json:"first_sequence,omitempty"`
+	LastSequence  int64  `json:"last_sequence,omitempty"`
+	LastHash      string `json:"last_hash,omitempty"`
+	Digest        string `json:"digest"`
+	// Algorithm is RS384 for a RSASSA-PKCS1-v1_5 signature or ES384 for an ASN.1 encoded ECDSA signature
+	Algorithm string `json:"algorithm"`
+	// Signature is the base64 encoded signature of the digest
+	Signature string `json:"signature"`
+	// Certificate is the base64 encoded DER certificate of the signing key
+	Certificate string `json:"certificate"`
+}
+
+// AuditLogExportTrailer wraps the signature on the last line of an export
+type AuditLogExportTrailer struct {
+	Signature AuditLogExportSignature `json:"signature"`
+}
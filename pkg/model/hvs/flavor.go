@@ -26,6 +26,8 @@ type SignedFlavor = model.SignedFlavor
 // SignedFlavorCollection is a list of SignedFlavor objects
 type SignedFlavorCollection struct {
 	SignedFlavors []SignedFlavor `json:"signed_flavors"`
+	// Next is the link to the following page of a paginated search
+	Next string `json:"next,omitempty"`
 }
 
 func (s SignedFlavorCollection) GetFlavors(flavorPart string) []SignedFlavor {
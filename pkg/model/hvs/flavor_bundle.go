@@ -0,0 +1,236 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package hvs
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/model"
+	"github.com/pkg/errors"
+)
+
+// FlavorBundleVersion is the version of the flavor bundle format
+const FlavorBundleVersion = "1.0"
+
+// FlavorBundleEntryType tells the kind of record a flavor bundle entry describes
+type FlavorBundleEntryType string
+
+const (
+	FlavorBundleEntryFlavorgroup    FlavorBundleEntryType = "flavorgroup"
+	FlavorBundleEntryFlavorTemplate FlavorBundleEntryType = "flavor_template"
+	FlavorBundleEntryFlavor         FlavorBundleEntryType = "flavor"
+)
+
+// FlavorBundleEntry describes a record of a flavor bundle, the digest is the hex encoded SHA384 of its content
+type FlavorBundleEntry struct {
+	Type FlavorBundleEntryType `json:"type"`
+	// swagger:strfmt uuid
+	Id     uuid.UUID `json:"id"`
+	Name   string    `json:"name"`
+	Digest string    `json:"digest"`
+}
+
+// FlavorBundleManifest lists the content of a flavor bundle, the bundle signature is computed over the manifest
+type FlavorBundleManifest struct {
+	Version   string              `json:"version"`
+	CreatedAt time.Time           `json:"created_at"`
+	Entries   []FlavorBundleEntry `json:"entries"`
+}
+
+// FlavorBundle holds flavorgroups along with their match policies, flavor templates and flavors so that they can
+// be copied between HVS instances
+type FlavorBundle struct {
+	Manifest        FlavorBundleManifest `json:"manifest"`
+	FlavorGroups    []FlavorGroup        `json:"flavorgroups"`
+	FlavorTemplates []FlavorTemplate     `json:"flavor_templates,omitempty"`
+	SignedFlavors   []SignedFlavor       `json:"signed_flavors,omitempty"`
+	// Signature is the base64 encoded signature of the manifest
+	Signature string `json:"signature"`
+	// SigningCertificates is the PEM encoded certificate chain of the key the bundle is signed with
+	SigningCertificates string `json:"signing_certificates"`
+}
+
+// FlavorBundleImportEntry reports what was done with a record of an imported flavor bundle
+type FlavorBundleImportEntry struct {
+	Type FlavorBundleEntryType `json:"type"`
+	// swagger:strfmt uuid
+	Id   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	// Reason tells why the record was skipped
+	Reason string `json:"reason,omitempty"`
+}
+
+// FlavorBundleImportResult lists the records of a flavor bundle that were added and the ones that were skipped
+type FlavorBundleImportResult struct {
+	Added   []FlavorBundleImportEntry `json:"added"`
+	Skipped []FlavorBundleImportEntry `json:"skipped"`
+}
+
+// FlavorDigest returns the hex encoded digest of the flavor content, it does not depend on the flavor id
+func FlavorDigest(flavor *Flavor) (string, error) {
+	digest, err := flavor.Digest()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(digest), nil
+}
+
+// FlavorTemplateDigest returns the hex encoded digest of the flavor template content, it does not depend on the
+// flavor template id
+func FlavorTemplateDigest(template *FlavorTemplate) (string, error) {
+	content := *template
+	content.ID = uuid.Nil
+	return jsonDigest(content)
+}
+
+func jsonDigest(v interface{}) (string, error) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return "", errors.Wrap(err, "could not marshal the content to digest")
+	}
+	digest := sha512.Sum384(content)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// entries lists the content of the bundle along with its digests
+func (bundle *FlavorBundle) entries() ([]FlavorBundleEntry, error) {
+	entries := make([]FlavorBundleEntry, 0, len(bundle.FlavorGroups)+len(bundle.FlavorTemplates)+len(bundle.SignedFlavors))
+	for _, flavorGroup := range bundle.FlavorGroups {
+		digest, err := jsonDigest(flavorGroup)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not compute the digest of flavorgroup %s", flavorGroup.Name)
+		}
+		entries = append(entries, FlavorBundleEntry{Type: FlavorBundleEntryFlavorgroup, Id: flavorGroup.ID, Name: flavorGroup.Name, Digest: digest})
+	}
+	for i := range bundle.FlavorTemplates {
+		digest, err := FlavorTemplateDigest(&bundle.FlavorTemplates[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not compute the digest of flavor template %s", bundle.FlavorTemplates[i].ID)
+		}
+		entries = append(entries, FlavorBundleEntry{Type: FlavorBundleEntryFlavorTemplate, Id: bundle.FlavorTemplates[i].ID,
+			Name: bundle.FlavorTemplates[i].Label, Digest: digest})
+	}
+	for i := range bundle.SignedFlavors {
+		flavor := &bundle.SignedFlavors[i].Flavor
+		digest, err := FlavorDigest(flavor)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not compute the digest of flavor %s", flavor.Meta.ID)
+		}
+		label, _ := flavor.Meta.Description[model.Label].(string)
+		entries = append(entries, FlavorBundleEntry{Type: FlavorBundleEntryFlavor, Id: flavor.Meta.ID, Name: label, Digest: digest})
+	}
+	return entries, nil
+}
+
+// Sign builds the manifest of the bundle and signs it with the given key, the certificate chain of the key is
+// added to the bundle so that it can be verified by the HVS it is imported in
+func (bundle *FlavorBundle) Sign(privateKey *rsa.PrivateKey, certificates []x509.Certificate, createdAt time.Time) error {
+	if privateKey == nil || len(certificates) == 0 {
+		return errors.New("the signing key and its certificate chain must be provided")
+	}
+	entries, err := bundle.entries()
+	if err != nil {
+		return err
+	}
+	bundle.Manifest = FlavorBundleManifest{
+		Version:   FlavorBundleVersion,
+		CreatedAt: createdAt,
+		Entries:   entries,
+	}
+	manifest, err := json.Marshal(bundle.Manifest)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal the flavor bundle manifest")
+	}
+	digest := sha512.Sum384(manifest)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA384, digest[:])
+	if err != nil {
+		return errors.Wrap(err, "could not sign the flavor bundle manifest")
+	}
+	bundle.Signature = base64.StdEncoding.EncodeToString(signature)
+
+	var chain bytes.Buffer
+	for _, certificate := range certificates {
+		if err := pem.Encode(&chain, &pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw}); err != nil {
+			return errors.Wrap(err, "could not encode the flavor bundle signing certificates")
+		}
+	}
+	bundle.SigningCertificates = chain.String()
+	return nil
+}
+
+// Verify checks that the bundle is signed by a certificate issued by the given CAs and that its manifest lists its
+// content. It returns the signing certificate, which the signature of the flavors of the bundle can be verified with.
+func (bundle *FlavorBundle) Verify(flavorCAs *x509.CertPool) (*x509.Certificate, error) {
+	if bundle.Manifest.Version != FlavorBundleVersion {
+		return nil, errors.Errorf("unsupported flavor bundle version %s", bundle.Manifest.Version)
+	}
+
+	var certificates []*x509.Certificate
+	rest := []byte(bundle.SigningCertificates)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse the flavor bundle signing certificates")
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, errors.New("the flavor bundle signing certificates are missing")
+	}
+	intermediates := x509.NewCertPool()
+	for _, certificate := range certificates[1:] {
+		intermediates.AddCert(certificate)
+	}
+	if _, err := certificates[0].Verify(x509.VerifyOptions{
+		Roots:         flavorCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return nil, errors.Wrap(err, "the flavor bundle signing certificate is not trusted")
+	}
+	publicKey, ok := certificates[0].PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("the flavor bundle signing certificate does not hold an RSA public key")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(bundle.Signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode the flavor bundle signature")
+	}
+	manifest, err := json.Marshal(bundle.Manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal the flavor bundle manifest")
+	}
+	digest := sha512.Sum384(manifest)
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA384, digest[:], signature); err != nil {
+		return nil, errors.Wrap(err, "the flavor bundle signature is not valid")
+	}
+
+	entries, err := bundle.entries()
+	if err != nil {
+		return nil, err
+	}
+	if !reflect.DeepEqual(entries, bundle.Manifest.Entries) {
+		return nil, errors.New("the flavor bundle content does not match its manifest")
+	}
+	return certificates[0], nil
+}
@@ -0,0 +1,262 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package hvs
+
+import (
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/model"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/host-connector/types"
+	"github.com/pkg/errors"
+)
+
+// PcrDiffStatus tells how a PCR differs between a flavor and another flavor or a host manifest
+type PcrDiffStatus string
+
+const (
+	// PcrDiffAdded is the status of a PCR that is not in the flavor
+	PcrDiffAdded PcrDiffStatus = "added"
+	// PcrDiffRemoved is the status of a PCR of the flavor that is missing from the other flavor or host manifest
+	PcrDiffRemoved PcrDiffStatus = "removed"
+	// PcrDiffChanged is the status of a PCR whose measurement or events differ
+	PcrDiffChanged PcrDiffStatus = "changed"
+)
+
+// PcrDiff describes how a PCR of a flavor differs from the same PCR of another flavor or of a host manifest
+type PcrDiff struct {
+	types.EventLogDiff
+	Status              PcrDiffStatus `json:"status"`
+	ExpectedMeasurement string        `json:"expected_measurement,omitempty"`
+	ActualMeasurement   string        `json:"actual_measurement,omitempty"`
+}
+
+// FlavorDiff lists the PCRs that differ between a flavor and another flavor or the latest manifest of a host
+type FlavorDiff struct {
+	// swagger:strfmt uuid
+	FlavorId uuid.UUID `json:"flavor_id"`
+	// swagger:strfmt uuid
+	OtherFlavorId *uuid.UUID `json:"other_flavor_id,omitempty"`
+	// swagger:strfmt uuid
+	HostId *uuid.UUID `json:"host_id,omitempty"`
+	Pcrs   []PcrDiff  `json:"pcrs"`
+	// CandidateFlavor is the flavor updated with the PCR diffs, it is neither signed nor stored
+	CandidateFlavor *model.Flavor `json:"candidate_flavor,omitempty"`
+}
+
+// DiffFlavors compares the PCRs of the flavor with the ones of the other flavor. All the events of the PCRs are
+// compared, whether they are listed as equal or included events. The PCRs that are the same are not listed.
+func DiffFlavors(flavor, other *model.Flavor) ([]PcrDiff, error) {
+	pcrDiffs := []PcrDiff{}
+	expectedPcrs := make(map[types.Pcr]bool)
+	otherPcrs := make(map[types.Pcr]types.FlavorPcrs)
+	for _, pcr := range other.Pcrs {
+		otherPcrs[pcr.Pcr] = pcr
+	}
+
+	for _, expected := range flavor.Pcrs {
+		expectedPcrs[expected.Pcr] = true
+		actual, ok := otherPcrs[expected.Pcr]
+		if !ok {
+			pcrDiffs = append(pcrDiffs, PcrDiff{
+				EventLogDiff:        types.EventLogDiff{Pcr: expected.Pcr, Removed: flavorPcrEvents(expected)},
+				Status:              PcrDiffRemoved,
+				ExpectedMeasurement: expected.Measurement,
+			})
+			continue
+		}
+		pcrDiff, err := newPcrDiff(expected, actual.Measurement, flavorPcrEvents(actual), true)
+		if err != nil {
+			return nil, err
+		}
+		if pcrDiff != nil {
+			pcrDiffs = append(pcrDiffs, *pcrDiff)
+		}
+	}
+
+	for _, actual := range other.Pcrs {
+		if !expectedPcrs[actual.Pcr] {
+			pcrDiffs = append(pcrDiffs, PcrDiff{
+				EventLogDiff:      types.EventLogDiff{Pcr: actual.Pcr, Added: flavorPcrEvents(actual)},
+				Status:            PcrDiffAdded,
+				ActualMeasurement: actual.Measurement,
+			})
+		}
+	}
+	return pcrDiffs, nil
+}
+
+// DiffFlavorWithHostManifest compares the PCRs of the flavor with the ones of the host manifest, the same way the
+// flavor is verified: the events carrying the exclude tags of the flavor are ignored and the events of the host that
+// are not in the flavor are only reported when the flavor expects an equal event log. The PCRs that match are not
+// listed.
+func DiffFlavorWithHostManifest(flavor *model.Flavor, hostManifest *types.HostManifest) ([]PcrDiff, error) {
+	pcrDiffs := []PcrDiff{}
+	for _, expected := range flavor.Pcrs {
+		pcrValue, err := hostManifest.PcrManifest.GetPcrValue(types.SHAAlgorithm(expected.Pcr.Bank), types.PcrIndex(expected.Pcr.Index))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not retrieve the value of PCR %d of bank %s", expected.Pcr.Index, expected.Pcr.Bank)
+		}
+		if pcrValue == nil {
+			pcrDiffs = append(pcrDiffs, PcrDiff{
+				EventLogDiff:        types.EventLogDiff{Pcr: expected.Pcr, Removed: flavorPcrEvents(expected)},
+				Status:              PcrDiffRemoved,
+				ExpectedMeasurement: expected.Measurement,
+			})
+			continue
+		}
+
+		actualEvents, _, _, err := hostManifest.PcrManifest.PcrEventLogMap.GetEventLogNew(expected.Pcr.Bank, expected.Pcr.Index)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not retrieve the event log of PCR %d of bank %s", expected.Pcr.Index, expected.Pcr.Bank)
+		}
+		if expected.EventlogEqual != nil {
+			actualEvents = withoutTaggedEvents(actualEvents, expected.EventlogEqual.ExcludeTags)
+		}
+
+		pcrDiff, err := newPcrDiff(expected, pcrValue.Value, actualEvents, expected.EventlogEqual != nil)
+		if err != nil {
+			return nil, err
+		}
+		if pcrDiff != nil {
+			pcrDiffs = append(pcrDiffs, *pcrDiff)
+		}
+	}
+	return pcrDiffs, nil
+}
+
+// CandidateFlavor returns a copy of the flavor updated with the PCR diffs, so that it matches the flavor or host
+// manifest the diffs were computed against. The removed PCRs are dropped and the added ones are expected to match
+// and to include their events. The candidate has no id and is not signed, it is meant to be reviewed before being
+// created.
+func CandidateFlavor(flavor *model.Flavor, pcrDiffs []PcrDiff) *model.Flavor {
+	candidate := *flavor
+	candidate.Meta.ID = uuid.Nil
+	if flavor.Meta.Description != nil {
+		candidate.Meta.Description = make(map[string]interface{}, len(flavor.Meta.Description))
+		for key, value := range flavor.Meta.Description {
+			candidate.Meta.Description[key] = value
+		}
+	}
+
+	diffs := make(map[types.Pcr]PcrDiff)
+	for _, pcrDiff := range pcrDiffs {
+		diffs[pcrDiff.Pcr] = pcrDiff
+	}
+
+	candidate.Pcrs = make([]types.FlavorPcrs, 0, len(flavor.Pcrs))
+	for _, pcr := range flavor.Pcrs {
+		pcrDiff, ok := diffs[pcr.Pcr]
+		if !ok {
+			candidate.Pcrs = append(candidate.Pcrs, pcr)
+			continue
+		}
+		if pcrDiff.Status == PcrDiffChanged {
+			candidate.Pcrs = append(candidate.Pcrs, pcrDiff.apply(pcr))
+		}
+	}
+	for _, pcrDiff := range pcrDiffs {
+		if pcrDiff.Status == PcrDiffAdded {
+			candidate.Pcrs = append(candidate.Pcrs, types.FlavorPcrs{
+				Pcr:              pcrDiff.Pcr,
+				Measurement:      pcrDiff.ActualMeasurement,
+				PCRMatches:       true,
+				EventlogIncludes: pcrDiff.Added,
+			})
+		}
+	}
+	return &candidate
+}
+
+// apply updates the measurement and events of the flavor PCR with the diff
+func (pcrDiff *PcrDiff) apply(pcr types.FlavorPcrs) types.FlavorPcrs {
+	type eventKey struct {
+		typeID      string
+		measurement string
+	}
+	removed := make(map[eventKey]bool)
+	for _, event := range pcrDiff.Removed {
+		removed[eventKey{event.TypeID, event.Measurement}] = true
+	}
+	changed := make(map[eventKey]types.EventLog)
+	for _, change := range pcrDiff.Changed {
+		changed[eventKey{change.Expected.TypeID, change.Expected.Measurement}] = change.Actual
+	}
+
+	var events []types.EventLog
+	for _, event := range flavorPcrEvents(pcr) {
+		key := eventKey{event.TypeID, event.Measurement}
+		if removed[key] {
+			continue
+		}
+		if actual, ok := changed[key]; ok {
+			event = actual
+		}
+		events = append(events, event)
+	}
+	events = append(events, pcrDiff.Added...)
+
+	updated := pcr
+	updated.Measurement = pcrDiff.ActualMeasurement
+	if pcr.EventlogEqual != nil {
+		updated.EventlogEqual = &types.EventLogEqual{Events: events, ExcludeTags: pcr.EventlogEqual.ExcludeTags}
+	} else if len(events) > 0 {
+		updated.EventlogIncludes = events
+	}
+	return updated
+}
+
+// newPcrDiff compares the flavor PCR with the actual measurement and events, it returns nil when they match
+func newPcrDiff(expected types.FlavorPcrs, actualMeasurement string, actualEvents []types.EventLog, reportAddedEvents bool) (*PcrDiff, error) {
+	expectedEventLog := types.TpmEventLog{Pcr: expected.Pcr, TpmEvent: flavorPcrEvents(expected)}
+	actualEventLog := types.TpmEventLog{Pcr: expected.Pcr, TpmEvent: actualEvents}
+	eventLogDiff, err := expectedEventLog.Diff(&actualEventLog)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not compare the event logs of PCR %d of bank %s", expected.Pcr.Index, expected.Pcr.Bank)
+	}
+	if !reportAddedEvents {
+		eventLogDiff.Added = nil
+	}
+	if eventLogDiff.IsEmpty() && expected.Measurement == actualMeasurement {
+		return nil, nil
+	}
+	return &PcrDiff{
+		EventLogDiff:        *eventLogDiff,
+		Status:              PcrDiffChanged,
+		ExpectedMeasurement: expected.Measurement,
+		ActualMeasurement:   actualMeasurement,
+	}, nil
+}
+
+// flavorPcrEvents returns the events the flavor PCR expects
+func flavorPcrEvents(pcr types.FlavorPcrs) []types.EventLog {
+	if pcr.EventlogEqual != nil {
+		return pcr.EventlogEqual.Events
+	}
+	return pcr.EventlogIncludes
+}
+
+// withoutTaggedEvents returns the events that carry none of the tags
+func withoutTaggedEvents(events []types.EventLog, tags []string) []types.EventLog {
+	if len(tags) == 0 {
+		return events
+	}
+	excluded := make(map[string]bool)
+	for _, tag := range tags {
+		excluded[tag] = true
+	}
+	var filtered []types.EventLog
+	for _, event := range events {
+		tagged := false
+		for _, tag := range event.Tags {
+			if excluded[tag] {
+				tagged = true
+				break
+			}
+		}
+		if !tagged {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package hvs_test
+
+import (
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/model"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/host-connector/types"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FlavorDiff", func() {
+	pcr0 := types.Pcr{Index: 0, Bank: "SHA256"}
+	pcr7 := types.Pcr{Index: 7, Bank: "SHA256"}
+	bootGuard := types.EventLog{TypeID: "0x8", TypeName: "EV_S_CRTM_VERSION", Tags: []string{"BootGuard"}, Measurement: "aa"}
+	microcode := types.EventLog{TypeID: "0x1", TypeName: "EV_POST_CODE", Tags: []string{"Microcode"}, Measurement: "bb"}
+	secureBoot := types.EventLog{TypeID: "0x80000001", TypeName: "EV_EFI_VARIABLE_DRIVER_CONFIG", Tags: []string{"SecureBoot"}, Measurement: "cc"}
+
+	var flavor *model.Flavor
+
+	BeforeEach(func() {
+		flavor = &model.Flavor{
+			Meta: model.Meta{ID: uuid.New(), Description: map[string]interface{}{model.Label: "platform"}},
+			Pcrs: []types.FlavorPcrs{
+				{
+					Pcr:           pcr0,
+					Measurement:   "00",
+					PCRMatches:    true,
+					EventlogEqual: &types.EventLogEqual{Events: []types.EventLog{bootGuard, microcode}, ExcludeTags: []string{"LCP_CONTROL_HASH"}},
+				},
+				{
+					Pcr:              pcr7,
+					Measurement:      "07",
+					PCRMatches:       true,
+					EventlogIncludes: []types.EventLog{secureBoot},
+				},
+			},
+		}
+	})
+
+	Describe("Diffing two flavors", func() {
+		It("Should not list the PCRs that are the same", func() {
+			pcrDiffs, err := hvs.DiffFlavors(flavor, flavor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pcrDiffs).To(BeEmpty())
+		})
+
+		It("Should report the added, removed and changed events", func() {
+			updatedMicrocode := microcode
+			updatedMicrocode.Measurement = "bc"
+			renamedBootGuard := bootGuard
+			renamedBootGuard.Tags = []string{"BootGuardV2"}
+			other := &model.Flavor{Pcrs: []types.FlavorPcrs{{
+				Pcr:           pcr0,
+				Measurement:   "01",
+				PCRMatches:    true,
+				EventlogEqual: &types.EventLogEqual{Events: []types.EventLog{renamedBootGuard, updatedMicrocode}},
+			}}}
+
+			pcrDiffs, err := hvs.DiffFlavors(flavor, other)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pcrDiffs).To(HaveLen(2))
+
+			Expect(pcrDiffs[0].Pcr).To(Equal(pcr0))
+			Expect(pcrDiffs[0].Status).To(Equal(hvs.PcrDiffChanged))
+			Expect(pcrDiffs[0].ExpectedMeasurement).To(Equal("00"))
+			Expect(pcrDiffs[0].ActualMeasurement).To(Equal("01"))
+			Expect(pcrDiffs[0].Added).To(Equal([]types.EventLog{updatedMicrocode}))
+			Expect(pcrDiffs[0].Removed).To(Equal([]types.EventLog{microcode}))
+			Expect(pcrDiffs[0].Changed).To(Equal([]types.EventLogChange{{Expected: bootGuard, Actual: renamedBootGuard}}))
+
+			Expect(pcrDiffs[1].Pcr).To(Equal(pcr7))
+			Expect(pcrDiffs[1].Status).To(Equal(hvs.PcrDiffRemoved))
+			Expect(pcrDiffs[1].Removed).To(Equal([]types.EventLog{secureBoot}))
+		})
+
+		It("Should report the PCRs missing from the flavor", func() {
+			pcrDiffs, err := hvs.DiffFlavors(&model.Flavor{}, flavor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pcrDiffs).To(HaveLen(2))
+			Expect(pcrDiffs[0].Status).To(Equal(hvs.PcrDiffAdded))
+			Expect(pcrDiffs[0].ActualMeasurement).To(Equal("00"))
+			Expect(pcrDiffs[0].Added).To(Equal([]types.EventLog{bootGuard, microcode}))
+		})
+	})
+
+	Describe("Diffing a flavor with a host manifest", func() {
+		var hostManifest *types.HostManifest
+
+		BeforeEach(func() {
+			lcpControl := types.EventLog{TypeID: "0x40a", TypeName: "LCP_CONTROL_HASH", Tags: []string{"LCP_CONTROL_HASH"}, Measurement: "dd"}
+			extraEvent := types.EventLog{TypeID: "0x80000002", TypeName: "EV_EFI_VARIABLE_BOOT", Measurement: "ee"}
+			hostManifest = &types.HostManifest{PcrManifest: types.PcrManifest{
+				Sha256Pcrs: []types.HostManifestPcrs{
+					{Index: 0, Value: "00", PcrBank: types.SHA256},
+					{Index: 7, Value: "07", PcrBank: types.SHA256},
+				},
+				PcrEventLogMap: types.PcrEventLogMap{Sha256EventLogs: []types.TpmEventLog{
+					{Pcr: pcr0, TpmEvent: []types.EventLog{bootGuard, lcpControl, microcode}},
+					{Pcr: pcr7, TpmEvent: []types.EventLog{extraEvent, secureBoot}},
+				}},
+			}}
+		})
+
+		It("Should ignore the excluded events and the events that are not expected to be equal", func() {
+			pcrDiffs, err := hvs.DiffFlavorWithHostManifest(flavor, hostManifest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pcrDiffs).To(BeEmpty())
+		})
+
+		It("Should report the PCRs that changed and generate a candidate flavor matching the host", func() {
+			updatedMicrocode := microcode
+			updatedMicrocode.Measurement = "bc"
+			hostManifest.PcrManifest.Sha256Pcrs[0].Value = "01"
+			hostManifest.PcrManifest.PcrEventLogMap.Sha256EventLogs[0].TpmEvent[2] = updatedMicrocode
+			hostManifest.PcrManifest.Sha256Pcrs = hostManifest.PcrManifest.Sha256Pcrs[:1]
+
+			pcrDiffs, err := hvs.DiffFlavorWithHostManifest(flavor, hostManifest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pcrDiffs).To(HaveLen(2))
+			Expect(pcrDiffs[0].Status).To(Equal(hvs.PcrDiffChanged))
+			Expect(pcrDiffs[0].Added).To(Equal([]types.EventLog{updatedMicrocode}))
+			Expect(pcrDiffs[0].Removed).To(Equal([]types.EventLog{microcode}))
+			Expect(pcrDiffs[1].Pcr).To(Equal(pcr7))
+			Expect(pcrDiffs[1].Status).To(Equal(hvs.PcrDiffRemoved))
+
+			candidate := hvs.CandidateFlavor(flavor, pcrDiffs)
+			Expect(candidate.Meta.ID).To(Equal(uuid.Nil))
+			Expect(candidate.Meta.Description[model.Label]).To(Equal("platform"))
+			Expect(candidate.Pcrs).To(HaveLen(1))
+			Expect(candidate.Pcrs[0].Measurement).To(Equal("01"))
+			Expect(candidate.Pcrs[0].EventlogEqual.Events).To(Equal([]types.EventLog{bootGuard, updatedMicrocode}))
+			Expect(candidate.Pcrs[0].EventlogEqual.ExcludeTags).To(Equal([]string{"LCP_CONTROL_HASH"}))
+
+			// the flavor is left untouched
+			Expect(flavor.Pcrs).To(HaveLen(2))
+			Expect(flavor.Pcrs[0].EventlogEqual.Events).To(Equal([]types.EventLog{bootGuard, microcode}))
+
+			pcrDiffs, err = hvs.DiffFlavorWithHostManifest(candidate, hostManifest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pcrDiffs).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,46 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package hvs
+
+import (
+	"github.com/google/uuid"
+)
+
+// FlavorDryRunRequest describes a what-if flavor verification. The candidate flavors are verified along with the
+// flavors of the flavorgroup against the latest stored manifest of each selected host, nothing is persisted.
+type FlavorDryRunRequest struct {
+	FlavorgroupName string `json:"flavorgroup_name"`
+	// FlavorCollection and SignedFlavorCollection hold the candidate flavors
+	FlavorCollection       FlavorCollection       `json:"flavor_collection,omitempty"`
+	SignedFlavorCollection SignedFlavorCollection `json:"signed_flavor_collection,omitempty"`
+	// FlavorMatchPolicies replace the match policies of the flavorgroup when provided
+	FlavorMatchPolicies FlavorMatchPolicies `json:"flavor_match_policies,omitempty"`
+	// HostIds and HostNames select the hosts, all the hosts linked to the flavorgroup are selected when both are empty
+	// swagger:strfmt uuid
+	HostIds   []uuid.UUID `json:"host_ids,omitempty"`
+	HostNames []string    `json:"host_names,omitempty"`
+}
+
+// FlavorDryRunHostResult is the outcome of a flavor verification dry run for a host
+type FlavorDryRunHostResult struct {
+	// swagger:strfmt uuid
+	HostId   uuid.UUID `json:"host_id"`
+	HostName string    `json:"host_name"`
+	Trusted  bool      `json:"trusted"`
+	// FailedResults holds the untrusted rule results of the host
+	FailedResults []RuleResult `json:"failed_results,omitempty"`
+	// Error is set when the host could not be evaluated, e.g. when no manifest is stored for it
+	Error string `json:"error,omitempty"`
+}
+
+// FlavorDryRunResponse summarizes a flavor verification dry run
+type FlavorDryRunResponse struct {
+	FlavorgroupName     string                   `json:"flavorgroup_name"`
+	FlavorMatchPolicies FlavorMatchPolicies      `json:"flavor_match_policies"`
+	TrustedCount        int                      `json:"trusted_count"`
+	UntrustedCount      int                      `json:"untrusted_count"`
+	ErrorCount          int                      `json:"error_count"`
+	HostResults         []FlavorDryRunHostResult `json:"host_results"`
+}
@@ -13,6 +13,8 @@ import (
 
 type FlavorgroupCollection struct {
 	Flavorgroups []FlavorGroup `json:"flavorgroups" xml:"flavorgroup"`
+	// Next is the link to the following page of a paginated search
+	Next string `json:"next,omitempty" xml:"next,omitempty"`
 }
 
 type FlavorMatchPolicies []FlavorMatchPolicy
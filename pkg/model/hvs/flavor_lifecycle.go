@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package hvs
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// FlavorLifecycleState tells how a flavor takes part in the host trust verification
+type FlavorLifecycleState string
+
+const (
+	// FlavorStateStaged flavors are evaluated in shadow, their results do not affect the trust status
+	FlavorStateStaged FlavorLifecycleState = "staged"
+	// FlavorStateActive flavors are verified as usual
+	FlavorStateActive FlavorLifecycleState = "active"
+	// FlavorStateDeprecated flavors are still verified, but their use and their faults are reported as warnings
+	FlavorStateDeprecated FlavorLifecycleState = "deprecated"
+	// FlavorStateRevoked flavors are no longer verified, a flavor cannot leave this state
+	FlavorStateRevoked FlavorLifecycleState = "revoked"
+)
+
+func (state FlavorLifecycleState) String() string {
+	return string(state)
+}
+
+// Parse validates the given lifecycle state
+func (state *FlavorLifecycleState) Parse(s string) error {
+	switch FlavorLifecycleState(s) {
+	case FlavorStateStaged, FlavorStateActive, FlavorStateDeprecated, FlavorStateRevoked:
+		*state = FlavorLifecycleState(s)
+		return nil
+	}
+	return errors.Errorf("invalid flavor lifecycle state %s", s)
+}
+
+// CanTransitionTo tells if a flavor can be moved to the next state. A flavor can stay in the same state to change
+// its dates, except when it is revoked.
+func (state FlavorLifecycleState) CanTransitionTo(next FlavorLifecycleState) bool {
+	switch state {
+	case FlavorStateStaged:
+		return next == FlavorStateStaged || next == FlavorStateActive || next == FlavorStateRevoked
+	case FlavorStateActive, FlavorStateDeprecated:
+		return next == FlavorStateStaged || next == FlavorStateActive || next == FlavorStateDeprecated || next == FlavorStateRevoked
+	}
+	return false
+}
+
+// FlavorLifecycle holds the lifecycle state of a flavor along with the dates restricting when it applies
+type FlavorLifecycle struct {
+	// swagger:strfmt uuid
+	FlavorId  uuid.UUID            `json:"flavor_id"`
+	State     FlavorLifecycleState `json:"state"`
+	NotBefore *time.Time           `json:"not_before,omitempty"`
+	NotAfter  *time.Time           `json:"not_after,omitempty"`
+}
+
+// EffectiveState returns the state the flavor is verified with at the given time. An active or deprecated flavor
+// is evaluated in shadow until its not-before date and any flavor is handled as revoked from its not-after date.
+func (lifecycle FlavorLifecycle) EffectiveState(at time.Time) FlavorLifecycleState {
+	if lifecycle.State == FlavorStateRevoked ||
+		(lifecycle.NotAfter != nil && !at.Before(*lifecycle.NotAfter)) {
+		return FlavorStateRevoked
+	}
+	if lifecycle.NotBefore != nil && at.Before(*lifecycle.NotBefore) {
+		return FlavorStateStaged
+	}
+	return lifecycle.State
+}
+
+// FlavorLifecycleRequest moves a flavor to a lifecycle state, the dates left empty are cleared
+type FlavorLifecycleRequest struct {
+	State     FlavorLifecycleState `json:"state"`
+	NotBefore *time.Time           `json:"not_before,omitempty"`
+	NotAfter  *time.Time           `json:"not_after,omitempty"`
+}
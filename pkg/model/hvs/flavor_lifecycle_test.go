@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package hvs_test
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FlavorLifecycle", func() {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := now.Add(-time.Hour)
+	after := now.Add(time.Hour)
+
+	Describe("Effective state of a flavor", func() {
+		Context("Without dates", func() {
+			It("Should be the stored state", func() {
+				for _, state := range []hvs.FlavorLifecycleState{hvs.FlavorStateStaged, hvs.FlavorStateActive, hvs.FlavorStateDeprecated, hvs.FlavorStateRevoked} {
+					lifecycle := hvs.FlavorLifecycle{FlavorId: uuid.New(), State: state}
+					Expect(lifecycle.EffectiveState(now)).To(Equal(state))
+				}
+			})
+		})
+
+		Context("Before the not_before date", func() {
+			It("Should be evaluated in shadow", func() {
+				lifecycle := hvs.FlavorLifecycle{State: hvs.FlavorStateActive, NotBefore: &after}
+				Expect(lifecycle.EffectiveState(now)).To(Equal(hvs.FlavorStateStaged))
+				lifecycle.NotBefore = &before
+				Expect(lifecycle.EffectiveState(now)).To(Equal(hvs.FlavorStateActive))
+			})
+		})
+
+		Context("From the not_after date", func() {
+			It("Should be revoked", func() {
+				lifecycle := hvs.FlavorLifecycle{State: hvs.FlavorStateDeprecated, NotAfter: &now}
+				Expect(lifecycle.EffectiveState(now)).To(Equal(hvs.FlavorStateRevoked))
+				lifecycle.NotAfter = &after
+				Expect(lifecycle.EffectiveState(now)).To(Equal(hvs.FlavorStateDeprecated))
+			})
+		})
+	})
+
+	Describe("Lifecycle state transitions", func() {
+		It("Should allow promoting a staged flavor", func() {
+			Expect(hvs.FlavorStateStaged.CanTransitionTo(hvs.FlavorStateActive)).To(BeTrue())
+			Expect(hvs.FlavorStateStaged.CanTransitionTo(hvs.FlavorStateDeprecated)).To(BeFalse())
+		})
+
+		It("Should not allow leaving the revoked state", func() {
+			for _, state := range []hvs.FlavorLifecycleState{hvs.FlavorStateStaged, hvs.FlavorStateActive, hvs.FlavorStateDeprecated, hvs.FlavorStateRevoked} {
+				Expect(hvs.FlavorStateRevoked.CanTransitionTo(state)).To(BeFalse())
+			}
+		})
+
+		It("Should reject unknown states", func() {
+			var state hvs.FlavorLifecycleState
+			Expect(state.Parse("deprecated")).To(Succeed())
+			Expect(state).To(Equal(hvs.FlavorStateDeprecated))
+			Expect(state.Parse("retired")).NotTo(Succeed())
+		})
+	})
+})
@@ -8,6 +8,8 @@ import "github.com/google/uuid"
 
 type HostCollection struct {
 	Hosts []*Host `json:"hosts" xml:"host"`
+	// Next is the link to the following page of a paginated search
+	Next string `json:"next,omitempty" xml:"next,omitempty"`
 }
 
 type Host struct {
@@ -19,16 +21,18 @@ type Host struct {
 	// swagger:strfmt uuid
 	HardwareUuid     *uuid.UUID             `json:"hardware_uuid,omitempty"`
 	FlavorgroupNames []string               `json:"flavorgroup_names,omitempty"`
+	Labels           map[string]string      `json:"labels,omitempty"`
 	Report           *TrustReport           `json:"report,omitempty"`
 	Trusted          *bool                  `json:"trusted,omitempty"`
 	ConnectionStatus *HostStatusInformation `json:"status,omitempty"`
 }
 
 type HostCreateRequest struct {
-	HostName         string   `json:"host_name"`
-	Description      string   `json:"description,omitempty"`
-	ConnectionString string   `json:"connection_string"`
-	FlavorgroupNames []string `json:"flavorgroup_names,omitempty"`
+	HostName         string            `json:"host_name"`
+	Description      string            `json:"description,omitempty"`
+	ConnectionString string            `json:"connection_string"`
+	FlavorgroupNames []string          `json:"flavorgroup_names,omitempty"`
+	Labels           map[string]string `json:"labels,omitempty"`
 }
 
 type HostFlavorgroupCollection struct {
@@ -46,3 +50,18 @@ type HostFlavorgroupCreateRequest struct {
 	// swagger:strfmt uuid
 	FlavorgroupId uuid.UUID `json:"flavorgroup_id,omitempty"`
 }
+
+// HostLabelTrustSummary counts the hosts sharing each value of a label by trust status
+type HostLabelTrustSummary struct {
+	LabelKey string                `json:"label_key"`
+	Values   []HostLabelTrustCount `json:"values"`
+}
+
+type HostLabelTrustCount struct {
+	Value     string `json:"value"`
+	Total     int    `json:"total"`
+	Trusted   int    `json:"trusted"`
+	Untrusted int    `json:"untrusted"`
+	// Unknown counts the hosts without trust report
+	Unknown int `json:"unknown"`
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package hvs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HostImportEntry describes a host to register with a bulk host import. The username and password are added to the
+// connection string when it does not hold credentials already.
+type HostImportEntry struct {
+	HostName         string   `json:"host_name"`
+	ConnectionString string   `json:"connection_string"`
+	Description      string   `json:"description,omitempty"`
+	FlavorgroupNames []string `json:"flavorgroup_names,omitempty"`
+	Username         string   `json:"username,omitempty"`
+	Password         string   `json:"password,omitempty"`
+}
+
+// HostImportRequest lists the hosts to register with a bulk host import
+type HostImportRequest struct {
+	Hosts []HostImportEntry `json:"hosts"`
+}
+
+// HostCreateRequest returns the request registering the host, with the credentials of the entry added to the
+// connection string
+func (entry HostImportEntry) HostCreateRequest() HostCreateRequest {
+	connectionString := entry.ConnectionString
+	if entry.Username != "" && !hasCredentials(connectionString) {
+		connectionString = fmt.Sprintf("%s;u=%s;p=%s", connectionString, entry.Username, entry.Password)
+	}
+	return HostCreateRequest{
+		HostName:         entry.HostName,
+		Description:      entry.Description,
+		ConnectionString: connectionString,
+		FlavorgroupNames: entry.FlavorgroupNames,
+	}
+}
+
+func hasCredentials(connectionString string) bool {
+	for _, part := range strings.Split(connectionString, ";") {
+		if strings.HasPrefix(part, "u=") {
+			return true
+		}
+	}
+	return false
+}
+
+// HostImportJobStatus is the processing status of a bulk host import job
+type HostImportJobStatus string
+
+const (
+	HostImportJobRunning   HostImportJobStatus = "running"
+	HostImportJobCompleted HostImportJobStatus = "completed"
+)
+
+// HostImportRowStatus is the result of the registration of a host of a bulk host import job
+type HostImportRowStatus string
+
+const (
+	HostImportRowPending   HostImportRowStatus = "pending"
+	HostImportRowSucceeded HostImportRowStatus = "succeeded"
+	HostImportRowFailed    HostImportRowStatus = "failed"
+)
+
+// HostImportRow reports the registration of a host of a bulk host import job
+type HostImportRow struct {
+	// Row is the position of the host in the request, starting at 1
+	Row      int    `json:"row"`
+	HostName string `json:"host_name"`
+	// ConnectionString is the connection string of the host without credentials
+	ConnectionString string              `json:"connection_string"`
+	Status           HostImportRowStatus `json:"status"`
+	// swagger:strfmt uuid
+	HostId   *uuid.UUID `json:"host_id,omitempty"`
+	Error    string     `json:"error,omitempty"`
+	Attempts int        `json:"attempts"`
+	// Entry is the requested host, it holds the credentials and is never reported
+	Entry HostImportEntry `json:"-"`
+}
+
+// HostImportJob reports the progress of a bulk host import
+type HostImportJob struct {
+	// swagger:strfmt uuid
+	ID        uuid.UUID           `json:"id"`
+	Status    HostImportJobStatus `json:"status"`
+	Created   time.Time           `json:"created"`
+	Updated   time.Time           `json:"updated"`
+	Total     int                 `json:"total"`
+	Pending   int                 `json:"pending"`
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	Rows      []HostImportRow     `json:"rows"`
+}
+
+// CountRows sets the number of rows of the job in each status
+func (job *HostImportJob) CountRows() {
+	job.Total, job.Pending, job.Succeeded, job.Failed = len(job.Rows), 0, 0, 0
+	for _, row := range job.Rows {
+		switch row.Status {
+		case HostImportRowPending:
+			job.Pending++
+		case HostImportRowSucceeded:
+			job.Succeeded++
+		case HostImportRowFailed:
+			job.Failed++
+		}
+	}
+}
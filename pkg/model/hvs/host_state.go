@@ -80,6 +80,11 @@ func (s HostState) Valid() bool {
 	return s >= HostStateInvalid && s <= HostStateTPMNotSupported
 }
 
+// IsConnectionFailure returns whether the state reports a failure to connect to or to attest the host
+func (s HostState) IsConnectionFailure() bool {
+	return s >= HostStateConnectionFailure && s.Valid()
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (s HostState) MarshalJSON() ([]byte, error) {
 	buffer := bytes.NewBufferString(`"`)
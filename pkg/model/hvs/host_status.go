@@ -32,4 +32,6 @@ type HostStatus struct {
 // HostStatusCollection holds a collection of HostStatus in response to an API query
 type HostStatusCollection struct {
 	HostStatuses []HostStatus `json:"host_status" xml:"host_status"`
+	// Next is the link to the following page of a paginated search
+	Next string `json:"next,omitempty" xml:"next,omitempty"`
 }
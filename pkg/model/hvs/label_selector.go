@@ -0,0 +1,179 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package hvs
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MaxHostLabels is the maximum number of labels of a host
+const MaxHostLabels = 64
+
+var (
+	labelKeyReg       = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,61}[A-Za-z0-9])?$`)
+	labelValueReg     = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]{0,61}[A-Za-z0-9])?$`)
+	setRequirementReg = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+)
+
+// LabelOperator is the comparison applied by a LabelRequirement
+type LabelOperator string
+
+const (
+	LabelOperatorEquals    LabelOperator = "="
+	LabelOperatorNotEquals LabelOperator = "!="
+	LabelOperatorIn        LabelOperator = "in"
+	LabelOperatorNotIn     LabelOperator = "notin"
+)
+
+// LabelRequirement compares the value of a label with the given values. The != and notin requirements are met by the
+// hosts that do not have the label.
+type LabelRequirement struct {
+	Key      string
+	Operator LabelOperator
+	Values   []string
+}
+
+// LabelSelector selects the hosts meeting all of its requirements, e.g. "env=prod,rack in (12,13),cluster!=a"
+type LabelSelector []LabelRequirement
+
+// ValidateLabels checks the labels of a host, keys and values being made of at most 63 alphanumeric characters,
+// '-', '_' and '.', keys also allowing '/'
+func ValidateLabels(labels map[string]string) error {
+	if len(labels) > MaxHostLabels {
+		return errors.Errorf("At most %d labels can be set on a host", MaxHostLabels)
+	}
+	for key, value := range labels {
+		if err := ValidateLabelKey(key); err != nil {
+			return err
+		}
+		if !labelValueReg.MatchString(value) {
+			return errors.Errorf("Invalid value for label %q", key)
+		}
+	}
+	return nil
+}
+
+// ValidateLabelKey checks the key of a label
+func ValidateLabelKey(key string) error {
+	if !labelKeyReg.MatchString(key) {
+		return errors.Errorf("Invalid label key %q", key)
+	}
+	return nil
+}
+
+// ParseLabelSelector parses comma separated requirements of the form "key=value", "key!=value",
+// "key in (value1,value2)" or "key notin (value1,value2)"
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	var requirements LabelSelector
+	for _, requirement := range splitRequirements(selector) {
+		requirement = strings.TrimSpace(requirement)
+		if requirement == "" {
+			return nil, errors.New("Empty label selector requirement")
+		}
+		parsed, err := parseRequirement(requirement)
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, *parsed)
+	}
+	if len(requirements) == 0 {
+		return nil, errors.New("Empty label selector")
+	}
+	return requirements, nil
+}
+
+// splitRequirements splits the selector on the commas that are not part of a set of values
+func splitRequirements(selector string) []string {
+	var requirements []string
+	depth, start := 0, 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				requirements = append(requirements, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(requirements, selector[start:])
+}
+
+func parseRequirement(requirement string) (*LabelRequirement, error) {
+	var parsed LabelRequirement
+	if match := setRequirementReg.FindStringSubmatch(requirement); match != nil {
+		parsed = LabelRequirement{Key: match[1], Operator: LabelOperator(match[2])}
+		for _, value := range strings.Split(match[3], ",") {
+			parsed.Values = append(parsed.Values, strings.TrimSpace(value))
+		}
+	} else if i := strings.Index(requirement, "!="); i > 0 {
+		parsed = LabelRequirement{Key: strings.TrimSpace(requirement[:i]), Operator: LabelOperatorNotEquals,
+			Values: []string{strings.TrimSpace(requirement[i+2:])}}
+	} else if i := strings.Index(requirement, "="); i > 0 {
+		parsed = LabelRequirement{Key: strings.TrimSpace(requirement[:i]), Operator: LabelOperatorEquals,
+			Values: []string{strings.TrimSpace(requirement[i+1:])}}
+	} else {
+		return nil, errors.Errorf("Invalid label selector requirement %q", requirement)
+	}
+
+	if !labelKeyReg.MatchString(parsed.Key) {
+		return nil, errors.Errorf("Invalid label key %q in label selector", parsed.Key)
+	}
+	for _, value := range parsed.Values {
+		if !labelValueReg.MatchString(value) {
+			return nil, errors.Errorf("Invalid value for label %q in label selector", parsed.Key)
+		}
+	}
+	return &parsed, nil
+}
+
+// Matches returns whether the labels meet all the requirements of the selector
+func (selector LabelSelector) Matches(labels map[string]string) bool {
+	for _, requirement := range selector {
+		value, ok := labels[requirement.Key]
+		in := false
+		for _, v := range requirement.Values {
+			if ok && v == value {
+				in = true
+				break
+			}
+		}
+		switch requirement.Operator {
+		case LabelOperatorEquals, LabelOperatorIn:
+			if !in {
+				return false
+			}
+		case LabelOperatorNotEquals, LabelOperatorNotIn:
+			if in {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (selector LabelSelector) String() string {
+	requirements := make([]string, 0, len(selector))
+	for _, requirement := range selector {
+		switch requirement.Operator {
+		case LabelOperatorIn, LabelOperatorNotIn:
+			values := append([]string(nil), requirement.Values...)
+			sort.Strings(values)
+			requirements = append(requirements, requirement.Key+" "+string(requirement.Operator)+" ("+strings.Join(values, ",")+")")
+		default:
+			requirements = append(requirements, requirement.Key+string(requirement.Operator)+strings.Join(requirement.Values, ""))
+		}
+	}
+	return strings.Join(requirements, ",")
+}
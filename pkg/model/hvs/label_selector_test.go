@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package hvs_test
+
+import (
+	"fmt"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/model/hvs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LabelSelector", func() {
+	Describe("Parse a label selector", func() {
+		Context("With valid requirements", func() {
+			It("Should parse each operator", func() {
+				selector, err := hvs.ParseLabelSelector("env=prod, rack in (12, 13),cluster != a,zone notin (east)")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(selector).To(Equal(hvs.LabelSelector{
+					{Key: "env", Operator: hvs.LabelOperatorEquals, Values: []string{"prod"}},
+					{Key: "rack", Operator: hvs.LabelOperatorIn, Values: []string{"12", "13"}},
+					{Key: "cluster", Operator: hvs.LabelOperatorNotEquals, Values: []string{"a"}},
+					{Key: "zone", Operator: hvs.LabelOperatorNotIn, Values: []string{"east"}},
+				}))
+				Expect(selector.String()).To(Equal("env=prod,rack in (12,13),cluster!=a,zone notin (east)"))
+			})
+		})
+
+		Context("With invalid requirements", func() {
+			It("Should fail", func() {
+				for _, selector := range []string{"", "env", "env=prod,", "=prod", "env=", "env in ()", "env in (a,)",
+					"env==prod", "e nv=prod", "env=pr od", "env in (a", "-env=prod"} {
+					_, err := hvs.ParseLabelSelector(selector)
+					Expect(err).To(HaveOccurred(), selector)
+				}
+			})
+		})
+	})
+
+	Describe("Match labels", func() {
+		labels := map[string]string{"env": "prod", "rack": "12"}
+
+		It("Should meet all the requirements", func() {
+			for selector, matches := range map[string]bool{
+				"env=prod":                    true,
+				"env=dev":                     false,
+				"env!=dev":                    true,
+				"env!=prod":                   false,
+				"rack in (11,12)":             true,
+				"rack notin (11,12)":          false,
+				"cluster!=a":                  true,
+				"cluster notin (a)":           true,
+				"cluster in (a)":              false,
+				"env=prod,rack in (11,12)":    true,
+				"env=prod,rack notin (11,12)": false,
+			} {
+				parsed, err := hvs.ParseLabelSelector(selector)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(parsed.Matches(labels)).To(Equal(matches), selector)
+			}
+		})
+	})
+
+	Describe("Validate host labels", func() {
+		It("Should accept valid labels", func() {
+			Expect(hvs.ValidateLabels(nil)).To(Succeed())
+			Expect(hvs.ValidateLabels(map[string]string{"env": "prod", "example.com/rack": "12"})).To(Succeed())
+		})
+
+		It("Should reject invalid labels", func() {
+			Expect(hvs.ValidateLabels(map[string]string{"env": ""})).NotTo(Succeed())
+			Expect(hvs.ValidateLabels(map[string]string{"env prod": "a"})).NotTo(Succeed())
+			Expect(hvs.ValidateLabels(map[string]string{"rack": "a/b"})).NotTo(Succeed())
+
+			labels := make(map[string]string)
+			for i := 0; i <= hvs.MaxHostLabels; i++ {
+				labels[fmt.Sprintf("label-%d", i)] = "a"
+			}
+			Expect(hvs.ValidateLabels(labels)).NotTo(Succeed())
+		})
+	})
+})
@@ -14,6 +14,8 @@ import (
 
 type ReportCollection struct {
 	Reports []*Report `json:"reports" xml:"reports"`
+	// Next is the link to the following page of a paginated search
+	Next string `json:"next,omitempty" xml:"next,omitempty"`
 }
 
 type Report struct {
@@ -45,4 +47,12 @@ type ReportCreateRequest struct {
 	// swagger:strfmt uuid
 	HardwareUUID uuid.UUID `json:"hardware_uuid"`
 	HostName     string    `json:"host_name"`
+	// LabelSelector queues for verification all the hosts it selects, e.g. "env=prod,rack in (12,13)"
+	LabelSelector string `json:"label_selector,omitempty"`
+}
+
+// ReportBulkCreateResponse lists the hosts queued for verification by a report create request with a label selector
+type ReportBulkCreateResponse struct {
+	// swagger:strfmt uuid
+	HostIDs []uuid.UUID `json:"host_ids"`
 }
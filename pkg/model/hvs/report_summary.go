@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package hvs
+
+import "time"
+
+// ReportSummary holds the aggregate trust counts of the hosts. Each host is counted once in the top level counts, the
+// hosts whose latest connection attempt failed being counted as connection failures whatever their last report, and
+// the hosts without a report being unknown.
+type ReportSummary struct {
+	Total             int `json:"total"`
+	Trusted           int `json:"trusted"`
+	Untrusted         int `json:"untrusted"`
+	Unknown           int `json:"unknown"`
+	ConnectionFailure int `json:"connection_failure"`
+	// FlavorParts counts the hosts trusted and untrusted for each flavor part of their latest report
+	FlavorParts []FlavorPartTrustCount `json:"flavor_parts,omitempty"`
+	// FailingRules counts the hosts whose latest report has faults for each rule, most failing first
+	FailingRules []RuleFailureCount `json:"failing_rules,omitempty"`
+	// HostStates counts the hosts in each connection state
+	HostStates []HostStateCount `json:"host_states,omitempty"`
+	// TimeBuckets counts the hosts attested or failing to connect over the requested date range
+	TimeBuckets []TrustTimeBucket `json:"time_buckets,omitempty"`
+}
+
+type FlavorPartTrustCount struct {
+	FlavorPart string `json:"flavor_part"`
+	Trusted    int    `json:"trusted"`
+	Untrusted  int    `json:"untrusted"`
+}
+
+type RuleFailureCount struct {
+	RuleName string `json:"rule_name"`
+	Hosts    int    `json:"hosts"`
+}
+
+type HostStateCount struct {
+	HostState string `json:"host_state"`
+	Hosts     int    `json:"hosts"`
+}
+
+// TrustTimeBucket counts the distinct hosts that got a trusted report, an untrusted report or a connection failure
+// during the bucket, a host being counted in each of them it went through
+type TrustTimeBucket struct {
+	Start             time.Time `json:"start"`
+	Trusted           int       `json:"trusted"`
+	Untrusted         int       `json:"untrusted"`
+	ConnectionFailure int       `json:"connection_failure"`
+}
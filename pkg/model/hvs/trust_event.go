@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package hvs
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of the events emitted by HVS when the trust or the connection state of a host changes
+const (
+	TrustEventHostTrustChanged = "host-trust-changed"
+	TrustEventHostStateChanged = "host-state-changed"
+)
+
+// TrustEventTypes lists all the supported event types
+var TrustEventTypes = []string{TrustEventHostTrustChanged, TrustEventHostStateChanged}
+
+// TrustEvent is emitted whenever a new report changes the trust status of a host, or whenever the
+// connection state of a host changes
+type TrustEvent struct {
+	// swagger:strfmt uuid
+	ID      uuid.UUID `json:"id"`
+	Type    string    `json:"type"`
+	Created time.Time `json:"created"`
+	// swagger:strfmt uuid
+	HostID uuid.UUID `json:"host_id"`
+	// swagger:strfmt uuid
+	ReportID *uuid.UUID `json:"report_id,omitempty"`
+	Trusted  *bool      `json:"trusted,omitempty"`
+	// PreviousTrusted is not set for the first report of a host
+	PreviousTrusted   *bool  `json:"previous_trusted,omitempty"`
+	HostState         string `json:"host_state,omitempty"`
+	PreviousHostState string `json:"previous_host_state,omitempty"`
+}
+
+// EventSubscription registers a webhook to which the trust events are delivered
+type EventSubscription struct {
+	// swagger:strfmt uuid
+	ID  uuid.UUID `json:"id,omitempty"`
+	URL string    `json:"url"`
+	// Secret is the key used to sign the payloads delivered to the webhook with HMAC-SHA256. It is only
+	// provided on creation or update and is never returned by HVS.
+	Secret string `json:"secret,omitempty"`
+	// EventTypes restricts the events delivered to the webhook, all the events are delivered when empty
+	EventTypes []string  `json:"event_types,omitempty"`
+	Created    time.Time `json:"created,omitempty"`
+}
+
+type EventSubscriptionCollection struct {
+	EventSubscriptions []*EventSubscription `json:"event_subscriptions" xml:"event_subscription"`
+}
@@ -9,6 +9,8 @@ package hvs
 //
 
 import (
+	"reflect"
+
 	"github.com/google/uuid"
 	constants "github.com/intel-secl/intel-secl/v4/pkg/hvs/constants/verifier-rules-and-faults"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/common"
@@ -21,6 +23,10 @@ type TrustReport struct {
 	Results      []RuleResult       `json:"results"`
 	Trusted      bool               `json:"trusted"`
 	HostManifest types.HostManifest `json:"host_manifest"`
+	// Warnings do not affect the trust status, e.g. a host trusted by a deprecated flavor
+	Warnings []TrustWarning `json:"warnings,omitempty"`
+	// ShadowResults are the results of the staged flavors, they do not affect the trust status
+	ShadowResults []RuleResult `json:"shadow_results,omitempty"`
 }
 
 type RuleResult struct {
@@ -79,6 +85,13 @@ type Fault struct {
 	MeasurementDigestAlg   *string                `json:"measurement_digest_alg,omitempty"`
 }
 
+type TrustWarning struct {
+	Name        string     `json:"warning_name"`
+	Description string     `json:"description"`
+	FlavorId    *uuid.UUID `json:"flavor_id,omitempty"`
+	Faults      []Fault    `json:"faults,omitempty"`
+}
+
 func NewTrustReport(report TrustReport) *TrustReport {
 	return &TrustReport{PolicyName: report.PolicyName, Results: report.Results, Trusted: report.Trusted}
 }
@@ -163,6 +176,22 @@ func (t *TrustReport) AddResult(ruleResult RuleResult) {
 	}
 }
 
+// AddWarnings adds the warnings that are not reported yet for the same flavor
+func (t *TrustReport) AddWarnings(warnings []TrustWarning) {
+	for _, warning := range warnings {
+		exists := false
+		for _, reported := range t.Warnings {
+			if reported.Name == warning.Name && reflect.DeepEqual(reported.FlavorId, warning.FlavorId) {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			t.Warnings = append(t.Warnings, warning)
+		}
+	}
+}
+
 func find(slice []common.FlavorPart, val string) bool {
 	for _, item := range slice {
 		if item.String() == val {
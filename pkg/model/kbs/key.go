@@ -28,17 +28,39 @@ type KeyRequest struct {
 	TransferPolicyID uuid.UUID `json:"transfer_policy_id,omitempty"`
 	Label            string    `json:"label,omitempty"`
 	Usage            string    `json:"usage,omitempty"`
+	// Structured usage policy, supersedes the tag:value list of usage
+	UsagePolicy *KeyUsagePolicy `json:"usage_policy,omitempty"`
+	// Interval of the automatic rotation of the key as a duration, e.g. 720h
+	RotationInterval string `json:"rotation_interval,omitempty"`
+}
+
+// KeyRotateRequest - Optional attributes of a key rotate request.
+type KeyRotateRequest struct {
+	// Replaces the interval of the automatic rotation of the key, an empty interval disables the automatic rotation
+	RotationInterval *string `json:"rotation_interval,omitempty"`
+}
+
+// KeyVersionInformation - Contains the attributes of a version of a key.
+type KeyVersionInformation struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // KeyResponse - key attributes from key create or register response.
 type KeyResponse struct {
 	KeyInformation *KeyInformation `json:"key_information"`
 	// swagger:strfmt uuid
-	TransferPolicyID uuid.UUID `json:"transfer_policy_id"`
-	TransferLink     string    `json:"transfer_link"`
-	CreatedAt        time.Time `json:"created_at"`
-	Label            string    `json:"label,omitempty"`
-	Usage            string    `json:"usage,omitempty"`
+	TransferPolicyID uuid.UUID       `json:"transfer_policy_id"`
+	TransferLink     string          `json:"transfer_link"`
+	CreatedAt        time.Time       `json:"created_at"`
+	Label            string          `json:"label,omitempty"`
+	Usage            string          `json:"usage,omitempty"`
+	UsagePolicy      *KeyUsagePolicy `json:"usage_policy,omitempty"`
+	// Active version of the key
+	Version          int                     `json:"version"`
+	Versions         []KeyVersionInformation `json:"versions,omitempty"`
+	RotationInterval string                  `json:"rotation_interval,omitempty"`
+	NextRotation     *time.Time              `json:"next_rotation,omitempty"`
 }
 
 // KeyTransferAttributes - Contains all possible key transfer attributes.
@@ -50,6 +72,7 @@ type KeyTransferAttributes struct {
 	KeyAlgorithm string     `json:"algorithm,omitempty"`
 	KeyLength    int        `json:"key_length,omitempty"`
 	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	Version      int        `json:"version,omitempty"`
 	Policy       struct {
 		Link struct {
 			KeyTransfer struct {
@@ -0,0 +1,36 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package kbs
+
+// KeyUsagePolicy - Conditions the SAML report of a host must satisfy for the key to be transferred to it, all the
+// clauses which are set must be satisfied.
+type KeyUsagePolicy struct {
+	AssetTags *AssetTagPolicy `json:"asset_tags,omitempty"`
+	// Flavor parts for which the host must be trusted, e.g. PLATFORM, OS
+	RequiredTrustedFlavorParts []string `json:"required_trusted_flavor_parts,omitempty"`
+	// Maximum age of the SAML report as a duration, e.g. 10m
+	MaxReportAge string `json:"max_report_age,omitempty"`
+	// Allowed values of the hardware features of the host, keyed by the name of the FEATURE_ attribute without the
+	// prefix, e.g. {"TXT": ["true"], "cbntProfile": ["BTGP3", "BTGP5"]}
+	AllowedHardwareFeatures map[string][]string `json:"allowed_hardware_features,omitempty"`
+	// Subject common names or distinguished names of the CAs allowed to issue the AIK certificate of the host
+	AllowedAikIssuers []string `json:"allowed_aik_issuers,omitempty"`
+}
+
+// AssetTagPolicy - Asset tags the host must, may or must not have deployed.
+type AssetTagPolicy struct {
+	// All of the tags must be deployed on the host
+	AllOf []AssetTag `json:"all_of,omitempty"`
+	// At least one of the tags must be deployed on the host
+	AnyOf []AssetTag `json:"any_of,omitempty"`
+	// None of the tags may be deployed on the host
+	NoneOf []AssetTag `json:"none_of,omitempty"`
+}
+
+// AssetTag - Key value pair of an asset tag, both are compared case-insensitively.
+type AssetTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
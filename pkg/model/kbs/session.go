@@ -6,14 +6,31 @@ package kbs
 
 import (
 	"time"
+
+	"github.com/google/uuid"
 )
 
+// KeyTransferSession is an SKC session, it holds the quote verification attributes and the session wrapping key once
+// the enclave has been attested
 type KeyTransferSession struct {
-	SWK               []byte `json:"swk"`
-	SessionId         string `json:"sessionid"`
-	ClientCertHash    string `json:"clientcerthash"`
-	Stmlabel          string `json:"stmlabel"`
-	SessionExpiryTime time.Time
+	SWK                   []byte    `json:"swk"`
+	SessionId             string    `json:"sessionid"`
+	ClientCertHash        string    `json:"clientcerthash"`
+	Stmlabel              string    `json:"stmlabel"`
+	CreatedAt             time.Time `json:"created"`
+	SessionExpiryTime     time.Time
+	QuoteVerifyAttributes *QuoteVerifyAttributes `json:"quote_verify_attributes,omitempty"`
+}
+
+// SessionInfo describes an SKC session to the administrators, the session wrapping key is never exposed
+type SessionInfo struct {
+	ID             uuid.UUID `json:"id"`
+	StmLabel       string    `json:"stm_label"`
+	ClientCertHash string    `json:"client_cert_hash"`
+	Attested       bool      `json:"attested"`
+	TCBLevel       string    `json:"tcb_level,omitempty"`
+	CreatedAt      time.Time `json:"created"`
+	ExpiresAt      time.Time `json:"expires"`
 }
 
 type ChallengeRequest struct {
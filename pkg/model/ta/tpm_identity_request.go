@@ -33,6 +33,8 @@ type IdentityRequest struct {
 	TpmVersion string `json:"tpm_version"`
 	AikModulus []byte `json:"aik_modulus"`
 	AikName    []byte `json:"aik_name"`
+	// AikPublicArea is the TPMT_PUBLIC of the AIK, required for the ECC AIKs which have no modulus
+	AikPublicArea []byte `json:"aik_public_area,omitempty"`
 }
 
 type IdentityChallengePayload struct {
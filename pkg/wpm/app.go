@@ -5,21 +5,27 @@
 package wpm
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	commLog "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/message"
 	commLogInt "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log/setup"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/setup"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/validation"
 	"github.com/intel-secl/intel-secl/v4/pkg/wpm/config"
+	consts "github.com/intel-secl/intel-secl/v4/pkg/wpm/constants"
 	"github.com/intel-secl/intel-secl/v4/pkg/wpm/imageflavor"
+	"github.com/intel-secl/intel-secl/v4/pkg/wpm/keyprovider"
 	"github.com/intel-secl/intel-secl/v4/pkg/wpm/util"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"io"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -150,6 +156,8 @@ func (a *App) createImageFlavor(args []string) error {
 	flag.StringVar(outputEncImageFilename, "encout", "", "output encrypted image file name")
 	keyID := flag.String("k", "", "existing key ID")
 	flag.StringVar(keyID, "key", "", "existing key ID")
+	encryptionVersion := flag.String("V", crypt.EncryptionHeaderVersion, "encryption header version")
+	flag.StringVar(encryptionVersion, "encryption-version", crypt.EncryptionHeaderVersion, "encryption header version")
 	flag.Usage = func() { a.printImageFlavorUsage() }
 	err := flag.CommandLine.Parse(args[2:])
 	if err != nil {
@@ -180,8 +188,14 @@ func (a *App) createImageFlavor(args []string) error {
 		}
 	}
 
+	if *encryptionVersion != crypt.EncryptionHeaderVersion && *encryptionVersion != crypt.EncryptionHeaderVersionV2 {
+		log.Errorf("app:createImageFlavor() %s : Error creating VM image flavor: Invalid encryption header version - %s\n", message.InvalidInputBadParam, *encryptionVersion)
+		a.printImageFlavorUsage()
+		return errors.New("Error creating VM image flavor: Invalid encryption header version")
+	}
+
 	imageFlavor, err := imageflavor.CreateImageFlavor(*flavorLabel, *outputFlavorFilename, *inputImageFilename,
-		*outputEncImageFilename, *keyID, false)
+		*outputEncImageFilename, *keyID, false, *encryptionVersion)
 	if err != nil {
 		log.WithError(err).Errorf("app:createImageFlavor() %s - Error creating VM image flavor: %s\n", message.AppRuntimeErr, err.Error())
 		a.printImageFlavorUsage()
@@ -193,6 +207,224 @@ func (a *App) createImageFlavor(args []string) error {
 	return nil
 }
 
+func (a *App) decryptImage(args []string) error {
+	inputImageFilePath := flag.String("i", "", "input encrypted image file path")
+	flag.StringVar(inputImageFilePath, "in", "", "input encrypted image file path")
+	outputImageFilePath := flag.String("o", "", "output decrypted image file path")
+	flag.StringVar(outputImageFilePath, "out", "", "output decrypted image file path")
+	keyID := flag.String("k", "", "key ID")
+	flag.StringVar(keyID, "key", "", "key ID")
+	flavorFilePath := flag.String("f", "", "image flavor file path")
+	flag.StringVar(flavorFilePath, "flavor", "", "image flavor file path")
+	flag.Usage = func() { a.printDecryptImageUsage() }
+	err := flag.CommandLine.Parse(args[2:])
+	if err != nil {
+		a.printDecryptImageUsage()
+		return errors.Wrap(err, "Error parsing arguments")
+	}
+
+	if len(strings.TrimSpace(*inputImageFilePath)) <= 0 || len(strings.TrimSpace(*outputImageFilePath)) <= 0 {
+		log.Errorf("app:decryptImage() %s : Error decrypting VM image: Missing arguments input and output image file path\n", message.InvalidInputBadParam)
+		a.printDecryptImageUsage()
+		return errors.New("Error decrypting VM image: Missing arguments input and output image file path")
+	}
+
+	// validate input strings
+	inputArr := []string{*inputImageFilePath, *outputImageFilePath, *flavorFilePath}
+	if validationErr := validation.ValidateStrings(inputArr); validationErr != nil {
+		log.WithError(validationErr).Errorf("app:decryptImage() %s : Error decrypting VM image. Parse error for input args: [ %s ] - %s\n", message.InvalidInputBadParam, inputArr, validationErr.Error())
+		a.printDecryptImageUsage()
+		return errors.Wrap(validationErr, "Error decrypting VM image: Invalid input arguments format")
+	}
+
+	//The key ID is taken from the key URL of the image flavor when it is not specified, the flavor must be signed
+	//with the flavor signing key so that the key URL can be trusted
+	if len(strings.TrimSpace(*keyID)) <= 0 && len(strings.TrimSpace(*flavorFilePath)) > 0 {
+		signedFlavor, err := imageflavor.VerifyImageFlavor(*flavorFilePath, "", consts.FlavorSigningCertFile)
+		if err != nil {
+			a.printDecryptImageUsage()
+			return errors.Wrap(err, "Error decrypting VM image")
+		}
+		*keyID, err = imageflavor.KeyIDFromImageFlavor(signedFlavor)
+		if err != nil {
+			a.printDecryptImageUsage()
+			return errors.Wrap(err, "Error decrypting VM image")
+		}
+	}
+	if validatekeyIDErr := validation.ValidateUUIDv4(*keyID); validatekeyIDErr != nil {
+		log.WithError(validatekeyIDErr).Errorf("app:decryptImage() %s : Error decrypting VM image: Invalid UUID - %s\n", message.InvalidInputBadParam, *keyID)
+		a.printDecryptImageUsage()
+		return errors.Wrap(validatekeyIDErr, "Error decrypting VM image: Invalid key UUID")
+	}
+
+	wrappedKey, _, err := util.FetchKey(*keyID, "")
+	if err != nil {
+		log.WithError(err).Errorf("app:decryptImage() %s - Error fetching key: %s\n", message.AppRuntimeErr, err.Error())
+		return errors.Wrap(err, "Error decrypting VM image: Fetch key failed")
+	}
+	err = util.Decrypt(filepath.Clean(*inputImageFilePath), consts.EnvelopePrivatekeyLocation, filepath.Clean(*outputImageFilePath), wrappedKey)
+	if err != nil {
+		log.WithError(err).Errorf("app:decryptImage() %s - Error decrypting VM image: %s\n", message.AppRuntimeErr, err.Error())
+		return errors.Wrap(err, "Error decrypting VM image")
+	}
+	fmt.Fprintln(a.consoleWriter(), "Image decrypted to", *outputImageFilePath)
+	return nil
+}
+
+func (a *App) verifyImageFlavor(args []string) error {
+	flavorFilePath := flag.String("f", "", "image flavor file path")
+	flag.StringVar(flavorFilePath, "flavor", "", "image flavor file path")
+	imageFilePath := flag.String("i", "", "image file path")
+	flag.StringVar(imageFilePath, "in", "", "image file path")
+	signingCertFilePath := flag.String("c", consts.FlavorSigningCertFile, "flavor signing certificate file path")
+	flag.StringVar(signingCertFilePath, "cert", consts.FlavorSigningCertFile, "flavor signing certificate file path")
+	flag.Usage = func() { a.printVerifyImageFlavorUsage() }
+	err := flag.CommandLine.Parse(args[2:])
+	if err != nil {
+		a.printVerifyImageFlavorUsage()
+		return errors.Wrap(err, "Error parsing arguments")
+	}
+
+	if len(strings.TrimSpace(*flavorFilePath)) <= 0 || len(strings.TrimSpace(*signingCertFilePath)) <= 0 {
+		log.Errorf("app:verifyImageFlavor() %s : Error verifying VM image flavor: Missing arguments flavor and certificate file path\n", message.InvalidInputBadParam)
+		a.printVerifyImageFlavorUsage()
+		return errors.New("Error verifying VM image flavor: Missing arguments flavor and certificate file path")
+	}
+
+	// validate input strings
+	inputArr := []string{*flavorFilePath, *imageFilePath, *signingCertFilePath}
+	if validationErr := validation.ValidateStrings(inputArr); validationErr != nil {
+		log.WithError(validationErr).Errorf("app:verifyImageFlavor() %s : Error verifying VM image flavor. Parse error for input args: [ %s ] - %s\n", message.InvalidInputBadParam, inputArr, validationErr.Error())
+		a.printVerifyImageFlavorUsage()
+		return errors.Wrap(validationErr, "Error verifying VM image flavor: Invalid input arguments format")
+	}
+
+	_, err = imageflavor.VerifyImageFlavor(*flavorFilePath, *imageFilePath, *signingCertFilePath)
+	if err != nil {
+		log.WithError(err).Errorf("app:verifyImageFlavor() %s - Error verifying VM image flavor: %s\n", message.AppRuntimeErr, err.Error())
+		return errors.Wrap(err, "Error verifying VM image flavor")
+	}
+	fmt.Fprintln(a.consoleWriter(), "Image flavor verified")
+	return nil
+}
+
+func (a *App) inspect(args []string) error {
+	imageFilePath := flag.String("i", "", "image file path")
+	flag.StringVar(imageFilePath, "in", "", "image file path")
+	flavorFilePath := flag.String("f", "", "image flavor file path")
+	flag.StringVar(flavorFilePath, "flavor", "", "image flavor file path")
+	flag.Usage = func() { a.printInspectUsage() }
+	err := flag.CommandLine.Parse(args[2:])
+	if err != nil {
+		a.printInspectUsage()
+		return errors.Wrap(err, "Error parsing arguments")
+	}
+
+	if len(strings.TrimSpace(*imageFilePath)) <= 0 && len(strings.TrimSpace(*flavorFilePath)) <= 0 {
+		log.Errorf("app:inspect() %s : Error inspecting VM image: Missing arguments image or flavor file path\n", message.InvalidInputBadParam)
+		a.printInspectUsage()
+		return errors.New("Error inspecting VM image: Missing arguments image or flavor file path")
+	}
+
+	// validate input strings
+	inputArr := []string{*imageFilePath, *flavorFilePath}
+	if validationErr := validation.ValidateStrings(inputArr); validationErr != nil {
+		log.WithError(validationErr).Errorf("app:inspect() %s : Error inspecting VM image. Parse error for input args: [ %s ] - %s\n", message.InvalidInputBadParam, inputArr, validationErr.Error())
+		a.printInspectUsage()
+		return errors.Wrap(validationErr, "Error inspecting VM image: Invalid input arguments format")
+	}
+
+	imageInfo, err := imageflavor.InspectImage(*imageFilePath, *flavorFilePath)
+	if err != nil {
+		log.WithError(err).Errorf("app:inspect() %s - Error inspecting VM image: %s\n", message.AppRuntimeErr, err.Error())
+		return errors.Wrap(err, "Error inspecting VM image")
+	}
+	imageInfoJSON, err := json.MarshalIndent(imageInfo, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "Error while marshalling image information")
+	}
+	fmt.Fprintln(a.consoleWriter(), string(imageInfoJSON))
+	return nil
+}
+
+func (a *App) createContainerImageFlavor(args []string) error {
+	imageName := flag.String("i", "", "container image name")
+	flag.StringVar(imageName, "img-name", "", "container image name")
+	tag := flag.String("t", "", "container image tag name")
+	flag.StringVar(tag, "tag", "", "container image tag name")
+	keyID := flag.String("k", "", "existing key ID")
+	flag.StringVar(keyID, "key-id", "", "existing key ID")
+	encryptionRequired := flag.Bool("e", false, "container image needs to be encrypted")
+	flag.BoolVar(encryptionRequired, "encryption-required", false, "container image needs to be encrypted")
+	integrityEnforced := flag.Bool("s", false, "container image should be signed")
+	flag.BoolVar(integrityEnforced, "integrity-enforced", false, "container image should be signed")
+	notaryURL := flag.String("n", "", "notary server url")
+	flag.StringVar(notaryURL, "notary-server", "", "notary server url")
+	outputFlavorFilename := flag.String("o", "", "output flavor file name")
+	flag.StringVar(outputFlavorFilename, "out-file", "", "output flavor file name")
+	flag.Usage = func() { a.printContainerFlavorUsage() }
+	err := flag.CommandLine.Parse(args[2:])
+	if err != nil {
+		a.printContainerFlavorUsage()
+		return errors.Wrap(err, "Error parsing arguments")
+	}
+
+	if len(strings.TrimSpace(*imageName)) <= 0 {
+		log.Errorf("app:createContainerImageFlavor() %s : Error creating container image flavor: Missing argument container image name\n", message.InvalidInputBadParam)
+		a.printContainerFlavorUsage()
+		return errors.New("Error creating container image flavor: Missing argument container image name")
+	}
+
+	// validate input strings
+	inputArr := []string{*imageName, *tag, *outputFlavorFilename}
+	if validationErr := validation.ValidateStrings(inputArr); validationErr != nil {
+		log.WithError(validationErr).Errorf("app:createContainerImageFlavor() %s : Error creating container image flavor. Parse error for input args: [ %s ] - %s\n", message.InvalidInputBadParam, inputArr, validationErr.Error())
+		a.printContainerFlavorUsage()
+		return errors.Wrap(validationErr, "Error creating container image flavor: Invalid input arguments format")
+	}
+
+	//If the notary server URL is specified, make sure it's a valid URL
+	if len(strings.TrimSpace(*notaryURL)) > 0 {
+		if _, validationErr := url.ParseRequestURI(*notaryURL); validationErr != nil {
+			log.WithError(validationErr).Errorf("app:createContainerImageFlavor() %s : Error creating container image flavor: Invalid notary server URL - %s\n", message.InvalidInputBadParam, *notaryURL)
+			a.printContainerFlavorUsage()
+			return errors.Wrap(validationErr, "Error creating container image flavor: Invalid notary server URL")
+		}
+	}
+
+	//If the key ID is specified, make sure it's a valid UUID
+	if len(strings.TrimSpace(*keyID)) > 0 {
+		if validatekeyIDErr := validation.ValidateUUIDv4(*keyID); validatekeyIDErr != nil {
+			log.WithError(validatekeyIDErr).Errorf("app:createContainerImageFlavor() %s : Error creating container image flavor: Invalid UUID - %s\n", message.InvalidInputBadParam, *keyID)
+			a.printContainerFlavorUsage()
+			return errors.Wrap(validatekeyIDErr, "Error creating container image flavor: Invalid key UUID")
+		}
+	}
+
+	containerImageFlavor, err := imageflavor.CreateContainerImageFlavor(*imageName, *tag, *keyID, *encryptionRequired,
+		*integrityEnforced, *notaryURL, *outputFlavorFilename)
+	if err != nil {
+		log.WithError(err).Errorf("app:createContainerImageFlavor() %s - Error creating container image flavor: %s\n", message.AppRuntimeErr, err.Error())
+		a.printContainerFlavorUsage()
+		return errors.Wrap(err, "Error creating container image flavor")
+	}
+	if len(containerImageFlavor) > 0 {
+		fmt.Println(containerImageFlavor)
+	}
+	return nil
+}
+
+// keyProvider serves a single request of the ocicrypt key provider protocol, ocicrypt runs the command for every
+// layer key to wrap or unwrap and exchanges the request and the response on the standard input and output
+func (a *App) keyProvider() error {
+	err := keyprovider.NewKeyProvider().HandleRequest(os.Stdin, a.consoleWriter())
+	if err != nil {
+		log.WithError(err).Errorf("app:keyProvider() %s - Error handling key provider request: %s\n", message.AppRuntimeErr, err.Error())
+		return errors.Wrap(err, "Error handling key provider request")
+	}
+	return nil
+}
+
 func (a *App) Run(args []string) error {
 
 	if len(args) < 2 {
@@ -244,6 +476,42 @@ func (a *App) Run(args []string) error {
 			return err
 		}
 		return a.createImageFlavor(os.Args[:])
+	case "decrypt-image":
+		configuration := a.configuration()
+		if err := a.configureLogs(configuration.Log.EnableStdout, true); err != nil {
+			return err
+		}
+		return a.decryptImage(os.Args[:])
+	case "verify-image-flavor":
+		configuration := a.configuration()
+		if err := a.configureLogs(configuration.Log.EnableStdout, true); err != nil {
+			return err
+		}
+		return a.verifyImageFlavor(os.Args[:])
+	case "inspect":
+		configuration := a.configuration()
+		if err := a.configureLogs(configuration.Log.EnableStdout, true); err != nil {
+			return err
+		}
+		return a.inspect(os.Args[:])
+	case "create-container-image-flavor":
+		configuration := a.configuration()
+		if err := a.configureLogs(configuration.Log.EnableStdout, true); err != nil {
+			return err
+		}
+		return a.createContainerImageFlavor(os.Args[:])
+	case "keyprovider":
+		if len(args) != 2 {
+			a.printKeyProviderUsage()
+			return errInvalidCmd
+		}
+		// loads the configuration of the loggers
+		a.configuration()
+		// the standard output is reserved for the key provider response
+		if err := a.configureLogs(false, true); err != nil {
+			return err
+		}
+		return a.keyProvider()
 	}
 	return nil
 }
@@ -18,6 +18,11 @@ Available Commands:
     -h|--help                        Show this help message
     -v|--version                     Print version/build information
     create-image-flavor              Create VM image flavors and encrypt the image
+    decrypt-image                    Decrypt an encrypted VM image with its key fetched from KBS
+    verify-image-flavor              Verify the signature of a VM image flavor and the digest of its image
+    inspect                          Print the encryption header of a VM image and the metadata of its flavor
+    create-container-image-flavor    Create container image flavors
+    keyprovider                      Wrap and unwrap container image layer keys with keys from KBS, run by ocicrypt
     fetch-key                        Fetches the image encryption key with associated tags from KBS
     uninstall [--purge]              Uninstall wpm. --purge option needs to be applied to remove configuration and data files
     setup                            Run workload-policy-manager setup tasks
@@ -68,15 +73,11 @@ func (a *App) printContainerFlavorUsage() {
 	log.Trace("app:printContainerFlavorUsage() Entering")
 	defer log.Trace("app:printContainerFlavorUsage() Leaving")
 
-	fmt.Fprintf(a.consoleWriter(), "usage: wpm create-container-image-flavor -i img-name [-t tag] [-f dockerFile] [-d build-dir] [-k keyId]\n"+
+	fmt.Fprintf(a.consoleWriter(), "usage: wpm create-container-image-flavor -i img-name [-t tag] [-k keyId]\n"+
 		"                            [-e] [-s] [-n notaryServer] [-o out-file]\n"+
 		"\t  -i, --img-name                  container image name\n"+
 		"\t  -t, --tag                       (optional) container image tag name\n"+
-		"\t  -f, --docker-file               (optional) container file path\n"+
-		"\t                                  to build the container image\n"+
-		"\t  -d, --build-dir                 (optional) build directory to build the\n"+
-		"\t                                  container image. To be provided when container\n"+
-		"\t                                  file path [-f] is provided as parameter\n"+
+		"\t                                  defaults to latest\n"+
 		"\t  -k, --key-id                    (optional) existing key ID\n"+
 		"\t                                  if not specified, a new key is generated\n"+
 		"\t  -e, --encryption-required       (optional) boolean parameter specifies if\n"+
@@ -84,8 +85,21 @@ func (a *App) printContainerFlavorUsage() {
 		"\t  -s, --integrity-enforced        (optional) boolean parameter specifies if\n"+
 		"\t                                  container image should be signed\n"+
 		"\t  -n, --notary-server             (optional) specify notary server url\n"+
-		"\t  -o, --out-file                  (optional) specify output file name")
+		"\t  -o, --out-file                  (optional) specify output file name\n\n")
+}
 
+// keyprovider command usage
+func (a *App) printKeyProviderUsage() {
+	log.Trace("app:printKeyProviderUsage() Entering")
+	defer log.Trace("app:printKeyProviderUsage() Leaving")
+
+	fmt.Fprintf(a.consoleWriter(), "usage: wpm keyprovider\n"+
+		"\t  reads an ocicrypt key provider request on the standard input and writes the\n"+
+		"\t  response on the standard output. To be configured in the ocicrypt key provider\n"+
+		"\t  configuration file given in OCICRYPT_KEYPROVIDER_CONFIG:\n"+
+		"\t      {\"key-providers\": {\"isecl\": {\"cmd\": {\"path\": \"/usr/bin/wpm\", \"args\": [\"keyprovider\"]}}}}\n"+
+		"\t  the layers are encrypted with the recipient provider:isecl:keyid=<key ID>\n"+
+		"\t  the key ID is the one of the container image flavor\n\n")
 }
 
 // fetch-key command usage string
@@ -123,7 +137,7 @@ func (a *App) printImageFlavorUsage() {
 	log.Trace("main:imageFlavorUsage() Entering")
 	defer log.Trace("main:imageFlavorUsage() Leaving")
 
-	fmt.Fprintf(a.consoleWriter(), "usage: wpm create-image-flavor [-l label] [-i in] [-o out] [-e encout] [-k key]\n"+
+	fmt.Fprintf(a.consoleWriter(), "usage: wpm create-image-flavor [-l label] [-i in] [-o out] [-e encout] [-k key] [-V encryption-version]\n"+
 		"\t  -l, --label     image flavor label\n"+
 		"\t  -i, --in        input image file name\n"+
 		"\t  -o, --out       (optional) output image flavor file name\n"+
@@ -131,5 +145,48 @@ func (a *App) printImageFlavorUsage() {
 		"\t  -e, --encout    (optional) output encrypted image file name\n"+
 		"\t                  if not specified, encryption is skipped\n"+
 		"\t  -k, --key       (optional) existing key ID\n"+
-		"\t                  if not specified, a new key is generated\n\n")
+		"\t                  if not specified, a new key is generated\n"+
+		"\t  -V, --encryption-version\n"+
+		"\t                  (optional) encryption header version of the encrypted image, V1 or V2\n"+
+		"\t                  V1 images are encrypted as a whole in memory, V2 images in chunks\n"+
+		"\t                  if not specified, V1 is used\n\n")
+}
+
+// decrypt-image command usage
+func (a *App) printDecryptImageUsage() {
+	log.Trace("app:printDecryptImageUsage() Entering")
+	defer log.Trace("app:printDecryptImageUsage() Leaving")
+
+	fmt.Fprintf(a.consoleWriter(), "usage: wpm decrypt-image [-i in] [-o out] [-k key | -f flavor]\n"+
+		"\t  -i, --in        encrypted image file path\n"+
+		"\t  -o, --out       output decrypted image file path\n"+
+		"\t  -k, --key       (optional) ID of the key the image is encrypted with\n"+
+		"\t  -f, --flavor    (optional) image flavor file path\n"+
+		"\t                  the key ID is taken from the key URL of the flavor\n"+
+		"\t                  if the key is not specified, once the signature of\n"+
+		"\t                  the flavor is verified with the flavor signing certificate\n\n")
+}
+
+// verify-image-flavor command usage
+func (a *App) printVerifyImageFlavorUsage() {
+	log.Trace("app:printVerifyImageFlavorUsage() Entering")
+	defer log.Trace("app:printVerifyImageFlavorUsage() Leaving")
+
+	fmt.Fprintf(a.consoleWriter(), "usage: wpm verify-image-flavor [-f flavor] [-i in] [-c cert]\n"+
+		"\t  -f, --flavor    signed image flavor file path\n"+
+		"\t  -i, --in        (optional) image file path\n"+
+		"\t                  if specified, its digest must match the digest in the flavor\n"+
+		"\t  -c, --cert      (optional) flavor signing certificate file path\n"+
+		"\t                  defaults to the WPM flavor signing certificate\n\n")
+}
+
+// inspect command usage
+func (a *App) printInspectUsage() {
+	log.Trace("app:printInspectUsage() Entering")
+	defer log.Trace("app:printInspectUsage() Leaving")
+
+	fmt.Fprintf(a.consoleWriter(), "usage: wpm inspect [-i in] [-f flavor]\n"+
+		"\t  -i, --in        (optional) image file path\n"+
+		"\t  -f, --flavor    (optional) image flavor file path\n"+
+		"\t                  at least one of the image and the flavor must be specified\n\n")
 }
@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package imageflavor
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/flavor"
+	consts "github.com/intel-secl/intel-secl/v4/pkg/wpm/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/wpm/util"
+	"github.com/pkg/errors"
+)
+
+// DefaultContainerImageTag is the tag of the container image when none is given
+const DefaultContainerImageTag = "latest"
+
+// CreateContainerImageFlavor is used to create the signed flavor of a container image, the layers of the image are
+// encrypted by skopeo or buildah through the WPM key provider with the key of the flavor
+func CreateContainerImageFlavor(imageName, tag, keyID string, encryptionRequired, integrityEnforced bool,
+	notaryURL, outputFlavorFilename string) (string, error) {
+	log.Trace("pkg/wpm/imageflavor/create_container_image_flavor.go:CreateContainerImageFlavor() Entering")
+	defer log.Trace("pkg/wpm/imageflavor/create_container_image_flavor.go:CreateContainerImageFlavor() Leaving")
+
+	if strings.TrimSpace(imageName) == "" {
+		return "", errors.New("Container image name cannot be empty")
+	}
+	if filepath.IsAbs(outputFlavorFilename) {
+		return "", errors.New("Container image flavor filename should not be an absolute path")
+	}
+	if integrityEnforced && strings.TrimSpace(notaryURL) == "" {
+		return "", errors.New("Notary server URL is required when integrity is enforced")
+	}
+	if strings.TrimSpace(tag) == "" {
+		tag = DefaultContainerImageTag
+	}
+
+	var keyURLString string
+	if encryptionRequired {
+		var err error
+		// the key is only needed for its URL, the layers are encrypted by the key provider
+		_, keyURLString, err = util.FetchKey(keyID, "")
+		if err != nil {
+			return "", errors.Wrap(err, "Fetch key failed")
+		}
+	}
+
+	containerImageFlavor, err := flavor.GetContainerImageFlavor(imageName+":"+tag, encryptionRequired, keyURLString,
+		integrityEnforced, notaryURL)
+	if err != nil {
+		return "", errors.Wrap(err, "Error creating container image flavor")
+	}
+
+	containerImageFlavorJSON, err := json.Marshal(containerImageFlavor)
+	if err != nil {
+		return "", errors.Wrap(err, "Error while marshalling container image flavor")
+	}
+
+	signedFlavor, err := flavor.GetSignedImageFlavor(string(containerImageFlavorJSON), consts.FlavorSigningKeyFile)
+	if err != nil {
+		return "", errors.Wrap(err, "Error signing flavor for container image")
+	}
+	log.Info("pkg/wpm/imageflavor/create_container_image_flavor.go:CreateContainerImageFlavor() Successfully created container image flavor")
+
+	//If no output flavor file path was specified, return the signed container image flavor
+	if len(strings.TrimSpace(outputFlavorFilename)) <= 0 {
+		return signedFlavor, nil
+	}
+
+	outputFlavorFilePath := filepath.Join(consts.FlavorsDir, outputFlavorFilename)
+	err = ioutil.WriteFile(outputFlavorFilePath, []byte(signedFlavor), 0600)
+	if err != nil {
+		return "", errors.Wrapf(err, "I/O Error writing container image flavor to output file %s", outputFlavorFilePath)
+	}
+
+	log.Info("pkg/wpm/imageflavor/create_container_image_flavor.go:CreateContainerImageFlavor() Successfully wrote container image flavor to file")
+	return "", nil
+}
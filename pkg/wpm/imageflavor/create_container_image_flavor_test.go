@@ -0,0 +1,35 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package imageflavor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCreateContainerImageFlavor(t *testing.T) {
+	// the flavor signing key is not available
+	imageFlavor, err := CreateContainerImageFlavor("nginx", "", "", false, false, "", "")
+	assert.NotNil(t, err)
+	assert.Equal(t, imageFlavor, "")
+}
+
+func TestFailCreateContainerImageFlavorMissingName(t *testing.T) {
+	imageFlavor, err := CreateContainerImageFlavor("", "latest", "", false, false, "", "")
+	assert.NotNil(t, err)
+	assert.Equal(t, imageFlavor, "")
+}
+
+func TestFailCreateContainerImageFlavorFlavorFilePath(t *testing.T) {
+	imageFlavor, err := CreateContainerImageFlavor("nginx", "latest", "", false, false, "", "/root/container_flavor.json")
+	assert.NotNil(t, err)
+	assert.Equal(t, imageFlavor, "")
+}
+
+func TestFailCreateContainerImageFlavorMissingNotaryURL(t *testing.T) {
+	imageFlavor, err := CreateContainerImageFlavor("nginx", "latest", "", false, true, "", "")
+	assert.NotNil(t, err)
+	assert.Equal(t, imageFlavor, "")
+}
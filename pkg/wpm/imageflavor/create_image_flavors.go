@@ -10,7 +10,6 @@ package imageflavor
  *
  */
 import (
-	"crypto/sha512"
 	"encoding/base64"
 	"encoding/json"
 	cLog "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
@@ -29,9 +28,10 @@ var (
 	log = cLog.GetDefaultLogger()
 )
 
-//CreateImageFlavor is used to create flavor of an encrypted image
+//CreateImageFlavor is used to create flavor of an encrypted image, the image is encrypted in the format of the
+//encryptionVersion of the encryption header, V1 when it is empty
 func CreateImageFlavor(flavorLabel, outputFlavorFilename, inputImageFilename,
-	outputEncImageFilename, keyID string, integrityRequired bool) (string, error) {
+	outputEncImageFilename, keyID string, integrityRequired bool, encryptionVersion string) (string, error) {
 	log.Trace("pkg/wpm/imageflavor/create_image_flavors.go:CreateImageFlavor() Entering")
 	defer log.Trace("pkg/wpm/imageflavor/create_image_flavors.go:CreateImageFlavor() Leaving")
 
@@ -84,22 +84,20 @@ func CreateImageFlavor(flavorLabel, outputFlavorFilename, inputImageFilename,
 			return "", errors.Wrap(err, "Fetch key failed: "+err.Error())
 		}
 		// encrypt the image with key retrieved from KBS
-		err = util.Encrypt(inputImageFilePath, consts.EnvelopePrivatekeyLocation, outputEncImageFilePath, wrappedKey)
+		err = util.Encrypt(inputImageFilePath, consts.EnvelopePrivatekeyLocation, outputEncImageFilePath, wrappedKey,
+			encryptionVersion)
 		if err != nil {
 			return "", errors.Wrap(err, "Image encryption failed: "+err.Error())
 		}
 		imageFilePath = outputEncImageFilePath
 	}
 
-	//Check the encrypted image output file
-	imageFile, err := ioutil.ReadFile(imageFilePath)
+	//Take the digest of the encrypted image
+	digest, err := util.ImageDigest(imageFilePath)
 	if err != nil {
 		return "", errors.Wrap(err, "I/O Error creating encrypted image file: "+err.Error())
 	}
 
-	//Take the digest of the encrypted image
-	digest := sha512.Sum384(imageFile)
-
 	//Create image flavor
 	imageFlavor, err := flavor.GetImageFlavor(flavorLabel, encRequired, keyUrlString, base64.StdEncoding.EncodeToString(digest[:]))
 	if err != nil {
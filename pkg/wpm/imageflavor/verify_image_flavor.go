@@ -0,0 +1,156 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package imageflavor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/flavor"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/flavor/model"
+	"github.com/intel-secl/intel-secl/v4/pkg/wpm/util"
+	"github.com/pkg/errors"
+)
+
+// ImageInfo describes an image and the image flavor created for it
+type ImageInfo struct {
+	Encrypted           bool        `json:"encrypted"`
+	Version             string      `json:"version,omitempty"`
+	EncryptionAlgorithm string      `json:"encryption_algorithm,omitempty"`
+	IV                  string      `json:"iv,omitempty"`
+	DataOffset          uint32      `json:"data_offset,omitempty"`
+	ChunkSize           uint32      `json:"chunk_size,omitempty"`
+	KeyURL              string      `json:"key_url,omitempty"`
+	Digest              string      `json:"digest,omitempty"`
+	FlavorMeta          *model.Meta `json:"flavor_meta,omitempty"`
+}
+
+// VerifyImageFlavor checks the signature of the signed image flavor with the flavor signing certificate and, when the
+// flavor holds the digest of the encrypted image, that the image file matches it
+func VerifyImageFlavor(flavorFilePath, imageFilePath, signingCertFilePath string) (*flavor.SignedImageFlavor, error) {
+	log.Trace("pkg/wpm/imageflavor/verify_image_flavor.go:VerifyImageFlavor() Entering")
+	defer log.Trace("pkg/wpm/imageflavor/verify_image_flavor.go:VerifyImageFlavor() Leaving")
+
+	signedFlavor, err := readSignedImageFlavor(flavorFilePath, signingCertFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if imageFilePath == "" {
+		return signedFlavor, nil
+	}
+
+	encryption := signedFlavor.ImageFlavor.Encryption
+	if encryption == nil || encryption.Digest == "" {
+		return nil, errors.New("Image flavor does not include the digest of the image")
+	}
+	digest, err := util.ImageDigest(imageFilePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error computing the image digest")
+	}
+	if base64.StdEncoding.EncodeToString(digest) != encryption.Digest {
+		return nil, errors.New("Image digest does not match the image flavor")
+	}
+
+	log.Info("pkg/wpm/imageflavor/verify_image_flavor.go:VerifyImageFlavor() Successfully verified image flavor")
+	return signedFlavor, nil
+}
+
+// InspectImage reads the encryption headers of the image and, when a flavor file is given, the key URL, digest and
+// metadata of the image flavor without verifying its signature
+func InspectImage(imageFilePath, flavorFilePath string) (*ImageInfo, error) {
+	log.Trace("pkg/wpm/imageflavor/verify_image_flavor.go:InspectImage() Entering")
+	defer log.Trace("pkg/wpm/imageflavor/verify_image_flavor.go:InspectImage() Leaving")
+
+	var imageInfo ImageInfo
+	if imageFilePath != "" {
+		encrypted, err := crypt.EncryptionHeaderExists(imageFilePath)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error reading the image file")
+		}
+		if encrypted {
+			if err = readImageHeaders(imageFilePath, &imageInfo); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if flavorFilePath != "" {
+		signedFlavor, err := readSignedImageFlavor(flavorFilePath, "")
+		if err != nil {
+			return nil, err
+		}
+		if encryption := signedFlavor.ImageFlavor.Encryption; encryption != nil {
+			imageInfo.KeyURL = encryption.KeyURL
+			imageInfo.Digest = encryption.Digest
+		}
+		imageInfo.FlavorMeta = &signedFlavor.ImageFlavor.Meta
+	}
+
+	return &imageInfo, nil
+}
+
+// KeyIDFromImageFlavor returns the id of the key from the key URL of the image flavor
+func KeyIDFromImageFlavor(signedFlavor *flavor.SignedImageFlavor) (string, error) {
+	encryption := signedFlavor.ImageFlavor.Encryption
+	if encryption == nil || encryption.KeyURL == "" {
+		return "", errors.New("Image flavor does not include a key URL")
+	}
+	return util.KeyIDFromKeyURL(encryption.KeyURL)
+}
+
+// readSignedImageFlavor reads the signed image flavor and verifies its signature when a signing certificate is given
+func readSignedImageFlavor(flavorFilePath, signingCertFilePath string) (*flavor.SignedImageFlavor, error) {
+	flavorBytes, err := ioutil.ReadFile(flavorFilePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "I/O Error reading image flavor file")
+	}
+
+	if signingCertFilePath == "" {
+		var signedFlavor flavor.SignedImageFlavor
+		if err = json.Unmarshal(flavorBytes, &signedFlavor); err != nil {
+			return nil, errors.Wrap(err, "Error while unmarshalling signed image flavor")
+		}
+		return &signedFlavor, nil
+	}
+
+	signingCert, err := crypt.GetCertFromPemFile(signingCertFilePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading flavor signing certificate")
+	}
+	return flavor.VerifySignedImageFlavor(string(flavorBytes), signingCert)
+}
+
+// readImageHeaders fills the image information with the encryption headers of the image
+func readImageHeaders(imageFilePath string, imageInfo *ImageInfo) error {
+	imageFile, err := os.Open(imageFilePath)
+	if err != nil {
+		return errors.Wrap(err, "Error reading the image file")
+	}
+	defer func() {
+		derr := imageFile.Close()
+		if derr != nil {
+			log.WithError(derr).Error("Error closing image file")
+		}
+	}()
+
+	encryptionHeader, chunkHeader, err := crypt.ReadEncryptionHeaders(imageFile)
+	if err != nil {
+		return errors.Wrap(err, "Error reading the encryption headers")
+	}
+	imageInfo.Encrypted = true
+	imageInfo.Version = encryptionHeader.GetVersion()
+	imageInfo.EncryptionAlgorithm = strings.TrimRight(string(encryptionHeader.EncryptionAlgorithm[:]), "\x00")
+	imageInfo.IV = base64.StdEncoding.EncodeToString(encryptionHeader.IV[:])
+	imageInfo.DataOffset = encryptionHeader.OffsetInLittleEndian
+	if chunkHeader != nil {
+		imageInfo.ChunkSize = chunkHeader.ChunkSizeInLittleEndian
+	}
+	return nil
+}
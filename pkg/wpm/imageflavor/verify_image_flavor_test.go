@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package imageflavor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/flavor"
+	"github.com/intel-secl/intel-secl/v4/pkg/wpm/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const testKeyURL = "https://kbs.server.com:9443/kbs/v1/keys/73755fda-c910-46be-821f-e8ddeab189e9/transfer"
+
+// createTestImageFlavor encrypts an image in the V2 format and signs its flavor, it returns the paths of the
+// encrypted image, the flavor and the signing certificate
+func createTestImageFlavor(t *testing.T) (string, string, string) {
+	dir := t.TempDir()
+	imageFilePath := filepath.Join(dir, "cirros-x86.qcow2_enc")
+	flavorFilePath := filepath.Join(dir, "image_flavor.json")
+	certFilePath := filepath.Join(dir, "flavor-signing.pem")
+	keyFilePath := filepath.Join(dir, "flavor-signing.key")
+
+	certDer, keyDer, err := crypt.CreateKeyPairAndCertificate("WPM Flavor Signing Certificate", "", "rsa", 3072)
+	assert.NoError(t, err)
+	assert.NoError(t, crypt.SavePemCert(certDer, certFilePath))
+	assert.NoError(t, crypt.SavePrivateKeyAsPKCS8(keyDer, keyFilePath))
+
+	imageFile, err := os.Create(imageFilePath)
+	assert.NoError(t, err)
+	key, _ := crypt.GetRandomBytes(32)
+	encrypter, err := crypt.NewChunkedEncrypter(imageFile, key, 0)
+	assert.NoError(t, err)
+	_, err = encrypter.Write([]byte("image"))
+	assert.NoError(t, err)
+	assert.NoError(t, encrypter.Close())
+	assert.NoError(t, imageFile.Close())
+
+	digest, err := util.ImageDigest(imageFilePath)
+	assert.NoError(t, err)
+	imageFlavor, err := flavor.GetImageFlavor("label", true, testKeyURL, base64.StdEncoding.EncodeToString(digest))
+	assert.NoError(t, err)
+	imageFlavorJSON, err := json.Marshal(imageFlavor)
+	assert.NoError(t, err)
+	signedFlavor, err := flavor.GetSignedImageFlavor(string(imageFlavorJSON), keyFilePath)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(flavorFilePath, []byte(signedFlavor), 0600))
+
+	return imageFilePath, flavorFilePath, certFilePath
+}
+
+func TestVerifyImageFlavor(t *testing.T) {
+	imageFilePath, flavorFilePath, certFilePath := createTestImageFlavor(t)
+
+	signedFlavor, err := VerifyImageFlavor(flavorFilePath, imageFilePath, certFilePath)
+	assert.NoError(t, err)
+	keyID, err := KeyIDFromImageFlavor(signedFlavor)
+	assert.NoError(t, err)
+	assert.Equal(t, "73755fda-c910-46be-821f-e8ddeab189e9", keyID)
+
+	// the image no longer matches the digest
+	imageFile, err := os.OpenFile(imageFilePath, os.O_APPEND|os.O_WRONLY, 0600)
+	assert.NoError(t, err)
+	_, err = imageFile.Write([]byte{0})
+	assert.NoError(t, err)
+	assert.NoError(t, imageFile.Close())
+	_, err = VerifyImageFlavor(flavorFilePath, imageFilePath, certFilePath)
+	assert.Error(t, err)
+
+	// the flavor is signed by another key
+	_, _, otherCertFilePath := createTestImageFlavor(t)
+	_, err = VerifyImageFlavor(flavorFilePath, "", otherCertFilePath)
+	assert.Error(t, err)
+}
+
+func TestInspectImage(t *testing.T) {
+	imageFilePath, flavorFilePath, _ := createTestImageFlavor(t)
+
+	imageInfo, err := InspectImage(imageFilePath, flavorFilePath)
+	assert.NoError(t, err)
+	assert.True(t, imageInfo.Encrypted)
+	assert.Equal(t, crypt.EncryptionHeaderVersionV2, imageInfo.Version)
+	assert.Equal(t, crypt.GCMEncryptionAlgorithm, imageInfo.EncryptionAlgorithm)
+	assert.Equal(t, uint32(crypt.DefaultEncryptionChunkSize), imageInfo.ChunkSize)
+	assert.Equal(t, testKeyURL, imageInfo.KeyURL)
+	assert.Equal(t, "label", imageInfo.FlavorMeta.Description["label"])
+
+	plainImageFilePath := filepath.Join(t.TempDir(), "cirros-x86.qcow2")
+	assert.NoError(t, ioutil.WriteFile(plainImageFilePath, []byte("plain image"), 0600))
+	imageInfo, err = InspectImage(plainImageFilePath, "")
+	assert.NoError(t, err)
+	assert.False(t, imageInfo.Encrypted)
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package keyprovider
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
+	cLog "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
+	consts "github.com/intel-secl/intel-secl/v4/pkg/wpm/constants"
+	"github.com/intel-secl/intel-secl/v4/pkg/wpm/util"
+	"github.com/pkg/errors"
+)
+
+var log = cLog.GetDefaultLogger()
+
+const (
+	// DefaultProviderName is the name of the key provider in the ocicrypt configuration, the recipients of the
+	// provider are given to skopeo and buildah as provider:isecl:<parameters>
+	DefaultProviderName = "isecl"
+
+	// parameters of a recipient, given in the query string format, e.g. keyid=<uuid>&asset-tag=country:us
+	keyIDParameter    = "keyid"
+	assetTagParameter = "asset-tag"
+)
+
+// keyAnnotation is stored by ocicrypt with the encrypted layer, it holds the layer options encrypted with the KBS key
+type keyAnnotation struct {
+	KeyURL     string `json:"key_url"`
+	WrappedKey []byte `json:"wrapped_key"`
+}
+
+// KeyProvider wraps and unwraps the options, which hold the symmetric key, of the encrypted layers of OCI images with
+// keys fetched from KBS
+type KeyProvider struct {
+	Name string
+	// FetchKey returns the key wrapped with the envelope key and its transfer URL, a new key is created when the key
+	// id is empty
+	FetchKey func(keyID, assetTag string) ([]byte, string, error)
+	// UnwrapKey unwraps the key returned by FetchKey
+	UnwrapKey func(wrappedKey []byte) ([]byte, error)
+}
+
+// NewKeyProvider returns a key provider fetching the keys from the KBS configured for WPM
+func NewKeyProvider() *KeyProvider {
+	return &KeyProvider{
+		Name:     DefaultProviderName,
+		FetchKey: util.FetchKey,
+		UnwrapKey: func(wrappedKey []byte) ([]byte, error) {
+			return util.UnwrapKey(wrappedKey, consts.EnvelopePrivatekeyLocation)
+		},
+	}
+}
+
+// HandleRequest reads a key provider request of ocicrypt and writes the response
+func (kp *KeyProvider) HandleRequest(in io.Reader, out io.Writer) error {
+	log.Trace("pkg/wpm/keyprovider/key_provider.go:HandleRequest() Entering")
+	defer log.Trace("pkg/wpm/keyprovider/key_provider.go:HandleRequest() Leaving")
+
+	var input KeyProviderKeyWrapProtocolInput
+	if err := json.NewDecoder(in).Decode(&input); err != nil {
+		return errors.Wrap(err, "pkg/wpm/keyprovider/key_provider.go:HandleRequest() Error decoding key provider request")
+	}
+
+	var output *KeyProviderKeyWrapProtocolOutput
+	var err error
+	switch input.Operation {
+	case OpKeyWrap:
+		output, err = kp.wrapKey(&input.KeyWrapParams)
+	case OpKeyUnwrap:
+		output, err = kp.unwrapKey(&input.KeyUnwrapParams)
+	default:
+		return errors.Errorf("pkg/wpm/keyprovider/key_provider.go:HandleRequest() Unsupported key provider operation: %s", input.Operation)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = json.NewEncoder(out).Encode(output); err != nil {
+		return errors.Wrap(err, "pkg/wpm/keyprovider/key_provider.go:HandleRequest() Error encoding key provider response")
+	}
+	return nil
+}
+
+// wrapKey encrypts the layer options with the KBS key of the recipient
+func (kp *KeyProvider) wrapKey(params *KeyWrapParams) (*KeyProviderKeyWrapProtocolOutput, error) {
+	log.Trace("pkg/wpm/keyprovider/key_provider.go:wrapKey() Entering")
+	defer log.Trace("pkg/wpm/keyprovider/key_provider.go:wrapKey() Leaving")
+
+	if params.Ec == nil || len(params.Ec.Parameters[kp.Name]) == 0 {
+		return nil, errors.Errorf("pkg/wpm/keyprovider/key_provider.go:wrapKey() No recipient given for key provider %s", kp.Name)
+	}
+	// a layer is encrypted with a single KBS key
+	recipients := params.Ec.Parameters[kp.Name]
+	if len(recipients) > 1 {
+		return nil, errors.Errorf("pkg/wpm/keyprovider/key_provider.go:wrapKey() Only one recipient is supported for key provider %s", kp.Name)
+	}
+
+	recipient, err := url.ParseQuery(string(recipients[0]))
+	if err != nil {
+		return nil, errors.Wrap(err, "pkg/wpm/keyprovider/key_provider.go:wrapKey() Error parsing recipient parameters")
+	}
+	// every layer of the image must be encrypted with the same key, it cannot be created on each call
+	keyID := recipient.Get(keyIDParameter)
+	if _, err = uuid.Parse(keyID); err != nil {
+		return nil, errors.Wrap(err, "pkg/wpm/keyprovider/key_provider.go:wrapKey() Recipient does not include a valid key id")
+	}
+
+	key, keyURL, err := kp.fetchKey(keyID, recipient.Get(assetTagParameter))
+	if err != nil {
+		return nil, err
+	}
+
+	wrappedOptions, err := crypt.AesEncrypt(params.OptsData, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "pkg/wpm/keyprovider/key_provider.go:wrapKey() Error encrypting the layer options")
+	}
+
+	annotation, err := json.Marshal(keyAnnotation{
+		KeyURL:     keyURL,
+		WrappedKey: wrappedOptions,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "pkg/wpm/keyprovider/key_provider.go:wrapKey() Error while marshalling key annotation")
+	}
+
+	log.Infof("pkg/wpm/keyprovider/key_provider.go:wrapKey() Successfully wrapped layer key with key %s", keyID)
+	return &KeyProviderKeyWrapProtocolOutput{
+		KeyWrapResults: KeyWrapResults{Annotation: annotation},
+	}, nil
+}
+
+// unwrapKey decrypts the layer options with the KBS key the annotation refers to
+func (kp *KeyProvider) unwrapKey(params *KeyUnwrapParams) (*KeyProviderKeyWrapProtocolOutput, error) {
+	log.Trace("pkg/wpm/keyprovider/key_provider.go:unwrapKey() Entering")
+	defer log.Trace("pkg/wpm/keyprovider/key_provider.go:unwrapKey() Leaving")
+
+	var annotation keyAnnotation
+	if err := json.Unmarshal(params.Annotation, &annotation); err != nil {
+		return nil, errors.Wrap(err, "pkg/wpm/keyprovider/key_provider.go:unwrapKey() Error while unmarshalling key annotation")
+	}
+
+	keyID, err := util.KeyIDFromKeyURL(annotation.KeyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	key, _, err := kp.fetchKey(keyID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := crypt.AesDecrypt(annotation.WrappedKey, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "pkg/wpm/keyprovider/key_provider.go:unwrapKey() Error decrypting the layer options")
+	}
+
+	log.Infof("pkg/wpm/keyprovider/key_provider.go:unwrapKey() Successfully unwrapped layer key with key %s", keyID)
+	return &KeyProviderKeyWrapProtocolOutput{
+		KeyUnwrapResults: KeyUnwrapResults{OptsData: options},
+	}, nil
+}
+
+// fetchKey returns the unwrapped KBS key and its transfer URL
+func (kp *KeyProvider) fetchKey(keyID, assetTag string) ([]byte, string, error) {
+	wrappedKey, keyURL, err := kp.FetchKey(keyID, assetTag)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "pkg/wpm/keyprovider/key_provider.go:fetchKey() Error fetching the key")
+	}
+	key, err := kp.UnwrapKey(wrappedKey)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "pkg/wpm/keyprovider/key_provider.go:fetchKey() Error while unwrapping the key")
+	}
+	return key, keyURL, nil
+}
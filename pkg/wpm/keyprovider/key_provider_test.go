@@ -0,0 +1,240 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package keyprovider
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testKeyID       = "73755fda-c910-46be-821f-e8ddeab189e9"
+	testOtherKeyID  = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	testKbsURL      = "https://kbs.server.com:9443/kbs/v1"
+	layerMediaType  = "application/vnd.oci.image.layer.v1.tar+encrypted"
+	keysAnnotation  = "org.opencontainers.image.enc.keys.provider." + DefaultProviderName
+	layoutVersion   = `{"imageLayoutVersion":"1.0.0"}`
+	testLayerString = "layer contents"
+)
+
+// the subset of the OCI image layout which is needed to store an encrypted layer
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociManifest struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Layers        []ociDescriptor `json:"layers"`
+}
+
+type ociIndex struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Manifests     []ociDescriptor `json:"manifests"`
+}
+
+// layerOptions are the options ocicrypt gives to the key provider, they hold the symmetric key of the layer
+type layerOptions struct {
+	Cipher string `json:"cipher"`
+	SymKey []byte `json:"symkey"`
+}
+
+func newTestKeyProvider() *KeyProvider {
+	keys := map[string][]byte{}
+	for _, keyID := range []string{testKeyID, testOtherKeyID} {
+		keys[keyID], _ = crypt.GetRandomBytes(32)
+	}
+	return &KeyProvider{
+		Name: DefaultProviderName,
+		FetchKey: func(keyID, assetTag string) ([]byte, string, error) {
+			key, ok := keys[keyID]
+			if !ok {
+				return nil, "", errors.New("key not found")
+			}
+			return key, testKbsURL + "/keys/" + keyID + "/transfer", nil
+		},
+		UnwrapKey: func(wrappedKey []byte) ([]byte, error) {
+			return wrappedKey, nil
+		},
+	}
+}
+
+func handleRequest(t *testing.T, kp *KeyProvider, input *KeyProviderKeyWrapProtocolInput) (*KeyProviderKeyWrapProtocolOutput, error) {
+	request, err := json.Marshal(input)
+	assert.NoError(t, err)
+	response := &bytes.Buffer{}
+	if err = kp.HandleRequest(bytes.NewReader(request), response); err != nil {
+		return nil, err
+	}
+	var output KeyProviderKeyWrapProtocolOutput
+	assert.NoError(t, json.Unmarshal(response.Bytes(), &output))
+	return &output, nil
+}
+
+func writeBlob(t *testing.T, layoutDir string, blob []byte) string {
+	sum := sha256.Sum256(blob)
+	digest := hex.EncodeToString(sum[:])
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(layoutDir, "blobs", "sha256", digest), blob, 0600))
+	return "sha256:" + digest
+}
+
+func readJSONBlob(t *testing.T, layoutDir, digest string, v interface{}) {
+	blob, err := ioutil.ReadFile(filepath.Join(layoutDir, "blobs", "sha256", digest[len("sha256:"):]))
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(blob, v))
+}
+
+// encryptImage writes an OCI layout with a single layer encrypted the way ocicrypt does, the options holding the
+// layer key are wrapped by the key provider
+func encryptImage(t *testing.T, kp *KeyProvider, layoutDir, recipient string) {
+	assert.NoError(t, os.MkdirAll(filepath.Join(layoutDir, "blobs", "sha256"), 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(layoutDir, "oci-layout"), []byte(layoutVersion), 0600))
+
+	symKey, _ := crypt.GetRandomBytes(32)
+	encryptedLayer, err := crypt.AesEncrypt([]byte(testLayerString), symKey)
+	assert.NoError(t, err)
+	optsData, err := json.Marshal(layerOptions{Cipher: "AES_256_GCM", SymKey: symKey})
+	assert.NoError(t, err)
+
+	output, err := handleRequest(t, kp, &KeyProviderKeyWrapProtocolInput{
+		Operation: OpKeyWrap,
+		KeyWrapParams: KeyWrapParams{
+			Ec: &EncryptConfig{
+				Parameters: map[string][][]byte{DefaultProviderName: {[]byte(recipient)}},
+			},
+			OptsData: optsData,
+		},
+	})
+	assert.NoError(t, err)
+
+	manifest, err := json.Marshal(ociManifest{
+		SchemaVersion: 2,
+		Layers: []ociDescriptor{{
+			MediaType: layerMediaType,
+			Digest:    writeBlob(t, layoutDir, encryptedLayer),
+			Size:      int64(len(encryptedLayer)),
+			Annotations: map[string]string{
+				keysAnnotation: base64.StdEncoding.EncodeToString(output.KeyWrapResults.Annotation),
+			},
+		}},
+	})
+	assert.NoError(t, err)
+	index, err := json.Marshal(ociIndex{
+		SchemaVersion: 2,
+		Manifests: []ociDescriptor{{
+			MediaType: "application/vnd.oci.image.manifest.v1+json",
+			Digest:    writeBlob(t, layoutDir, manifest),
+			Size:      int64(len(manifest)),
+		}},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(layoutDir, "index.json"), index, 0600))
+}
+
+// decryptImage reads the encrypted layer of the OCI layout and decrypts it with the options unwrapped by the key
+// provider
+func decryptImage(t *testing.T, kp *KeyProvider, layoutDir string) ([]byte, error) {
+	indexBytes, err := ioutil.ReadFile(filepath.Join(layoutDir, "index.json"))
+	assert.NoError(t, err)
+	var index ociIndex
+	assert.NoError(t, json.Unmarshal(indexBytes, &index))
+	var manifest ociManifest
+	readJSONBlob(t, layoutDir, index.Manifests[0].Digest, &manifest)
+	layer := manifest.Layers[0]
+
+	annotation, err := base64.StdEncoding.DecodeString(layer.Annotations[keysAnnotation])
+	assert.NoError(t, err)
+	output, err := handleRequest(t, kp, &KeyProviderKeyWrapProtocolInput{
+		Operation: OpKeyUnwrap,
+		KeyUnwrapParams: KeyUnwrapParams{
+			Dc:         &DecryptConfig{},
+			Annotation: annotation,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var options layerOptions
+	assert.NoError(t, json.Unmarshal(output.KeyUnwrapResults.OptsData, &options))
+	encryptedLayer, err := ioutil.ReadFile(filepath.Join(layoutDir, "blobs", "sha256", layer.Digest[len("sha256:"):]))
+	assert.NoError(t, err)
+	return crypt.AesDecrypt(encryptedLayer, options.SymKey)
+}
+
+func TestKeyProviderRoundTrip(t *testing.T) {
+	kp := newTestKeyProvider()
+	layoutDir := t.TempDir()
+
+	encryptImage(t, kp, layoutDir, "keyid="+testKeyID+"&asset-tag=country:us")
+	layer, err := decryptImage(t, kp, layoutDir)
+	assert.NoError(t, err)
+	assert.Equal(t, testLayerString, string(layer))
+}
+
+func TestKeyProviderUnwrapWithOtherKey(t *testing.T) {
+	kp := newTestKeyProvider()
+	layoutDir := t.TempDir()
+	encryptImage(t, kp, layoutDir, "keyid="+testKeyID)
+
+	// the key the annotation refers to is returned in place of another one
+	fetchKey := kp.FetchKey
+	kp.FetchKey = func(keyID, assetTag string) ([]byte, string, error) {
+		return fetchKey(testOtherKeyID, assetTag)
+	}
+	_, err := decryptImage(t, kp, layoutDir)
+	assert.Error(t, err)
+}
+
+func TestKeyProviderWrapInvalidRecipient(t *testing.T) {
+	kp := newTestKeyProvider()
+	for _, parameters := range []map[string][][]byte{
+		nil,
+		{"other": {[]byte("keyid=" + testKeyID)}},
+		{DefaultProviderName: {[]byte("asset-tag=country:us")}},
+		{DefaultProviderName: {[]byte("keyid=invalid")}},
+		{DefaultProviderName: {[]byte("keyid=" + testKeyID), []byte("keyid=" + testOtherKeyID)}},
+	} {
+		_, err := handleRequest(t, kp, &KeyProviderKeyWrapProtocolInput{
+			Operation: OpKeyWrap,
+			KeyWrapParams: KeyWrapParams{
+				Ec:       &EncryptConfig{Parameters: parameters},
+				OptsData: []byte("{}"),
+			},
+		})
+		assert.Error(t, err)
+	}
+}
+
+func TestKeyProviderInvalidRequest(t *testing.T) {
+	kp := newTestKeyProvider()
+
+	_, err := handleRequest(t, kp, &KeyProviderKeyWrapProtocolInput{Operation: "keyrotate"})
+	assert.Error(t, err)
+
+	err = kp.HandleRequest(bytes.NewReader([]byte("not json")), &bytes.Buffer{})
+	assert.Error(t, err)
+
+	_, err = handleRequest(t, kp, &KeyProviderKeyWrapProtocolInput{
+		Operation: OpKeyUnwrap,
+		KeyUnwrapParams: KeyUnwrapParams{
+			Annotation: []byte(`{"key_url":"https://kbs.server.com:9443/kbs/v1/keys/invalid/transfer"}`),
+		},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package keyprovider
+
+// The messages of the ocicrypt key provider protocol, exchanged as json on the standard input and output of the key
+// provider command. They mirror the types of the github.com/containers/ocicrypt keyprovider and config packages, the
+// byte slices being base64 encoded in json.
+
+// Operations of the key provider protocol
+const (
+	OpKeyWrap   = "keywrap"
+	OpKeyUnwrap = "keyunwrap"
+)
+
+// KeyProviderKeyWrapProtocolInput is the request of ocicrypt to wrap or unwrap the options of a layer
+type KeyProviderKeyWrapProtocolInput struct {
+	Operation       string          `json:"op,omitempty"`
+	KeyWrapParams   KeyWrapParams   `json:"keywrapparams,omitempty"`
+	KeyUnwrapParams KeyUnwrapParams `json:"keyunwrapparams,omitempty"`
+}
+
+// KeyProviderKeyWrapProtocolOutput is the response of the key provider to ocicrypt
+type KeyProviderKeyWrapProtocolOutput struct {
+	KeyWrapResults   KeyWrapResults   `json:"keywrapresults,omitempty"`
+	KeyUnwrapResults KeyUnwrapResults `json:"keyunwrapresults,omitempty"`
+}
+
+// KeyWrapParams holds the encryption configuration and the layer options to be wrapped
+type KeyWrapParams struct {
+	Ec       *EncryptConfig `json:"ec"`
+	OptsData []byte         `json:"optsdata"`
+}
+
+// KeyUnwrapParams holds the decryption configuration and the annotation of the layer to be unwrapped
+type KeyUnwrapParams struct {
+	Dc         *DecryptConfig `json:"dc"`
+	Annotation []byte         `json:"annotation"`
+}
+
+// KeyWrapResults holds the annotation ocicrypt stores with the encrypted layer
+type KeyWrapResults struct {
+	Annotation []byte `json:"annotation"`
+}
+
+// KeyUnwrapResults holds the unwrapped layer options
+type KeyUnwrapResults struct {
+	OptsData []byte `json:"optsdata"`
+}
+
+// EncryptConfig holds the parameters of the recipients, keyed by protocol or key provider name
+type EncryptConfig struct {
+	Parameters    map[string][][]byte
+	DecryptConfig DecryptConfig
+}
+
+// DecryptConfig holds the parameters of the decryption keys, keyed by protocol or key provider name
+type DecryptConfig struct {
+	Parameters map[string][][]byte
+}
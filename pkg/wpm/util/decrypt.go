@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2021 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package util
+
+import (
+	"crypto/sha512"
+	"io"
+	"os"
+
+	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
+	"github.com/pkg/errors"
+)
+
+// Decrypt restores the plaintext of an image encrypted in the V1 or V2 format, the V2 images are streamed
+func Decrypt(encryptedImagePath string, privateKeyLocation string, imagePath string, wrappedKey []byte) error {
+	log.Trace("pkg/wpm/util/decrypt.go:Decrypt() Entering")
+	defer log.Trace("pkg/wpm/util/decrypt.go:Decrypt() Leaving")
+
+	encryptedImage, err := os.Open(encryptedImagePath)
+	if err != nil {
+		return errors.Wrap(err, "Error reading the encrypted image file")
+	}
+	defer func() {
+		derr := encryptedImage.Close()
+		if derr != nil {
+			log.WithError(derr).Error("Error closing encrypted image file")
+		}
+	}()
+
+	info, err := encryptedImage.Stat()
+	if err != nil {
+		return errors.Wrap(err, "Error reading the encrypted image file")
+	}
+
+	key, err := UnwrapKey(wrappedKey, privateKeyLocation)
+	if err != nil {
+		return errors.Wrap(err, "Error while unwrapping the key")
+	}
+
+	image, err := crypt.OpenEncryptedImage(encryptedImage, info.Size(), key)
+	if err != nil {
+		return errors.Wrap(err, "Error opening the encrypted image")
+	}
+
+	imageFile, err := os.OpenFile(imagePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "Error creating the image file")
+	}
+	defer func() {
+		derr := imageFile.Close()
+		if derr != nil {
+			log.WithError(derr).Error("Error closing image file")
+		}
+	}()
+
+	if _, err = image.DecryptTo(imageFile); err != nil {
+		// do not leave a partially decrypted image behind
+		_ = os.Remove(imagePath)
+		return errors.Wrap(err, "Error during writing the decrypted image to file")
+	}
+
+	log.Info("pkg/wpm/util/decrypt.go:Decrypt() Successfully decrypted image")
+	return nil
+}
+
+// ImageDigest returns the SHA384 digest of the image file, the image is streamed as it can be larger than the memory
+func ImageDigest(imagePath string) ([]byte, error) {
+	log.Trace("pkg/wpm/util/decrypt.go:ImageDigest() Entering")
+	defer log.Trace("pkg/wpm/util/decrypt.go:ImageDigest() Leaving")
+
+	imageFile, err := os.Open(imagePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading the image file")
+	}
+	defer func() {
+		derr := imageFile.Close()
+		if derr != nil {
+			log.WithError(derr).Error("Error closing image file")
+		}
+	}()
+
+	hash := sha512.New384()
+	if _, err = io.Copy(hash, imageFile); err != nil {
+		return nil, errors.Wrap(err, "Error reading the image file")
+	}
+	return hash.Sum(nil), nil
+}
@@ -5,14 +5,10 @@
 package util
 
 import (
-	"bytes"
-	"crypto/aes"
-	"crypto/cipher"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha512"
 	"crypto/x509"
-	"encoding/binary"
 	"encoding/pem"
 	"github.com/intel-secl/intel-secl/v4/pkg/lib/common/crypt"
 	cLog "github.com/intel-secl/intel-secl/v4/pkg/lib/common/log"
@@ -20,68 +16,104 @@ import (
 	"github.com/pkg/errors"
 	"io"
 	"io/ioutil"
-	"unsafe"
+	"os"
 )
 
 var log = cLog.GetDefaultLogger()
 
-func Encrypt(imagePath string, privateKeyLocation string, encryptedFileLocation string, wrappedKey []byte) error {
+// Encrypt encrypts the image in the format of the given version of the encryption header, V1 when it is empty. The
+// V1 image is sealed as a whole in memory while the V2 image is streamed in chunks, so that the memory used does not
+// depend on its size
+func Encrypt(imagePath string, privateKeyLocation string, encryptedFileLocation string, wrappedKey []byte,
+	encryptionVersion string) error {
 	log.Trace("pkg/wpm/util/encrypt.go:Encrypt() Entering")
 	defer log.Trace("pkg/wpm/util/encrypt.go:Encrypt() Leaving")
 
-	var encryptionHeader crypt.EncryptionHeader
-
-	// reading image file
-	image, err := ioutil.ReadFile(imagePath)
-	if err != nil {
-		return errors.Wrap(err, "Error reading the image file")
+	if encryptionVersion == "" {
+		encryptionVersion = crypt.EncryptionHeaderVersion
+	}
+	if encryptionVersion != crypt.EncryptionHeaderVersion && encryptionVersion != crypt.EncryptionHeaderVersionV2 {
+		return errors.New("Unsupported encryption header version " + encryptionVersion)
 	}
 
 	key, err := UnwrapKey(wrappedKey, privateKeyLocation)
 	if err != nil {
 		return errors.Wrap(err, "Error while unwrapping the key")
 	}
-	// creating a new cipher block of 128 bits
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return errors.Wrap(err, "Error initializing cipher")
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return errors.Wrap(err, "Error creating a cipher block")
-	}
 
 	log.Infof("pkg/util/encrypt.go:Encrypt() %s", cMsg.EncKeyUsed)
 
-	// assigning a 12 byte empty array to store random value
-	iv := make([]byte, 12)
-	// reading random value into the byte array
-	if _, err = io.ReadFull(rand.Reader, iv); err != nil {
-		return errors.Wrap(err, "Error creating random IV value")
+	if encryptionVersion == crypt.EncryptionHeaderVersionV2 {
+		err = encryptChunked(imagePath, encryptedFileLocation, key)
+	} else {
+		err = encryptWhole(imagePath, encryptedFileLocation, key)
+	}
+	if err != nil {
+		return err
 	}
 
-	copy(encryptionHeader.MagicText[:], crypt.EncryptionHeaderMagicText)
-	copy(encryptionHeader.EncryptionAlgorithm[:], crypt.GCMEncryptionAlgorithm)
-	copy(encryptionHeader.IV[:], iv)
-	copy(encryptionHeader.Version[:], crypt.EncryptionHeaderVersion)
-	encryptionHeader.OffsetInLittleEndian = uint32(unsafe.Sizeof(encryptionHeader))
+	log.Info("pkg/wpm/util/encrypt.go:Encrypt() Successfully encrypted image")
+	return nil
+}
 
-	encryptionHeaderSlice := &bytes.Buffer{}
-	err = binary.Write(encryptionHeaderSlice, binary.LittleEndian, encryptionHeader)
+// encryptWhole encrypts the image in the V1 format
+func encryptWhole(imagePath string, encryptedFileLocation string, key []byte) error {
+	// reading image file
+	image, err := ioutil.ReadFile(imagePath)
 	if err != nil {
-		return errors.Wrap(err, "Error while writing encryption header struc values in to buffer")
+		return errors.Wrap(err, "Error reading the image file")
 	}
 
 	// The first 44 bytes of the encrypted file is the encryption header and
 	// the rest is the data.
-	encryptedDataWithHeader := gcm.Seal(encryptionHeaderSlice.Bytes(), iv, image, nil)
+	encryptedDataWithHeader, err := crypt.EncryptImage(image, key)
+	if err != nil {
+		return errors.Wrap(err, "Error encrypting the image")
+	}
 	err = ioutil.WriteFile(encryptedFileLocation, encryptedDataWithHeader, 0600)
 	if err != nil {
 		return errors.Wrap(err, "Error during writing the encrypted image to file")
 	}
+	return nil
+}
 
-	log.Info("pkg/wpm/util/encrypt.go:Encrypt() Successfully encrypted image")
+// encryptChunked encrypts the image in the V2 format
+func encryptChunked(imagePath string, encryptedFileLocation string, key []byte) error {
+	// opening image file
+	image, err := os.Open(imagePath)
+	if err != nil {
+		return errors.Wrap(err, "Error reading the image file")
+	}
+	defer func() {
+		derr := image.Close()
+		if derr != nil {
+			log.WithError(derr).Error("Error closing image file")
+		}
+	}()
+
+	encryptedFile, err := os.OpenFile(encryptedFileLocation, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "Error creating the encrypted image file")
+	}
+	defer func() {
+		derr := encryptedFile.Close()
+		if derr != nil {
+			log.WithError(derr).Error("Error closing encrypted image file")
+		}
+	}()
+
+	// The first 48 bytes of the encrypted file are the encryption and chunk headers and
+	// the rest is the encrypted chunks.
+	encrypter, err := crypt.NewChunkedEncrypter(encryptedFile, key, crypt.DefaultEncryptionChunkSize)
+	if err != nil {
+		return errors.Wrap(err, "Error initializing image encryption")
+	}
+	if _, err = io.Copy(encrypter, image); err != nil {
+		return errors.Wrap(err, "Error during writing the encrypted image to file")
+	}
+	if err = encrypter.Close(); err != nil {
+		return errors.Wrap(err, "Error during writing the encrypted image to file")
+	}
 	return nil
 }
 
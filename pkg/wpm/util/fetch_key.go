@@ -7,11 +7,13 @@ package util
 import (
 	"encoding/base64"
 	"encoding/json"
+	"github.com/google/uuid"
 	"github.com/intel-secl/intel-secl/v4/pkg/wpm/config"
 	consts "github.com/intel-secl/intel-secl/v4/pkg/wpm/constants"
 	"github.com/spf13/viper"
 	"io/ioutil"
 	"net/url"
+	"path"
 	"regexp"
 	"strings"
 
@@ -149,3 +151,12 @@ func FetchKeyForAssetTag(keyID string, assetTag string) ([]byte, error) {
 	log.Info("pkg/wpm/util/fetch_key.go:FetchKeyForAssetTag() Successfully received encryption key from kbs")
 	return keyJSON, nil
 }
+
+//KeyIDFromKeyURL returns the id of the key from its transfer URL, which ends with /keys/{id}/transfer
+func KeyIDFromKeyURL(keyURL string) (string, error) {
+	keyID := path.Base(strings.TrimSuffix(keyURL, "/transfer"))
+	if _, err := uuid.Parse(keyID); err != nil {
+		return "", errors.Wrap(err, "pkg/wpm/util/fetch_key.go:KeyIDFromKeyURL() Key URL does not include a valid key id")
+	}
+	return keyID, nil
+}
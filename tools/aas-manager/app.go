@@ -185,6 +185,7 @@ func (a *App) GetServiceUsers() []UserAndRolesCreate {
 			urc.Name = a.IhubServiceUserName
 			urc.Password = a.IhubServiceUserPassword
 			urc.Roles = append(urc.Roles, NewRole("HVS", "ReportSearcher", "", []string{"reports:search:*"}))
+			urc.Roles = append(urc.Roles, NewRole("HVS", "TrustEventListener", "", []string{"events:stream:*"}))
 		case "WPM":
 			urc.Name = a.WpmServiceUserName
 			urc.Password = a.WpmServiceUserPassword